@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Output goes through stdout and stderr rather than straight to os.Stdout
+// and os.Stderr. ASCII mode swaps the status markers (✓, ⚠, 💬, ...) and
+// table borders that garble some CI consoles and log processors for plain
+// ones: the CLI's own strings pass through forStdout or forStderr where they
+// are printed, so comment and document text, and JSON and other
+// machine-readable output, are written as is.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// plainStdout and plainStderr are set in ASCII mode, per stream
+var plainStdout, plainStderr bool
+
+// asciiMarkers maps the symbols the CLI prints to plain replacements
+var asciiMarkers = strings.NewReplacer(
+	"✓", "[ok]",
+	"✗", "[x]",
+	"⚠️", "[!]",
+	"⚠", "[!]",
+	"📦", "[archived]",
+	"📍", "[section]",
+	"📄", "[document]",
+	"💬", "[line]",
+	"•", "|",
+	"·", "|",
+	"→", "->",
+	"↳", "->",
+	"↪", "->",
+	"↷", "->",
+	"►", ">",
+	"↑", "^",
+	"↓", "v",
+	"…", "...",
+	"—", "--",
+	"━", "=",
+	"─", "-",
+	"│", "|",
+	"┌", "+", "┬", "+", "┐", "+",
+	"├", "+", "┼", "+", "┤", "+",
+	"└", "+", "┴", "+", "┘", "+",
+)
+
+// setupOutput removes the global --ascii flag from args and switches stdout
+// and stderr to plain markers if it is set. Without the flag, each stream is
+// plain when it isn't a terminal (piped or in CI); --ascii=false keeps the
+// symbols everywhere.
+func setupOutput(args []string) []string {
+	ascii := ""
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			rest = append(rest, args[i:]...)
+			i = len(args)
+		case arg == "--ascii" || arg == "-ascii":
+			ascii = "true"
+		case strings.HasPrefix(arg, "--ascii=") || strings.HasPrefix(arg, "-ascii="):
+			ascii = arg[strings.Index(arg, "=")+1:]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if ascii == "" {
+		plainStdout = !isTerminal(os.Stdout)
+		plainStderr = !isTerminal(os.Stderr)
+		return rest
+	}
+
+	on, err := strconv.ParseBool(ascii)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: invalid --ascii value '%s'\n", ascii)
+		os.Exit(1)
+	}
+	plainStdout, plainStderr = on, on
+	return rest
+}
+
+// forStdout returns one of the CLI's own strings as it should be printed
+// on stdout: with plain markers in ASCII mode. Never pass it comment or
+// document text, which is printed as written.
+func forStdout(s string) string {
+	if plainStdout {
+		return asciiMarkers.Replace(s)
+	}
+	return s
+}
+
+// forStderr is forStdout for stderr
+func forStderr(s string) string {
+	if plainStderr {
+		return asciiMarkers.Replace(s)
+	}
+	return s
+}
+
+// ellipsis marks truncated text on stdout
+func ellipsis() string {
+	return forStdout("…")
+}
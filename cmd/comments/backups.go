@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// defaultBackupKeep is how many backups `backups prune` keeps when neither
+// --keep nor the project config says otherwise
+const defaultBackupKeep = 5
+
+// setupAutoRestore removes the global --auto-restore flag from args,
+// wherever it appears before "--". With it, loading a document whose sidecar
+// is missing restores a backup of its current content, as
+// backups.auto_restore in the project config does; otherwise the backup is
+// pointed out. Returns the remaining args.
+func setupAutoRestore(args []string) []string {
+	autoRestore := false
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			rest = append(rest, args[i:]...)
+			i = len(args)
+		case arg == "--auto-restore" || arg == "-auto-restore":
+			autoRestore = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if autoRestore {
+		comment.SetSettingsFunc(func(dir string) comment.Settings {
+			settings := config.SettingsFor(dir)
+			settings.Save.AutoRestore = true
+			return settings
+		})
+	}
+	return rest
+}
+
+// backupsCommand lists, restores, or prunes the timestamped sidecar backups
+// made before destructive saves and when stale sidecars are archived
+func backupsCommand(action, filename string, args []string) {
+	fs := flag.NewFlagSet("backups "+action, flag.ExitOnError)
+	backup := fs.String("backup", "1", "Backup to restore: its number in 'backups list' (1 = newest), timestamp, or path")
+	keep := fs.Int("keep", -1, fmt.Sprintf("Backups to keep when pruning (default: backups.keep from the project config, or %d)", defaultBackupKeep))
+	dryRun := fs.Bool("dry-run", false, "Show what would be pruned without deleting anything")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	backups, err := comment.ListBackups(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch action {
+	case "list":
+		fmt.Fprintf(stdout, "Found %d backup(s) of %s\n\n", len(backups), comment.GetSidecarPath(filename))
+		for i, b := range backups {
+			threads := "unreadable"
+			if storage, err := comment.ReadBackup(b.Path); err == nil {
+				threads = fmt.Sprintf("%d thread(s)", len(storage.Threads))
+			}
+			fmt.Fprintf(stdout, forStdout("[%d] %s • %s • %s\n"), i+1, b.Time.Format("2006-01-02 15:04:05"), threads, formatBytes(b.Size))
+			fmt.Fprintf(stdout, "    %s\n\n", b.Path)
+		}
+
+	case "restore":
+		enforcePolicy(filename, config.ActionRestore, currentActor(*actor))
+		chosen, ok := findBackup(backups, *backup)
+		if !ok {
+			fmt.Fprintf(stdout, "Error: backup '%s' not found; see 'comments backups list %s'\n", *backup, filename)
+			os.Exit(1)
+		}
+
+		previous, err := comment.RestoreBackup(filename, chosen.Path)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error restoring backup: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Restored %s from %s\n"), comment.GetSidecarPath(filename), chosen.Path)
+		if previous != "" {
+			fmt.Fprintf(stdout, "  The replaced sidecar was saved to %s\n", previous)
+		}
+
+		// The restore's own backup counts against the configured limit
+		if n := loadPolicy(filename).Backups.Keep; n > 0 {
+			if _, err := comment.PruneBackups(filename, n); err != nil {
+				fmt.Fprintf(stdout, forStdout("⚠ Warning: %v\n"), err)
+			}
+		}
+
+	case "prune":
+		n := *keep
+		if n < 0 {
+			n = loadPolicy(filename).Backups.Keep
+			if n == 0 {
+				n = defaultBackupKeep
+			}
+		}
+
+		if *dryRun {
+			for _, b := range backups[min(n, len(backups)):] {
+				fmt.Fprintf(stdout, "Would remove %s\n", b.Path)
+			}
+			fmt.Fprintln(stdout, "Dry run - no changes made")
+			return
+		}
+
+		enforcePolicy(filename, config.ActionCleanup, currentActor(*actor))
+		removed, err := comment.PruneBackups(filename, n)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error pruning backups: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Removed %d backup(s), kept %d\n"), len(removed), len(backups)-len(removed))
+
+	default:
+		fmt.Fprintf(stdout, "Error: unknown backups action '%s'. Valid actions: list, restore, prune\n", action)
+		os.Exit(1)
+	}
+}
+
+// findBackup picks a backup by list number, timestamp, or path
+func findBackup(backups []comment.Backup, which string) (comment.Backup, bool) {
+	if n, err := strconv.Atoi(which); err == nil {
+		if n >= 1 && n <= len(backups) {
+			return backups[n-1], true
+		}
+		return comment.Backup{}, false
+	}
+	for _, b := range backups {
+		if b.Path == which || filepath.Base(b.Path) == filepath.Base(which) || strings.HasSuffix(b.Path, ".backup."+which) {
+			return b, true
+		}
+	}
+	return comment.Backup{}, false
+}
+
+// formatBytes renders a file size for listings
+func formatBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
@@ -7,38 +7,48 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
 )
 
 // BatchComment represents a comment to be added in batch mode
 type BatchComment struct {
-	Line    int    `json:"line,omitempty"`    // Line number (use either line or section)
-	Section string `json:"section,omitempty"` // Section path (use either line or section)
-	Author  string `json:"author"`
-	Text    string `json:"text"`
-	Type    string `json:"type,omitempty"` // Q, S, B, T, E
+	Line     int    `json:"line,omitempty"`     // Line number (use either line or section)
+	Section  string `json:"section,omitempty"`  // Section path (use either line or section)
+	Document bool   `json:"document,omitempty"` // Comment on the document as a whole
+	Author   string `json:"author"`
+	Text     string `json:"text"`
+	Type     string `json:"type,omitempty"` // Q, S, B, T, E
 
 	// Suggestion fields (optional) - simplified to multi-line only
-	IsSuggestion bool   `json:"is_suggestion,omitempty"`
-	StartLine    int    `json:"start_line,omitempty"`
-	EndLine      int    `json:"end_line,omitempty"`
-	OriginalText string `json:"original_text,omitempty"`
-	ProposedText string `json:"proposed_text,omitempty"`
+	IsSuggestion bool     `json:"is_suggestion,omitempty"`
+	StartLine    int      `json:"start_line,omitempty"`
+	EndLine      int      `json:"end_line,omitempty"` // Also ends a range comment starting at 'line'
+	OriginalText string   `json:"original_text,omitempty"`
+	ProposedText string   `json:"proposed_text,omitempty"`
+	DependsOn    []string `json:"depends_on,omitempty"` // Existing suggestion IDs to apply first
+
+	// Client-supplied key; items whose key was already applied are skipped
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 func batchAddCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("batch-add", flag.ExitOnError)
 	jsonInput := fs.String("json", "", "JSON file path (use '-' for stdin)")
+	sign := fs.Bool("sign", false, "Sign the new comments with the local signing key")
+	dedupeMode := fs.String("dedupe", "", "Near-duplicates of open comments: skip, merge, or off (default: config, else skip)")
+	dedupeThreshold := fs.Float64("dedupe-threshold", 0, "Text similarity (0-1) that counts as a duplicate (default: config, else 0.9)")
 
 	fs.Parse(args)
 
 	if *jsonInput == "" {
-		fmt.Println("Error: --json flag is required")
-		fmt.Println("Usage: comments batch-add <file> --json <file|->")
-		fmt.Println("Example: comments batch-add doc.md --json reviews.json")
-		fmt.Println("Example: echo '[{\"line\":10,\"text\":\"comment\"}]' | comments batch-add doc.md --json -")
+		fmt.Fprintln(stdout, "Error: --json flag is required")
+		fmt.Fprintln(stdout, "Usage: comments batch-add <file> --json <file|->")
+		fmt.Fprintln(stdout, "Example: comments batch-add doc.md --json reviews.json")
+		fmt.Fprintln(stdout, "Example: echo '[{\"line\":10,\"text\":\"comment\"}]' | comments batch-add doc.md --json -")
 		os.Exit(1)
 	}
 
@@ -50,14 +60,14 @@ func batchAddCommand(filename string, args []string) {
 		// Read from stdin
 		input, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			fmt.Printf("Error reading from stdin: %v\n", err)
+			fmt.Fprintf(stdout, "Error reading from stdin: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
 		// Read from file
 		input, err = os.ReadFile(*jsonInput)
 		if err != nil {
-			fmt.Printf("Error reading JSON file: %v\n", err)
+			fmt.Fprintf(stdout, "Error reading JSON file: %v\n", err)
 			os.Exit(1)
 		}
 	}
@@ -65,18 +75,26 @@ func batchAddCommand(filename string, args []string) {
 	// Parse batch comments
 	var batchComments []BatchComment
 	if err := json.Unmarshal(input, &batchComments); err != nil {
-		fmt.Printf("Error parsing JSON: %v\n", err)
-		fmt.Println("\nExpected format (regular comment with line):")
-		fmt.Println(`[
+		fmt.Fprintf(stdout, "Error parsing JSON: %v\n", err)
+		fmt.Fprintln(stdout, "\nExpected format (regular comment with line):")
+		fmt.Fprintln(stdout, `[
   {"line": 10, "author": "alice", "text": "Add examples", "type": "S"},
   {"line": 25, "author": "bob", "text": "Great point!"}
 ]`)
-		fmt.Println("\nExpected format (comment with section):")
-		fmt.Println(`[
+		fmt.Fprintln(stdout, "\nExpected format (comment with section):")
+		fmt.Fprintln(stdout, `[
   {"section": "Introduction > Overview", "author": "alice", "text": "Consider adding examples", "type": "S"}
 ]`)
-		fmt.Println("\nExpected format (multi-line suggestion):")
-		fmt.Println(`[
+		fmt.Fprintln(stdout, "\nExpected format (document-level comment):")
+		fmt.Fprintln(stdout, `[
+  {"document": true, "author": "alice", "text": "Overall structure is confusing"}
+]`)
+		fmt.Fprintln(stdout, "\nExpected format (range comment spanning lines 10-25):")
+		fmt.Fprintln(stdout, `[
+  {"line": 10, "end_line": 25, "author": "alice", "text": "This whole example needs rework"}
+]`)
+		fmt.Fprintln(stdout, "\nExpected format (multi-line suggestion):")
+		fmt.Fprintln(stdout, `[
   {
     "line": 15,
     "author": "claude",
@@ -92,78 +110,143 @@ func batchAddCommand(filename string, args []string) {
 	}
 
 	if len(batchComments) == 0 {
-		fmt.Println("No comments found in JSON input")
+		fmt.Fprintln(stdout, "No comments found in JSON input")
 		os.Exit(0)
 	}
 
 	// Validate comments
 	for i, bc := range batchComments {
+		if bc.Document && (bc.Line != 0 || bc.Section != "" || bc.IsSuggestion) {
+			fmt.Fprintf(stdout, "Error: Comment %d is a document comment and cannot specify 'line', 'section', or a suggestion\n", i+1)
+			os.Exit(1)
+		}
 		// Validate that either line or section is provided (but not both)
-		if bc.Line == 0 && bc.Section == "" {
-			fmt.Printf("Error: Comment %d must specify either 'line' or 'section'\n", i+1)
+		if bc.Line == 0 && bc.Section == "" && !bc.Document {
+			fmt.Fprintf(stdout, "Error: Comment %d must specify either 'line', 'section', or 'document'\n", i+1)
 			os.Exit(1)
 		}
 		if bc.Line != 0 && bc.Section != "" {
-			fmt.Printf("Error: Comment %d cannot specify both 'line' and 'section'\n", i+1)
+			fmt.Fprintf(stdout, "Error: Comment %d cannot specify both 'line' and 'section'\n", i+1)
 			os.Exit(1)
 		}
 		if bc.Text == "" {
-			fmt.Printf("Error: Comment %d has empty text\n", i+1)
+			fmt.Fprintf(stdout, "Error: Comment %d has empty text\n", i+1)
 			os.Exit(1)
 		}
 		if bc.Author == "" {
-			fmt.Printf("Error: Comment %d has empty author (author is required)\n", i+1)
+			fmt.Fprintf(stdout, "Error: Comment %d has empty author (author is required)\n", i+1)
 			os.Exit(1)
 		}
 		// Validate type if specified
 		if bc.Type != "" {
 			validTypes := map[string]bool{"Q": true, "S": true, "B": true, "T": true, "E": true}
 			if !validTypes[bc.Type] {
-				fmt.Printf("Error: Comment %d has invalid type '%s'. Valid types: Q, S, B, T, E\n", i+1, bc.Type)
+				fmt.Fprintf(stdout, "Error: Comment %d has invalid type '%s'. Valid types: Q, S, B, T, E\n", i+1, bc.Type)
 				os.Exit(1)
 			}
 		}
+		if !bc.IsSuggestion && bc.EndLine != 0 && (bc.Line == 0 || bc.EndLine < bc.Line) {
+			fmt.Fprintf(stdout, "Error: Comment %d has 'end_line' (%d) without a 'line' at or before it\n", i+1, bc.EndLine)
+			os.Exit(1)
+		}
 		// Validate suggestion fields if is_suggestion is true
 		if bc.IsSuggestion {
 			if bc.StartLine == 0 {
-				fmt.Printf("Error: Comment %d is a suggestion but missing 'start_line' field\n", i+1)
+				fmt.Fprintf(stdout, "Error: Comment %d is a suggestion but missing 'start_line' field\n", i+1)
 				os.Exit(1)
 			}
 			if bc.EndLine == 0 {
-				fmt.Printf("Error: Comment %d is a suggestion but missing 'end_line' field\n", i+1)
+				fmt.Fprintf(stdout, "Error: Comment %d is a suggestion but missing 'end_line' field\n", i+1)
 				os.Exit(1)
 			}
 			if bc.StartLine > bc.EndLine {
-				fmt.Printf("Error: Comment %d has start_line (%d) > end_line (%d)\n", i+1, bc.StartLine, bc.EndLine)
+				fmt.Fprintf(stdout, "Error: Comment %d has start_line (%d) > end_line (%d)\n", i+1, bc.StartLine, bc.EndLine)
 				os.Exit(1)
 			}
 			if bc.ProposedText == "" {
-				fmt.Printf("Error: Comment %d is a suggestion but missing 'proposed_text' field\n", i+1)
+				fmt.Fprintf(stdout, "Error: Comment %d is a suggestion but missing 'proposed_text' field\n", i+1)
 				os.Exit(1)
 			}
 		}
 	}
 
+	// Ctrl+C from here on stops before anything is saved
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Check project policy for every author before adding anything
+	policy := loadPolicy(filename)
+	for i, bc := range batchComments {
+		action := config.ActionAdd
+		if bc.IsSuggestion {
+			action = config.ActionSuggest
+		}
+		if err := policy.Check(action, bc.Author); err != nil {
+			fmt.Fprintf(stdout, "Error in comment %d: %v\n", i+1, err)
+			os.Exit(1)
+		}
+	}
+
+	// Screen every comment before adding anything: the content filter may
+	// change its text, and lint sees what will actually be stored
+	screened := make([]*comment.Comment, len(batchComments))
+	for i, bc := range batchComments {
+		screened[i] = &comment.Comment{Author: bc.Author, Text: bc.Text, Type: bc.Type, IsSuggestion: bc.IsSuggestion}
+	}
+	screenBatch(policy, "comment", screened, true)
+	for i, c := range screened {
+		batchComments[i].Text = c.Text
+	}
+
+	// Flags override the project's dedupe settings
+	dedupe := policy.Dedupe.Policy()
+	if *dedupeMode != "" {
+		dedupe.Mode = *dedupeMode
+	}
+	if *dedupeThreshold != 0 {
+		dedupe.Threshold = *dedupeThreshold
+	}
+	if err := dedupe.Validate(); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Drop items a previous run already applied, so retries don't double-post
+	replayedCount := 0
+	seenKeys := map[string]bool{}
+	pending := []BatchComment{}
+	for _, bc := range batchComments {
+		if key := bc.IdempotencyKey; key != "" {
+			if _, applied := doc.AppliedKey(key); applied || seenKeys[key] {
+				replayedCount++
+				continue
+			}
+			seenKeys[key] = true
+		}
+		pending = append(pending, bc)
+	}
+	batchComments = pending
+
 	// Resolve section paths to line numbers
 	for i := range batchComments {
 		if batchComments[i].Section != "" {
 			// Validate section exists
-			if err := comment.ValidateSectionPath(doc.Content, batchComments[i].Section); err != nil {
-				fmt.Printf("Error in comment %d: %v\n", i+1, err)
+			if err := comment.ValidateSectionPath(doc, batchComments[i].Section); err != nil {
+				fmt.Fprintf(stdout, "Error in comment %d: %v\n", i+1, err)
 				os.Exit(1)
 			}
 
 			// Resolve section to line number (use section start line)
-			startLine, _, err := comment.ResolveSectionToLines(doc.Content, batchComments[i].Section, false)
+			startLine, _, err := comment.ResolveSectionToLines(doc, batchComments[i].Section, false)
 			if err != nil {
-				fmt.Printf("Error resolving section for comment %d: %v\n", i+1, err)
+				fmt.Fprintf(stdout, "Error resolving section for comment %d: %v\n", i+1, err)
 				os.Exit(1)
 			}
 			batchComments[i].Line = startLine
@@ -178,8 +261,13 @@ func batchAddCommand(filename string, args []string) {
 	// Add all comments to the document structure
 	addedCount := 0
 	addedComments := []*comment.Comment{}
+	mergedReplies := []*comment.Comment{}
+	skippedCount := 0
 
 	for _, bc := range batchComments {
+		if ctx.Err() != nil {
+			break
+		}
 		var newComment *comment.Comment
 
 		// Check if this is a suggestion
@@ -193,6 +281,11 @@ func batchAddCommand(filename string, args []string) {
 				bc.OriginalText,
 				bc.ProposedText,
 			)
+			newComment.DependsOn = bc.DependsOn
+			if err := comment.ValidateDependencies(newComment, doc.Threads); err != nil {
+				fmt.Fprintf(stdout, "Error in suggestion at line %d: depends_on: %v\n", bc.StartLine, err)
+				os.Exit(1)
+			}
 		} else {
 			// Auto-prefix text with type if specified
 			text := bc.Text
@@ -206,19 +299,53 @@ func batchAddCommand(filename string, args []string) {
 			} else {
 				newComment = comment.NewComment(bc.Author, bc.Line, text)
 			}
+
+			if bc.EndLine > bc.Line {
+				if lineCount := len(strings.Split(doc.Content, "\n")); bc.EndLine > lineCount {
+					fmt.Fprintf(stdout, "Error: Comment at line %d has end_line %d beyond the end of the document (%d lines)\n", bc.Line, bc.EndLine, lineCount)
+					os.Exit(1)
+				}
+				newComment.EndLine = bc.EndLine
+			}
 		}
 
 		// Compute section metadata for the new comment
-		comment.UpdateCommentSection(newComment, doc.Content)
+		comment.UpdateCommentSection(newComment, doc)
+		comment.CaptureQuote(newComment, doc.Content)
+
+		// Skip (or fold into the existing thread) what a previous run already said
+		if dedupe.Mode != comment.DedupeOff {
+			if dup := comment.FindDuplicate(doc.Threads, newComment, dedupe.Threshold); dup != nil {
+				if dedupe.Mode == comment.DedupeMerge && !newComment.IsSuggestion && comment.TextSimilarity(dup.Text, newComment.Text) < 1 {
+					reply := comment.NewReply(newComment.Author, newComment.Text, dup)
+					doc.AttachToOpenReview(reply)
+					dup.Replies = append(dup.Replies, reply)
+					mergedReplies = append(mergedReplies, reply)
+					doc.RecordKey(bc.IdempotencyKey, reply.ID)
+					fmt.Fprintf(stdout, forStdout("  ↪ Merged comment at line %d into similar thread %s\n"), newComment.Line, dup.ID)
+				} else {
+					skippedCount++
+					doc.RecordKey(bc.IdempotencyKey, dup.ID)
+					fmt.Fprintf(stdout, forStdout("  ↷ Skipped comment at line %d: duplicate of %s\n"), newComment.Line, dup.ID)
+				}
+				continue
+			}
+		}
+
+		doc.AttachToOpenReview(newComment)
 
 		doc.Threads = append(doc.Threads, newComment)
+		doc.RecordKey(bc.IdempotencyKey, newComment.ID)
 		addedComments = append(addedComments, newComment)
 		addedCount++
 	}
 
+	signComments(filename, *sign, append(addedComments, mergedReplies...)...)
+
 	// Save to sidecar
+	exitIfInterrupted(ctx, "no changes were saved")
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -239,9 +366,16 @@ func batchAddCommand(filename string, args []string) {
 		}
 
 		if verifiedCount != addedCount {
-			fmt.Printf("⚠ Warning: Added %d comment(s) but only %d were verified in the file\n", addedCount, verifiedCount)
+			fmt.Fprintf(stdout, forStdout("⚠ Warning: Added %d comment(s) but only %d were verified in the file\n"), addedCount, verifiedCount)
 		}
 	}
 
-	fmt.Printf("✓ Added %d comment(s) to %s\n", addedCount, filename)
+	fmt.Fprintf(stdout, forStdout("✓ Added %d comment(s) to %s\n"), addedCount, filename)
+	if replayedCount > 0 {
+		fmt.Fprintf(stdout, "  Already applied: %d item(s) with a known idempotency_key\n", replayedCount)
+	}
+	if skippedCount > 0 || len(mergedReplies) > 0 {
+		fmt.Fprintf(stdout, "  Deduplicated: %d skipped, %d merged as replies (mode %s, threshold %.2f)\n",
+			skippedCount, len(mergedReplies), dedupe.Mode, dedupe.Threshold)
+	}
 }
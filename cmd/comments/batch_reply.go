@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
 )
 
 // BatchReply represents a reply to be added in batch mode
@@ -15,20 +16,24 @@ type BatchReply struct {
 	Thread string `json:"thread"`
 	Author string `json:"author"`
 	Text   string `json:"text"`
+
+	// Client-supplied key; replies whose key was already applied are skipped
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 func batchReplyCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("batch-reply", flag.ExitOnError)
 	jsonInput := fs.String("json", "", "JSON file path (use '-' for stdin)")
+	sign := fs.Bool("sign", false, "Sign the new replies with the local signing key")
 
 	fs.Parse(args)
 
 	if *jsonInput == "" {
-		fmt.Println("Error: --json flag is required")
-		fmt.Println("Usage: comments batch-reply <file> --json <file|->")
-		fmt.Println("Example: comments batch-reply doc.md --json replies.json")
-		fmt.Println("Example: echo '[{\"thread\":\"c123\",\"author\":\"claude\",\"text\":\"reply\"}]' | comments batch-reply doc.md --json -")
+		fmt.Fprintln(stdout, "Error: --json flag is required")
+		fmt.Fprintln(stdout, "Usage: comments batch-reply <file> --json <file|->")
+		fmt.Fprintln(stdout, "Example: comments batch-reply doc.md --json replies.json")
+		fmt.Fprintln(stdout, "Example: echo '[{\"thread\":\"c123\",\"author\":\"claude\",\"text\":\"reply\"}]' | comments batch-reply doc.md --json -")
 		os.Exit(1)
 	}
 
@@ -40,14 +45,14 @@ func batchReplyCommand(filename string, args []string) {
 		// Read from stdin
 		input, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			fmt.Printf("Error reading from stdin: %v\n", err)
+			fmt.Fprintf(stdout, "Error reading from stdin: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
 		// Read from file
 		input, err = os.ReadFile(*jsonInput)
 		if err != nil {
-			fmt.Printf("Error reading JSON file: %v\n", err)
+			fmt.Fprintf(stdout, "Error reading JSON file: %v\n", err)
 			os.Exit(1)
 		}
 	}
@@ -55,9 +60,9 @@ func batchReplyCommand(filename string, args []string) {
 	// Parse batch replies
 	var batchReplies []BatchReply
 	if err := json.Unmarshal(input, &batchReplies); err != nil {
-		fmt.Printf("Error parsing JSON: %v\n", err)
-		fmt.Println("\nExpected format:")
-		fmt.Println(`[
+		fmt.Fprintf(stdout, "Error parsing JSON: %v\n", err)
+		fmt.Fprintln(stdout, "\nExpected format:")
+		fmt.Fprintln(stdout, `[
   {"thread": "c123", "author": "claude", "text": "This looks good"},
   {"thread": "c456", "author": "alice", "text": "I agree"}
 ]`)
@@ -65,33 +70,72 @@ func batchReplyCommand(filename string, args []string) {
 	}
 
 	if len(batchReplies) == 0 {
-		fmt.Println("No replies found in JSON input")
+		fmt.Fprintln(stdout, "No replies found in JSON input")
 		os.Exit(0)
 	}
 
 	// Validate replies
 	for i, br := range batchReplies {
 		if br.Thread == "" {
-			fmt.Printf("Error: Reply %d has empty thread ID\n", i+1)
+			fmt.Fprintf(stdout, "Error: Reply %d has empty thread ID\n", i+1)
 			os.Exit(1)
 		}
 		if br.Author == "" {
-			fmt.Printf("Error: Reply %d has empty author (author is required)\n", i+1)
+			fmt.Fprintf(stdout, "Error: Reply %d has empty author (author is required)\n", i+1)
 			os.Exit(1)
 		}
 		if br.Text == "" {
-			fmt.Printf("Error: Reply %d has empty text\n", i+1)
+			fmt.Fprintf(stdout, "Error: Reply %d has empty text\n", i+1)
 			os.Exit(1)
 		}
 	}
 
+	// Ctrl+C from here on stops before anything is saved
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Check project policy for every author before adding anything
+	policy := loadPolicy(filename)
+	for i, br := range batchReplies {
+		if err := policy.Check(config.ActionReply, br.Author); err != nil {
+			fmt.Fprintf(stdout, "Error in reply %d: %v\n", i+1, err)
+			os.Exit(1)
+		}
+	}
+
+	// Screen every reply before adding anything
+	screened := make([]*comment.Comment, len(batchReplies))
+	for i, br := range batchReplies {
+		screened[i] = &comment.Comment{Author: br.Author, Text: br.Text}
+	}
+	screenBatch(policy, "reply", screened, false)
+	for i, c := range screened {
+		batchReplies[i].Text = c.Text
+	}
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Drop replies a previous run already applied, so retries don't double-post
+	replayedCount := 0
+	seenKeys := map[string]bool{}
+	pending := []BatchReply{}
+	for _, br := range batchReplies {
+		if key := br.IdempotencyKey; key != "" {
+			if _, applied := doc.AppliedKey(key); applied || seenKeys[key] {
+				replayedCount++
+				continue
+			}
+			seenKeys[key] = true
+		}
+		pending = append(pending, br)
+	}
+	batchReplies = pending
+
 	// Build thread ID lookup for validation
 	threadIDs := make(map[string]bool)
 	for _, t := range doc.Threads {
@@ -107,36 +151,50 @@ func batchReplyCommand(filename string, args []string) {
 	}
 
 	if len(invalidThreads) > 0 {
-		fmt.Printf("Error: The following thread IDs were not found:\n")
+		fmt.Fprintf(stdout, "Error: The following thread IDs were not found:\n")
 		for _, tid := range invalidThreads {
-			fmt.Printf("  - %s\n", tid)
+			fmt.Fprintf(stdout, "  - %s\n", tid)
 		}
-		fmt.Println("\nAvailable threads:")
+		fmt.Fprintln(stdout, "\nAvailable threads:")
 		for _, t := range doc.Threads {
-			fmt.Printf("  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
+			fmt.Fprintf(stdout, "  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
 		}
 		os.Exit(1)
 	}
 
 	// Add all replies to the document structure
 	addedCount := 0
+	addedReplies := []*comment.Comment{}
 
 	for _, br := range batchReplies {
+		if ctx.Err() != nil {
+			break
+		}
 		// Use helper to add reply to thread
 		if err := comment.AddReplyToThread(doc.Threads, br.Thread, br.Author, br.Text); err != nil {
-			fmt.Printf("Error adding reply to thread %s: %v\n", br.Thread, err)
+			fmt.Fprintf(stdout, "Error adding reply to thread %s: %v\n", br.Thread, err)
 			os.Exit(1)
 		}
+		replies := doc.FindThreadByID(br.Thread).Replies
+		doc.AttachToOpenReview(replies[len(replies)-1])
+		doc.RecordKey(br.IdempotencyKey, replies[len(replies)-1].ID)
+		addedReplies = append(addedReplies, replies[len(replies)-1])
 		addedCount++
 	}
 
+	signComments(filename, *sign, addedReplies...)
+
 	// Save to sidecar
+	exitIfInterrupted(ctx, "no changes were saved")
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Added %d reply/replies to %s\n", addedCount, filename)
+	fmt.Fprintf(stdout, forStdout("✓ Added %d reply/replies to %s\n"), addedCount, filename)
+	if replayedCount > 0 {
+		fmt.Fprintf(stdout, "  Already applied: %d reply/replies with a known idempotency_key\n", replayedCount)
+	}
 
 	// Show summary of which threads were replied to
 	threadCounts := make(map[string]int)
@@ -144,8 +202,8 @@ func batchReplyCommand(filename string, args []string) {
 		threadCounts[br.Thread]++
 	}
 
-	fmt.Println("\nReplies by thread:")
+	fmt.Fprintln(stdout, "\nReplies by thread:")
 	for threadID, count := range threadCounts {
-		fmt.Printf("  %s: %d reply/replies\n", threadID, count)
+		fmt.Fprintf(stdout, "  %s: %d reply/replies\n", threadID, count)
 	}
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// blameEntry is a thread in `blame --format json` output
+type blameEntry struct {
+	*comment.Comment
+	Archived bool `json:"archived,omitempty"`
+}
+
+// blameCommand shows every thread that commented on or changed a line or
+// range, including resolved, decided, and archived ones, oldest first
+func blameCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("blame", flag.ExitOnError)
+	line := fs.Int("line", 0, "Line to trace")
+	lineRange := fs.String("lines", "", "Range of lines to trace (e.g., 10-30)")
+	format := fs.String("format", "text", "Output format: text, json")
+
+	fs.Parse(args)
+
+	if (*line == 0) == (*lineRange == "") {
+		fmt.Fprintln(stdout, "Error: exactly one of --line or --lines is required")
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	start, end := *line, *line
+	if *lineRange != "" {
+		var err error
+		if start, end, err = comment.ParseLineRange(*lineRange); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	archived, err := comment.LoadArchivedThreads(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading archives: %v\n", err)
+		os.Exit(1)
+	}
+	archivedIDs := make(map[string]bool)
+	for _, thread := range archived {
+		archivedIDs[thread.ID] = true
+	}
+
+	threads := comment.Blame(append(append([]*comment.Comment{}, doc.Threads...), archived...), start, end)
+
+	if *format == "json" {
+		entries := make([]blameEntry, 0, len(threads))
+		for _, thread := range threads {
+			entries = append(entries, blameEntry{thread, archivedIDs[thread.ID]})
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	lines := fmt.Sprintf("line %d", start)
+	if end != start {
+		lines = fmt.Sprintf("lines %d-%d", start, end)
+	}
+	if len(threads) == 0 {
+		fmt.Fprintf(stdout, "No comments have touched %s of %s\n", lines, filename)
+		return
+	}
+
+	fmt.Fprintf(stdout, "Found %d thread(s) that touched %s of %s\n\n", len(threads), lines, filename)
+	for i, thread := range threads {
+		status := thread.GetStatus()
+		if archivedIDs[thread.ID] {
+			status += forStdout(" • 📦 ARCHIVED")
+		}
+		fmt.Fprintf(stdout, forStdout("[%d] %s • @%s • %s\n"), i+1, thread.Timestamp.Format("2006-01-02 15:04"), thread.Author, status)
+		printBlameComment(thread, "    ", false)
+		fmt.Fprintln(stdout)
+	}
+}
+
+// printBlameComment prints a comment in a blame listing: where it is,
+// suggestions with their outcome and change, then replies indented below
+func printBlameComment(c *comment.Comment, indent string, isReply bool) {
+	switch {
+	case c.IsSuggestion:
+		outcome := "pending"
+		if c.IsAccepted() {
+			outcome = "accepted"
+		} else if c.IsRejected() {
+			outcome = "rejected"
+		}
+		fmt.Fprintf(stdout, forStdout("%sSuggestion (%s): %s • %s\n"), indent, outcome, comment.DescribeSuggestionLines(c), c.ID)
+	case !isReply:
+		first, last := c.LineRange()
+		location := fmt.Sprintf("Line %d", first)
+		if last != first {
+			location = fmt.Sprintf("Lines %d-%d", first, last)
+		}
+		fmt.Fprintf(stdout, forStdout("%s%s • %s\n"), indent, location, c.ID)
+	}
+
+	fmt.Fprintf(stdout, "%s%s\n", indent, c.Text)
+	if c.IsSuggestion && !c.IsMove {
+		printChangedLines(indent+"- ", c.OriginalText)
+		printChangedLines(indent+"+ ", c.ProposedText)
+	}
+
+	for _, reply := range c.Replies {
+		fmt.Fprintf(stdout, forStdout("%s↳ @%s • %s\n"), indent, reply.Author, reply.Timestamp.Format("2006-01-02 15:04"))
+		printBlameComment(reply, indent+"  ", true)
+	}
+}
+
+// printChangedLines prints each line of text after prefix
+func printChangedLines(prefix, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(stdout, "%s%s\n", prefix, line)
+	}
+}
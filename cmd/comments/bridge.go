@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rcliao/comments/pkg/chat"
+)
+
+// bridgeCommand serves Slack slash commands, Teams outgoing webhooks, and
+// inbound email for the documents under dir until interrupted. The secrets
+// come from the environment so they stay out of shell history and process
+// lists.
+func bridgeCommand(dir string, args []string) {
+	fs := flag.NewFlagSet("bridge", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	sign := fs.Bool("sign", false, "Sign every reply with the local signing key")
+	emailDomains := fs.String("email-domains", "", "Comma-separated sender domains email replies are accepted from (default: any)")
+
+	fs.Parse(args)
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(stdout, "Error: %s is not a directory\n", dir)
+		os.Exit(1)
+	}
+	bridge := chat.NewBridge(dir)
+	bridge.Service.Sign = *sign
+	bridge.SlackSecret = os.Getenv("COMMENTS_SLACK_SIGNING_SECRET")
+	bridge.TeamsSecret = os.Getenv("COMMENTS_TEAMS_SECRET")
+	bridge.EmailToken = os.Getenv("COMMENTS_EMAIL_TOKEN")
+	bridge.EmailDomains = parseIDList(*emailDomains)
+	if bridge.SlackSecret == "" && bridge.TeamsSecret == "" && bridge.EmailToken == "" {
+		fmt.Fprintln(stdout, "Error: set COMMENTS_SLACK_SIGNING_SECRET, COMMENTS_TEAMS_SECRET, or COMMENTS_EMAIL_TOKEN to enable an endpoint")
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	server := &http.Server{Addr: *addr, Handler: bridge.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	if bridge.SlackSecret != "" {
+		fmt.Fprintf(stderr, "Slack slash commands: POST http://%s/slack\n", *addr)
+	}
+	if bridge.TeamsSecret != "" {
+		fmt.Fprintf(stderr, "Teams outgoing webhook: POST http://%s/teams\n", *addr)
+	}
+	if bridge.EmailToken != "" {
+		fmt.Fprintf(stderr, "Inbound email: POST http://%s/email?token=...\n", *addr)
+	}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// mailInCommand adds the reply in an email read from stdin, for delivery
+// pipes such as a .forward file, procmail, or fetchmail's --mda polling an
+// IMAP inbox. It exits non-zero when the message can't be used, so the mail
+// system can bounce it.
+func mailInCommand(dir string, args []string) {
+	fs := flag.NewFlagSet("mail-in", flag.ExitOnError)
+	sign := fs.Bool("sign", false, "Sign the reply with the local signing key")
+	emailDomains := fs.String("email-domains", "", "Comma-separated sender domains replies are accepted from (default: any)")
+
+	fs.Parse(args)
+
+	bridge := chat.NewBridge(dir)
+	bridge.Service.Sign = *sign
+	bridge.EmailDomains = parseIDList(*emailDomains)
+	confirmation, err := bridge.ReplyByEmail(context.Background(), os.Stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, forStdout("✓ %s\n"), confirmation)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// bulkStatusCommand sets the status of every thread matching a list-style
+// filter, e.g. to mark all of a bot's tasks completed at once
+func bulkStatusCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("bulk-status", flag.ExitOnError)
+	filterSpec := fs.String("filter", "", "list filter flags selecting the threads, e.g. '--type T --author bot' (required)")
+	newStatus := fs.String("set", "", "New status: active, orphaned, resolved, completed (required)")
+	dryRun := fs.Bool("dry-run", false, "Show what would change without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if strings.TrimSpace(*filterSpec) == "" {
+		fmt.Fprintln(stdout, "Error: --filter is required (use --filter '--status active' to select every active thread)")
+		fmt.Fprintln(stdout, "Usage: comments bulk-status <file> --filter '<list flags>' --set <status> [--dry-run]")
+		os.Exit(1)
+	}
+	if !validStatuses[*newStatus] {
+		fmt.Fprintf(stdout, "Error: Invalid status '%s'\n", *newStatus)
+		fmt.Fprintln(stdout, "Valid statuses: active, orphaned, resolved, completed")
+		os.Exit(1)
+	}
+
+	// Check project policy before making changes
+	if !*dryRun {
+		enforcePolicy(filename, config.ActionStatus, currentActor(*actor))
+	}
+
+	// Load document
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	mergePrivateNotes(filename, doc, *actor)
+	comment.ComputeSectionsForComments(doc)
+
+	filter, showResolved, err := parseThreadFilter(doc, *filterSpec)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	matched := filter.Apply(comment.GetVisibleComments(doc.Threads, showResolved))
+	sortComments(matched, "line")
+
+	// Change the threads not already in the new status, counting them by the
+	// status they leave
+	changed := map[string]int{}
+	for _, thread := range matched {
+		oldStatus := thread.GetStatus()
+		if oldStatus == *newStatus {
+			continue
+		}
+		changed[oldStatus]++
+		if *dryRun {
+			fmt.Fprintf(stdout, forStdout("  %s • @%s • %s: %s → %s\n"), thread.ID, thread.Author, draftLocation(thread), oldStatus, *newStatus)
+			continue
+		}
+		thread.Status = *newStatus
+		// Back to active from orphaned: clear the orphaned metadata, as status does
+		if oldStatus == "orphaned" && *newStatus == "active" {
+			thread.OrphanedReason = ""
+			thread.OrphanedAt = nil
+		}
+	}
+
+	total := 0
+	from := make([]string, 0, len(changed))
+	for status, n := range changed {
+		total += n
+		from = append(from, fmt.Sprintf("%d %s", n, status))
+	}
+	sort.Strings(from)
+	summary := ""
+	if len(from) > 0 {
+		summary = fmt.Sprintf(" (%s)", strings.Join(from, ", "))
+	}
+	unchanged := len(matched) - total
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would set %d of %d matching thread(s) to %s%s; %d already %s\n", total, len(matched), *newStatus, summary, unchanged, *newStatus)
+		fmt.Fprintln(stdout, "Dry run: nothing was saved")
+		return
+	}
+
+	if total > 0 {
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Set %d of %d matching thread(s) to %s%s; %d already %s\n"), total, len(matched), *newStatus, summary, unchanged, *newStatus)
+}
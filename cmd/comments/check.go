@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// checkEntry is a suggestion waiting on its section's owners in `check`
+// JSON output
+type checkEntry struct {
+	File       string   `json:"file"`
+	Suggestion string   `json:"suggestion"`
+	Section    string   `json:"section"`
+	Lines      string   `json:"lines"`
+	Author     string   `json:"author"`
+	Owners     []string `json:"owners"`
+}
+
+// checkCommand fails when a pending suggestion in a section with required
+// reviewers (section_owners in the project config) has not been reviewed by
+// one of them, for use as a CI gate. Only sections whose text changed since
+// the document's last submitted review are checked unless --all-sections.
+func checkCommand(target string, args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json")
+	allSections := fs.Bool("all-sections", false, "Check every owned section, not only those changed since the last submitted review")
+	quiet := fs.Bool("quiet", false, "Only print files with problems")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "not every file was checked")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	results := map[string][]comment.AwaitingOwner{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Checking", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) ([]comment.AwaitingOwner, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := config.LoadForDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		comment.ComputeSectionsForComments(doc)
+		awaiting := comment.AwaitingOwnerReview(doc, policy.SectionOwnersOf)
+		if *allSections {
+			return awaiting, nil
+		}
+		return comment.AwaitingInChangedSections(doc, awaiting), nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		results[result.Path] = result.Value
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "not every file was checked")
+
+	paths := make([]string, 0, len(results))
+	for path := range results {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	waiting := 0
+	for _, path := range paths {
+		waiting += len(results[path])
+	}
+
+	if *format == "json" {
+		entries := []checkEntry{}
+		for _, path := range paths {
+			for _, a := range results[path] {
+				entries = append(entries, checkEntry{
+					File:       path,
+					Suggestion: a.Suggestion.ID,
+					Section:    a.Suggestion.SectionPath,
+					Lines:      comment.DescribeSuggestionLines(a.Suggestion),
+					Author:     a.Suggestion.Author,
+					Owners:     a.Owners,
+				})
+			}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, path := range paths {
+			awaiting := results[path]
+			if len(awaiting) == 0 {
+				if !*quiet {
+					fmt.Fprintf(stdout, forStdout("✓ %s\n"), path)
+				}
+				continue
+			}
+
+			fmt.Fprintf(stdout, forStdout("⚠ %s: %d suggestion(s) awaiting section owners\n"), path, len(awaiting))
+			for _, a := range awaiting {
+				s := a.Suggestion
+				fmt.Fprintf(stdout, forStdout("    %s • %s (%s) • @%s • waiting on %s\n"),
+					s.ID, s.SectionPath, comment.DescribeSuggestionLines(s), s.Author, strings.Join(a.Owners, ", "))
+			}
+		}
+		if waiting > 0 {
+			fmt.Fprintf(stdout, "\n%d suggestion(s) need review by their section owners (see each owner's load with: comments status-report %s)\n", waiting, target)
+		}
+	}
+
+	if reportFileErrors(errs) || waiting > 0 {
+		os.Exit(1)
+	}
+}
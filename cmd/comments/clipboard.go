@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/clipboard"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// copyComment copies the comment's text, ID, or quote and link ("text",
+// "id", "quote") to the clipboard, reporting on stderr so stdout stays
+// clean for piping
+func copyComment(c *comment.Comment, filename, docContent, what string) {
+	text := comment.FormatReference(c, filename, docContent)
+	switch what {
+	case "text":
+		text = c.Text
+	case "id":
+		text = c.ID
+	}
+
+	// OSC52 has to reach the terminal even when stdout is piped
+	terminal := os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		terminal = tty
+	}
+
+	method, err := clipboard.Copy(text, terminal)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error copying to clipboard: %v\n", err)
+		os.Exit(1)
+	}
+	if method == clipboard.MethodTerminal {
+		fmt.Fprintf(stderr, forStderr("✓ Copied %s to clipboard (via terminal)\n"), what)
+		return
+	}
+	fmt.Fprintf(stderr, forStderr("✓ Copied %s to clipboard\n"), what)
+}
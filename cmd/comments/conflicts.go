@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// conflictSideJSON is one competing proposal in `conflicts --format json`
+type conflictSideJSON struct {
+	ID        string `json:"id"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Result    string `json:"result"` // Conflict region with only this suggestion applied
+}
+
+// conflictJSON is a conflict in `conflicts --format json`
+type conflictJSON struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	StartLine   int                `json:"start_line"`
+	EndLine     int                `json:"end_line"`
+	Suggestions []conflictSideJSON `json:"suggestions"`
+}
+
+// conflictsCommand lists overlapping pending suggestions side by side and
+// resolves a conflict by picking one, merging both in an editor, or rejecting both
+func conflictsCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("conflicts", flag.ExitOnError)
+	pick := fs.String("pick", "", "Accept this suggestion and reject every suggestion that conflicts with it")
+	merge := fs.String("merge", "", "Merge two conflicting suggestions (id1,id2) by hand")
+	mergedText := fs.String("text", "", "Merged text for --merge (supports @filename; default: open $EDITOR)")
+	rejectBoth := fs.String("reject-both", "", "Reject both conflicting suggestions (id1,id2)")
+	format := fs.String("format", "text", "Output format for listing: text, json")
+	width := fs.Int("width", 100, "Total width of the side-by-side listing")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	actions := 0
+	for _, set := range []string{*pick, *merge, *rejectBoth} {
+		if set != "" {
+			actions++
+		}
+	}
+	if actions > 1 {
+		fmt.Fprintln(stdout, "Error: Use only one of --pick, --merge, or --reject-both")
+		os.Exit(1)
+	}
+
+	if actions == 0 {
+		listConflicts(filename, *format, *width)
+		return
+	}
+
+	who := currentActor(*actor)
+	if *rejectBoth == "" {
+		enforcePolicy(filename, config.ActionAccept, who)
+	}
+	enforcePolicy(filename, config.ActionReject, who)
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	conflicts := comment.PendingConflicts(doc.Threads)
+
+	switch {
+	case *pick != "":
+		winner := doc.FindCommentByID(*pick)
+		if winner == nil || !involvedInConflict(conflicts, winner) {
+			fmt.Fprintf(stdout, "Error: '%s' is not a pending suggestion with conflicts\n", *pick)
+			os.Exit(1)
+		}
+		enforceSelfAccept(filename, doc, winner, who, false)
+		rejected, err := comment.PickSuggestion(doc, winner)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		saveConflictResolution(filename, doc)
+		fmt.Fprintf(stdout, forStdout("✓ Accepted and applied %s\n"), winner.ID)
+		for _, s := range rejected {
+			fmt.Fprintf(stdout, forStdout("✓ Rejected %s\n"), s.ID)
+		}
+
+	case *merge != "":
+		conflict := findConflict(conflicts, *merge)
+		text := *mergedText
+		if text != "" {
+			if text, err = resolveTextInput(text); err != nil {
+				fmt.Fprintf(stdout, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			template, err := comment.MergeTemplate(doc.Content, conflict)
+			if err != nil {
+				fmt.Fprintf(stdout, "Error building merge template: %v\n", err)
+				os.Exit(1)
+			}
+			if text, err = editText(template, "merge-*.md"); err != nil {
+				fmt.Fprintf(stdout, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if text == template {
+				fmt.Fprintln(stdout, "Merge cancelled: the text was not edited")
+				os.Exit(1)
+			}
+		}
+		merged, err := comment.MergeSuggestions(doc, conflict, who, text)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		saveConflictResolution(filename, doc)
+		fmt.Fprintf(stdout, forStdout("✓ Merged %s and %s as %s (lines %d-%d)\n"),
+			conflict.Suggestion1.ID, conflict.Suggestion2.ID, merged.ID, merged.StartLine, merged.EndLine)
+
+	case *rejectBoth != "":
+		conflict := findConflict(conflicts, *rejectBoth)
+		if err := comment.RejectConflict(doc, conflict); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		saveConflictResolution(filename, doc)
+		fmt.Fprintf(stdout, forStdout("✓ Rejected %s and %s\n"), conflict.Suggestion1.ID, conflict.Suggestion2.ID)
+	}
+
+	if remaining := len(comment.PendingConflicts(doc.Threads)); remaining > 0 {
+		fmt.Fprintf(stdout, "%d conflict(s) remaining\n", remaining)
+	}
+}
+
+// listConflicts prints every conflict between pending suggestions
+func listConflicts(filename, format string, width int) {
+	if format != "text" && format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", format)
+		os.Exit(1)
+	}
+
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	conflicts := comment.PendingConflicts(doc.Threads)
+
+	if format == "json" {
+		out := []conflictJSON{}
+		for _, c := range conflicts {
+			start, end := c.Range()
+			entry := conflictJSON{Type: string(c.Type), Description: c.Description, StartLine: start, EndLine: end}
+			for _, s := range []*comment.Comment{c.Suggestion1, c.Suggestion2} {
+				result, _ := comment.ConflictSide(doc.Content, start, end, s)
+				entry.Suggestions = append(entry.Suggestions, conflictSideJSON{
+					ID: s.ID, Author: s.Author, Text: s.Text, StartLine: s.StartLine, EndLine: s.EndLine, Result: result,
+				})
+			}
+			out = append(out, entry)
+		}
+		jsonBytes, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Fprintln(stdout, "No conflicting suggestions")
+		return
+	}
+
+	columnWidth := max((width-3)/2, 20)
+	for i, c := range conflicts {
+		start, end := c.Range()
+		fmt.Fprintf(stdout, "Conflict %d of %d: %s, lines %d-%d (%s)\n\n", i+1, len(conflicts), c.Type, start, end, c.Description)
+
+		var sides [2][]string
+		for j, s := range []*comment.Comment{c.Suggestion1, c.Suggestion2} {
+			result, err := comment.ConflictSide(doc.Content, start, end, s)
+			if err != nil {
+				result = fmt.Sprintf("(cannot apply: %v)", err)
+			}
+			sides[j] = append([]string{
+				fmt.Sprintf("%s @%s (lines %d-%d)", s.ID, s.Author, s.StartLine, s.EndLine),
+				s.Text,
+				strings.Repeat(forStdout("─"), columnWidth),
+			}, strings.Split(result, "\n")...)
+		}
+		printSideBySide(sides[0], sides[1], columnWidth)
+		fmt.Fprintln(stdout)
+	}
+
+	fmt.Fprintln(stdout, "Resolve with: --pick <id>, --merge <id1,id2>, or --reject-both <id1,id2>")
+}
+
+// printSideBySide prints two columns of lines separated by a vertical bar
+func printSideBySide(left, right []string, columnWidth int) {
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		fmt.Fprintf(stdout, forStdout("%s │ %s\n"), fitColumn(l, columnWidth), strings.TrimRight(fitColumn(r, columnWidth), " "))
+	}
+}
+
+// fitColumn truncates or pads s to exactly width runes
+func fitColumn(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		tail := ellipsis()
+		return string(runes[:width-utf8.RuneCountInString(tail)]) + tail
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// involvedInConflict reports whether s is part of any of the conflicts
+func involvedInConflict(conflicts []comment.Conflict, s *comment.Comment) bool {
+	for _, c := range conflicts {
+		if c.Suggestion1 == s || c.Suggestion2 == s {
+			return true
+		}
+	}
+	return false
+}
+
+// findConflict returns the conflict between the two suggestions in an
+// "id1,id2" list, exiting if they don't conflict
+func findConflict(conflicts []comment.Conflict, pair string) comment.Conflict {
+	ids := parseIDList(pair)
+	if len(ids) != 2 {
+		fmt.Fprintf(stdout, "Error: Expected two suggestion IDs (id1,id2), got '%s'\n", pair)
+		os.Exit(1)
+	}
+
+	for _, c := range conflicts {
+		if (c.Suggestion1.ID == ids[0] && c.Suggestion2.ID == ids[1]) ||
+			(c.Suggestion1.ID == ids[1] && c.Suggestion2.ID == ids[0]) {
+			return c
+		}
+	}
+
+	fmt.Fprintf(stdout, "Error: Suggestions %s and %s are not in conflict\n", ids[0], ids[1])
+	os.Exit(1)
+	return comment.Conflict{}
+}
+
+// saveConflictResolution saves the document after resolving a conflict
+func saveConflictResolution(filename string, doc *comment.DocumentWithComments) {
+	backupSidecar(filename)
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+}
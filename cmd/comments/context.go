@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/rcliao/comments/pkg/comment"
-	"github.com/rcliao/comments/pkg/markdown"
 )
 
 // CommentContext represents the context information for a comment
@@ -14,6 +13,8 @@ type CommentContext struct {
 	SectionHeading  string
 	SectionRange    string
 	ContextLines    []ContextLine
+	Quote           string // Target text when the comment was made
+	QuoteChanged    bool   // Whether the target lines changed since the comment was made
 	OriginalText    string // For suggestions
 	ProposedText    string // For suggestions
 }
@@ -25,18 +26,23 @@ type ContextLine struct {
 	IsTarget bool
 }
 
+// defaultContextLines is the number of lines shown before and after a comment
+// when no --context flag is given
+const defaultContextLines = 5
+
 // getCommentContext extracts context information for a comment
-func getCommentContext(c *comment.Comment, docContent string) CommentContext {
+// contextSize is the number of lines to include before and after the target line
+func getCommentContext(c *comment.Comment, doc *comment.DocumentWithComments, contextSize int) CommentContext {
 	ctx := CommentContext{}
 
-	lines := strings.Split(docContent, "\n")
+	lines := strings.Split(doc.Content, "\n")
 
 	// Get section information if available
 	if c.SectionPath != "" {
 		ctx.SectionPath = c.SectionPath
 
 		// Parse document to get section details
-		docStructure := markdown.ParseDocument(docContent)
+		docStructure := doc.Structure()
 		section, exists := docStructure.SectionsByLine[c.Line]
 		if exists {
 			ctx.SectionHeading = section.Title
@@ -44,13 +50,23 @@ func getCommentContext(c *comment.Comment, docContent string) CommentContext {
 		}
 	}
 
-	// Get context lines (5 lines before and after, or less if at boundaries)
-	contextSize := 5
+	// Document-level comments have no surrounding lines
+	if c.IsDocumentLevel() {
+		return ctx
+	}
+
+	if c.Quote != "" {
+		ctx.Quote = c.Quote
+		ctx.QuoteChanged = c.IsOrphaned() || c.TargetChanged(lines)
+	}
+
+	// Get context lines (contextSize lines before and after, or less if at boundaries)
 	start := c.Line - contextSize
 	if start < 1 {
 		start = 1
 	}
-	end := c.Line + contextSize
+	first, last := c.LineRange()
+	end := last + contextSize
 	if end > len(lines) {
 		end = len(lines)
 	}
@@ -61,7 +77,7 @@ func getCommentContext(c *comment.Comment, docContent string) CommentContext {
 			ctx.ContextLines = append(ctx.ContextLines, ContextLine{
 				LineNum: i,
 				Text: lines[i-1],
-				IsTarget: i == c.Line,
+				IsTarget: i >= first && i <= last,
 			})
 		}
 	}
@@ -76,22 +92,34 @@ func getCommentContext(c *comment.Comment, docContent string) CommentContext {
 }
 
 // formatCommentWithContext formats a comment with its context for display
-func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeReplies bool) string {
+// timeFormat is how timestamps are shown (see comment.FormatTimestamp).
+func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeReplies bool, timeFormat string) string {
 	var output strings.Builder
 
 	// Header with ID and metadata
-	output.WriteString(fmt.Sprintf("━━━ Comment ID: %s ━━━\n", c.ID))
+	output.WriteString(fmt.Sprintf(forStdout("━━━ Comment ID: %s ━━━\n"), c.ID))
 	output.WriteString(fmt.Sprintf("Author: @%s\n", c.Author))
-	output.WriteString(fmt.Sprintf("Timestamp: %s\n", c.Timestamp.Format("2006-01-02 15:04:05")))
+	if c.IsPrivate() {
+		output.WriteString("Visibility: private (only you can see it)\n")
+	}
+	output.WriteString(fmt.Sprintf("Timestamp: %s\n", comment.FormatTimestamp(c.Timestamp, timeFormat)))
 
 	// Location info
-	if ctx.SectionPath != "" {
-		output.WriteString(fmt.Sprintf("Location: 📍 %s (Line %d)\n", ctx.SectionPath, c.Line))
+	if c.IsDocumentLevel() {
+		output.WriteString(forStdout("Location: 📄 Document\n"))
+	} else if ctx.SectionPath != "" {
+		if c.IsRange() {
+			output.WriteString(fmt.Sprintf(forStdout("Location: 📍 %s (Lines %d-%d)\n"), ctx.SectionPath, c.Line, c.EndLine))
+		} else {
+			output.WriteString(fmt.Sprintf(forStdout("Location: 📍 %s (Line %d)\n"), ctx.SectionPath, c.Line))
+		}
 		if ctx.SectionHeading != "" {
 			output.WriteString(fmt.Sprintf("Section: %s (%s)\n", ctx.SectionHeading, ctx.SectionRange))
 		}
+	} else if c.IsRange() {
+		output.WriteString(fmt.Sprintf(forStdout("Location: 💬 Lines %d-%d\n"), c.Line, c.EndLine))
 	} else {
-		output.WriteString(fmt.Sprintf("Location: 💬 Line %d\n", c.Line))
+		output.WriteString(fmt.Sprintf(forStdout("Location: 💬 Line %d\n"), c.Line))
 	}
 
 	// Type and status
@@ -110,7 +138,7 @@ func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeRep
 		output.WriteString(fmt.Sprintf("Suggestion: %s\n", status))
 	}
 	if c.Resolved {
-		output.WriteString("Status: ✓ Resolved\n")
+		output.WriteString(forStdout("Status: ✓ Resolved\n"))
 	}
 
 	output.WriteString("\n")
@@ -120,16 +148,29 @@ func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeRep
 	output.WriteString(fmt.Sprintf("  %s\n", c.Text))
 	output.WriteString("\n")
 
+	// Quoted text from when the comment was made
+	if ctx.Quote != "" {
+		if ctx.QuoteChanged {
+			output.WriteString(forStdout("Quoted Text (⚠ changed since the comment was made; current text marked ► below):\n"))
+		} else {
+			output.WriteString("Quoted Text:\n")
+		}
+		for _, line := range strings.Split(ctx.Quote, "\n") {
+			output.WriteString(fmt.Sprintf("  > %s\n", line))
+		}
+		output.WriteString("\n")
+	}
+
 	// Context section
 	if len(ctx.ContextLines) > 0 {
 		output.WriteString("Document Context:\n")
-		output.WriteString("─────────────────\n")
+		output.WriteString(forStdout("─────────────────\n"))
 		for _, line := range ctx.ContextLines {
 			marker := " "
 			if line.IsTarget {
-				marker = "►"
+				marker = forStdout("►")
 			}
-			output.WriteString(fmt.Sprintf("%s %4d │ %s\n", marker, line.LineNum, line.Text))
+			output.WriteString(fmt.Sprintf(forStdout("%s %4d │ %s\n"), marker, line.LineNum, line.Text))
 		}
 		output.WriteString("\n")
 	}
@@ -137,8 +178,12 @@ func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeRep
 	// Suggestion details
 	if c.IsSuggestion {
 		output.WriteString("Suggestion Details:\n")
-		output.WriteString("───────────────────\n")
-		output.WriteString(fmt.Sprintf("Lines: %d-%d\n\n", c.StartLine, c.EndLine))
+		output.WriteString(forStdout("───────────────────\n"))
+		output.WriteString(comment.DescribeSuggestionLines(c) + "\n")
+		if len(c.DependsOn) > 0 {
+			output.WriteString(fmt.Sprintf("Depends on: %s\n", strings.Join(c.DependsOn, ", ")))
+		}
+		output.WriteString("\n")
 
 		if ctx.OriginalText != "" {
 			output.WriteString("Original:\n")
@@ -160,9 +205,13 @@ func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeRep
 	// Replies
 	if includeReplies && len(c.Replies) > 0 {
 		output.WriteString(fmt.Sprintf("Replies (%d):\n", len(c.Replies)))
-		output.WriteString("─────────\n")
+		output.WriteString(forStdout("─────────\n"))
 		for i, reply := range c.Replies {
-			output.WriteString(fmt.Sprintf("[%d] @%s · %s\n", i+1, reply.Author, reply.Timestamp.Format("2006-01-02 15:04")))
+			decision := ""
+			if reply.Decision != "" {
+				decision = fmt.Sprintf(forStdout(" · reason for %s"), reply.Decision)
+			}
+			output.WriteString(fmt.Sprintf(forStdout("[%d] @%s · %s%s\n"), i+1, reply.Author, comment.FormatTimestamp(reply.Timestamp, timeFormat), decision))
 			output.WriteString(fmt.Sprintf("    %s\n", reply.Text))
 			if i < len(c.Replies)-1 {
 				output.WriteString("\n")
@@ -174,17 +223,17 @@ func formatCommentWithContext(c *comment.Comment, ctx CommentContext, includeRep
 }
 
 // formatListWithContext formats a list of comments with context
-func formatListWithContext(comments []*comment.Comment, docContent string) string {
+func formatListWithContext(comments []*comment.Comment, doc *comment.DocumentWithComments, contextSize int, timeFormat string) string {
 	var output strings.Builder
 
 	output.WriteString(fmt.Sprintf("Found %d comment thread(s) with context\n\n", len(comments)))
 
 	for i, c := range comments {
-		ctx := getCommentContext(c, docContent)
-		output.WriteString(formatCommentWithContext(c, ctx, false))
+		ctx := getCommentContext(c, doc, contextSize)
+		output.WriteString(formatCommentWithContext(c, ctx, false, timeFormat))
 
 		if i < len(comments)-1 {
-			output.WriteString("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
+			output.WriteString(forStdout("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n"))
 		}
 	}
 
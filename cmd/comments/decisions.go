@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// decisionThreadPrefix starts the line naming a record's thread; appending
+// to an existing log skips the threads it already names
+const decisionThreadPrefix = "- **Thread:** "
+
+// decisionsCommand compiles the resolved [B] and [T] threads of a document,
+// archived ones included, into an ADR-style decision log
+func decisionsCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("decisions", flag.ExitOnError)
+	output := fs.String("output", "", "Decision log to append new records to (default: print the log to stdout)")
+
+	fs.Parse(args)
+
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	archived, err := comment.LoadArchivedThreads(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading archives: %v\n", err)
+		os.Exit(1)
+	}
+	records := comment.DecisionRecords(append(doc.Threads, archived...))
+
+	if *output == "" {
+		// The log is a document, printed as is like publish's output
+		var b strings.Builder
+		b.WriteString("# Decision Log\n")
+		for i, r := range records {
+			b.WriteString(formatDecisionRecord(filename, i+1, r))
+		}
+		os.Stdout.WriteString(b.String())
+		return
+	}
+
+	existing, err := os.ReadFile(*output)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(stdout, "Error reading %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	recorded := recordedDecisions(string(existing))
+
+	var b strings.Builder
+	if len(existing) == 0 {
+		b.WriteString("# Decision Log\n")
+	}
+	added := 0
+	for _, r := range records {
+		if recorded[r.Thread.ID] {
+			continue
+		}
+		added++
+		b.WriteString(formatDecisionRecord(filename, len(recorded)+added, r))
+	}
+	if added == 0 {
+		fmt.Fprintf(stdout, "No new decisions in %s (%d already in %s)\n", filename, len(records), *output)
+		return
+	}
+
+	f, err := os.OpenFile(*output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err == nil {
+		_, err = f.WriteString(b.String())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stdout, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Added %s from %s to %s\n"), pluralize(added, "decision"), filename, *output)
+}
+
+// recordedDecisions returns the IDs of the threads a decision log already
+// has records for
+func recordedDecisions(log string) map[string]bool {
+	ids := map[string]bool{}
+	for _, line := range strings.Split(log, "\n") {
+		if rest, ok := strings.CutPrefix(line, decisionThreadPrefix); ok {
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				ids[fields[0]] = true
+			}
+		}
+	}
+	return ids
+}
+
+// formatDecisionRecord renders record n of a decision log: where the thread
+// was, who raised it and when it was settled, then its question, discussion,
+// and resolution note
+func formatDecisionRecord(filename string, n int, r comment.DecisionRecord) string {
+	var b strings.Builder
+	thread := r.Thread
+
+	fmt.Fprintf(&b, "\n## %d. %s\n\n", n, r.Title())
+	location := fmt.Sprintf("line %d", thread.Line)
+	if thread.IsDocumentLevel() {
+		location = "the whole document"
+	}
+	if thread.SectionPath != "" {
+		location = fmt.Sprintf("%s (%s)", thread.SectionPath, location)
+	}
+	fmt.Fprintf(&b, "%s%s in %s, %s\n", decisionThreadPrefix, thread.ID, filepath.Base(filename), location)
+	fmt.Fprintf(&b, "- **Raised:** [%s] by @%s on %s\n", thread.Type, thread.Author, thread.Timestamp.Format("2006-01-02"))
+	fmt.Fprintf(&b, "- **Resolved:** %s\n", r.ResolvedAt.Format("2006-01-02"))
+
+	fmt.Fprintf(&b, "\n### Context\n\n%s\n", r.Context)
+	if len(r.Discussion) > 0 {
+		b.WriteString("\n### Discussion\n\n")
+		for _, reply := range r.Discussion {
+			fmt.Fprintf(&b, "- @%s (%s): %s\n", reply.Author, reply.Timestamp.Format("2006-01-02"), strings.ReplaceAll(reply.Text, "\n", " "))
+		}
+	}
+	b.WriteString("\n### Decision\n\n")
+	if r.Resolution == nil {
+		b.WriteString("Resolved without a note.\n")
+	} else {
+		fmt.Fprintf(&b, "%s — @%s\n", r.Resolution.Text, r.Resolution.Author)
+	}
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// demoDocument is the sample markdown written by `comments demo`
+const demoDocument = `# Release Checklist
+
+This document describes how we ship a new version of the service.
+Every release follows the same steps so anyone on the team can run it.
+
+## Preparation
+
+Make sure the main branch is green before you start.
+Update the changelog with every user-facing change.
+Bump the version number in version.go.
+
+## Deployment
+
+Deploy to staging first and wait for the smoke tests.
+Run the database migrations by hand if they are needed.
+Deploy to production during business hours.
+Announce the release in the team channel.
+
+## Rollback
+
+If something goes wrong, redeploy the previous version.
+Migrations are not rolled back automatically.
+
+## Open Questions
+
+Who owns the release calendar?
+`
+
+// demoCommand writes a sample document with a populated sidecar to a
+// scratch directory and opens the TUI on it, so every feature can be
+// tried without touching real files
+func demoCommand(args []string) {
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	dir := fs.String("dir", "", "Directory to write the demo into (default: a new temp directory)")
+	noView := fs.Bool("no-view", false, "Only create the demo files; don't open the TUI")
+
+	fs.Parse(args)
+
+	if *dir == "" {
+		tmp, err := os.MkdirTemp("", "comments-demo-")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error creating demo directory: %v\n", err)
+			os.Exit(1)
+		}
+		*dir = tmp
+	} else if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Fprintf(stdout, "Error creating demo directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	filename := filepath.Join(*dir, "release-checklist.md")
+	if _, err := os.Stat(filename); err == nil {
+		fmt.Fprintf(stdout, "Error: %s already exists; choose another --dir\n", filename)
+		os.Exit(1)
+	}
+
+	doc := buildDemoDocument()
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error writing demo: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("✓ Demo document: %s\n"), filename)
+	fmt.Fprintf(stdout, forStdout("✓ Demo comments: %s\n"), comment.GetSidecarPath(filename))
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "Try these on the demo file:")
+	fmt.Fprintf(stdout, "  comments list %s --format table\n", filename)
+	fmt.Fprintf(stdout, "  comments conflicts %s\n", filename)
+	fmt.Fprintf(stdout, "  comments preview %s --diff\n", filename)
+	fmt.Fprintf(stdout, "  comments add %s --line 9 --author you --text \"Which changelog?\"\n", filename)
+
+	if *noView {
+		return
+	}
+
+	// Keep the scratch file out of the saved session
+	viewCommand(filename, []string{"--no-session"})
+}
+
+// buildDemoDocument returns the demo document with one of each kind of
+// comment: questions, a blocker with replies, a range comment, a
+// document-level comment, pending (and conflicting) suggestions, a resolved
+// thread, and an orphan
+func buildDemoDocument() *comment.DocumentWithComments {
+	content := strings.TrimSuffix(demoDocument, "\n")
+	doc := &comment.DocumentWithComments{Content: content}
+
+	// Look lines up by text so the comments follow edits to the sample
+	lineOf := func(text string) int {
+		for i, line := range strings.Split(content, "\n") {
+			if line == text {
+				return i + 1
+			}
+		}
+		panic("demo document is missing line: " + text)
+	}
+
+	add := func(c *comment.Comment) *comment.Comment {
+		c.Status = "active"
+		comment.UpdateCommentSection(c, doc)
+		comment.CaptureQuote(c, content)
+		doc.Threads = append(doc.Threads, c)
+		return c
+	}
+	reply := func(parent *comment.Comment, author, text string) {
+		parent.Replies = append(parent.Replies, comment.NewReply(author, text, parent))
+	}
+
+	overall := add(comment.NewComment("alice", comment.DocumentLine, "Could we add a short summary of who runs releases and how often?"))
+	reply(overall, "bob", "Good idea, I'll add one once the rollback section is settled.")
+
+	migrations := lineOf("Run the database migrations by hand if they are needed.")
+	blocker := add(comment.NewCommentWithType("bob", migrations, "[B] Manual migrations have bitten us twice. This must be automated before the next release.", "B"))
+	blocker.Priority = "high"
+	reply(blocker, "claude", "The deploy script could run pending migrations after staging passes. Want me to draft it?")
+	reply(blocker, "bob", "Yes please.")
+
+	add(comment.NewCommentWithType("alice", lineOf("Who owns the release calendar?"), "[Q] Is this still open, or did ops take it over?", "Q"))
+
+	rollback := add(comment.NewComment("claude", lineOf("If something goes wrong, redeploy the previous version."), "This section doesn't say who decides to roll back or how fast."))
+	rollback.EndLine = lineOf("Migrations are not rolled back automatically.")
+	comment.CaptureQuote(rollback, content)
+
+	done := add(comment.NewCommentWithType("alice", lineOf("Bump the version number in version.go."), "[T] Link to the versioning guide.", "T"))
+	done.Resolved = true
+	done.Status = "resolved"
+
+	// Two suggestions competing for the same line show up in `conflicts`
+	production := "Deploy to production during business hours."
+	add(comment.NewSuggestion("claude", lineOf(production), lineOf(production),
+		"Be specific about the deploy window",
+		production, "Deploy to production between 10:00 and 15:00 on weekdays."))
+	add(comment.NewSuggestion("bob", lineOf(production), lineOf(production),
+		"Avoid Friday deploys",
+		production, "Deploy to production during business hours, Monday to Thursday."))
+
+	changelog := "Update the changelog with every user-facing change."
+	add(comment.NewSuggestion("alice", lineOf(changelog), lineOf(changelog),
+		"Name the file",
+		changelog, "Update CHANGELOG.md with every user-facing change."))
+
+	// A comment on a line that has since been deleted becomes an orphan
+	add(comment.NewComment("bob", len(strings.Split(content, "\n"))+5, "Mention the status page here."))
+	comment.ValidateAndUpdateCommentStatus(doc)
+
+	return doc
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// digestActions describes each digest event type, in the order a digest
+// summarizes them
+var digestActions = []struct {
+	eventType string
+	label     string // In the summary line, e.g. "3 new comments"
+	verb      string // Before the location, e.g. "Commented on line 12"
+}{
+	{comment.EventCommentAdded, "new comment", "Commented on"},
+	{comment.EventSuggestionAdded, "new suggestion", "Suggested an edit to"},
+	{comment.EventCommentReplied, "reply", "Replied on"},
+	{comment.EventThreadResolved, "resolved thread", "Thread resolved on"},
+	{comment.EventSuggestionAccepted, "accepted suggestion", "Suggestion accepted on"},
+}
+
+// digestPreviewLength caps the comment text quoted for each event
+const digestPreviewLength = 80
+
+// digestCommand summarizes recent comment activity across a file or directory tree
+func digestCommand(target string, args []string) {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	sinceFlag := fs.String("since", "24h", "How far back to look (e.g., 24h, 7d, 2w)")
+	format := fs.String("format", "markdown", "Output format: markdown, email, json, atom")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "markdown" && *format != "email" && *format != "json" && *format != "atom" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: markdown, email, json, atom\n", *format)
+		os.Exit(1)
+	}
+	age, err := comment.ParseAge(*sinceFlag)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: invalid --since: %v\n", err)
+		os.Exit(1)
+	}
+	since := time.Now().Add(-age)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "no digest was printed")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	events := []comment.Event{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Reading", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) ([]comment.Event, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return nil, err
+		}
+		return comment.DigestEvents(path, doc.Threads, since), nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		events = append(events, result.Value...)
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "no digest was printed")
+
+	// Group by document, then author, each in order of first activity
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].File != events[j].File {
+			return events[i].File < events[j].File
+		}
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(events); err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+	case "atom":
+		feed, err := formatDigestAtom(target, events, since)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding feed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(feed)
+	case "email":
+		fmt.Fprint(stdout, formatDigestEmail(events, since))
+	default:
+		fmt.Fprint(stdout, formatDigestMarkdown(events, since))
+	}
+
+	if reportFileErrors(errs) {
+		os.Exit(1)
+	}
+}
+
+// digestSummary counts the events by type, e.g. "3 new comments, 1 reply"
+func digestSummary(events []comment.Event) string {
+	counts := map[string]int{}
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	parts := []string{}
+	for _, action := range digestActions {
+		if n := counts[action.eventType]; n > 0 {
+			parts = append(parts, pluralize(n, action.label))
+		}
+	}
+	if len(parts) == 0 {
+		return "No activity"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pluralize formats a count with a noun, made plural unless the count is one
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	if stem, ok := strings.CutSuffix(noun, "y"); ok {
+		return fmt.Sprintf("%d %sies", n, stem)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// digestLine describes one event, e.g. `Replied on line 12: "Looks good"`
+func digestLine(e comment.Event) string {
+	verb := e.Type
+	for _, action := range digestActions {
+		if action.eventType == e.Type {
+			verb = action.verb
+		}
+	}
+	location := fmt.Sprintf("line %d", e.Line)
+	if e.Line == comment.DocumentLine {
+		location = "the document"
+	}
+
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if len([]rune(text)) > digestPreviewLength {
+		text = string([]rune(text)[:digestPreviewLength-1]) + "…"
+	}
+	return fmt.Sprintf("%s %s: %q", verb, location, text)
+}
+
+// digestGroups splits events sorted by file into per-file, per-author
+// groups, authors in order of their first event
+func digestGroups(events []comment.Event) (files []string, authors map[string][]string, byAuthor map[string]map[string][]comment.Event) {
+	authors = map[string][]string{}
+	byAuthor = map[string]map[string][]comment.Event{}
+	for _, e := range events {
+		if byAuthor[e.File] == nil {
+			files = append(files, e.File)
+			byAuthor[e.File] = map[string][]comment.Event{}
+		}
+		if byAuthor[e.File][e.Author] == nil {
+			authors[e.File] = append(authors[e.File], e.Author)
+		}
+		byAuthor[e.File][e.Author] = append(byAuthor[e.File][e.Author], e)
+	}
+	return files, authors, byAuthor
+}
+
+// formatDigestMarkdown renders a digest for pasting into team chat or an issue
+func formatDigestMarkdown(events []comment.Event, since time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Comment digest since %s\n\n", since.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "%s\n", digestSummary(events))
+
+	files, authors, byAuthor := digestGroups(events)
+	for _, file := range files {
+		fmt.Fprintf(&b, "\n### %s\n", file)
+		for _, author := range authors[file] {
+			fmt.Fprintf(&b, "\n**@%s**\n", author)
+			for _, e := range byAuthor[file][author] {
+				fmt.Fprintf(&b, "- %s\n", digestLine(e))
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatDigestEmail renders a digest as a plain-text email, subject first
+func formatDigestEmail(events []comment.Event, since time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Subject: Comment digest: %s\n\n", digestSummary(events))
+	fmt.Fprintf(&b, "Comment activity since %s.\n", since.Format("2006-01-02 15:04"))
+
+	files, authors, byAuthor := digestGroups(events)
+	for _, file := range files {
+		fmt.Fprintf(&b, "\n%s\n%s\n", file, strings.Repeat("=", len(file)))
+		for _, author := range authors[file] {
+			fmt.Fprintf(&b, "\n  @%s\n", author)
+			for _, e := range byAuthor[file][author] {
+				fmt.Fprintf(&b, "    * %s\n", digestLine(e))
+			}
+		}
+	}
+	return b.String()
+}
+
+// atomFeed and atomEntry are the parts of an Atom feed (RFC 4287) a digest
+// fills in
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Updated  string       `xml:"updated"`
+	Author   atomAuthor   `xml:"author"`
+	Category atomCategory `xml:"category"`
+	Content  string       `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// formatDigestAtom renders a digest as an Atom feed, newest event first, for
+// feed readers. Entry IDs stay the same across runs, so a feed regenerated
+// on a schedule only shows readers what is new.
+func formatDigestAtom(target string, events []comment.Event, since time.Time) ([]byte, error) {
+	// Name the project by its absolute path, however the target was given
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+	feed := atomFeed{
+		ID:      "urn:comments:" + filepath.ToSlash(target),
+		Title:   fmt.Sprintf("Comment activity in %s", filepath.Base(target)),
+		Updated: since.UTC().Format(time.RFC3339),
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		updated := e.Timestamp.UTC().Format(time.RFC3339)
+		if len(feed.Entries) == 0 {
+			feed.Updated = updated
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:       fmt.Sprintf("urn:comments:%s:%s:%s", filepath.ToSlash(e.File), e.CommentID, e.Type),
+			Title:    fmt.Sprintf("%s: %s", e.File, digestLine(e)),
+			Updated:  updated,
+			Author:   atomAuthor{Name: e.Author},
+			Category: atomCategory{Term: e.Type},
+			Content:  e.Text,
+		})
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// doctorCommand checks a directory tree for broken, stale, or stray comment
+// files and project config problems, and with --fix makes the safe repairs.
+// Exits 1 if problems remain.
+func doctorCommand(root string, args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "Repair what can be fixed without losing comments (stale hashes, duplicate IDs, temporary files)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		fmt.Fprintf(stdout, "Error: %s is not a directory\n", root)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	remaining := 0
+	for _, problem := range configProblems(root) {
+		remaining++
+		fmt.Fprintf(stdout, forStdout("✗ config: %s\n"), problem)
+	}
+
+	problems, err := comment.Diagnose(ctx, root)
+	exitIfInterrupted(ctx, "the check did not finish")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", root, err)
+		os.Exit(1)
+	}
+
+	fixed, fixable := 0, 0
+	for _, p := range problems {
+		if !p.Fixable || !*fix {
+			if p.Fixable {
+				fixable++
+			}
+			remaining++
+			fmt.Fprintf(stdout, forStdout("⚠ %s: %s: %s\n"), p.Path, p.Kind, p.Message)
+			continue
+		}
+
+		if p.Kind != comment.ProblemTempFile {
+			enforcePolicy(p.Document, config.ActionCleanup, currentActor(*actor))
+			backupSidecar(p.Document)
+		}
+		done, err := comment.FixProblem(p)
+		if err != nil {
+			remaining++
+			fmt.Fprintf(stdout, forStdout("✗ %s: %s: %v\n"), p.Path, p.Kind, err)
+			continue
+		}
+		fixed++
+		fmt.Fprintf(stdout, forStdout("✓ %s: %s: %s\n"), p.Path, p.Kind, done)
+	}
+
+	switch {
+	case remaining == 0 && fixed == 0:
+		fmt.Fprintf(stdout, forStdout("✓ No problems found in %s\n"), root)
+	case *fix:
+		fmt.Fprintf(stdout, "\nFixed %d problem(s), %d remaining\n", fixed, remaining)
+	default:
+		fmt.Fprintf(stdout, "\nFound %d problem(s)", remaining)
+		if fixable > 0 {
+			fmt.Fprintf(stdout, ", %d fixable with --fix", fixable)
+		}
+		fmt.Fprintln(stdout)
+	}
+
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
+
+// configProblems checks the project config that applies to root
+func configProblems(root string) []string {
+	cfg, err := config.Load(root)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if cfg.SignComments {
+		path, err := config.DefaultKeyPath()
+		if err == nil {
+			_, err = config.LoadSigningKey(path)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("sign_comments is set in %s but %v", cfg.Path, err))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// draftsCommand lists, publishes, or discards the drafts saved with
+// `comments add --draft` (or the TUI's draft toggle)
+func draftsCommand(action, filename string, args []string) {
+	fs := flag.NewFlagSet("drafts "+action, flag.ExitOnError)
+	author := fs.String("author", "", "Only drafts by this author (default: $COMMENTS_AUTHOR or $USER)")
+	allAuthors := fs.Bool("all-authors", false, "Act on every author's drafts, not only your own")
+
+	fs.Parse(args)
+
+	// Drafts are personal: other people's are only touched when asked for
+	owner := currentActor(*author)
+	if *allAuthors {
+		if *author != "" {
+			fmt.Fprintln(stdout, "Error: --author and --all-authors cannot be combined")
+			os.Exit(1)
+		}
+		owner = ""
+	}
+
+	drafts, err := comment.LoadDrafts(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading drafts: %v\n", err)
+		os.Exit(1)
+	}
+	selected := comment.SelectDrafts(drafts.Threads, owner)
+
+	switch action {
+	case "list":
+		fmt.Fprintf(stdout, "Found %d draft(s) in %s\n\n", len(selected), comment.GetDraftsPath(filename))
+		for i, d := range selected {
+			fmt.Fprintf(stdout, forStdout("[%d] %s • @%s • %s\n"), i+1, draftLocation(d), d.Author, d.Timestamp.Format("2006-01-02 15:04"))
+			fmt.Fprintf(stdout, "    Draft ID: %s\n", d.ID)
+			fmt.Fprintf(stdout, "    %s\n\n", d.Text)
+		}
+
+	case "publish":
+		if len(selected) == 0 {
+			fmt.Fprintln(stdout, "No drafts to publish")
+			return
+		}
+
+		// Publishing is when a draft reaches the shared sidecar
+		for _, d := range selected {
+			action := config.ActionAdd
+			if d.IsSuggestion {
+				action = config.ActionSuggest
+			}
+			enforcePolicy(filename, action, d.Author)
+		}
+
+		doc, err := comment.LoadFromSidecar(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+			os.Exit(1)
+		}
+		changed := comment.PublishDrafts(doc, drafts, selected)
+
+		// Save the sidecar first so a failure can't lose the drafts
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+		if err := comment.RemoveDrafts(filename, draftIDs(selected)); err != nil {
+			fmt.Fprintf(stdout, "Error removing published drafts: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(stdout, forStdout("✓ Published %d draft(s) to %s\n"), len(selected), comment.GetSidecarPath(filename))
+		if changed {
+			fmt.Fprintln(stdout, forStdout("⚠ The document changed after these drafts were written; check that they still point at the right lines"))
+		}
+
+	case "discard":
+		if err := comment.RemoveDrafts(filename, draftIDs(selected)); err != nil {
+			fmt.Fprintf(stdout, "Error discarding drafts: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Discarded %d draft(s)\n"), len(selected))
+
+	default:
+		fmt.Fprintf(stdout, "Error: unknown drafts action '%s'. Valid actions: list, publish, discard\n", action)
+		os.Exit(1)
+	}
+}
+
+// draftLocation describes where a draft points, like the list command does
+func draftLocation(d *comment.Comment) string {
+	switch {
+	case d.IsDocumentLevel():
+		return "Document"
+	case d.IsRange():
+		return fmt.Sprintf("Lines %d-%d", d.Line, d.EndLine)
+	case d.SectionPath != "":
+		return fmt.Sprintf("%s (Line %d)", d.SectionPath, d.Line)
+	default:
+		return fmt.Sprintf("Line %d", d.Line)
+	}
+}
+
+// draftIDs returns the IDs of drafts
+func draftIDs(drafts []*comment.Comment) []string {
+	ids := make([]string, len(drafts))
+	for i, d := range drafts {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+// mergePrivateNotes adds the private notes of viewer (see currentActor) to doc
+func mergePrivateNotes(filename string, doc *comment.DocumentWithComments, viewer string) {
+	if err := doc.MergePrivateNotes(filename, currentActor(viewer)); err != nil {
+		fmt.Fprintf(stdout, "Error loading private notes: %v\n", err)
+		os.Exit(1)
+	}
+}
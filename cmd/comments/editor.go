@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editorCommand returns the user's editor: $VISUAL, then $EDITOR, then vi
+func editorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// editText opens initial in the user's editor and returns the saved text
+// pattern is the temp file name pattern (e.g. "merge-*.md") so editors pick
+// the right syntax highlighting.
+func editText(initial, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("creating temp file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.WriteString(initial); err != nil {
+		f.Close()
+		return "", fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("writing temp file: %w", err)
+	}
+
+	// The editor setting may include arguments (e.g. "code --wait")
+	parts := strings.Fields(editorCommand())
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running editor %s: %w", parts[0], err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading edited file: %w", err)
+	}
+	return string(edited), nil
+}
+
+// composeText opens initial in the user's editor for writing a comment or
+// reply (--edit) and returns the result without trailing whitespace
+func composeText(initial string) (string, error) {
+	text, err := editText(initial, "comment-*.md")
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimRight(text, " \t\r\n")
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("the text is empty, nothing was saved")
+	}
+	return text, nil
+}
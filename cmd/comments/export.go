@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// exportCommand writes a document's comments as JSON, or every decided
+// suggestion under a file or directory as training pairs (JSON Lines). The
+// project's redaction rules are applied to both.
+func exportCommand(target string, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "Export format: json, training-pairs")
+	output := fs.String("output", "", "Output file (default: stdout)")
+	contextLines := fs.Int("context", 5, "Lines of document context on each side of a suggestion (training-pairs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "json" && *format != "training-pairs" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: json, training-pairs\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "nothing was exported")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	var out bytes.Buffer
+	failed := false
+
+	if *format == "json" {
+		if len(docs) != 1 || docs[0] != target {
+			fmt.Fprintln(stdout, "Error: json export takes a single file; use --format training-pairs for a directory")
+			os.Exit(1)
+		}
+		doc, err := comment.ReadSidecar(target)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading comments: %v\n", err)
+			os.Exit(1)
+		}
+		redaction, err := redactionFor(target)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		jsonBytes, err := json.MarshalIndent(comment.StorageFormat{
+			Version:       comment.StorageVersion,
+			DocumentHash:  doc.DocumentHash,
+			LastValidated: doc.LastValidated,
+			Threads:       redaction.Apply(doc.Threads),
+			Reviews:       redaction.ApplyReviews(doc.Reviews),
+			AppliedKeys:   doc.AppliedKeys,
+		}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		out.Write(jsonBytes)
+		out.WriteByte('\n')
+	} else {
+		var errs comment.FileErrors
+		progress := newProgressBar("Exporting", len(docs), *noProgress)
+
+		pairs := map[string][]comment.TrainingPair{}
+		for result := range comment.RunPipelineContext(ctx, docs, 0, func(path string) ([]comment.TrainingPair, error) {
+			doc, err := comment.ReadSidecar(path)
+			if err != nil {
+				return nil, err
+			}
+			redaction, err := redactionFor(path)
+			if err != nil {
+				return nil, err
+			}
+			doc.Threads = redaction.Apply(doc.Threads)
+			return comment.TrainingPairs(doc, *contextLines), nil
+		}) {
+			progress.Step()
+			if result.Err != nil {
+				errs.Add(result.Path, result.Err)
+				continue
+			}
+			pairs[result.Path] = result.Value
+		}
+		progress.Clear()
+		exitIfInterrupted(ctx, "nothing was exported")
+
+		// One JSON object per line, in the order the documents were found
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		for _, path := range docs {
+			for _, pair := range pairs[path] {
+				pair.File = filepath.ToSlash(path)
+				if err := enc.Encode(pair); err != nil {
+					errs.Add(path, err)
+				}
+			}
+		}
+		failed = reportFileErrors(errs)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(out.Bytes())
+	} else {
+		if err := os.WriteFile(*output, out.Bytes(), 0644); err != nil {
+			fmt.Fprintf(stdout, "Error writing %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Exported to %s\n"), *output)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// findMatch is a thread matching a cross-file search
+type findMatch struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	ID         string `json:"id"`
+	Author     string `json:"author"`
+	Text       string `json:"text"`
+	Type       string `json:"type,omitempty"`
+	Status     string `json:"status"`
+	Resolved   bool   `json:"resolved"`
+	ReplyCount int    `json:"reply_count"`
+	MatchedIn  string `json:"matched_in,omitempty"` // Reply ID when the search matched a reply
+}
+
+// findCommand searches comment threads across every sidecar under a directory
+func findCommand(root string, args []string) {
+	fs := flag.NewFlagSet("find", flag.ExitOnError)
+	searchText := fs.String("search", "", "Search thread and reply text (case-insensitive)")
+	typeFilter := fs.String("type", "", "Filter by comment type: Q, S, B, T, E")
+	statusFilter := fs.String("status", "", "Filter by status: active, orphaned, resolved, completed")
+	authorFilter := fs.String("author", "", "Filter by thread author")
+	showResolved := fs.Bool("resolved", false, "Include resolved threads")
+	format := fs.String("format", "text", "Output format: text, json")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := comment.WalkDocumentsContext(ctx, root)
+	exitIfInterrupted(ctx, "search stopped early")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", root, err)
+		os.Exit(1)
+	}
+
+	query := *searchText
+
+	filters := []comment.Filter{}
+	if *typeFilter != "" {
+		filters = append(filters, comment.ByType(*typeFilter))
+	}
+	if *statusFilter != "" {
+		filters = append(filters, comment.ByStatus(*statusFilter))
+	}
+	if *authorFilter != "" {
+		filters = append(filters, comment.ByAuthor(*authorFilter))
+	}
+	filter := comment.And(filters...)
+	matches := []findMatch{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Searching", len(docs), *noProgress)
+
+	for result := range comment.ReadDocumentsParallelContext(ctx, docs, *workers) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+
+		for _, thread := range comment.GetVisibleComments(result.Doc.Threads, *showResolved) {
+			if !filter.Matches(thread) {
+				continue
+			}
+
+			match := findMatch{
+				File:       result.Path,
+				Line:       thread.Line,
+				ID:         thread.ID,
+				Author:     thread.Author,
+				Text:       thread.Text,
+				Type:       thread.Type,
+				Status:     thread.GetStatus(),
+				Resolved:   thread.Resolved,
+				ReplyCount: thread.CountReplies(),
+			}
+
+			if query != "" {
+				matchedID, ok := comment.SearchThread(thread, query)
+				if !ok {
+					continue
+				}
+				if matchedID != thread.ID {
+					match.MatchedIn = matchedID
+				}
+			}
+
+			// Stream text results as soon as each file is processed
+			if *format == "text" {
+				progress.Clear()
+				printFindMatch(match)
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	progress.Clear()
+	exitIfInterrupted(ctx, "search stopped early")
+
+	if *format == "json" {
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].File != matches[j].File {
+				return matches[i].File < matches[j].File
+			}
+			return matches[i].Line < matches[j].Line
+		})
+		jsonBytes, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Fprintf(stdout, "\nFound %d thread(s) in %d file(s) searched\n", len(matches), len(docs))
+	}
+
+	if reportFileErrors(errs) {
+		os.Exit(1)
+	}
+}
+
+// printFindMatch prints a match as a file:line reference
+func printFindMatch(m findMatch) {
+	status := m.Status
+	if m.Resolved {
+		status += ", resolved"
+	}
+	fmt.Fprintf(stdout, forStdout("%s:%d: %s • @%s • %s\n"), m.File, m.Line, m.ID, m.Author, status)
+	fmt.Fprintf(stdout, "    %s\n", m.Text)
+	if m.MatchedIn != "" {
+		fmt.Fprintf(stdout, "    (matched in reply %s)\n", m.MatchedIn)
+	}
+}
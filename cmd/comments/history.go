@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// sidecarVersions returns every committed version of a document's sidecar,
+// oldest first, by walking its git history. Commits that deleted the
+// sidecar are skipped.
+func sidecarVersions(ctx context.Context, mdPath string) ([]comment.SidecarVersion, error) {
+	sidecar := comment.GetSidecarPath(mdPath)
+	dir, name := filepath.Split(sidecar)
+	if dir == "" {
+		dir = "."
+	}
+
+	logOut, err := runGit(ctx, dir, "log", "--reverse", "--diff-filter=AM", "--format=%H %cI", "--", name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []comment.SidecarVersion{}
+	for _, line := range strings.Split(strings.TrimSpace(logOut), "\n") {
+		hash, stamp, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, stamp)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git log date %q: %w", stamp, err)
+		}
+		// ./ makes the path relative to dir rather than the repository root
+		data, err := runGit(ctx, dir, "show", hash+":./"+name)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, comment.SidecarVersion{Commit: hash, Time: at, Data: []byte(data)})
+	}
+	return versions, nil
+}
+
+// runGit runs a git command in dir and returns its output
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// historyCommand prints how many threads were open and resolved after each
+// commit touching each document's sidecar, for plotting a review burndown
+func historyCommand(ctx context.Context, docs []string, format string, workers int, noProgress bool) {
+	points := []comment.HistoryPoint{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Reading history", len(docs), noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, workers, func(path string) ([]comment.HistoryPoint, error) {
+		versions, err := sidecarVersions(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		return comment.ThreadHistory(path, versions)
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		points = append(points, result.Value...)
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "no history was printed")
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if !points[i].Time.Equal(points[j].Time) {
+			return points[i].Time.Before(points[j].Time)
+		}
+		return points[i].File < points[j].File
+	})
+
+	if format == "json" {
+		jsonBytes, err := json.MarshalIndent(points, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"time", "file", "commit", "threads", "open", "resolved", "opened", "closed"})
+		for _, p := range points {
+			w.Write([]string{
+				p.Time.Format(time.RFC3339), p.File, p.Commit,
+				strconv.Itoa(p.Threads), strconv.Itoa(p.Open), strconv.Itoa(p.Resolved),
+				strconv.Itoa(p.Opened), strconv.Itoa(p.Closed),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fmt.Fprintf(stdout, "Error writing CSV: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if reportFileErrors(errs) {
+		os.Exit(1)
+	}
+}
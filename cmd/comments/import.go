@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+	"github.com/rcliao/comments/pkg/importer"
+)
+
+// importCommand seeds a markdown document's sidecar with the comments from
+// another tool's export of the same document, placing each on the lines
+// whose text matches what it was anchored to. Word's tracked changes become
+// pending suggestions.
+func importCommand(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fromGoogle := fs.String("from-gdoc", "", "Google Docs web page export (.html, or the .zip from File > Download > Web page)")
+	fromWord := fs.String("from-docx", "", "Word document (.docx) with comments or tracked changes")
+	to := fs.String("to", "", "Markdown version of the document to add the comments to (required)")
+	author := fs.String("author", "", "Author for comments the export doesn't attribute (default: google-docs or word)")
+	dryRun := fs.Bool("dry-run", false, "Show where each comment would go without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if (*fromGoogle == "") == (*fromWord == "") || *to == "" {
+		fmt.Fprintln(stdout, "Error: --to and one of --from-gdoc or --from-docx are required")
+		fmt.Fprintln(stdout, "Usage: comments import --from-gdoc <export.zip|export.html> --to <file>")
+		fmt.Fprintln(stdout, "       comments import --from-docx <draft.docx> --to <file>")
+		os.Exit(1)
+	}
+
+	if !*dryRun {
+		enforcePolicy(*to, config.ActionAdd, currentActor(*actor))
+	}
+
+	var imported []importer.Comment
+	var changes []importer.Change
+	var err error
+	source := *fromGoogle
+	if source != "" {
+		imported, err = importer.ReadGoogleDocs(source)
+		if *author == "" {
+			*author = "google-docs"
+		}
+	} else {
+		source = *fromWord
+		imported, changes, err = importer.ReadDocx(source)
+		if *author == "" {
+			*author = "word"
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stdout, "Error reading %s: %v\n", source, err)
+		os.Exit(1)
+	}
+
+	doc, err := comment.LoadFromSidecar(*to)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	threads, _ := importer.Threads(doc.Content, imported, *author)
+	suggestions, _ := importer.Suggestions(doc.Content, changes, *author)
+	threads = append(threads, suggestions...)
+
+	// Screen before looking for earlier imports, which stored the text as
+	// the content filter left it
+	policy := loadPolicy(*to)
+	replies := []*comment.Comment{}
+	for _, thread := range threads {
+		replies = append(replies, thread.Replies...)
+	}
+	screenBatch(policy, "comment", threads, true)
+	screenBatch(policy, "reply", replies, false)
+
+	added := []*comment.Comment{}
+	unplaced, unapplied, suggested := 0, 0, 0
+	for _, thread := range threads {
+		// Importing the same export again adds nothing
+		if alreadyImported(doc.Threads, thread) {
+			continue
+		}
+		added = append(added, thread)
+		switch {
+		case thread.IsSuggestion:
+			suggested++
+		case strings.HasPrefix(thread.Text, importer.UnappliedChange):
+			unapplied++
+		case thread.IsDocumentLevel():
+			unplaced++
+		}
+	}
+	skipped := len(threads) - len(added)
+	doc.Threads = append(doc.Threads, added...)
+	comment.ComputeSectionsForComments(doc)
+
+	summary := fmt.Sprintf("%d comment(s) from %s", len(added)-suggested, source)
+	if *fromWord != "" {
+		summary = fmt.Sprintf("%d comment(s) and %d suggestion(s) from %s", len(added)-suggested, suggested, source)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(" (%d already imported)", skipped)
+	}
+	if *dryRun {
+		for _, thread := range added {
+			text, _, _ := strings.Cut(thread.Text, "\n")
+			fmt.Fprintf(stdout, forStdout("  @%s • %s: %s\n"), thread.Author, draftLocation(thread), text)
+		}
+		fmt.Fprintf(stdout, "Would import %s\n", summary)
+		fmt.Fprintln(stdout, "Dry run: nothing was saved")
+		return
+	}
+	if suggested > 0 {
+		enforcePolicy(*to, config.ActionSuggest, currentActor(*actor))
+	}
+	if len(added) > 0 {
+		if err := comment.SaveToSidecar(*to, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving comments: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Imported %s into %s\n"), summary, *to)
+	if unplaced > 0 {
+		fmt.Fprintf(stdout, "  %d comment(s) whose text wasn't found in %s were added to the document as a whole; move them with 'comments reattach'\n", unplaced, *to)
+	}
+	if unapplied > 0 {
+		fmt.Fprintf(stdout, "  %d tracked change(s) that couldn't be made to the markdown were added as comments describing them\n", unapplied)
+	}
+}
+
+// alreadyImported reports whether threads has a thread with the same
+// author, text, and lines as thread
+func alreadyImported(threads []*comment.Comment, thread *comment.Comment) bool {
+	first, last := thread.LineRange()
+	for _, t := range threads {
+		tFirst, tLast := t.LineRange()
+		if t.Author == thread.Author && t.Text == thread.Text && tFirst == first && tLast == last {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitInterrupted is the conventional exit status after Ctrl+C (128 + SIGINT)
+const exitInterrupted = 130
+
+// interruptContext returns a context cancelled by Ctrl+C or SIGTERM, so long
+// operations can stop between files or items instead of dying mid-write.
+// A second Ctrl+C exits immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// exitIfInterrupted exits with a note on stderr if ctx was cancelled
+// what describes the outcome, e.g. "no changes were saved".
+func exitIfInterrupted(ctx context.Context, what string) {
+	if ctx.Err() == nil {
+		return
+	}
+	fmt.Fprintf(stderr, "\nInterrupted; %s\n", what)
+	os.Exit(exitInterrupted)
+}
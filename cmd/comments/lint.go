@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// lintEntry is a lint violation in `lint-comments` JSON output
+type lintEntry struct {
+	File    string `json:"file"`
+	Comment string `json:"comment"`
+	Author  string `json:"author"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// lintCommentsCommand checks existing comments against the project's lint
+// rules (lint in the project config) and fails if any break them
+func lintCommentsCommand(target string, args []string) {
+	fs := flag.NewFlagSet("lint-comments", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json")
+	quiet := fs.Bool("quiet", false, "Only print files with problems")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "not every file was linted")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	results := map[string][]config.LintIssue{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Linting", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) ([]config.LintIssue, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := config.LoadForDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		return policy.LintDocument(doc), nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		results[result.Path] = result.Value
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "not every file was linted")
+
+	paths := make([]string, 0, len(results))
+	for path := range results {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	violations := 0
+	for _, path := range paths {
+		violations += len(results[path])
+	}
+
+	if *format == "json" {
+		entries := []lintEntry{}
+		for _, path := range paths {
+			for _, issue := range results[path] {
+				entries = append(entries, lintEntry{
+					File:    path,
+					Comment: issue.Comment.ID,
+					Author:  issue.Comment.Author,
+					Line:    issue.Comment.Line,
+					Rule:    issue.Rule,
+					Message: issue.Message,
+				})
+			}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, path := range paths {
+			issues := results[path]
+			if len(issues) == 0 {
+				if !*quiet {
+					fmt.Fprintf(stdout, forStdout("✓ %s\n"), path)
+				}
+				continue
+			}
+
+			fmt.Fprintf(stdout, forStdout("⚠ %s: %d lint violation(s)\n"), path, len(issues))
+			for _, issue := range issues {
+				c := issue.Comment
+				fmt.Fprintf(stdout, forStdout("    %s • @%s • %s • %s: %s\n"), c.ID, c.Author, draftLocation(c), issue.Rule, issue.Message)
+			}
+		}
+		if violations > 0 {
+			fmt.Fprintf(stdout, "\n%d comment lint violation(s)\n", violations)
+		}
+	}
+
+	if reportFileErrors(errs) || violations > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,69 +2,20 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rcliao/comments/pkg/comment"
 )
 
-// filterByAuthor filters comments by author name
-func filterByAuthor(comments []*comment.Comment, author string) []*comment.Comment {
-	result := make([]*comment.Comment, 0)
-	for _, c := range comments {
-		if c.Author == author {
-			result = append(result, c)
-		}
-	}
-	return result
-}
-
-// filterBySearch filters comments by text search (case-insensitive)
-func filterBySearch(comments []*comment.Comment, query string) []*comment.Comment {
-	query = strings.ToLower(query)
-	result := make([]*comment.Comment, 0)
-	for _, c := range comments {
-		if strings.Contains(strings.ToLower(c.Text), query) {
-			result = append(result, c)
-		}
-	}
-	return result
-}
-
-// filterByLineRange filters comments within a line range
-func filterByLineRange(comments []*comment.Comment, lineRange string) ([]*comment.Comment, error) {
-	parts := strings.Split(lineRange, "-")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid line range format. Expected: start-end (e.g., 10-30)")
-	}
-
-	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-	if err != nil {
-		return nil, fmt.Errorf("invalid start line: %s", parts[0])
-	}
-
-	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return nil, fmt.Errorf("invalid end line: %s", parts[1])
-	}
-
-	if start > end {
-		return nil, fmt.Errorf("start line (%d) must be less than or equal to end line (%d)", start, end)
-	}
-
-	result := make([]*comment.Comment, 0)
-	for _, c := range comments {
-		if c.Line >= start && c.Line <= end {
-			result = append(result, c)
-		}
-	}
-	return result, nil
-}
-
-// sortComments sorts comments by the specified field
+// sortComments sorts comments by the specified field, pinned threads first
 func sortComments(comments []*comment.Comment, sortBy string) {
 	switch sortBy {
 	case "line":
@@ -79,148 +30,314 @@ func sortComments(comments []*comment.Comment, sortBy string) {
 		sort.Slice(comments, func(i, j int) bool {
 			return comments[i].Author < comments[j].Author
 		})
+	case "activity":
+		// Most recent first
+		sort.SliceStable(comments, func(i, j int) bool {
+			return comments[i].LatestTimestamp().After(comments[j].LatestTimestamp())
+		})
+	case "score":
+		// Most urgent first
+		comment.SortByScore(comments)
 	}
+	// Pinned threads come first whatever the sort
+	comment.PinnedFirst(comments)
 }
 
-// outputTable outputs comment threads in table format (v2.0)
-func outputTable(threads []*comment.Comment, allThreads []*comment.Comment) {
-	// Simple ASCII table
-	fmt.Println("┌──────┬──────────────┬──────────┬─────────┬────────────────────────────────────────┐")
-	fmt.Println("│ Line │ Author       │ Type     │ Replies │ Preview                                │")
-	fmt.Println("├──────┼──────────────┼──────────┼─────────┼────────────────────────────────────────┤")
+// parseThreadFilter builds a thread filter from spec, a string of list's
+// filter flags (e.g. "--type T --author bot"), quoted like a shell command
+// line. It also reports whether --resolved asked for resolved threads.
+func parseThreadFilter(doc *comment.DocumentWithComments, spec string) (comment.Filter, bool, error) {
+	args, err := splitFilterArgs(spec)
+	if err != nil {
+		return nil, false, err
+	}
 
-	for _, thread := range threads {
-		commentType := "Root"
-		replyCount := thread.CountReplies()
+	fs := flag.NewFlagSet("filter", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	typeFilter := fs.String("type", "", "")
+	authorFilter := fs.String("author", "", "")
+	searchText := fs.String("search", "", "")
+	lineRange := fs.String("line-range", "", "")
+	sectionFilter := fs.String("section", "", "")
+	statusFilter := fs.String("status", "", "")
+	priorityFilter := fs.String("priority", "", "")
+	showResolved := fs.Bool("resolved", false, "")
+	suggestionsOnly := fs.Bool("suggestions", false, "")
+	changedOnly := fs.Bool("changed-only", false, "")
+	pendingOnly := fs.Bool("pending", false, "")
+	acceptedOnly := fs.Bool("accepted", false, "")
+	rejectedOnly := fs.Bool("rejected", false, "")
+	if err := fs.Parse(args); err != nil {
+		return nil, false, fmt.Errorf("filter: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("filter: unexpected argument %q", fs.Arg(0))
+	}
 
-		// Create preview (truncate if too long)
-		preview := thread.Text
-		if len(preview) > 40 {
-			preview = preview[:37] + "..."
+	filters := []comment.Filter{}
+	if *typeFilter != "" {
+		filters = append(filters, comment.ByType(*typeFilter))
+	}
+	if *authorFilter != "" {
+		filters = append(filters, comment.ByAuthor(*authorFilter))
+	}
+	if *searchText != "" {
+		filters = append(filters, comment.BySearch(*searchText))
+	}
+	if *lineRange != "" {
+		start, end, err := comment.ParseLineRange(*lineRange)
+		if err != nil {
+			return nil, false, fmt.Errorf("filter: %w", err)
 		}
-
-		resolvedMarker := ""
-		if thread.Resolved {
-			resolvedMarker = " ✓"
+		filters = append(filters, comment.ByLineRange(start, end))
+	}
+	if *sectionFilter != "" {
+		inSection, err := comment.InSection(doc, *sectionFilter)
+		if err != nil {
+			return nil, false, fmt.Errorf("filter: %w", err)
 		}
-
-		// Format row with padding
-		fmt.Printf("│ %-4d │ %-12s │ %-8s │ %-7d │ %-40s │\n",
-			thread.Line,
-			truncateString(thread.Author, 12),
-			truncateString(commentType+resolvedMarker, 8),
-			replyCount,
-			preview,
-		)
+		filters = append(filters, inSection)
+	}
+	if *statusFilter != "" {
+		filters = append(filters, comment.ByStatus(*statusFilter))
+	}
+	if *priorityFilter != "" {
+		filters = append(filters, comment.ByPriority(*priorityFilter))
+	}
+	if *pendingOnly || *acceptedOnly || *rejectedOnly {
+		states := []comment.Filter{}
+		if *pendingOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionPending))
+		}
+		if *acceptedOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionAccepted))
+		}
+		if *rejectedOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionRejected))
+		}
+		filters = append(filters, comment.Or(states...))
+	} else if *suggestionsOnly {
+		filters = append(filters, comment.BySuggestionState(""))
+	}
+	if *changedOnly {
+		filters = append(filters, comment.ByTargetChanged(doc.Content))
 	}
 
-	fmt.Println("└──────┴──────────────┴──────────┴─────────┴────────────────────────────────────────┘")
-	fmt.Printf("\nTotal: %d comment thread(s)\n", len(threads))
+	// Decided suggestions are usually resolved, as in list
+	return comment.And(filters...), *showResolved || *acceptedOnly || *rejectedOnly, nil
 }
 
-// truncateString truncates a string to a max length
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// splitFilterArgs splits a filter spec into arguments at spaces outside
+// single or double quotes
+func splitFilterArgs(spec string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range spec {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("filter: unterminated quote")
 	}
-	return s[:maxLen-1] + "…"
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
 }
 
-// outputJSON outputs comment threads in JSON format (v2.0)
-func outputJSON(threads []*comment.Comment, allThreads []*comment.Comment, docContent string, withContext bool) error {
-	// Create a simplified output structure
-	type ContextLine struct {
-		LineNum  int    `json:"line_num"`
-		Text     string `json:"text"`
-		IsTarget bool   `json:"is_target"`
-	}
-
-	type CommentOutput struct {
-		ID             string        `json:"id"`
-		Author         string        `json:"author"`
-		Line           int           `json:"line"`
-		Timestamp      string        `json:"timestamp"`
-		Text           string        `json:"text"`
-		Type           string        `json:"type,omitempty"`
-		Status         string        `json:"status"`
-		Priority       string        `json:"priority"`
-		Resolved       bool          `json:"resolved"`
-		ReplyCount     int           `json:"reply_count"`
-		SectionPath    string        `json:"section_path,omitempty"`
-		OrphanedReason string        `json:"orphaned_reason,omitempty"`
-		// Context fields (only included when --with-context is specified)
-		LineContent    string        `json:"line_content,omitempty"`
-		ContextBefore  string        `json:"context_before,omitempty"`
-		ContextAfter   string        `json:"context_after,omitempty"`
-		ContextLines   []ContextLine `json:"context_lines,omitempty"`
+// contextLine is a document line around a comment in list output
+type contextLine struct {
+	LineNum  int    `json:"line_num"`
+	Text     string `json:"text"`
+	IsTarget bool   `json:"is_target"`
+}
+
+// suggestionOutput is the proposed edit of a suggestion in list output
+type suggestionOutput struct {
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	OriginalText string   `json:"original_text"`
+	ProposedText string   `json:"proposed_text"`
+	State        string   `json:"state"` // pending, accepted, or rejected
+	DependsOn    []string `json:"depends_on,omitempty"`
+	MoveAfter    *int     `json:"move_after,omitempty"` // Moves only: the line the lines go after
+}
+
+// commentOutput is a thread as `list --format json` writes it, and what
+// --template sees
+type commentOutput struct {
+	ID             string `json:"id"`
+	Author         string `json:"author"`
+	Line           int    `json:"line"`
+	EndLine        int    `json:"end_line,omitempty"`
+	DocumentLevel  bool   `json:"document_level,omitempty"`
+	Timestamp      string `json:"timestamp"`
+	LastActivity   string `json:"last_activity"` // Latest timestamp in the thread, replies included
+	Text           string `json:"text"`
+	Type           string `json:"type,omitempty"`
+	Status         string `json:"status"`
+	Priority       string `json:"priority"`
+	Resolved       bool   `json:"resolved"`
+	ReplyCount     int    `json:"reply_count"`
+	SectionPath    string `json:"section_path,omitempty"`
+	OrphanedReason string `json:"orphaned_reason,omitempty"`
+	DriftedFrom    int    `json:"drifted_from,omitempty"` // Line before the comment drifted to the end
+	Changed        bool   `json:"changed,omitempty"`      // The text under the comment changed since it was made
+	Archived       bool   `json:"archived,omitempty"`
+	Pinned         bool   `json:"pinned,omitempty"`
+	Visibility     string `json:"visibility"` // shared, or private for the viewer's own notes
+	// The proposed edit, on suggestions only
+	Suggestion *suggestionOutput `json:"suggestion,omitempty"`
+	// Context fields (only included when --with-context is specified)
+	Quote         string        `json:"quote,omitempty"`
+	LineContent   string        `json:"line_content,omitempty"`
+	ContextBefore string        `json:"context_before,omitempty"`
+	ContextAfter  string        `json:"context_after,omitempty"`
+	ContextLines  []contextLine `json:"context_lines,omitempty"`
+	// Nested replies (only included when --include-replies is specified)
+	Replies []commentOutput `json:"replies,omitempty"`
+}
+
+// newCommentOutput builds the output of a thread. lines is the document's
+// content split into lines; contextSize controls how many lines before/after
+// are included when withContext is set.
+func newCommentOutput(thread *comment.Comment, lines []string, withContext bool, contextSize int, archived bool) commentOutput {
+	commentOut := commentOutput{
+		ID:             thread.ID,
+		Author:         thread.Author,
+		Line:           thread.Line,
+		EndLine:        thread.EndLine,
+		DocumentLevel:  thread.IsDocumentLevel(),
+		Timestamp:      thread.Timestamp.UTC().Format(time.RFC3339),
+		LastActivity:   thread.LatestTimestamp().UTC().Format(time.RFC3339),
+		Text:           thread.Text,
+		Type:           thread.Type,
+		Status:         thread.GetStatus(),
+		Priority:       thread.GetPriority(),
+		Resolved:       thread.Resolved,
+		ReplyCount:     thread.CountReplies(),
+		SectionPath:    thread.SectionPath,
+		OrphanedReason: thread.OrphanedReason,
+		DriftedFrom:    thread.DriftedFrom,
+		Changed:        thread.TargetChanged(lines),
+		Archived:       archived,
+		Pinned:         thread.Pinned,
+		Visibility:     comment.VisibilityShared,
+	}
+	if thread.IsPrivate() {
+		commentOut.Visibility = comment.VisibilityPrivate
 	}
 
-	lines := strings.Split(docContent, "\n")
+	if thread.IsSuggestion {
+		commentOut.Suggestion = &suggestionOutput{
+			StartLine:    thread.StartLine,
+			EndLine:      thread.EndLine,
+			OriginalText: thread.OriginalText,
+			ProposedText: thread.ProposedText,
+			State:        thread.SuggestionState(),
+			DependsOn:    thread.DependsOn,
+		}
+		if thread.IsMove {
+			commentOut.Suggestion.MoveAfter = &thread.MoveAfter
+		}
+	}
 
-	output := make([]CommentOutput, 0, len(threads))
-	for _, thread := range threads {
-		commentOut := CommentOutput{
-			ID:             thread.ID,
-			Author:         thread.Author,
-			Line:           thread.Line,
-			Timestamp:      thread.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-			Text:           thread.Text,
-			Type:           thread.Type,
-			Status:         thread.GetStatus(),
-			Priority:       thread.GetPriority(),
-			Resolved:       thread.Resolved,
-			ReplyCount:     thread.CountReplies(),
-			SectionPath:    thread.SectionPath,
-			OrphanedReason: thread.OrphanedReason,
-		}
-
-		// Add context if requested
-		if withContext && thread.Line > 0 && thread.Line <= len(lines) {
-			// Line content
-			commentOut.LineContent = lines[thread.Line-1]
-
-			// Context lines (5 before and 5 after)
-			contextSize := 5
-			start := thread.Line - contextSize
-			if start < 1 {
-				start = 1
-			}
-			end := thread.Line + contextSize
-			if end > len(lines) {
-				end = len(lines)
-			}
+	if withContext {
+		commentOut.Quote = thread.Quote
+	}
+
+	// Add context if requested
+	if withContext && thread.Line > 0 && thread.Line <= len(lines) {
+		// Line content
+		commentOut.LineContent = lines[thread.Line-1]
 
-			// Build context before
-			var beforeLines []string
-			for i := start; i < thread.Line; i++ {
-				if i > 0 && i <= len(lines) {
-					beforeLines = append(beforeLines, lines[i-1])
-				}
+		// Context lines (contextSize before and after)
+		start := thread.Line - contextSize
+		if start < 1 {
+			start = 1
+		}
+		// Range comments include their whole range as the target
+		first, last := thread.LineRange()
+		end := last + contextSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		// Build context before
+		var beforeLines []string
+		for i := start; i < thread.Line; i++ {
+			if i > 0 && i <= len(lines) {
+				beforeLines = append(beforeLines, lines[i-1])
 			}
-			commentOut.ContextBefore = strings.Join(beforeLines, "\n")
-
-			// Build context after
-			var afterLines []string
-			for i := thread.Line + 1; i <= end; i++ {
-				if i > 0 && i <= len(lines) {
-					afterLines = append(afterLines, lines[i-1])
-				}
+		}
+		commentOut.ContextBefore = strings.Join(beforeLines, "\n")
+
+		// Build context after
+		var afterLines []string
+		for i := last + 1; i <= end; i++ {
+			if i > 0 && i <= len(lines) {
+				afterLines = append(afterLines, lines[i-1])
 			}
-			commentOut.ContextAfter = strings.Join(afterLines, "\n")
-
-			// Build detailed context lines
-			commentOut.ContextLines = make([]ContextLine, 0)
-			for i := start; i <= end; i++ {
-				if i > 0 && i <= len(lines) {
-					commentOut.ContextLines = append(commentOut.ContextLines, ContextLine{
-						LineNum:  i,
-						Text:     lines[i-1],
-						IsTarget: i == thread.Line,
-					})
-				}
+		}
+		commentOut.ContextAfter = strings.Join(afterLines, "\n")
+
+		// Build detailed context lines
+		commentOut.ContextLines = make([]contextLine, 0)
+		for i := start; i <= end; i++ {
+			if i > 0 && i <= len(lines) {
+				commentOut.ContextLines = append(commentOut.ContextLines, contextLine{
+					LineNum:  i,
+					Text:     lines[i-1],
+					IsTarget: i >= first && i <= last,
+				})
 			}
 		}
+	}
 
+	return commentOut
+}
+
+// replyOutputs builds the output of a reply tree, without document context
+func replyOutputs(replies []*comment.Comment, archived bool) []commentOutput {
+	output := make([]commentOutput, 0, len(replies))
+	for _, reply := range replies {
+		replyOut := newCommentOutput(reply, nil, false, 0, archived)
+		replyOut.Replies = replyOutputs(reply.Replies, archived)
+		output = append(output, replyOut)
+	}
+	return output
+}
+
+// outputJSON outputs comment threads in JSON format (v2.0)
+// contextSize controls how many lines before/after are included when withContext is set
+// archivedIDs marks threads loaded from cleanup archives
+// includeReplies embeds each thread's nested replies
+func outputJSON(threads []*comment.Comment, allThreads []*comment.Comment, docContent string, withContext bool, contextSize int, archivedIDs map[string]bool, includeReplies bool) error {
+	lines := strings.Split(docContent, "\n")
+
+	output := make([]commentOutput, 0, len(threads))
+	for _, thread := range threads {
+		commentOut := newCommentOutput(thread, lines, withContext, contextSize, archivedIDs[thread.ID])
+		if includeReplies {
+			commentOut.Replies = replyOutputs(thread.Replies, archivedIDs[thread.ID])
+		}
 		output = append(output, commentOut)
 	}
 
@@ -229,3 +346,85 @@ func outputJSON(threads []*comment.Comment, allThreads []*comment.Comment, docCo
 	encoder.SetEscapeHTML(false)
 	return encoder.Encode(output)
 }
+
+// outputMarkdown outputs comment threads as a markdown checklist grouped by
+// section, for pasting into a PR description or issue. Resolved threads are
+// checked off. archivedIDs marks threads loaded from cleanup archives.
+func outputMarkdown(threads []*comment.Comment, filename string, archivedIDs map[string]bool) {
+	resolved := 0
+	for _, thread := range threads {
+		if thread.Resolved {
+			resolved++
+		}
+	}
+	fmt.Fprintf(stdout, "## Review comments: %s\n\n", filepath.Base(filename))
+	fmt.Fprintf(stdout, "%d open, %d resolved\n", len(threads)-resolved, resolved)
+
+	// Sections in the order their first thread is listed
+	var sections []string
+	bySection := make(map[string][]*comment.Comment)
+	for _, thread := range threads {
+		if _, ok := bySection[thread.SectionPath]; !ok {
+			sections = append(sections, thread.SectionPath)
+		}
+		bySection[thread.SectionPath] = append(bySection[thread.SectionPath], thread)
+	}
+
+	for _, section := range sections {
+		heading := section
+		if heading == "" {
+			heading = "(No section)"
+		}
+		fmt.Fprintf(stdout, "\n### %s\n\n", heading)
+		for _, thread := range bySection[section] {
+			fmt.Fprintln(stdout, markdownChecklistItem(thread, archivedIDs[thread.ID]))
+		}
+	}
+}
+
+// markdownChecklistItem formats a thread as a checklist item, e.g.
+// "- [ ] **[B]** Line 42 (@alice): Fix this"
+func markdownChecklistItem(thread *comment.Comment, archived bool) string {
+	var b strings.Builder
+	if thread.Resolved {
+		b.WriteString("- [x] ")
+	} else {
+		b.WriteString("- [ ] ")
+	}
+
+	text := thread.Text
+	if thread.Type != "" {
+		label := "[" + thread.Type + "]"
+		text = strings.TrimPrefix(text, label)
+		fmt.Fprintf(&b, "**%s** ", label)
+	}
+
+	switch {
+	case thread.IsDocumentLevel():
+		b.WriteString("Document")
+	case thread.IsRange():
+		fmt.Fprintf(&b, "Lines %d-%d", thread.Line, thread.EndLine)
+	default:
+		fmt.Fprintf(&b, "Line %d", thread.Line)
+	}
+	fmt.Fprintf(&b, " (@%s): %s", thread.Author, strings.Join(strings.Fields(text), " "))
+
+	var notes []string
+	if n := thread.CountReplies(); n == 1 {
+		notes = append(notes, "1 reply")
+	} else if n > 1 {
+		notes = append(notes, fmt.Sprintf("%d replies", n))
+	}
+	if thread.GetStatus() == "orphaned" {
+		notes = append(notes, "orphaned")
+	} else if thread.IsDrifted() {
+		notes = append(notes, fmt.Sprintf("drifted from line %d", thread.DriftedFrom))
+	}
+	if archived {
+		notes = append(notes, "archived")
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(&b, " _(%s)_", strings.Join(notes, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// setupLogging removes the global --verbose and --log-file flags from args,
+// wherever they appear before "--", and installs a logger for them.
+// --verbose traces to stderr; --log-file appends JSON records to a file,
+// keeping stdout clean for agents. Without either flag nothing is logged.
+// Returns the remaining args and a function that closes the log file.
+func setupLogging(args []string) ([]string, func()) {
+	verbose := false
+	logFile := ""
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			rest = append(rest, args[i:]...)
+			i = len(args)
+		case arg == "--verbose" || arg == "-verbose":
+			verbose = true
+		case arg == "--log-file" || arg == "-log-file":
+			if i+1 >= len(args) {
+				fmt.Fprintln(stdout, "Error: --log-file requires a path")
+				os.Exit(1)
+			}
+			i++
+			logFile = args[i]
+		case strings.HasPrefix(arg, "--log-file=") || strings.HasPrefix(arg, "-log-file="):
+			logFile = arg[strings.Index(arg, "=")+1:]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if !verbose && logFile == "" {
+		// The default logger would print Info and above to stderr
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return rest, func() {}
+	}
+
+	var handlers []slog.Handler
+	closeLog := func() {}
+	if verbose {
+		handlers = append(handlers, slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error opening log file: %v\n", err)
+			os.Exit(1)
+		}
+		handlers = append(handlers, slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		closeLog = func() { f.Close() }
+	}
+
+	logger := slog.New(teeHandler(handlers))
+	slog.SetDefault(logger)
+	comment.SetLogger(logger)
+	return rest, closeLog
+}
+
+// teeHandler sends each record to every handler that accepts its level
+type teeHandler []slog.Handler
+
+var _ slog.Handler = teeHandler(nil)
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range t {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make(teeHandler, len(t))
+	for i, h := range t {
+		next[i] = h.WithAttrs(attrs)
+	}
+	return next
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	next := make(teeHandler, len(t))
+	for i, h := range t {
+		next[i] = h.WithGroup(name)
+	}
+	return next
+}
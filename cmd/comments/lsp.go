@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/lsp"
+)
+
+// lspCommand runs the editor integration server over stdio
+// Stdout carries the protocol stream, so all diagnostics go to stderr.
+func lspCommand(args []string) {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	author := fs.String("author", "", "Author name for replies (default: $USER; editors may override via initializationOptions)")
+
+	fs.Parse(args)
+
+	if *author == "" {
+		*author = os.Getenv("USER")
+	}
+	if *author == "" {
+		*author = "user"
+	}
+
+	server := lsp.NewServer(os.Stdin, os.Stdout, *author)
+	if err := server.Run(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
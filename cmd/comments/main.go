@@ -1,168 +1,506 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"text/template"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rcliao/comments/pkg/comment"
-	"github.com/rcliao/comments/pkg/markdown"
+	"github.com/rcliao/comments/pkg/config"
 	"github.com/rcliao/comments/pkg/tui"
 )
 
 func main() {
+	args, closeLog := setupLogging(setupOutput(os.Args))
+	defer closeLog()
+	os.Args = setupAutoRestore(setupSidecarLocation(args))
+
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
 	command := os.Args[1]
+	slog.Debug("running command", "command", command, "args", os.Args[2:])
 
 	switch command {
 	case "view":
 		// View command can be called with or without a filename
 		var filename string
-		if len(os.Args) >= 3 {
-			filename = os.Args[2]
+		args := os.Args[2:]
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			filename = args[0]
+			args = args[1:]
 		}
-		viewCommand(filename)
+		viewCommand(filename, args)
 
 	case "list":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments list <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments list <file> [flags]")
 			os.Exit(1)
 		}
 		listCommand(os.Args[2], os.Args[3:])
 
+	case "find":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments find <dir> [flags]")
+			os.Exit(1)
+		}
+		findCommand(os.Args[2], os.Args[3:])
+
+	case "export":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments export <file|dir> [flags]")
+			os.Exit(1)
+		}
+		exportCommand(os.Args[2], os.Args[3:])
+
+	case "stats":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments stats <file|dir> [flags]")
+			os.Exit(1)
+		}
+		statsCommand(os.Args[2], os.Args[3:])
+
+	case "digest":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments digest <file|dir> [flags]")
+			os.Exit(1)
+		}
+		digestCommand(os.Args[2], os.Args[3:])
+
+	case "validate":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments validate <file|dir> [flags]")
+			os.Exit(1)
+		}
+		validateCommand(os.Args[2], os.Args[3:])
+
+	case "check":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments check <file|dir> [flags]")
+			os.Exit(1)
+		}
+		checkCommand(os.Args[2], os.Args[3:])
+
+	case "status-report":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments status-report <file|dir> [flags]")
+			os.Exit(1)
+		}
+		statusReportCommand(os.Args[2], os.Args[3:])
+
+	case "lint-comments":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments lint-comments <file|dir> [flags]")
+			os.Exit(1)
+		}
+		lintCommentsCommand(os.Args[2], os.Args[3:])
+
+	case "doctor":
+		root := "."
+		args := os.Args[2:]
+		if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+			root, args = args[0], args[1:]
+		}
+		doctorCommand(root, args)
+
 	case "get":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments get <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments get <file> [flags]")
 			os.Exit(1)
 		}
 		getCommand(os.Args[2], os.Args[3:])
 
 	case "add":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments add <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments add <file> [flags]")
 			os.Exit(1)
 		}
 		addCommand(os.Args[2], os.Args[3:])
 
 	case "batch-add":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments batch-add <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments batch-add <file> [flags]")
 			os.Exit(1)
 		}
 		batchAddCommand(os.Args[2], os.Args[3:])
 
 	case "reply":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments reply <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments reply <file> [flags]")
 			os.Exit(1)
 		}
 		replyCommand(os.Args[2], os.Args[3:])
 
 	case "batch-reply":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments batch-reply <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments batch-reply <file> [flags]")
 			os.Exit(1)
 		}
 		batchReplyCommand(os.Args[2], os.Args[3:])
 
 	case "resolve":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments resolve <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments resolve <file> [flags]")
 			os.Exit(1)
 		}
 		resolveCommand(os.Args[2], os.Args[3:])
 
 	case "suggest":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments suggest <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments suggest <file> [flags]")
 			os.Exit(1)
 		}
 		suggestCommand(os.Args[2], os.Args[3:])
 
 	case "accept":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments accept <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments accept <file> [flags]")
 			os.Exit(1)
 		}
 		acceptCommand(os.Args[2], os.Args[3:])
 
 	case "reject":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments reject <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments reject <file> [flags]")
 			os.Exit(1)
 		}
 		rejectCommand(os.Args[2], os.Args[3:])
 
 	case "batch-accept":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments batch-accept <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments batch-accept <file> [flags]")
 			os.Exit(1)
 		}
 		batchAcceptCommand(os.Args[2], os.Args[3:])
 
+	case "conflicts":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments conflicts <file> [flags]")
+			os.Exit(1)
+		}
+		conflictsCommand(os.Args[2], os.Args[3:])
+
+	case "preview":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments preview <file> [flags]")
+			os.Exit(1)
+		}
+		previewCommand(os.Args[2], os.Args[3:])
+
 	case "status":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments status <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments status <file> [flags]")
 			os.Exit(1)
 		}
 		statusCommand(os.Args[2], os.Args[3:])
 
+	case "bulk-status":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments bulk-status <file> --filter '<list flags>' --set <status> [--dry-run]")
+			os.Exit(1)
+		}
+		bulkStatusCommand(os.Args[2], os.Args[3:])
+
+	case "sync":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments sync <file> [--dry-run]")
+			os.Exit(1)
+		}
+		syncCommand(os.Args[2], os.Args[3:])
+
+	case "pin":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments pin <file> --comment <id> [--unpin]")
+			os.Exit(1)
+		}
+		pinCommand(os.Args[2], os.Args[3:])
+
+	case "shift":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments shift <file> --after-line N --by M")
+			os.Exit(1)
+		}
+		shiftCommand(os.Args[2], os.Args[3:])
+
 	case "reattach":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments reattach <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments reattach <file> [flags]")
 			os.Exit(1)
 		}
 		reattachCommand(os.Args[2], os.Args[3:])
 
 	case "cleanup":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: comments cleanup <file> [flags]")
+			fmt.Fprintln(stdout, "Usage: comments cleanup <file> [flags]")
 			os.Exit(1)
 		}
 		cleanupCommand(os.Args[2], os.Args[3:])
 
+	case "restore":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments restore <file> [flags]")
+			os.Exit(1)
+		}
+		restoreCommand(os.Args[2], os.Args[3:])
+
+	case "verify":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments verify <file> [flags]")
+			os.Exit(1)
+		}
+		verifyCommand(os.Args[2], os.Args[3:])
+
+	case "publish":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments publish <file> [flags]")
+			os.Exit(1)
+		}
+		publishCommand(os.Args[2], os.Args[3:])
+
+	case "bridge":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments bridge <dir> [--addr :8080]")
+			os.Exit(1)
+		}
+		bridgeCommand(os.Args[2], os.Args[3:])
+
+	case "mail-in":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments mail-in <dir> < message.eml")
+			os.Exit(1)
+		}
+		mailInCommand(os.Args[2], os.Args[3:])
+
+	case "decisions":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments decisions <file> [--output decisions.md]")
+			os.Exit(1)
+		}
+		decisionsCommand(os.Args[2], os.Args[3:])
+
+	case "storage":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments storage <file> [flags]")
+			os.Exit(1)
+		}
+		storageCommand(os.Args[2], os.Args[3:])
+
+	case "migrate":
+		migrateCommand(os.Args[2:])
+
+	case "import":
+		importCommand(os.Args[2:])
+
+	case "merge-reviews":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(stdout, "Usage: comments merge-reviews <file> <copy.json>... [flags]")
+			os.Exit(1)
+		}
+		mergeReviewsCommand(os.Args[2], os.Args[3:])
+
+	case "keygen":
+		keygenCommand(os.Args[2:])
+
+	case "tail":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments tail <file|dir> [flags]")
+			os.Exit(1)
+		}
+		tailCommand(os.Args[2], os.Args[3:])
+
+	case "lsp":
+		lspCommand(os.Args[2:])
+
+	case "queue":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(stdout, "Usage: comments queue <add|reply|suggest|list|review|flush|discard> <file> [flags]")
+			os.Exit(1)
+		}
+		queueCommand(os.Args[2], os.Args[3], os.Args[4:])
+
+	case "drafts":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(stdout, "Usage: comments drafts <list|publish|discard> <file> [flags]")
+			os.Exit(1)
+		}
+		draftsCommand(os.Args[2], os.Args[3], os.Args[4:])
+
+	case "blame":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments blame <file> --line <n> | --lines <start-end>")
+			os.Exit(1)
+		}
+		blameCommand(os.Args[2], os.Args[3:])
+
+	case "history":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments history <file>")
+			os.Exit(1)
+		}
+		revisionsCommand(os.Args[2], os.Args[3:])
+
+	case "revert":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(stdout, "Usage: comments revert <file> --to <revision>")
+			os.Exit(1)
+		}
+		revertCommand(os.Args[2], os.Args[3:])
+
+	case "backups":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(stdout, "Usage: comments backups <list|restore|prune> <file> [flags]")
+			os.Exit(1)
+		}
+		backupsCommand(os.Args[2], os.Args[3], os.Args[4:])
+
+	case "review":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(stdout, "Usage: comments review <start|submit|list> <file> [flags]")
+			os.Exit(1)
+		}
+		reviewCommand(os.Args[2], os.Args[3], os.Args[4:])
+
+	case "demo":
+		demoCommand(os.Args[2:])
+
 	case "help", "-h", "--help":
 		printUsage()
 
 	default:
-		fmt.Printf("Unknown command: %s\n\n", command)
+		fmt.Fprintf(stdout, "Unknown command: %s\n\n", command)
 		printUsage()
 		os.Exit(1)
 	}
 }
 
-func viewCommand(filename string) {
+func viewCommand(filename string, args []string) {
+	// Parse flags
+	fs := flag.NewFlagSet("view", flag.ExitOnError)
+	contextSize := fs.Int("context", tui.DefaultContextSize, "Lines of document context shown around comments in modals and thread view")
+	readOnly := fs.Bool("read-only", false, "Browse comments without allowing any changes")
+	noSession := fs.Bool("no-session", false, "Don't resume or save the last file, scroll position, and filters")
+	noRecovery := fs.Bool("no-recovery", false, "Don't autosave unsent comment text or offer to restore it")
+	layout := fs.String("layout", tui.LayoutAuto, "Pane layout: auto, split, stacked, or single (auto splits when the terminal is at least 100 columns wide)")
+	split := fs.Float64("split", tui.DefaultSplitRatio, "Document pane's share of the width (or height when stacked), 0.2 to 0.8")
+	timeFormat := fs.String("time-format", comment.TimeLocal, "How to show timestamps: local, relative (e.g. 2h ago), iso")
+	themeName := fs.String("theme", tui.ThemeDefault, "Color theme: default, high-contrast, or mono (no color, ASCII symbols); NO_COLOR turns color off")
+
+	fs.Parse(args)
+
+	if *contextSize < 0 {
+		fmt.Fprintln(stdout, "Error: --context must be zero or greater")
+		os.Exit(1)
+	}
+	if err := tui.ValidateLayout(*layout, *split); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := tui.ValidateTheme(*themeName); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := comment.ValidateTimeFormat(*timeFormat); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	var model tui.Model
 
 	if filename == "" {
 		// No filename provided - start with file picker
 		model = tui.NewModel()
+	} else if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		// Directory provided - open a multi-file review workspace
+		files, err := comment.WalkMarkdownFiles(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error scanning %s: %v\n", filename, err)
+			os.Exit(1)
+		}
+
+		model, err = tui.NewWorkspaceModel(filename, files)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error opening workspace: %v\n", err)
+			os.Exit(1)
+		}
 	} else {
 		// Filename provided - load it directly
 		doc, err := comment.LoadFromSidecar(filename)
 		if err != nil {
-			fmt.Printf("Error loading document: %v\n", err)
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Create model with pre-loaded file
 		model = tui.NewModelWithFile(doc, filename)
 	}
+	model.SetContextSize(*contextSize)
+	model.SetReadOnly(*readOnly)
+	model.SetTheme(*themeName)
+	model.SetTimeFormat(*timeFormat)
+
+	// Resume the previous session; a broken session file shouldn't block viewing
+	var sessionPath string
+	if !*noSession {
+		path, err := tui.DefaultSessionPath()
+		if err == nil {
+			var session *tui.Session
+			session, err = tui.LoadSession(path)
+			if err == nil {
+				sessionPath = path
+				model.SetSession(session)
+				// The ratio last set with < and > applies unless --split is given
+				splitSet := false
+				fs.Visit(func(f *flag.Flag) {
+					if f.Name == "split" {
+						splitSet = true
+					}
+				})
+				if !splitSet && tui.ValidateLayout(*layout, session.SplitRatio) == nil {
+					*split = session.SplitRatio
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: session not restored: %v\n", err)
+		}
+	}
+
+	model.SetLayout(*layout, *split)
+
+	// Autosave unsent modal text so a crash doesn't lose it
+	if !*noRecovery && !*readOnly {
+		if dir, err := tui.DefaultRecoveryDir(); err == nil {
+			model.SetRecoveryDir(dir)
+		} else {
+			fmt.Fprintf(stderr, "Warning: autosave disabled: %v\n", err)
+		}
+	}
 
 	// Run TUI
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running TUI: %v\n", err)
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(stdout, "Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
+
+	if err := final.(tui.Model).FlushRecovery(); err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+
+	if sessionPath != "" {
+		if err := final.(tui.Model).Session().Save(sessionPath); err != nil {
+			fmt.Fprintf(stderr, "Warning: session not saved: %v\n", err)
+		}
+	}
 }
 
 func listCommand(filename string, args []string) {
@@ -176,125 +514,213 @@ func listCommand(filename string, args []string) {
 	sectionFilter := fs.String("section", "", "Filter by section path (includes nested sections)")
 	statusFilter := fs.String("status", "", "Filter by status: active, orphaned, resolved, completed")
 	priorityFilter := fs.String("priority", "", "Filter by priority: low, medium, high")
-	sortBy := fs.String("sort", "line", "Sort by: line, timestamp, author")
-	format := fs.String("format", "text", "Output format: text, json, table")
+	sortBy := fs.String("sort", "line", "Sort by: line, timestamp, author, activity (latest reply, most recent first), score (most urgent first)")
+	format := fs.String("format", "text", "Output format: text, json, table, markdown")
+	columnList := fs.String("columns", defaultTableColumns, "Table columns: id, line, author, type, priority, status, replies, section, date, preview")
+	wide := fs.Bool("wide", false, "Don't truncate table cells to the terminal width")
+	templateText := fs.String("template", "", "Go template run for each thread over the JSON fields, e.g. '{{.ID}}\\t{{.Line}}\\t{{.Text}}' (overrides --format)")
 	withContext := fs.Bool("with-context", false, "Include document context for each comment")
+	contextSize := fs.Int("context", defaultContextLines, "Lines of context before/after each comment (with --with-context)")
+	timeFormat := fs.String("time-format", comment.TimeLocal, "How to show timestamps: local, relative (e.g. 2h ago), iso")
+	includeArchived := fs.Bool("include-archived", false, "Include threads archived by cleanup (flagged as archived)")
+	includeReplies := fs.Bool("include-replies", false, "Embed each thread's nested replies in JSON and --template output")
+	suggestionsOnly := fs.Bool("suggestions", false, "List only suggestions")
+	changedOnly := fs.Bool("changed-only", false, "List threads whose text was edited since they were made (⚠ CHANGED)")
+	unanswered := fs.Bool("unanswered", false, "List questions ([Q] threads) the document owners haven't answered yet")
+	pendingOnly := fs.Bool("pending", false, "List pending suggestions (implies --suggestions)")
+	acceptedOnly := fs.Bool("accepted", false, "List accepted suggestions (implies --suggestions and --resolved)")
+	rejectedOnly := fs.Bool("rejected", false, "List rejected suggestions (implies --suggestions and --resolved)")
+	unread := fs.Bool("unread", false, "List threads with activity from others since you last viewed them (--author names you and matches threads you wrote in)")
+	markRead := fs.Bool("mark-read", false, "Mark the listed threads as viewed by you (--author, $COMMENTS_AUTHOR, or $USER)")
 
 	fs.Parse(args)
 
-	// Load document
+	if *contextSize < 0 {
+		fmt.Fprintln(stdout, "Error: --context must be zero or greater")
+		os.Exit(1)
+	}
+	if err := comment.ValidateTimeFormat(*timeFormat); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	columns, err := parseTableColumns(*columnList)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var tmpl *template.Template
+	if *templateText != "" {
+		if tmpl, err = parseOutputTemplate(*templateText); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load document, with the private notes of whoever is viewing it
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
+	mergePrivateNotes(filename, doc, "")
 
 	// Compute section metadata for all comments if not already present
 	comment.ComputeSectionsForComments(doc)
 
+	// Decided suggestions are usually resolved, so asking for them shows resolved threads
+	if *acceptedOnly || *rejectedOnly {
+		*showResolved = true
+	}
+
 	// Filter by resolved status (only show root comments based on resolved flag)
 	filteredComments := comment.GetVisibleComments(doc.Threads, *showResolved)
 
-	// Filter comments by type if specified
-	if *typeFilter != "" {
-		filteredComments = filterCommentsByType(filteredComments, *typeFilter)
+	// Merge archived threads; they are always shown regardless of resolved state
+	archivedIDs := make(map[string]bool)
+	if *includeArchived {
+		archived, err := comment.LoadArchivedThreads(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading archives: %v\n", err)
+			os.Exit(1)
+		}
+		for _, thread := range archived {
+			archivedIDs[thread.ID] = true
+		}
+		filteredComments = append(append([]*comment.Comment{}, filteredComments...), archived...)
 	}
 
-	// Apply author filter
-	if *authorFilter != "" {
-		filteredComments = filterByAuthor(filteredComments, *authorFilter)
+	// Combine the requested filters; unset flags match everything
+	filters := []comment.Filter{}
+	if *typeFilter != "" {
+		filters = append(filters, comment.ByType(*typeFilter))
+	}
+	// Unread state is personal: --author names the reader, and matches the
+	// threads they took part in rather than only those they started
+	reader := currentActor(*authorFilter)
+	var seen *comment.SeenState
+	if *unread || *markRead {
+		if seen, err = comment.LoadSeen(filename); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *unread {
+		filters = append(filters, comment.ByUnread(seen, reader))
+	}
+	if *authorFilter != "" && *unread {
+		filters = append(filters, comment.ByParticipant(*authorFilter))
+	} else if *authorFilter != "" {
+		filters = append(filters, comment.ByAuthor(*authorFilter))
 	}
-
-	// Apply text search filter
 	if *searchText != "" {
-		filteredComments = filterBySearch(filteredComments, *searchText)
+		filters = append(filters, comment.BySearch(*searchText))
 	}
-
-	// Apply line range filter
 	if *lineRange != "" {
-		filtered, err := filterByLineRange(filteredComments, *lineRange)
+		start, end, err := comment.ParseLineRange(*lineRange)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fmt.Fprintf(stdout, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		filteredComments = filtered
+		filters = append(filters, comment.ByLineRange(start, end))
 	}
-
-	// Apply section filter
 	if *sectionFilter != "" {
-		// Validate section exists
-		if err := comment.ValidateSectionPath(doc.Content, *sectionFilter); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		inSection, err := comment.InSection(doc, *sectionFilter)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
 			os.Exit(1)
 		}
-
-		// Get all comments in this section (including nested sections)
-		sectionComments := comment.GetCommentsInSection(doc, *sectionFilter)
-
-		// Intersect with filtered comments (preserve other filters)
-		commentSet := make(map[string]bool)
-		for _, c := range sectionComments {
-			commentSet[c.ID] = true
+		// Archived threads are not part of the live document; match them by stored path
+		archived := comment.Filter(func(c *comment.Comment) bool { return archivedIDs[c.ID] })
+		filters = append(filters, comment.Or(inSection, comment.And(archived, comment.BySectionPath(*sectionFilter))))
+	}
+	if *statusFilter != "" {
+		filters = append(filters, comment.ByStatus(*statusFilter))
+	}
+	if *priorityFilter != "" {
+		filters = append(filters, comment.ByPriority(*priorityFilter))
+	}
+	if *pendingOnly || *acceptedOnly || *rejectedOnly {
+		states := []comment.Filter{}
+		if *pendingOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionPending))
 		}
-
-		filtered := []*comment.Comment{}
-		for _, c := range filteredComments {
-			if commentSet[c.ID] {
-				filtered = append(filtered, c)
-			}
+		if *acceptedOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionAccepted))
 		}
-		filteredComments = filtered
+		if *rejectedOnly {
+			states = append(states, comment.BySuggestionState(comment.SuggestionRejected))
+		}
+		filters = append(filters, comment.Or(states...))
+	} else if *suggestionsOnly {
+		filters = append(filters, comment.BySuggestionState(""))
+	}
+	if *changedOnly {
+		filters = append(filters, comment.ByTargetChanged(doc.Content))
 	}
+	// Owners from the project config answer questions; without any, anyone
+	// but the asker does
+	answerer := loadPolicy(filename).Answerer()
+	if *unanswered {
+		filters = append(filters, comment.ByQuestionState(comment.QuestionOpen, answerer))
+	}
+	filteredComments = comment.And(filters...).Apply(filteredComments)
 
-	// Apply status filter
-	if *statusFilter != "" {
-		filtered := []*comment.Comment{}
-		for _, c := range filteredComments {
-			if c.GetStatus() == *statusFilter {
-				filtered = append(filtered, c)
-			}
+	// Sort comments
+	sortComments(filteredComments, *sortBy)
+
+	if *markRead {
+		seen.MarkSeen(reader, filteredComments...)
+		if err := comment.SaveSeen(filename, seen); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		filteredComments = filtered
 	}
 
-	// Apply priority filter
-	if *priorityFilter != "" {
-		filtered := []*comment.Comment{}
-		for _, c := range filteredComments {
-			if c.GetPriority() == *priorityFilter {
-				filtered = append(filtered, c)
+	if tmpl != nil {
+		lines := strings.Split(doc.Content, "\n")
+		items := make([]commentOutput, 0, len(filteredComments))
+		for _, thread := range filteredComments {
+			item := newCommentOutput(thread, lines, *withContext, *contextSize, archivedIDs[thread.ID])
+			if *includeReplies {
+				item.Replies = replyOutputs(thread.Replies, archivedIDs[thread.ID])
 			}
+			items = append(items, item)
+		}
+		if err := outputTemplate(tmpl, items); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		filteredComments = filtered
+		return
 	}
 
-	// Sort comments
-	sortComments(filteredComments, *sortBy)
-
 	// Output based on format
 	switch *format {
 	case "json":
-		if err := outputJSON(filteredComments, doc.Threads, doc.Content, *withContext); err != nil {
-			fmt.Printf("Error outputting JSON: %v\n", err)
+		if err := outputJSON(filteredComments, doc.Threads, doc.Content, *withContext, *contextSize, archivedIDs, *includeReplies); err != nil {
+			fmt.Fprintf(stdout, "Error outputting JSON: %v\n", err)
 			os.Exit(1)
 		}
 		return
 
 	case "table":
-		outputTable(filteredComments, doc.Threads)
+		outputTable(filteredComments, archivedIDs, columns, *wide, *timeFormat)
+		return
+
+	case "markdown":
+		outputMarkdown(filteredComments, filename, archivedIDs)
 		return
 
 	case "text":
 		// If --with-context is specified with text format, use context format
 		if *withContext {
-			output := formatListWithContext(filteredComments, doc.Content)
-			fmt.Print(output)
+			output := formatListWithContext(filteredComments, doc, *contextSize, *timeFormat)
+			fmt.Fprint(stdout, output)
 			return
 		}
 		// Original text format (below)
 
 	default:
-		fmt.Printf("Error: Unknown format '%s'. Valid formats: text, json, table\n", *format)
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json, table, markdown\n", *format)
 		os.Exit(1)
 	}
 
@@ -309,7 +735,12 @@ func listCommand(filename string, args []string) {
 	if *typeFilter != "" {
 		filterDesc += fmt.Sprintf(" with type [%s]", *typeFilter)
 	}
-	if *authorFilter != "" {
+	if *unread {
+		filterDesc += fmt.Sprintf(" with activity @%s hasn't seen", reader)
+	}
+	if *authorFilter != "" && *unread {
+		filterDesc += fmt.Sprintf(" involving @%s", *authorFilter)
+	} else if *authorFilter != "" {
 		filterDesc += fmt.Sprintf(" by @%s", *authorFilter)
 	}
 	if *searchText != "" {
@@ -327,13 +758,45 @@ func listCommand(filename string, args []string) {
 	if *priorityFilter != "" {
 		filterDesc += fmt.Sprintf(" with priority [%s]", *priorityFilter)
 	}
+	switch {
+	case *pendingOnly || *acceptedOnly || *rejectedOnly:
+		states := []string{}
+		for _, state := range []struct {
+			set  bool
+			name string
+		}{{*pendingOnly, "pending"}, {*acceptedOnly, "accepted"}, {*rejectedOnly, "rejected"}} {
+			if state.set {
+				states = append(states, state.name)
+			}
+		}
+		filterDesc += fmt.Sprintf(" that are %s suggestions", strings.Join(states, " or "))
+	case *suggestionsOnly:
+		filterDesc += " that are suggestions"
+	}
+	if *changedOnly {
+		filterDesc += " whose text changed"
+	}
+	if *unanswered {
+		filterDesc += " that are unanswered questions"
+	}
+	if len(archivedIDs) > 0 {
+		filterDesc += " (including archived)"
+	}
 
-	fmt.Printf("Found %d %s thread(s)%s in %s\n\n", len(filteredComments), statusText, filterDesc, filename)
+	fmt.Fprintf(stdout, "Found %d %s thread(s)%s in %s\n\n", len(filteredComments), statusText, filterDesc, filename)
 
+	lines := strings.Split(doc.Content, "\n")
 	for i, thread := range filteredComments {
 		// Build location string (show section path if available, otherwise just line)
 		locationStr := fmt.Sprintf("Line %d", thread.Line)
-		if thread.SectionPath != "" {
+		if thread.IsDocumentLevel() {
+			locationStr = "Document"
+		} else if thread.IsRange() {
+			locationStr = fmt.Sprintf("Lines %d-%d", thread.Line, thread.EndLine)
+			if thread.SectionPath != "" {
+				locationStr = fmt.Sprintf("%s (%s)", thread.SectionPath, locationStr)
+			}
+		} else if thread.SectionPath != "" {
 			locationStr = fmt.Sprintf("%s (Line %d)", thread.SectionPath, thread.Line)
 		}
 
@@ -346,22 +809,42 @@ func listCommand(filename string, args []string) {
 			priorityIndicator = " [LOW]"
 		// medium is default, no indicator needed
 		}
+		if thread.Pinned {
+			priorityIndicator = " [PINNED]" + priorityIndicator
+		}
+		if thread.IsPrivate() {
+			priorityIndicator += " [PRIVATE]"
+		}
 
 		// Status indicator
 		statusIndicator := ""
 		status := thread.GetStatus()
 		if status == "orphaned" {
-			statusIndicator = " ⚠️  ORPHANED"
+			statusIndicator = forStdout(" ⚠️  ORPHANED")
 			if thread.OrphanedReason != "" {
 				statusIndicator += fmt.Sprintf(" (%s)", thread.OrphanedReason)
 			}
 		} else if status == "completed" {
-			statusIndicator = " ✓ COMPLETED"
+			statusIndicator = forStdout(" ✓ COMPLETED")
+		} else if thread.IsDrifted() {
+			statusIndicator = fmt.Sprintf(" ~ DRIFTED (from line %d)", thread.DriftedFrom)
+		}
+		if status != "orphaned" && thread.TargetChanged(lines) {
+			statusIndicator += forStdout(" ⚠ CHANGED")
+		}
+		switch thread.QuestionState(answerer) {
+		case comment.QuestionOpen:
+			statusIndicator += " ? UNANSWERED"
+		case comment.QuestionAnswered:
+			statusIndicator += forStdout(" ✓ ANSWERED")
+		}
+		if archivedIDs[thread.ID] {
+			statusIndicator += forStdout(" 📦 ARCHIVED")
 		}
 
 		// Show thread info with priority and status
-		fmt.Printf("[%d] %s • @%s • %s%s%s\n", i+1, locationStr, thread.Author, thread.Timestamp.Format("2006-01-02 15:04"), priorityIndicator, statusIndicator)
-		fmt.Printf("    Type: Root | Thread ID: %s | Status: %s\n", thread.ID, thread.GetStatus())
+		fmt.Fprintf(stdout, forStdout("[%d] %s • @%s • %s%s%s\n"), i+1, locationStr, thread.Author, comment.FormatTimestamp(thread.Timestamp, *timeFormat), priorityIndicator, statusIndicator)
+		fmt.Fprintf(stdout, "    Type: Root | Thread ID: %s | Status: %s\n", thread.ID, thread.GetStatus())
 
 		// Show reply count and resolved status
 		replyCount := thread.CountReplies()
@@ -369,131 +852,267 @@ func listCommand(filename string, args []string) {
 		if thread.Resolved {
 			resolvedStatus = " [RESOLVED]"
 		}
-		fmt.Printf("    Replies: %d%s\n", replyCount, resolvedStatus)
+		fmt.Fprintf(stdout, "    Replies: %d%s\n", replyCount, resolvedStatus)
 
-		fmt.Printf("    %s\n\n", thread.Text)
+		fmt.Fprintf(stdout, "    %s\n\n", thread.Text)
 	}
 }
 
 func getCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("get", flag.ExitOnError)
-	threadID := fs.String("thread", "", "Thread ID to get (required)")
+	threadID := fs.String("thread", "", "Thread ID to get, or several separated by commas")
+	line := fs.Int("line", 0, "Get the threads on a line (0 for document-level threads)")
+	section := fs.String("section", "", "Get the threads in a section (includes nested sections)")
+	showResolved := fs.Bool("resolved", false, "Include resolved threads with --line/--section")
 	withReplies := fs.Bool("with-replies", true, "Include replies in output (default: true)")
+	contextSize := fs.Int("context", defaultContextLines, "Lines of context before/after the comment")
+	timeFormat := fs.String("time-format", comment.TimeLocal, "How to show timestamps: local, relative (e.g. 2h ago), iso")
+	copyOut := fs.Bool("copy", false, "Also copy the comment to the clipboard")
+	copyAs := fs.String("copy-as", "quote", "What --copy copies: text, id, quote (quoted text, comment, and file#L link)")
+	templateText := fs.String("template", "", "Go template over the JSON fields of list, with context, e.g. '{{.ID}}: {{.LineContent}}'")
 
 	fs.Parse(args)
 
-	if *threadID == "" {
-		fmt.Println("Error: --thread flag is required")
-		fmt.Println("Usage: comments get <file> --thread <thread-id>")
+	lineSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "line" {
+			lineSet = true
+		}
+	})
+
+	if *contextSize < 0 {
+		fmt.Fprintln(stdout, "Error: --context must be zero or greater")
+		os.Exit(1)
+	}
+	if err := comment.ValidateTimeFormat(*timeFormat); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Load document
+	if *copyAs != "text" && *copyAs != "id" && *copyAs != "quote" {
+		fmt.Fprintf(stdout, "Error: Unknown --copy-as '%s'. Valid values: text, id, quote\n", *copyAs)
+		os.Exit(1)
+	}
+
+	if *threadID == "" && !lineSet && *section == "" {
+		fmt.Fprintln(stdout, "Error: --thread, --line, or --section is required")
+		fmt.Fprintln(stdout, "Usage: comments get <file> --thread <thread-id>[,<thread-id>...]")
+		fmt.Fprintln(stdout, "   or: comments get <file> --line N")
+		fmt.Fprintln(stdout, "   or: comments get <file> --section \"Section Path\"")
+		os.Exit(1)
+	}
+	if *threadID != "" && (lineSet || *section != "") {
+		fmt.Fprintln(stdout, "Error: --thread can't be combined with --line or --section")
+		os.Exit(1)
+	}
+
+	var tmpl *template.Template
+	if *templateText != "" {
+		var err error
+		if tmpl, err = parseOutputTemplate(*templateText); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load document, with the private notes of whoever is viewing it
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
+	mergePrivateNotes(filename, doc, "")
 
 	// Compute section metadata for all comments if not already present
 	comment.ComputeSectionsForComments(doc)
 
-	// Find the thread
-	var foundComment *comment.Comment
-	for _, thread := range doc.Threads {
-		if thread.ID == *threadID {
-			foundComment = thread
-			break
+	// Find the comments: by ID (threads or replies), or the threads on a line
+	// or in a section
+	var found []*comment.Comment
+	if *threadID != "" {
+		for _, id := range strings.Split(*threadID, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			c := doc.FindCommentByID(id)
+			if c == nil {
+				fmt.Fprintf(stdout, "Error: Thread with ID '%s' not found\n", id)
+				fmt.Fprintln(stdout, "\nAvailable threads:")
+				for i, thread := range doc.Threads {
+					fmt.Fprintf(stdout, "  [%d] %s (Line %d) - @%s\n", i+1, thread.ID, thread.Line, thread.Author)
+				}
+				os.Exit(1)
+			}
+			found = append(found, c)
 		}
-		// Also search in replies
-		for _, reply := range thread.Replies {
-			if reply.ID == *threadID {
-				foundComment = reply
-				break
+	} else {
+		filters := []comment.Filter{}
+		where := ""
+		if lineSet {
+			filters = append(filters, comment.ByLineRange(*line, *line))
+			where = fmt.Sprintf(" on line %d", *line)
+		}
+		if *section != "" {
+			inSection, err := comment.InSection(doc, *section)
+			if err != nil {
+				fmt.Fprintf(stdout, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			filters = append(filters, inSection)
+			where += fmt.Sprintf(" in section '%s'", *section)
 		}
-		if foundComment != nil {
-			break
+		found = comment.And(filters...).Apply(comment.GetVisibleComments(doc.Threads, *showResolved))
+		sortComments(found, "line")
+		if len(found) == 0 {
+			statusText := "unresolved "
+			if *showResolved {
+				statusText = ""
+			}
+			fmt.Fprintf(stdout, "Error: No %sthreads%s\n", statusText, where)
+			os.Exit(1)
 		}
 	}
 
-	if foundComment == nil {
-		fmt.Printf("Error: Thread with ID '%s' not found\n", *threadID)
-		fmt.Println("\nAvailable threads:")
-		for i, thread := range doc.Threads {
-			fmt.Printf("  [%d] %s (Line %d) - @%s\n", i+1, thread.ID, thread.Line, thread.Author)
-		}
+	if *copyOut && len(found) > 1 {
+		fmt.Fprintf(stdout, "Error: --copy needs a single comment, but %d were found\n", len(found))
 		os.Exit(1)
 	}
 
 	// Get context and format output
-	ctx := getCommentContext(foundComment, doc.Content)
-	output := formatCommentWithContext(foundComment, ctx, *withReplies)
-
-	fmt.Print(output)
-}
-
-// filterCommentsByType filters comments by type prefix ([Q], [S], [B], [T], [E])
-func filterCommentsByType(comments []*comment.Comment, typePrefix string) []*comment.Comment {
-	filtered := make([]*comment.Comment, 0)
-	targetPrefix := "[" + typePrefix + "]"
-
-	for _, c := range comments {
-		if len(c.Text) >= len(targetPrefix) && c.Text[:len(targetPrefix)] == targetPrefix {
-			filtered = append(filtered, c)
+	if tmpl != nil {
+		lines := strings.Split(doc.Content, "\n")
+		items := make([]commentOutput, 0, len(found))
+		for _, c := range found {
+			items = append(items, newCommentOutput(c, lines, true, *contextSize, false))
+		}
+		if err := outputTemplate(tmpl, items); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for i, c := range found {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			ctx := getCommentContext(c, doc, *contextSize)
+			fmt.Fprint(stdout, formatCommentWithContext(c, ctx, *withReplies, *timeFormat))
 		}
 	}
 
-	return filtered
+	if *copyOut && len(found) > 0 {
+		copyComment(found[0], filename, doc.Content, *copyAs)
+	}
 }
 
 func addCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("add", flag.ExitOnError)
-	text := fs.String("text", "", "Comment text (required)")
+	text := fs.String("text", "", "Comment text (required unless --edit)")
+	edit := fs.Bool("edit", false, "Write the comment in $EDITOR (starting from --text, if given)")
 	line := fs.Int("line", 0, "Line number (use either --line or --section)")
 	section := fs.String("section", "", "Section path (use either --line or --section)")
+	document := fs.Bool("document", false, "Comment on the document as a whole (same as --line 0)")
+	startLine := fs.Int("start-line", 0, "First line of a multi-line range (use with --end-line)")
+	endLine := fs.Int("end-line", 0, "Last line of a multi-line range (use with --start-line)")
 	author := fs.String("author", "", "Author name (required)")
 	commentType := fs.String("type", "", "Comment type: Q, S, B, T, E (auto-prefixes text)")
 	priority := fs.String("priority", "medium", "Priority: low, medium, high (default: medium)")
+	sign := fs.Bool("sign", false, "Sign the new comment with the local signing key")
+	draft := fs.Bool("draft", false, "Save as a private draft until published with the drafts command")
+	queue := fs.Bool("queue", false, "Stage the comment until 'comments queue flush' instead of writing the sidecar")
+	anchor := fs.String("anchor", "", "Anchor to the line or to the section's heading: line, heading (default: the project config's, or line)")
+	visibility := fs.String("visibility", comment.VisibilityShared, "Who sees the comment: shared (the team) or private (a note only you see)")
 
 	fs.Parse(args)
 
-	if *text == "" {
-		fmt.Println("Error: --text flag is required")
-		fmt.Println("Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
-		fmt.Println("   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
+	if *draft && *queue {
+		fmt.Fprintln(stdout, "Error: --draft and --queue cannot be used together")
+		os.Exit(1)
+	}
+	if err := comment.ValidateVisibility(*visibility); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	private := *visibility == comment.VisibilityPrivate
+	if private && (*draft || *queue) {
+		fmt.Fprintln(stdout, "Error: --visibility private cannot be combined with --draft or --queue")
+		os.Exit(1)
+	}
+
+	// An explicit --line 0 is a document-level comment
+	lineSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "line" {
+			lineSet = true
+		}
+	})
+	if lineSet && *line == comment.DocumentLine {
+		*document = true
+	}
+
+	if *text == "" && !*edit {
+		fmt.Fprintln(stdout, "Error: --text flag is required (or use --edit to write it in $EDITOR)")
+		fmt.Fprintln(stdout, "Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
+		fmt.Fprintln(stdout, "   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
 		os.Exit(1)
 	}
 
 	if *author == "" {
-		fmt.Println("Error: --author flag is required")
-		fmt.Println("Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
-		fmt.Println("   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
+		fmt.Fprintln(stdout, "Error: --author flag is required")
+		fmt.Fprintln(stdout, "Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
+		fmt.Fprintln(stdout, "   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
+		os.Exit(1)
+	}
+
+	if *document && (*line != comment.DocumentLine || *section != "") {
+		fmt.Fprintln(stdout, "Error: --document cannot be combined with --line N or --section")
 		os.Exit(1)
 	}
 
+	// A range comment starts at --start-line and ends at --end-line
+	if *startLine != 0 || *endLine != 0 {
+		if *line != 0 || *section != "" || *document {
+			fmt.Fprintln(stdout, "Error: --start-line/--end-line cannot be combined with --line, --section, or --document")
+			os.Exit(1)
+		}
+		if *startLine < 1 || *endLine < *startLine {
+			fmt.Fprintf(stdout, "Error: invalid range %d-%d (--start-line and --end-line are both required, start <= end)\n", *startLine, *endLine)
+			os.Exit(1)
+		}
+		*line = *startLine
+	}
+
 	// Validate that either line or section is provided (but not both)
-	if *line == 0 && *section == "" {
-		fmt.Println("Error: either --line or --section flag is required")
-		fmt.Println("Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
-		fmt.Println("   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
+	if *line == 0 && *section == "" && !*document {
+		fmt.Fprintln(stdout, "Error: either --line, --section, or --document flag is required")
+		fmt.Fprintln(stdout, "Usage: comments add <file> --line N --author \"name\" --text \"your comment\"")
+		fmt.Fprintln(stdout, "   or: comments add <file> --section \"Section Path\" --author \"name\" --text \"your comment\"")
 		os.Exit(1)
 	}
 
 	if *line != 0 && *section != "" {
-		fmt.Println("Error: cannot specify both --line and --section")
-		fmt.Println("Use either --line N or --section \"Section Path\", not both")
+		fmt.Fprintln(stdout, "Error: cannot specify both --line and --section")
+		fmt.Fprintln(stdout, "Use either --line N or --section \"Section Path\", not both")
 		os.Exit(1)
 	}
 
 	// Resolve text input (supports @filename)
 	resolvedText, err := resolveTextInput(*text)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if *edit {
+		if resolvedText, err = composeText(resolvedText); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionAdd, *author)
 
 	// Auto-prefix text with type if specified
 	commentText := resolvedText
@@ -504,7 +1123,7 @@ func addCommand(filename string, args []string) {
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -512,15 +1131,15 @@ func addCommand(filename string, args []string) {
 	targetLine := *line
 	if *section != "" {
 		// Validate section exists
-		if err := comment.ValidateSectionPath(doc.Content, *section); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		if err := comment.ValidateSectionPath(doc, *section); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Resolve section to line number (use section start line)
-		startLine, _, err := comment.ResolveSectionToLines(doc.Content, *section, false)
+		startLine, _, err := comment.ResolveSectionToLines(doc, *section, false)
 		if err != nil {
-			fmt.Printf("Error resolving section: %v\n", err)
+			fmt.Fprintf(stdout, "Error resolving section: %v\n", err)
 			os.Exit(1)
 		}
 		targetLine = startLine
@@ -537,124 +1156,229 @@ func addCommand(filename string, args []string) {
 	// Set priority
 	newComment.Priority = *priority
 	newComment.Status = "active"
+	screenComment(filename, newComment, true)
+
+	if *endLine > targetLine {
+		if lineCount := len(strings.Split(doc.Content, "\n")); *endLine > lineCount {
+			fmt.Fprintf(stdout, "Error: end line %d out of range (document has %d lines)\n", *endLine, lineCount)
+			os.Exit(1)
+		}
+		newComment.EndLine = *endLine
+	}
 
 	// Compute section metadata for the new comment
-	comment.UpdateCommentSection(newComment, doc.Content)
+	comment.UpdateCommentSection(newComment, doc)
+	comment.CaptureQuote(newComment, doc.Content)
+	anchorComment(filename, doc, newComment, *anchor)
+	if !private {
+		doc.AttachToOpenReview(newComment)
+	}
+
+	signComments(filename, *sign, newComment)
+
+	// Private notes never reach the shared sidecar
+	if private {
+		if err := comment.AddPrivateNote(filename, newComment, doc.Content); err != nil {
+			fmt.Fprintf(stdout, "Error saving private note: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Private note added to %s by @%s (only you can see it)\n"), draftLocation(newComment), *author)
+		fmt.Fprintf(stdout, "  Comment ID: %s\n", newComment.ID)
+		return
+	}
+
+	// Drafts stay out of the shared sidecar until published
+	if *draft {
+		if err := comment.AddDraft(filename, newComment, doc.Content); err != nil {
+			fmt.Fprintf(stdout, "Error saving draft: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Draft saved for %s by @%s (only you can see it)\n"), draftLocation(newComment), *author)
+		fmt.Fprintf(stdout, "  Comment ID: %s\n", newComment.ID)
+		fmt.Fprintf(stdout, "  Publish with: comments drafts publish %s\n", filename)
+		return
+	}
+	if *queue {
+		enqueue(filename, comment.NewQueuedThread(newComment), doc.Content)
+		return
+	}
 
 	doc.Threads = append(doc.Threads, newComment)
 
 	// Save to sidecar
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Display success message
-	if newComment.SectionPath != "" {
-		fmt.Printf("✓ Comment added to %s (Line %d) by @%s\n", newComment.SectionPath, targetLine, *author)
+	if newComment.IsDocumentLevel() {
+		fmt.Fprintf(stdout, forStdout("✓ Document-level comment added by @%s\n"), *author)
+	} else if newComment.IsRange() && newComment.SectionPath != "" {
+		fmt.Fprintf(stdout, forStdout("✓ Comment added to %s (Lines %d-%d) by @%s\n"), newComment.SectionPath, newComment.Line, newComment.EndLine, *author)
+	} else if newComment.IsRange() {
+		fmt.Fprintf(stdout, forStdout("✓ Comment added to lines %d-%d by @%s\n"), newComment.Line, newComment.EndLine, *author)
+	} else if newComment.SectionPath != "" {
+		fmt.Fprintf(stdout, forStdout("✓ Comment added to %s (Line %d) by @%s\n"), newComment.SectionPath, targetLine, *author)
 	} else {
-		fmt.Printf("✓ Comment added to line %d by @%s\n", targetLine, *author)
+		fmt.Fprintf(stdout, forStdout("✓ Comment added to line %d by @%s\n"), targetLine, *author)
+	}
+	fmt.Fprintf(stdout, "  Comment ID: %s\n", newComment.ID)
+	if newComment.Anchor != "" {
+		fmt.Fprintf(stdout, "  Anchored to heading #%s\n", newComment.Anchor)
 	}
-	fmt.Printf("  Comment ID: %s\n", newComment.ID)
 }
 
 func replyCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("reply", flag.ExitOnError)
-	text := fs.String("text", "", "Reply text (required)")
+	text := fs.String("text", "", "Reply text (required unless --edit)")
+	edit := fs.Bool("edit", false, "Write the reply in $EDITOR (starting from --text, if given)")
 	thread := fs.String("thread", "", "Thread ID (required)")
 	author := fs.String("author", "", "Author name (required)")
+	sign := fs.Bool("sign", false, "Sign the new reply with the local signing key")
+	queue := fs.Bool("queue", false, "Stage the reply until 'comments queue flush' instead of writing the sidecar")
 
 	fs.Parse(args)
 
-	if *text == "" {
-		fmt.Println("Error: --text flag is required")
-		fmt.Println("Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
+	if *text == "" && !*edit {
+		fmt.Fprintln(stdout, "Error: --text flag is required (or use --edit to write it in $EDITOR)")
+		fmt.Fprintln(stdout, "Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
 		os.Exit(1)
 	}
 
 	if *thread == "" {
-		fmt.Println("Error: --thread flag is required")
-		fmt.Println("Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
+		fmt.Fprintln(stdout, "Error: --thread flag is required")
+		fmt.Fprintln(stdout, "Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
 		os.Exit(1)
 	}
 
 	if *author == "" {
-		fmt.Println("Error: --author flag is required")
-		fmt.Println("Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
+		fmt.Fprintln(stdout, "Error: --author flag is required")
+		fmt.Fprintln(stdout, "Usage: comments reply <file> --thread ID --author \"name\" --text \"your reply\"")
 		os.Exit(1)
 	}
 
 	// Resolve text input (supports @filename)
 	resolvedText, err := resolveTextInput(*text)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionReply, *author)
+
+	// Compose after the policy check so a denied reply isn't written in vain
+	if *edit {
+		if resolvedText, err = composeText(resolvedText); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
+	if !*queue {
+		mergePrivateNotes(filename, doc, *author)
+	}
+
+	// Queued replies may answer threads that are still queued themselves
+	if *queue {
+		parent := doc.FindThreadByID(*thread)
+		if parent == nil {
+			staged, err := comment.LoadQueue(filename)
+			if err != nil {
+				fmt.Fprintf(stdout, "Error loading queue: %v\n", err)
+				os.Exit(1)
+			}
+			parent = staged.Thread(*thread)
+		}
+		if parent == nil {
+			fmt.Fprintf(stdout, "Error: thread not found: %s\n", *thread)
+			os.Exit(1)
+		}
+		reply := comment.NewReply(*author, resolvedText, parent)
+		screenComment(filename, reply, false)
+		doc.AttachToOpenReview(reply)
+		signComments(filename, *sign, reply)
+		enqueue(filename, comment.NewQueuedReply(*thread, reply), doc.Content)
+		return
+	}
 
 	// Add reply to thread using helper
 	if err := comment.AddReplyToThread(doc.Threads, *thread, *author, resolvedText); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		fmt.Println("\nAvailable threads:")
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		fmt.Fprintln(stdout, "\nAvailable threads:")
 		for _, t := range doc.Threads {
-			fmt.Printf("  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
+			fmt.Fprintf(stdout, "  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
 		}
 		os.Exit(1)
 	}
 
+	parent := doc.FindThreadByID(*thread)
+	replies := parent.Replies
+	screenComment(filename, replies[len(replies)-1], false)
+	if !parent.IsPrivate() {
+		doc.AttachToOpenReview(replies[len(replies)-1])
+	}
+	signComments(filename, *sign, replies[len(replies)-1])
+
 	// Save to sidecar
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Reply added to thread %s by @%s\n", *thread, *author)
+	fmt.Fprintf(stdout, forStdout("✓ Reply added to thread %s by @%s\n"), *thread, *author)
 }
 
 func resolveCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("resolve", flag.ExitOnError)
 	thread := fs.String("thread", "", "Thread ID (required)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
 
 	fs.Parse(args)
 
 	if *thread == "" {
-		fmt.Println("Error: --thread flag is required")
-		fmt.Println("Usage: comments resolve <file> --thread ID")
+		fmt.Fprintln(stdout, "Error: --thread flag is required")
+		fmt.Fprintln(stdout, "Usage: comments resolve <file> --thread ID")
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionResolve, currentActor(*actor))
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
+	mergePrivateNotes(filename, doc, *actor)
 
 	// Resolve the thread
 	if err := comment.ResolveThread(doc.Threads, *thread); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		fmt.Println("\nAvailable threads:")
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		fmt.Fprintln(stdout, "\nAvailable threads:")
 		for _, t := range doc.Threads {
-			fmt.Printf("  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
+			fmt.Fprintf(stdout, "  %s (Line %d, %d replies)\n", t.ID, t.Line, t.CountReplies())
 		}
 		os.Exit(1)
 	}
 
 	// Save to sidecar
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Thread %s marked as resolved\n", *thread)
+	fmt.Fprintf(stdout, forStdout("✓ Thread %s marked as resolved\n"), *thread)
 }
 
 func suggestCommand(filename string, args []string) {
@@ -667,66 +1391,90 @@ func suggestCommand(filename string, args []string) {
 	text := fs.String("text", "", "Suggestion description (required)")
 	original := fs.String("original", "", "Original text to replace")
 	proposed := fs.String("proposed", "", "Proposed replacement text (required)")
+	insertAfter := fs.Int("insert-after", -1, "Insert the proposed text after line N (0 for the top) instead of replacing lines")
+	moveAfter := fs.Int("move-after", -1, "Move the lines (or section) to after line N (0 for the top) instead of replacing them")
+	dependsOn := fs.String("depends-on", "", "Comma-separated suggestion IDs that must be applied before this one")
+	sign := fs.Bool("sign", false, "Sign the new suggestion with the local signing key")
+	queue := fs.Bool("queue", false, "Stage the suggestion until 'comments queue flush' instead of writing the sidecar")
+	anchor := fs.String("anchor", "", "Anchor to the lines or to the section's heading: line, heading (default: the project config's, or line)")
 
 	fs.Parse(args)
 
 	// Validate required flags
 	if *author == "" {
-		fmt.Println("Error: --author flag is required")
-		fmt.Println("Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
-		fmt.Println("   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "Error: --author flag is required")
+		fmt.Fprintln(stdout, "Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
 		os.Exit(1)
 	}
 	if *text == "" {
-		fmt.Println("Error: --text flag is required")
-		fmt.Println("Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
-		fmt.Println("   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "Error: --text flag is required")
+		fmt.Fprintln(stdout, "Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+		os.Exit(1)
+	}
+	// A move keeps the lines' text, so it proposes none
+	moving := *moveAfter >= 0
+	if moving && (*proposed != "" || *insertAfter >= 0) {
+		fmt.Fprintln(stdout, "Error: --move-after cannot be combined with --proposed or --insert-after")
 		os.Exit(1)
 	}
-	if *proposed == "" {
-		fmt.Println("Error: --proposed flag is required")
-		fmt.Println("Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
-		fmt.Println("   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+	if *proposed == "" && !moving {
+		fmt.Fprintln(stdout, "Error: --proposed flag is required")
+		fmt.Fprintln(stdout, "Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+		os.Exit(1)
+	}
+
+	// An insertion replaces nothing, so it takes no range or original text
+	inserting := *insertAfter >= 0
+	if inserting && (*startLine != 0 || *endLine != 0 || *section != "" || *original != "") {
+		fmt.Fprintln(stdout, "Error: --insert-after cannot be combined with --start-line, --end-line, --section, or --original")
 		os.Exit(1)
 	}
 
 	// Validate that either line range or section is provided (but not both)
-	if *startLine == 0 && *section == "" {
-		fmt.Println("Error: either --start-line/--end-line or --section flag is required")
-		fmt.Println("Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
-		fmt.Println("   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+	if *startLine == 0 && *section == "" && !inserting {
+		fmt.Fprintln(stdout, "Error: either --start-line/--end-line, --section, or --insert-after flag is required")
+		fmt.Fprintln(stdout, "Usage: comments suggest <file> --start-line N --end-line M --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --section \"Section Path\" --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --insert-after N --author \"name\" --text \"desc\" --proposed \"new text\"")
+		fmt.Fprintln(stdout, "   or: comments suggest <file> --section \"Section Path\" --move-after N --author \"name\" --text \"desc\"")
 		os.Exit(1)
 	}
 
 	if *startLine != 0 && *section != "" {
-		fmt.Println("Error: cannot specify both line range and section")
-		fmt.Println("Use either --start-line/--end-line or --section, not both")
+		fmt.Fprintln(stdout, "Error: cannot specify both line range and section")
+		fmt.Fprintln(stdout, "Use either --start-line/--end-line or --section, not both")
 		os.Exit(1)
 	}
 
 	// Resolve text inputs (supports @filename)
 	resolvedText, err := resolveTextInput(*text)
 	if err != nil {
-		fmt.Printf("Error resolving --text: %v\n", err)
+		fmt.Fprintf(stdout, "Error resolving --text: %v\n", err)
 		os.Exit(1)
 	}
 
 	resolvedOriginal, err := resolveTextInput(*original)
 	if err != nil {
-		fmt.Printf("Error resolving --original: %v\n", err)
+		fmt.Fprintf(stdout, "Error resolving --original: %v\n", err)
 		os.Exit(1)
 	}
 
 	resolvedProposed, err := resolveTextInput(*proposed)
 	if err != nil {
-		fmt.Printf("Error resolving --proposed: %v\n", err)
+		fmt.Fprintf(stdout, "Error resolving --proposed: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionSuggest, *author)
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -735,51 +1483,108 @@ func suggestCommand(filename string, args []string) {
 	targetEndLine := *endLine
 	if *section != "" {
 		// Validate section exists
-		if err := comment.ValidateSectionPath(doc.Content, *section); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		if err := comment.ValidateSectionPath(doc, *section); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Resolve section to line range
-		start, end, err := comment.ResolveSectionToLines(doc.Content, *section, false)
+		start, end, err := comment.ResolveSectionToLines(doc, *section, false)
 		if err != nil {
-			fmt.Printf("Error resolving section: %v\n", err)
+			fmt.Fprintf(stdout, "Error resolving section: %v\n", err)
 			os.Exit(1)
 		}
 		targetStartLine = start
 		targetEndLine = end
 	}
 
-	// Validate line range
-	if targetEndLine == 0 {
-		targetEndLine = targetStartLine
+	// Create suggestion using helper
+	var suggestion *comment.Comment
+	if moving {
+		if targetEndLine == 0 {
+			targetEndLine = targetStartLine
+		}
+		lines := strings.Split(doc.Content, "\n")
+		if err := comment.CheckMove(targetStartLine, targetEndLine, *moveAfter, len(lines)); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// The moved text, checked again when the move is applied
+		if resolvedOriginal == "" {
+			resolvedOriginal = strings.Join(lines[targetStartLine-1:targetEndLine], "\n")
+		}
+		suggestion = comment.NewMove(*author, targetStartLine, targetEndLine, *moveAfter, resolvedText, resolvedOriginal)
+	} else if inserting {
+		if lineCount := len(strings.Split(doc.Content, "\n")); *insertAfter > lineCount {
+			fmt.Fprintf(stdout, "Error: --insert-after %d is past the end of the document (%d lines)\n", *insertAfter, lineCount)
+			os.Exit(1)
+		}
+		suggestion = comment.NewInsertion(*author, *insertAfter, resolvedText, resolvedProposed)
+	} else {
+		// Validate line range
+		if targetEndLine == 0 {
+			targetEndLine = targetStartLine
+		}
+		if targetStartLine > targetEndLine {
+			fmt.Fprintf(stdout, "Error: start line (%d) must be <= end line (%d)\n", targetStartLine, targetEndLine)
+			os.Exit(1)
+		}
+		suggestion = comment.NewSuggestion(*author, targetStartLine, targetEndLine, resolvedText, resolvedOriginal, resolvedProposed)
 	}
-	if targetStartLine > targetEndLine {
-		fmt.Printf("Error: start line (%d) must be <= end line (%d)\n", targetStartLine, targetEndLine)
+
+	screenComment(filename, suggestion, true)
+
+	// Record dependencies on other suggestions
+	suggestion.DependsOn = parseIDList(*dependsOn)
+	if err := comment.ValidateDependencies(suggestion, doc.Threads); err != nil {
+		fmt.Fprintf(stdout, "Error: --depends-on: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Create suggestion using helper
-	suggestion := comment.NewSuggestion(*author, targetStartLine, targetEndLine, resolvedText, resolvedOriginal, resolvedProposed)
-
 	// Compute section metadata
-	comment.UpdateCommentSection(suggestion, doc.Content)
+	comment.UpdateCommentSection(suggestion, doc)
+	anchorComment(filename, doc, suggestion, *anchor)
+	doc.AttachToOpenReview(suggestion)
+
+	signComments(filename, *sign, suggestion)
+
+	if *queue {
+		enqueue(filename, comment.NewQueuedThread(suggestion), doc.Content)
+		return
+	}
 
 	// Add to document
 	doc.Threads = append(doc.Threads, suggestion)
 
 	// Save
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
 	if suggestion.SectionPath != "" {
-		fmt.Printf("✓ Suggestion added to %s (Lines %d-%d) by @%s\n", suggestion.SectionPath, targetStartLine, targetEndLine, *author)
+		fmt.Fprintf(stdout, forStdout("✓ Suggestion added to %s (%s) by @%s\n"), suggestion.SectionPath, comment.DescribeSuggestionLines(suggestion), *author)
 	} else {
-		fmt.Printf("✓ Suggestion added to lines %d-%d by @%s\n", targetStartLine, targetEndLine, *author)
+		fmt.Fprintf(stdout, forStdout("✓ Suggestion added (%s) by @%s\n"), comment.DescribeSuggestionLines(suggestion), *author)
 	}
-	fmt.Printf("  Suggestion ID: %s\n", suggestion.ID)
+	fmt.Fprintf(stdout, "  Suggestion ID: %s\n", suggestion.ID)
+	if suggestion.Anchor != "" {
+		fmt.Fprintf(stdout, "  Anchored to heading #%s\n", suggestion.Anchor)
+	}
+	if len(suggestion.DependsOn) > 0 {
+		fmt.Fprintf(stdout, "  Depends on: %s\n", strings.Join(suggestion.DependsOn, ", "))
+	}
+}
+
+// parseIDList splits a comma-separated list of IDs, dropping empty entries
+func parseIDList(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 func acceptCommand(filename string, args []string) {
@@ -787,18 +1592,33 @@ func acceptCommand(filename string, args []string) {
 	fs := flag.NewFlagSet("accept", flag.ExitOnError)
 	suggestionID := fs.String("suggestion", "", "Suggestion ID (required)")
 	preview := fs.Bool("preview", false, "Preview changes without applying")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+	reason := fs.String("reason", "", "Why the suggestion is accepted, recorded as a reply (supports @filename)")
+	allowSelfAccept := fs.Bool("allow-self-accept", false, "Accept your own suggestion even though self_accept forbids it (owners only)")
 
 	fs.Parse(args)
 
 	if *suggestionID == "" {
-		fmt.Println("Error: --suggestion flag is required")
+		fmt.Fprintln(stdout, "Error: --suggestion flag is required")
+		os.Exit(1)
+	}
+
+	resolvedReason, err := resolveTextInput(*reason)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes (previews are always allowed)
+	if !*preview {
+		enforcePolicy(filename, config.ActionAccept, currentActor(*actor))
+		requireReason(filename, resolvedReason, "accept")
+	}
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -813,12 +1633,23 @@ func acceptCommand(filename string, args []string) {
 	}
 
 	if suggestion == nil {
-		fmt.Printf("Error: Suggestion '%s' not found\n", *suggestionID)
+		fmt.Fprintf(stdout, "Error: Suggestion '%s' not found\n", *suggestionID)
 		os.Exit(1)
 	}
 
 	if !suggestion.IsSuggestion {
-		fmt.Printf("Error: Comment '%s' is not a suggestion\n", *suggestionID)
+		fmt.Fprintf(stdout, "Error: Comment '%s' is not a suggestion\n", *suggestionID)
+		os.Exit(1)
+	}
+
+	if !*preview {
+		enforceSelfAccept(filename, doc, suggestion, currentActor(*actor), *allowSelfAccept)
+	}
+
+	// Dependencies must be applied first
+	if unmet := comment.UnmetDependencies(suggestion, doc.Threads); len(unmet) > 0 {
+		fmt.Fprintf(stdout, "Error: Suggestion '%s' depends on %s, which has not been accepted\n", *suggestionID, strings.Join(unmet, ", "))
+		fmt.Fprintln(stdout, "Accept the dependencies first, or use batch-accept to apply them in order")
 		os.Exit(1)
 	}
 
@@ -826,19 +1657,19 @@ func acceptCommand(filename string, args []string) {
 	if *preview {
 		newContent, err := comment.ApplySuggestion(doc.Content, suggestion)
 		if err != nil {
-			fmt.Printf("Error applying suggestion: %v\n", err)
+			fmt.Fprintf(stdout, "Error applying suggestion: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Preview of changes:")
-		fmt.Println("==================")
-		fmt.Println(newContent)
+		fmt.Fprintln(stdout, "Preview of changes:")
+		fmt.Fprintln(stdout, "==================")
+		fmt.Fprintln(stdout, newContent)
 		return
 	}
 
 	// Apply suggestion
 	newContent, err := comment.ApplySuggestion(doc.Content, suggestion)
 	if err != nil {
-		fmt.Printf("Error applying suggestion: %v\n", err)
+		fmt.Fprintf(stdout, "Error applying suggestion: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -847,68 +1678,100 @@ func acceptCommand(filename string, args []string) {
 
 	// Mark suggestion as accepted using helper
 	if err := comment.AcceptSuggestion(doc.Threads, *suggestionID); err != nil {
-		fmt.Printf("Error marking suggestion as accepted: %v\n", err)
+		fmt.Fprintf(stdout, "Error marking suggestion as accepted: %v\n", err)
 		os.Exit(1)
 	}
+	recordReason(filename, doc, suggestion, currentActor(*actor), resolvedReason)
 
-	// Recalculate comment line numbers (line-only tracking)
-	linesAdded := len(strings.Split(suggestion.ProposedText, "\n"))
-	comment.RecalculateCommentLines(doc.Threads, suggestion.StartLine, suggestion.EndLine, linesAdded)
+	// Recalculate comment line numbers and sections (the edit may change headings)
+	doc.RecordApplied(suggestion)
 
 	// Save
+	backupSidecar(filename)
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Suggestion %s accepted and applied\n", *suggestionID)
+	fmt.Fprintf(stdout, forStdout("✓ Suggestion %s accepted and applied\n"), *suggestionID)
 }
 
 func rejectCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("reject", flag.ExitOnError)
 	suggestionID := fs.String("suggestion", "", "Suggestion ID (required)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+	reason := fs.String("reason", "", "Why the suggestion is rejected, recorded as a reply (supports @filename)")
 
 	fs.Parse(args)
 
 	if *suggestionID == "" {
-		fmt.Println("Error: --suggestion flag is required")
+		fmt.Fprintln(stdout, "Error: --suggestion flag is required")
+		os.Exit(1)
+	}
+
+	resolvedReason, err := resolveTextInput(*reason)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionReject, currentActor(*actor))
+	requireReason(filename, resolvedReason, "reject")
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Mark suggestion as rejected using helper
 	if err := comment.RejectSuggestion(doc.Threads, *suggestionID); err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	recordReason(filename, doc, doc.FindCommentByID(*suggestionID), currentActor(*actor), resolvedReason)
 
 	// Save
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Suggestion %s rejected\n", *suggestionID)
+	fmt.Fprintf(stdout, forStdout("✓ Suggestion %s rejected\n"), *suggestionID)
 }
 
 func batchAcceptCommand(filename string, args []string) {
 	// Parse flags
 	fs := flag.NewFlagSet("batch-accept", flag.ExitOnError)
 	filterAuthor := fs.String("author", "", "Accept all suggestions by author")
+	sectionPath := fs.String("section", "", "Accept pending suggestions entirely inside this section (includes nested sections)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+	reason := fs.String("reason", "", "Why the suggestions are accepted, recorded as a reply on each (supports @filename)")
+	allowSelfAccept := fs.Bool("allow-self-accept", false, "Accept your own suggestions even though self_accept forbids it (owners only)")
 
 	fs.Parse(args)
 
+	resolvedReason, err := resolveTextInput(*reason)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionAccept, currentActor(*actor))
+	requireReason(filename, resolvedReason, "accept")
+
+	// Ctrl+C stops before anything is saved
+	ctx, stop := interruptContext()
+	defer stop()
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -922,47 +1785,86 @@ func batchAcceptCommand(filename string, args []string) {
 		suggestionsToAccept = comment.GetPendingSuggestions(doc.Threads)
 	}
 
-	if len(suggestionsToAccept) == 0 {
-		fmt.Println("No pending suggestions found matching criteria")
-		os.Exit(0)
-	}
-
-	fmt.Printf("Found %d pending suggestion(s) to accept\n", len(suggestionsToAccept))
-
-	// Apply each suggestion sequentially
-	acceptedCount := 0
-	for _, suggestion := range suggestionsToAccept {
-		// Apply suggestion
-		newContent, err := comment.ApplySuggestion(doc.Content, suggestion)
+	// Narrow to suggestions inside the section, dropping conflicting ones
+	if *sectionPath != "" {
+		if err := comment.ValidateSectionPath(doc, *sectionPath); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		startLine, endLine, err := comment.ResolveSectionToLines(doc, *sectionPath, true)
 		if err != nil {
-			fmt.Printf("⚠ Warning: Failed to apply suggestion %s: %v\n", suggestion.ID, err)
-			continue
+			fmt.Fprintf(stdout, "Error resolving section: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Update document content
-		doc.Content = newContent
+		inSection := comment.FilterSuggestionsInRange(suggestionsToAccept, startLine, endLine)
+		suggestionsToAccept = comment.FilterNonConflicting(inSection)
+		fmt.Fprintf(stdout, "Section %s spans lines %d-%d\n", *sectionPath, startLine, endLine)
+
+		if excluded := len(inSection) - len(suggestionsToAccept); excluded > 0 {
+			kept := make(map[string]bool)
+			for _, s := range suggestionsToAccept {
+				kept[s.ID] = true
+			}
+			for _, s := range inSection {
+				if !kept[s.ID] {
+					fmt.Fprintf(stdout, forStdout("⚠ Skipping %s (lines %d-%d): conflicts with another suggestion in the section\n"), s.ID, s.StartLine, s.EndLine)
+				}
+			}
+		}
+	}
 
-		// Mark as accepted
-		if err := comment.AcceptSuggestion(doc.Threads, suggestion.ID); err != nil {
-			fmt.Printf("⚠ Warning: Failed to mark suggestion %s as accepted: %v\n", suggestion.ID, err)
+	// The self_accept rule may leave the actor's own suggestions to someone else
+	policy := loadPolicy(filename)
+	allowed := suggestionsToAccept[:0]
+	for _, s := range suggestionsToAccept {
+		if err := policy.CheckAccept(doc, s, currentActor(*actor), *allowSelfAccept); err != nil {
+			fmt.Fprintf(stdout, forStdout("⚠ Skipping %s: %v\n"), s.ID, err)
 			continue
 		}
+		allowed = append(allowed, s)
+	}
+	suggestionsToAccept = allowed
+
+	if len(suggestionsToAccept) == 0 {
+		fmt.Fprintln(stdout, "No pending suggestions found matching criteria")
+		os.Exit(0)
+	}
+
+	fmt.Fprintf(stdout, "Found %d pending suggestion(s) to accept\n", len(suggestionsToAccept))
 
-		// Recalculate comment lines after this edit
-		linesAdded := len(strings.Split(suggestion.ProposedText, "\n"))
-		comment.RecalculateCommentLines(doc.Threads, suggestion.StartLine, suggestion.EndLine, linesAdded)
+	// Apply in dependency order, recomputing line ranges after each edit
+	applied, skipped, err := comment.AcceptSuggestionsInOrder(doc, suggestionsToAccept)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-		acceptedCount++
-		fmt.Printf("  ✓ Accepted and applied %s\n", suggestion.ID)
+	for _, suggestion := range applied {
+		recordReason(filename, doc, suggestion, currentActor(*actor), resolvedReason)
+		fmt.Fprintf(stdout, forStdout("  ✓ Accepted and applied %s\n"), suggestion.ID)
+	}
+	for _, skip := range skipped {
+		fmt.Fprintf(stdout, forStdout("⚠ Warning: Failed to apply suggestion %s: %v\n"), skip.Suggestion.ID, skip.Reason)
 	}
 
 	// Save
+	exitIfInterrupted(ctx, "no suggestions were accepted")
+	backupSidecar(filename)
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving document: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("\n✓ Successfully accepted and applied %d of %d suggestions\n", acceptedCount, len(suggestionsToAccept))
+	fmt.Fprintf(stdout, forStdout("\n✓ Successfully accepted and applied %d of %d suggestions\n"), len(applied), len(suggestionsToAccept))
+}
+
+// validStatuses are the statuses status and bulk-status can set
+var validStatuses = map[string]bool{
+	"active":    true,
+	"orphaned":  true,
+	"resolved":  true,
+	"completed": true,
 }
 
 func statusCommand(filename string, args []string) {
@@ -970,45 +1872,43 @@ func statusCommand(filename string, args []string) {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
 	commentID := fs.String("comment", "", "Comment ID to update (required)")
 	newStatus := fs.String("status", "", "New status: active, orphaned, resolved, completed (required)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
 
 	fs.Parse(args)
 
 	if *commentID == "" {
-		fmt.Println("Error: --comment flag is required")
-		fmt.Println("Usage: comments status <file> --comment <id> --status <status>")
+		fmt.Fprintln(stdout, "Error: --comment flag is required")
+		fmt.Fprintln(stdout, "Usage: comments status <file> --comment <id> --status <status>")
 		os.Exit(1)
 	}
 
 	if *newStatus == "" {
-		fmt.Println("Error: --status flag is required")
-		fmt.Println("Valid statuses: active, orphaned, resolved, completed")
+		fmt.Fprintln(stdout, "Error: --status flag is required")
+		fmt.Fprintln(stdout, "Valid statuses: active, orphaned, resolved, completed")
 		os.Exit(1)
 	}
 
 	// Validate status value
-	validStatuses := map[string]bool{
-		"active":    true,
-		"orphaned":  true,
-		"resolved":  true,
-		"completed": true,
-	}
 	if !validStatuses[*newStatus] {
-		fmt.Printf("Error: Invalid status '%s'\n", *newStatus)
-		fmt.Println("Valid statuses: active, orphaned, resolved, completed")
+		fmt.Fprintf(stdout, "Error: Invalid status '%s'\n", *newStatus)
+		fmt.Fprintln(stdout, "Valid statuses: active, orphaned, resolved, completed")
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionStatus, currentActor(*actor))
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Find the comment
 	foundComment := doc.FindCommentByID(*commentID)
 	if foundComment == nil {
-		fmt.Printf("Error: Comment '%s' not found\n", *commentID)
+		fmt.Fprintf(stdout, "Error: Comment '%s' not found\n", *commentID)
 		os.Exit(1)
 	}
 
@@ -1024,11 +1924,55 @@ func statusCommand(filename string, args []string) {
 
 	// Save changes
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving changes: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("Updated comment %s status: %s → %s\n"), *commentID, oldStatus, *newStatus)
+}
+
+func pinCommand(filename string, args []string) {
+	// Parse flags
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	commentID := fs.String("comment", "", "Thread ID to pin (required)")
+	unpin := fs.Bool("unpin", false, "Unpin the thread instead")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if *commentID == "" {
+		fmt.Fprintln(stdout, "Error: --comment flag is required")
+		fmt.Fprintln(stdout, "Usage: comments pin <file> --comment <id> [--unpin]")
+		os.Exit(1)
+	}
+
+	// Pinning is triage, like setting a status
+	enforcePolicy(filename, config.ActionStatus, currentActor(*actor))
+
+	// Load document
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	mergePrivateNotes(filename, doc, *actor)
+
+	if err := doc.PinThread(*commentID, !*unpin); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Save changes
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Updated comment %s status: %s → %s\n", *commentID, oldStatus, *newStatus)
+	if *unpin {
+		fmt.Fprintf(stdout, forStdout("✓ Thread %s unpinned\n"), *commentID)
+	} else {
+		fmt.Fprintf(stdout, forStdout("✓ Thread %s pinned; it is listed first in list and the TUI\n"), *commentID)
+	}
 }
 
 func reattachCommand(filename string, args []string) {
@@ -1037,37 +1981,41 @@ func reattachCommand(filename string, args []string) {
 	commentID := fs.String("comment", "", "Comment ID to reattach (required)")
 	newLine := fs.Int("line", 0, "New line number to attach to (required)")
 	sectionPath := fs.String("section", "", "Section path to attach to (alternative to --line)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
 
 	fs.Parse(args)
 
 	if *commentID == "" {
-		fmt.Println("Error: --comment flag is required")
-		fmt.Println("Usage: comments reattach <file> --comment <id> --line <num>")
-		fmt.Println("   or: comments reattach <file> --comment <id> --section <path>")
+		fmt.Fprintln(stdout, "Error: --comment flag is required")
+		fmt.Fprintln(stdout, "Usage: comments reattach <file> --comment <id> --line <num>")
+		fmt.Fprintln(stdout, "   or: comments reattach <file> --comment <id> --section <path>")
 		os.Exit(1)
 	}
 
 	if *newLine == 0 && *sectionPath == "" {
-		fmt.Println("Error: either --line or --section flag is required")
+		fmt.Fprintln(stdout, "Error: either --line or --section flag is required")
 		os.Exit(1)
 	}
 
 	if *newLine != 0 && *sectionPath != "" {
-		fmt.Println("Error: cannot specify both --line and --section")
+		fmt.Fprintln(stdout, "Error: cannot specify both --line and --section")
 		os.Exit(1)
 	}
 
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionReattach, currentActor(*actor))
+
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Find the comment
 	foundComment := doc.FindCommentByID(*commentID)
 	if foundComment == nil {
-		fmt.Printf("Error: Comment '%s' not found\n", *commentID)
+		fmt.Fprintf(stdout, "Error: Comment '%s' not found\n", *commentID)
 		os.Exit(1)
 	}
 
@@ -1075,13 +2023,13 @@ func reattachCommand(filename string, args []string) {
 	targetLine := *newLine
 	if *sectionPath != "" {
 		// Find section
-		docStructure := markdown.ParseDocument(doc.Content)
+		docStructure := doc.Structure()
 		section := docStructure.FindSection(*sectionPath)
 		if section == nil {
-			fmt.Printf("Error: Section '%s' not found\n", *sectionPath)
-			fmt.Println("\nAvailable sections:")
+			fmt.Fprintf(stdout, "Error: Section '%s' not found\n", *sectionPath)
+			fmt.Fprintln(stdout, "\nAvailable sections:")
 			for _, sec := range docStructure.Sections {
-				fmt.Printf("  - %s\n", sec.GetFullPath(docStructure.SectionsByID))
+				fmt.Fprintf(stdout, "  - %s\n", sec.GetFullPath(docStructure.SectionsByID))
 			}
 			os.Exit(1)
 		}
@@ -1093,7 +2041,7 @@ func reattachCommand(filename string, args []string) {
 	// Validate line number
 	lines := strings.Split(doc.Content, "\n")
 	if targetLine < 1 || targetLine > len(lines) {
-		fmt.Printf("Error: Line %d out of bounds (document has %d lines)\n", targetLine, len(lines))
+		fmt.Fprintf(stdout, "Error: Line %d out of bounds (document has %d lines)\n", targetLine, len(lines))
 		os.Exit(1)
 	}
 
@@ -1103,10 +2051,12 @@ func reattachCommand(filename string, args []string) {
 	foundComment.Status = "active"
 	foundComment.OrphanedReason = ""
 	foundComment.OrphanedAt = nil
+	foundComment.ClearDrift()
+	comment.CaptureLineHash(foundComment, doc.Content)
 
 	// Save changes
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving changes: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -1114,7 +2064,61 @@ func reattachCommand(filename string, args []string) {
 	if *sectionPath != "" {
 		locationStr = fmt.Sprintf("section '%s' (line %d)", *sectionPath, targetLine)
 	}
-	fmt.Printf("Reattached comment %s: line %d → %s\n", *commentID, oldLine, locationStr)
+	fmt.Fprintf(stdout, forStdout("Reattached comment %s: line %d → %s\n"), *commentID, oldLine, locationStr)
+}
+
+func shiftCommand(filename string, args []string) {
+	// Parse flags
+	fs := flag.NewFlagSet("shift", flag.ExitOnError)
+	afterLine := fs.Int("after-line", 0, "Shift the comments below this line (0: every comment)")
+	by := fs.Int("by", 0, "Lines to shift by: positive if lines were added, negative if removed (required)")
+	dryRun := fs.Bool("dry-run", false, "Check the shift and report what would move without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if *by == 0 {
+		fmt.Fprintln(stdout, "Error: --by flag is required (and not 0)")
+		fmt.Fprintln(stdout, "Usage: comments shift <file> --after-line N --by M")
+		os.Exit(1)
+	}
+
+	// Check project policy before making changes
+	if !*dryRun {
+		enforcePolicy(filename, config.ActionReattach, currentActor(*actor))
+	}
+
+	// Read without validating: validation would orphan or move the very
+	// comments the shift is meant to put back
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	mergePrivateNotes(filename, doc, *actor)
+
+	moved, err := doc.ShiftLines(*afterLine, *by)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	direction, count := "down", *by
+	if *by < 0 {
+		direction, count = "up", -*by
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would shift %d thread(s) below line %d %s by %d line(s)\n", moved, *afterLine, direction, count)
+		fmt.Fprintln(stdout, "Dry run: nothing was saved")
+		return
+	}
+
+	// Save changes
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Shifted %d thread(s) below line %d %s by %d line(s)\n"), moved, *afterLine, direction, count)
 }
 
 func cleanupCommand(filename string, args []string) {
@@ -1122,117 +2126,216 @@ func cleanupCommand(filename string, args []string) {
 	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
 	dryRun := fs.Bool("dry-run", false, "Show what would be cleaned up without actually doing it")
 	statusFilter := fs.String("status", "completed", "Status to clean up (completed or resolved)")
+	olderThan := fs.String("older-than", "", "Only clean up threads with no activity for this long (e.g., 90d, 2w, 36h)")
+	authorFilter := fs.String("author", "", "Only clean up threads started by this author")
+	applyPolicy := fs.Bool("apply-policy", false, "Apply the retention rules from the project config")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
 
 	fs.Parse(args)
 
-	// Validate status
-	if *statusFilter != "completed" && *statusFilter != "resolved" {
-		fmt.Println("Error: --status must be 'completed' or 'resolved'")
-		os.Exit(1)
+	// Build selection rules from flags or the project retention policy
+	var rules []comment.ArchiveCriteria
+	if *applyPolicy {
+		conflicting := false
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "status" || f.Name == "older-than" || f.Name == "author" {
+				conflicting = true
+			}
+		})
+		if conflicting {
+			fmt.Fprintln(stdout, "Error: --apply-policy cannot be combined with --status, --older-than, or --author")
+			os.Exit(1)
+		}
+
+		cfg := loadPolicy(filename)
+		if len(cfg.Retention) == 0 {
+			fmt.Fprintf(stdout, "Error: no retention rules configured (add \"retention\" to %s)\n", config.FileName)
+			os.Exit(1)
+		}
+		for _, rule := range cfg.Retention {
+			criteria, err := rule.Criteria()
+			if err != nil {
+				fmt.Fprintf(stdout, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			rules = append(rules, criteria)
+		}
+	} else {
+		// Validate status
+		if *statusFilter != "completed" && *statusFilter != "resolved" {
+			fmt.Fprintln(stdout, "Error: --status must be 'completed' or 'resolved'")
+			os.Exit(1)
+		}
+
+		criteria := comment.ArchiveCriteria{Status: *statusFilter, Author: *authorFilter}
+		if *olderThan != "" {
+			age, err := comment.ParseAge(*olderThan)
+			if err != nil {
+				fmt.Fprintf(stdout, "Error: --older-than: %v\n", err)
+				os.Exit(1)
+			}
+			criteria.OlderThan = age
+		}
+		rules = append(rules, criteria)
+	}
+
+	// Check project policy before making changes (dry runs are always allowed)
+	if !*dryRun {
+		enforcePolicy(filename, config.ActionCleanup, currentActor(*actor))
 	}
 
 	// Load document
 	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error loading document: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Find comments to clean up
+	// Find threads to clean up, grouped by the status they are archived under
+	now := time.Now()
+	selected := make(map[string]bool)
+	byStatus := make(map[string][]*comment.Comment)
+	var statuses []string
 	var toCleanup []*comment.Comment
-	for _, c := range doc.GetAllComments() {
-		if c.GetStatus() == *statusFilter {
-			toCleanup = append(toCleanup, c)
+	for _, rule := range rules {
+		for _, thread := range comment.SelectThreadsForArchive(doc.Threads, rule, now) {
+			if selected[thread.ID] {
+				continue
+			}
+			selected[thread.ID] = true
+			if _, ok := byStatus[rule.Status]; !ok {
+				statuses = append(statuses, rule.Status)
+			}
+			byStatus[rule.Status] = append(byStatus[rule.Status], thread)
+			toCleanup = append(toCleanup, thread)
 		}
 	}
 
 	if len(toCleanup) == 0 {
-		fmt.Printf("No %s comments to clean up\n", *statusFilter)
+		if *applyPolicy {
+			fmt.Fprintln(stdout, "No threads match the retention policy")
+		} else {
+			fmt.Fprintf(stdout, "No %s comments to clean up\n", *statusFilter)
+		}
 		return
 	}
 
 	// Show what will be cleaned up
-	fmt.Printf("Found %d %s comment(s) to clean up:\n\n", len(toCleanup), *statusFilter)
+	fmt.Fprintf(stdout, "Found %d thread(s) to clean up:\n\n", len(toCleanup))
 	for i, c := range toCleanup {
-		fmt.Printf("[%d] %s • @%s • Line %d\n", i+1, c.ID, c.Author, c.Line)
-		fmt.Printf("    %s\n\n", c.Text)
+		fmt.Fprintf(stdout, forStdout("[%d] %s • @%s • Line %d • last activity %s\n"), i+1, c.ID, c.Author, c.Line, c.LatestTimestamp().Format("2006-01-02"))
+		fmt.Fprintf(stdout, "    %s\n\n", c.Text)
 	}
 
 	if *dryRun {
-		fmt.Println("Dry run - no changes made")
+		fmt.Fprintln(stdout, "Dry run - no changes made")
 		return
 	}
 
-	// Archive to separate file
-	sidecarPath := comment.GetSidecarPath(filename)
-	archivePath := strings.TrimSuffix(sidecarPath, ".json") + fmt.Sprintf(".archived.%s", *statusFilter)
-
-	// Load or create archive
-	var archive comment.StorageFormat
-	if archiveData, err := os.ReadFile(archivePath); err == nil {
-		json.Unmarshal(archiveData, &archive)
-	} else {
-		archive = comment.StorageFormat{
-			Version:       comment.StorageVersion,
-			Threads:       []*comment.Comment{},
-			DocumentHash:  doc.DocumentHash,
-			LastValidated: doc.LastValidated,
-		}
-	}
-
-	// Remove from doc.Threads and add to archive
-	cleanupIDs := make(map[string]bool)
-	for _, c := range toCleanup {
-		cleanupIDs[c.ID] = true
-		// Only add root comments to archive (replies are nested)
-		for _, thread := range doc.Threads {
-			if thread.ID == c.ID {
-				archive.Threads = append(archive.Threads, thread)
-				break
-			}
-		}
-	}
+	backupSidecar(filename)
 
-	// Filter out cleaned up threads
-	var remainingThreads []*comment.Comment
-	for _, thread := range doc.Threads {
-		if !cleanupIDs[thread.ID] {
-			remainingThreads = append(remainingThreads, thread)
+	// Archive to separate files per status
+	var archivePaths []string
+	for _, status := range statuses {
+		archivePath, err := comment.ArchiveThreads(filename, doc, byStatus[status], status)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error writing archive: %v\n", err)
+			os.Exit(1)
 		}
+		archivePaths = append(archivePaths, archivePath)
 	}
-	doc.Threads = remainingThreads
 
 	// Save updated sidecar
 	if err := comment.SaveToSidecar(filename, doc); err != nil {
-		fmt.Printf("Error saving sidecar: %v\n", err)
+		fmt.Fprintf(stdout, "Error saving sidecar: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("✓ Cleaned up %d thread(s)\n"), len(toCleanup))
+	for _, archivePath := range archivePaths {
+		fmt.Fprintf(stdout, forStdout("✓ Archived to: %s\n"), archivePath)
+	}
+	fmt.Fprintln(stdout, "  Use 'comments list --include-archived' to query archived threads")
+}
+
+func restoreCommand(filename string, args []string) {
+	// Parse flags
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	commentID := fs.String("comment", "", "ID of the archived thread (or any reply in it) to restore (required)")
+	reopen := fs.Bool("reopen", false, "Mark the restored thread as unresolved and active")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if *commentID == "" {
+		fmt.Fprintln(stdout, "Error: --comment flag is required")
+		fmt.Fprintln(stdout, "Usage: comments restore <file> --comment ID [--reopen]")
+		fmt.Fprintln(stdout, "Find archived IDs with: comments list <file> --include-archived")
 		os.Exit(1)
 	}
 
-	// Save archive
-	archiveBytes, err := json.MarshalIndent(archive, "", "  ")
+	// Check project policy before making changes
+	enforcePolicy(filename, config.ActionRestore, currentActor(*actor))
+
+	// Locate the thread in the archives
+	thread, archivePath, err := comment.FindArchivedThread(filename, *commentID)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load document
+	doc, err := comment.LoadFromSidecar(filename)
 	if err != nil {
-		fmt.Printf("Error creating archive: %v\n", err)
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	if doc.FindThreadByID(thread.ID) != nil {
+		fmt.Fprintf(stdout, "Error: thread %s already exists in the active sidecar\n", thread.ID)
+		os.Exit(1)
+	}
+
+	if *reopen {
+		thread.Resolved = false
+		thread.ResolvedAt = nil
+		thread.Status = "active"
+	}
+
+	doc.Threads = append(doc.Threads, thread)
+
+	// Save the sidecar before touching the archive so a failure never loses the thread
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
-	if err := os.WriteFile(archivePath, archiveBytes, 0644); err != nil {
-		fmt.Printf("Error writing archive: %v\n", err)
+
+	if err := comment.RemoveFromArchive(archivePath, thread.ID); err != nil {
+		fmt.Fprintf(stdout, "Error updating archive: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Cleaned up %d %s comment(s)\n", len(toCleanup), *statusFilter)
-	fmt.Printf("✓ Archived to: %s\n", archivePath)
+	fmt.Fprintf(stdout, forStdout("✓ Restored thread %s (Line %d) from %s\n"), thread.ID, thread.Line, archivePath)
 }
 
 func printUsage() {
-	usage := `comments - CLI tool for collaborative document commenting
+	usage := forStdout(`comments - CLI tool for collaborative document commenting
 
 Usage:
   comments <command> [arguments]
 
 Commands:
-  view <file>                 Open interactive TUI viewer
+  view <file|dir> [flags]     Open interactive TUI viewer (a directory opens a multi-file workspace)
   list <file> [flags]         List all comments in a file
   get <file> [flags]          Get detailed comment with context
+  find <dir> [flags]          Search comments across all documents in a directory tree
+  stats <file|dir> [flags]    Summarize comment activity for a file or directory tree
+  digest <file|dir> [flags]   Summarize recent comments, replies, resolutions, and accepts
+  validate <file|dir> [flags] Check sidecars against their markdown without modifying them
+  check <file|dir> [flags]    Fail while suggestions in changed sections await review by their owners
+  status-report <file|dir>    Show each section owner's outstanding review load
+  lint-comments <file|dir>    Fail if comments break the project's lint rules (length, characters, type)
+  doctor [dir] [flags]        Find broken, stale, or stray comment files and config problems
   add <file> [flags]          Add a comment to a specific line
   batch-add <file> [flags]    Add multiple comments from JSON
   reply <file> [flags]        Reply to a comment thread
@@ -1242,13 +2345,46 @@ Commands:
   accept <file> [flags]       Accept a suggestion and apply changes
   reject <file> [flags]       Reject a suggestion
   batch-accept <file> [flags] Accept multiple suggestions at once
+  preview <file> [flags]      Show the document with a set of suggestions applied (nothing is saved)
+  conflicts <file> [flags]    Compare overlapping suggestions side by side and resolve them
   status <file> [flags]       Update comment status (active/orphaned/resolved/completed)
+  bulk-status <file> [flags]  Set the status of every thread matching a list filter
+  sync <file> [flags]         Mark suggestions accepted whose text is already in the document
+  pin <file> [flags]          Pin a thread so it is listed first (or --unpin it)
   reattach <file> [flags]     Reattach an orphaned comment to a new line/section
+  shift <file> [flags]        Move every comment below a line by N lines (after an outside edit)
+  migrate [flags]             Move a section's threads to the document it was pasted into
+  import [flags]              Import comments from a Google Docs export or Word document onto the markdown version
+  merge-reviews <file> <copy>... Union the threads in reviewers' copies of the sidecar
+  storage <file> [flags]      Show or convert where threads are stored (sidecar or embedded)
   cleanup <file> [flags]      Archive completed/resolved comments
-  export <file> [flags]       Export comments to JSON format
+  restore <file> [flags]      Move an archived thread back into the active sidecar
+  drafts <action> <file>      List, publish, or discard private draft comments
+  queue <action> <file>       Stage writes and flush them to the sidecar in one save
+  review <action> <file>      Start, submit (with a verdict), or list review sessions
+  blame <file> [flags]        Show every thread that commented on or changed a line or range
+  history <file>              List the revisions made by accepting suggestions
+  revert <file> [flags]       Roll the document back to an earlier revision
+  backups <action> <file>     List, restore, or prune sidecar backups
+  export <file|dir> [flags]   Export comments to JSON, or decided suggestions as training pairs
   publish <file> [flags]      Output clean markdown without comments
+  decisions <file> [flags]    Compile resolved [B]/[T] threads into an ADR-style decision log
+  verify <file> [flags]       Verify comment signatures (tampered/unattributed entries)
+  keygen [flags]              Create the local ed25519 key used to sign comments
+  tail <file|dir> [flags]     Stream comment events as newline-delimited JSON
+  lsp [flags]                 Run editor integration server over stdio
+  bridge <dir> [flags]        Serve Slack/Teams chat commands and email replies for a directory
+  mail-in <dir> [flags]       Add the reply in an email read from stdin (for mail delivery pipes)
+  demo [flags]                Try every feature on a sample document in a scratch directory
   help                        Show this help message
 
+Global Flags (any command):
+  --verbose                   Trace loads, validation, and saves to stderr
+  --log-file <path>           Append JSON log records to a file
+  --sidecar-dir <dir>         Keep sidecars under <dir> (e.g. .comments), mirroring the document tree
+  --auto-restore              Restore a backup of the document's current content when its sidecar is missing
+  --ascii                     Plain markers instead of symbols (default when output isn't a terminal; --ascii=false to keep them)
+
 List Command Flags:
   --type <type>               Filter by comment type: Q, S, B, T, E
   --resolved                  Show resolved comments (default: false, only shows unresolved)
@@ -1258,37 +2394,139 @@ List Command Flags:
   --section <path>            Filter by section path (includes nested sections)
   --status <status>           Filter by status: active, orphaned, resolved, completed
   --priority <priority>       Filter by priority: low, medium, high
-  --sort <field>              Sort by: line (default), timestamp, author, priority
-  --format <format>           Output format: text (default), json, table
+  --sort <field>              Sort by: line (default), timestamp, author, priority, activity (most recent first),
+                              score (most urgent first: type, priority, age, and activity)
+  --format <format>           Output format: text (default), json, table, markdown
+  --columns <list>            Table columns (default: line,author,type,replies,preview); also
+                              id, priority, status, section, date
+  --wide                      Don't truncate table cells to fit the terminal width
+  --template <tmpl>           Go template run for each thread, over the fields of the JSON
+                              output ({{.ID}}, {{.Line}}, {{.Author}}, {{.Text}}, ...);
+                              \t and \n are unescaped. Overrides --format
   --with-context              Include document context for each comment
+  --context <n>               Lines of context before/after each comment (default: 5)
+  --time-format <format>      How to show timestamps: local (default), relative (e.g. 2h ago), iso
+  --include-archived          Include threads archived by cleanup (flagged as archived)
+  --include-replies           Embed each thread's nested replies in JSON and --template output
+  --suggestions               List only suggestions
+  --changed-only              List threads whose text was edited since they were made (⚠ CHANGED)
+  --unanswered                List questions ([Q] threads) the document owners haven't answered yet
+  --pending                   List pending suggestions (implies --suggestions)
+  --accepted                  List accepted suggestions (implies --suggestions and --resolved)
+  --rejected                  List rejected suggestions (implies --suggestions and --resolved)
+  --unread                    List threads with activity from others since you last viewed them;
+                              --author names you (default: $COMMENTS_AUTHOR or $USER) and
+                              matches threads you wrote in
+  --mark-read                 Mark the listed threads as viewed by you
 
 Get Command Flags:
-  --thread <id>               Thread ID to retrieve (required)
+  --thread <id>[,<id>...]     Thread or reply IDs to retrieve
+  --line <n>                  Retrieve the threads on line N (0 for document-level threads)
+  --section <path>            Retrieve the threads in a section (includes nested sections)
+  --resolved                  Include resolved threads with --line/--section
   --with-replies              Include replies in output (default: true)
+  --context <n>               Lines of context before/after the comment (default: 5)
+  --time-format <format>      How to show timestamps: local (default), relative (e.g. 2h ago), iso
+  --copy                      Also copy the comment to the clipboard (system clipboard or OSC52)
+  --copy-as <what>            What to copy: text, id, quote (default: quote; quoted text + comment + file#L link)
+  --template <tmpl>           Go template over the same fields as list --template, context included
+
+Find Command Flags:
+  --search <text>             Search thread and reply text (case-insensitive)
+  --type <type>               Filter by comment type: Q, S, B, T, E
+  --status <status>           Filter by status: active, orphaned, resolved, completed
+  --author <name>             Filter by thread author
+  --resolved                  Include resolved threads
+  --format <format>           Output format: text (default), json
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar (only drawn when stderr is a terminal)
+
+Stats Command Flags:
+  --format <format>           Output format: text (default), json; csv (default), json with --history
+  --history                   Open and resolved threads after each git commit of each sidecar
+  --per-file                  Include a breakdown for each file
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar
+
+Digest Command Flags:
+  --since <age>               How far back to look: 24h (default), 7d, 2w
+  --format <format>           Output format: markdown (default), email, json, atom
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar
+
+Validate Command Flags:
+  --quiet                     Only print files with problems
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar
+
+Check Command Flags:
+  --format <format>           Output format: text, json (default: text)
+  --quiet                     Only print files with problems
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar
+
+Lint-Comments Command Flags:
+  --format <format>           Output format: text, json (default: text)
+  --quiet                     Only print files with problems
+  --workers <n>               Files read concurrently (default: number of CPUs)
+  --no-progress               Don't show a progress bar
+
+Doctor Command Flags:
+  --fix                       Repair stale hashes, duplicate IDs, and leftover temporary files
+  --as <name>                 Who is making the repairs (default: $COMMENTS_AUTHOR or $USER)
+
+View Command Flags:
+  --context <n>               Lines of context shown in TUI modals and thread view (default: 2)
+  --read-only                 Browse comments without allowing any changes
+  --no-session                Don't resume or save the last file, scroll position, and filters
+  --no-recovery               Don't autosave unsent comment text or offer to restore it
+  --layout <name>             Pane layout: auto, split, stacked, or single (default: auto)
+  --split <ratio>             Document pane's share of the width, or height when stacked (default: 0.6, or as last set with < and >)
+  --theme <name>              default, high-contrast, or mono (no color, text markers, ASCII symbols)
+  --time-format <format>      How to show timestamps: local (default), relative (e.g. 2h ago), iso
 
 Add Command Flags:
   --line <number>             Line number (use either --line or --section)
   --section <path>            Section path (use either --line or --section)
-  --text <text>               Comment text (required, supports @filename)
+  --document                  Comment on the document as a whole (same as --line 0)
+  --start-line <n>            First line of a range comment (with --end-line)
+  --end-line <n>              Last line of a range comment (with --start-line)
+  --text <text>               Comment text (required unless --edit, supports @filename)
+  --edit                      Write the comment in $EDITOR (starts from --text, if given)
   --author <name>             Author name (required)
   --type <type>               Comment type: Q, S, B, T, E (auto-prefixes text)
   --priority <priority>       Priority: low, medium, high (default: medium)
+  --sign                      Sign the comment with the local key (see keygen)
+  --draft                     Save as a private draft (see drafts) instead of sharing it
+  --visibility <v>            shared (default) or private: a note only you see, kept out of the sidecar
+  --queue                     Stage the comment until 'queue flush' instead of writing the sidecar
+  --anchor <mode>             line (default) or heading: follow the section's heading when it moves
 
 Batch-Add Command Flags:
   --json <file|->             JSON file path or '-' for stdin (required)
+  --sign                      Sign the comments with the local key
+  --dedupe <mode>             Near-duplicates of open comments: skip (default), merge, off
+  --dedupe-threshold <n>      Text similarity (0-1) that counts as a duplicate (default: 0.9)
                               Note: Each comment in JSON must include "author" field
+                              Items with an "idempotency_key" already applied are skipped
 
 Reply Command Flags:
   --thread <id>               Thread ID (required)
-  --text <text>               Reply text (required)
+  --text <text>               Reply text (required unless --edit)
+  --edit                      Write the reply in $EDITOR (starts from --text, if given)
   --author <name>             Author name (required)
+  --sign                      Sign the reply with the local key
+  --queue                     Stage the reply until 'queue flush' (it may answer a queued thread)
 
 Batch-Reply Command Flags:
   --json <file|->             JSON file path or '-' for stdin (required)
+  --sign                      Sign the replies with the local key
                               Note: Each reply in JSON must include "thread" and "author" fields
+                              Items with an "idempotency_key" already applied are skipped
 
 Resolve Command Flags:
   --thread <id>               Thread ID (required)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Suggest Command Flags:
   --line <number>             Line number (required for line/diff-hunk types)
@@ -1297,47 +2535,234 @@ Suggest Command Flags:
   --type <type>               Suggestion type: line (default), char-range, multi-line, diff-hunk
   --original <text>           Original text to replace (required)
   --proposed <text>           Proposed replacement text (required)
+  --depends-on <ids>          Comma-separated suggestion IDs that must be applied first
   --start-line <number>       Start line (for multi-line type)
   --end-line <number>         End line (for multi-line type)
+  --insert-after <number>     Insert the proposed text after this line (0 for the top) instead of replacing lines
+  --move-after <number>       Move the lines or section after this line (0 for the top) instead of replacing them
   --offset <number>           Byte offset (for char-range type)
   --length <number>           Length in bytes (for char-range type)
+  --sign                      Sign the suggestion with the local key
+  --queue                     Stage the suggestion until 'queue flush' instead of writing the sidecar
+  --anchor <mode>             line (default) or heading: follow the section's heading when it moves
 
 Accept Command Flags:
   --suggestion <id>           Suggestion ID (required)
   --preview                   Preview changes without applying
+  --reason <text>             Why it was accepted, recorded as a reply (supports @filename)
+  --allow-self-accept         Accept your own suggestion despite self_accept (owners only)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Reject Command Flags:
   --suggestion <id>           Suggestion ID (required)
+  --reason <text>             Why it was rejected, recorded as a reply (supports @filename)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Batch-Accept Command Flags:
   --json <file|->             JSON file path or '-' for stdin (suggestion IDs)
   --author <name>             Accept all suggestions from this author
+  --section <path>            Accept non-conflicting suggestions entirely inside a section (one save)
   --type <type>               Accept all suggestions of this type
   --check-conflicts           Check for conflicts before accepting (default: true)
+  --reason <text>             Why they were accepted, recorded as a reply on each (supports @filename)
+  --allow-self-accept         Accept your own suggestions despite self_accept (owners only)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Preview Command Flags:
+  --suggestions <ids>         Comma-separated suggestion IDs to apply (default: all pending)
+  --diff                      Print a unified diff against the current document
+  --context <n>               Lines of context around each change in --diff output (default: 3)
+
+Conflicts Command Flags:
+  --pick <id>                 Accept this suggestion and reject those conflicting with it
+  --merge <id1,id2>           Merge two conflicting suggestions by hand (opens $EDITOR)
+  --text <text>               Merged text for --merge instead of the editor (supports @filename)
+  --reject-both <id1,id2>     Reject both conflicting suggestions
+  --format <format>           Listing format: text (default), json
+  --width <n>                 Total width of the side-by-side listing (default: 100)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Status Command Flags:
   --comment <id>              Comment ID to update (required)
   --status <status>           New status: active, orphaned, resolved, completed (required)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Bulk-status Command Flags:
+  --filter '<flags>'          list filter flags selecting threads, e.g. '--type T --author bot' (required)
+  --set <status>              New status: active, orphaned, resolved, completed (required)
+  --dry-run                   Show what would change without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Sync Command Flags:
+  --dry-run                   Report what would be marked accepted without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Shift Command Flags:
+  --after-line <n>            Shift the comments below this line (default: 0, every comment)
+  --by <n>                    Lines added (positive) or removed (negative) after that line (required)
+  --dry-run                   Check the shift and report what would move without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Pin Command Flags:
+  --comment <id>              Thread ID to pin (required)
+  --unpin                     Unpin the thread instead
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Reattach Command Flags:
   --comment <id>              Comment ID to reattach (required)
   --line <number>             New line number (use either --line or --section)
   --section <path>            Section path (use either --line or --section)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Migrate Command Flags:
+  --from <file>               Document the section was cut from (required)
+  --to <file>                 Document the section was pasted into (required)
+  --section <path>            Section that moved; the end of its path is enough (required)
+  --dry-run                   Show which threads would move without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Import Command Flags:
+  --from-gdoc <file>          Google Docs web page export: the .zip from File > Download > Web page,
+                              or the .html in it
+  --from-docx <file>          Word document: its comments become threads, tracked changes pending suggestions
+  --to <file>                 Markdown version of the document to add the comments to (required)
+  --author <name>             Author for comments the export doesn't attribute (default: google-docs or word)
+  --dry-run                   Show where each comment would go without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Merge Reviews Command Flags:
+  --dry-run                   Show what each copy would add without saving
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Storage Command Flags:
+  --mode <mode>               Convert to sidecar or embedded (a comments block at the end of the markdown)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Cleanup Command Flags:
   --status <status>           Status to clean up: completed (default) or resolved
+  --older-than <age>          Only threads with no activity for this long (e.g., 90d, 2w, 36h)
+  --author <name>             Only threads started by this author
+  --apply-policy              Apply the retention rules from the project config instead
   --dry-run                   Preview what would be cleaned up without doing it
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Restore Command Flags:
+  --comment <id>              Archived thread ID, or any reply ID in it (required)
+  --reopen                    Mark the restored thread as unresolved and active
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Queue Command (comments queue <add|reply|suggest|list|review|flush|discard> <file>):
+  add, reply, suggest         Take the flags of the command of the same name and stage the write
+  --author <name>             list/review/flush/discard: only operations by this author (default: all)
+  review                      Approve, edit, drop, merge, or skip each operation, then write the approved ones
+
+Drafts Command (comments drafts <list|publish|discard> <file>):
+  --author <name>             Only drafts by this author (default: all drafts)
+
+Review Command (comments review <start|submit|list> <file>):
+  --reviewer <name>           Reviewer (default: $COMMENTS_AUTHOR or $USER)
+  --verdict <verdict>         Required for submit: approve, request-changes
+  --text <text>               Overall feedback for submit (supports @filename)
+
+Blame Command Flags:
+  --line <n>                  Line to trace
+  --lines <start-end>         Range of lines to trace (e.g., 10-30)
+  --format <format>           Output format: text, json (default: text)
+
+Revert Command Flags:
+  --to <n>                    Revision to roll back to, from 'comments history' (required; 0 = before the first)
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
+
+Backups Command (comments backups <list|restore|prune> <file>):
+  --backup <n|timestamp|path> Backup to restore (default: 1, the newest in 'backups list')
+  --keep <n>                  Backups to keep when pruning (default: backups.keep from the config, or 5)
+  --dry-run                   Show what prune would remove without deleting anything
+  --as <name>                 Who is making the change (default: $COMMENTS_AUTHOR or $USER)
 
 Export Command Flags:
-  --format <format>           Export format: json (default: json)
+  --format <format>           Export format: json, training-pairs (default: json)
+                              training-pairs writes one JSON object per accepted or rejected
+                              suggestion: context, suggestion, outcome, reason, final text
   --output <file>             Output file (default: stdout)
+  --context <n>               Lines of context on each side of a suggestion (default: 5)
+  --no-progress               Don't show a progress bar on stderr
 
 Publish Command Flags:
   --output <file>             Output file (default: stdout)
+  --appendix                  Append Open Questions (unresolved [Q]) and Decisions (resolved [B]) sections
+  --appendix-template <file>  Go template for the appendix (implies --appendix); sees .Document,
+                              .OpenQuestions, and .Decisions, each thread with the JSON output's
+                              fields plus .Body (text without the type prefix) and .Outcome (last reply)
+
+Decisions Command Flags:
+  --output <file>             Decision log to append new records to; threads it already
+                              records are skipped (default: print the whole log to stdout)
+
+Bridge Command Flags:
+  --addr <host:port>          Address to listen on (default: :8080)
+  --sign                      Sign every reply with the local signing key
+  --email-domains <list>      Comma-separated sender domains email replies are accepted from
+                              Secrets come from COMMENTS_SLACK_SIGNING_SECRET (POST /slack),
+                              COMMENTS_TEAMS_SECRET (POST /teams), and COMMENTS_EMAIL_TOKEN
+                              (POST /email); set at least one
+
+Mail-in Command Flags:
+  --sign                      Sign the reply with the local signing key
+  --email-domains <list>      Comma-separated sender domains replies are accepted from
+
+Verify Command Flags:
+  --require-signed            Fail on unsigned comments (default when sign_comments is enabled)
+  --format <format>           Output format: text (default), json
+
+Keygen Command Flags:
+  --force                     Replace an existing signing key
+                              Key location: $COMMENTS_SIGNING_KEY or <user config dir>/comments/signing_key
+
+Tail Command Flags:
+  --interval <duration>       How often to check for changes (default: 1s)
+  --replay                    Emit events for existing comments before watching
+                              Event types: comment_added, comment_replied, thread_resolved,
+                              thread_reopened, suggestion_added, suggestion_accepted,
+                              suggestion_rejected
+
+LSP Command Flags:
+  --author <name>             Author for replies (default: $USER)
+                              Threads are published as diagnostics; code actions resolve
+                              threads and accept/reject suggestions. Commands:
+                              comments.resolve, comments.reply, comments.accept, comments.reject
+
+Demo Command Flags:
+  --dir <path>                Directory to write the demo into (default: a new temp directory)
+  --no-view                   Only create the demo files; don't open the TUI
+
+Project Config (.comments.config.json, searched upward from the document):
+  {
+    "read_only": false,              // Disable all changes (also: COMMENTS_READ_ONLY=1)
+    "owners": ["alice"],             // Role "owner"
+    "agents": ["claude", "bot"],     // Role "agent"; everyone else is "human"
+    "permissions": {                 // Action -> allowed roles or author names
+      "accept": ["owner"],           // Unlisted actions are allowed for everyone
+      "resolve": ["owner", "human"]
+    },
+    "sign_comments": false,          // Sign every new comment with the local key
+    "trusted_keys": {                // Author -> public keys allowed to sign as them
+      "alice": ["<base64 public key>"]
+    },
+    "retention": [                   // Rules for 'cleanup --apply-policy'
+      {"status": "resolved", "author": "bot", "older_than": "30d"},
+      {"status": "completed", "older_than": "90d"}
+    ]
+  }
+  Actions: add, reply, resolve, suggest, accept, reject, status, reattach, cleanup, restore
 
 Examples:
   # Interactive mode
   comments view document.md
+  comments view document.md --read-only                  # Browse without making changes
+  comments view ./docs                                   # Review every markdown file ([ and ] switch files)
+  comments view document.md --layout single              # One pane at a time; Tab switches document/comments
+  comments view document.md --theme mono                 # No color or emoji, for screen readers and limited terminals
+  comments demo                                          # Explore a sample document with comments
 
   # List with filters (can combine multiple filters!)
   comments list document.md                              # Show only unresolved comments
@@ -1348,6 +2773,11 @@ Examples:
   comments list document.md --line-range 10-50           # Comments between lines 10-50
   comments list document.md --author alice --type Q      # Alice's questions
   comments list document.md --format table               # Pretty table output
+  comments list document.md --format table --columns id,line,priority,preview
+  comments list document.md --resolved --format markdown # Checklist for a PR description
+  comments list document.md --pending --format json      # Proposed edits awaiting review
+  comments list document.md --unread --author me --mark-read  # Catch up on new activity
+  comments list document.md --template '{{.ID}}\t{{.Line}}\t{{.Text}}'
   comments list document.md --format json > output.json  # Export filtered results
   comments list document.md --with-context               # Show all comments with document context
   comments list document.md --type Q --with-context      # Show questions with context (great for LLMs!)
@@ -1355,11 +2785,26 @@ Examples:
   # Get detailed comment with context
   comments get document.md --thread c123                 # Get comment with full context
   comments get document.md --thread c456 --with-replies=false  # Get without replies
+  comments get document.md --thread c123 --context 15    # Show a wider window of the document
+  comments get document.md --thread c123,c456            # Several comments at once
+  comments get document.md --line 42                     # Every unresolved thread on line 42
+  comments get document.md --section "Intro" --context 2 # Every unresolved thread in a section
+
+  # Search across a project
+  comments find ./specs --search "rate limit" --type Q --status active
+  comments find . --author claude --format json
+  comments stats ./specs --per-file
+  comments stats ./specs --history > burndown.csv       # Review burndown from git history
+  comments digest ./docs --since 7d --format email     # Weekly summary for the team
+  comments validate . --quiet
+  comments doctor . --fix
 
   # Single comment (author required for CLI)
   comments add document.md --line 10 --author "claude" --text "This needs review"
   comments add document.md --line 15 --author "bot" --text "Great point!"
   comments add document.md --line 20 --author "reviewer" --type Q --text "Is this correct?"
+  comments add document.md --document --author "editor" --text "Overall structure is confusing"
+  comments add document.md --start-line 10 --end-line 25 --author "reviewer" --type Q --text "Does this paragraph belong here?"
 
   # Batch add comments from JSON (each comment must have author)
   comments batch-add document.md --json reviews.json
@@ -1368,6 +2813,7 @@ Examples:
 
   # Thread operations (author required for CLI)
   comments reply document.md --thread c123 --author "claude" --text "I agree"
+  comments reply document.md --thread c123 --author "alice" --edit
   comments batch-reply document.md --json replies.json
   echo '[{"thread":"c123","author":"claude","text":"LGTM"}]' | \
     comments batch-reply document.md --json -
@@ -1386,15 +2832,30 @@ Examples:
     --original "Line 1\nLine 2\nLine 3\nLine 4" \
     --proposed "New line 1\nNew line 2"
 
+  # Stacked suggestion - applied only after c123 (batch-accept orders them)
+  comments suggest document.md --start-line 5 --end-line 5 --author "writer" \
+    --text "Tighten wording" --original "New line 1" --proposed "Line one" --depends-on c123
+
   # Accept/reject suggestions
   comments accept document.md --suggestion c123 --preview  # Preview changes first
   comments accept document.md --suggestion c123            # Apply the changes
   comments reject document.md --suggestion c456            # Reject suggestion
 
   # Batch accept suggestions
-  comments batch-accept document.md --author "copywriter"  # Accept all from author
+  comments batch-accept document.md --author "copywriter"  # Accept all from author (dependencies first)
+  comments batch-accept document.md --section "Design > API"  # Accept an LLM rewrite of one chapter
   comments batch-accept document.md --type "line"          # Accept all line suggestions
 
+  # Evaluate a combination of suggestions before accepting them
+  comments preview document.md --suggestions c123,c456 --diff
+  comments preview document.md --suggestions c123,c456 > draft.md
+
+  # Resolve overlapping suggestions
+  comments conflicts document.md                           # Show competing proposals side by side
+  comments conflicts document.md --pick c123               # Keep c123, reject its competitors
+  comments conflicts document.md --merge c123,c456         # Combine both in $EDITOR
+  comments conflicts document.md --reject-both c123,c456
+
   # Status management - track TODOs and handle document changes
   comments list document.md --status orphaned              # View comments orphaned by edits
   comments list document.md --priority high                # View high-priority TODOs
@@ -1402,16 +2863,64 @@ Examples:
   comments status document.md --comment c123 --status completed  # Mark TODO as done
   comments reattach document.md --comment c456 --line 42   # Reattach orphaned comment
   comments reattach document.md --comment c789 --section "Introduction"  # Reattach to section
+  comments storage wiki-page.md --mode embedded  # Keep comments inside the markdown file
+  comments migrate --from guide.md --to reference.md --section "Appendix"  # Section moved to another doc
   comments cleanup document.md --dry-run                   # Preview cleanup
   comments cleanup document.md --status completed          # Archive completed TODOs
+  comments cleanup document.md --status resolved --author bot --older-than 90d  # Prune stale bot threads
+  comments cleanup document.md --apply-policy              # Apply project retention rules
+  comments list document.md --include-archived --search "API"  # Search live and archived threads
+  comments restore document.md --comment c123 --reopen     # Bring an archived thread back
+
+  # Compose a whole review privately, then share it at once
+  comments add document.md --line 10 --author "alice" --text "Unclear" --draft
+  comments drafts list document.md
+  comments drafts publish document.md --author "alice"
+
+  # Stage an agent's writes and land them in one save
+  comments queue suggest document.md --start-line 5 --end-line 5 --author "claude" --text "Tighten" --proposed "..."
+  comments queue add document.md --line 9 --author "claude" --text "Needs a source"
+  comments queue review document.md                       # Approve, edit, drop, or merge each one
+  comments queue flush document.md
+
+  # Group a pass of comments into a review with a verdict
+  comments review start document.md --reviewer "bob"
+  comments add document.md --line 12 --author "bob" --type B --text "Off by one"
+  comments review submit document.md --reviewer "bob" --verdict request-changes --text "One blocker"
+  comments review list document.md
+
+  # Why does this paragraph say this? (includes resolved, decided, and archived threads)
+  comments blame document.md --lines 40-52
+
+  # Roll back accepted suggestions; their comments move back and they reopen
+  comments history document.md
+  comments revert document.md --to 2
+
+  # Undo a bad accept or cleanup (set "backups": {"keep": 5} in .comments.config.json)
+  comments backups list document.md
+  comments backups restore document.md --backup 2
+  comments backups prune document.md --keep 3
 
   # Export comments for programmatic access
   comments export document.md                    # Print JSON to stdout
   comments export document.md --output comments.json  # Save to file
+  comments export docs/ --format training-pairs > pairs.jsonl  # Suggestion outcomes for evaluation
 
   # Publish clean markdown (strip all comments)
   comments publish document.md                   # Print to stdout
   comments publish document.md --output final.md # Save to file
+  comments publish document.md --appendix        # Add open questions and decisions
+
+  # Signed comments
+  comments keygen                                # Create a local signing key
+  comments add document.md --line 5 --author alice --text "Approved" --sign
+  comments verify document.md                    # Flag tampered or unattributed comments
+
+  # Stream events to a bot or another tool
+  comments tail docs/ | jq -c 'select(.type == "comment_added")'
+
+  # Editor integration (configure your editor to launch this for markdown files)
+  comments lsp --author alice
 
 Batch-Add JSON Format:
   [
@@ -1448,8 +2957,8 @@ Keyboard shortcuts (in view mode):
   q or Ctrl+C     Quit
 
 For more information, visit: https://github.com/rcliao/comments
-`
-	fmt.Print(usage)
+`)
+	fmt.Fprint(stdout, usage)
 }
 
 // resolveTextInput resolves text input that may be a file reference (@filename)
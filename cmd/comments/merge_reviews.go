@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// mergeReviewsCommand unions the threads of reviewers' copies of a
+// document's sidecar into its own, e.g. after sending the sidecar out to
+// several reviewers who each commented offline
+func mergeReviewsCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("merge-reviews", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Show what each copy would add without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	// The copies come first, flags after them or before
+	copies := []string{}
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		copies = append(copies, args[0])
+		args = args[1:]
+	}
+	fs.Parse(args)
+	copies = append(copies, fs.Args()...)
+
+	if len(copies) == 0 {
+		fmt.Fprintln(stdout, "Error: at least one reviewer's copy is required")
+		fmt.Fprintln(stdout, "Usage: comments merge-reviews <file> <copy.json>... [flags]")
+		os.Exit(1)
+	}
+
+	if !*dryRun {
+		enforcePolicy(filename, config.ActionAdd, currentActor(*actor))
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	var total comment.ReviewMerge
+	for _, path := range copies {
+		store, err := comment.LoadReviewCopy(path)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		merge := comment.MergeReviews(doc, store)
+		fmt.Fprintf(stdout, "  %s: %s\n", path, describeReviewMerge(merge))
+		if store.DocumentHash != "" && store.DocumentHash != doc.DocumentHash {
+			fmt.Fprintf(stdout, "    Warning: made against another version of %s; check where its comments landed with 'comments validate'\n", filename)
+		}
+		total.Threads += merge.Threads
+		total.Replies += merge.Replies
+		total.Resolved += merge.Resolved
+		total.Duplicates += merge.Duplicates
+	}
+	comment.ComputeSectionsForComments(doc)
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would merge %s from %s\n", describeReviewMerge(total), pluralize(len(copies), "copy"))
+		fmt.Fprintln(stdout, "Dry run: nothing was saved")
+		return
+	}
+	if total != (comment.ReviewMerge{}) {
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving comments: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Merged %s from %s into %s\n"), describeReviewMerge(total), pluralize(len(copies), "copy"), filename)
+}
+
+// describeReviewMerge says what a merge added, e.g. "2 threads, 1 reply"
+func describeReviewMerge(m comment.ReviewMerge) string {
+	parts := []string{pluralize(m.Threads, "thread"), pluralize(m.Replies, "reply")}
+	if m.Resolved > 0 {
+		parts = append(parts, fmt.Sprintf("%d resolved", m.Resolved))
+	}
+	if m.Duplicates > 0 {
+		parts = append(parts, pluralize(m.Duplicates, "duplicate")+" skipped")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// migrateCommand moves the threads of a section cut from one document and
+// pasted into another between their sidecars
+func migrateCommand(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "Document the section was cut from (required)")
+	to := fs.String("to", "", "Document the section was pasted into (required)")
+	sectionPath := fs.String("section", "", "Section that moved, e.g. \"Appendix\" (required)")
+	dryRun := fs.Bool("dry-run", false, "Show which threads would move without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if *from == "" || *to == "" || *sectionPath == "" {
+		fmt.Fprintln(stdout, "Error: --from, --to, and --section flags are required")
+		fmt.Fprintln(stdout, "Usage: comments migrate --from <file> --to <file> --section <path>")
+		os.Exit(1)
+	}
+	if filepath.Clean(*from) == filepath.Clean(*to) {
+		fmt.Fprintln(stdout, "Error: --from and --to are the same document; use 'comments reattach' to move threads within a document")
+		os.Exit(1)
+	}
+
+	// Moving threads reattaches them, in both documents
+	enforcePolicy(*from, config.ActionReattach, currentActor(*actor))
+	enforcePolicy(*to, config.ActionReattach, currentActor(*actor))
+
+	src, err := comment.LoadFromSidecar(*from)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading %s: %v\n", *from, err)
+		os.Exit(1)
+	}
+	dest, err := comment.LoadFromSidecar(*to)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading %s: %v\n", *to, err)
+		os.Exit(1)
+	}
+
+	moved, err := comment.MigrateSection(src, dest, *sectionPath)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(moved) == 0 {
+		fmt.Fprintf(stdout, "No threads in section '%s' of %s\n", *sectionPath, *from)
+		return
+	}
+
+	for _, thread := range moved {
+		fmt.Fprintf(stdout, forStdout("  %s → %s line %d: %s\n"), thread.ID, *to, thread.Line, ansi.Truncate(strings.Join(strings.Fields(thread.Text), " "), 60, forStdout("…")))
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would move %d thread(s) from %s to %s (dry run, nothing saved)\n", len(moved), *from, *to)
+		return
+	}
+
+	// Save the destination first so a failed save can't lose threads, only
+	// leave them in both sidecars
+	if err := comment.SaveToSidecar(*to, dest); err != nil {
+		fmt.Fprintf(stdout, "Error saving %s: %v\n", *to, err)
+		os.Exit(1)
+	}
+	if err := comment.SaveToSidecar(*from, src); err != nil {
+		fmt.Fprintf(stdout, "Error saving %s: %v\n", *from, err)
+		fmt.Fprintf(stdout, "The threads were copied to %s but are still in %s\n", *to, *from)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, "Moved %d thread(s) from %s to %s\n", len(moved), *from, *to)
+}
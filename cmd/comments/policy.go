@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// currentActor returns who is making a change: the explicit name if given,
+// otherwise $COMMENTS_AUTHOR, $USER, or "user"
+func currentActor(name string) string {
+	if name != "" {
+		return name
+	}
+	if env := os.Getenv("COMMENTS_AUTHOR"); env != "" {
+		return env
+	}
+	if env := os.Getenv("USER"); env != "" {
+		return env
+	}
+	return "user"
+}
+
+// loadPolicy loads the project config for a document, exiting on error
+func loadPolicy(filename string) *config.Config {
+	cfg, err := config.LoadForDocument(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading project config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// redactionFor returns the project's redaction rules for a document, applied
+// to everything that leaves the team (export, publish)
+func redactionFor(filename string) (comment.Redaction, error) {
+	cfg, err := config.LoadForDocument(filename)
+	if err != nil {
+		return comment.Redaction{}, fmt.Errorf("failed to load project config: %w", err)
+	}
+	return cfg.Redaction.Rules(), nil
+}
+
+// enforcePolicy exits with an error if the project config forbids actor from
+// performing action on the given document
+func enforcePolicy(filename, action, actor string) {
+	cfg := loadPolicy(filename)
+
+	if err := cfg.Check(action, actor); err != nil {
+		slog.Info("policy denied action", "file", filename, "action", action, "actor", actor, "config", cfg.Path, "err", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		if cfg.Path != "" {
+			fmt.Fprintf(stdout, "Policy defined in %s\n", cfg.Path)
+		}
+		os.Exit(1)
+	}
+}
+
+// enforceSelfAccept exits with an error if the project's self_accept rule
+// forbids actor from accepting suggestion s, their own
+func enforceSelfAccept(filename string, doc *comment.DocumentWithComments, s *comment.Comment, actor string, override bool) {
+	cfg := loadPolicy(filename)
+
+	if err := cfg.CheckAccept(doc, s, actor, override); err != nil {
+		slog.Info("policy denied self-acceptance", "file", filename, "suggestion", s.ID, "actor", actor, "config", cfg.Path, "err", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		if override {
+			fmt.Fprintf(stdout, "Only owners can override the rule; see owners in %s\n", cfg.Path)
+		}
+		os.Exit(1)
+	}
+}
+
+// screenComment runs new comment c through the project's content filter,
+// which may change its text, and lint rules, exiting with an error if it
+// must not be stored. thread is true when c starts a thread.
+func screenComment(filename string, c *comment.Comment, thread bool) {
+	cfg := loadPolicy(filename)
+
+	err := cfg.Screen(c, thread)
+	if err == nil {
+		return
+	}
+	var lint *config.LintError
+	var rejection *comment.FilterRejection
+	switch {
+	case errors.As(err, &lint):
+		for _, issue := range lint.Issues {
+			fmt.Fprintf(stdout, "Error: %s: %s\n", issue.Rule, issue.Message)
+		}
+		fmt.Fprintf(stdout, "Lint rules defined in %s\n", cfg.Path)
+	case errors.As(err, &rejection):
+		slog.Info("content filter refused comment", "file", filename, "author", c.Author, "config", cfg.Path, "err", err)
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		fmt.Fprintf(stdout, "Content filter defined in %s\n", cfg.Path)
+	default:
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+	}
+	os.Exit(1)
+}
+
+// screenBatch screens each of a batch's new comments (see screenComment),
+// reporting every refusal by its place in the batch before exiting, so none
+// of the batch is added unless all of it may be
+func screenBatch(cfg *config.Config, noun string, batch []*comment.Comment, thread bool) {
+	refused := false
+	for i, c := range batch {
+		err := cfg.Screen(c, thread)
+		var lint *config.LintError
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &lint):
+			for _, issue := range lint.Issues {
+				fmt.Fprintf(stdout, "Error in %s %d: %s: %s\n", noun, i+1, issue.Rule, issue.Message)
+			}
+		default:
+			fmt.Fprintf(stdout, "Error in %s %d: %v\n", noun, i+1, err)
+		}
+		refused = true
+	}
+	if refused {
+		fmt.Fprintf(stdout, "Content filter and lint rules defined in %s; nothing was added\n", cfg.Path)
+		os.Exit(1)
+	}
+}
+
+// requireReason exits if the project requires a reason for accepting or
+// rejecting suggestions and none was given
+func requireReason(filename, reason, action string) {
+	cfg := loadPolicy(filename)
+	if cfg.RequireReason && strings.TrimSpace(reason) == "" {
+		fmt.Fprintf(stdout, "Error: --reason is required to %s suggestions (require_reason is set in %s)\n", action, cfg.Path)
+		os.Exit(1)
+	}
+}
+
+// recordReason adds the reason for a suggestion's decision as a reply by
+// actor; an empty reason records nothing
+func recordReason(filename string, doc *comment.DocumentWithComments, suggestion *comment.Comment, actor, reason string) {
+	if strings.TrimSpace(reason) == "" {
+		return
+	}
+	reply := comment.AddDecisionReply(suggestion, actor, reason)
+	doc.AttachToOpenReview(reply)
+	signComments(filename, false, reply)
+}
+
+// backupSidecar copies the sidecar before a destructive save when the project
+// config sets backups.keep, pruning older backups beyond that many
+func backupSidecar(filename string) {
+	cfg := loadPolicy(filename)
+	if _, err := comment.BackupSidecar(filename, cfg.Backups.Keep); err != nil {
+		fmt.Fprintf(stdout, "Error backing up sidecar: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// anchorComment anchors a new thread as --anchor asks, or else as the
+// project config does. An explicit --anchor heading fails for threads with
+// no heading above them; the config default leaves those on their line.
+func anchorComment(filename string, doc *comment.DocumentWithComments, c *comment.Comment, mode string) {
+	if mode != "" {
+		if err := comment.AnchorComment(c, doc, mode); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	_ = comment.AnchorComment(c, doc, loadPolicy(filename).Anchor)
+}
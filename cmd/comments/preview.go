@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// previewCommand prints the document as it would look after accepting a set
+// of suggestions. Nothing is written: the sidecar and markdown stay untouched.
+func previewCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	suggestionIDs := fs.String("suggestions", "", "Comma-separated suggestion IDs to apply (default: all pending suggestions)")
+	showDiff := fs.Bool("diff", false, "Print a unified diff against the current document instead of the full result")
+	contextLines := fs.Int("context", 3, "Lines of context around each change in --diff output")
+
+	fs.Parse(args)
+
+	// Read without validating so the preview never rewrites the sidecar
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	var suggestions []*comment.Comment
+	if *suggestionIDs == "" {
+		suggestions = comment.GetPendingSuggestions(doc.Threads)
+	} else {
+		for _, id := range parseIDList(*suggestionIDs) {
+			suggestion := doc.FindCommentByID(id)
+			if suggestion == nil {
+				fmt.Fprintf(stdout, "Error: Suggestion '%s' not found\n", id)
+				os.Exit(1)
+			}
+			if !suggestion.IsSuggestion {
+				fmt.Fprintf(stdout, "Error: Comment '%s' is not a suggestion\n", id)
+				os.Exit(1)
+			}
+			if !suggestion.IsPending() {
+				state := "rejected"
+				if suggestion.IsAccepted() {
+					state = "accepted"
+				}
+				fmt.Fprintf(stdout, "Error: Suggestion '%s' has already been %s\n", id, state)
+				os.Exit(1)
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Fprintln(stdout, "No pending suggestions to preview")
+		os.Exit(0)
+	}
+
+	original := doc.Content
+
+	// Apply in memory exactly as batch-accept would
+	applied, skipped, err := comment.AcceptSuggestionsInOrder(doc, suggestions)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Keep stdout limited to the document or diff so it can be piped
+	for _, skip := range skipped {
+		fmt.Fprintf(stderr, forStderr("⚠ Skipping %s: %v\n"), skip.Suggestion.ID, skip.Reason)
+	}
+	ids := make([]string, len(applied))
+	for i, s := range applied {
+		ids[i] = s.ID
+	}
+	fmt.Fprintf(stderr, "Previewing %d of %d suggestion(s): %s\n", len(applied), len(suggestions), strings.Join(ids, ", "))
+
+	if *showDiff {
+		fmt.Fprint(stdout, comment.UnifiedDiff(filename, filename+" (preview)", original, doc.Content, *contextLines))
+	} else {
+		fmt.Fprint(stdout, doc.Content)
+		if !strings.HasSuffix(doc.Content, "\n") {
+			fmt.Fprintln(stdout)
+		}
+	}
+
+	if len(skipped) > 0 {
+		os.Exit(1)
+	}
+}
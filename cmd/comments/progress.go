@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// progressBar draws a single-line progress indicator on stderr
+// It is a no-op unless stderr is a terminal, so piped and CI output stays clean.
+type progressBar struct {
+	label   string
+	total   int
+	done    int
+	enabled bool
+	drawn   bool
+}
+
+// newProgressBar returns a progress bar for total items
+func newProgressBar(label string, total int, disabled bool) *progressBar {
+	return &progressBar{
+		label:   label,
+		total:   total,
+		enabled: !disabled && total > 1 && isTerminal(os.Stderr),
+	}
+}
+
+// Step records one finished item and redraws the bar
+func (p *progressBar) Step() {
+	p.done++
+	if !p.enabled {
+		return
+	}
+
+	filled := progressBarWidth * p.done / p.total
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	fmt.Fprintf(stderr, "\r%s [%s] %d/%d", p.label, bar, p.done, p.total)
+	p.drawn = true
+}
+
+// Clear erases the bar so other output can be printed on a clean line
+func (p *progressBar) Clear() {
+	if !p.drawn {
+		return
+	}
+	fmt.Fprintf(stderr, "\r\033[K")
+	p.drawn = false
+}
+
+// isTerminal reports whether f is attached to a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// documentTargets expands a file or directory argument into markdown paths
+// Directories are walked for every document with a sidecar; the walk stops
+// early when ctx is cancelled.
+func documentTargets(ctx context.Context, target string) ([]string, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{target}, nil
+	}
+	return comment.WalkDocumentsContext(ctx, target)
+}
+
+// reportFileErrors prints aggregated per-file failures to stderr
+// Returns true if anything failed.
+func reportFileErrors(errs comment.FileErrors) bool {
+	if err := errs.Err(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return true
+	}
+	return false
+}
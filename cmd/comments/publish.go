@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// defaultAppendixTemplate renders the review summary added by
+// `publish --appendix`. It is skipped entirely when there is nothing to list.
+const defaultAppendixTemplate = `{{if or .OpenQuestions .Decisions}}
+---
+{{if .OpenQuestions}}
+## Open Questions
+
+{{range .OpenQuestions}}- {{.Body}}{{if .SectionPath}} ({{.SectionPath}}){{end}} — @{{.Author}}
+{{end}}{{end}}{{if .Decisions}}
+## Decisions
+
+{{range .Decisions}}- {{.Body}}{{if .SectionPath}} ({{.SectionPath}}){{end}}{{if .Outcome}}: {{.Outcome}}{{end}}
+{{end}}{{end}}{{end}}`
+
+// appendixData is what the appendix template sees
+type appendixData struct {
+	Document      string           // The document's file name
+	OpenQuestions []appendixThread // Unresolved [Q] threads
+	Decisions     []appendixThread // Resolved [B] threads
+}
+
+// appendixThread is a thread in the appendix: the fields of the JSON output
+// (commentOutput, with replies) plus a couple of conveniences
+type appendixThread struct {
+	commentOutput
+	Body    string // Text without its [Q]/[B] prefix
+	Outcome string // The last reply, usually how the thread was settled (empty if none)
+}
+
+// publishCommand prints the document as it would be shared: the markdown
+// without comments, optionally followed by a review summary of the threads
+// left after the project's redaction rules
+func publishCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	output := fs.String("output", "", "Output file (default: stdout)")
+	appendix := fs.Bool("appendix", false, "Append Open Questions (unresolved [Q]) and Decisions (resolved [B]) sections")
+	appendixTemplate := fs.String("appendix-template", "", "Go template file for the appendix (implies --appendix)")
+
+	fs.Parse(args)
+
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	content := doc.Content
+	if *appendix || *appendixTemplate != "" {
+		text := defaultAppendixTemplate
+		if *appendixTemplate != "" {
+			data, err := os.ReadFile(*appendixTemplate)
+			if err != nil {
+				fmt.Fprintf(stdout, "Error reading appendix template: %v\n", err)
+				os.Exit(1)
+			}
+			text = string(data)
+		}
+		tmpl, err := template.New("appendix").Parse(text)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: invalid appendix template: %v\n", err)
+			os.Exit(1)
+		}
+
+		var rendered bytes.Buffer
+		redaction, err := redactionFor(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := tmpl.Execute(&rendered, newAppendixData(filename, redaction.Apply(doc.Threads))); err != nil {
+			fmt.Fprintf(stdout, "Error rendering appendix: %v\n", err)
+			os.Exit(1)
+		}
+		if rendered.Len() > 0 {
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			content += rendered.String()
+		}
+	}
+
+	data := doc.Format.Encode(content)
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(stdout, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, "Published %s to %s\n", filename, *output)
+}
+
+// newAppendixData collects the threads the appendix lists, in document order
+func newAppendixData(filename string, threads []*comment.Comment) appendixData {
+	resolved := func(c *comment.Comment) bool { return c.Resolved }
+	open := comment.And(comment.ByType("Q"), comment.Not(resolved))
+	decided := comment.And(comment.ByType("B"), resolved)
+
+	threads = comment.CanonicalOrder(threads)
+	return appendixData{
+		Document:      filepath.Base(filename),
+		OpenQuestions: appendixThreads(open.Apply(threads), "Q"),
+		Decisions:     appendixThreads(decided.Apply(threads), "B"),
+	}
+}
+
+// appendixThreads builds the template data for threads of a comment type
+func appendixThreads(threads []*comment.Comment, commentType string) []appendixThread {
+	out := make([]appendixThread, 0, len(threads))
+	for _, thread := range threads {
+		item := appendixThread{commentOutput: newCommentOutput(thread, nil, false, 0, false)}
+		item.Replies = replyOutputs(thread.Replies, false)
+		item.Body = strings.TrimSpace(strings.TrimPrefix(thread.Text, "["+commentType+"]"))
+		if n := len(thread.Replies); n > 0 {
+			item.Outcome = thread.Replies[n-1].Text
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// queueCommand stages writes, lists or reviews them, flushes them into the
+// sidecar in one save, or discards them. add, reply, and suggest take the flags of the
+// commands of the same name.
+func queueCommand(action, filename string, args []string) {
+	switch action {
+	case "add":
+		addCommand(filename, append(args, "--queue"))
+		return
+	case "reply":
+		replyCommand(filename, append(args, "--queue"))
+		return
+	case "suggest":
+		suggestCommand(filename, append(args, "--queue"))
+		return
+	case "review":
+		queueReviewCommand(filename, args)
+		return
+	}
+
+	fs := flag.NewFlagSet("queue "+action, flag.ExitOnError)
+	author := fs.String("author", "", "Only operations by this author (default: all)")
+
+	fs.Parse(args)
+
+	queue, err := comment.LoadQueue(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading queue: %v\n", err)
+		os.Exit(1)
+	}
+	selected := comment.SelectQueued(queue.Ops, *author)
+
+	switch action {
+	case "list":
+		fmt.Fprintf(stdout, "Found %d queued operation(s) in %s\n\n", len(selected), comment.GetQueuePath(filename))
+		for i, op := range selected {
+			fmt.Fprintf(stdout, forStdout("[%d] %s • @%s • %s\n"), i+1, describeQueued(op), op.Comment.Author, op.QueuedAt.Format("2006-01-02 15:04"))
+			fmt.Fprintf(stdout, "    ID: %s\n", op.Comment.ID)
+			fmt.Fprintf(stdout, "    %s\n\n", op.Comment.Text)
+		}
+
+	case "flush":
+		if len(selected) == 0 {
+			fmt.Fprintln(stdout, "Nothing queued")
+			return
+		}
+
+		for _, op := range selected {
+			enforcePolicy(filename, queuedAction(op), op.Comment.Author)
+			screenComment(filename, op.Comment, op.Kind == comment.QueueThread)
+		}
+
+		doc, err := comment.LoadFromSidecar(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+			os.Exit(1)
+		}
+		changed, err := comment.ApplyQueued(doc, queue, selected)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			fmt.Fprintln(stdout, "Nothing was flushed")
+			os.Exit(1)
+		}
+
+		// Save the sidecar first so a failure can't lose the queue
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+		if err := comment.RemoveQueued(filename, queuedIDs(selected)); err != nil {
+			fmt.Fprintf(stdout, "Error removing flushed operations: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(stdout, forStdout("✓ Flushed %d queued operation(s) to %s\n"), len(selected), comment.GetSidecarPath(filename))
+		if changed {
+			fmt.Fprintln(stdout, forStdout("⚠ The document changed after these were queued; check that they still point at the right lines"))
+		}
+
+	case "discard":
+		if err := comment.RemoveQueued(filename, queuedIDs(selected)); err != nil {
+			fmt.Fprintf(stdout, "Error discarding queued operations: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, forStdout("✓ Discarded %d queued operation(s)\n"), len(selected))
+
+	default:
+		fmt.Fprintf(stdout, "Error: unknown queue action '%s'. Valid actions: add, reply, suggest, list, review, flush, discard\n", action)
+		os.Exit(1)
+	}
+}
+
+// enqueue stages op for filename and reports it, exiting on error
+func enqueue(filename string, op *comment.QueuedOp, content string) {
+	if err := comment.Enqueue(filename, op, content); err != nil {
+		fmt.Fprintf(stdout, "Error queueing: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, forStdout("✓ Queued %s by @%s\n"), describeQueued(op), op.Comment.Author)
+	fmt.Fprintf(stdout, "  ID: %s\n", op.Comment.ID)
+	fmt.Fprintf(stdout, "  Write it with: comments queue flush %s\n", filename)
+}
+
+// queuedAction is the permission writing op to the sidecar takes. Queueing
+// doesn't touch the sidecar, so it is checked when the op is written, for
+// the op's author.
+func queuedAction(op *comment.QueuedOp) string {
+	switch {
+	case op.Kind == comment.QueueReply:
+		return config.ActionReply
+	case op.Comment.IsSuggestion:
+		return config.ActionSuggest
+	}
+	return config.ActionAdd
+}
+
+// describeQueued says what a queued operation will write and where
+func describeQueued(op *comment.QueuedOp) string {
+	switch {
+	case op.Kind == comment.QueueReply:
+		return fmt.Sprintf("reply to %s", op.ThreadID)
+	case op.Comment.IsSuggestion:
+		return fmt.Sprintf("suggestion (%s)", comment.DescribeSuggestionLines(op.Comment))
+	default:
+		return fmt.Sprintf("comment (%s)", draftLocation(op.Comment))
+	}
+}
+
+// queuedIDs returns the comment IDs of queued operations
+func queuedIDs(ops []*comment.QueuedOp) []string {
+	ids := make([]string, len(ops))
+	for i, op := range ops {
+		ids[i] = op.Comment.ID
+	}
+	return ids
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+const queueReviewHelp = `  a  approve: write it to the sidecar
+  e  edit its text in $EDITOR
+  p  edit a suggestion's proposed text in $EDITOR
+  d  drop it (and any queued replies to it)
+  m  merge it into the last approved comment
+  s  skip: leave it queued
+  q  quit: leave the rest queued and write what was approved
+  ?  show this help`
+
+// queueReviewCommand walks through the queued operations one at a time, like
+// an interactive rebase, to approve, edit, drop, merge, or skip each one.
+// Approved operations are written to the sidecar in one save at the end;
+// skipped ones stay queued, with any edits.
+func queueReviewCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("queue review", flag.ExitOnError)
+	author := fs.String("author", "", "Only operations by this author (default: all)")
+
+	fs.Parse(args)
+
+	queue, err := comment.LoadQueue(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading queue: %v\n", err)
+		os.Exit(1)
+	}
+	selected := comment.SelectQueued(queue.Ops, *author)
+	if len(selected) == 0 {
+		fmt.Fprintln(stdout, "Nothing queued")
+		return
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	policy := loadPolicy(filename)
+	fmt.Fprintf(stdout, "Reviewing %d queued operation(s) for %s (? for help)\n", len(selected), filename)
+
+	in := bufio.NewReader(os.Stdin)
+	var approved []*comment.QueuedOp
+	var done []string
+	decided := map[string]string{} // Queued thread ID -> "approved", "dropped", or "skipped"
+	dropped, merged := 0, 0
+
+review:
+	for i, op := range selected {
+		// A reply to a queued thread follows its thread
+		if op.Kind == comment.QueueReply && queue.Thread(op.ThreadID) != nil {
+			switch decided[op.ThreadID] {
+			case "approved":
+			case "dropped":
+				done = append(done, op.Comment.ID)
+				dropped++
+				fmt.Fprintf(stdout, forStdout("\n✓ Dropped reply %s with its thread\n"), op.Comment.ID)
+				continue
+			default:
+				fmt.Fprintf(stdout, forStdout("\n• Left reply %s queued with its thread\n"), op.Comment.ID)
+				continue
+			}
+		}
+
+		fmt.Fprintln(stdout)
+		printQueuedForReview(i+1, len(selected), op, doc, queue)
+
+		for {
+			fmt.Fprint(stdout, "Approve, edit, drop, merge, skip, quit? [a,e,p,d,m,s,q,?] ")
+			answer, err := in.ReadString('\n')
+			if err == io.EOF && strings.TrimSpace(answer) == "" {
+				fmt.Fprintln(stdout)
+				break review
+			}
+			if err != nil && err != io.EOF {
+				fmt.Fprintf(stdout, "Error reading answer: %v\n", err)
+				os.Exit(1)
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "a":
+				if err := policy.Check(queuedAction(op), op.Comment.Author); err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				if err := policy.Screen(op.Comment, op.Kind == comment.QueueThread); err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				approved = append(approved, op)
+				done = append(done, op.Comment.ID)
+				decided[op.Comment.ID] = "approved"
+				continue review
+
+			case "e":
+				text, err := composeText(op.Comment.Text)
+				if err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				op.Comment.Text = text
+				fmt.Fprintf(stdout, forStdout("✓ Edited:\n    %s\n"), op.Comment.Text)
+
+			case "p":
+				if !op.Comment.IsSuggestion || op.Comment.IsMove {
+					fmt.Fprintln(stdout, "Only a suggestion's proposed text can be edited")
+					continue
+				}
+				text, err := editText(op.Comment.ProposedText, "proposed-*.md")
+				if err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				op.Comment.ProposedText = strings.TrimSuffix(text, "\n")
+				fmt.Fprintln(stdout, forStdout("✓ Edited the proposed text:"))
+				printChangedLines("    + ", op.Comment.ProposedText)
+
+			case "d":
+				done = append(done, op.Comment.ID)
+				decided[op.Comment.ID] = "dropped"
+				dropped++
+				continue review
+
+			case "m":
+				if len(approved) == 0 {
+					fmt.Fprintln(stdout, "Nothing approved yet to merge into")
+					continue
+				}
+				into := approved[len(approved)-1]
+				if err := comment.MergeQueued(into, op, queue.Ops); err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				done = append(done, op.Comment.ID)
+				merged++
+				fmt.Fprintf(stdout, forStdout("✓ Merged into %s\n"), into.Comment.ID)
+				continue review
+
+			case "s":
+				decided[op.Comment.ID] = "skipped"
+				continue review
+
+			case "q":
+				break review
+
+			default:
+				fmt.Fprintln(stdout, queueReviewHelp)
+			}
+		}
+	}
+
+	if len(approved) > 0 {
+		changed, err := comment.ApplyQueued(doc, queue, approved)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			fmt.Fprintln(stdout, "Nothing was written and the queue is unchanged")
+			os.Exit(1)
+		}
+		// Save the sidecar first so a failure can't lose the queue
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+		if changed {
+			fmt.Fprintln(stdout, forStdout("⚠ The document changed after these were queued; check that they still point at the right lines"))
+		}
+	}
+	if err := comment.SaveReviewedQueue(filename, queue.Ops, done); err != nil {
+		fmt.Fprintf(stdout, "Error updating queue: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("\n✓ Wrote %d, dropped %d, merged %d; %d left queued\n"),
+		len(approved), dropped, merged, len(selected)-len(done))
+}
+
+// printQueuedForReview shows a queued operation in full: what it writes and
+// where, its text, a suggestion's change, and the thread a reply answers
+func printQueuedForReview(n, total int, op *comment.QueuedOp, doc *comment.DocumentWithComments, queue *comment.Queue) {
+	c := op.Comment
+	fmt.Fprintf(stdout, forStdout("[%d/%d] %s • @%s • %s\n"), n, total, describeQueued(op), c.Author, op.QueuedAt.Format("2006-01-02 15:04"))
+	fmt.Fprintf(stdout, "    ID: %s\n", c.ID)
+
+	if op.Kind == comment.QueueReply {
+		thread := queue.Thread(op.ThreadID)
+		if thread == nil {
+			thread = doc.FindThreadByID(op.ThreadID)
+		}
+		if thread != nil {
+			fmt.Fprintf(stdout, "    In reply to @%s: %s\n", thread.Author, thread.Text)
+		}
+	}
+
+	fmt.Fprintf(stdout, "    %s\n", c.Text)
+	if c.IsSuggestion && !c.IsMove {
+		printChangedLines("    - ", c.OriginalText)
+		printChangedLines("    + ", c.ProposedText)
+	}
+}
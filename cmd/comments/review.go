@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// reviewCommand starts, submits, or lists review sessions
+// While a review is open, comments, replies, and suggestions by the reviewer
+// are grouped into it; submitting records a verdict like a PR review.
+func reviewCommand(action, filename string, args []string) {
+	fs := flag.NewFlagSet("review "+action, flag.ExitOnError)
+	reviewer := fs.String("reviewer", "", "Reviewer name (default: $COMMENTS_AUTHOR or $USER)")
+	verdict := fs.String("verdict", "", "Verdict for submit: "+strings.Join(comment.Verdicts, ", "))
+	text := fs.String("text", "", "Overall feedback for submit (supports @filename)")
+
+	fs.Parse(args)
+
+	who := currentActor(*reviewer)
+
+	switch action {
+	case "start":
+		enforcePolicy(filename, config.ActionReview, who)
+
+		doc, err := comment.LoadFromSidecar(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+			os.Exit(1)
+		}
+		if open := doc.OpenReview(who); open != nil {
+			fmt.Fprintf(stdout, "Error: @%s already has review %s in progress; submit it first\n", who, open.ID)
+			os.Exit(1)
+		}
+
+		review := comment.NewReview(who)
+		doc.Reviews = append(doc.Reviews, review)
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(stdout, forStdout("✓ Review %s started by @%s\n"), review.ID, who)
+		fmt.Fprintf(stdout, "  Comments by @%s are grouped into it until: comments review submit %s --verdict <%s>\n",
+			who, filename, strings.Join(comment.Verdicts, "|"))
+
+	case "submit":
+		if *verdict == "" {
+			fmt.Fprintln(stdout, "Error: --verdict flag is required")
+			fmt.Fprintf(stdout, "Usage: comments review submit <file> --verdict <%s> [--text \"summary\"]\n", strings.Join(comment.Verdicts, "|"))
+			os.Exit(1)
+		}
+		summary, err := resolveTextInput(*text)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		enforcePolicy(filename, config.ActionReview, who)
+
+		doc, err := comment.LoadFromSidecar(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+			os.Exit(1)
+		}
+		review := doc.OpenReview(who)
+		if review == nil {
+			fmt.Fprintf(stdout, "Error: @%s has no review in progress (start one with: comments review start %s)\n", who, filename)
+			os.Exit(1)
+		}
+		if err := doc.SubmitReview(review, *verdict, summary, time.Now().UTC()); err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := comment.SaveToSidecar(filename, doc); err != nil {
+			fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(stdout, forStdout("✓ Review %s submitted by @%s: %s (%d comment(s))\n"), review.ID, who, review.Verdict, len(doc.ReviewComments(review.ID)))
+
+		// Drafts aren't part of the review until they are shared
+		if drafts, err := comment.LoadDrafts(filename); err == nil {
+			if n := len(comment.SelectDrafts(drafts.Threads, who)); n > 0 {
+				fmt.Fprintf(stdout, forStdout("⚠ @%s still has %d unpublished draft(s); share them with: comments drafts publish %s --author %s\n"), who, n, filename, who)
+			}
+		}
+
+	case "list":
+		doc, err := comment.ReadSidecar(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(stdout, "Found %d review(s) in %s\n\n", len(doc.Reviews), filename)
+		for i, r := range doc.Reviews {
+			state := comment.VerdictInProgress
+			if !r.IsOpen() {
+				state = fmt.Sprintf("%s on %s", strings.ToUpper(r.Verdict), r.SubmittedAt.Format("2006-01-02 15:04"))
+			}
+			fmt.Fprintf(stdout, forStdout("[%d] @%s • started %s • %s\n"), i+1, r.Reviewer, r.StartedAt.Format("2006-01-02 15:04"), state)
+			fmt.Fprintf(stdout, "    Review ID: %s | Comments: %d\n", r.ID, len(doc.ReviewComments(r.ID)))
+			if r.Summary != "" {
+				fmt.Fprintf(stdout, "    %s\n", r.Summary)
+			}
+			fmt.Fprintln(stdout)
+		}
+
+	default:
+		fmt.Fprintf(stdout, "Error: unknown review action '%s'. Valid actions: start, submit, list\n", action)
+		os.Exit(1)
+	}
+}
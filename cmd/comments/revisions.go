@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// revisionsCommand lists the revisions recorded each time accepted suggestions
+// changed the document, newest first
+func revisionsCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	fs.Parse(args)
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(doc.Revisions) == 0 {
+		fmt.Fprintln(stdout, "No revisions recorded")
+		return
+	}
+
+	fmt.Fprintf(stdout, "Found %d revision(s) of %s\n\n", len(doc.Revisions), filename)
+	for i := len(doc.Revisions) - 1; i >= 0; i-- {
+		r := doc.Revisions[i]
+		current := ""
+		if i == len(doc.Revisions)-1 {
+			current = forStdout(" • current")
+		}
+		fmt.Fprintf(stdout, forStdout("[%d] %s • %d suggestion(s)%s\n"), r.Number, r.Timestamp.Format("2006-01-02 15:04:05"), len(r.Suggestions), current)
+		for _, id := range r.Suggestions {
+			s := doc.FindCommentByID(id)
+			if s == nil {
+				fmt.Fprintf(stdout, "    %s (deleted)\n", id)
+				continue
+			}
+			fmt.Fprintf(stdout, forStdout("    %s • %s • %s: %s\n"), id, comment.DescribeSuggestionLines(s), s.Author, s.Text)
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, "Revision %d is the document before the first recorded change\n", doc.Revisions[0].Number-1)
+}
+
+// revertCommand rolls the document back to an earlier revision, moving
+// comments back with the undone edits and reopening the suggestions they
+// applied
+func revertCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("revert", flag.ExitOnError)
+	to := fs.Int("to", -1, "Revision to roll back to, from 'comments history' (required)")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	if *to < 0 {
+		fmt.Fprintln(stdout, "Error: --to flag is required")
+		os.Exit(1)
+	}
+
+	// Reverting undoes accepts, so it needs the same permission
+	enforcePolicy(filename, config.ActionAccept, currentActor(*actor))
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	reopened, err := doc.RevertTo(*to)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	backupSidecar(filename)
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("✓ Reverted %s to revision %d\n"), filename, *to)
+	for _, s := range reopened {
+		fmt.Fprintf(stdout, "  Reopened suggestion %s (%s)\n", s.ID, comment.DescribeSuggestionLines(s))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// setupSidecarLocation removes the global --sidecar-dir flag from args,
+// wherever it appears before "--", and decides where sidecars are kept.
+// --sidecar-dir keeps every sidecar under that directory in a tree mirroring
+// the working directory (e.g. .comments/docs/guide.md.comments.json);
+// without it the "sidecars" settings of each document's project config apply.
+// Returns the remaining args.
+func setupSidecarLocation(args []string) []string {
+	dir := ""
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			rest = append(rest, args[i:]...)
+			i = len(args)
+		case arg == "--sidecar-dir" || arg == "-sidecar-dir":
+			if i+1 >= len(args) {
+				fmt.Fprintln(stdout, "Error: --sidecar-dir requires a directory")
+				os.Exit(1)
+			}
+			i++
+			dir = args[i]
+		case strings.HasPrefix(arg, "--sidecar-dir=") || strings.HasPrefix(arg, "-sidecar-dir="):
+			dir = arg[strings.Index(arg, "=")+1:]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if dir == "" {
+		// Each document's sidecars go where its project config says
+		if _, err := config.Load(configDir(rest)); err != nil {
+			// Commands that check the policy report this themselves
+			fmt.Fprintf(stderr, forStderr("⚠ Warning: %v\n"), err)
+		}
+		return rest
+	}
+
+	if _, err := comment.SetSidecarLocation(comment.SidecarLocation{Dir: dir}); err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return rest
+}
+
+// configDir returns where to look for the project config: the command's
+// file or directory argument if it has one (after an action such as
+// "drafts list"), otherwise the working directory
+func configDir(args []string) string {
+	for _, arg := range args[min(2, len(args)):min(4, len(args))] {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		info, err := os.Stat(arg)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			return arg
+		}
+		return filepath.Dir(arg)
+	}
+	return "."
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// statsOutput is the JSON shape of `comments stats`
+type statsOutput struct {
+	Total *comment.Stats            `json:"total"`
+	Files map[string]*comment.Stats `json:"files,omitempty"`
+}
+
+// statsCommand summarizes comment activity for a file or every document under a directory
+func statsCommand(target string, args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json (csv, json with --history)")
+	history := fs.Bool("history", false, "Walk the git history of sidecars: open and resolved threads after each commit")
+	perFile := fs.Bool("per-file", false, "Include a breakdown for each file")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *history {
+		formatSet := false
+		fs.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+		if !formatSet {
+			*format = "csv"
+		}
+		if *format != "csv" && *format != "json" {
+			fmt.Fprintf(stdout, "Error: Unknown format '%s' for --history. Valid formats: csv, json\n", *format)
+			os.Exit(1)
+		}
+	} else if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "no stats were printed")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	if *history {
+		historyCommand(ctx, docs, *format, *workers, *noProgress)
+		return
+	}
+
+	total := comment.NewStats()
+	files := map[string]*comment.Stats{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Counting", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) (*comment.Stats, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := config.LoadForDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		stats := comment.ComputeStats(doc)
+		comment.ComputeSectionsForComments(doc)
+		stats.AddAwaitingOwners(comment.AwaitingOwnerReview(doc, policy.SectionOwnersOf))
+		stats.AddQuestions(doc, policy.Answerer())
+		return stats, nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		total.Merge(result.Value)
+		files[result.Path] = result.Value
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "no stats were printed")
+
+	if *format == "json" {
+		out := statsOutput{Total: total}
+		if *perFile {
+			out.Files = files
+		}
+		jsonBytes, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		printStats(total)
+		if *perFile {
+			paths := make([]string, 0, len(files))
+			for path := range files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			fmt.Fprintln(stdout, "\nBy file:")
+			for _, path := range paths {
+				s := files[path]
+				fmt.Fprintf(stdout, "  %s: %d thread(s), %d open, %d resolved, %d pending suggestion(s)\n",
+					path, s.Threads, s.Open, s.Resolved, s.Pending)
+			}
+		}
+	}
+
+	if reportFileErrors(errs) {
+		os.Exit(1)
+	}
+}
+
+// printStats prints aggregated stats as text
+func printStats(s *comment.Stats) {
+	fmt.Fprintf(stdout, "Files:    %d\n", s.Files)
+	fmt.Fprintf(stdout, "Threads:  %d (%d open, %d resolved)\n", s.Threads, s.Open, s.Resolved)
+	fmt.Fprintf(stdout, "Replies:  %d\n", s.Replies)
+	fmt.Fprintf(stdout, "Suggestions: %d pending, %d accepted, %d rejected", s.Suggestions.Pending, s.Suggestions.Accepted, s.Suggestions.Rejected)
+	if s.Suggestions.Accepted+s.Suggestions.Rejected > 0 {
+		fmt.Fprintf(stdout, " (%.0f%% accepted)", s.Suggestions.AcceptanceRate()*100)
+	}
+	fmt.Fprintln(stdout)
+	if s.Reviews > 0 {
+		fmt.Fprintf(stdout, "Reviews:  %d\n", s.Reviews)
+	}
+
+	printCounts("By status", s.ByStatus)
+	printCounts("By type", s.ByType)
+	printCounts("By author", s.ByAuthor)
+	printCounts("Review verdicts", s.Verdicts)
+	printOutcomes(s.Outcomes)
+	printCounts("Awaiting section owners", s.AwaitingOwners)
+	printCounts("Questions", s.Questions)
+}
+
+// printOutcomes prints how each author's suggestions fared, most suggestions first
+func printOutcomes(outcomes map[string]*comment.SuggestionOutcomes) {
+	if len(outcomes) == 0 {
+		return
+	}
+
+	total := func(o *comment.SuggestionOutcomes) int { return o.Pending + o.Accepted + o.Rejected }
+	authors := make([]string, 0, len(outcomes))
+	for author := range outcomes {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if ti, tj := total(outcomes[authors[i]]), total(outcomes[authors[j]]); ti != tj {
+			return ti > tj
+		}
+		return authors[i] < authors[j]
+	})
+
+	fmt.Fprintf(stdout, "\nSuggestions by author:\n")
+	for _, author := range authors {
+		o := outcomes[author]
+		rate := "no decisions yet"
+		if o.Accepted+o.Rejected > 0 {
+			rate = fmt.Sprintf("%.0f%% accepted", o.AcceptanceRate()*100)
+		}
+		fmt.Fprintf(stdout, "  %-12s %d accepted, %d rejected, %d pending (%s)\n", author, o.Accepted, o.Rejected, o.Pending, rate)
+	}
+}
+
+// printCounts prints a labelled map of counts, largest first
+func printCounts(label string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Fprintf(stdout, "\n%s:\n", label)
+	for _, k := range keys {
+		fmt.Fprintf(stdout, "  %-12s %d\n", k, counts[k])
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// ownerLoad is the review work waiting on one section owner in
+// `status-report` JSON output
+type ownerLoad struct {
+	Owner       string           `json:"owner"`
+	Waiting     int              `json:"waiting"`
+	Changed     int              `json:"in_changed_sections"` // Of Waiting, those `check` fails on
+	Oldest      time.Time        `json:"oldest"`
+	Suggestions []ownerLoadEntry `json:"suggestions"`
+}
+
+// ownerLoadEntry is a suggestion waiting on an owner
+type ownerLoadEntry struct {
+	File       string    `json:"file"`
+	Suggestion string    `json:"suggestion"`
+	Section    string    `json:"section"`
+	Lines      string    `json:"lines"`
+	Author     string    `json:"author"`
+	Timestamp  time.Time `json:"timestamp"`
+	Changed    bool      `json:"changed"` // The section changed since the last submitted review
+}
+
+// ownerReview is what status-report reads from one document: the
+// suggestions awaiting owners, and which of those are in changed sections
+type ownerReview struct {
+	awaiting []comment.AwaitingOwner
+	changed  map[*comment.Comment]bool
+}
+
+// statusReportCommand shows each section owner's outstanding load: the
+// pending suggestions in their sections that they haven't reviewed yet
+func statusReportCommand(target string, args []string) {
+	fs := flag.NewFlagSet("status-report", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json")
+	owner := fs.String("owner", "", "Only show this owner's load")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stdout, "Error: Unknown format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "no report was printed")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	loads := map[string]*ownerLoad{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Reading", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) (ownerReview, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return ownerReview{}, err
+		}
+		policy, err := config.LoadForDocument(path)
+		if err != nil {
+			return ownerReview{}, err
+		}
+		comment.ComputeSectionsForComments(doc)
+		review := ownerReview{
+			awaiting: comment.AwaitingOwnerReview(doc, policy.SectionOwnersOf),
+			changed:  map[*comment.Comment]bool{},
+		}
+		for _, a := range comment.AwaitingInChangedSections(doc, review.awaiting) {
+			review.changed[a.Suggestion] = true
+		}
+		return review, nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		for _, a := range result.Value.awaiting {
+			s := a.Suggestion
+			entry := ownerLoadEntry{
+				File:       result.Path,
+				Suggestion: s.ID,
+				Section:    s.SectionPath,
+				Lines:      comment.DescribeSuggestionLines(s),
+				Author:     s.Author,
+				Timestamp:  s.Timestamp,
+				Changed:    result.Value.changed[s],
+			}
+			for _, o := range a.Owners {
+				if *owner != "" && !strings.EqualFold(o, *owner) {
+					continue
+				}
+				load, ok := loads[o]
+				if !ok {
+					load = &ownerLoad{Owner: o}
+					loads[o] = load
+				}
+				load.add(entry)
+			}
+		}
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "no report was printed")
+
+	report := make([]*ownerLoad, 0, len(loads))
+	for _, load := range loads {
+		sort.Slice(load.Suggestions, func(i, j int) bool {
+			return load.Suggestions[i].Timestamp.Before(load.Suggestions[j].Timestamp)
+		})
+		report = append(report, load)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Waiting != report[j].Waiting {
+			return report[i].Waiting > report[j].Waiting
+		}
+		return report[i].Owner < report[j].Owner
+	})
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else if len(report) == 0 {
+		fmt.Fprintf(stdout, forStdout("✓ No suggestions are waiting on section owners in %s\n"), target)
+	} else {
+		fmt.Fprintf(stdout, "Outstanding section owner reviews in %s\n", target)
+		for _, load := range report {
+			fmt.Fprintf(stdout, "\n@%s: %d waiting (%d in changed sections), oldest %s\n",
+				load.Owner, load.Waiting, load.Changed, comment.FormatTimestamp(load.Oldest, comment.TimeRelative))
+			for _, e := range load.Suggestions {
+				changed := ""
+				if e.Changed {
+					changed = " (changed)"
+				}
+				fmt.Fprintf(stdout, forStdout("    %s • %s • %s (%s) • @%s • %s%s\n"),
+					e.File, e.Suggestion, e.Section, e.Lines, e.Author, comment.FormatTimestamp(e.Timestamp, comment.TimeRelative), changed)
+			}
+		}
+	}
+
+	if reportFileErrors(errs) {
+		os.Exit(1)
+	}
+}
+
+// add counts a suggestion against the owner
+func (l *ownerLoad) add(e ownerLoadEntry) {
+	l.Waiting++
+	if e.Changed {
+		l.Changed++
+	}
+	if l.Oldest.IsZero() || e.Timestamp.Before(l.Oldest) {
+		l.Oldest = e.Timestamp
+	}
+	l.Suggestions = append(l.Suggestions, e)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// storageCommand shows where a document's threads are stored, or moves them
+// between a sidecar and a block embedded in the markdown file
+func storageCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("storage", flag.ExitOnError)
+	mode := fs.String("mode", "", "Convert to this storage mode: sidecar, embedded")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	current, err := comment.StorageMode(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *mode == "" {
+		where := comment.GetSidecarPath(filename)
+		if current == comment.StorageEmbedded {
+			where = "a comments block at the end of " + filename
+		}
+		fmt.Fprintf(stdout, "%s: %s (%s)\n", filename, current, where)
+		return
+	}
+	if *mode != comment.StorageSidecar && *mode != comment.StorageEmbedded {
+		fmt.Fprintf(stdout, "Error: Unknown mode '%s'. Valid modes: sidecar, embedded\n", *mode)
+		os.Exit(1)
+	}
+	if *mode == current {
+		fmt.Fprintf(stdout, "%s already uses %s storage\n", filename, current)
+		return
+	}
+
+	// Converting rewrites the markdown file and removes or creates the sidecar
+	enforcePolicy(filename, config.ActionCleanup, currentActor(*actor))
+
+	if err := comment.ConvertStorage(filename, *mode); err != nil {
+		fmt.Fprintf(stdout, "Error converting %s: %v\n", filename, err)
+		os.Exit(1)
+	}
+	if *mode == comment.StorageEmbedded {
+		fmt.Fprintf(stdout, forStdout("✓ Embedded the comments of %s in the document; the sidecar was removed\n"), filename)
+	} else {
+		fmt.Fprintf(stdout, forStdout("✓ Moved the comments of %s to %s\n"), filename, comment.GetSidecarPath(filename))
+	}
+}
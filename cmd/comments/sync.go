@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// syncReason is the decision reply sync leaves on each suggestion it accepts
+const syncReason = "Already in the document; marked accepted by sync"
+
+// syncCommand marks pending suggestions accepted when the document already
+// contains their proposed text, e.g. because the author made the edit by hand
+func syncCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Report the suggestions that would be marked accepted without saving")
+	actor := fs.String("as", "", "Name of the person or agent making the change (default: $COMMENTS_AUTHOR or $USER)")
+
+	fs.Parse(args)
+
+	// Check project policy before making changes
+	if !*dryRun {
+		enforcePolicy(filename, config.ActionAccept, currentActor(*actor))
+	}
+
+	// Load document
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	mergePrivateNotes(filename, doc, *actor)
+
+	applied := doc.SuggestionsAppliedByHand()
+	if len(applied) == 0 {
+		fmt.Fprintln(stdout, "No pending suggestions are already in the document")
+		return
+	}
+
+	verb := "Marked"
+	if *dryRun {
+		verb = "Would mark"
+	}
+	fmt.Fprintf(stdout, "%s %d suggestion(s) accepted whose proposed text is already in %s:\n", verb, len(applied), filename)
+	for _, s := range applied {
+		fmt.Fprintf(stdout, forStdout("  %s • @%s • %s\n"), s.ID, s.Author, comment.DescribeSuggestionLines(s))
+	}
+	if *dryRun {
+		fmt.Fprintln(stdout, "Dry run: nothing was saved")
+		return
+	}
+
+	// The edits are already in the document, so nothing is applied; each
+	// suggestion is only marked, with a reply saying why
+	for _, s := range applied {
+		if err := comment.AcceptSuggestion(doc.Threads, s.ID); err != nil {
+			fmt.Fprintf(stdout, "Error marking suggestion as accepted: %v\n", err)
+			os.Exit(1)
+		}
+		recordReason(filename, doc, s, currentActor(*actor), syncReason)
+	}
+	if err := comment.SaveToSidecar(filename, doc); err != nil {
+		fmt.Fprintf(stdout, "Error saving changes: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(stdout, forStdout("✓ %d suggestion(s) accepted\n"), len(applied))
+}
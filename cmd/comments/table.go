@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// tableColumn is a column of `list --format table`
+type tableColumn struct {
+	header string
+
+	// max caps the column's width unless --wide is given; 0 means the
+	// column takes whatever width the terminal has left (the preview)
+	max int
+
+	value func(thread *comment.Comment, cell tableCell) string
+}
+
+// tableCell is what a column knows about the row it fills in besides the
+// thread itself
+type tableCell struct {
+	archived   bool   // Loaded from a cleanup archive
+	timeFormat string // See comment.FormatTimestamp
+}
+
+// tableColumns are the columns --columns can choose from, by name
+var tableColumns = map[string]tableColumn{
+	"id": {header: "ID", max: 24, value: func(c *comment.Comment, _ tableCell) string {
+		return c.ID
+	}},
+	"line": {header: "Line", max: 9, value: func(c *comment.Comment, _ tableCell) string {
+		switch {
+		case c.IsDocumentLevel():
+			return "doc"
+		case c.IsRange():
+			return fmt.Sprintf("%d-%d", c.Line, c.EndLine)
+		}
+		return strconv.Itoa(c.Line)
+	}},
+	"author": {header: "Author", max: 12, value: func(c *comment.Comment, _ tableCell) string {
+		return c.Author
+	}},
+	"type": {header: "Type", max: 8, value: func(c *comment.Comment, cell tableCell) string {
+		text := c.Type
+		if text == "" {
+			text = "-"
+		}
+		if c.Resolved {
+			text += forStdout(" ✓")
+		}
+		if cell.archived {
+			text += " (A)"
+		}
+		return text
+	}},
+	"priority": {header: "Priority", max: 8, value: func(c *comment.Comment, _ tableCell) string {
+		return c.GetPriority()
+	}},
+	"status": {header: "Status", max: 10, value: func(c *comment.Comment, cell tableCell) string {
+		if cell.archived {
+			return "archived"
+		}
+		return c.GetStatus()
+	}},
+	"replies": {header: "Replies", max: 7, value: func(c *comment.Comment, _ tableCell) string {
+		return strconv.Itoa(c.CountReplies())
+	}},
+	"section": {header: "Section", max: 30, value: func(c *comment.Comment, _ tableCell) string {
+		return c.SectionPath
+	}},
+	"date": {header: "Date", max: 25, value: func(c *comment.Comment, cell tableCell) string {
+		return comment.FormatTimestamp(c.Timestamp, cell.timeFormat)
+	}},
+	"preview": {header: "Preview", value: func(c *comment.Comment, _ tableCell) string {
+		return strings.Join(strings.Fields(c.Text), " ")
+	}},
+}
+
+// defaultTableColumns are the columns shown without --columns
+const defaultTableColumns = "line,author,type,replies,preview"
+
+// Width of the preview column when the terminal width is unknown (output is
+// piped) and the narrowest it gets on a small terminal
+const (
+	defaultPreviewWidth = 40
+	minPreviewWidth     = 10
+)
+
+// parseTableColumns parses a comma-separated --columns list
+func parseTableColumns(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := tableColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column '%s'. Valid columns: id, line, author, type, priority, status, replies, section, date, preview", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--columns needs at least one column")
+	}
+	return names, nil
+}
+
+// terminalWidth returns the width of the terminal stdout is attached to, or
+// 0 when it isn't one. COLUMNS overrides it.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	width, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// outputTable outputs comment threads in table format (v2.0)
+// archivedIDs marks threads loaded from cleanup archives, and timeFormat is
+// how the date column shows timestamps. Columns are sized to their contents
+// up to a cap, and the preview fills the rest of the terminal's width; wide
+// lifts the caps and never truncates.
+func outputTable(threads []*comment.Comment, archivedIDs map[string]bool, names []string, wide bool, timeFormat string) {
+	columns := make([]tableColumn, len(names))
+	for i, name := range names {
+		columns[i] = tableColumns[name]
+	}
+
+	rows := make([][]string, len(threads))
+	for i, thread := range threads {
+		rows[i] = make([]string, len(columns))
+		for j, col := range columns {
+			rows[i][j] = col.value(thread, tableCell{archived: archivedIDs[thread.ID], timeFormat: timeFormat})
+		}
+	}
+
+	// Size each column to its widest cell, capped unless wide
+	widths := make([]int, len(columns))
+	flexible := 0
+	for j, col := range columns {
+		widths[j] = ansi.StringWidth(col.header)
+		for _, row := range rows {
+			widths[j] = max(widths[j], ansi.StringWidth(row[j]))
+		}
+		switch {
+		case wide:
+		case col.max > 0:
+			widths[j] = min(widths[j], max(col.max, ansi.StringWidth(col.header)))
+		default:
+			flexible++
+		}
+	}
+
+	// Share what the terminal has left among the flexible columns. Each
+	// column takes 3 characters of padding and border, plus one closing border.
+	if flexible > 0 {
+		share := defaultPreviewWidth
+		if width := terminalWidth(); width > 0 {
+			used := 1
+			for j, col := range columns {
+				used += 3
+				if col.max > 0 {
+					used += widths[j]
+				}
+			}
+			share = max((width-used)/flexible, minPreviewWidth)
+		}
+		for j, col := range columns {
+			if col.max == 0 {
+				widths[j] = min(widths[j], share)
+			}
+		}
+	}
+
+	rule := func(left, middle, right string) string {
+		parts := make([]string, len(widths))
+		for j, w := range widths {
+			parts[j] = strings.Repeat(forStdout("─"), w+2)
+		}
+		return left + strings.Join(parts, middle) + right
+	}
+	row := func(cells []string) string {
+		var b strings.Builder
+		b.WriteString(forStdout("│"))
+		for j, cell := range cells {
+			cell = ansi.Truncate(cell, widths[j], ellipsis())
+			b.WriteString(" " + cell + strings.Repeat(" ", widths[j]-ansi.StringWidth(cell)) + forStdout(" │"))
+		}
+		return b.String()
+	}
+
+	headers := make([]string, len(columns))
+	for j, col := range columns {
+		headers[j] = col.header
+	}
+
+	fmt.Fprintln(stdout, rule(forStdout("┌"), forStdout("┬"), forStdout("┐")))
+	fmt.Fprintln(stdout, row(headers))
+	fmt.Fprintln(stdout, rule(forStdout("├"), forStdout("┼"), forStdout("┤")))
+	for _, cells := range rows {
+		fmt.Fprintln(stdout, row(cells))
+	}
+	fmt.Fprintln(stdout, rule(forStdout("└"), forStdout("┴"), forStdout("┘")))
+	fmt.Fprintf(stdout, "\nTotal: %d comment thread(s)\n", len(threads))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/markdown"
+)
+
+// tailedDocument tracks the last observed state of one document's threads
+type tailedDocument struct {
+	watched string // The file holding its threads: its sidecar, or the document when embedded
+	modTime time.Time
+	size    int64
+	threads []*comment.Comment
+}
+
+// tailCommand watches a markdown file or directory and prints comment events as NDJSON
+func tailCommand(target string, args []string) {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	interval := fs.Duration("interval", time.Second, "How often to check for changes (e.g., 500ms, 2s)")
+	replay := fs.Bool("replay", false, "Emit events for existing comments before watching")
+
+	fs.Parse(args)
+
+	if *interval <= 0 {
+		fmt.Fprintln(stdout, "Error: --interval must be greater than zero")
+		os.Exit(1)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	emit := func(events []comment.Event) {
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				fmt.Fprintf(stderr, "Error writing event: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Take the initial snapshot; existing comments only produce events with --replay
+	state := make(map[string]*tailedDocument)
+	for _, mdPath := range tailDocuments(target, info.IsDir()) {
+		snapshot, err := readTailedDocument(mdPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+			continue
+		}
+		state[mdPath] = snapshot
+		if *replay {
+			emit(comment.DiffThreads(mdPath, nil, snapshot.threads, time.Now().UTC()))
+		}
+	}
+
+	for {
+		time.Sleep(*interval)
+
+		for _, mdPath := range tailDocuments(target, info.IsDir()) {
+			watched := tailedFile(mdPath)
+			stat, err := os.Stat(watched)
+			if err != nil {
+				continue
+			}
+
+			previous, known := state[mdPath]
+			if known && watched == previous.watched && stat.ModTime().Equal(previous.modTime) && stat.Size() == previous.size {
+				continue
+			}
+
+			snapshot, err := readTailedDocument(mdPath)
+			if err != nil {
+				// Likely caught mid-write; retry on the next tick
+				continue
+			}
+
+			var before []*comment.Comment
+			if known {
+				before = previous.threads
+			}
+			emit(comment.DiffThreads(mdPath, before, snapshot.threads, time.Now().UTC()))
+			state[mdPath] = snapshot
+		}
+	}
+}
+
+// tailDocuments returns the documents to watch for a file or directory
+// target: those with sidecars, and the rest of its markdown files, which
+// may embed their threads
+func tailDocuments(target string, isDir bool) []string {
+	if !isDir {
+		return []string{target}
+	}
+
+	sidecars, err := comment.ListSidecars(target)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+		return nil
+	}
+	seen := map[string]bool{}
+	docs := []string{}
+	for _, sidecarPath := range sidecars {
+		mdPath := comment.MarkdownForSidecar(target, sidecarPath)
+		seen[mdPath] = true
+		docs = append(docs, mdPath)
+	}
+
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+		return nil
+	}
+	for _, entry := range entries {
+		mdPath := filepath.Join(target, entry.Name())
+		if !entry.IsDir() && markdown.IsDocument(entry.Name()) && !seen[mdPath] {
+			docs = append(docs, mdPath)
+		}
+	}
+	sort.Strings(docs)
+	return docs
+}
+
+// tailedFile returns the file whose changes mean a document's threads may
+// have changed: its sidecar, or else the document, which may embed them
+func tailedFile(mdPath string) string {
+	sidecarPath := comment.GetSidecarPath(mdPath)
+	if _, err := os.Stat(sidecarPath); err == nil {
+		return sidecarPath
+	}
+	return mdPath
+}
+
+// readTailedDocument reads a document's threads without validating or
+// rewriting anything
+func readTailedDocument(mdPath string) (*tailedDocument, error) {
+	watched := tailedFile(mdPath)
+	stat, err := os.Stat(watched)
+	if os.IsNotExist(err) {
+		// No comments yet; treat as an empty snapshot
+		return &tailedDocument{watched: watched}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.ReadSidecar(mdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tailedDocument{
+		watched: watched,
+		modTime: stat.ModTime(),
+		size:    stat.Size(),
+		threads: doc.Threads,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateEscapes turns the escapes people type in shell quotes into the
+// characters they mean
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+// parseOutputTemplate parses a --template. The template sees the fields of
+// the JSON output (commentOutput), e.g. {{.ID}}, {{.Line}}, {{.Text}}, and
+// runs once per thread; a newline is added unless it ends with one.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	text = templateEscapes.Replace(text)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// outputTemplate writes each thread through the template. Nothing is
+// written if the template fails on any of them.
+func outputTemplate(tmpl *template.Template, items []commentOutput) error {
+	var out bytes.Buffer
+	for _, item := range items {
+		if err := tmpl.Execute(&out, item); err != nil {
+			return err
+		}
+	}
+	_, err := out.WriteTo(os.Stdout)
+	return err
+}
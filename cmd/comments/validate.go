@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// validateResult is the outcome of validating one document
+type validateResult struct {
+	Issues   []comment.ValidationIssue
+	Orphaned int
+}
+
+// validateCommand checks sidecars against their markdown without modifying anything
+// Reports comments that would be orphaned on the next load and exits 1 if
+// any file is unreadable or has orphan candidates.
+func validateCommand(target string, args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	quiet := fs.Bool("quiet", false, "Only print files with problems")
+	workers := fs.Int("workers", 0, "Number of files to read concurrently (default: number of CPUs)")
+	noProgress := fs.Bool("no-progress", false, "Don't show a progress bar on stderr")
+
+	fs.Parse(args)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	docs, err := documentTargets(ctx, target)
+	exitIfInterrupted(ctx, "not every file was validated")
+	if err != nil {
+		fmt.Fprintf(stdout, "Error scanning %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	results := map[string]validateResult{}
+	var errs comment.FileErrors
+	progress := newProgressBar("Validating", len(docs), *noProgress)
+
+	for result := range comment.RunPipelineContext(ctx, docs, *workers, func(path string) (validateResult, error) {
+		doc, err := comment.ReadSidecar(path)
+		if err != nil {
+			return validateResult{}, err
+		}
+		// Validation only touches the in-memory copy; nothing is saved
+		orphaned, issues := comment.ValidateAndUpdateCommentStatus(doc)
+		return validateResult{Issues: issues, Orphaned: orphaned}, nil
+	}) {
+		progress.Step()
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		results[result.Path] = result.Value
+	}
+	progress.Clear()
+	exitIfInterrupted(ctx, "not every file was validated")
+
+	paths := make([]string, 0, len(results))
+	for path := range results {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	problemFiles := 0
+	for _, path := range paths {
+		r := results[path]
+		if r.Orphaned == 0 {
+			if !*quiet {
+				fmt.Fprintf(stdout, forStdout("✓ %s\n"), path)
+			}
+			continue
+		}
+
+		problemFiles++
+		fmt.Fprintf(stdout, forStdout("⚠ %s: %d comment(s) would be orphaned\n"), path, r.Orphaned)
+		for _, issue := range r.Issues {
+			if issue.CommentID == "" || issue.Severity != "warning" {
+				continue
+			}
+			fmt.Fprintf(stdout, "    %s: %s\n", issue.CommentID, issue.Message)
+		}
+	}
+
+	fmt.Fprintf(stdout, "\nValidated %d file(s): %d with problems, %d unreadable\n", len(docs), problemFiles, len(errs))
+
+	failed := reportFileErrors(errs)
+	if failed || problemFiles > 0 {
+		os.Exit(1)
+	}
+}
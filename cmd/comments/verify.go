@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// signComments signs newly created comments when requested with --sign or
+// when the project config enables signing
+func signComments(filename string, sign bool, comments ...*comment.Comment) {
+	cfg := loadPolicy(filename)
+	if !sign && !cfg.SignComments {
+		return
+	}
+
+	path, err := config.DefaultKeyPath()
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	key, err := config.LoadSigningKey(path)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, c := range comments {
+		comment.SignComment(c, key)
+	}
+}
+
+// keygenCommand creates the local signing key used by --sign
+func keygenCommand(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	force := fs.Bool("force", false, "Overwrite an existing key")
+
+	fs.Parse(args)
+
+	path, err := config.DefaultKeyPath()
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(path); err == nil && !*force {
+		key, err := config.LoadSigningKey(path)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(stdout, "Signing key already exists at %s (use --force to replace it)\n", path)
+		fmt.Fprintf(stdout, "Public key: %s\n", config.PublicKeyString(key))
+		return
+	}
+
+	key, err := config.GenerateSigningKey(path)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(stdout, forStdout("✓ Signing key written to %s\n"), path)
+	fmt.Fprintf(stdout, "Public key: %s\n", config.PublicKeyString(key))
+	fmt.Fprintf(stdout, "\nAdd it to trusted_keys in %s to attribute your comments:\n", config.FileName)
+	fmt.Fprintf(stdout, "  \"trusted_keys\": {\"<your name>\": [\"%s\"]}\n", config.PublicKeyString(key))
+}
+
+// verifyCommand checks comment signatures and reports tampered or unattributed entries
+func verifyCommand(filename string, args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	requireSigned := fs.Bool("require-signed", false, "Treat unsigned comments as failures (default: true when sign_comments is enabled)")
+	format := fs.String("format", "text", "Output format: text, json")
+
+	fs.Parse(args)
+
+	cfg := loadPolicy(filename)
+	strict := *requireSigned || cfg.SignComments
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+
+	reports := comment.VerifySignatures(doc.Threads, cfg.TrustedKeys)
+
+	failed := 0
+	counts := make(map[string]int)
+	for _, report := range reports {
+		counts[report.Status]++
+		if isVerifyFailure(report.Status, strict) {
+			failed++
+		}
+	}
+
+	switch *format {
+	case "json":
+		type verifyOutput struct {
+			ID     string `json:"id"`
+			Author string `json:"author"`
+			Line   int    `json:"line"`
+			Status string `json:"status"`
+			Detail string `json:"detail,omitempty"`
+		}
+		output := []verifyOutput{}
+		for _, report := range reports {
+			output = append(output, verifyOutput{
+				ID:     report.Comment.ID,
+				Author: report.Comment.Author,
+				Line:   report.Comment.Line,
+				Status: report.Status,
+				Detail: report.Detail,
+			})
+		}
+		jsonBytes, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stdout, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+
+	case "text":
+		for _, report := range reports {
+			if !isVerifyFailure(report.Status, strict) {
+				continue
+			}
+			fmt.Fprintf(stdout, forStdout("✗ %s (Line %d) @%s: %s - %s\n"), report.Comment.ID, report.Comment.Line, report.Comment.Author, report.Status, report.Detail)
+		}
+		fmt.Fprintf(stdout, "%d comment(s): %d valid, %d invalid, %d untrusted, %d unsigned\n",
+			len(reports), counts[comment.SignatureValid], counts[comment.SignatureInvalid],
+			counts[comment.SignatureUntrusted], counts[comment.SignatureUnsigned])
+		if failed == 0 {
+			fmt.Fprintln(stdout, forStdout("✓ All signatures verified"))
+		}
+
+	default:
+		fmt.Fprintf(stdout, "Error: invalid format '%s'. Valid formats: text, json\n", *format)
+		os.Exit(1)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// isVerifyFailure reports whether a signature status should fail verification
+func isVerifyFailure(status string, strict bool) bool {
+	switch status {
+	case comment.SignatureInvalid, comment.SignatureUntrusted:
+		return true
+	case comment.SignatureUnsigned:
+		return strict
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+// Package chat bridges chat slash commands and email replies to document
+// reviews, so people who don't use the CLI can follow and answer review
+// threads from Slack, Microsoft Teams, or their mail client. Commands run
+// through a comments.Service as the chat user (or sender's address), under
+// the same project policy as the CLI.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/comments"
+)
+
+// maxListed caps the threads listed in one chat message
+const maxListed = 20
+
+// Usage is the help text answered to "help" and to unknown commands
+const Usage = `Commands (<file> is relative to the bridge's directory):
+  <file> blockers                  List open [B] threads
+  <file> questions                 List open [Q] threads
+  <file> list                      List every open thread
+  <file> reply <thread> <text>     Reply to a thread
+  <file> resolve <thread>          Resolve a thread
+  help                             Show this help`
+
+// Bridge runs chat commands against the documents under a directory
+type Bridge struct {
+	Service *comments.Service
+	Root    string // Documents are named relative to Root; paths outside it are refused
+
+	SlackSecret string // Slack app signing secret; empty disables the Slack endpoint
+	TeamsSecret string // Teams outgoing webhook security token (base64); empty disables the Teams endpoint
+	EmailToken  string // Token inbound mail services pass to the email endpoint; empty disables it
+
+	// EmailDomains limits email replies to senders at these domains (any
+	// sender when empty); From addresses are easy to forge, so set it
+	EmailDomains []string
+
+	mu sync.Mutex // One command at a time, so concurrent saves don't drop each other's changes
+}
+
+// NewBridge returns a bridge to the documents under root
+func NewBridge(root string) *Bridge {
+	return &Bridge{Service: comments.NewService(), Root: root}
+}
+
+// Result is the reply to a chat command
+type Result struct {
+	Text   string
+	Public bool // Changes are announced to the channel; lists and errors go only to the user
+}
+
+// Run runs a command from a chat user and returns the reply to post
+// Failures are replies too, so the user sees what went wrong.
+func (b *Bridge) Run(ctx context.Context, user, text string) Result {
+	args := strings.Fields(text)
+	if len(args) < 2 || args[0] == "help" {
+		return Result{Text: Usage}
+	}
+	if user == "" {
+		return Result{Text: "Error: the chat request did not say who sent it"}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path, err := b.document(args[0])
+	if err != nil {
+		return Result{Text: fmt.Sprintf("Error: %v", err)}
+	}
+	name := args[0]
+
+	switch args[1] {
+	case "blockers", "questions", "list":
+		filter := comments.ListFilter{}
+		switch args[1] {
+		case "blockers":
+			filter.Type = "B"
+		case "questions":
+			filter.Type = "Q"
+		}
+		threads, err := b.Service.List(ctx, path, filter)
+		if err != nil {
+			return Result{Text: fmt.Sprintf("Error: %v", err)}
+		}
+		return Result{Text: formatThreads(name, args[1], threads)}
+
+	case "reply":
+		if len(args) < 4 {
+			return Result{Text: "Usage: <file> reply <thread> <text>"}
+		}
+		replyText := restAfter(text, 3)
+		if _, err := b.Service.Reply(ctx, path, comments.ReplyOptions{ThreadID: args[2], Author: user, Text: replyText}); err != nil {
+			return Result{Text: fmt.Sprintf("Error: %v", err)}
+		}
+		return Result{Text: fmt.Sprintf("@%s replied to %s on %s: %s", user, args[2], name, replyText), Public: true}
+
+	case "resolve":
+		if len(args) != 3 {
+			return Result{Text: "Usage: <file> resolve <thread>"}
+		}
+		if err := b.Service.Resolve(ctx, path, args[2], user); err != nil {
+			return Result{Text: fmt.Sprintf("Error: %v", err)}
+		}
+		return Result{Text: fmt.Sprintf("@%s resolved %s on %s", user, args[2], name), Public: true}
+	}
+	return Result{Text: fmt.Sprintf("Unknown command %q\n\n%s", args[1], Usage)}
+}
+
+// document resolves a document name against the bridge's directory
+func (b *Bridge) document(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", errors.New("documents must be named relative to the bridge's directory")
+	}
+	return filepath.Join(b.Root, name), nil
+}
+
+// restAfter returns text after its first n fields, spacing kept as typed
+func restAfter(text string, n int) string {
+	rest := strings.TrimSpace(text)
+	for range n {
+		i := strings.IndexFunc(rest, unicode.IsSpace)
+		if i < 0 {
+			return ""
+		}
+		rest = strings.TrimSpace(rest[i:])
+	}
+	return rest
+}
+
+// formatThreads lists threads one per line, e.g.
+// "c123 • line 12 • @bob: [B] Broken example (2 replies)"
+func formatThreads(name, what string, threads []*comment.Comment) string {
+	if len(threads) == 0 {
+		return fmt.Sprintf("No open %s on %s", noun(what), name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d open %s on %s:", len(threads), noun(what), name)
+	for i, t := range threads {
+		if i == maxListed {
+			fmt.Fprintf(&b, "\n… and %d more", len(threads)-maxListed)
+			break
+		}
+		location := fmt.Sprintf("line %d", t.Line)
+		if t.IsDocumentLevel() {
+			location = "document"
+		}
+		text, _, _ := strings.Cut(t.Text, "\n")
+		fmt.Fprintf(&b, "\n%s • %s • @%s: %s", t.ID, location, t.Author, text)
+		switch n := t.CountReplies(); n {
+		case 0:
+		case 1:
+			b.WriteString(" (1 reply)")
+		default:
+			fmt.Fprintf(&b, " (%d replies)", n)
+		}
+	}
+	return b.String()
+}
+
+// noun names what a list command lists
+func noun(what string) string {
+	if what == "list" {
+		return "threads"
+	}
+	return what
+}
@@ -0,0 +1,185 @@
+package chat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+func setupBridge(t *testing.T) (*Bridge, *comment.Comment) {
+	t.Helper()
+	root := t.TempDir()
+	path := filepath.Join(root, "guide.md")
+	if err := os.WriteFile(path, []byte("# Guide\n\nInstall the tool.\nRun it once."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := comment.LoadFromSidecar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocker := comment.NewCommentWithType("bob", 3, "[B] Broken install step", "B")
+	doc.Threads = append(doc.Threads, blocker, comment.NewCommentWithType("bob", 4, "[Q] Why once?", "Q"))
+	if err := comment.SaveToSidecar(path, doc); err != nil {
+		t.Fatal(err)
+	}
+	return NewBridge(root), blocker
+}
+
+func TestBridgeRun(t *testing.T) {
+	b, blocker := setupBridge(t)
+	ctx := context.Background()
+
+	result := b.Run(ctx, "carol", "guide.md blockers")
+	if result.Public || !strings.Contains(result.Text, "1 open blockers on guide.md") || !strings.Contains(result.Text, blocker.ID) {
+		t.Errorf("Unexpected blockers reply: %+v", result)
+	}
+
+	result = b.Run(ctx, "carol", "guide.md reply "+blocker.ID+"  Fixed in  the README")
+	if !result.Public || !strings.Contains(result.Text, "Fixed in  the README") {
+		t.Errorf("Unexpected reply result: %+v", result)
+	}
+	if result := b.Run(ctx, "carol", "guide.md resolve "+blocker.ID); !result.Public {
+		t.Errorf("Unexpected resolve result: %+v", result)
+	}
+
+	doc, err := comment.LoadFromSidecar(filepath.Join(b.Root, "guide.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := doc.FindThreadByID(blocker.ID)
+	if !thread.Resolved || len(thread.Replies) != 1 || thread.Replies[0].Author != "carol" || thread.Replies[0].Text != "Fixed in  the README" {
+		t.Errorf("Expected carol's reply and the thread resolved, got %+v", thread)
+	}
+	if result := b.Run(ctx, "carol", "guide.md blockers"); !strings.HasPrefix(result.Text, "No open blockers") {
+		t.Errorf("Resolved blockers should not be listed: %q", result.Text)
+	}
+
+	if result := b.Run(ctx, "carol", "../secret.md list"); !strings.HasPrefix(result.Text, "Error:") {
+		t.Errorf("Paths outside the root must be refused: %q", result.Text)
+	}
+	if result := b.Run(ctx, "carol", "guide.md shout"); !strings.Contains(result.Text, Usage) {
+		t.Errorf("Unknown commands should show the usage: %q", result.Text)
+	}
+}
+
+func TestSlackEndpoint(t *testing.T) {
+	b, _ := setupBridge(t)
+	b.SlackSecret = "slack-secret"
+	server := httptest.NewServer(b.Handler())
+	defer server.Close()
+
+	post := func(body string, stamp time.Time, secret string) *http.Response {
+		ts := strconv.FormatInt(stamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + ts + ":" + body))
+		req, _ := http.NewRequest("POST", server.URL+"/slack", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", ts)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	body := url.Values{"user_name": {"carol"}, "text": {"guide.md questions"}}.Encode()
+	resp := post(body, time.Now(), "slack-secret")
+	defer resp.Body.Close()
+	var reply map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["response_type"] != "ephemeral" || !strings.Contains(reply["text"], "Why once?") {
+		t.Errorf("Unexpected Slack reply: %v", reply)
+	}
+
+	if resp := post(body, time.Now(), "wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Bad signature: got status %d", resp.StatusCode)
+	}
+	if resp := post(body, time.Now().Add(-time.Hour), "slack-secret"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Stale request: got status %d", resp.StatusCode)
+	}
+	if resp, _ := http.Post(server.URL+"/teams", "application/json", strings.NewReader("{}")); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Teams without a secret should be disabled, got status %d", resp.StatusCode)
+	}
+}
+
+func TestTeamsEndpoint(t *testing.T) {
+	b, blocker := setupBridge(t)
+	key := []byte("teams-key")
+	b.TeamsSecret = base64.StdEncoding.EncodeToString(key)
+	server := httptest.NewServer(b.Handler())
+	defer server.Close()
+
+	body := `{"type":"message","text":"<at>comments</at> guide.md resolve ` + blocker.ID + `&nbsp;","from":{"name":"Dana"}}`
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	req, _ := http.NewRequest("POST", server.URL+"/teams", strings.NewReader(body))
+	req.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var reply map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["type"] != "message" || reply["text"] != "@Dana resolved "+blocker.ID+" on guide.md" {
+		t.Errorf("Unexpected Teams reply: %v", reply)
+	}
+}
+
+func TestEmailEndpoint(t *testing.T) {
+	b, blocker := setupBridge(t)
+	b.EmailToken = "mail-token"
+	b.EmailDomains = []string{"example.com"}
+	server := httptest.NewServer(b.Handler())
+	defer server.Close()
+
+	message := func(from string) string {
+		return "From: " + from + "\r\nSubject: Re: [comments:guide.md#" + blocker.ID + "] Broken install step\r\n\r\nFixed upstream.\r\n"
+	}
+	post := func(token, from string) *http.Response {
+		form := url.Values{"email": {message(from)}}
+		resp, err := http.PostForm(server.URL+"/email?token="+token, form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := post("wrong", "erin@example.com"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Bad token: got status %d", resp.StatusCode)
+	}
+	if resp := post("mail-token", "mallory@evil.test"); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Sender outside the domains: got status %d", resp.StatusCode)
+	}
+	if resp := post("mail-token", "Erin <erin@example.com>"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Valid email: got status %d", resp.StatusCode)
+	}
+
+	doc, err := comment.LoadFromSidecar(filepath.Join(b.Root, "guide.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := doc.FindThreadByID(blocker.ID)
+	if len(thread.Replies) != 1 || thread.Replies[0].Author != "erin@example.com" || thread.Replies[0].Text != "Fixed upstream." {
+		t.Errorf("Expected erin's emailed reply, got %+v", thread.Replies)
+	}
+}
@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comments"
+	"github.com/rcliao/comments/pkg/mailin"
+)
+
+// ReplyByEmail adds the reply an email makes to the thread it references
+// (see package mailin), as the sender's address, and returns a confirmation
+func (b *Bridge) ReplyByEmail(ctx context.Context, r io.Reader) (string, error) {
+	reply, err := mailin.Parse(r)
+	if err != nil {
+		return "", err
+	}
+	if !b.senderAllowed(reply.Author) {
+		return "", fmt.Errorf("%w: mail from %s is not accepted", comments.ErrInvalid, reply.Author)
+	}
+	path, err := b.document(reply.Document)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.Service.Reply(ctx, path, comments.ReplyOptions{ThreadID: reply.ThreadID, Author: reply.Author, Text: reply.Text}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s replied to %s on %s", reply.Author, reply.ThreadID, reply.Document), nil
+}
+
+// senderAllowed reports whether mail from address is accepted: from any
+// sender without EmailDomains, else only from those domains
+func (b *Bridge) senderAllowed(address string) bool {
+	if len(b.EmailDomains) == 0 {
+		return true
+	}
+	_, domain, _ := strings.Cut(address, "@")
+	for _, allowed := range b.EmailDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveEmail takes an email forwarded by an inbound mail service: the raw
+// message as the body, or in the "email" (SendGrid) or "body-mime" (Mailgun)
+// form field. Services can't sign requests, so the token goes in the URL
+// (?token=) or an Authorization: Bearer header.
+func (b *Bridge) serveEmail(w http.ResponseWriter, r *http.Request) {
+	if b.EmailToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(b.EmailToken)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var message io.Reader
+	switch mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); mediaType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		r.Body = http.MaxBytesReader(w, r.Body, maxEmail)
+		if err := r.ParseMultipartForm(maxEmail); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			http.Error(w, "invalid form", http.StatusBadRequest)
+			return
+		}
+		raw := r.FormValue("email")
+		if raw == "" {
+			raw = r.FormValue("body-mime")
+		}
+		message = strings.NewReader(raw)
+	default:
+		message = http.MaxBytesReader(w, r.Body, maxEmail)
+	}
+
+	confirmation, err := b.ReplyByEmail(r.Context(), message)
+	if err != nil {
+		// The service would only retry the same message
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, map[string]string{"text": confirmation})
+}
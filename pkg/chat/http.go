@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Requests are capped at maxBody bytes (emails, with attachments, at
+// maxEmail), and Slack requests older than maxSkew are refused as possible
+// replays
+const (
+	maxBody  = 64 << 10
+	maxEmail = 10 << 20
+	maxSkew  = 5 * time.Minute
+)
+
+// Handler serves the bridge endpoints: POST /slack for a Slack slash
+// command, POST /teams for a Teams outgoing webhook, and POST /email for an
+// inbound mail service. Each checks its platform's signature or token and
+// is disabled (404) without it.
+func (b *Bridge) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /slack", b.serveSlack)
+	mux.HandleFunc("POST /teams", b.serveTeams)
+	mux.HandleFunc("POST /email", b.serveEmail)
+	return mux
+}
+
+// serveSlack answers a slash command, e.g. "/comments docs/api.md blockers"
+func (b *Bridge) serveSlack(w http.ResponseWriter, r *http.Request) {
+	if b.SlackSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if !validSlackSignature(b.SlackSecret, r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	result := b.Run(r.Context(), form.Get("user_name"), form.Get("text"))
+	responseType := "ephemeral"
+	if result.Public {
+		responseType = "in_channel"
+	}
+	writeJSON(w, map[string]string{"response_type": responseType, "text": result.Text})
+}
+
+// serveTeams answers a message mentioning the outgoing webhook's bot, e.g.
+// "@comments docs/api.md blockers"
+func (b *Bridge) serveTeams(w http.ResponseWriter, r *http.Request) {
+	if b.TeamsSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if !validTeamsSignature(b.TeamsSecret, r.Header.Get("Authorization"), body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var activity struct {
+		Text string `json:"text"`
+		From struct {
+			Name string `json:"name"`
+		} `json:"from"`
+	}
+	if err := json.Unmarshal(body, &activity); err != nil {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+
+	// Teams replies go to the conversation the message came from
+	result := b.Run(r.Context(), activity.From.Name, teamsText(activity.Text))
+	writeJSON(w, map[string]string{"type": "message", "text": result.Text})
+}
+
+// readBody reads a request body up to maxBody, answering the request itself
+// if it can't
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return body, true
+}
+
+// writeJSON answers with v as JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	// Nothing more can be done for a client that went away
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// validSlackSignature checks Slack's request signature: v0= and the hex
+// HMAC-SHA256 of "v0:<timestamp>:<body>" under the signing secret
+func validSlackSignature(secret string, header http.Header, body []byte, at time.Time) bool {
+	stamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil || at.Sub(time.Unix(seconds, 0)).Abs() > maxSkew {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + stamp + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature")))
+}
+
+// validTeamsSignature checks a Teams outgoing webhook's Authorization
+// header: "HMAC " and the base64 HMAC-SHA256 of the body under the
+// base64-decoded security token
+func validTeamsSignature(secret, authorization string, body []byte) bool {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	want := "HMAC " + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(authorization))
+}
+
+// teamsMention matches the bot's @mention that starts a Teams message;
+// other markup is stripped by htmlTag
+var (
+	teamsMention = regexp.MustCompile(`<at>[^<]*</at>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// teamsText returns the command in a Teams message: its text without the
+// bot's mention and HTML markup
+func teamsText(text string) string {
+	text = teamsMention.ReplaceAllString(text, "")
+	text = htmlTag.ReplaceAllString(text, " ")
+	return strings.NewReplacer("&nbsp;", " ", "&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`).Replace(text)
+}
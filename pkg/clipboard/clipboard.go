@@ -0,0 +1,52 @@
+// Package clipboard copies text to the system clipboard, falling back to the
+// OSC52 terminal escape sequence when no platform clipboard is available
+// (e.g. over SSH or in a container).
+package clipboard
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Method is how text reached the clipboard
+type Method string
+
+const (
+	MethodSystem   Method = "system"   // pbcopy, xclip/xsel/wl-copy, or the Windows clipboard
+	MethodTerminal Method = "terminal" // OSC52 escape sequence written to the terminal
+)
+
+// Copy copies text to the platform clipboard, or asks the terminal to do it
+// with OSC52 when there is none. terminal receives the escape sequence.
+// Terminals without OSC52 support ignore it, so a MethodTerminal result is
+// best effort.
+func Copy(text string, terminal io.Writer) (Method, error) {
+	if !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return MethodSystem, nil
+		}
+	}
+
+	if _, err := Sequence(text).WriteTo(terminal); err != nil {
+		return "", fmt.Errorf("writing OSC52 sequence: %w", err)
+	}
+	return MethodTerminal, nil
+}
+
+// Sequence returns the OSC52 sequence that copies text, wrapped for tmux or
+// screen when running inside one
+func Sequence(text string) osc52.Sequence {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		return seq.Tmux()
+	}
+	if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return seq.Screen()
+	}
+	return seq
+}
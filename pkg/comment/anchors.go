@@ -0,0 +1,95 @@
+package comment
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/markdown"
+)
+
+// Comments are normally anchored to a line, which edits made inside the
+// tool keep up to date but edits made elsewhere can only be guessed at. A
+// comment anchored to a heading stores the heading's slug and its offset
+// within the section instead, so when validation finds the document changed
+// it re-resolves the slug: reordering sections moves the comment with its
+// section rather than orphaning or misplacing it.
+
+// Anchor modes for new comments
+const (
+	AnchorLine    = "line"    // Anchored to the line (default)
+	AnchorHeading = "heading" // Anchored to the section's heading slug
+)
+
+// AnchorComment anchors a new thread as mode asks: AnchorHeading anchors it
+// to its section's heading, AnchorLine or "" leaves it on its line
+func AnchorComment(c *Comment, doc *DocumentWithComments, mode string) error {
+	switch mode {
+	case "", AnchorLine:
+		return nil
+	case AnchorHeading:
+		return AnchorToHeading(c, doc)
+	}
+	return fmt.Errorf("unknown anchor mode %q (valid: %s, %s)", mode, AnchorLine, AnchorHeading)
+}
+
+// AnchorToHeading anchors a thread to the heading of the section its line is
+// in. Document-level comments and lines before the first heading have no
+// heading to anchor to.
+func AnchorToHeading(c *Comment, doc *DocumentWithComments) error {
+	if c.IsDocumentLevel() {
+		return fmt.Errorf("document-level comments can't be anchored to a heading")
+	}
+	section, ok := doc.Structure().SectionsByLine[c.Line]
+	if !ok {
+		return fmt.Errorf("line %d is not under a heading", c.Line)
+	}
+	c.Anchor = section.Slug
+	c.AnchorOffset = c.Line - section.StartLine
+	return nil
+}
+
+// refreshAnchors re-anchors heading-anchored threads to wherever their lines
+// are now, after edits made inside the tool moved them. It must only be
+// called while lines and Content agree, i.e. when saving.
+func refreshAnchors(doc *DocumentWithComments) {
+	var structure *markdown.DocumentStructure
+	for _, thread := range doc.Threads {
+		if thread.Anchor == "" || thread.IsOrphaned() {
+			continue
+		}
+		if structure == nil {
+			structure = doc.Structure()
+		}
+		if section, ok := structure.SectionsByLine[thread.Line]; ok {
+			thread.Anchor = section.Slug
+			thread.AnchorOffset = thread.Line - section.StartLine
+		}
+	}
+}
+
+// resolveAnchor moves a heading-anchored thread to its heading's current
+// position, keeping its offset within the section (clamped to the section's
+// end). found is false if the heading no longer exists.
+func resolveAnchor(c *Comment, structure *markdown.DocumentStructure) (moved, found bool) {
+	section := structure.FindSlug(c.Anchor)
+	if section == nil {
+		return false, false
+	}
+	target := min(section.StartLine+c.AnchorOffset, section.EndLine)
+	path := section.GetFullPath(structure.SectionsByID)
+	if delta := target - c.Line; delta != 0 {
+		c.OriginalLine = c.Line
+		shiftThread(c, delta)
+		moved = true
+	}
+	setThreadSection(c, section.ID, path)
+	return moved, true
+}
+
+// setThreadSection records the section of a thread and its replies
+func setThreadSection(c *Comment, id, path string) {
+	c.SectionID = id
+	c.SectionPath = path
+	for _, reply := range c.Replies {
+		setThreadSection(reply, id, path)
+	}
+}
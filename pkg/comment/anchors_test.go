@@ -0,0 +1,88 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeadingAnchorFollowsReorderedSections(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	original := "# Setup\n\nInstall it.\n\n# Usage\n\nRun it.\nThen stop it.\n"
+	if err := os.WriteFile(mdPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anchored := NewComment("alice", 8, "How?")
+	if err := AnchorComment(anchored, doc, AnchorHeading); err != nil {
+		t.Fatal(err)
+	}
+	if anchored.Anchor != "usage" || anchored.AnchorOffset != 3 {
+		t.Fatalf("Anchor = %q+%d, want usage+3", anchored.Anchor, anchored.AnchorOffset)
+	}
+	anchored.Replies = append(anchored.Replies, NewReply("bob", "Like this", anchored))
+	doc.Threads = append(doc.Threads, anchored)
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the sections outside the tool
+	reordered := "# Usage\n\nRun it.\nThen stop it.\n\n# Setup\n\nInstall it.\n"
+	if err := os.WriteFile(mdPath, []byte(reordered), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := doc.Threads[0]
+	if c.Line != 4 || c.IsOrphaned() || c.SectionPath != "Usage" {
+		t.Errorf("Anchored comment at line %d (%s, orphaned=%v), want line 4 in Usage", c.Line, c.SectionPath, c.IsOrphaned())
+	}
+	if c.Replies[0].Line != 4 {
+		t.Errorf("Reply at line %d, want it to follow its thread to line 4", c.Replies[0].Line)
+	}
+
+	// Removing the heading leaves the thread to the usual checks
+	if err := os.WriteFile(mdPath, []byte("# Setup\n\nInstall it.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Threads[0].IsOrphaned() {
+		t.Error("A thread whose heading is gone should be orphaned")
+	}
+}
+
+func TestHeadingAnchorRefreshedOnSave(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# A\n\none\ntwo\n"}
+	c := NewComment("alice", 4, "Check")
+	if err := AnchorToHeading(c, doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Threads = append(doc.Threads, c)
+
+	// An edit made inside the tool moves the comment; saving re-anchors it
+	doc.Content = "# A\n\nzero\none\ntwo\n"
+	RecalculateCommentLines(doc.Threads, 3, 3, 2)
+	if _, err := marshalStore(doc, SaveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Line != 5 || c.AnchorOffset != 4 {
+		t.Errorf("Comment at line %d with offset %d, want line 5 with offset 4", c.Line, c.AnchorOffset)
+	}
+
+	if err := AnchorToHeading(NewComment("alice", DocumentLine, "Overall"), doc); err == nil {
+		t.Error("Document-level comments should not anchor to a heading")
+	}
+	if err := AnchorComment(c, doc, "paragraph"); err == nil {
+		t.Error("Expected an error for an unknown anchor mode")
+	}
+}
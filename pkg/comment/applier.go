@@ -16,19 +16,33 @@ func ApplySuggestion(content string, suggestion *Comment) (string, error) {
 		return "", fmt.Errorf("invalid start line: %d", suggestion.StartLine)
 	}
 
-	if suggestion.EndLine < suggestion.StartLine {
-		return "", fmt.Errorf("end line %d cannot be before start line %d", suggestion.EndLine, suggestion.StartLine)
-	}
-
 	lines := strings.Split(content, "\n")
 
-	// Validate line range
-	if suggestion.StartLine > len(lines) {
-		return "", fmt.Errorf("start line %d out of range (1-%d)", suggestion.StartLine, len(lines))
-	}
+	if suggestion.IsMove {
+		if err := CheckMove(suggestion.StartLine, suggestion.EndLine, suggestion.MoveAfter, len(lines)); err != nil {
+			return "", err
+		}
+	} else if suggestion.IsInsertion() {
+		// Insertions replace nothing, so they may also go after the last line
+		if suggestion.StartLine > len(lines)+1 {
+			return "", fmt.Errorf("insertion after line %d out of range (0-%d)", suggestion.EndLine, len(lines))
+		}
+		if suggestion.OriginalText != "" {
+			return "", fmt.Errorf("an insertion cannot have original text")
+		}
+	} else {
+		if suggestion.EndLine < suggestion.StartLine {
+			return "", fmt.Errorf("end line %d cannot be before start line %d", suggestion.EndLine, suggestion.StartLine)
+		}
 
-	if suggestion.EndLine > len(lines) {
-		return "", fmt.Errorf("end line %d out of range (1-%d)", suggestion.EndLine, len(lines))
+		// Validate line range
+		if suggestion.StartLine > len(lines) {
+			return "", fmt.Errorf("start line %d out of range (1-%d)", suggestion.StartLine, len(lines))
+		}
+
+		if suggestion.EndLine > len(lines) {
+			return "", fmt.Errorf("end line %d out of range (1-%d)", suggestion.EndLine, len(lines))
+		}
 	}
 
 	// Extract original text for verification
@@ -44,6 +58,10 @@ func ApplySuggestion(content string, suggestion *Comment) (string, error) {
 			suggestion.OriginalText, actualOriginal)
 	}
 
+	if suggestion.IsMove {
+		return strings.Join(applyMove(lines, suggestion), "\n"), nil
+	}
+
 	// Build new content
 	var result []string
 
@@ -73,18 +91,33 @@ func PreviewSuggestion(content string, suggestion *Comment) (string, error) {
 
 	lines := strings.Split(content, "\n")
 
-	if suggestion.StartLine < 1 || suggestion.StartLine > len(lines) {
-		return "", fmt.Errorf("invalid line range")
-	}
+	if suggestion.IsMove {
+		if err := CheckMove(suggestion.StartLine, suggestion.EndLine, suggestion.MoveAfter, len(lines)); err != nil {
+			return "", fmt.Errorf("invalid line range: %w", err)
+		}
+	} else if suggestion.IsInsertion() {
+		if suggestion.StartLine < 1 || suggestion.StartLine > len(lines)+1 {
+			return "", fmt.Errorf("invalid line range")
+		}
+	} else {
+		if suggestion.StartLine < 1 || suggestion.StartLine > len(lines) {
+			return "", fmt.Errorf("invalid line range")
+		}
 
-	if suggestion.EndLine > len(lines) {
-		return "", fmt.Errorf("invalid line range")
+		if suggestion.EndLine > len(lines) {
+			return "", fmt.Errorf("invalid line range")
+		}
 	}
 
 	var preview strings.Builder
 
 	preview.WriteString("=== Suggestion Preview ===\n\n")
-	preview.WriteString(fmt.Sprintf("Lines %d-%d\n\n", suggestion.StartLine, suggestion.EndLine))
+	preview.WriteString(DescribeSuggestionLines(suggestion) + "\n\n")
+
+	if suggestion.IsMove {
+		preview.WriteString(previewMove(lines, suggestion))
+		return preview.String(), nil
+	}
 
 	// Show original
 	preview.WriteString("--- Original\n")
@@ -102,6 +135,24 @@ func PreviewSuggestion(content string, suggestion *Comment) (string, error) {
 	return preview.String(), nil
 }
 
+// DescribeSuggestionLines describes where a suggestion applies, e.g.
+// "Lines 3-5", "Insert after line 2", or "Move lines 3-5 after line 9"
+func DescribeSuggestionLines(suggestion *Comment) string {
+	if suggestion.IsMove && suggestion.MoveAfter == 0 {
+		return fmt.Sprintf("Move lines %d-%d to the top", suggestion.StartLine, suggestion.EndLine)
+	}
+	if suggestion.IsMove {
+		return fmt.Sprintf("Move lines %d-%d after line %d", suggestion.StartLine, suggestion.EndLine, suggestion.MoveAfter)
+	}
+	if suggestion.IsInsertion() {
+		if suggestion.EndLine == 0 {
+			return "Insert at top of document"
+		}
+		return fmt.Sprintf("Insert after line %d", suggestion.EndLine)
+	}
+	return fmt.Sprintf("Lines %d-%d", suggestion.StartLine, suggestion.EndLine)
+}
+
 // ApplyAllSuggestions applies multiple suggestions to the document
 // Suggestions should be sorted by line number in descending order (bottom to top)
 // to avoid line number shifts affecting subsequent suggestions
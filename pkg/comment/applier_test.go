@@ -443,3 +443,51 @@ func TestPreviewSuggestionNonSuggestion(t *testing.T) {
 		t.Error("Expected error for non-suggestion")
 	}
 }
+
+func TestApplyInsertion(t *testing.T) {
+	content := "Line 1\nLine 2\nLine 3"
+
+	tests := []struct {
+		after    int
+		expected string
+	}{
+		{0, "New\nLine 1\nLine 2\nLine 3"},
+		{2, "Line 1\nLine 2\nNew\nLine 3"},
+		{3, "Line 1\nLine 2\nLine 3\nNew"},
+	}
+	for _, tt := range tests {
+		insertion := NewInsertion("alice", tt.after, "Add a line", "New")
+		if !insertion.IsInsertion() {
+			t.Fatalf("NewInsertion(%d) is not an insertion", tt.after)
+		}
+		result, err := ApplySuggestion(content, insertion)
+		if err != nil {
+			t.Fatalf("Insert after %d: %v", tt.after, err)
+		}
+		if result != tt.expected {
+			t.Errorf("Insert after %d:\nExpected:\n%s\nGot:\n%s", tt.after, tt.expected, result)
+		}
+	}
+
+	if _, err := ApplySuggestion(content, NewInsertion("alice", 4, "Past the end", "New")); err == nil {
+		t.Error("Expected error for an insertion past the end")
+	}
+	withOriginal := NewInsertion("alice", 1, "Replace", "New")
+	withOriginal.OriginalText = "Line 2"
+	if _, err := ApplySuggestion(content, withOriginal); err == nil {
+		t.Error("Expected error for an insertion with original text")
+	}
+}
+
+func TestPreviewInsertion(t *testing.T) {
+	preview, err := PreviewSuggestion("Line 1\nLine 2", NewInsertion("alice", 1, "Add", "New"))
+	if err != nil {
+		t.Fatalf("PreviewSuggestion failed: %v", err)
+	}
+	if !strings.Contains(preview, "Insert after line 1") || !strings.Contains(preview, "+ New") {
+		t.Errorf("Preview should describe the insertion point and the new line:\n%s", preview)
+	}
+	if strings.Contains(preview, "- Line") {
+		t.Errorf("Preview of an insertion should remove nothing:\n%s", preview)
+	}
+}
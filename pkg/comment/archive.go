@@ -0,0 +1,238 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchiveCriteria selects threads to move out of the live sidecar
+type ArchiveCriteria struct {
+	Status    string        // Required: "completed" or "resolved"
+	Author    string        // Only threads started by this author (empty matches any author)
+	OlderThan time.Duration // Only threads with no activity within this window (0 matches any age)
+}
+
+// Matches reports whether a thread root satisfies the criteria at time now
+// A thread counts as resolved if it was resolved via the resolve command or
+// has status "resolved".
+func (a ArchiveCriteria) Matches(thread *Comment, now time.Time) bool {
+	switch a.Status {
+	case "resolved":
+		if !thread.Resolved && thread.GetStatus() != "resolved" {
+			return false
+		}
+	default:
+		if thread.GetStatus() != a.Status {
+			return false
+		}
+	}
+
+	if a.Author != "" && !strings.EqualFold(thread.Author, a.Author) {
+		return false
+	}
+
+	if a.OlderThan > 0 && now.Sub(thread.LatestTimestamp()) < a.OlderThan {
+		return false
+	}
+
+	return true
+}
+
+// SelectThreadsForArchive returns the thread roots matching the criteria
+func SelectThreadsForArchive(threads []*Comment, criteria ArchiveCriteria, now time.Time) []*Comment {
+	selected := []*Comment{}
+	for _, thread := range threads {
+		if criteria.Matches(thread, now) {
+			selected = append(selected, thread)
+		}
+	}
+	return selected
+}
+
+// ParseAge parses a retention age such as "90d", "2w", or any time.ParseDuration value ("36h")
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+
+	unit := s[len(s)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (examples: 90d, 2w, 36h)", s)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (examples: 90d, 2w, 36h)", s)
+	}
+	return d, nil
+}
+
+// GetArchivePath returns the archive file for threads cleaned up with the given status
+func GetArchivePath(mdPath, status string) string {
+	return strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".archived." + status
+}
+
+// ListArchivePaths returns every archive file that belongs to a markdown file
+func ListArchivePaths(mdPath string) ([]string, error) {
+	pattern := strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".archived.*"
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadArchive reads an archive file, returning an empty archive if it does not exist
+func LoadArchive(path string) (*StorageFormat, error) {
+	archive := &StorageFormat{
+		Version: StorageVersion,
+		Threads: []*Comment{},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return archive, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, fmt.Errorf("failed to parse archive %s: %w", path, err)
+	}
+	return archive, nil
+}
+
+// SaveArchive writes an archive file
+func SaveArchive(path string, archive *StorageFormat) error {
+	sorted := *archive
+	sorted.Threads = CanonicalOrder(archive.Threads)
+	data, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	log().Info("saved archive", "archive", path, "threads", len(archive.Threads))
+	return nil
+}
+
+// ArchiveThreads moves threads from doc into the archive file for status
+// The document's sidecar is not saved; callers save it after archiving succeeds.
+func ArchiveThreads(mdPath string, doc *DocumentWithComments, threads []*Comment, status string) (string, error) {
+	archivePath := GetArchivePath(mdPath, status)
+
+	archive, err := LoadArchive(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if archive.DocumentHash == "" {
+		archive.DocumentHash = doc.DocumentHash
+		archive.LastValidated = doc.LastValidated
+	}
+
+	archiveIDs := make(map[string]bool)
+	for _, thread := range threads {
+		archiveIDs[thread.ID] = true
+		archive.Threads = append(archive.Threads, thread)
+	}
+
+	remaining := []*Comment{}
+	for _, thread := range doc.Threads {
+		if !archiveIDs[thread.ID] {
+			remaining = append(remaining, thread)
+		}
+	}
+
+	if err := SaveArchive(archivePath, archive); err != nil {
+		return "", err
+	}
+	doc.Threads = remaining
+
+	return archivePath, nil
+}
+
+// LoadArchivedThreads returns all archived threads for a markdown file
+func LoadArchivedThreads(mdPath string) ([]*Comment, error) {
+	paths, err := ListArchivePaths(mdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := []*Comment{}
+	for _, path := range paths {
+		archive, err := LoadArchive(path)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, archive.Threads...)
+	}
+	return threads, nil
+}
+
+// FindArchivedThread locates the archived thread containing a comment ID
+// The ID may be a thread root or any reply within it. Returns the thread root
+// and the archive file it was found in.
+func FindArchivedThread(mdPath, id string) (*Comment, string, error) {
+	paths, err := ListArchivePaths(mdPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, path := range paths {
+		archive, err := LoadArchive(path)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, thread := range archive.Threads {
+			if thread.ID == id || findInReplies(thread.Replies, id) != nil {
+				return thread, path, nil
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("comment not found in archives: %s", id)
+}
+
+// RemoveFromArchive deletes a thread from an archive file
+// The file is removed once it no longer holds any threads.
+func RemoveFromArchive(archivePath, threadID string) error {
+	archive, err := LoadArchive(archivePath)
+	if err != nil {
+		return err
+	}
+
+	remaining := []*Comment{}
+	for _, thread := range archive.Threads {
+		if thread.ID != threadID {
+			remaining = append(remaining, thread)
+		}
+	}
+	archive.Threads = remaining
+
+	if len(remaining) == 0 {
+		if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove archive: %w", err)
+		}
+		return nil
+	}
+
+	return SaveArchive(archivePath, archive)
+}
@@ -0,0 +1,127 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"90d", 90 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if err != nil {
+			t.Errorf("ParseAge(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "d", "-3d", "soon"} {
+		if _, err := ParseAge(bad); err == nil {
+			t.Errorf("ParseAge(%q) should fail", bad)
+		}
+	}
+}
+
+func TestArchiveCriteriaMatches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-100 * 24 * time.Hour)
+
+	botResolved := &Comment{ID: "c1", Author: "bot", Timestamp: old, Resolved: true, Status: "active"}
+	recentReply := &Comment{ID: "c2", Author: "bot", Timestamp: old, Resolved: true, Replies: []*Comment{
+		{ID: "c3", Author: "alice", Timestamp: now.Add(-time.Hour)},
+	}}
+	humanCompleted := &Comment{ID: "c4", Author: "alice", Timestamp: old, Status: "completed"}
+
+	criteria := ArchiveCriteria{Status: "resolved", Author: "bot", OlderThan: 90 * 24 * time.Hour}
+	selected := SelectThreadsForArchive([]*Comment{botResolved, recentReply, humanCompleted}, criteria, now)
+	if len(selected) != 1 || selected[0].ID != "c1" {
+		t.Errorf("Expected only c1 to match, got %v", selected)
+	}
+
+	completed := ArchiveCriteria{Status: "completed"}
+	if !completed.Matches(humanCompleted, now) || completed.Matches(botResolved, now) {
+		t.Error("Completed criteria matched the wrong threads")
+	}
+}
+
+func TestArchiveThreadsAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+	if err := os.WriteFile(mdPath, []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keep := &Comment{ID: "c1", Text: "keep", Replies: []*Comment{}}
+	drop := &Comment{ID: "c2", Text: "drop", Status: "completed", Replies: []*Comment{}}
+	doc := &DocumentWithComments{Content: "line\n", Threads: []*Comment{keep, drop}}
+
+	archivePath, err := ArchiveThreads(mdPath, doc, []*Comment{drop}, "completed")
+	if err != nil {
+		t.Fatalf("ArchiveThreads failed: %v", err)
+	}
+	if archivePath != GetArchivePath(mdPath, "completed") {
+		t.Errorf("Unexpected archive path %s", archivePath)
+	}
+	if len(doc.Threads) != 1 || doc.Threads[0].ID != "c1" {
+		t.Errorf("Archived thread should be removed from document, got %v", doc.Threads)
+	}
+
+	// Archiving again appends rather than overwrites
+	another := &Comment{ID: "c3", Status: "resolved", Replies: []*Comment{}}
+	doc.Threads = append(doc.Threads, another)
+	if _, err := ArchiveThreads(mdPath, doc, []*Comment{another}, "resolved"); err != nil {
+		t.Fatalf("ArchiveThreads failed: %v", err)
+	}
+
+	archived, err := LoadArchivedThreads(mdPath)
+	if err != nil {
+		t.Fatalf("LoadArchivedThreads failed: %v", err)
+	}
+	if len(archived) != 2 {
+		t.Fatalf("Expected 2 archived threads, got %d", len(archived))
+	}
+}
+
+func TestFindAndRemoveArchivedThread(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+
+	reply := &Comment{ID: "c2", Text: "reply"}
+	thread := &Comment{ID: "c1", Status: "completed", Replies: []*Comment{reply}}
+	doc := &DocumentWithComments{Threads: []*Comment{thread}}
+	if _, err := ArchiveThreads(mdPath, doc, []*Comment{thread}, "completed"); err != nil {
+		t.Fatalf("ArchiveThreads failed: %v", err)
+	}
+
+	// Reply IDs resolve to their thread
+	found, archivePath, err := FindArchivedThread(mdPath, "c2")
+	if err != nil {
+		t.Fatalf("FindArchivedThread failed: %v", err)
+	}
+	if found.ID != "c1" {
+		t.Errorf("Expected thread c1, got %s", found.ID)
+	}
+
+	if _, _, err := FindArchivedThread(mdPath, "missing"); err == nil {
+		t.Error("Expected error for unknown ID")
+	}
+
+	if err := RemoveFromArchive(archivePath, "c1"); err != nil {
+		t.Fatalf("RemoveFromArchive failed: %v", err)
+	}
+	if _, err := os.Stat(archivePath); !os.IsNotExist(err) {
+		t.Error("Empty archive file should be removed")
+	}
+}
@@ -0,0 +1,47 @@
+package comment
+
+import "strings"
+
+// Authors sometimes make a suggested edit by hand instead of accepting the
+// suggestion, which then stays pending forever. Validation points such
+// suggestions out, and `comments sync` marks them accepted.
+
+// AppliedByHand returns true if lines (the document split into lines)
+// already hold a pending suggestion's proposed text at its target range.
+// Suggestions whose original text is still there don't count, as the
+// document may simply happen to contain the proposal next to it; nor do
+// deletions and moves, whose result can't be told apart from other edits.
+func (c *Comment) AppliedByHand(lines []string) bool {
+	if !c.IsPending() || c.IsMove || c.ProposedText == "" || c.ProposedText == c.OriginalText {
+		return false
+	}
+	if !c.IsInsertion() && c.OriginalText != "" && c.EndLine <= len(lines) && c.StartLine >= 1 &&
+		strings.Join(lines[c.StartLine-1:c.EndLine], "\n") == c.OriginalText {
+		return false
+	}
+
+	proposed := strings.Split(c.ProposedText, "\n")
+	first := c.StartLine - 1
+	if first < 0 || first+len(proposed) > len(lines) {
+		return false
+	}
+	for i, line := range proposed {
+		if lines[first+i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// SuggestionsAppliedByHand returns the document's pending suggestions whose
+// proposed text is already in it (see AppliedByHand)
+func (d *DocumentWithComments) SuggestionsAppliedByHand() []*Comment {
+	lines := strings.Split(d.Content, "\n")
+	applied := []*Comment{}
+	for _, c := range d.GetAllComments() {
+		if c.AppliedByHand(lines) {
+			applied = append(applied, c)
+		}
+	}
+	return applied
+}
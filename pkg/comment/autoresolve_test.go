@@ -0,0 +1,46 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppliedByHand(t *testing.T) {
+	original := "# Title\n\nTeh first line\nSecond line"
+	replace := NewSuggestion("alice", 3, 3, "Typo", "Teh first line", "The first line\nWith more detail")
+	insert := NewInsertion("bob", 4, "Add a closing line", "Last line")
+	remove := NewSuggestion("carol", 4, 4, "Drop this", "Second line", "")
+
+	lines := strings.Split(original, "\n")
+	for _, s := range []*Comment{replace, insert, remove} {
+		if s.AppliedByHand(lines) {
+			t.Errorf("%s should not count as applied before the edit", s.Text)
+		}
+	}
+
+	edited := strings.Split("# Title\n\nThe first line\nWith more detail\nSecond line", "\n")
+	if !replace.AppliedByHand(edited) {
+		t.Error("Expected the replacement to count as applied")
+	}
+	if remove.AppliedByHand(edited) {
+		t.Error("Deletions never count as applied")
+	}
+
+	doc := &DocumentWithComments{
+		Content: "# Title\n\nThe first line\nWith more detail\nLast line",
+		Threads: []*Comment{replace, insert, remove},
+	}
+	insert.StartLine, insert.EndLine = 5, 4 // Where the edit moved it
+	applied := doc.SuggestionsAppliedByHand()
+	if len(applied) != 2 || applied[0] != replace || applied[1] != insert {
+		t.Errorf("Expected the replacement and the insertion, got %d", len(applied))
+	}
+
+	// Decided suggestions are left alone
+	if err := RejectSuggestion(doc.Threads, replace.ID); err != nil {
+		t.Fatal(err)
+	}
+	if replace.AppliedByHand(edited) {
+		t.Error("A rejected suggestion should not count as applied")
+	}
+}
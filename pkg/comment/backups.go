@@ -0,0 +1,220 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat is the timestamp in backup file names
+// (<file>.md.comments.json.backup.20060102_150405)
+const backupTimeFormat = "20060102_150405"
+
+// Backup is a saved copy of a sidecar
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// GetBackupPattern returns the glob matching every backup of a markdown file's sidecar
+func GetBackupPattern(mdPath string) string {
+	return GetSidecarPath(mdPath) + ".backup.*"
+}
+
+// newBackupPath returns an unused backup path for the sidecar, stamped with
+// the current time
+func newBackupPath(mdPath string) string {
+	base := GetSidecarPath(mdPath) + ".backup." + now().Format(backupTimeFormat)
+	path := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// ListBackups returns the backups of a markdown file's sidecar, newest first
+func ListBackups(mdPath string) ([]Backup, error) {
+	paths, err := filepath.Glob(GetBackupPattern(mdPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	prefix := GetSidecarPath(mdPath) + ".backup."
+	backups := make([]Backup, 0, len(paths))
+	for _, path := range paths {
+		stamp := strings.TrimPrefix(path, prefix)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		backups = append(backups, Backup{Path: path, Time: t, Size: info.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].Path > backups[j].Path
+	})
+	return backups, nil
+}
+
+// ReadBackup parses a sidecar backup
+func ReadBackup(path string) (*StorageFormat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	var storage StorageFormat
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", path, err)
+	}
+	if storage.Version != StorageVersion {
+		return nil, fmt.Errorf("unsupported storage version in %s: %s (expected %s)", path, storage.Version, StorageVersion)
+	}
+	return &storage, nil
+}
+
+// copySidecar copies the current sidecar to a new backup
+// Returns "" if there is no sidecar to copy.
+func copySidecar(mdPath string) (string, error) {
+	data, err := os.ReadFile(GetSidecarPath(mdPath))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read sidecar: %w", err)
+	}
+
+	path := newBackupPath(mdPath)
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	log().Info("backed up sidecar", "file", mdPath, "backup", path, "bytes", len(data))
+	return path, nil
+}
+
+// BackupSidecar copies the sidecar to a timestamped backup before a
+// destructive save, then prunes all but the newest keep backups.
+// keep <= 0 disables backups; returns the backup path, or "" if none was made.
+func BackupSidecar(mdPath string, keep int) (string, error) {
+	if keep <= 0 {
+		return "", nil
+	}
+
+	path, err := copySidecar(mdPath)
+	if err != nil || path == "" {
+		return "", err
+	}
+	if _, err := PruneBackups(mdPath, keep); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// PruneBackups removes all but the newest keep backups and returns the paths removed
+func PruneBackups(mdPath string, keep int) ([]string, error) {
+	backups, err := ListBackups(mdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, b := range backups[min(max(keep, 0), len(backups)):] {
+		if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove backup: %w", err)
+		}
+		removed = append(removed, b.Path)
+	}
+	if len(removed) > 0 {
+		log().Info("pruned backups", "file", mdPath, "removed", len(removed), "kept", len(backups)-len(removed))
+	}
+	return removed, nil
+}
+
+// RestoreBackup replaces the sidecar with a backup. The current sidecar is
+// backed up first, so a restore can itself be undone. Returns the path of
+// that backup ("" if there was no sidecar).
+func RestoreBackup(mdPath, backupPath string) (string, error) {
+	if _, err := ReadBackup(backupPath); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	previous, err := copySidecar(mdPath)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(GetSidecarPath(mdPath), data, 0644); err != nil {
+		return previous, fmt.Errorf("failed to write sidecar file: %w", err)
+	}
+	log().Info("restored sidecar", "file", mdPath, "backup", backupPath, "previous", previous)
+	return previous, nil
+}
+
+// FindMatchingBackup returns the newest backup of mdPath's sidecar that was
+// written against content with the given hash and holds at least one thread,
+// with its parsed contents, or nil if there is none. Unreadable backups are
+// skipped.
+func FindMatchingBackup(mdPath, contentHash string) (*Backup, *StorageFormat, error) {
+	backups, err := ListBackups(mdPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range backups {
+		storage, err := ReadBackup(backups[i].Path)
+		if err != nil {
+			log().Debug("skipping unreadable backup", "file", mdPath, "backup", backups[i].Path, "err", err)
+			continue
+		}
+		if storage.DocumentHash == contentHash && len(storage.Threads) > 0 {
+			return &backups[i], storage, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// recoverSidecar handles a missing sidecar whose document matches one of its
+// backups, e.g. when a stale sidecar was archived and the edit that made it
+// stale was then undone. With autoRestore (see SaveOptions) the backup
+// becomes the sidecar again and its contents are returned; otherwise the
+// backup is pointed out on stderr and nil is returned.
+func recoverSidecar(mdPath, contentHash string, autoRestore bool) ([]byte, error) {
+	backup, storage, err := FindMatchingBackup(mdPath, contentHash)
+	if err != nil || backup == nil {
+		return nil, err
+	}
+
+	if !autoRestore {
+		fmt.Fprintf(os.Stderr, "Note: %s has no sidecar, but backup %s holds %d thread(s) written against its current content\n",
+			mdPath, backup.Path, len(storage.Threads))
+		fmt.Fprintf(os.Stderr, "Restore it with 'comments backups restore %s --backup %s', or run with --auto-restore\n",
+			mdPath, strings.TrimPrefix(backup.Path, GetSidecarPath(mdPath)+".backup."))
+		return nil, nil
+	}
+
+	if _, err := RestoreBackup(mdPath, backup.Path); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Restored %d thread(s) for %s from %s, which matches its current content\n",
+		len(storage.Threads), mdPath, backup.Path)
+	return os.ReadFile(GetSidecarPath(mdPath))
+}
@@ -0,0 +1,167 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupSidecarKeepsNewest(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	doc := &DocumentWithComments{Content: "# Title\n\nBody\n", Threads: []*Comment{}}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		restore := SetClock(FixedClock(start.Add(time.Duration(i) * time.Minute)))
+		path, err := BackupSidecar(mdPath, 2)
+		restore()
+		if err != nil {
+			t.Fatalf("BackupSidecar: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	backups, err := ListBackups(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups, got %d", len(backups))
+	}
+	if backups[0].Path != paths[3] || backups[1].Path != paths[2] {
+		t.Errorf("Expected the two newest backups, newest first; got %s, %s", backups[0].Path, backups[1].Path)
+	}
+	if !backups[0].Time.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("Unexpected backup time %v", backups[0].Time)
+	}
+
+	if path, err := BackupSidecar(mdPath, 0); err != nil || path != "" {
+		t.Errorf("Expected keep 0 to skip the backup, got %q, %v", path, err)
+	}
+}
+
+func TestBackupNamesDoNotCollide(t *testing.T) {
+	defer SetClock(FixedClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)))()
+
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := SaveToSidecar(mdPath, &DocumentWithComments{Content: "Body\n"}); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := BackupSidecar(mdPath, 5)
+	second, _ := BackupSidecar(mdPath, 5)
+	if first == second {
+		t.Fatalf("Expected distinct backup paths, both were %s", first)
+	}
+	if backups, _ := ListBackups(mdPath); len(backups) != 2 {
+		t.Errorf("Expected 2 backups, got %d", len(backups))
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	doc := &DocumentWithComments{Content: "Line one\n", Threads: []*Comment{NewComment("alice", 1, "Keep me")}}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := BackupSidecar(mdPath, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc.Threads = nil
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := RestoreBackup(mdPath, backup)
+	if err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if previous == "" {
+		t.Error("Expected the replaced sidecar to be backed up")
+	}
+
+	restored, err := ReadSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Threads) != 1 || restored.Threads[0].Text != "Keep me" {
+		t.Errorf("Expected the backed-up thread, got %+v", restored.Threads)
+	}
+
+	if saved, err := ReadBackup(previous); err != nil || len(saved.Threads) != 0 {
+		t.Errorf("Expected the replaced (empty) sidecar in %s, got %v", previous, err)
+	}
+}
+
+func TestRestoreBackupRejectsInvalidFile(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := SaveToSidecar(mdPath, &DocumentWithComments{Content: "Body\n"}); err != nil {
+		t.Fatal(err)
+	}
+	bad := GetSidecarPath(mdPath) + ".backup.20250115_100000"
+	if err := os.WriteFile(bad, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RestoreBackup(mdPath, bad); err == nil {
+		t.Error("Expected an error restoring an invalid backup")
+	}
+	if backups, _ := ListBackups(mdPath); len(backups) != 1 {
+		t.Errorf("A failed restore should not create a backup, found %d", len(backups))
+	}
+}
+
+func TestLoadFromSidecarRecoversMatchingBackup(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	content := "# Title\n\nLine one\n"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc := &DocumentWithComments{Content: content, Threads: []*Comment{NewComment("alice", 3, "Keep me")}}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := ArchiveStaleSidecar(mdPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without auto-restore the backup is only pointed out
+	loaded, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Threads) != 0 || SidecarExists(mdPath) {
+		t.Fatalf("Expected nothing restored, got %d thread(s)", len(loaded.Threads))
+	}
+
+	// A backup of other content is never restored
+	defer SetSettingsFunc(func(string) Settings {
+		return Settings{Save: SaveOptions{AutoRestore: true}}
+	})()
+	if err := os.WriteFile(mdPath, []byte(content+"Edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadFromSidecar(mdPath); err != nil || len(loaded.Threads) != 0 {
+		t.Fatalf("Expected no restore for changed content, got %v", err)
+	}
+
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Threads) != 1 || loaded.Threads[0].Text != "Keep me" {
+		t.Fatalf("Expected the backed-up thread, got %+v", loaded.Threads)
+	}
+	if !SidecarExists(mdPath) {
+		t.Error("Expected the backup restored as the sidecar")
+	}
+}
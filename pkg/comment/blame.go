@@ -0,0 +1,49 @@
+package comment
+
+import "sort"
+
+// Blame returns the threads whose comments or suggestions touched any of
+// lines start-end, oldest first, to trace how those lines came to read as
+// they do. Resolved threads and decided suggestions count; a thread matches
+// if it or any of its replies touched the lines.
+func Blame(threads []*Comment, start, end int) []*Comment {
+	var touched func(c *Comment) bool
+	touched = func(c *Comment) bool {
+		if first, last := blameRange(c); first <= end && last >= start {
+			return true
+		}
+		for _, reply := range c.Replies {
+			if touched(reply) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var matches []*Comment
+	for _, thread := range threads {
+		if !thread.IsDocumentLevel() && touched(thread) {
+			matches = append(matches, thread)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Timestamp.Before(matches[j].Timestamp)
+	})
+	return matches
+}
+
+// blameRange returns the lines a comment touches in the current document.
+// A decided suggestion's range is left as it was when it was decided, but
+// its Line keeps following the document, so its extent is counted from Line:
+// the proposed text for an accepted suggestion and the original lines
+// otherwise.
+func blameRange(c *Comment) (int, int) {
+	if !c.IsSuggestion || c.IsPending() {
+		return c.LineRange()
+	}
+	lines := c.EndLine - c.StartLine + 1
+	if c.IsAccepted() && !c.IsMove {
+		lines = ProposedLineCount(c)
+	}
+	return c.Line, c.Line + max(lines, 1) - 1
+}
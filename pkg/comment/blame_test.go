@@ -0,0 +1,40 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlame(t *testing.T) {
+	question := NewComment("alice", 11, "Why 100ms?")
+	question.Resolved = true
+	question.Timestamp = time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	// Accepted when it was at lines 3-3; the two proposed lines are now 10-11
+	accepted := NewSuggestion("bob", 3, 3, "Expand", "x", "y\nz")
+	accepted.Line = 10
+	accepted.Accepted = new(bool)
+	*accepted.Accepted = true
+	accepted.Timestamp = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	elsewhere := NewComment("carol", 20, "Unrelated")
+	documentLevel := NewComment("carol", DocumentLine, "Overall")
+
+	// A thread elsewhere with a suggestion reply on the lines
+	withReply := NewComment("dave", 30, "Rework this")
+	withReply.Timestamp = time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	withReply.Replies = []*Comment{NewSuggestion("erin", 12, 12, "Here", "a", "b")}
+
+	threads := []*Comment{question, elsewhere, documentLevel, withReply, accepted}
+
+	got := Blame(threads, 11, 12)
+	if len(got) != 3 || got[0] != accepted || got[1] != question || got[2] != withReply {
+		t.Fatalf("Blame(11-12) returned %d thread(s), want accepted, question, and the thread with the reply in that order", len(got))
+	}
+	if got := Blame(threads, 12, 12); len(got) != 1 || got[0] != withReply {
+		t.Errorf("Blame(12) returned %d thread(s), want only the thread with the reply", len(got))
+	}
+	if got := Blame(threads, 13, 19); len(got) != 0 {
+		t.Errorf("Blame(13-19) returned %d thread(s), want none", len(got))
+	}
+}
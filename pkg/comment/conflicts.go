@@ -0,0 +1,189 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict markers used in merge templates (same shape as git's)
+const (
+	conflictMarkerStart = "<<<<<<<"
+	conflictMarkerSep   = "======="
+	conflictMarkerEnd   = ">>>>>>>"
+)
+
+// PendingConflicts returns the overlap and nested conflicts between pending
+// suggestions in the threads. Adjacent suggestions apply cleanly and are not
+// included.
+func PendingConflicts(threads []*Comment) []Conflict {
+	serious := []Conflict{}
+	for _, c := range DetectConflicts(GetPendingSuggestions(threads)) {
+		if c.Type == ConflictOverlap || c.Type == ConflictNested {
+			serious = append(serious, c)
+		}
+	}
+	return serious
+}
+
+// Range returns the line range covered by both suggestions in the conflict
+func (c Conflict) Range() (int, int) {
+	return min(c.Suggestion1.StartLine, c.Suggestion2.StartLine),
+		max(c.Suggestion1.EndLine, c.Suggestion2.EndLine)
+}
+
+// Other returns the suggestion competing with s in the conflict
+func (c Conflict) Other(s *Comment) *Comment {
+	if c.Suggestion1 == s {
+		return c.Suggestion2
+	}
+	return c.Suggestion1
+}
+
+// ConflictSide returns the text of lines startLine-endLine with only s applied
+// Used to show competing proposals over the same region side by side.
+func ConflictSide(content string, startLine, endLine int, s *Comment) (string, error) {
+	lines := strings.Split(content, "\n")
+	if startLine < 1 || endLine > len(lines) || startLine > endLine {
+		return "", fmt.Errorf("lines %d-%d out of range (1-%d)", startLine, endLine, len(lines))
+	}
+
+	region := strings.Join(lines[startLine-1:endLine], "\n")
+
+	// Apply the suggestion relative to the region
+	shifted := *s
+	shifted.StartLine = s.StartLine - startLine + 1
+	shifted.EndLine = s.EndLine - startLine + 1
+	return ApplySuggestion(region, &shifted)
+}
+
+// MergeTemplate returns the conflict region with both proposals between
+// git-style conflict markers, ready to be edited into a single version
+func MergeTemplate(content string, c Conflict) (string, error) {
+	if c.Suggestion1.IsMove || c.Suggestion2.IsMove {
+		return "", fmt.Errorf("a move can't be merged by hand; pick one of the suggestions instead")
+	}
+	start, end := c.Range()
+
+	side1, err := ConflictSide(content, start, end, c.Suggestion1)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", c.Suggestion1.ID, err)
+	}
+	side2, err := ConflictSide(content, start, end, c.Suggestion2)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", c.Suggestion2.ID, err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s (@%s): %s\n", conflictMarkerStart, c.Suggestion1.ID, c.Suggestion1.Author, firstLine(c.Suggestion1.Text))
+	b.WriteString(side1)
+	b.WriteString("\n" + conflictMarkerSep + "\n")
+	b.WriteString(side2)
+	fmt.Fprintf(&b, "\n%s %s (@%s): %s\n", conflictMarkerEnd, c.Suggestion2.ID, c.Suggestion2.Author, firstLine(c.Suggestion2.Text))
+	return b.String(), nil
+}
+
+// HasConflictMarkers reports whether text still contains merge template markers
+func HasConflictMarkers(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, conflictMarkerStart) || line == conflictMarkerSep || strings.HasPrefix(line, conflictMarkerEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// PickSuggestion resolves a conflict in favour of winner: winner is accepted
+// and applied to the document, and every pending suggestion that conflicts
+// with it is rejected. Returns the rejected suggestions.
+func PickSuggestion(doc *DocumentWithComments, winner *Comment) ([]*Comment, error) {
+	if !winner.IsPending() {
+		return nil, fmt.Errorf("suggestion %s is not pending", winner.ID)
+	}
+	if unmet := UnmetDependencies(winner, doc.Threads); len(unmet) > 0 {
+		return nil, fmt.Errorf("suggestion %s depends on %s, which has not been accepted", winner.ID, strings.Join(unmet, ", "))
+	}
+
+	losers := []*Comment{}
+	for _, c := range PendingConflicts(doc.Threads) {
+		if c.Suggestion1 == winner || c.Suggestion2 == winner {
+			losers = append(losers, c.Other(winner))
+		}
+	}
+
+	newContent, err := ApplySuggestion(doc.Content, winner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, loser := range losers {
+		if err := RejectSuggestion(doc.Threads, loser.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := AcceptSuggestion(doc.Threads, winner.ID); err != nil {
+		return nil, err
+	}
+	doc.Content = newContent
+	doc.RecordApplied(winner)
+
+	return losers, nil
+}
+
+// MergeSuggestions resolves a conflict with a hand-merged version of the
+// region. Both suggestions are rejected and a new accepted suggestion by
+// author, covering the whole conflict range, records the merged text.
+func MergeSuggestions(doc *DocumentWithComments, c Conflict, author, mergedText string) (*Comment, error) {
+	if HasConflictMarkers(mergedText) {
+		return nil, fmt.Errorf("merged text still contains conflict markers")
+	}
+
+	start, end := c.Range()
+	lines := strings.Split(doc.Content, "\n")
+	if end > len(lines) {
+		return nil, fmt.Errorf("end line %d out of range (1-%d)", end, len(lines))
+	}
+	original := strings.Join(lines[start-1:end], "\n")
+
+	merged := NewSuggestion(author, start, end,
+		fmt.Sprintf("Merged %s and %s", c.Suggestion1.ID, c.Suggestion2.ID),
+		original, strings.TrimSuffix(mergedText, "\n"))
+
+	newContent, err := ApplySuggestion(doc.Content, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range []*Comment{c.Suggestion1, c.Suggestion2} {
+		if err := RejectSuggestion(doc.Threads, s.ID); err != nil {
+			return nil, err
+		}
+	}
+	accepted := true
+	merged.Accepted = &accepted
+	decidedAt := now()
+	merged.DecidedAt = &decidedAt
+	doc.Content = newContent
+	doc.RecordApplied(merged)
+
+	UpdateCommentSection(merged, doc)
+	doc.Threads = append(doc.Threads, merged)
+	return merged, nil
+}
+
+// RejectConflict resolves a conflict by rejecting both suggestions
+func RejectConflict(doc *DocumentWithComments, c Conflict) error {
+	for _, s := range []*Comment{c.Suggestion1, c.Suggestion2} {
+		if err := RejectSuggestion(doc.Threads, s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
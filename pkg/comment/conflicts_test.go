@@ -0,0 +1,113 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func newConflictDoc() (*DocumentWithComments, *Comment, *Comment, *Comment) {
+	a := newTestSuggestion("a", 2, 3, "two\nthree", "TWO and THREE")
+	b := newTestSuggestion("b", 3, 4, "three\nfour", "3\n4")
+	c := newTestSuggestion("c", 6, 6, "six", "SIX")
+	doc := &DocumentWithComments{
+		Content: "one\ntwo\nthree\nfour\nfive\nsix",
+		Threads: []*Comment{a, b, c},
+	}
+	return doc, a, b, c
+}
+
+func TestPendingConflicts(t *testing.T) {
+	doc, a, b, _ := newConflictDoc()
+
+	conflicts := PendingConflicts(doc.Threads)
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+	if conflicts[0].Suggestion1 != a || conflicts[0].Suggestion2 != b {
+		t.Errorf("Expected conflict between a and b")
+	}
+
+	start, end := conflicts[0].Range()
+	if start != 2 || end != 4 {
+		t.Errorf("Expected range 2-4, got %d-%d", start, end)
+	}
+}
+
+func TestMergeTemplate(t *testing.T) {
+	doc, _, _, _ := newConflictDoc()
+	conflicts := PendingConflicts(doc.Threads)
+
+	template, err := MergeTemplate(doc.Content, conflicts[0])
+	if err != nil {
+		t.Fatalf("MergeTemplate failed: %v", err)
+	}
+
+	expected := "<<<<<<< a (@): \nTWO and THREE\nfour\n=======\ntwo\n3\n4\n>>>>>>> b (@): \n"
+	if template != expected {
+		t.Errorf("Template mismatch.\nExpected:\n%q\nGot:\n%q", expected, template)
+	}
+	if !HasConflictMarkers(template) {
+		t.Error("Expected template to contain conflict markers")
+	}
+}
+
+func TestPickSuggestion(t *testing.T) {
+	doc, a, b, c := newConflictDoc()
+
+	rejected, err := PickSuggestion(doc, b)
+	if err != nil {
+		t.Fatalf("PickSuggestion failed: %v", err)
+	}
+
+	if len(rejected) != 1 || rejected[0] != a {
+		t.Errorf("Expected a to be rejected, got %v", rejected)
+	}
+	if !b.IsAccepted() || !a.IsRejected() || !c.IsPending() {
+		t.Errorf("Unexpected states: a=%v b=%v c=%v", a.Accepted, b.Accepted, c.Accepted)
+	}
+	if doc.Content != "one\ntwo\n3\n4\nfive\nsix" {
+		t.Errorf("Unexpected content: %q", doc.Content)
+	}
+}
+
+func TestMergeSuggestions(t *testing.T) {
+	doc, a, b, c := newConflictDoc()
+	conflicts := PendingConflicts(doc.Threads)
+
+	merged, err := MergeSuggestions(doc, conflicts[0], "reviewer", "TWO\n3\n4 merged\nextra\n")
+	if err != nil {
+		t.Fatalf("MergeSuggestions failed: %v", err)
+	}
+
+	if !a.IsRejected() || !b.IsRejected() {
+		t.Error("Expected both original suggestions to be rejected")
+	}
+	if !merged.IsAccepted() || merged.Author != "reviewer" || merged.StartLine != 2 || merged.EndLine != 4 {
+		t.Errorf("Unexpected merged suggestion: %+v", merged)
+	}
+	if doc.Content != "one\nTWO\n3\n4 merged\nextra\nfive\nsix" {
+		t.Errorf("Unexpected content: %q", doc.Content)
+	}
+	// The untouched suggestion below moves down by one line
+	if c.StartLine != 7 || c.EndLine != 7 {
+		t.Errorf("Expected c at line 7, got %d-%d", c.StartLine, c.EndLine)
+	}
+
+	if _, err := MergeSuggestions(doc, conflicts[0], "reviewer", "<<<<<<< a\nx\n=======\ny\n>>>>>>> b"); err == nil || !strings.Contains(err.Error(), "markers") {
+		t.Errorf("Expected conflict marker error, got %v", err)
+	}
+}
+
+func TestRejectConflict(t *testing.T) {
+	doc, a, b, _ := newConflictDoc()
+
+	if err := RejectConflict(doc, PendingConflicts(doc.Threads)[0]); err != nil {
+		t.Fatalf("RejectConflict failed: %v", err)
+	}
+	if !a.IsRejected() || !b.IsRejected() {
+		t.Error("Expected both suggestions to be rejected")
+	}
+	if len(PendingConflicts(doc.Threads)) != 0 {
+		t.Error("Expected no conflicts after rejecting both")
+	}
+}
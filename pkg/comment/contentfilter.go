@@ -0,0 +1,134 @@
+package comment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Content filter actions
+const (
+	FilterRedact = "redact" // Replace the match and keep the comment
+	FilterReject = "reject" // Refuse the comment
+)
+
+// DefaultRedactionText replaces what a redact rule matches unless the rule
+// gives its own replacement
+const DefaultRedactionText = "[redacted]"
+
+// filterCommandTimeout bounds how long an external filter may take
+const filterCommandTimeout = 10 * time.Second
+
+// FilterRule is one pattern the content filter looks for
+type FilterRule struct {
+	Name        string         // Shown when the rule rejects a comment
+	Pattern     *regexp.Regexp // What to look for
+	Action      string         // FilterRedact or FilterReject
+	Replacement string         // What redact replaces matches with
+}
+
+// ContentFilter screens the text of incoming comments, for teams with
+// compliance rules on what stored review data may contain: regex rules
+// redact or reject matches, then an optional external command gets the last
+// word.
+type ContentFilter struct {
+	Rules []FilterRule
+
+	// Command, if set, is run with the comment text on stdin and the author
+	// in $COMMENTS_FILTER_AUTHOR. Exiting 0 accepts the text it prints (which
+	// may be changed); any other exit rejects the comment, with what it
+	// printed on stderr as the reason.
+	Command string
+
+	// Dir is where Command runs (the config file's directory)
+	Dir string
+}
+
+// FilterRejection is a comment refused by the content filter
+type FilterRejection struct {
+	Rule   string // The rule or command that refused it
+	Reason string
+}
+
+func (e *FilterRejection) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("rejected by content filter %s", e.Rule)
+	}
+	return fmt.Sprintf("rejected by content filter %s: %s", e.Rule, e.Reason)
+}
+
+// IsEmpty reports whether the filter lets everything through unchanged
+func (f ContentFilter) IsEmpty() bool {
+	return len(f.Rules) == 0 && f.Command == ""
+}
+
+// Apply returns text as it should be stored, redacted where the rules say,
+// or a *FilterRejection if the comment must not be stored at all. Other
+// errors mean the external command couldn't be run.
+func (f ContentFilter) Apply(author, text string) (string, error) {
+	for _, rule := range f.Rules {
+		if !rule.Pattern.MatchString(text) {
+			continue
+		}
+		if rule.Action == FilterReject {
+			return "", &FilterRejection{Rule: rule.Name, Reason: "the text matches a forbidden pattern"}
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultRedactionText
+		}
+		text = rule.Pattern.ReplaceAllLiteralString(text, replacement)
+		log().Debug("content filter redacted text", "rule", rule.Name, "author", author)
+	}
+
+	if f.Command == "" {
+		return text, nil
+	}
+	return f.runCommand(author, text)
+}
+
+// runCommand passes text through the external filter command
+func (f ContentFilter) runCommand(author, text string) (string, error) {
+	parts := strings.Fields(f.Command)
+	ctx, cancel := context.WithTimeout(context.Background(), filterCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Dir = f.Dir
+	cmd.Env = append(os.Environ(), "COMMENTS_FILTER_AUTHOR="+author)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("content filter %s timed out after %s", parts[0], filterCommandTimeout)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", &FilterRejection{Rule: parts[0], Reason: strings.TrimSpace(stderr.String()) + exitReason(exitErr, stderr.Len())}
+	}
+	if err != nil {
+		return "", fmt.Errorf("running content filter %s: %w", parts[0], err)
+	}
+
+	filtered := strings.TrimRight(stdout.String(), "\n")
+	if strings.TrimSpace(filtered) == "" {
+		return "", &FilterRejection{Rule: parts[0], Reason: "the filter removed all of the text"}
+	}
+	return filtered, nil
+}
+
+// exitReason describes a failed filter command's exit when it printed no
+// reason of its own
+func exitReason(err *exec.ExitError, printed int) string {
+	if printed > 0 {
+		return ""
+	}
+	return fmt.Sprintf("exit status %d", err.ExitCode())
+}
@@ -0,0 +1,61 @@
+package comment
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// A decision log keeps the rationale of settled [B] and [T] threads in the
+// repository, where it outlives cleanup: each record has the thread's
+// question, the discussion that followed, and the note it was resolved
+// with (its last reply).
+
+// DecisionRecord is one settled thread in a decision log
+type DecisionRecord struct {
+	Thread     *Comment
+	Context    string     // The thread's text without its [B]/[T] prefix
+	Discussion []*Comment // Replies before the resolution, oldest first
+	Resolution *Comment   // The last reply, nil if resolved without one
+	ResolvedAt time.Time  // When it was resolved, or its last activity if not recorded
+}
+
+// Title returns the first line of the record's context
+func (r DecisionRecord) Title() string {
+	title, _, _ := strings.Cut(r.Context, "\n")
+	return title
+}
+
+// DecisionRecords returns the decision records for the resolved [B] and [T]
+// threads among threads, oldest decision first
+func DecisionRecords(threads []*Comment) []DecisionRecord {
+	records := []DecisionRecord{}
+	for _, t := range threads {
+		if !t.Resolved || (t.Type != "B" && t.Type != "T") {
+			continue
+		}
+
+		replies := flattenReplies(t.Replies)
+		sort.SliceStable(replies, func(i, j int) bool {
+			return replies[i].Timestamp.Before(replies[j].Timestamp)
+		})
+		record := DecisionRecord{
+			Thread:     t,
+			Context:    strings.TrimSpace(strings.TrimPrefix(t.Text, "["+t.Type+"]")),
+			Discussion: replies,
+			ResolvedAt: t.LatestTimestamp(),
+		}
+		if n := len(replies); n > 0 {
+			record.Discussion, record.Resolution = replies[:n-1], replies[n-1]
+		}
+		if t.ResolvedAt != nil {
+			record.ResolvedAt = *t.ResolvedAt
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ResolvedAt.Before(records[j].ResolvedAt)
+	})
+	return records
+}
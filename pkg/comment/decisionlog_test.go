@@ -0,0 +1,48 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecisionRecords(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	bug := NewCommentWithType("bob", 3, "[B] Example is broken\nIt calls the old API", "B")
+	bug.Resolved = true
+	resolvedAt := at(5)
+	bug.ResolvedAt = &resolvedAt
+	question := &Comment{ID: "r1", Author: "carol", Timestamp: at(1), Text: "Which one?"}
+	question.Replies = []*Comment{{ID: "r3", Author: "alice", Timestamp: at(3), Text: "Fixed the call"}}
+	bug.Replies = []*Comment{question, {ID: "r2", Author: "bob", Timestamp: at(2), Text: "The first"}}
+
+	task := NewCommentWithType("bob", 4, "Add tests", "T")
+	task.Timestamp = at(1)
+	task.Resolved = true
+
+	open := NewCommentWithType("bob", 5, "Still broken", "B")
+	settledQuestion := NewCommentWithType("bob", 6, "Why?", "Q")
+	settledQuestion.Resolved = true
+
+	records := DecisionRecords([]*Comment{bug, open, settledQuestion, task})
+	if len(records) != 2 {
+		t.Fatalf("Expected the resolved B and T threads, got %d records", len(records))
+	}
+
+	// The task was resolved without a recorded time; its last activity is earlier
+	if records[0].Thread != task || records[0].Resolution != nil || len(records[0].Discussion) != 0 {
+		t.Errorf("First record should be the task, without a note: %+v", records[0])
+	}
+
+	r := records[1]
+	if r.Title() != "Example is broken" || r.Context != "Example is broken\nIt calls the old API" {
+		t.Errorf("Title %q, context %q", r.Title(), r.Context)
+	}
+	if len(r.Discussion) != 2 || r.Discussion[0].ID != "r1" || r.Discussion[1].ID != "r2" {
+		t.Errorf("Discussion should be the earlier replies in time order, got %v", r.Discussion)
+	}
+	if r.Resolution == nil || r.Resolution.ID != "r3" || !r.ResolvedAt.Equal(resolvedAt) {
+		t.Errorf("Resolution should be the last reply, resolved at %v: %+v", resolvedAt, r)
+	}
+}
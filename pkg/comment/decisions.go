@@ -0,0 +1,31 @@
+package comment
+
+// Decisions recorded on the reply that explains why a suggestion was
+// accepted or rejected
+const (
+	DecisionAccepted = "accepted"
+	DecisionRejected = "rejected"
+)
+
+// AddDecisionReply records reason as a reply by actor explaining the
+// suggestion's decision. Call it after the suggestion is accepted or rejected.
+func AddDecisionReply(suggestion *Comment, actor, reason string) *Comment {
+	reply := NewReply(actor, reason, suggestion)
+	reply.Decision = DecisionRejected
+	if suggestion.IsAccepted() {
+		reply.Decision = DecisionAccepted
+	}
+	suggestion.Replies = append(suggestion.Replies, reply)
+	return reply
+}
+
+// DecisionReply returns the latest reply explaining a suggestion's decision,
+// or nil if none was given
+func DecisionReply(suggestion *Comment) *Comment {
+	for i := len(suggestion.Replies) - 1; i >= 0; i-- {
+		if suggestion.Replies[i].Decision != "" {
+			return suggestion.Replies[i]
+		}
+	}
+	return nil
+}
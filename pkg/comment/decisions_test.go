@@ -0,0 +1,59 @@
+package comment
+
+import "testing"
+
+func TestDecisionReply(t *testing.T) {
+	suggestion := NewSuggestion("alice", 1, 1, "Shorter", "Line one", "Line 1")
+	threads := []*Comment{suggestion}
+
+	if DecisionReply(suggestion) != nil {
+		t.Fatal("No reason has been recorded yet")
+	}
+
+	if err := AddReplyToThread(threads, suggestion.ID, "bob", "Looking at this"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RejectSuggestion(threads, suggestion.ID); err != nil {
+		t.Fatal(err)
+	}
+	reply := AddDecisionReply(suggestion, "bob", "Numbers read worse here")
+
+	if reply.Author != "bob" || reply.Decision != DecisionRejected {
+		t.Errorf("Expected bob's rejected decision reply, got %s/%q", reply.Author, reply.Decision)
+	}
+	if got := DecisionReply(suggestion); got != reply {
+		t.Errorf("DecisionReply should skip ordinary replies, got %+v", got)
+	}
+}
+
+func TestStatsSuggestionsByAuthor(t *testing.T) {
+	doc := &DocumentWithComments{Content: "Line one\nLine two\nLine three"}
+	for i, author := range []string{"alice", "alice", "alice", "bob"} {
+		doc.Threads = append(doc.Threads, NewSuggestion(author, i%3+1, i%3+1, "Edit", "x", "y"))
+	}
+	if err := AcceptSuggestion(doc.Threads, doc.Threads[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RejectSuggestion(doc.Threads, doc.Threads[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := ComputeStats(doc)
+	alice := stats.Outcomes["alice"]
+	if alice == nil || alice.Accepted != 1 || alice.Rejected != 1 || alice.Pending != 1 {
+		t.Fatalf("Unexpected outcomes for alice: %+v", alice)
+	}
+	if rate := alice.AcceptanceRate(); rate != 0.5 {
+		t.Errorf("Expected a 50%% acceptance rate, got %v", rate)
+	}
+	if bob := stats.Outcomes["bob"]; bob == nil || bob.Pending != 1 || bob.AcceptanceRate() != 0 {
+		t.Errorf("Unexpected outcomes for bob: %+v", bob)
+	}
+
+	total := NewStats()
+	total.Merge(stats)
+	total.Merge(stats)
+	if got := total.Outcomes["alice"].Accepted; got != 2 {
+		t.Errorf("Merge should add outcomes, got %d accepted", got)
+	}
+}
@@ -0,0 +1,121 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// How batch-add treats a new comment that nearly repeats an open one, e.g.
+// when an agent is run twice over the same document
+const (
+	DedupeSkip  = "skip"  // Drop the duplicate
+	DedupeMerge = "merge" // Add a reworded duplicate as a reply to the existing thread
+	DedupeOff   = "off"   // Add duplicates like any other comment
+)
+
+// DefaultDuplicateThreshold is the text similarity (0-1) at which two
+// comments at the same place count as duplicates
+const DefaultDuplicateThreshold = 0.9
+
+// DedupePolicy selects what happens to duplicates and how similar two
+// comments must be to count as one
+type DedupePolicy struct {
+	Mode      string
+	Threshold float64
+}
+
+// DefaultDedupePolicy skips near-identical comments
+func DefaultDedupePolicy() DedupePolicy {
+	return DedupePolicy{Mode: DedupeSkip, Threshold: DefaultDuplicateThreshold}
+}
+
+// Validate checks the mode and threshold
+func (p DedupePolicy) Validate() error {
+	switch p.Mode {
+	case DedupeSkip, DedupeMerge, DedupeOff:
+	default:
+		return fmt.Errorf("invalid dedupe mode %q (valid: %s, %s, %s)", p.Mode, DedupeSkip, DedupeMerge, DedupeOff)
+	}
+	if p.Threshold <= 0 || p.Threshold > 1 {
+		return fmt.Errorf("dedupe threshold must be greater than 0 and at most 1, got %g", p.Threshold)
+	}
+	return nil
+}
+
+// FindDuplicate returns the open thread that c nearly repeats, or nil
+// Comments are compared with open comments on the same lines (or in the same
+// section); suggestions with pending suggestions replacing the same lines.
+func FindDuplicate(threads []*Comment, c *Comment, threshold float64) *Comment {
+	for _, t := range threads {
+		if t.Resolved || t.GetStatus() != "active" || t.IsSuggestion != c.IsSuggestion {
+			continue
+		}
+
+		if c.IsSuggestion {
+			if t.IsPending() && t.StartLine == c.StartLine && t.EndLine == c.EndLine &&
+				TextSimilarity(t.ProposedText, c.ProposedText) >= threshold {
+				return t
+			}
+			continue
+		}
+
+		tFirst, tLast := t.LineRange()
+		cFirst, cLast := c.LineRange()
+		samePlace := (tFirst == cFirst && tLast == cLast) ||
+			(t.SectionPath != "" && t.SectionPath == c.SectionPath)
+		if samePlace && TextSimilarity(t.Text, c.Text) >= threshold {
+			return t
+		}
+	}
+	return nil
+}
+
+// TextSimilarity scores how alike two texts are from 0 (nothing shared) to 1
+// (the same once case, punctuation, and spacing are ignored)
+func TextSimilarity(a, b string) float64 {
+	a, b = normalizeForComparison(a), normalizeForComparison(b)
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// normalizeForComparison lowercases text, drops punctuation, and collapses
+// whitespace
+func normalizeForComparison(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,63 @@
+package comment
+
+import "testing"
+
+func TestTextSimilarity(t *testing.T) {
+	if got := TextSimilarity("Fix the typo.", "  fix THE typo "); got != 1 {
+		t.Errorf("Expected punctuation/case/spacing to be ignored, got %v", got)
+	}
+	if got := TextSimilarity("Add an example for the API", "Add an example for this API"); got < 0.85 || got >= 1 {
+		t.Errorf("Expected a near match, got %v", got)
+	}
+	if got := TextSimilarity("Add an example", "Remove this section"); got > 0.5 {
+		t.Errorf("Expected unrelated texts to score low, got %v", got)
+	}
+	if got := TextSimilarity("", ""); got != 1 {
+		t.Errorf("Expected empty texts to match, got %v", got)
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	existing := NewComment("claude", 10, "[S] Consider adding an example here.")
+	existing.Status = "active"
+	resolved := NewComment("claude", 20, "Clarify this")
+	resolved.Resolved = true
+	suggestion := NewSuggestion("claude", 5, 6, "Tighten", "old", "The new text")
+	suggestion.Status = "active"
+	threads := []*Comment{existing, resolved, suggestion}
+
+	rerun := NewComment("claude", 10, "[S] Consider adding an example here")
+	if FindDuplicate(threads, rerun, DefaultDuplicateThreshold) != existing {
+		t.Error("Expected the rerun comment to match the existing one")
+	}
+
+	elsewhere := NewComment("claude", 11, "[S] Consider adding an example here")
+	if FindDuplicate(threads, elsewhere, DefaultDuplicateThreshold) != nil {
+		t.Error("Comments on different lines should not match")
+	}
+
+	// Resolved threads don't count; the feedback may be new again
+	if FindDuplicate(threads, NewComment("bot", 20, "Clarify this"), DefaultDuplicateThreshold) != nil {
+		t.Error("Resolved threads should not match")
+	}
+
+	same := NewSuggestion("claude", 5, 6, "Tighten wording", "old", "The new text.")
+	if FindDuplicate(threads, same, DefaultDuplicateThreshold) != suggestion {
+		t.Error("Expected the repeated suggestion to match")
+	}
+	different := NewSuggestion("claude", 5, 6, "Tighten", "old", "Something else entirely")
+	if FindDuplicate(threads, different, DefaultDuplicateThreshold) != nil {
+		t.Error("Suggestions with different proposals should not match")
+	}
+}
+
+func TestDedupePolicyValidate(t *testing.T) {
+	if err := DefaultDedupePolicy().Validate(); err != nil {
+		t.Errorf("Default policy should be valid: %v", err)
+	}
+	for _, bad := range []DedupePolicy{{Mode: "drop", Threshold: 0.9}, {Mode: DedupeSkip, Threshold: 0}, {Mode: DedupeMerge, Threshold: 1.5}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", bad)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package comment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SkippedSuggestion is a suggestion that could not be applied in a batch
+type SkippedSuggestion struct {
+	Suggestion *Comment
+	Reason     error
+}
+
+// OrderSuggestions sorts suggestions so every suggestion comes after the ones
+// it depends on. Independent suggestions are applied bottom-to-top to keep
+// line drift small. Dependencies outside the slice are ignored here.
+// Returns an error naming the suggestions involved if dependencies form a cycle.
+func OrderSuggestions(suggestions []*Comment) ([]*Comment, error) {
+	byID := make(map[string]*Comment, len(suggestions))
+	for _, s := range suggestions {
+		byID[s.ID] = s
+	}
+
+	// Count in-batch dependencies and record reverse edges
+	remaining := make(map[string]int, len(suggestions))
+	dependents := make(map[string][]*Comment)
+	for _, s := range suggestions {
+		for _, dep := range s.DependsOn {
+			if _, ok := byID[dep]; ok && dep != s.ID {
+				remaining[s.ID]++
+				dependents[dep] = append(dependents[dep], s)
+			}
+		}
+	}
+
+	var ready []*Comment
+	for _, s := range suggestions {
+		if remaining[s.ID] == 0 {
+			ready = append(ready, s)
+		}
+	}
+
+	ordered := make([]*Comment, 0, len(suggestions))
+	for len(ready) > 0 {
+		// Highest start line first among the suggestions that are ready
+		sort.SliceStable(ready, func(i, j int) bool {
+			return ready[i].StartLine > ready[j].StartLine
+		})
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, next)
+
+		for _, d := range dependents[next.ID] {
+			remaining[d.ID]--
+			if remaining[d.ID] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	if len(ordered) < len(suggestions) {
+		var cycle []string
+		for _, s := range suggestions {
+			if remaining[s.ID] > 0 {
+				cycle = append(cycle, s.ID)
+			}
+		}
+		return nil, fmt.Errorf("suggestion dependencies form a cycle: %s", strings.Join(cycle, ", "))
+	}
+
+	return ordered, nil
+}
+
+// UnmetDependencies returns the dependencies of s that have not been accepted
+func UnmetDependencies(s *Comment, threads []*Comment) []string {
+	var unmet []string
+	for _, dep := range s.DependsOn {
+		if c := findCommentByID(threads, dep); c == nil || !c.IsAccepted() {
+			unmet = append(unmet, dep)
+		}
+	}
+	return unmet
+}
+
+// ValidateDependencies checks that every dependency names another suggestion
+func ValidateDependencies(s *Comment, threads []*Comment) error {
+	for _, dep := range s.DependsOn {
+		if dep == s.ID {
+			return fmt.Errorf("suggestion cannot depend on itself")
+		}
+		c := findCommentByID(threads, dep)
+		if c == nil {
+			return fmt.Errorf("dependency %s not found", dep)
+		}
+		if !c.IsSuggestion {
+			return fmt.Errorf("dependency %s is not a suggestion", dep)
+		}
+	}
+	return nil
+}
+
+// ProposedLineCount returns how many lines a suggestion inserts when applied
+func ProposedLineCount(s *Comment) int {
+	if s.ProposedText == "" {
+		return 0
+	}
+	return len(strings.Split(s.ProposedText, "\n"))
+}
+
+// RelocateSuggestion moves a suggestion's range to where its original text
+// now appears, if it appears exactly once. Returns true if the range changed.
+func RelocateSuggestion(content string, s *Comment) bool {
+	if s.OriginalText == "" {
+		return false
+	}
+
+	lines := strings.Split(content, "\n")
+	want := strings.Split(s.OriginalText, "\n")
+	found := -1
+
+	for i := 0; i+len(want) <= len(lines); i++ {
+		if strings.Join(lines[i:i+len(want)], "\n") != s.OriginalText {
+			continue
+		}
+		if found >= 0 {
+			return false // Ambiguous
+		}
+		found = i
+	}
+
+	if found < 0 || found+1 == s.StartLine {
+		return false
+	}
+	s.StartLine = found + 1
+	s.EndLine = found + len(want)
+	s.Line = s.StartLine
+	return true
+}
+
+// AcceptSuggestionsInOrder applies and accepts suggestions in dependency order
+// After each application the remaining comments and suggestion ranges are
+// recomputed. A suggestion whose original text no longer matches is relocated
+// if its text appears exactly once. Suggestions that fail, or that depend on a
+// suggestion that was not applied, are skipped and reported.
+func AcceptSuggestionsInOrder(doc *DocumentWithComments, suggestions []*Comment) ([]*Comment, []SkippedSuggestion, error) {
+	ordered, err := OrderSuggestions(suggestions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var applied []*Comment
+	var skipped []SkippedSuggestion
+	failed := map[string]bool{}
+
+	for _, s := range ordered {
+		if dep := firstUnapplied(s, doc.Threads, failed); dep != "" {
+			failed[s.ID] = true
+			skipped = append(skipped, SkippedSuggestion{s, fmt.Errorf("depends on %s, which was not applied", dep)})
+			continue
+		}
+
+		newContent, err := ApplySuggestion(doc.Content, s)
+		if err != nil && RelocateSuggestion(doc.Content, s) {
+			newContent, err = ApplySuggestion(doc.Content, s)
+		}
+		if err != nil {
+			failed[s.ID] = true
+			skipped = append(skipped, SkippedSuggestion{s, err})
+			continue
+		}
+
+		doc.Content = newContent
+		if err := AcceptSuggestion(doc.Threads, s.ID); err != nil {
+			failed[s.ID] = true
+			skipped = append(skipped, SkippedSuggestion{s, err})
+			continue
+		}
+		doc.RecordApplied(s)
+		applied = append(applied, s)
+	}
+
+	return applied, skipped, nil
+}
+
+// firstUnapplied returns the first dependency of s that failed in this batch
+// or is neither accepted nor applied yet
+func firstUnapplied(s *Comment, threads []*Comment, failed map[string]bool) string {
+	for _, dep := range s.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	if unmet := UnmetDependencies(s, threads); len(unmet) > 0 {
+		return unmet[0]
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestSuggestion(id string, start, end int, original, proposed string, deps ...string) *Comment {
+	return &Comment{
+		ID:           id,
+		Line:         start,
+		IsSuggestion: true,
+		StartLine:    start,
+		EndLine:      end,
+		OriginalText: original,
+		ProposedText: proposed,
+		DependsOn:    deps,
+		Replies:      []*Comment{},
+	}
+}
+
+func TestOrderSuggestionsRespectsDependencies(t *testing.T) {
+	a := newTestSuggestion("a", 2, 2, "", "")
+	b := newTestSuggestion("b", 5, 5, "", "", "a")
+	c := newTestSuggestion("c", 9, 9, "", "")
+
+	ordered, err := OrderSuggestions([]*Comment{b, a, c})
+	if err != nil {
+		t.Fatalf("OrderSuggestions failed: %v", err)
+	}
+
+	ids := []string{}
+	for _, s := range ordered {
+		ids = append(ids, s.ID)
+	}
+	// c and a are independent (bottom-to-top), b waits for a
+	if strings.Join(ids, ",") != "c,a,b" {
+		t.Errorf("Expected order c,a,b, got %v", ids)
+	}
+}
+
+func TestOrderSuggestionsDetectsCycle(t *testing.T) {
+	a := newTestSuggestion("a", 1, 1, "", "", "b")
+	b := newTestSuggestion("b", 2, 2, "", "", "a")
+
+	_, err := OrderSuggestions([]*Comment{a, b})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+}
+
+func TestAcceptSuggestionsInOrderStacked(t *testing.T) {
+	doc := &DocumentWithComments{
+		Content: "Title\nold one\nold two\nfooter\ntail",
+	}
+
+	// a rewrites lines 2-3 into one line; b edits the text a produces;
+	// c edits a line below, which moves up once a is applied
+	a := newTestSuggestion("a", 2, 3, "old one\nold two", "new merged line")
+	b := newTestSuggestion("b", 2, 2, "new merged line", "final line", "a")
+	c := newTestSuggestion("c", 5, 5, "tail", "TAIL")
+	doc.Threads = []*Comment{b, c, a}
+
+	applied, skipped, err := AcceptSuggestionsInOrder(doc, []*Comment{b, c, a})
+	if err != nil {
+		t.Fatalf("AcceptSuggestionsInOrder failed: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("Expected nothing skipped, got %v", skipped[0].Reason)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("Expected 3 applied, got %d", len(applied))
+	}
+
+	expected := "Title\nfinal line\nfooter\nTAIL"
+	if doc.Content != expected {
+		t.Errorf("Content mismatch.\nExpected:\n%s\nGot:\n%s", expected, doc.Content)
+	}
+	for _, s := range []*Comment{a, b, c} {
+		if !s.IsAccepted() {
+			t.Errorf("Suggestion %s should be accepted", s.ID)
+		}
+	}
+}
+
+func TestAcceptSuggestionsInOrderSkipsDependents(t *testing.T) {
+	doc := &DocumentWithComments{Content: "one\ntwo\nthree"}
+
+	a := newTestSuggestion("a", 2, 2, "does not match", "x")
+	b := newTestSuggestion("b", 3, 3, "three", "3", "a")
+	pending := newTestSuggestion("p", 1, 1, "one", "1")
+	c := newTestSuggestion("c", 1, 1, "one", "ONE", "p")
+	doc.Threads = []*Comment{a, b, pending, c}
+
+	applied, skipped, err := AcceptSuggestionsInOrder(doc, []*Comment{a, b, c})
+	if err != nil {
+		t.Fatalf("AcceptSuggestionsInOrder failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected nothing applied, got %d", len(applied))
+	}
+	if len(skipped) != 3 {
+		t.Fatalf("Expected 3 skipped, got %d", len(skipped))
+	}
+	if doc.Content != "one\ntwo\nthree" {
+		t.Errorf("Content should be unchanged, got %q", doc.Content)
+	}
+}
+
+func TestRecalculateCommentLinesShiftsPendingSuggestions(t *testing.T) {
+	accepted := true
+	after := newTestSuggestion("after", 10, 12, "", "")
+	inside := newTestSuggestion("inside", 6, 6, "", "")
+	done := newTestSuggestion("done", 10, 10, "", "")
+	done.Accepted = &accepted
+
+	// Lines 5-7 replaced by a single line (delta -2)
+	RecalculateCommentLines([]*Comment{after, inside, done}, 5, 7, 1)
+
+	if after.StartLine != 8 || after.EndLine != 10 {
+		t.Errorf("Expected after range 8-10, got %d-%d", after.StartLine, after.EndLine)
+	}
+	if inside.StartLine != 5 || inside.EndLine != 5 {
+		t.Errorf("Expected inside range clamped to 5-5, got %d-%d", inside.StartLine, inside.EndLine)
+	}
+	if done.StartLine != 10 {
+		t.Errorf("Accepted suggestion range should not move, got %d", done.StartLine)
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	s := newTestSuggestion("s", 1, 1, "", "", "missing")
+	if err := ValidateDependencies(s, []*Comment{s}); err == nil {
+		t.Error("Expected error for missing dependency")
+	}
+
+	note := &Comment{ID: "n", Replies: []*Comment{}}
+	s.DependsOn = []string{"n"}
+	if err := ValidateDependencies(s, []*Comment{s, note}); err == nil {
+		t.Error("Expected error for non-suggestion dependency")
+	}
+
+	s.DependsOn = []string{"s"}
+	if err := ValidateDependencies(s, []*Comment{s}); err == nil {
+		t.Error("Expected error for self dependency")
+	}
+}
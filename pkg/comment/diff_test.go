@@ -0,0 +1,47 @@
+package comment
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj"
+	newText := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+
+	got := UnifiedDiff("doc.md", "doc.md (preview)", oldText, newText, 1)
+	expected := `--- doc.md
++++ doc.md (preview)
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -10 +10,2 @@
+ j
++k
+`
+	if got != expected {
+		t.Errorf("Diff mismatch.\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestUnifiedDiffIdentical(t *testing.T) {
+	if got := UnifiedDiff("a", "b", "same\ntext", "same\ntext", 3); got != "" {
+		t.Errorf("Expected empty diff, got %q", got)
+	}
+}
+
+func TestUnifiedDiffMergesNearbyChanges(t *testing.T) {
+	got := UnifiedDiff("x", "y", "1\n2\n3\n4", "1\nTWO\n3\nFOUR", 3)
+	expected := `--- x
++++ y
+@@ -1,4 +1,4 @@
+ 1
+-2
++TWO
+ 3
+-4
++FOUR
+`
+	if got != expected {
+		t.Errorf("Diff mismatch.\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,181 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// DisplayLayout maps document positions (line, column) to word-wrapped display rows
+// Frontends (TUI, web, editor plugins) can use it to keep cursor and scroll positions
+// consistent with how a document is rendered at a given width.
+//
+// Lines, columns, and rows follow the document convention:
+// lines and columns are 1-indexed, display rows are 0-indexed.
+type DisplayLayout struct {
+	Width int // Wrap width in columns (<= 0 disables wrapping)
+
+	lines     []layoutLine
+	totalRows int
+}
+
+// layoutLine holds the wrapped rows for a single document line
+type layoutLine struct {
+	startRow  int   // First display row of this line
+	rowStarts []int // Rune offset (0-based) in the original line where each row begins
+	rowLens   []int // Rune length of each row
+	runeCount int   // Rune length of the original line
+}
+
+// NewDisplayLayout computes the wrapped layout of content at the given width
+// Wrapping matches the word-wrapping used by the TUI document view.
+func NewDisplayLayout(content string, width int) *DisplayLayout {
+	layout := &DisplayLayout{Width: width}
+
+	for _, line := range strings.Split(content, "\n") {
+		ll := layoutLine{startRow: layout.totalRows}
+		runes := []rune(line)
+		ll.runeCount = len(runes)
+
+		if width <= 0 {
+			ll.rowStarts = []int{0}
+			ll.rowLens = []int{len(runes)}
+		} else {
+			ll.rowStarts, ll.rowLens = mapWrappedRows(runes, strings.Split(wordwrap.String(line, width), "\n"))
+		}
+
+		layout.totalRows += len(ll.rowStarts)
+		layout.lines = append(layout.lines, ll)
+	}
+
+	return layout
+}
+
+// mapWrappedRows locates each wrapped row within the original line
+// Word wrapping drops whitespace at break points, so whitespace is skipped
+// before matching every row after the first.
+func mapWrappedRows(original []rune, rows []string) ([]int, []int) {
+	starts := make([]int, 0, len(rows))
+	lens := make([]int, 0, len(rows))
+
+	pos := 0
+	for i, row := range rows {
+		rowRunes := []rune(row)
+		if i > 0 {
+			for pos < len(original) && unicode.IsSpace(original[pos]) && !hasRunePrefix(original[pos:], rowRunes) {
+				pos++
+			}
+		}
+		starts = append(starts, pos)
+		lens = append(lens, len(rowRunes))
+		pos += len(rowRunes)
+	}
+
+	return starts, lens
+}
+
+// hasRunePrefix reports whether s begins with prefix
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// LineCount returns the number of document lines in the layout
+func (l *DisplayLayout) LineCount() int {
+	return len(l.lines)
+}
+
+// RowCount returns the total number of display rows
+func (l *DisplayLayout) RowCount() int {
+	return l.totalRows
+}
+
+// RowsForLine returns how many display rows a line occupies (0 if out of range)
+func (l *DisplayLayout) RowsForLine(line int) int {
+	if line < 1 || line > len(l.lines) {
+		return 0
+	}
+	return len(l.lines[line-1].rowStarts)
+}
+
+// LineStartRow returns the display row where the given line begins
+// Lines before the document clamp to 0; lines past the end clamp to RowCount().
+func (l *DisplayLayout) LineStartRow(line int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(l.lines) {
+		return l.totalRows
+	}
+	return l.lines[line-1].startRow
+}
+
+// PositionToRow translates a document position into a display row and the
+// 1-indexed column within that row. Columns that fall on whitespace dropped at
+// a wrap point map to the end of the preceding row.
+func (l *DisplayLayout) PositionToRow(line, column int) (int, int, error) {
+	if line < 1 || line > len(l.lines) {
+		return 0, 0, fmt.Errorf("line %d out of range (1-%d)", line, len(l.lines))
+	}
+
+	ll := l.lines[line-1]
+	if column < 1 || column > ll.runeCount+1 {
+		return 0, 0, fmt.Errorf("column %d out of range (1-%d) on line %d", column, ll.runeCount+1, line)
+	}
+
+	offset := column - 1
+	rowIdx := 0
+	for i := len(ll.rowStarts) - 1; i >= 0; i-- {
+		if offset >= ll.rowStarts[i] {
+			rowIdx = i
+			break
+		}
+	}
+
+	rowColumn := offset - ll.rowStarts[rowIdx] + 1
+	if rowColumn > ll.rowLens[rowIdx]+1 {
+		rowColumn = ll.rowLens[rowIdx] + 1
+	}
+
+	return ll.startRow + rowIdx, rowColumn, nil
+}
+
+// RowToPosition translates a display row and 1-indexed column within that row
+// back into a document (line, column) position. Columns past the end of the
+// row clamp to the position just after its last character.
+func (l *DisplayLayout) RowToPosition(row, rowColumn int) (int, int, error) {
+	if row < 0 || row >= l.totalRows {
+		return 0, 0, fmt.Errorf("row %d out of range (0-%d)", row, l.totalRows-1)
+	}
+	if rowColumn < 1 {
+		return 0, 0, fmt.Errorf("invalid row column: %d", rowColumn)
+	}
+
+	// Binary search for the line containing this row
+	lo, hi := 0, len(l.lines)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if l.lines[mid].startRow <= row {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	ll := l.lines[lo]
+	rowIdx := row - ll.startRow
+	if rowColumn > ll.rowLens[rowIdx]+1 {
+		rowColumn = ll.rowLens[rowIdx] + 1
+	}
+
+	return lo + 1, ll.rowStarts[rowIdx] + rowColumn, nil
+}
@@ -0,0 +1,117 @@
+package comment
+
+import (
+	"testing"
+)
+
+func TestDisplayLayoutRowCounts(t *testing.T) {
+	content := "short\nhello world foo bar\n\nend"
+	layout := NewDisplayLayout(content, 6)
+
+	if layout.LineCount() != 4 {
+		t.Fatalf("LineCount = %d, want 4", layout.LineCount())
+	}
+
+	// "hello world foo bar" wraps to 4 rows at width 6
+	if got := layout.RowsForLine(2); got != 4 {
+		t.Errorf("RowsForLine(2) = %d, want 4", got)
+	}
+
+	if layout.RowCount() != 7 {
+		t.Errorf("RowCount = %d, want 7", layout.RowCount())
+	}
+
+	if got := layout.LineStartRow(3); got != 5 {
+		t.Errorf("LineStartRow(3) = %d, want 5", got)
+	}
+
+	// Out of range lines clamp
+	if got := layout.LineStartRow(0); got != 0 {
+		t.Errorf("LineStartRow(0) = %d, want 0", got)
+	}
+	if got := layout.LineStartRow(99); got != layout.RowCount() {
+		t.Errorf("LineStartRow(99) = %d, want %d", got, layout.RowCount())
+	}
+}
+
+func TestDisplayLayoutNoWrap(t *testing.T) {
+	layout := NewDisplayLayout("a very long line that would wrap\nb", 0)
+
+	if layout.RowCount() != 2 {
+		t.Errorf("RowCount = %d, want 2", layout.RowCount())
+	}
+}
+
+func TestDisplayLayoutPositionToRow(t *testing.T) {
+	layout := NewDisplayLayout("intro\nhello world foo", 6)
+
+	// "world" starts at column 7 of line 2, which is the second row of that line
+	row, col, err := layout.PositionToRow(2, 7)
+	if err != nil {
+		t.Fatalf("PositionToRow failed: %v", err)
+	}
+	if row != 2 || col != 1 {
+		t.Errorf("PositionToRow(2, 7) = (%d, %d), want (2, 1)", row, col)
+	}
+
+	// The space dropped at the wrap point maps to the end of the first row
+	row, col, err = layout.PositionToRow(2, 6)
+	if err != nil {
+		t.Fatalf("PositionToRow failed: %v", err)
+	}
+	if row != 1 || col != 6 {
+		t.Errorf("PositionToRow(2, 6) = (%d, %d), want (1, 6)", row, col)
+	}
+
+	if _, _, err := layout.PositionToRow(3, 1); err == nil {
+		t.Error("Expected error for out of range line")
+	}
+	if _, _, err := layout.PositionToRow(1, 50); err == nil {
+		t.Error("Expected error for out of range column")
+	}
+}
+
+func TestDisplayLayoutRowToPosition(t *testing.T) {
+	layout := NewDisplayLayout("intro\nhello world foo", 6)
+
+	line, col, err := layout.RowToPosition(3, 2)
+	if err != nil {
+		t.Fatalf("RowToPosition failed: %v", err)
+	}
+	// Row 3 is "foo", which begins at column 13 of line 2
+	if line != 2 || col != 14 {
+		t.Errorf("RowToPosition(3, 2) = (%d, %d), want (2, 14)", line, col)
+	}
+
+	// Columns past the end of a row clamp
+	line, col, err = layout.RowToPosition(0, 40)
+	if err != nil {
+		t.Fatalf("RowToPosition failed: %v", err)
+	}
+	if line != 1 || col != 6 {
+		t.Errorf("RowToPosition(0, 40) = (%d, %d), want (1, 6)", line, col)
+	}
+
+	if _, _, err := layout.RowToPosition(10, 1); err == nil {
+		t.Error("Expected error for out of range row")
+	}
+}
+
+func TestDisplayLayoutRoundTrip(t *testing.T) {
+	content := "  indented text that keeps going\nnext line here"
+	layout := NewDisplayLayout(content, 8)
+
+	for row := 0; row < layout.RowCount(); row++ {
+		line, col, err := layout.RowToPosition(row, 1)
+		if err != nil {
+			t.Fatalf("RowToPosition(%d, 1) failed: %v", row, err)
+		}
+		gotRow, gotCol, err := layout.PositionToRow(line, col)
+		if err != nil {
+			t.Fatalf("PositionToRow(%d, %d) failed: %v", line, col, err)
+		}
+		if gotRow != row || gotCol != 1 {
+			t.Errorf("round trip for row %d gave (%d, %d)", row, gotRow, gotCol)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package comment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of problem found by Diagnose
+const (
+	ProblemMalformed       = "malformed"        // Sidecar isn't valid JSON or has an unsupported version
+	ProblemMissingMarkdown = "missing-markdown" // Sidecar whose markdown file is gone
+	ProblemStale           = "stale"            // Sidecar's documentHash doesn't match the markdown
+	ProblemDuplicateID     = "duplicate-id"     // Several comments in one sidecar share an ID
+	ProblemOrphanFile      = "orphan-file"      // Archive, backup, or drafts file whose markdown is gone
+	ProblemTempFile        = "temp-file"        // Temporary file left behind by an interrupted save
+)
+
+// tempFileAge is how old a temporary file must be before it counts as left
+// behind rather than part of a save in progress
+const tempFileAge = time.Minute
+
+// Problem is something wrong with the comment files of one document
+type Problem struct {
+	Kind     string
+	Document string // Markdown file the problem belongs to
+	Path     string // File with the problem
+	Message  string
+	Fixable  bool // FixProblem can repair it without losing comments
+}
+
+// Diagnose checks every comment file under root (and under the central
+// sidecar directory's mirror of root, if one is set): sidecars that don't
+// parse, whose markdown is gone, whose hash is stale, or that repeat comment
+// IDs, plus archives, backups, and drafts left without a markdown file and
+// temporary files left by interrupted saves. Problems are sorted by path.
+func Diagnose(ctx context.Context, root string) ([]Problem, error) {
+	var problems []Problem
+
+	roots := []string{root}
+	if dir := sidecarDir(root); dir != root {
+		if _, err := os.Stat(dir); err == nil {
+			roots = append(roots, dir)
+		}
+	}
+
+	for i, walkRoot := range roots {
+		err := filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != walkRoot && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// Map the file's directory back to the documents' directory
+			docDir := filepath.Dir(path)
+			if i > 0 {
+				rel, err := filepath.Rel(walkRoot, docDir)
+				if err != nil {
+					return err
+				}
+				docDir = filepath.Join(root, rel)
+			} else if abs, err := filepath.Abs(path); err == nil && len(roots) > 1 && isWithin(roots[1], abs) {
+				return nil // The central directory is walked on its own
+			}
+
+			problems = append(problems, diagnoseFile(path, docDir, d)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+	return problems, nil
+}
+
+// diagnoseFile checks one file found by Diagnose
+func diagnoseFile(path, docDir string, d fs.DirEntry) []Problem {
+	name := d.Name()
+
+	// Temporary files are named ".<target>.tmp-<random>" (see writeFileAtomic)
+	if i := strings.Index(name, ".tmp-"); strings.HasPrefix(name, ".") && i > 1 {
+		target := name[1:i]
+		mdName, ok := markdownName(docDir, target)
+		if !ok {
+			mdName, _, ok = auxiliaryOwner(docDir, target)
+		}
+		if !ok {
+			mdName, ok = target, isMarkdownName(target)
+		}
+		info, err := d.Info()
+		if !ok || err != nil || time.Since(info.ModTime()) < tempFileAge {
+			return nil
+		}
+		return []Problem{{
+			Kind:     ProblemTempFile,
+			Document: filepath.Join(docDir, mdName),
+			Path:     path,
+			Message:  "temporary file left by an interrupted save",
+			Fixable:  true,
+		}}
+	}
+
+	if mdName, kind, ok := auxiliaryOwner(docDir, name); ok {
+		mdPath := filepath.Join(docDir, mdName)
+		if fileExists(mdPath) {
+			return nil
+		}
+		return []Problem{{
+			Kind:     ProblemOrphanFile,
+			Document: mdPath,
+			Path:     path,
+			Message:  fmt.Sprintf("%s for a markdown file that no longer exists", kind),
+		}}
+	}
+
+	if mdName, ok := markdownName(docDir, name); ok {
+		return diagnoseSidecar(filepath.Join(docDir, mdName), path)
+	}
+	return nil
+}
+
+// diagnoseSidecar checks a sidecar against its markdown file
+func diagnoseSidecar(mdPath, sidecarPath string) []Problem {
+	problem := func(kind, message string, fixable bool) Problem {
+		return Problem{Kind: kind, Document: mdPath, Path: sidecarPath, Message: message, Fixable: fixable}
+	}
+
+	var problems []Problem
+	data, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		return []Problem{problem(ProblemMalformed, fmt.Sprintf("unreadable: %v", err), false)}
+	}
+	var storage StorageFormat
+	if err := json.Unmarshal(data, &storage); err != nil {
+		problems = append(problems, problem(ProblemMalformed, fmt.Sprintf("invalid JSON: %v", err), false))
+	} else if storage.Version != StorageVersion {
+		problems = append(problems, problem(ProblemMalformed, fmt.Sprintf("unsupported storage version %q (expected %s)", storage.Version, StorageVersion), false))
+	}
+
+	if !fileExists(mdPath) {
+		problems = append(problems, problem(ProblemMissingMarkdown, fmt.Sprintf("%s not found; restore or rename it, or delete the sidecar", filepath.Base(mdPath)), false))
+		return problems
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	for _, dup := range duplicateIDs(storage.Threads) {
+		problems = append(problems, problem(ProblemDuplicateID,
+			fmt.Sprintf("comment ID %s is used %d times", dup.id, dup.count), dup.fixable))
+	}
+
+	content, _, _, err := readDocument(mdPath)
+	if err != nil {
+		problems = append(problems, Problem{Kind: ProblemMalformed, Document: mdPath, Path: mdPath, Message: err.Error()})
+	} else if ComputeDocumentHash(content) != storage.DocumentHash {
+		problems = append(problems, problem(ProblemStale, "the markdown changed since comments were last validated", true))
+	}
+	return problems
+}
+
+// duplicateID is a comment ID used more than once in a sidecar
+type duplicateID struct {
+	id      string
+	count   int
+	fixable bool // Some later copy is unsigned and can take a new ID
+}
+
+// duplicateIDs finds IDs shared by several comments, in document order
+func duplicateIDs(threads []*Comment) []duplicateID {
+	var order []string
+	seen := map[string]*duplicateID{}
+	for _, c := range flattenReplies(threads) {
+		dup, ok := seen[c.ID]
+		if !ok {
+			seen[c.ID] = &duplicateID{id: c.ID, count: 1}
+			continue
+		}
+		if dup.count == 1 {
+			order = append(order, c.ID)
+		}
+		dup.count++
+		dup.fixable = dup.fixable || c.Signature == ""
+	}
+
+	dups := make([]duplicateID, 0, len(order))
+	for _, id := range order {
+		dups = append(dups, *seen[id])
+	}
+	return dups
+}
+
+// auxiliaryOwner returns the markdown file name that an archive, backup,
+// drafts, or queue file name for a document in dir belongs to, and what kind
+// of file it is
+func auxiliaryOwner(dir, name string) (mdName, kind string, ok bool) {
+	lower := strings.ToLower(name)
+	for _, suffix := range sidecarSuffixes(dir) {
+		stem := strings.ToLower(strings.TrimSuffix(suffix, ".json"))
+		if i := strings.Index(lower, strings.ToLower(suffix)+".backup."); i > 0 {
+			return name[:i], "backup", true
+		}
+		if i := strings.Index(lower, stem+".archived."); i > 0 {
+			return name[:i], "archive", true
+		}
+		if hasSuffixFold(name, stem+".drafts.json") {
+			return name[:len(name)-len(stem+".drafts.json")], "drafts", true
+		}
+		if hasSuffixFold(name, stem+".queue.json") {
+			return name[:len(name)-len(stem+".queue.json")], "queue", true
+		}
+	}
+	return "", "", false
+}
+
+// FixProblem makes the safe repair for a fixable problem: a stale sidecar
+// is revalidated against the markdown (orphaning comments whose lines are
+// gone) and stamped with its current hash; duplicated IDs after the first
+// are replaced with new ones, except on signed comments; a left-behind
+// temporary file is deleted. Returns a description of what was done.
+func FixProblem(p Problem) (string, error) {
+	if !p.Fixable {
+		return "", fmt.Errorf("%s problems can't be fixed automatically", p.Kind)
+	}
+
+	switch p.Kind {
+	case ProblemTempFile:
+		if err := os.Remove(p.Path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove temporary file: %w", err)
+		}
+		return "removed " + p.Path, nil
+
+	case ProblemStale, ProblemDuplicateID:
+		doc, err := LoadFromSidecar(p.Document)
+		if err != nil {
+			return "", err
+		}
+		renamed := renumberDuplicates(doc.Threads)
+		if err := SaveSidecar(p.Document, doc); err != nil {
+			return "", err
+		}
+		if p.Kind == ProblemStale {
+			return "revalidated comments", nil
+		}
+		return fmt.Sprintf("gave %d comment(s) new IDs", renamed), nil
+	}
+
+	return "", fmt.Errorf("unknown problem kind %q", p.Kind)
+}
+
+// renumberDuplicates gives every unsigned comment whose ID was already used
+// earlier in the document a new ID, and returns how many were changed
+func renumberDuplicates(threads []*Comment) int {
+	all := flattenReplies(threads)
+	used := make(map[string]bool, len(all))
+	for _, c := range all {
+		used[c.ID] = false
+	}
+
+	renamed := 0
+	for _, c := range all {
+		if !used[c.ID] || c.Signature != "" {
+			used[c.ID] = true
+			continue
+		}
+		id := generateID("c")
+		for _, taken := used[id]; taken; _, taken = used[id] {
+			id = generateID("c")
+		}
+		log().Info("renumbered duplicate comment", "from", c.ID, "to", id)
+		c.ID = id
+		used[id] = true
+		renamed++
+	}
+	return renamed
+}
@@ -0,0 +1,120 @@
+package comment
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiagnoseAndFix(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// healthy.md has nothing wrong
+	healthy := write("healthy.md", "line one\n")
+	doc, _ := LoadFromSidecar(healthy)
+	doc.Threads = append(doc.Threads, NewComment("alice", 1, "fine"))
+	if err := SaveToSidecar(healthy, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// stale.md changed after it was commented on, and repeats an ID
+	stale := write("stale.md", "line one\nline two\nline three\n")
+	doc, _ = LoadFromSidecar(stale)
+	first := NewComment("alice", 3, "first")
+	second := NewComment("bob", 1, "second")
+	second.ID = first.ID
+	doc.Threads = append(doc.Threads, first, second)
+	if err := SaveToSidecar(stale, doc); err != nil {
+		t.Fatal(err)
+	}
+	write("stale.md", "line one\n")
+
+	write("gone.md.comments.json", `{"version": "2.0", "threads": []}`)
+	write("broken.md", "x\n")
+	write("broken.md.comments.json", `{"version": `)
+	write("deleted.md.comments.archived.resolved", "[]")
+	tmp := write(".healthy.md.comments.json.tmp-123", "{")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(tmp, old, old); err != nil {
+		t.Fatal(err)
+	}
+	write(".unrelated.tmp-1", "")
+
+	problems, err := Diagnose(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]Problem{}
+	for _, p := range problems {
+		kinds[p.Kind+" "+filepath.Base(p.Path)] = p
+	}
+	want := map[string]bool{
+		ProblemTempFile + " .healthy.md.comments.json.tmp-123":       true,
+		ProblemMalformed + " broken.md.comments.json":                false,
+		ProblemOrphanFile + " deleted.md.comments.archived.resolved": false,
+		ProblemMissingMarkdown + " gone.md.comments.json":            false,
+		ProblemDuplicateID + " stale.md.comments.json":               true,
+		ProblemStale + " stale.md.comments.json":                     true,
+	}
+	if len(problems) != len(want) {
+		t.Errorf("Expected %d problems, got %d: %+v", len(want), len(problems), problems)
+	}
+	for key, fixable := range want {
+		p, ok := kinds[key]
+		if !ok {
+			t.Errorf("Missing problem %q", key)
+			continue
+		}
+		if p.Fixable != fixable {
+			t.Errorf("%s: fixable = %v, want %v", key, p.Fixable, fixable)
+		}
+	}
+
+	for _, p := range problems {
+		if !p.Fixable {
+			if _, err := FixProblem(p); err == nil {
+				t.Errorf("Expected %s not to be fixable", p.Kind)
+			}
+			continue
+		}
+		if _, err := FixProblem(p); err != nil {
+			t.Errorf("Fixing %s failed: %v", p.Kind, err)
+		}
+	}
+
+	problems, err = Diagnose(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		if p.Fixable {
+			t.Errorf("Expected %s in %s to be fixed", p.Kind, p.Path)
+		}
+	}
+	if len(problems) != 3 {
+		t.Errorf("Expected the 3 unfixable problems to remain, got %+v", problems)
+	}
+
+	doc, err = ReadSidecar(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Threads) != 2 || doc.Threads[0].ID == doc.Threads[1].ID {
+		t.Errorf("Expected distinct IDs after the fix, got %+v", doc.Threads)
+	}
+	for _, c := range doc.Threads {
+		if c.Text == "first" && c.Status != "orphaned" {
+			t.Error("Expected the comment on the removed line to be orphaned by revalidation")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Drafts are comments a reviewer has written but not shared yet. They live in
+// a file next to the sidecar, readable only by its owner (keep it out of
+// version control), until they are published into the sidecar, so a full
+// review pass can be composed before anyone sees it.
+
+// GetDraftsPath returns the drafts file for a markdown file
+func GetDraftsPath(mdPath string) string {
+	return strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".drafts.json"
+}
+
+// LoadDrafts reads the drafts for a markdown file, returning an empty set if
+// there are none
+func LoadDrafts(mdPath string) (*StorageFormat, error) {
+	drafts := &StorageFormat{
+		Version: StorageVersion,
+		Threads: []*Comment{},
+	}
+
+	path := GetDraftsPath(mdPath)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return drafts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+
+	if err := json.Unmarshal(data, drafts); err != nil {
+		return nil, fmt.Errorf("failed to parse drafts %s: %w", path, err)
+	}
+	return drafts, nil
+}
+
+// SaveDrafts writes the drafts for a markdown file, readable only by its
+// owner. The file is removed once it no longer holds any drafts.
+func SaveDrafts(mdPath string, drafts *StorageFormat) error {
+	path := GetDraftsPath(mdPath)
+	if len(drafts.Threads) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove drafts: %w", err)
+		}
+		log().Debug("removed empty drafts", "file", mdPath, "drafts", path)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal drafts: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write drafts: %w", err)
+	}
+	// Files written by earlier versions were readable by everyone
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to write drafts: %w", err)
+	}
+	log().Info("saved drafts", "file", mdPath, "drafts", path, "threads", len(drafts.Threads))
+	return nil
+}
+
+// AddDraft saves c as a draft of mdPath, written against the document content
+func AddDraft(mdPath string, c *Comment, content string) error {
+	drafts, err := LoadDrafts(mdPath)
+	if err != nil {
+		return err
+	}
+
+	drafts.Threads = append(drafts.Threads, c)
+	drafts.DocumentHash = ComputeDocumentHash(content)
+	drafts.LastValidated = now()
+
+	return SaveDrafts(mdPath, drafts)
+}
+
+// SelectDrafts returns the drafts written by author (every draft if author is empty)
+func SelectDrafts(drafts []*Comment, author string) []*Comment {
+	selected := []*Comment{}
+	for _, d := range drafts {
+		if author == "" || strings.EqualFold(d.Author, author) {
+			selected = append(selected, d)
+		}
+	}
+	return selected
+}
+
+// PublishDrafts adds drafts to doc's threads, refreshing their section
+// metadata against the current content. Returns true if the document has
+// changed since the drafts were written, in which case their line numbers
+// may need checking. Neither file is saved; callers save the sidecar and
+// then remove the published drafts with RemoveDrafts.
+func PublishDrafts(doc *DocumentWithComments, drafts *StorageFormat, selected []*Comment) bool {
+	for _, d := range selected {
+		UpdateCommentSection(d, doc)
+		doc.Threads = append(doc.Threads, d)
+	}
+	return drafts.DocumentHash != "" && drafts.DocumentHash != ComputeDocumentHash(doc.Content)
+}
+
+// RemoveDrafts deletes the drafts with the given IDs
+func RemoveDrafts(mdPath string, ids []string) error {
+	drafts, err := LoadDrafts(mdPath)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	remaining := []*Comment{}
+	for _, d := range drafts.Threads {
+		if !remove[d.ID] {
+			remaining = append(remaining, d)
+		}
+	}
+	drafts.Threads = remaining
+
+	return SaveDrafts(mdPath, drafts)
+}
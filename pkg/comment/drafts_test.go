@@ -0,0 +1,103 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDraftsRoundTripAndPublish(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+	content := "# Title\n\nFirst line\nSecond line"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetDraftsPath(mdPath); got != mdPath+".comments.drafts.json" {
+		t.Errorf("GetDraftsPath = %s", got)
+	}
+
+	alice := NewComment("alice", 3, "Draft from alice")
+	bob := NewComment("bob", 4, "Draft from bob")
+	for _, c := range []*Comment{alice, bob} {
+		if err := AddDraft(mdPath, c, content); err != nil {
+			t.Fatalf("AddDraft failed: %v", err)
+		}
+	}
+
+	drafts, err := LoadDrafts(mdPath)
+	if err != nil {
+		t.Fatalf("LoadDrafts failed: %v", err)
+	}
+	if len(drafts.Threads) != 2 {
+		t.Fatalf("Expected 2 drafts, got %d", len(drafts.Threads))
+	}
+
+	// Drafts stay out of the shared sidecar
+	if SidecarExists(mdPath) {
+		t.Error("Adding drafts should not create the sidecar")
+	}
+
+	selected := SelectDrafts(drafts.Threads, "Alice")
+	if len(selected) != 1 || selected[0].ID != alice.ID {
+		t.Fatalf("Expected only alice's draft, got %v", selected)
+	}
+
+	doc := &DocumentWithComments{Content: content, Threads: []*Comment{}}
+	if changed := PublishDrafts(doc, drafts, selected); changed {
+		t.Error("Document hasn't changed since the drafts were written")
+	}
+	if len(doc.Threads) != 1 || doc.Threads[0].SectionPath != "Title" {
+		t.Errorf("Expected the published draft with section metadata, got %+v", doc.Threads)
+	}
+
+	if err := RemoveDrafts(mdPath, []string{alice.ID}); err != nil {
+		t.Fatalf("RemoveDrafts failed: %v", err)
+	}
+	drafts, _ = LoadDrafts(mdPath)
+	if len(drafts.Threads) != 1 || drafts.Threads[0].ID != bob.ID {
+		t.Errorf("Expected bob's draft to remain, got %v", drafts.Threads)
+	}
+
+	// Changing the document is reported on publish
+	doc.Content = content + "\nThird line"
+	if changed := PublishDrafts(doc, drafts, drafts.Threads); !changed {
+		t.Error("Expected the document change to be reported")
+	}
+
+	// The file goes away with the last draft
+	if err := RemoveDrafts(mdPath, []string{bob.ID}); err != nil {
+		t.Fatalf("RemoveDrafts failed: %v", err)
+	}
+	if _, err := os.Stat(GetDraftsPath(mdPath)); !os.IsNotExist(err) {
+		t.Error("Drafts file should be removed once empty")
+	}
+}
+
+func TestDraftsReadableOnlyByOwner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	content := "# Title\n\nLine"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A drafts file written by an earlier version
+	if err := os.WriteFile(GetDraftsPath(mdPath), []byte(`{"version": "2.0", "threads": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddDraft(mdPath, NewComment("alice", 3, "Unfinished"), content); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(GetDraftsPath(mdPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected drafts readable only by their owner, got %v", perm)
+	}
+}
@@ -0,0 +1,82 @@
+package comment
+
+import "fmt"
+
+// When a document gets shorter outside the tool, comments past its new end
+// are orphaned at once by default. A drift policy gives them a grace period
+// instead: they are moved to the last line and marked drifted, a warning
+// rather than an error, and only orphaned if the document changes Grace more
+// times without anyone reattaching them.
+
+// DriftPolicy decides what validation does with a comment whose line is past
+// the end of the document. The zero value orphans it at once.
+type DriftPolicy struct {
+	// Grace is how many validations of a changed document a comment stays
+	// drifted before it is orphaned; 0 orphans at once
+	Grace int
+
+	// MaxDrift orphans at once comments more than this many lines past the
+	// end, where the last line is unlikely to be near their text (0: no limit)
+	MaxDrift int
+}
+
+// IsDrifted returns true if validation moved the comment to the end of the
+// document and it hasn't been reattached since
+func (c *Comment) IsDrifted() bool {
+	return c.DriftedFrom > 0
+}
+
+// ClearDrift forgets that the comment drifted, e.g. once it is reattached
+func (c *Comment) ClearDrift() {
+	c.DriftedFrom = 0
+	c.DriftChecks = 0
+}
+
+// driftComment applies the policy to a comment in a document of lineCount
+// lines. A comment past the end is moved to the last line and marked
+// drifted, and a drifted comment counts the validation if the document
+// changed. Returns the issue to report, or an orphan reason when the comment
+// is too far out or has used up its grace ("" and no issue when neither
+// applies, including comments the policy leaves to the orphan checks).
+func driftComment(c *Comment, lineCount int, changed bool, policy DriftPolicy) (*ValidationIssue, string) {
+	last := c.Line
+	if c.IsRange() {
+		last = c.EndLine
+	}
+	over := last - lineCount
+	if policy.Grace <= 0 || c.IsSuggestion || c.Line < 1 || (over <= 0 && !c.IsDrifted()) {
+		return nil, ""
+	}
+	if policy.MaxDrift > 0 && over > policy.MaxDrift {
+		return nil, ""
+	}
+
+	from := c.Line
+	if over > 0 {
+		if !c.IsDrifted() {
+			c.DriftedFrom = c.Line
+		}
+		c.Line = min(c.Line, lineCount)
+		if c.IsRange() {
+			c.EndLine = lineCount
+		}
+	}
+	if over > 0 || changed {
+		c.DriftChecks++
+	}
+
+	if c.DriftChecks > policy.Grace {
+		reason := fmt.Sprintf("Line %d out of bounds; drifted to line %d for %d validation(s)", c.DriftedFrom, c.Line, c.DriftChecks-1)
+		c.OriginalLine = c.DriftedFrom
+		c.ClearDrift()
+		return nil, reason
+	}
+	if over <= 0 {
+		return nil, ""
+	}
+	return &ValidationIssue{
+		Severity:  "warning",
+		Message:   fmt.Sprintf("Comment drifted: line %d out of bounds (document has %d lines); moved to line %d, orphaned after %d more change(s)", from, lineCount, c.Line, policy.Grace-c.DriftChecks+1),
+		CommentID: c.ID,
+	}, ""
+}
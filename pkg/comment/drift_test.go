@@ -0,0 +1,75 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDriftGracePeriod(t *testing.T) {
+	defer SetSettingsFunc(func(string) Settings {
+		return Settings{Save: SaveOptions{Drift: DriftPolicy{Grace: 2, MaxDrift: 5}}}
+	})()
+
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	content := "Line 1\nLine 2\nLine 3\nLine 4\nLine 5\nLine 6"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	near := NewComment("alice", 5, "Near the end")
+	far := NewComment("bob", 6, "Far from the end")
+	doc := &DocumentWithComments{Content: content, Threads: []*Comment{near, far}}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(content string) (*Comment, *Comment) {
+		t.Helper()
+		if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := LoadFromSidecar(mdPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc.FindThreadByID(near.ID), doc.FindThreadByID(far.ID)
+	}
+
+	// Line 5 is 4 lines past the end and drifts; line 6 is 5 past, within max_drift
+	gotNear, gotFar := load("Line 1")
+	if gotNear.IsOrphaned() || !gotNear.IsDrifted() || gotNear.Line != 1 || gotNear.DriftedFrom != 5 {
+		t.Fatalf("Expected line 5 drifted to line 1, got line %d, status %s, from %d", gotNear.Line, gotNear.Status, gotNear.DriftedFrom)
+	}
+	if gotFar.IsOrphaned() {
+		t.Errorf("Expected line 6 drifted, got orphaned: %s", gotFar.OrphanedReason)
+	}
+
+	// Loading unchanged content doesn't use up the grace
+	gotNear, _ = load("Line 1")
+	if gotNear.IsOrphaned() || gotNear.DriftChecks != 1 {
+		t.Fatalf("Expected 1 drift check, got %d (%s)", gotNear.DriftChecks, gotNear.Status)
+	}
+
+	gotNear, _ = load("Line 1 edited")
+	if gotNear.IsOrphaned() {
+		t.Fatal("Expected the comment to survive a second change")
+	}
+	gotNear, _ = load("Line 1 edited again")
+	if !gotNear.IsOrphaned() || gotNear.IsDrifted() || gotNear.OriginalLine != 5 {
+		t.Errorf("Expected orphaned after the grace, keeping line 5; got %s, drifted %v, original %d", gotNear.Status, gotNear.IsDrifted(), gotNear.OriginalLine)
+	}
+}
+
+func TestDriftMaxDriftOrphansAtOnce(t *testing.T) {
+	c := NewComment("alice", 20, "Way out")
+	issue, reason := driftComment(c, 3, true, DriftPolicy{Grace: 3, MaxDrift: 5})
+	if issue != nil || reason != "" || c.IsDrifted() || c.Line != 20 {
+		t.Errorf("Expected the comment left to the orphan checks, got %+v, %q, line %d", issue, reason, c.Line)
+	}
+
+	// No policy: nothing drifts
+	c = NewComment("alice", 4, "Just out")
+	if issue, _ := driftComment(c, 3, true, DriftPolicy{}); issue != nil || c.IsDrifted() {
+		t.Error("Expected no drift without a grace period")
+	}
+}
@@ -0,0 +1,145 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Embedded storage keeps the thread store inside the markdown file, for
+// wikis and tools that only accept a single file. The store is the sidecar
+// JSON in a fenced "comments" block at the very end of the document, after a
+// blank line:
+//
+//	# Title
+//
+//	Body text
+//
+//	```comments
+//	{"version": "2.0", ...}
+//	```
+//
+// Being last, the block never shifts the line numbers comments point at.
+// LoadFromSidecar and ReadSidecar detect it and leave it out of Content; saves
+// write it back (and no sidecar file) while DocumentWithComments.Embedded is set.
+
+// embedOpen starts the block holding an embedded thread store
+const embedOpen = "\n```comments\n"
+
+// embedClose ends it
+const embedClose = "\n```"
+
+// splitEmbedded separates a document from its embedded thread store,
+// returning the document content and the store's JSON. ok is false if the
+// document doesn't end with a comments block holding a JSON object.
+func splitEmbedded(text string) (content string, store []byte, ok bool) {
+	start := strings.LastIndex(text, embedOpen)
+	if start < 0 {
+		return text, nil, false
+	}
+	body := text[start+len(embedOpen)-1:] // From the newline ending the opening fence
+	body = strings.TrimRight(body, "\n")
+	if !strings.HasSuffix(body, embedClose) {
+		return text, nil, false
+	}
+	store = []byte(strings.TrimSpace(strings.TrimSuffix(body, embedClose)))
+	if len(store) == 0 || store[0] != '{' {
+		return text, nil, false // A code block about comments, not a store
+	}
+	return text[:start], store, true
+}
+
+// joinEmbedded appends a thread store block to document content. Content
+// that doesn't end with a newline gets one, since the block needs a line of
+// its own; see normalizeEmbeddedContent.
+func joinEmbedded(content string, store []byte) string {
+	return normalizeEmbeddedContent(content) + embedOpen + string(store) + embedClose + "\n"
+}
+
+// normalizeEmbeddedContent returns content as it reads back from an
+// embedded document: with a final newline unless empty
+func normalizeEmbeddedContent(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		return content + "\n"
+	}
+	return content
+}
+
+// saveEmbedded writes the document and its thread store to the markdown file
+func saveEmbedded(mdPath string, doc *DocumentWithComments) error {
+	doc.Content = normalizeEmbeddedContent(doc.Content)
+	doc.recordRevision()
+	if err := savePrivateNotes(mdPath, doc); err != nil {
+		return err
+	}
+	store, err := marshalStore(doc, doc.saveOptions(mdPath))
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(mdPath, doc.Format.Encode(joinEmbedded(doc.Content, store)), 0644); err != nil {
+		return fmt.Errorf("failed to write markdown file: %w", err)
+	}
+	doc.savedContent = doc.Content
+	log().Info("saved embedded comments", "file", mdPath, "threads", len(doc.Threads),
+		"comments", len(doc.GetAllComments()), "hash", doc.DocumentHash, "bytes", len(store))
+	return nil
+}
+
+// Storage modes, as reported by StorageMode and accepted by ConvertStorage
+const (
+	StorageSidecar  = "sidecar"
+	StorageEmbedded = "embedded"
+)
+
+// StorageMode returns where a document's threads are stored
+func StorageMode(mdPath string) (string, error) {
+	text, _, model, err := readDocument(mdPath)
+	if err != nil {
+		return "", err
+	}
+	if !isPlainText(model) {
+		return StorageSidecar, nil
+	}
+	if _, _, ok := splitEmbedded(text); ok {
+		return StorageEmbedded, nil
+	}
+	return StorageSidecar, nil
+}
+
+// ConvertStorage moves a document's threads to the given storage mode. The
+// new copy is written before the old one is removed, so an interrupted
+// conversion leaves the threads in both places rather than neither.
+func ConvertStorage(mdPath, mode string) error {
+	if mode != StorageSidecar && mode != StorageEmbedded {
+		return fmt.Errorf("unknown storage mode %q (valid: %s, %s)", mode, StorageSidecar, StorageEmbedded)
+	}
+
+	doc, err := ReadSidecar(mdPath)
+	if err != nil {
+		return err
+	}
+	if doc.Embedded == (mode == StorageEmbedded) {
+		return nil
+	}
+
+	if mode == StorageEmbedded {
+		if !isPlainText(doc.Model) {
+			return fmt.Errorf("%s documents can't embed their comments; use sidecar storage", doc.Model.Name())
+		}
+		doc.Embedded = true
+		if err := saveEmbedded(mdPath, doc); err != nil {
+			return err
+		}
+		return DeleteSidecar(mdPath)
+	}
+
+	// Content read from an embedded document always ends with a newline;
+	// drop it again if the file doesn't, so the hash matches what is written
+	doc.Embedded = false
+	if !doc.Format.FinalNewline {
+		doc.Content = strings.TrimSuffix(doc.Content, "\n")
+	}
+	if err := SaveSidecar(mdPath, doc); err != nil {
+		return err
+	}
+	return SaveMarkdown(mdPath, doc)
+}
@@ -0,0 +1,131 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitEmbedded(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		content string
+		ok      bool
+	}{
+		{"store", "# T\n\nBody\n\n```comments\n{\"version\": \"2.0\"}\n```\n", "# T\n\nBody\n", true},
+		{"no final newline", "Body\n\n```comments\n{}\n```", "Body\n", true},
+		{"empty document", "\n```comments\n{}\n```\n", "", true},
+		{"plain markdown", "# T\n\nBody\n", "# T\n\nBody\n", false},
+		{"example block", "Use this:\n\n```comments\nlist --unread\n```\n", "Use this:\n\n```comments\nlist --unread\n```\n", false},
+		{"block not last", "A\n\n```comments\n{}\n```\n\nMore\n", "A\n\n```comments\n{}\n```\n\nMore\n", false},
+	}
+	for _, tt := range tests {
+		content, _, ok := splitEmbedded(tt.text)
+		if content != tt.content || ok != tt.ok {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tt.name, content, ok, tt.content, tt.ok)
+		}
+	}
+
+	// Joining and splitting again gives back the same content and store
+	content, store, ok := splitEmbedded(joinEmbedded("# T\n\nBody\n", []byte(`{"version": "2.0"}`)))
+	if !ok || content != "# T\n\nBody\n" || string(store) != `{"version": "2.0"}` {
+		t.Errorf("Round trip gave (%q, %q, %v)", content, store, ok)
+	}
+}
+
+func TestConvertStorage(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	original := "# Title\n\nFirst line\nSecond line\n"
+	if err := os.WriteFile(mdPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := NewComment("alice", 3, "Clarify")
+	doc.Threads = append(doc.Threads, thread)
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ConvertStorage(mdPath, StorageEmbedded); err != nil {
+		t.Fatalf("ConvertStorage(embedded) failed: %v", err)
+	}
+	if SidecarExists(mdPath) {
+		t.Error("The sidecar should be removed after embedding")
+	}
+	if mode, _ := StorageMode(mdPath); mode != StorageEmbedded {
+		t.Errorf("StorageMode = %s, want embedded", mode)
+	}
+
+	// Loading and saving an embedded document keeps one store block and
+	// leaves it out of Content
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Embedded || doc.Content != original || len(doc.Threads) != 1 {
+		t.Fatalf("Embedded load: embedded=%v threads=%d content=%q", doc.Embedded, len(doc.Threads), doc.Content)
+	}
+	if err := AddReplyToThread(doc.Threads, thread.ID, "bob", "Done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(mdPath)
+	if n := strings.Count(string(data), "```comments"); n != 1 {
+		t.Errorf("Expected one store block, found %d", n)
+	}
+	if SidecarExists(mdPath) {
+		t.Error("Saving an embedded document should not create a sidecar")
+	}
+
+	readOnly, err := ReadSidecar(mdPath)
+	if err != nil || readOnly.Threads[0].CountReplies() != 1 {
+		t.Fatalf("ReadSidecar of embedded document failed: %v", err)
+	}
+
+	if err := ConvertStorage(mdPath, StorageSidecar); err != nil {
+		t.Fatalf("ConvertStorage(sidecar) failed: %v", err)
+	}
+	data, _ = os.ReadFile(mdPath)
+	if string(data) != original {
+		t.Errorf("Markdown after converting back = %q, want the original", data)
+	}
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil || doc.Embedded || len(doc.Threads) != 1 || doc.Threads[0].CountReplies() != 1 {
+		t.Errorf("Threads did not survive converting back to a sidecar (%v)", err)
+	}
+}
+
+func TestConvertStorageWithoutFinalNewline(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	original := "# Title\n\nLast line"
+	if err := os.WriteFile(mdPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ConvertStorage(mdPath, StorageEmbedded); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConvertStorage(mdPath, StorageSidecar); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(mdPath)
+	if string(data) != original {
+		t.Errorf("Markdown = %q, want %q", data, original)
+	}
+	doc, err := ReadSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.DocumentHash != ComputeDocumentHash(original) {
+		t.Error("The sidecar's hash should match the markdown as written")
+	}
+}
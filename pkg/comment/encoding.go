@@ -0,0 +1,141 @@
+package comment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encodings recognized in markdown files
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+)
+
+// Byte order marks
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// ErrNotText is returned for markdown files that are neither UTF-8 nor UTF-16 with a byte order mark
+var ErrNotText = errors.New("file is not UTF-8 or UTF-16 text")
+
+// TextFormat records how a markdown file is encoded on disk, so saving
+// writes it back the way it was found. Content in memory is always UTF-8
+// with "\n" line endings.
+type TextFormat struct {
+	Encoding     string // EncodingUTF8, EncodingUTF16LE, or EncodingUTF16BE
+	BOM          bool   // A UTF-8 byte order mark (UTF-16 files always have one)
+	CRLF         bool   // Lines end with "\r\n"
+	FinalNewline bool   // The file ends with a line ending
+	detected     bool   // Read from an existing file (zero value: write as-is)
+}
+
+// DecodeText converts file contents to UTF-8 text with "\n" line endings
+// and reports the format it was stored in. Files that mix "\r\n" and "\n"
+// are left as they are.
+func DecodeText(data []byte) (string, TextFormat, error) {
+	format := TextFormat{Encoding: EncodingUTF8, detected: true}
+
+	var text string
+	switch {
+	case bytes.HasPrefix(data, bomUTF16LE), bytes.HasPrefix(data, bomUTF16BE):
+		var order binary.ByteOrder = binary.LittleEndian
+		format.Encoding = EncodingUTF16LE
+		if bytes.HasPrefix(data, bomUTF16BE) {
+			order = binary.BigEndian
+			format.Encoding = EncodingUTF16BE
+		}
+		body := data[2:]
+		if len(body)%2 != 0 {
+			return "", format, fmt.Errorf("%w: truncated %s", ErrNotText, format.Encoding)
+		}
+		units := make([]uint16, len(body)/2)
+		for i := range units {
+			units[i] = order.Uint16(body[2*i:])
+		}
+		text = string(utf16.Decode(units))
+
+	case bytes.HasPrefix(data, bomUTF8):
+		format.BOM = true
+		data = data[len(bomUTF8):]
+		fallthrough
+
+	default:
+		if !utf8.Valid(data) {
+			return "", format, ErrNotText
+		}
+		text = string(data)
+	}
+
+	if crlf := strings.Count(text, "\r\n"); crlf > 0 && crlf == strings.Count(text, "\n") {
+		format.CRLF = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	format.FinalNewline = strings.HasSuffix(text, "\n")
+
+	return text, format, nil
+}
+
+// Encode converts content back to the stored format. A file that had (or
+// lacked) a final newline keeps it that way.
+func (f TextFormat) Encode(content string) []byte {
+	if !f.detected {
+		return []byte(content)
+	}
+
+	if content != "" {
+		hasNewline := strings.HasSuffix(content, "\n")
+		if f.FinalNewline && !hasNewline {
+			content += "\n"
+		} else if !f.FinalNewline && hasNewline {
+			content = strings.TrimSuffix(content, "\n")
+		}
+	}
+	if f.CRLF {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+
+	switch f.Encoding {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		var order binary.ByteOrder = binary.LittleEndian
+		bom := bomUTF16LE
+		if f.Encoding == EncodingUTF16BE {
+			order = binary.BigEndian
+			bom = bomUTF16BE
+		}
+		units := utf16.Encode([]rune(content))
+		data := make([]byte, len(bom)+2*len(units))
+		copy(data, bom)
+		for i, u := range units {
+			order.PutUint16(data[len(bom)+2*i:], u)
+		}
+		return data
+	}
+
+	if f.BOM {
+		return append(append([]byte{}, bomUTF8...), content...)
+	}
+	return []byte(content)
+}
+
+// readMarkdown reads and decodes a markdown file
+func readMarkdown(mdPath string) (string, TextFormat, error) {
+	data, err := os.ReadFile(mdPath)
+	if err != nil {
+		return "", TextFormat{}, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+	content, format, err := DecodeText(data)
+	if err != nil {
+		return "", TextFormat{}, fmt.Errorf("failed to read markdown file %s: %w", mdPath, err)
+	}
+	return content, format, nil
+}
@@ -0,0 +1,156 @@
+package comment
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDecodeTextRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		content string
+		format  TextFormat
+	}{
+		{
+			name:    "plain",
+			data:    []byte("# Title\nText\n"),
+			content: "# Title\nText\n",
+			format:  TextFormat{Encoding: EncodingUTF8, FinalNewline: true},
+		},
+		{
+			name:    "crlf",
+			data:    []byte("# Title\r\nText\r\n"),
+			content: "# Title\nText\n",
+			format:  TextFormat{Encoding: EncodingUTF8, CRLF: true, FinalNewline: true},
+		},
+		{
+			name:    "no final newline",
+			data:    []byte("# Title\r\nText"),
+			content: "# Title\nText",
+			format:  TextFormat{Encoding: EncodingUTF8, CRLF: true},
+		},
+		{
+			name:    "mixed line endings are left alone",
+			data:    []byte("a\r\nb\nc\n"),
+			content: "a\r\nb\nc\n",
+			format:  TextFormat{Encoding: EncodingUTF8, FinalNewline: true},
+		},
+		{
+			name:    "utf-8 bom",
+			data:    append([]byte{0xEF, 0xBB, 0xBF}, "Héllo\r\n"...),
+			content: "Héllo\n",
+			format:  TextFormat{Encoding: EncodingUTF8, BOM: true, CRLF: true, FinalNewline: true},
+		},
+		{
+			name:    "utf-16le",
+			data:    []byte{0xFF, 0xFE, 'H', 0, 'i', 0, '\r', 0, '\n', 0},
+			content: "Hi\n",
+			format:  TextFormat{Encoding: EncodingUTF16LE, CRLF: true, FinalNewline: true},
+		},
+		{
+			name:    "utf-16be",
+			data:    []byte{0xFE, 0xFF, 0, 'H', 0, 'i', 0, '\n'},
+			content: "Hi\n",
+			format:  TextFormat{Encoding: EncodingUTF16BE, FinalNewline: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, format, err := DecodeText(tt.data)
+			if err != nil {
+				t.Fatalf("DecodeText: %v", err)
+			}
+			if content != tt.content {
+				t.Errorf("content = %q, want %q", content, tt.content)
+			}
+			format.detected = false
+			if format != tt.format {
+				t.Errorf("format = %+v, want %+v", format, tt.format)
+			}
+			format.detected = true
+			if got := format.Encode(content); !bytes.Equal(got, tt.data) {
+				t.Errorf("Encode = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestDecodeTextRejectsBinary(t *testing.T) {
+	for _, data := range [][]byte{
+		{0x89, 'P', 'N', 'G', 0xFF, 0x00},
+		{0xFF, 0xFE, 'H'}, // Odd-length UTF-16
+	} {
+		if _, _, err := DecodeText(data); !errors.Is(err, ErrNotText) {
+			t.Errorf("DecodeText(%q) error = %v, want ErrNotText", data, err)
+		}
+	}
+}
+
+func TestEncodeKeepsFinalNewlineState(t *testing.T) {
+	_, format, _ := DecodeText([]byte("no newline"))
+	if got := string(format.Encode("edited\n")); got != "edited" {
+		t.Errorf("Expected the missing final newline to be kept, got %q", got)
+	}
+
+	_, format, _ = DecodeText([]byte("newline\r\n"))
+	if got := string(format.Encode("edited")); got != "edited\r\n" {
+		t.Errorf("Expected the final CRLF to be kept, got %q", got)
+	}
+
+	// A zero TextFormat writes content unchanged
+	if got := string((TextFormat{}).Encode("as is")); got != "as is" {
+		t.Errorf("Expected content unchanged, got %q", got)
+	}
+}
+
+func TestSavePreservesWindowsFile(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "test.md")
+	original := append([]byte{0xEF, 0xBB, 0xBF}, "# Title\r\nLine two\r\n"...)
+	if err := os.WriteFile(mdPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Content != "# Title\nLine two\n" {
+		t.Fatalf("Expected normalized content, got %q", doc.Content)
+	}
+
+	doc.Content = "# Title\nLine two, edited\n"
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	written, _ := os.ReadFile(mdPath)
+	want := append([]byte{0xEF, 0xBB, 0xBF}, "# Title\r\nLine two, edited\r\n"...)
+	if !bytes.Equal(written, want) {
+		t.Errorf("Saved %q, want %q", written, want)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(mdPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected file mode 0600 to be kept, got %o", info.Mode().Perm())
+		}
+	}
+
+	// The sidecar hash is of the normalized content, so a reload is clean
+	reloaded, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Content != doc.Content || reloaded.DocumentHash != ComputeDocumentHash(doc.Content) {
+		t.Errorf("Reload mismatch: %q", reloaded.Content)
+	}
+}
@@ -0,0 +1,153 @@
+package comment
+
+import (
+	"sort"
+	"time"
+)
+
+// Event types emitted when comparing two snapshots of a document's threads
+const (
+	EventCommentAdded       = "comment_added"
+	EventCommentReplied     = "comment_replied"
+	EventThreadResolved     = "thread_resolved"
+	EventThreadReopened     = "thread_reopened"
+	EventSuggestionAdded    = "suggestion_added"
+	EventSuggestionAccepted = "suggestion_accepted"
+	EventSuggestionRejected = "suggestion_rejected"
+)
+
+// Event describes a single change to a document's comments
+// Events are designed to be serialized as newline-delimited JSON for bots and pipelines.
+type Event struct {
+	Type      string    `json:"type"`
+	File      string    `json:"file"`
+	ThreadID  string    `json:"thread_id"`
+	CommentID string    `json:"comment_id"`
+	Author    string    `json:"author,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Text      string    `json:"text,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DiffThreads returns the events that turn the before snapshot into the after snapshot
+// New comments and replies carry their own timestamps, as do resolutions and
+// decisions made since the sidecar started recording when (ResolvedAt,
+// DecidedAt); other state changes, such as reopening, are stamped with now.
+func DiffThreads(file string, before, after []*Comment, now time.Time) []Event {
+	events := []Event{}
+
+	// Index every comment in the old snapshot by ID
+	previous := make(map[string]*Comment)
+	for _, thread := range before {
+		previous[thread.ID] = thread
+		for _, reply := range flattenReplies(thread.Replies) {
+			previous[reply.ID] = reply
+		}
+	}
+
+	for _, thread := range after {
+		old, existed := previous[thread.ID]
+		if !existed {
+			eventType := EventCommentAdded
+			if thread.IsSuggestion {
+				eventType = EventSuggestionAdded
+			}
+			events = append(events, newEvent(eventType, file, thread, thread, thread.Timestamp))
+		} else {
+			if !old.Resolved && thread.Resolved {
+				events = append(events, newEvent(EventThreadResolved, file, thread, thread, recordedAt(thread.ResolvedAt, now)))
+			} else if old.Resolved && !thread.Resolved {
+				events = append(events, newEvent(EventThreadReopened, file, thread, thread, now))
+			}
+		}
+
+		for _, reply := range flattenReplies(thread.Replies) {
+			if _, ok := previous[reply.ID]; !ok {
+				events = append(events, newEvent(EventCommentReplied, file, thread, reply, reply.Timestamp))
+			}
+		}
+
+		// Suggestions may live at the thread root or within replies
+		for _, c := range append([]*Comment{thread}, flattenReplies(thread.Replies)...) {
+			if !c.IsSuggestion || c.Accepted == nil {
+				continue
+			}
+			if old, ok := previous[c.ID]; ok && old.Accepted != nil {
+				continue
+			}
+			eventType := EventSuggestionRejected
+			if *c.Accepted {
+				eventType = EventSuggestionAccepted
+			}
+			events = append(events, newEvent(eventType, file, thread, c, recordedAt(c.DecidedAt, now)))
+		}
+	}
+
+	return events
+}
+
+// recordedAt returns the time a change was recorded at, or now if it wasn't
+func recordedAt(at *time.Time, now time.Time) time.Time {
+	if at == nil || at.IsZero() {
+		return now
+	}
+	return *at
+}
+
+// DigestEvents returns what happened to a document's threads since a time,
+// oldest first: new comments and suggestions, replies, resolutions, and
+// accepted suggestions. Resolutions and acceptances are only known for
+// threads resolved or decided since the sidecar started recording when.
+func DigestEvents(file string, threads []*Comment, since time.Time) []Event {
+	events := []Event{}
+	recent := func(t *time.Time) bool { return t != nil && !t.Before(since) }
+
+	for _, thread := range threads {
+		if recent(&thread.Timestamp) {
+			eventType := EventCommentAdded
+			if thread.IsSuggestion {
+				eventType = EventSuggestionAdded
+			}
+			events = append(events, newEvent(eventType, file, thread, thread, thread.Timestamp))
+		}
+		if thread.Resolved && recent(thread.ResolvedAt) {
+			events = append(events, newEvent(EventThreadResolved, file, thread, thread, *thread.ResolvedAt))
+		}
+
+		for _, reply := range flattenReplies(thread.Replies) {
+			if recent(&reply.Timestamp) {
+				events = append(events, newEvent(EventCommentReplied, file, thread, reply, reply.Timestamp))
+			}
+		}
+
+		for _, c := range append([]*Comment{thread}, flattenReplies(thread.Replies)...) {
+			if c.IsAccepted() && recent(c.DecidedAt) {
+				events = append(events, newEvent(EventSuggestionAccepted, file, thread, c, *c.DecidedAt))
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events
+}
+
+// newEvent builds an event for comment c within thread
+func newEvent(eventType, file string, thread, c *Comment, timestamp time.Time) Event {
+	line := c.Line
+	if line == 0 {
+		line = thread.Line
+	}
+
+	return Event{
+		Type:      eventType,
+		File:      file,
+		ThreadID:  thread.ID,
+		CommentID: c.ID,
+		Author:    c.Author,
+		Line:      line,
+		Text:      c.Text,
+		Timestamp: timestamp,
+	}
+}
@@ -0,0 +1,132 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffThreadsAddedAndReplied(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	now := ts.Add(time.Hour)
+
+	before := []*Comment{
+		{ID: "c1", Author: "alice", Line: 3, Timestamp: ts, Text: "First", Replies: []*Comment{}},
+	}
+	after := []*Comment{
+		{ID: "c1", Author: "alice", Line: 3, Timestamp: ts, Text: "First", Replies: []*Comment{
+			{ID: "c2", Author: "bob", Timestamp: ts.Add(time.Minute), Text: "Reply"},
+		}},
+		{ID: "c3", Author: "carol", Line: 7, Timestamp: ts, Text: "Second", Replies: []*Comment{}},
+	}
+
+	events := DiffThreads("doc.md", before, after, now)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+
+	if events[0].Type != EventCommentReplied || events[0].ThreadID != "c1" || events[0].CommentID != "c2" {
+		t.Errorf("Unexpected reply event: %+v", events[0])
+	}
+	if events[0].Line != 3 {
+		t.Errorf("Reply event should inherit thread line, got %d", events[0].Line)
+	}
+	if events[1].Type != EventCommentAdded || events[1].CommentID != "c3" || events[1].File != "doc.md" {
+		t.Errorf("Unexpected added event: %+v", events[1])
+	}
+}
+
+func TestDiffThreadsStateChanges(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	now := ts.Add(time.Hour)
+	decided := ts.Add(time.Minute)
+	accepted := true
+
+	before := []*Comment{
+		{ID: "c1", Line: 1, Timestamp: ts, Replies: []*Comment{}},
+		{ID: "s1", Line: 2, Timestamp: ts, IsSuggestion: true, Replies: []*Comment{}},
+	}
+	after := []*Comment{
+		{ID: "c1", Line: 1, Timestamp: ts, Resolved: true, Replies: []*Comment{}},
+		{ID: "s1", Line: 2, Timestamp: ts, IsSuggestion: true, Accepted: &accepted, DecidedAt: &decided, Replies: []*Comment{}},
+	}
+
+	events := DiffThreads("doc.md", before, after, now)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+	// Resolved without ResolvedAt (recorded by an older version): stamped now
+	if events[0].Type != EventThreadResolved || !events[0].Timestamp.Equal(now) {
+		t.Errorf("Unexpected resolve event: %+v", events[0])
+	}
+	if events[1].Type != EventSuggestionAccepted || !events[1].Timestamp.Equal(decided) {
+		t.Errorf("Unexpected suggestion event: %+v", events[1])
+	}
+
+	// Diffing identical snapshots yields nothing
+	if events := DiffThreads("doc.md", after, after, now); len(events) != 0 {
+		t.Errorf("Expected no events for identical snapshots, got %+v", events)
+	}
+}
+
+func TestDigestEventsSince(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	since := ts.Add(time.Hour)
+	later := since.Add(time.Minute)
+	accepted := true
+
+	threads := []*Comment{
+		// Old thread, newly resolved, with a new reply
+		{ID: "c1", Author: "alice", Line: 3, Timestamp: ts, Text: "Old", Resolved: true, ResolvedAt: &later, Replies: []*Comment{
+			{ID: "c2", Author: "bob", Timestamp: ts.Add(2 * time.Hour), Text: "Done"},
+		}},
+		// New suggestion, accepted
+		{ID: "c3", Author: "claude", Line: 5, Timestamp: later, IsSuggestion: true, Accepted: &accepted, DecidedAt: &later},
+		// Old and quiet: resolved before the cutoff
+		{ID: "c4", Author: "carol", Line: 9, Timestamp: ts, Resolved: true, ResolvedAt: &ts},
+	}
+
+	events := DigestEvents("doc.md", threads, since)
+	want := []struct{ eventType, commentID string }{
+		{EventThreadResolved, "c1"},
+		{EventSuggestionAdded, "c3"},
+		{EventSuggestionAccepted, "c3"},
+		{EventCommentReplied, "c2"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.eventType || events[i].CommentID != w.commentID {
+			t.Errorf("Event %d: expected %s of %s, got %+v", i, w.eventType, w.commentID, events[i])
+		}
+	}
+}
+
+func TestResolveAndDecideRecordWhen(t *testing.T) {
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	defer SetClock(FixedClock(at))()
+
+	thread := NewComment("alice", 3, "Question")
+	suggestion := NewSuggestion("bob", 4, 4, "Reword", "old", "new")
+	threads := []*Comment{thread, suggestion}
+
+	if err := ResolveThread(threads, thread.ID); err != nil {
+		t.Fatal(err)
+	}
+	if thread.ResolvedAt == nil || !thread.ResolvedAt.Equal(at) {
+		t.Errorf("ResolvedAt = %v", thread.ResolvedAt)
+	}
+	if err := UnresolveThread(threads, thread.ID); err != nil {
+		t.Fatal(err)
+	}
+	if thread.ResolvedAt != nil {
+		t.Error("Reopening should clear ResolvedAt")
+	}
+
+	if err := AcceptSuggestion(threads, suggestion.ID); err != nil {
+		t.Fatal(err)
+	}
+	if suggestion.DecidedAt == nil || !suggestion.DecidedAt.Equal(at) {
+		t.Errorf("DecidedAt = %v", suggestion.DecidedAt)
+	}
+}
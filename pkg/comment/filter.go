@@ -0,0 +1,188 @@
+package comment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter reports whether a comment matches
+// Build filters with the By* constructors and combine them with And, Or,
+// and Not. A nil Filter matches everything.
+type Filter func(c *Comment) bool
+
+// Matches reports whether c passes the filter
+func (f Filter) Matches(c *Comment) bool {
+	return f == nil || f(c)
+}
+
+// Apply returns the comments that pass the filter, in their original order
+func (f Filter) Apply(comments []*Comment) []*Comment {
+	result := make([]*Comment, 0, len(comments))
+	for _, c := range comments {
+		if f.Matches(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// And matches comments that pass every filter (nil filters are skipped)
+func And(filters ...Filter) Filter {
+	return func(c *Comment) bool {
+		for _, f := range filters {
+			if !f.Matches(c) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches comments that pass any of the filters
+// With no filters it matches nothing.
+func Or(filters ...Filter) Filter {
+	return func(c *Comment) bool {
+		for _, f := range filters {
+			if f.Matches(c) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches comments that fail f
+func Not(f Filter) Filter {
+	return func(c *Comment) bool {
+		return !f.Matches(c)
+	}
+}
+
+// ByAuthor matches comments written by author
+func ByAuthor(author string) Filter {
+	return func(c *Comment) bool {
+		return c.Author == author
+	}
+}
+
+// ByType matches comments whose text starts with the type prefix ([Q], [S], [B], [T], [E])
+func ByType(commentType string) Filter {
+	prefix := "[" + commentType + "]"
+	return func(c *Comment) bool {
+		return strings.HasPrefix(c.Text, prefix)
+	}
+}
+
+// BySearch matches comments whose text contains query (case-insensitive)
+func BySearch(query string) Filter {
+	query = strings.ToLower(query)
+	return func(c *Comment) bool {
+		return strings.Contains(strings.ToLower(c.Text), query)
+	}
+}
+
+// ByThreadSearch matches threads where the root or any reply contains query
+// (case-insensitive)
+func ByThreadSearch(query string) Filter {
+	return func(c *Comment) bool {
+		_, ok := SearchThread(c, query)
+		return ok
+	}
+}
+
+// SearchThread returns the ID of the first comment in the thread (the root,
+// then replies depth-first) whose text contains query, case-insensitively
+func SearchThread(thread *Comment, query string) (string, bool) {
+	query = strings.ToLower(query)
+	var search func(c *Comment) (string, bool)
+	search = func(c *Comment) (string, bool) {
+		if strings.Contains(strings.ToLower(c.Text), query) {
+			return c.ID, true
+		}
+		for _, reply := range c.Replies {
+			if id, ok := search(reply); ok {
+				return id, true
+			}
+		}
+		return "", false
+	}
+	return search(thread)
+}
+
+// ByLineRange matches comments whose lines overlap start-end
+func ByLineRange(start, end int) Filter {
+	return func(c *Comment) bool {
+		first, last := c.LineRange()
+		return first <= end && last >= start
+	}
+}
+
+// ParseLineRange parses a "start-end" line range such as "10-30"
+func ParseLineRange(lineRange string) (int, int, error) {
+	parts := strings.Split(lineRange, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid line range format. Expected: start-end (e.g., 10-30)")
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start line: %s", parts[0])
+	}
+
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end line: %s", parts[1])
+	}
+
+	if start > end {
+		return 0, 0, fmt.Errorf("start line (%d) must be less than or equal to end line (%d)", start, end)
+	}
+	return start, end, nil
+}
+
+// ByStatus matches comments with the given status (active, orphaned, resolved, completed)
+func ByStatus(status string) Filter {
+	return func(c *Comment) bool {
+		return c.GetStatus() == status
+	}
+}
+
+// ByPriority matches comments with the given priority (unset counts as medium)
+func ByPriority(priority string) Filter {
+	return func(c *Comment) bool {
+		return c.GetPriority() == priority
+	}
+}
+
+// BySuggestionState matches suggestions in the given state (pending,
+// accepted, or rejected), or every suggestion when state is empty
+func BySuggestionState(state string) Filter {
+	return func(c *Comment) bool {
+		return c.IsSuggestion && (state == "" || c.SuggestionState() == state)
+	}
+}
+
+// BySectionPath matches comments whose stored section path is section or
+// nested beneath it. Use InSection for comments in a live document.
+func BySectionPath(section string) Filter {
+	return func(c *Comment) bool {
+		return c.SectionPath == section || strings.HasPrefix(c.SectionPath, section+" > ")
+	}
+}
+
+// InSection matches comments in a document section, including nested
+// sections. Returns an error if the document has no such section.
+func InSection(doc *DocumentWithComments, sectionPath string) (Filter, error) {
+	if err := ValidateSectionPath(doc, sectionPath); err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{}
+	for _, c := range GetCommentsInSection(doc, sectionPath) {
+		ids[c.ID] = true
+	}
+	return func(c *Comment) bool {
+		return ids[c.ID]
+	}, nil
+}
@@ -0,0 +1,116 @@
+package comment
+
+import "testing"
+
+func filterFixture() []*Comment {
+	question := NewCommentWithType("alice", 3, "[Q] Why this order?", "Q")
+	blocker := NewCommentWithType("bob", 10, "[B] Broken link", "B")
+	blocker.Priority = "high"
+	blocker.EndLine = 12
+	note := NewComment("bob", DocumentLine, "Nice overview")
+	note.Replies = append(note.Replies, NewReply("carol", "Agreed, ship it", note))
+	return []*Comment{question, blocker, note}
+}
+
+func TestFilterCombinators(t *testing.T) {
+	comments := filterFixture()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"nil matches all", nil, 3},
+		{"author", ByAuthor("bob"), 2},
+		{"type", ByType("Q"), 1},
+		{"search is case-insensitive", BySearch("BROKEN"), 1},
+		{"thread search includes replies", ByThreadSearch("ship"), 1},
+		{"range overlap", ByLineRange(11, 20), 1},
+		{"priority defaults to medium", ByPriority("medium"), 2},
+		{"and", And(ByAuthor("bob"), ByPriority("high")), 1},
+		{"or", Or(ByType("Q"), ByType("B")), 2},
+		{"empty or matches nothing", Or(), 0},
+		{"not", Not(ByAuthor("bob")), 1},
+		{"nested", And(Not(ByType("B")), Or(ByAuthor("alice"), (*Comment).IsDocumentLevel)), 2},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Apply(comments); len(got) != tt.want {
+			t.Errorf("%s: expected %d matches, got %d", tt.name, tt.want, len(got))
+		}
+	}
+}
+
+func TestBySuggestionState(t *testing.T) {
+	pending := NewSuggestion("alice", 1, 1, "Reword", "old", "new")
+	accepted := NewSuggestion("bob", 2, 2, "Reword", "old", "new")
+	rejected := NewSuggestion("carol", 3, 3, "Reword", "old", "new")
+	comments := append(filterFixture(), pending, accepted, rejected)
+	if err := AcceptSuggestion(comments, accepted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RejectSuggestion(comments, rejected.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		state string
+		want  *Comment
+	}{
+		{SuggestionPending, pending},
+		{SuggestionAccepted, accepted},
+		{SuggestionRejected, rejected},
+	}
+	for _, tt := range tests {
+		got := BySuggestionState(tt.state).Apply(comments)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: expected only %s, got %d matches", tt.state, tt.want.ID, len(got))
+		}
+	}
+	if got := BySuggestionState("").Apply(comments); len(got) != 3 {
+		t.Errorf("Expected every suggestion, got %d", len(got))
+	}
+	if state := comments[0].SuggestionState(); state != "" {
+		t.Errorf("Expected no state for a plain comment, got %q", state)
+	}
+}
+
+func TestSearchThreadReportsMatchingReply(t *testing.T) {
+	note := filterFixture()[2]
+	id, ok := SearchThread(note, "agreed")
+	if !ok || id != note.Replies[0].ID {
+		t.Errorf("Expected the reply to match, got %q (%v)", id, ok)
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	if start, end, err := ParseLineRange("10 - 30"); err != nil || start != 10 || end != 30 {
+		t.Errorf("Expected 10-30, got %d-%d (%v)", start, end, err)
+	}
+	for _, bad := range []string{"10", "a-5", "30-10"} {
+		if _, _, err := ParseLineRange(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestInSection(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# Guide\n\n## Setup\n\nInstall.\n\n## Usage\n\nRun."}
+	setup := NewComment("alice", 5, "Which version?")
+	usage := NewComment("bob", 9, "How often?")
+	doc.Threads = []*Comment{setup, usage}
+	ComputeSectionsForComments(doc)
+
+	filter, err := InSection(doc, "Guide > Setup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filter.Apply(doc.Threads); len(got) != 1 || got[0] != setup {
+		t.Errorf("Expected only the Setup comment, got %d", len(got))
+	}
+	if got := BySectionPath("Guide").Apply(doc.Threads); len(got) != 2 {
+		t.Errorf("Expected nested sections to match by stored path, got %d", len(got))
+	}
+	if _, err := InSection(doc, "Guide > Missing"); err == nil {
+		t.Error("Expected an error for a missing section")
+	}
+}
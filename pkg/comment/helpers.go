@@ -2,21 +2,15 @@ package comment
 
 import (
 	"fmt"
-	"time"
+	"strings"
 )
 
-// generateID generates a unique comment ID based on timestamp
-func generateID() string {
-	// Use nanosecond timestamp for uniqueness
-	return fmt.Sprintf("c%d", time.Now().UnixNano())
-}
-
 // NewComment creates a new root comment (v2.0)
 func NewComment(author string, line int, text string) *Comment {
 	return &Comment{
-		ID:        generateID(),
+		ID:        generateID("c"),
 		Author:    author,
-		Timestamp: time.Now(),
+		Timestamp: now(),
 		Text:      text,
 		Line:      line,
 		Resolved:  false,
@@ -31,12 +25,28 @@ func NewCommentWithType(author string, line int, text string, commentType string
 	return comment
 }
 
+// CaptureQuote stores the current text of the comment's target line(s) in Quote,
+// and its hash in LineHash
+// Document-level comments and suggestions (which keep OriginalText) get no quote.
+func CaptureQuote(c *Comment, docContent string) {
+	if c.IsSuggestion || c.IsDocumentLevel() {
+		return
+	}
+	lines := strings.Split(docContent, "\n")
+	first, last := c.LineRange()
+	if first < 1 || last > len(lines) {
+		return
+	}
+	c.Quote = strings.Join(lines[first-1:last], "\n")
+	c.LineHash = hashLines(c.Quote)
+}
+
 // NewReply creates a reply to an existing comment
 func NewReply(author string, text string, parentComment *Comment) *Comment {
 	return &Comment{
-		ID:         generateID(),
+		ID:         generateID("c"),
 		Author:     author,
-		Timestamp:  time.Now(),
+		Timestamp:  now(),
 		Text:       text,
 		Line:       parentComment.Line, // Inherit line from parent
 		SectionID:  parentComment.SectionID,
@@ -49,9 +59,9 @@ func NewReply(author string, text string, parentComment *Comment) *Comment {
 // NewSuggestion creates a new suggestion comment (v2.0)
 func NewSuggestion(author string, startLine, endLine int, text, originalText, proposedText string) *Comment {
 	return &Comment{
-		ID:           generateID(),
+		ID:           generateID("c"),
 		Author:       author,
-		Timestamp:    time.Now(),
+		Timestamp:    now(),
 		Text:         text,
 		Line:         startLine,
 		Resolved:     false,
@@ -65,23 +75,33 @@ func NewSuggestion(author string, startLine, endLine int, text, originalText, pr
 	}
 }
 
+// NewInsertion creates a suggestion that inserts proposedText after line
+// afterLine without replacing anything; afterLine 0 inserts at the top
+func NewInsertion(author string, afterLine int, text, proposedText string) *Comment {
+	s := NewSuggestion(author, afterLine+1, afterLine, text, "", proposedText)
+	s.Line = max(afterLine, 1)
+	return s
+}
+
 // GetVisibleComments returns comments that should be displayed based on resolved filter
-// In v2.0, this operates on thread roots (since threads are already nested)
+// In v2.0, this operates on thread roots (since threads are already nested).
+// Document-level threads come first; the rest keep their stored order.
 func GetVisibleComments(threads []*Comment, showResolved bool) []*Comment {
-	if showResolved {
-		// Show all threads
-		return threads
-	}
-
-	// Filter to only unresolved threads
+	documentLevel := []*Comment{}
 	visible := []*Comment{}
 	for _, thread := range threads {
-		if !thread.Resolved {
+		// Unless showing everything, filter to only unresolved threads
+		if thread.Resolved && !showResolved {
+			continue
+		}
+		if thread.IsDocumentLevel() {
+			documentLevel = append(documentLevel, thread)
+		} else {
 			visible = append(visible, thread)
 		}
 	}
 
-	return visible
+	return append(documentLevel, visible...)
 }
 
 // GroupCommentsByLine groups comments by their line number for display
@@ -104,6 +124,23 @@ func GroupCommentsByLine(threads []*Comment) map[int][]*Comment {
 	return grouped
 }
 
+// LinesCoveredByRanges returns the lines inside multi-line threads, excluding
+// each thread's first line (which already shows a comment marker). Resolved
+// threads and reviewed suggestions are skipped.
+func LinesCoveredByRanges(threads []*Comment) map[int]bool {
+	covered := make(map[int]bool)
+	for _, thread := range threads {
+		if thread.Resolved || (thread.IsSuggestion && !thread.IsPending()) {
+			continue
+		}
+		start, end := thread.LineRange()
+		for line := start + 1; line <= end; line++ {
+			covered[line] = true
+		}
+	}
+	return covered
+}
+
 // AddReplyToThread adds a reply to a thread
 // Returns error if thread not found
 func AddReplyToThread(threads []*Comment, threadID, author, text string) error {
@@ -135,7 +172,9 @@ func ResolveThread(threads []*Comment, threadID string) error {
 		return fmt.Errorf("thread not found: %s", threadID)
 	}
 
+	at := now()
 	thread.Resolved = true
+	thread.ResolvedAt = &at
 	return nil
 }
 
@@ -147,6 +186,7 @@ func UnresolveThread(threads []*Comment, threadID string) error {
 	}
 
 	thread.Resolved = false
+	thread.ResolvedAt = nil
 	return nil
 }
 
@@ -183,6 +223,19 @@ func GetSuggestionsByAuthor(threads []*Comment, author string) []*Comment {
 	return filtered
 }
 
+// FilterSuggestionsInRange returns the suggestions whose line range lies
+// entirely within startLine..endLine (inclusive)
+func FilterSuggestionsInRange(suggestions []*Comment, startLine, endLine int) []*Comment {
+	filtered := []*Comment{}
+	for _, s := range suggestions {
+		if s.StartLine >= startLine && s.EndLine <= endLine {
+			filtered = append(filtered, s)
+		}
+	}
+
+	return filtered
+}
+
 // AcceptSuggestion marks a suggestion as accepted
 func AcceptSuggestion(threads []*Comment, suggestionID string) error {
 	suggestion := findCommentByID(threads, suggestionID)
@@ -194,8 +247,9 @@ func AcceptSuggestion(threads []*Comment, suggestionID string) error {
 		return fmt.Errorf("comment is not a suggestion: %s", suggestionID)
 	}
 
-	accepted := true
+	accepted, at := true, now()
 	suggestion.Accepted = &accepted
+	suggestion.DecidedAt = &at
 	return nil
 }
 
@@ -210,8 +264,9 @@ func RejectSuggestion(threads []*Comment, suggestionID string) error {
 		return fmt.Errorf("comment is not a suggestion: %s", suggestionID)
 	}
 
-	rejected := false
+	rejected, at := false, now()
 	suggestion.Accepted = &rejected
+	suggestion.DecidedAt = &at
 	return nil
 }
 
@@ -0,0 +1,70 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SidecarVersion is one committed version of a sidecar, e.g. from git history
+type SidecarVersion struct {
+	Commit string
+	Time   time.Time
+	Data   []byte // The sidecar JSON as of the commit
+}
+
+// HistoryPoint counts a document's threads as of one version of its sidecar.
+// Opened and Closed are the changes since the previous version, for charting
+// review velocity; Open over time is the burndown.
+type HistoryPoint struct {
+	File     string    `json:"file"`
+	Commit   string    `json:"commit"`
+	Time     time.Time `json:"time"`
+	Threads  int       `json:"threads"`
+	Open     int       `json:"open"`
+	Resolved int       `json:"resolved"`
+	Opened   int       `json:"opened"` // Threads added since the previous version
+	Closed   int       `json:"closed"` // Threads resolved since the previous version
+}
+
+// ThreadHistory counts the threads in each version of a document's sidecar.
+// Versions must be oldest first; a thread reopened and resolved again counts
+// as closed each time.
+func ThreadHistory(file string, versions []SidecarVersion) ([]HistoryPoint, error) {
+	points := make([]HistoryPoint, 0, len(versions))
+	previous := map[string]bool{} // Thread ID -> resolved, as of the previous version
+
+	for _, v := range versions {
+		var storage StorageFormat
+		if err := json.Unmarshal(v.Data, &storage); err != nil {
+			return nil, fmt.Errorf("failed to parse sidecar at %s: %w", v.Commit, err)
+		}
+
+		point := HistoryPoint{File: file, Commit: v.Commit, Time: v.Time}
+		current := make(map[string]bool, len(storage.Threads))
+		for _, thread := range storage.Threads {
+			resolved := thread.Resolved || thread.GetStatus() == "resolved"
+			current[thread.ID] = resolved
+
+			point.Threads++
+			if resolved {
+				point.Resolved++
+			} else {
+				point.Open++
+			}
+
+			wasResolved, existed := previous[thread.ID]
+			if !existed {
+				point.Opened++
+			}
+			if resolved && !wasResolved {
+				point.Closed++
+			}
+		}
+
+		points = append(points, point)
+		previous = current
+	}
+
+	return points, nil
+}
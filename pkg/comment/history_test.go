@@ -0,0 +1,63 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestThreadHistory(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	first := NewComment("alice", 2, "Typo")
+	second := NewComment("bob", 4, "Unclear")
+
+	version := func(day int, threads ...*Comment) SidecarVersion {
+		data, err := json.Marshal(StorageFormat{Version: StorageVersion, Threads: threads})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return SidecarVersion{Commit: fmt.Sprintf("c%d", day), Time: start.AddDate(0, 0, day), Data: data}
+	}
+
+	resolvedFirst := first.Clone()
+	resolvedFirst.Resolved = true
+	resolvedSecond := second.Clone()
+	resolvedSecond.Resolved = true
+
+	points, err := ThreadHistory("doc.md", []SidecarVersion{
+		version(0, first),
+		version(1, first, second),
+		version(2, resolvedFirst, second),
+		version(3, resolvedFirst, resolvedSecond),
+	})
+	if err != nil {
+		t.Fatalf("ThreadHistory failed: %v", err)
+	}
+
+	want := []HistoryPoint{
+		{Threads: 1, Open: 1, Resolved: 0, Opened: 1, Closed: 0},
+		{Threads: 2, Open: 2, Resolved: 0, Opened: 1, Closed: 0},
+		{Threads: 2, Open: 1, Resolved: 1, Opened: 0, Closed: 1},
+		{Threads: 2, Open: 0, Resolved: 2, Opened: 0, Closed: 1},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("Expected %d points, got %d", len(want), len(points))
+	}
+	for i, p := range points {
+		if p.File != "doc.md" || !p.Time.Equal(start.AddDate(0, 0, i)) {
+			t.Errorf("Point %d: file %q time %v", i, p.File, p.Time)
+		}
+		w := want[i]
+		if p.Threads != w.Threads || p.Open != w.Open || p.Resolved != w.Resolved || p.Opened != w.Opened || p.Closed != w.Closed {
+			t.Errorf("Point %d = %+v, want %+v", i, p, w)
+		}
+	}
+}
+
+func TestThreadHistoryRejectsBadVersion(t *testing.T) {
+	_, err := ThreadHistory("doc.md", []SidecarVersion{{Commit: "abc", Data: []byte("{")}})
+	if err == nil {
+		t.Error("Expected an error for an unparseable sidecar")
+	}
+}
@@ -0,0 +1,23 @@
+package comment
+
+// Batch items may carry a client-supplied idempotency key. The sidecar
+// remembers which comment each applied key produced, so a retried agent
+// pipeline that resends the same items doesn't post them twice.
+
+// AppliedKey returns the ID of the comment created for an idempotency key
+// and whether the key has been applied
+func (d *DocumentWithComments) AppliedKey(key string) (string, bool) {
+	id, ok := d.AppliedKeys[key]
+	return id, ok
+}
+
+// RecordKey remembers that key produced (or was satisfied by) comment id
+func (d *DocumentWithComments) RecordKey(key, id string) {
+	if key == "" {
+		return
+	}
+	if d.AppliedKeys == nil {
+		d.AppliedKeys = map[string]string{}
+	}
+	d.AppliedKeys[key] = id
+}
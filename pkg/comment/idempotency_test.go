@@ -0,0 +1,36 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppliedKeysRoundTrip(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("# Title"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := &DocumentWithComments{Content: "# Title", Threads: []*Comment{}}
+	if _, applied := doc.AppliedKey("run-1"); applied {
+		t.Fatal("No keys have been applied yet")
+	}
+
+	doc.RecordKey("run-1", "c1")
+	doc.RecordKey("", "c2") // Items without a key aren't tracked
+	if len(doc.AppliedKeys) != 1 {
+		t.Errorf("Expected one key, got %v", doc.AppliedKeys)
+	}
+
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, applied := loaded.AppliedKey("run-1"); !applied || id != "c1" {
+		t.Errorf("AppliedKey(run-1) = %q, %v after reload", id, applied)
+	}
+}
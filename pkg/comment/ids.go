@@ -0,0 +1,102 @@
+package comment
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator hands out IDs for new comments ("c" prefix) and reviews ("r")
+// Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NextID(prefix string) string
+}
+
+// Clock supplies the time stamped on new comments, reviews, and sidecars
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the default Clock: the wall clock
+type SystemClock struct{}
+
+// Now returns time.Now()
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// FixedClock always returns the same time; useful in tests
+type FixedClock time.Time
+
+// Now returns the fixed time
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+// TimestampIDs is the default IDGenerator: prefix + the clock's Unix time in
+// nanoseconds. IDs never repeat within a process, even when the clock is
+// coarse or stands still, because each one is at least one more than the last.
+type TimestampIDs struct {
+	last atomic.Int64
+}
+
+// NextID returns the next timestamp ID
+func (g *TimestampIDs) NextID(prefix string) string {
+	for {
+		last := g.last.Load()
+		next := max(now().UnixNano(), last+1)
+		if g.last.CompareAndSwap(last, next) {
+			return fmt.Sprintf("%s%d", prefix, next)
+		}
+	}
+}
+
+// SequentialIDs numbers IDs 1, 2, 3, ... per generator; useful in tests
+type SequentialIDs struct {
+	n atomic.Int64
+}
+
+// NextID returns prefix followed by the next number
+func (g *SequentialIDs) NextID(prefix string) string {
+	return fmt.Sprintf("%s%d", prefix, g.n.Add(1))
+}
+
+var (
+	providersMu sync.RWMutex
+	idGenerator IDGenerator = &TimestampIDs{}
+	clock       Clock       = SystemClock{}
+)
+
+// SetIDGenerator replaces the generator used for new IDs and returns a
+// function that restores the previous one
+func SetIDGenerator(g IDGenerator) (restore func()) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	previous := idGenerator
+	idGenerator = g
+	return func() { SetIDGenerator(previous) }
+}
+
+// SetClock replaces the clock used for new timestamps and returns a
+// function that restores the previous one
+func SetClock(c Clock) (restore func()) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	previous := clock
+	clock = c
+	return func() { SetClock(previous) }
+}
+
+// generateID returns a new ID with the given prefix from the current generator
+func generateID(prefix string) string {
+	providersMu.RLock()
+	g := idGenerator
+	providersMu.RUnlock()
+	return g.NextID(prefix)
+}
+
+// now returns the current clock's time in UTC, the zone every stored
+// timestamp uses
+func now() time.Time {
+	providersMu.RLock()
+	c := clock
+	providersMu.RUnlock()
+	return c.Now().UTC()
+}
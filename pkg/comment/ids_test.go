@@ -0,0 +1,66 @@
+package comment
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIDsUniqueWhenClockStandsStill(t *testing.T) {
+	defer SetClock(FixedClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)))()
+	defer SetIDGenerator(&TimestampIDs{})()
+
+	const workers, perWorker = 8, 200
+	ids := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- NewComment("bot", 1, "Batch comment").ID
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[string]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate ID %s", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d IDs, got %d", workers*perWorker, len(seen))
+	}
+}
+
+func TestDeterministicProviders(t *testing.T) {
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	defer SetClock(FixedClock(at))()
+	defer SetIDGenerator(&SequentialIDs{})()
+
+	c := NewComment("alice", 1, "First")
+	r := NewReply("bob", "Second", c)
+	review := NewReview("bob")
+
+	if c.ID != "c1" || r.ID != "c2" || review.ID != "r3" {
+		t.Errorf("Expected sequential IDs c1, c2, r3, got %s, %s, %s", c.ID, r.ID, review.ID)
+	}
+	if !c.Timestamp.Equal(at) || !review.StartedAt.Equal(at) {
+		t.Errorf("Expected timestamps from the fixed clock, got %v and %v", c.Timestamp, review.StartedAt)
+	}
+}
+
+func TestSetIDGeneratorRestores(t *testing.T) {
+	restore := SetIDGenerator(&SequentialIDs{})
+	if id := NewComment("alice", 1, "x").ID; id != "c1" {
+		t.Fatalf("Expected the sequential generator, got %s", id)
+	}
+	restore()
+	if id := NewComment("alice", 1, "x").ID; id == "c2" {
+		t.Error("Restoring should bring back the default generator")
+	}
+}
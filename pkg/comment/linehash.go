@@ -0,0 +1,65 @@
+package comment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// The document hash in the sidecar only says that something changed. Each
+// comment also keeps a hash of its own target line(s), so validation can
+// tell which comments had the text under them edited since they were made
+// (or last reattached), rather than treating every comment as suspect.
+
+// hashLines returns the short hash comments keep of their target text
+func hashLines(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// targetText returns the text of the comment's target line(s) in lines, or
+// false if they are out of range
+func targetText(c *Comment, lines []string) (string, bool) {
+	first, last := c.LineRange()
+	if first < 1 || last > len(lines) || first > last {
+		return "", false
+	}
+	return strings.Join(lines[first-1:last], "\n"), true
+}
+
+// CaptureLineHash stores the hash of the comment's current target line(s) in
+// LineHash. Document-level comments and suggestions (which keep
+// OriginalText) get none.
+func CaptureLineHash(c *Comment, docContent string) {
+	if c.IsSuggestion || c.IsDocumentLevel() {
+		return
+	}
+	if text, ok := targetText(c, strings.Split(docContent, "\n")); ok {
+		c.LineHash = hashLines(text)
+	}
+}
+
+// TargetChanged returns true if the text of the comment's target line(s) in
+// lines (the document split into lines) no longer matches its LineHash.
+// Comments made before line hashes were kept fall back to their Quote;
+// comments with neither never count as changed.
+func (c *Comment) TargetChanged(lines []string) bool {
+	hash := c.LineHash
+	if hash == "" && c.Quote != "" {
+		hash = hashLines(c.Quote)
+	}
+	if hash == "" {
+		return false
+	}
+	text, ok := targetText(c, lines)
+	return !ok || hashLines(text) != hash
+}
+
+// ByTargetChanged matches comments whose target text in content changed
+// since they were made (see TargetChanged)
+func ByTargetChanged(content string) Filter {
+	lines := strings.Split(content, "\n")
+	return func(c *Comment) bool {
+		return c.TargetChanged(lines)
+	}
+}
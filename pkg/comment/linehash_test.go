@@ -0,0 +1,79 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTargetChanged(t *testing.T) {
+	content := "# Title\n\nFirst line\nSecond line\nThird line"
+	c := NewComment("alice", 3, "About the first line")
+	CaptureQuote(c, content)
+	if c.LineHash == "" {
+		t.Fatal("Expected CaptureQuote to store the line hash")
+	}
+
+	if c.TargetChanged(strings.Split(content, "\n")) {
+		t.Error("Unchanged text should not count as changed")
+	}
+	// Lines inserted above move the text but don't change it
+	moved := NewComment("alice", 4, "")
+	moved.LineHash = c.LineHash
+	if moved.TargetChanged(strings.Split("# Title\n\nNew\nFirst line", "\n")) {
+		t.Error("The same text on another line should not count as changed")
+	}
+	if !c.TargetChanged(strings.Split("# Title\n\nFirst line, edited\nSecond line", "\n")) {
+		t.Error("Edited text should count as changed")
+	}
+	if !c.TargetChanged(strings.Split("# Title", "\n")) {
+		t.Error("Text past the end should count as changed")
+	}
+
+	// Ranges hash every line
+	r := NewComment("bob", 3, "About both lines")
+	r.EndLine = 4
+	CaptureLineHash(r, content)
+	if !r.TargetChanged(strings.Split("# Title\n\nFirst line\nSecond line, edited", "\n")) {
+		t.Error("An edit to the range's last line should count as changed")
+	}
+
+	// Older comments fall back to their quote
+	old := NewComment("carol", 3, "Made before line hashes")
+	old.Quote = "First line"
+	if !old.TargetChanged(strings.Split("# Title\n\nFirst line, edited", "\n")) {
+		t.Error("Expected the quote to stand in for a missing line hash")
+	}
+	changed := ByTargetChanged("# Title\n\nFirst line\nSecond line, edited").Apply([]*Comment{c, r, old})
+	if len(changed) != 1 || changed[0] != r {
+		t.Errorf("Expected only the range over the edited line, got %d", len(changed))
+	}
+
+	// Without a hash nothing is known
+	if NewComment("carol", 3, "Old comment").TargetChanged(nil) {
+		t.Error("A comment without a line hash should never count as changed")
+	}
+}
+
+func TestValidationReportsChangedTargets(t *testing.T) {
+	content := "Line 1\nLine 2\nLine 3"
+	edited := NewComment("alice", 2, "On the edited line")
+	kept := NewComment("bob", 3, "On the kept line")
+	CaptureQuote(edited, content)
+	CaptureQuote(kept, content)
+	doc := &DocumentWithComments{
+		Content:      "Line 1\nLine 2, rewritten\nLine 3",
+		Threads:      []*Comment{edited, kept},
+		DocumentHash: ComputeDocumentHash(content),
+	}
+
+	_, issues := ValidateAndUpdateCommentStatus(doc)
+	var changed []string
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, "changed since it was made") {
+			changed = append(changed, issue.CommentID)
+		}
+	}
+	if len(changed) != 1 || changed[0] != edited.ID {
+		t.Errorf("Expected only %s reported, got %v", edited.ID, changed)
+	}
+}
@@ -0,0 +1,258 @@
+package comment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// sidecarSuffix is the default file name suffix of sidecar files
+const sidecarSuffix = ".comments.json"
+
+// externalDir holds sidecars of documents outside the mirrored root
+const externalDir = "_external"
+
+// SidecarLocation says where sidecars are kept. The zero value keeps each
+// sidecar next to its markdown file.
+type SidecarLocation struct {
+	// Dir is a central directory (e.g. ".comments") holding every sidecar in a
+	// tree that mirrors the documents' paths relative to Root
+	Dir string
+
+	// Root is the directory Dir mirrors (default: the working directory)
+	Root string
+
+	// Suffix replaces ".comments.json" in sidecar file names
+	Suffix string
+}
+
+// suffix returns the sidecar file name suffix in effect
+func (l SidecarLocation) suffix() string {
+	if l.Suffix == "" {
+		return sidecarSuffix
+	}
+	return l.Suffix
+}
+
+// ValidateSidecarSuffix checks that a sidecar suffix can be appended to a
+// file name: it starts with "." and contains no path separators
+func ValidateSidecarSuffix(suffix string) error {
+	if len(suffix) < 2 || suffix[0] != '.' || strings.ContainsAny(suffix, `/\`) {
+		return fmt.Errorf("invalid sidecar suffix %q (want e.g. \".review.json\")", suffix)
+	}
+	return nil
+}
+
+var (
+	sidecarLocationMu sync.RWMutex
+	sidecarLocation   *SidecarLocation // Set by SetSidecarLocation, for every document
+)
+
+// SetSidecarLocation changes where sidecars (and their drafts, archives, and
+// backups) are read and written for every document, whatever the
+// SettingsFunc says, and returns a function that restores the previous
+// location. Relative paths are resolved now, so a later change of working
+// directory doesn't move the sidecars.
+func SetSidecarLocation(loc SidecarLocation) (restore func(), err error) {
+	if loc, err = loc.resolve(); err != nil {
+		return nil, err
+	}
+
+	sidecarLocationMu.Lock()
+	defer sidecarLocationMu.Unlock()
+	previous := sidecarLocation
+	sidecarLocation = &loc
+	return func() {
+		sidecarLocationMu.Lock()
+		defer sidecarLocationMu.Unlock()
+		sidecarLocation = previous
+	}, nil
+}
+
+// resolve checks the location's suffix and makes its paths absolute
+func (l SidecarLocation) resolve() (SidecarLocation, error) {
+	if l.Suffix != "" {
+		if err := ValidateSidecarSuffix(l.Suffix); err != nil {
+			return SidecarLocation{}, err
+		}
+	}
+	if l.Dir != "" {
+		if l.Root == "" {
+			l.Root = "."
+		}
+		root, err := filepath.Abs(l.Root)
+		if err != nil {
+			return SidecarLocation{}, fmt.Errorf("invalid sidecar root: %w", err)
+		}
+		l.Root = root
+		if !filepath.IsAbs(l.Dir) {
+			l.Dir = filepath.Join(l.Root, l.Dir)
+		}
+		l.Dir = filepath.Clean(l.Dir)
+	}
+	return l, nil
+}
+
+// sidecarLocationFor returns the location in effect for documents in dir:
+// the one set with SetSidecarLocation, or else the SettingsFunc's. A location
+// that doesn't resolve keeps sidecars next to their documents.
+func sidecarLocationFor(dir string) SidecarLocation {
+	sidecarLocationMu.RLock()
+	set := sidecarLocation
+	sidecarLocationMu.RUnlock()
+	if set != nil {
+		return *set
+	}
+
+	loc, err := settingsFor(dir).Location.resolve()
+	if err != nil {
+		return SidecarLocation{}
+	}
+	return loc
+}
+
+// GetSidecarPath returns the sidecar JSON path for a given markdown file.
+// With a custom location, a sidecar already next to the markdown file under
+// the default name keeps being used, so documents commented on before the
+// location changed carry on working; new sidecars go to the custom location.
+func GetSidecarPath(mdPath string) string {
+	loc := sidecarLocationFor(filepath.Dir(mdPath))
+	path := configuredSidecarPath(loc, mdPath)
+	if path == mdPath+sidecarSuffix {
+		return path
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, err := os.Stat(mdPath + sidecarSuffix); err == nil {
+			return mdPath + sidecarSuffix
+		}
+	}
+	return path
+}
+
+// configuredSidecarPath returns where the location puts a markdown file's sidecar
+func configuredSidecarPath(loc SidecarLocation, mdPath string) string {
+	if loc.Dir == "" {
+		return mdPath + loc.suffix()
+	}
+	return filepath.Join(loc.Dir, mirrorPath(loc.Root, mdPath)) + loc.suffix()
+}
+
+// mirrorPath returns where path sits in a tree mirroring root. Paths outside
+// root (another drive, a UNC share) are placed under _external by volume, so
+// they can't collide with each other or with paths inside root.
+func mirrorPath(root, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = filepath.Clean(path)
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil && !isParentRef(rel) {
+		return rel
+	}
+
+	// "C:" -> "C", `\\server\share` -> `server\share`, `\\?\C:` -> "C"
+	vol := filepath.VolumeName(abs)
+	rest := abs[len(vol):]
+	vol = strings.TrimSuffix(strings.TrimLeft(vol, `\/?.`), ":")
+	return filepath.Join(externalDir, vol, rest)
+}
+
+// isParentRef reports whether a relative path climbs out of its base
+func isParentRef(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && !isParentRef(rel)
+}
+
+// sidecarDir returns the directory holding the sidecars of markdown files in dir
+func sidecarDir(dir string) string {
+	loc := sidecarLocationFor(dir)
+	if loc.Dir == "" {
+		return dir
+	}
+	return filepath.Join(loc.Dir, mirrorPath(loc.Root, dir))
+}
+
+// sidecarSuffixes returns the configured and the default sidecar suffix for
+// documents in dir, longest first so the more specific one matches
+func sidecarSuffixes(dir string) []string {
+	suffixes := []string{sidecarLocationFor(dir).suffix(), sidecarSuffix}
+	if len(suffixes[1]) > len(suffixes[0]) {
+		suffixes[0], suffixes[1] = suffixes[1], suffixes[0]
+	}
+	return suffixes
+}
+
+// hasSuffixFold reports whether name ends with suffix, ignoring case
+// (Windows and macOS file systems don't preserve it reliably)
+func hasSuffixFold(name, suffix string) bool {
+	return len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix)
+}
+
+// markdownName returns the markdown file name a sidecar file name belongs
+// to, for documents in dir. Both the configured and the default suffix are
+// recognized.
+func markdownName(dir, name string) (string, bool) {
+	for _, suffix := range sidecarSuffixes(dir) {
+		if hasSuffixFold(name, suffix) {
+			return name[:len(name)-len(suffix)], true
+		}
+	}
+	return "", false
+}
+
+// ListSidecars finds all sidecar files for markdown files in a directory,
+// in the configured location and next to the files
+func ListSidecars(dir string) ([]string, error) {
+	var sidecars []string
+	index := map[string]int{} // markdown name -> position in sidecars
+
+	dirs := []string{sidecarDir(dir)}
+	if dirs[0] != dir {
+		dirs = append(dirs, dir)
+	}
+	for i, listDir := range dirs {
+		entries, err := os.ReadDir(listDir)
+		if os.IsNotExist(err) && listDir != dir {
+			// Nothing in this directory has been commented on yet
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			name, ok := markdownName(dir, entry.Name())
+			if !ok || (i > 0 && !hasSuffixFold(entry.Name(), sidecarSuffix)) {
+				continue
+			}
+			path := filepath.Join(listDir, entry.Name())
+			if j, dup := index[name]; dup {
+				// Sidecars under both names: keep the one GetSidecarPath uses
+				if path == GetSidecarPath(filepath.Join(dir, name)) {
+					sidecars[j] = path
+				}
+				continue
+			}
+			index[name] = len(sidecars)
+			sidecars = append(sidecars, path)
+		}
+	}
+
+	return sidecars, nil
+}
+
+// MarkdownForSidecar returns the markdown file in dir that a sidecar returned
+// by ListSidecars(dir) belongs to
+func MarkdownForSidecar(dir, sidecarPath string) string {
+	name, _ := markdownName(dir, filepath.Base(sidecarPath))
+	return filepath.Join(dir, name)
+}
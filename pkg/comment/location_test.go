@@ -0,0 +1,213 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSidecarDirMirrorsDocumentTree(t *testing.T) {
+	root := t.TempDir()
+	restore, err := SetSidecarLocation(SidecarLocation{Dir: ".comments", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+
+	if err := os.MkdirAll(filepath.Join(root, "docs", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		filepath.Join(root, "README.md"),
+		filepath.Join(root, "docs", "guide.md"),
+		filepath.Join(root, "docs", "api", "ref.md"),
+	}
+	for _, path := range files {
+		if err := os.WriteFile(path, []byte("line one\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := LoadFromSidecar(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Threads = append(doc.Threads, NewComment("alice", 1, "note"))
+		if err := SaveToSidecar(path, doc); err != nil {
+			t.Fatalf("SaveToSidecar failed: %v", err)
+		}
+	}
+
+	want := filepath.Join(root, ".comments", "docs", "guide.md.comments.json")
+	if got := GetSidecarPath(files[1]); got != want {
+		t.Errorf("GetSidecarPath = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected the sidecar in the central directory: %v", err)
+	}
+	if _, err := os.Stat(files[1] + sidecarSuffix); !os.IsNotExist(err) {
+		t.Error("Expected no sidecar next to the markdown file")
+	}
+
+	doc, err := LoadFromSidecar(files[1])
+	if err != nil || len(doc.Threads) != 1 {
+		t.Fatalf("Expected the comment to load back, got %v, %v", doc, err)
+	}
+
+	docs, err := WalkDocuments(filepath.Join(root, "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0] != files[2] || docs[1] != files[1] {
+		t.Errorf("WalkDocuments = %v, want the two docs under docs/", docs)
+	}
+
+	sidecars, err := ListSidecars(filepath.Join(root, "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sidecars) != 1 || MarkdownForSidecar(filepath.Join(root, "docs"), sidecars[0]) != files[1] {
+		t.Errorf("ListSidecars = %v", sidecars)
+	}
+
+	// A directory nothing has been commented on yet is empty, not an error
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if docs, err := WalkDocuments(filepath.Join(root, "empty")); err != nil || len(docs) != 0 {
+		t.Errorf("Expected no documents, got %v, %v", docs, err)
+	}
+	if sidecars, err := ListSidecars(filepath.Join(root, "empty")); err != nil || len(sidecars) != 0 {
+		t.Errorf("Expected no sidecars, got %v, %v", sidecars, err)
+	}
+}
+
+func TestSidecarDirOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	restore, err := SetSidecarLocation(SidecarLocation{Dir: ".comments", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+
+	outside := filepath.Join(t.TempDir(), "notes.md")
+	abs, _ := filepath.Abs(outside)
+	want := filepath.Join(root, ".comments", externalDir, abs[len(filepath.VolumeName(abs)):]) + sidecarSuffix
+	if got := GetSidecarPath(outside); got != want {
+		t.Errorf("GetSidecarPath = %q, want %q", got, want)
+	}
+}
+
+func TestListSidecarsIgnoresCase(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md.comments.json", "B.MD.COMMENTS.JSON", "c.md", ".comments.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sidecars, err := ListSidecars(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sidecars) != 2 {
+		t.Fatalf("Expected 2 sidecars, got %v", sidecars)
+	}
+	if got := MarkdownForSidecar(dir, filepath.Join(dir, "B.MD.COMMENTS.JSON")); got != filepath.Join(dir, "B.MD") {
+		t.Errorf("MarkdownForSidecar = %q", got)
+	}
+}
+
+func TestCustomSuffixKeepsExistingSidecars(t *testing.T) {
+	root := t.TempDir()
+	legacy := filepath.Join(root, "old.md")
+	fresh := filepath.Join(root, "new.md")
+	for _, path := range []string{legacy, fresh} {
+		if err := os.WriteFile(path, []byte("line one\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// old.md was commented on before the location changed
+	doc, err := LoadFromSidecar(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Threads = append(doc.Threads, NewComment("alice", 1, "before"))
+	if err := SaveToSidecar(legacy, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	restore, err := SetSidecarLocation(SidecarLocation{Dir: "reviews", Root: root, Suffix: ".review.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+
+	if got := GetSidecarPath(legacy); got != legacy+sidecarSuffix {
+		t.Errorf("Expected the existing sidecar to keep being used, got %q", got)
+	}
+	want := filepath.Join(root, "reviews", "new.md.review.json")
+	if got := GetSidecarPath(fresh); got != want {
+		t.Errorf("GetSidecarPath = %q, want %q", got, want)
+	}
+
+	doc, err = LoadFromSidecar(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Threads = append(doc.Threads, NewComment("alice", 1, "after"))
+	if err := SaveToSidecar(fresh, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both schemes are found; the central directory isn't mistaken for documents
+	docs, err := WalkDocuments(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0] != fresh || docs[1] != legacy {
+		t.Errorf("WalkDocuments = %v", docs)
+	}
+	sidecars, err := ListSidecars(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sidecars) != 2 {
+		t.Errorf("ListSidecars = %v", sidecars)
+	}
+
+	if _, err := SetSidecarLocation(SidecarLocation{Suffix: "review.json"}); err == nil {
+		t.Error("Expected a suffix without a leading dot to be rejected")
+	}
+}
+
+func TestSettingsFuncPerDirectory(t *testing.T) {
+	root := t.TempDir()
+	mirrored := filepath.Join(root, "mirrored")
+	restore := SetSettingsFunc(func(dir string) Settings {
+		if dir == mirrored {
+			return Settings{Location: SidecarLocation{Dir: ".review", Root: mirrored, Suffix: ".notes.json"}}
+		}
+		return Settings{}
+	})
+	defer restore()
+
+	plain := filepath.Join(root, "plain.md")
+	if got := GetSidecarPath(plain); got != plain+sidecarSuffix {
+		t.Errorf("GetSidecarPath = %q, want the default", got)
+	}
+	want := filepath.Join(mirrored, ".review", "guide.md.notes.json")
+	if got := GetSidecarPath(filepath.Join(mirrored, "guide.md")); got != want {
+		t.Errorf("GetSidecarPath = %q, want %q", got, want)
+	}
+
+	// A location set for every document wins
+	restoreLocation, err := SetSidecarLocation(SidecarLocation{Dir: ".all", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restoreLocation()
+	want = filepath.Join(root, ".all", "mirrored", "guide.md.comments.json")
+	if got := GetSidecarPath(filepath.Join(mirrored, "guide.md")); got != want {
+		t.Errorf("GetSidecarPath = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package comment
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// logger records loads, validation outcomes, and saves; it discards
+// everything until SetLogger is called
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(slog.New(slog.DiscardHandler))
+}
+
+// SetLogger sets the logger used for storage and validation tracing and
+// returns a function that restores the previous one. A nil logger discards.
+func SetLogger(l *slog.Logger) (restore func()) {
+	if l == nil {
+		l = slog.New(slog.DiscardHandler)
+	}
+	previous := logger.Swap(l)
+	return func() { logger.Store(previous) }
+}
+
+// log returns the current logger
+func log() *slog.Logger {
+	return logger.Load()
+}
@@ -0,0 +1,66 @@
+package comment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoggerTracesLoadAndSave(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))()
+
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("# Title\n\nLine three\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatalf("LoadFromSidecar: %v", err)
+	}
+	doc.Threads = append(doc.Threads, NewComment("alice", 3, "Looks good"))
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatalf("SaveToSidecar: %v", err)
+	}
+	if _, err := LoadFromSidecar(mdPath); err != nil {
+		t.Fatalf("LoadFromSidecar: %v", err)
+	}
+
+	var messages []string
+	var saved map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		messages = append(messages, record["msg"].(string))
+		if record["msg"] == "saved sidecar" {
+			saved = record
+		}
+	}
+
+	want := []string{"loaded document without sidecar", "saved sidecar", "loaded sidecar", "validated comments"}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected messages %v, got %v", want, messages)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("Message %d: expected %q, got %q", i, want[i], messages[i])
+		}
+	}
+	if saved["threads"] != float64(1) || saved["file"] != mdPath {
+		t.Errorf("Unexpected save record: %v", saved)
+	}
+}
+
+func TestLoggerDiscardsByDefault(t *testing.T) {
+	defer SetLogger(nil)()
+	if log().Enabled(context.Background(), slog.LevelError) {
+		t.Error("Expected the default logger to discard records")
+	}
+}
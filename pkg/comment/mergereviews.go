@@ -0,0 +1,117 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// ReviewMerge counts what MergeReviews took from reviewers' copies
+type ReviewMerge struct {
+	Threads    int // Threads added
+	Replies    int // Replies added to threads already there
+	Resolved   int // Threads resolved in a copy
+	Duplicates int // Threads and replies a copy repeated under another ID
+}
+
+// LoadReviewCopy reads a reviewer's copy of a sidecar
+func LoadReviewCopy(path string) (*StorageFormat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review copy: %w", err)
+	}
+	var store StorageFormat
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse review copy %s: %w", path, err)
+	}
+	if store.Version != StorageVersion {
+		return nil, fmt.Errorf("unsupported storage version in %s: %s (expected %s)", path, store.Version, StorageVersion)
+	}
+
+	copied := &DocumentWithComments{Threads: store.Threads, Reviews: store.Reviews}
+	copied.MigrateDocument()
+	copied.NormalizeTimestamps()
+	return &store, nil
+}
+
+// MergeReviews unions the threads in reviewers' copies of doc's sidecar into
+// doc, each keeping its author. A thread or reply is the one already there
+// with its ID (the copies share the threads they started with) or with the
+// same author, place, and text; new replies are added to it, and a
+// resolution made in any copy stands. Suggestions are decided on doc, not in
+// copies, since accepting one changes the markdown: new ones arrive pending.
+func MergeReviews(doc *DocumentWithComments, copies ...*StorageFormat) ReviewMerge {
+	var merge ReviewMerge
+	for _, c := range copies {
+		for _, r := range c.Reviews {
+			if !slices.ContainsFunc(doc.Reviews, func(existing *Review) bool { return existing.ID == r.ID }) {
+				doc.Reviews = append(doc.Reviews, r)
+			}
+		}
+
+		for _, thread := range c.Threads {
+			existing := findReviewCopy(doc.Threads, thread)
+			if existing == nil {
+				added := thread.Clone()
+				if added.IsSuggestion {
+					added.Accepted, added.DecidedAt = nil, nil
+				}
+				doc.Threads = append(doc.Threads, added)
+				merge.Threads++
+				continue
+			}
+			if existing.ID != thread.ID {
+				merge.Duplicates++
+			}
+			mergeReplies(existing, thread, &merge)
+			if thread.Resolved && !existing.Resolved {
+				existing.Resolved = true
+				existing.ResolvedAt = nil
+				if thread.ResolvedAt != nil {
+					at := *thread.ResolvedAt
+					existing.ResolvedAt = &at
+				}
+				merge.Resolved++
+			}
+		}
+	}
+	return merge
+}
+
+// mergeReplies adds the replies in from, at any depth, that into doesn't
+// have
+func mergeReplies(into, from *Comment, merge *ReviewMerge) {
+	for _, reply := range from.Replies {
+		existing := findReviewCopy(into.Replies, reply)
+		if existing == nil {
+			into.Replies = append(into.Replies, reply.Clone())
+			merge.Replies++
+			continue
+		}
+		if existing.ID != reply.ID {
+			merge.Duplicates++
+		}
+		mergeReplies(existing, reply, merge)
+	}
+}
+
+// findReviewCopy returns the comment among comments that c is a copy of:
+// the one with its ID, or else with its author, lines, and text
+func findReviewCopy(comments []*Comment, c *Comment) *Comment {
+	for _, existing := range comments {
+		if existing.ID == c.ID {
+			return existing
+		}
+	}
+	first, last := c.LineRange()
+	for _, existing := range comments {
+		eFirst, eLast := existing.LineRange()
+		if existing.Author == c.Author && eFirst == first && eLast == last &&
+			existing.IsSuggestion == c.IsSuggestion && existing.ProposedText == c.ProposedText &&
+			TextSimilarity(existing.Text, c.Text) == 1 {
+			return existing
+		}
+	}
+	return nil
+}
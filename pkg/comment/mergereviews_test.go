@@ -0,0 +1,74 @@
+package comment
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeReviews(t *testing.T) {
+	base := NewComment("alice", 3, "Needs a diagram")
+	doc := &DocumentWithComments{Threads: []*Comment{base}}
+
+	// Both reviewers start from the base sidecar
+	bob := base.Clone()
+	bob.Replies = append(bob.Replies, NewReply("bob", "Agreed", bob))
+	bobNew := NewComment("bob", 5, "Typo here")
+	carol := base.Clone()
+	carol.Replies = append(carol.Replies, NewReply("carol", "I'll draw one", carol))
+	ResolveThread([]*Comment{carol}, carol.ID)
+	// Carol made the same comment as Bob, and copied Bob's into her file
+	carolOwn := NewComment("carol", 5, "Typo here")
+	carolCopied := NewComment("bob", 5, "typo here.")
+
+	merge := MergeReviews(doc,
+		&StorageFormat{Threads: []*Comment{bob, bobNew}},
+		&StorageFormat{Threads: []*Comment{carol, carolOwn, carolCopied}, Reviews: []*Review{{ID: "r1", Reviewer: "carol"}}},
+	)
+
+	want := ReviewMerge{Threads: 2, Replies: 2, Resolved: 1, Duplicates: 1}
+	if merge != want {
+		t.Errorf("MergeReviews = %+v, want %+v", merge, want)
+	}
+	if len(doc.Threads) != 3 || doc.Threads[1].Author != "bob" || doc.Threads[2].Author != "carol" {
+		t.Fatalf("Expected the base thread, Bob's, and Carol's: %+v", doc.Threads)
+	}
+	if !base.Resolved || base.ResolvedAt == nil || len(base.Replies) != 2 {
+		t.Errorf("Expected both replies on the resolved base thread: %+v", base)
+	}
+	if len(doc.Reviews) != 1 {
+		t.Errorf("Expected Carol's review session, got %d", len(doc.Reviews))
+	}
+
+	// A suggestion accepted in a copy changed only the copy's markdown
+	accepted := NewSuggestion("bob", 4, 4, "Fix", "Line two", "Line 2")
+	yes := true
+	accepted.Accepted = &yes
+	MergeReviews(doc, &StorageFormat{Threads: []*Comment{accepted}})
+	if merged := doc.Threads[len(doc.Threads)-1]; !merged.IsPending() || accepted.Accepted == nil {
+		t.Errorf("Expected the suggestion to arrive pending, leaving the copy alone: %+v", merged)
+	}
+
+	// Merging the same copy again adds nothing
+	if again := MergeReviews(doc, &StorageFormat{Threads: []*Comment{bob, bobNew}}); again != (ReviewMerge{}) {
+		t.Errorf("Merging again = %+v, want nothing", again)
+	}
+}
+
+func TestLoadReviewCopy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "copy.json")
+	data, _ := json.Marshal(StorageFormat{Version: StorageVersion, Threads: []*Comment{NewComment("bob", 1, "Hi")}})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := LoadReviewCopy(path)
+	if err != nil || len(store.Threads) != 1 || store.Threads[0].Status != "active" {
+		t.Fatalf("LoadReviewCopy = %+v, %v", store, err)
+	}
+
+	os.WriteFile(path, []byte(`{"version":"1.0","threads":[]}`), 0644)
+	if _, err := LoadReviewCopy(path); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
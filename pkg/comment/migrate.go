@@ -0,0 +1,164 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/markdown"
+)
+
+// Migrating moves threads whose section was cut from one document and pasted
+// into another, so they land on the pasted text instead of staying orphaned
+// in the source sidecar.
+
+// InSectionPath reports whether a comment's section path lies within the
+// named section. The section may be given by title ("Appendix") or as a
+// partial path ("Appendix > Glossary"), so a section pasted under a
+// different parent still matches.
+func InSectionPath(commentPath, sectionPath string) bool {
+	if commentPath == "" || sectionPath == "" {
+		return false
+	}
+	return strings.Contains(" > "+commentPath+" > ", " > "+sectionPath+" > ")
+}
+
+// MigrateSection moves the threads in a section of from to the same section
+// of to, returning the moved threads. The destination section may be named by
+// the end of its path when that is unambiguous. Lines are remapped into the
+// destination section: to the line matching the comment's quote when there
+// is one, otherwise keeping the offset from the start of the section if the
+// source still has it, otherwise to the section's first line. Moved threads
+// are reactivated if the cut orphaned them.
+func MigrateSection(from, to *DocumentWithComments, sectionPath string) ([]*Comment, error) {
+	structure := to.Structure()
+	destPath, err := findPastedSection(structure, sectionPath)
+	if err != nil {
+		return nil, err
+	}
+	destStart, destEnd, err := ResolveSectionToLines(to, destPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The section is usually gone from the source after the cut, but if it
+	// was copied instead, its start lets lines keep their offset
+	srcStart := 0
+	if start, _, err := ResolveSectionToLines(from, sectionPath, true); err == nil {
+		srcStart = start
+	}
+
+	existing := map[string]bool{}
+	for _, thread := range to.Threads {
+		existing[thread.ID] = true
+	}
+
+	destLines := strings.Split(to.Content, "\n")
+	var moved, kept []*Comment
+	for _, thread := range from.Threads {
+		if !InSectionPath(thread.SectionPath, sectionPath) {
+			kept = append(kept, thread)
+			continue
+		}
+		if existing[thread.ID] {
+			return nil, fmt.Errorf("thread %s is already in the destination", thread.ID)
+		}
+		moved = append(moved, thread)
+	}
+
+	for _, thread := range moved {
+		start, _ := thread.LineRange()
+		target := migratedLine(thread, destLines, destStart, destEnd, srcStart)
+		shiftThread(thread, target-start)
+		for _, c := range append([]*Comment{thread}, flattenReplies(thread.Replies)...) {
+			if c.IsOrphaned() {
+				c.Status = "active"
+				c.OrphanedReason = ""
+				c.OrphanedAt = nil
+			}
+			if section, ok := structure.SectionsByLine[c.Line]; ok {
+				c.SectionID = section.ID
+				c.SectionPath = section.GetFullPath(structure.SectionsByID)
+			}
+		}
+	}
+
+	if kept == nil {
+		kept = []*Comment{}
+	}
+	from.Threads = kept
+	to.Threads = append(to.Threads, moved...)
+	return moved, nil
+}
+
+// findPastedSection returns the full path of the section in the destination:
+// sectionPath itself, or the one section whose path ends with it
+func findPastedSection(structure *markdown.DocumentStructure, sectionPath string) (string, error) {
+	if structure.FindSection(sectionPath) != nil {
+		return sectionPath, nil
+	}
+
+	var matches []string
+	for _, path := range structure.ListAllPaths() {
+		if strings.HasSuffix(" > "+path, " > "+sectionPath) {
+			matches = append(matches, path)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		available := structure.ListAllPaths()
+		if len(available) == 0 {
+			return "", fmt.Errorf("section '%s' not found: document has no headings", sectionPath)
+		}
+		return "", fmt.Errorf("section '%s' not found\nAvailable sections:\n  - %s", sectionPath, joinPaths(available))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("section '%s' is ambiguous, use the full path:\n  - %s", sectionPath, joinPaths(matches))
+	}
+}
+
+// migratedLine picks the destination line for a thread's first line
+func migratedLine(thread *Comment, destLines []string, destStart, destEnd, srcStart int) int {
+	start, _ := thread.LineRange()
+	expected := destStart
+	if srcStart > 0 && start >= srcStart {
+		expected = min(destStart+start-srcStart, destEnd)
+	}
+
+	quote, _, _ := strings.Cut(thread.Quote, "\n")
+	quote = strings.TrimSpace(quote)
+	if quote == "" {
+		return expected
+	}
+
+	// The matching line nearest to where the offset would put it
+	best := 0
+	for line := destStart; line <= destEnd && line <= len(destLines); line++ {
+		if strings.TrimSpace(destLines[line-1]) != quote {
+			continue
+		}
+		if best == 0 || abs(line-expected) < abs(best-expected) {
+			best = line
+		}
+	}
+	if best == 0 {
+		return expected
+	}
+	return best
+}
+
+// shiftThread moves every position in a thread by delta lines
+func shiftThread(thread *Comment, delta int) {
+	for _, c := range append([]*Comment{thread}, flattenReplies(thread.Replies)...) {
+		insertion := c.IsInsertion() // An insertion at the top has EndLine 0
+		if c.Line > 0 {
+			c.Line += delta
+		}
+		if c.StartLine > 0 {
+			c.StartLine += delta
+		}
+		if c.EndLine > 0 || insertion {
+			c.EndLine += delta
+		}
+	}
+}
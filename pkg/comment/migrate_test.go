@@ -0,0 +1,89 @@
+package comment
+
+import "testing"
+
+func TestInSectionPath(t *testing.T) {
+	tests := []struct {
+		commentPath, section string
+		want                 bool
+	}{
+		{"Appendix", "Appendix", true},
+		{"Appendix > Glossary", "Appendix", true},
+		{"Guide > Appendix > Glossary", "Appendix", true},
+		{"Guide > Appendix > Glossary", "Appendix > Glossary", true},
+		{"Appendix B", "Appendix", false},
+		{"Intro", "Appendix", false},
+		{"", "Appendix", false},
+	}
+	for _, tt := range tests {
+		if got := InSectionPath(tt.commentPath, tt.section); got != tt.want {
+			t.Errorf("InSectionPath(%q, %q) = %v, want %v", tt.commentPath, tt.section, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateSectionMovesCutThreads(t *testing.T) {
+	// The source after "## Appendix" was cut; its threads were orphaned
+	from := &DocumentWithComments{Content: "# Guide\n\nIntro text\n"}
+	quoted := &Comment{ID: "c1", Line: 6, Quote: "Term: meaning", SectionPath: "Guide > Appendix", Status: "orphaned", OrphanedReason: "Section 'Guide > Appendix' no longer exists",
+		Replies: []*Comment{{ID: "r1", Line: 6, Replies: []*Comment{}}}}
+	unquoted := &Comment{ID: "c2", Line: 7, SectionPath: "Guide > Appendix", Replies: []*Comment{}}
+	other := &Comment{ID: "c3", Line: 3, SectionPath: "Guide", Replies: []*Comment{}}
+	from.Threads = []*Comment{quoted, unquoted, other}
+
+	to := &DocumentWithComments{
+		Content: "# Reference\n\n## Appendix\n\nPreamble\n\nTerm: meaning\n",
+		Threads: []*Comment{{ID: "c9", Line: 1, Replies: []*Comment{}}},
+	}
+
+	if _, err := MigrateSection(from, to, "Glossary"); err == nil {
+		t.Fatal("Expected an error for a section missing from the destination")
+	}
+
+	// "Appendix" is found as "Reference > Appendix"
+	moved, err := MigrateSection(from, to, "Appendix")
+	if err != nil {
+		t.Fatalf("MigrateSection failed: %v", err)
+	}
+	if len(moved) != 2 || len(from.Threads) != 1 || from.Threads[0] != other || len(to.Threads) != 3 {
+		t.Fatalf("Expected c1 and c2 moved, got %d moved, %d left, %d in destination", len(moved), len(from.Threads), len(to.Threads))
+	}
+
+	if quoted.Line != 7 || quoted.Replies[0].Line != 7 {
+		t.Errorf("Quoted thread should land on its quoted line 7, got %d (reply %d)", quoted.Line, quoted.Replies[0].Line)
+	}
+	if unquoted.Line != 3 {
+		t.Errorf("Unquoted thread should land on the section start, got %d", unquoted.Line)
+	}
+	if quoted.IsOrphaned() || quoted.OrphanedReason != "" {
+		t.Error("Moved thread should be active again")
+	}
+	if quoted.SectionPath != "Reference > Appendix" {
+		t.Errorf("SectionPath = %q, want the destination's", quoted.SectionPath)
+	}
+}
+
+func TestMigrateSectionKeepsOffsetWhenCopied(t *testing.T) {
+	from := &DocumentWithComments{Content: "# Notes\n\n## Appendix\n\none\ntwo\n"}
+	from.Threads = []*Comment{{ID: "c1", Line: 6, SectionPath: "Notes > Appendix", Replies: []*Comment{}}}
+	to := &DocumentWithComments{Content: "# Notes\n\nMore\n\n## Appendix\n\none\ntwo\n", Threads: []*Comment{}}
+
+	if _, err := MigrateSection(from, to, "Notes > Appendix"); err != nil {
+		t.Fatalf("MigrateSection failed: %v", err)
+	}
+	if got := to.Threads[0].Line; got != 8 {
+		t.Errorf("Expected the thread to keep its offset in the section (line 8), got %d", got)
+	}
+}
+
+func TestMigrateSectionRefusesDuplicates(t *testing.T) {
+	from := &DocumentWithComments{Content: "# A\n", Threads: []*Comment{{ID: "c1", Line: 1, SectionPath: "A", Replies: []*Comment{}}}}
+	to := &DocumentWithComments{Content: "# A\n", Threads: []*Comment{{ID: "c1", Line: 1, Replies: []*Comment{}}}}
+
+	if _, err := MigrateSection(from, to, "A"); err == nil {
+		t.Error("Expected an error when the thread is already in the destination")
+	}
+	if len(from.Threads) != 1 || len(to.Threads) != 1 {
+		t.Error("Nothing should move when migration fails")
+	}
+}
@@ -0,0 +1,62 @@
+package comment
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/markdown"
+)
+
+// Documents other than plain markdown (notebooks, MDX) are handled through
+// their markdown.Model: loading reads the file's text view into Content, so
+// comment lines, quotes, and sections all refer to the view, and saving
+// merges an edited view back into the file.
+
+// Structure returns the sections of the document, as its model finds them
+func (d *DocumentWithComments) Structure() *markdown.DocumentStructure {
+	return d.model().Parse(d.Content)
+}
+
+// model returns the document's model, defaulting to markdown
+func (d *DocumentWithComments) model() markdown.Model {
+	if d.Model == nil {
+		return markdown.Markdown
+	}
+	return d.Model
+}
+
+// isPlainText reports whether a model's files are their own text view, so
+// Content can be written as is and a thread store embedded at the end
+func isPlainText(m markdown.Model) bool {
+	return m == nil || m == markdown.Markdown || m == markdown.MDX
+}
+
+// readDocument reads a document file and returns its text view and model
+func readDocument(mdPath string) (string, TextFormat, markdown.Model, error) {
+	text, format, err := readMarkdown(mdPath)
+	if err != nil {
+		return "", TextFormat{}, nil, err
+	}
+	model := markdown.ModelFor(mdPath)
+	view, err := model.View(text)
+	if err != nil {
+		return "", TextFormat{}, nil, fmt.Errorf("failed to read %s as %s: %w", mdPath, model.Name(), err)
+	}
+	return view, format, model, nil
+}
+
+// encodeDocument returns the file contents for a document's text view,
+// merging it into the file on disk for models that aren't plain text
+func encodeDocument(mdPath string, doc *DocumentWithComments) ([]byte, error) {
+	if isPlainText(doc.Model) {
+		return doc.Format.Encode(doc.Content), nil
+	}
+	source, _, err := readMarkdown(mdPath)
+	if err != nil {
+		return nil, err
+	}
+	text, err := doc.Model.Update(source, doc.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", mdPath, err)
+	}
+	return doc.Format.Encode(text), nil
+}
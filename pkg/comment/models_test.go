@@ -0,0 +1,102 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Results\n",
+    "Accuracy is 90%."
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": null,
+   "metadata": {},
+   "outputs": [],
+   "source": [
+    "# train the model\n",
+    "model.fit(x, y)"
+   ]
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestNotebookDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analysis.ipynb")
+	if err := os.WriteFile(path, []byte(testNotebook), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(doc.Content, "\n")
+	if lines[0] != "%% cell 1 [markdown]" || lines[5] != "model.fit(x, y)" {
+		t.Fatalf("Unexpected notebook view %q", doc.Content)
+	}
+
+	// Comments anchor to cells, and headings within markdown cells
+	c := NewComment("alice", 3, "Which dataset?")
+	UpdateCommentSection(c, doc)
+	if c.SectionPath != "Cell 1 > Results" {
+		t.Errorf("SectionPath = %q, want Cell 1 > Results", c.SectionPath)
+	}
+	code := NewComment("alice", 6, "Seed this")
+	UpdateCommentSection(code, doc)
+	if code.SectionPath != "Cell 2" {
+		t.Errorf("SectionPath = %q, want Cell 2 (code comments aren't headings)", code.SectionPath)
+	}
+	doc.Threads = append(doc.Threads, c, code)
+
+	// Edits to the view are merged back into the notebook
+	doc.Content = strings.Replace(doc.Content, "90%", "92%", 1)
+	if err := SaveToSidecar(path, doc); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"Accuracy is 92%."`) || !strings.Contains(string(data), `"execution_count": null`) {
+		t.Errorf("Notebook after save:\n%s", data)
+	}
+
+	reloaded, err := LoadFromSidecar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Content != doc.Content || len(reloaded.Threads) != 2 {
+		t.Errorf("Reloaded view = %q with %d threads", reloaded.Content, len(reloaded.Threads))
+	}
+
+	if err := ConvertStorage(path, StorageEmbedded); err == nil {
+		t.Error("Notebooks should not accept embedded storage")
+	}
+}
+
+func TestWalkIncludesNotebooksAndMDX(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.mdx", "c.ipynb", "d.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := WalkMarkdownFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected a.md, b.mdx, and c.ipynb, got %v", files)
+	}
+}
@@ -0,0 +1,140 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A move suggestion cuts lines StartLine-EndLine and pastes them after line
+// MoveAfter, as one change. Restructuring a document this way with replace
+// suggestions takes two of them (delete here, insert there) that have to be
+// accepted together; a move is accepted or rejected as a whole, and comments
+// on the moved lines travel with them.
+
+// NewMove creates a suggestion that moves lines startLine-endLine to after
+// line afterLine (0 for the top). originalText is the text of the lines, so
+// the move can be checked against the document when it is applied.
+func NewMove(author string, startLine, endLine, afterLine int, text, originalText string) *Comment {
+	s := NewSuggestion(author, startLine, endLine, text, originalText, "")
+	s.IsMove = true
+	s.MoveAfter = afterLine
+	return s
+}
+
+// CheckMove returns an error if a move of lines start-end to after line after
+// is out of range for a document of lineCount lines or changes nothing
+func CheckMove(start, end, after, lineCount int) error {
+	if start < 1 || end < start || end > lineCount {
+		return fmt.Errorf("lines %d-%d out of range (1-%d)", start, end, lineCount)
+	}
+	if after < 0 || after > lineCount {
+		return fmt.Errorf("cannot move after line %d (document has %d lines)", after, lineCount)
+	}
+	if after >= start && after < end {
+		return fmt.Errorf("cannot move lines %d-%d to after line %d, which is one of them", start, end, after)
+	}
+	if after == start-1 || after == end {
+		return fmt.Errorf("moving lines %d-%d to after line %d would not change the document", start, end, after)
+	}
+	return nil
+}
+
+// applyMove returns lines with the move applied
+func applyMove(lines []string, s *Comment) []string {
+	block := lines[s.StartLine-1 : s.EndLine]
+	rest := append(append([]string{}, lines[:s.StartLine-1]...), lines[s.EndLine:]...)
+
+	// Where the block goes among the remaining lines
+	at := s.MoveAfter
+	if at > s.EndLine {
+		at -= len(block)
+	}
+
+	result := make([]string, 0, len(lines))
+	result = append(result, rest[:at]...)
+	result = append(result, block...)
+	return append(result, rest[at:]...)
+}
+
+// previewMove renders a move as the lines it removes and where they go
+func previewMove(lines []string, s *Comment) string {
+	var preview strings.Builder
+	fmt.Fprintf(&preview, "--- Lines %d-%d\n", s.StartLine, s.EndLine)
+	for _, line := range lines[s.StartLine-1 : s.EndLine] {
+		fmt.Fprintf(&preview, "- %s\n", line)
+	}
+
+	if s.MoveAfter == 0 {
+		preview.WriteString("\n+++ Top of document\n")
+	} else {
+		fmt.Fprintf(&preview, "\n+++ After line %d\n", s.MoveAfter)
+		fmt.Fprintf(&preview, "  %s\n", lines[s.MoveAfter-1])
+	}
+	for _, line := range lines[s.StartLine-1 : s.EndLine] {
+		fmt.Fprintf(&preview, "+ %s\n", line)
+	}
+	return preview.String()
+}
+
+// movedLine returns where line ends up after lines start-end move to after
+// line after
+func movedLine(line, start, end, after int) int {
+	n := end - start + 1
+	switch {
+	case line >= start && line <= end && after > end:
+		return line + after - end
+	case line >= start && line <= end:
+		return line - (start - 1 - after)
+	case after > end && line > end && line <= after:
+		return line - n // Lines the block moved past shift up
+	case after < start && line > after && line < start:
+		return line + n // Lines the block moved ahead of shift down
+	}
+	return line
+}
+
+// RecordMove updates a document's comments after lines start-end of Content
+// were moved to after line after: comments on the moved lines move with them,
+// and comments on lines they moved past shift to make room. Sections are
+// recomputed, since headings may have moved.
+func (d *DocumentWithComments) RecordMove(start, end, after int) {
+	recalculateMovedLines(d.Threads, start, end, after)
+	RecomputeAllSections(d)
+}
+
+// recalculateMovedLines moves the positions of comments and their replies
+// for a move of lines start-end to after line after
+func recalculateMovedLines(comments []*Comment, start, end, after int) {
+	move := func(line int) int {
+		if line < 1 {
+			return line
+		}
+		return movedLine(line, start, end, after)
+	}
+
+	for _, c := range comments {
+		isRange := c.IsRange()
+		c.Line = move(c.Line)
+		if isRange {
+			// A range split by the move keeps only its first line
+			if c.EndLine = move(c.EndLine); c.EndLine <= c.Line {
+				c.EndLine = 0
+			}
+		}
+
+		if c.IsPending() {
+			if c.IsInsertion() {
+				c.EndLine = move(c.EndLine)
+				c.StartLine = c.EndLine + 1
+			} else {
+				c.StartLine, c.EndLine = move(c.StartLine), move(c.EndLine)
+				c.EndLine = max(c.EndLine, c.StartLine)
+			}
+			if c.IsMove {
+				c.MoveAfter = move(c.MoveAfter)
+			}
+		}
+
+		recalculateMovedLines(c.Replies, start, end, after)
+	}
+}
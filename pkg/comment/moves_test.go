@@ -0,0 +1,118 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyMove(t *testing.T) {
+	content := "1\n2\n3\n4\n5\n6"
+
+	tests := []struct {
+		start, end, after int
+		expected          string
+	}{
+		{2, 3, 5, "1\n4\n5\n2\n3\n6"}, // Down
+		{4, 5, 1, "1\n4\n5\n2\n3\n6"}, // Up
+		{5, 6, 0, "5\n6\n1\n2\n3\n4"}, // To the top
+		{1, 1, 6, "2\n3\n4\n5\n6\n1"}, // To the end
+	}
+	for _, tt := range tests {
+		lines := strings.Split(content, "\n")
+		move := NewMove("alice", tt.start, tt.end, tt.after, "Reorder", strings.Join(lines[tt.start-1:tt.end], "\n"))
+		result, err := ApplySuggestion(content, move)
+		if err != nil {
+			t.Fatalf("Move %d-%d after %d: %v", tt.start, tt.end, tt.after, err)
+		}
+		if result != tt.expected {
+			t.Errorf("Move %d-%d after %d = %q, want %q", tt.start, tt.end, tt.after, result, tt.expected)
+		}
+	}
+
+	for _, after := range []int{1, 3, 4, 7} {
+		if _, err := ApplySuggestion(content, NewMove("alice", 2, 4, after, "No-op or invalid", "")); err == nil {
+			t.Errorf("Expected an error moving lines 2-4 after line %d", after)
+		}
+	}
+	if _, err := ApplySuggestion(content, NewMove("alice", 2, 3, 5, "Stale", "two\nthree")); err == nil {
+		t.Error("Expected an error when the moved text no longer matches")
+	}
+}
+
+func TestPreviewMove(t *testing.T) {
+	preview, err := PreviewSuggestion("a\nb\nc", NewMove("alice", 1, 1, 3, "Last", "a"))
+	if err != nil {
+		t.Fatalf("PreviewSuggestion failed: %v", err)
+	}
+	for _, want := range []string{"Move lines 1-1 after line 3", "- a", "+++ After line 3", "  c", "+ a"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview missing %q:\n%s", want, preview)
+		}
+	}
+}
+
+func TestRecordMove(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# A\n\na\n# B\n\nb\n"}
+	onMoved := NewComment("alice", 5, "Moves with B")
+	passed := NewComment("alice", 2, "Shifts down")
+	rangeSplit := NewComment("alice", 3, "Split")
+	rangeSplit.EndLine = 5
+	pending := NewSuggestion("bob", 6, 6, "Edit b", "b", "bee")
+	insertion := NewInsertion("bob", 3, "After a", "more")
+	doc.Threads = []*Comment{onMoved, passed, rangeSplit, pending, insertion}
+
+	// Move section B (lines 4-6) to the top
+	move := NewMove("carol", 4, 6, 0, "B first", "# B\n\nb")
+	content, err := ApplySuggestion(doc.Content, move)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Content = content
+	doc.RecordApplied(move)
+
+	if onMoved.Line != 2 || onMoved.SectionPath != "B" {
+		t.Errorf("Comment on the moved lines at line %d in %q, want 2 in B", onMoved.Line, onMoved.SectionPath)
+	}
+	if passed.Line != 5 {
+		t.Errorf("Comment the lines moved ahead of at line %d, want 5", passed.Line)
+	}
+	if rangeSplit.Line != 6 || rangeSplit.IsRange() {
+		t.Errorf("Split range at %d-%d, want a single line at 6", rangeSplit.Line, rangeSplit.EndLine)
+	}
+	if pending.StartLine != 3 || pending.EndLine != 3 {
+		t.Errorf("Pending suggestion at %d-%d, want 3-3", pending.StartLine, pending.EndLine)
+	}
+	if !insertion.IsInsertion() || insertion.EndLine != 6 {
+		t.Errorf("Insertion after line %d, want after 6", insertion.EndLine)
+	}
+	if _, err := ApplySuggestion(doc.Content, pending); err != nil {
+		t.Errorf("Pending suggestion no longer applies: %v", err)
+	}
+}
+
+func TestDetectConflictsMoves(t *testing.T) {
+	move := NewMove("alice", 1, 2, 6, "Down", "")
+	into := NewSuggestion("bob", 5, 8, "Rewrite", "", "x")
+	if !HasConflicts(DetectConflicts([]*Comment{move, into})) {
+		t.Error("Expected a conflict for lines moved into a replaced range")
+	}
+
+	sameTarget := NewInsertion("bob", 6, "Add", "x")
+	if !HasConflicts(DetectConflicts([]*Comment{move, sameTarget})) {
+		t.Error("Expected a conflict for lines moved to where another suggestion inserts")
+	}
+
+	elsewhere := NewSuggestion("bob", 9, 9, "Edit", "", "x")
+	if HasConflicts(DetectConflicts([]*Comment{move, elsewhere})) {
+		t.Error("Expected no conflict with an edit away from the move")
+	}
+}
+
+func TestRecalculateMoveTarget(t *testing.T) {
+	move := NewMove("alice", 2, 3, 10, "Down", "")
+	// Three lines inserted before the target
+	RecalculateCommentLines([]*Comment{move}, 5, 5, 4)
+	if move.MoveAfter != 13 {
+		t.Errorf("Move target = %d, want 13", move.MoveAfter)
+	}
+}
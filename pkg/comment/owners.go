@@ -0,0 +1,204 @@
+package comment
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sections can have required reviewers, like CODEOWNERS for a document: a
+// pending suggestion in an owned section waits on one of its owners. The
+// project config maps section patterns to owners; this file decides which
+// suggestions are still waiting, and which sections changed since the last
+// submitted review so a CI gate can hold back only those.
+
+// MatchSectionPattern reports whether a section path, or a section it is
+// nested in, matches pattern. Patterns are section paths whose headings may
+// use path.Match wildcards, e.g. "API > *" or "Guide > Setup*"; a pattern
+// naming a section covers its subsections too.
+func MatchSectionPattern(pattern, sectionPath string) bool {
+	if pattern == "" || sectionPath == "" {
+		return false
+	}
+	patterns := splitSectionPath(pattern)
+	headings := splitSectionPath(sectionPath)
+	if len(patterns) > len(headings) {
+		return false
+	}
+	for i, p := range patterns {
+		if ok, err := path.Match(p, headings[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateSectionPattern returns an error if pattern has a malformed wildcard
+func ValidateSectionPattern(pattern string) error {
+	for _, p := range splitSectionPath(pattern) {
+		if _, err := path.Match(p, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitSectionPath splits "A > B" into its headings
+func splitSectionPath(sectionPath string) []string {
+	parts := strings.Split(sectionPath, ">")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// AwaitingOwner is a pending suggestion that none of its section's owners
+// has reviewed yet. Owners leaves out the suggestion's author, unless they
+// are its section's only owner.
+type AwaitingOwner struct {
+	Suggestion *Comment
+	Owners     []string
+}
+
+// AwaitingOwnerReview returns the pending suggestions in owned sections that
+// no owner has reviewed, in document order. ownersOf returns the owners of a
+// section path (none if it is unowned).
+func AwaitingOwnerReview(doc *DocumentWithComments, ownersOf func(sectionPath string) []string) []AwaitingOwner {
+	var awaiting []AwaitingOwner
+	for _, s := range GetPendingSuggestions(doc.Threads) {
+		owners := ownersOf(s.SectionPath)
+		if len(owners) == 0 || doc.ReviewedBy(s, owners) {
+			continue
+		}
+		awaiting = append(awaiting, AwaitingOwner{Suggestion: s, Owners: reviewersOf(s, owners)})
+	}
+	sort.SliceStable(awaiting, func(i, j int) bool {
+		return awaiting[i].Suggestion.StartLine < awaiting[j].Suggestion.StartLine
+	})
+	return awaiting
+}
+
+// reviewersOf returns the owners who can review s: all but its author,
+// unless the author is the only one
+func reviewersOf(s *Comment, owners []string) []string {
+	var others []string
+	for _, o := range owners {
+		if !strings.EqualFold(o, s.Author) {
+			others = append(others, o)
+		}
+	}
+	if len(others) == 0 {
+		return owners
+	}
+	return others
+}
+
+// ReviewedBy reports whether any of reviewers other than its author has
+// reviewed suggestion s: by commenting in its thread, or submitting a review
+// of the document since it was made
+func (d *DocumentWithComments) ReviewedBy(s *Comment, reviewers []string) bool {
+	is := func(name string) bool {
+		if strings.EqualFold(name, s.Author) {
+			return false
+		}
+		for _, r := range reviewers {
+			if strings.EqualFold(r, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, thread := range d.Threads {
+		inThread := append([]*Comment{thread}, flattenReplies(thread.Replies)...)
+		if !containsComment(inThread, s) {
+			continue
+		}
+		for _, c := range inThread {
+			if is(c.Author) {
+				return true
+			}
+		}
+	}
+	return d.submittedReviewSince(s.Timestamp, is)
+}
+
+// containsComment reports whether comments includes c
+func containsComment(comments []*Comment, c *Comment) bool {
+	for _, other := range comments {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+// submittedReviewSince reports whether a reviewer matching is submitted a
+// review after since
+func (d *DocumentWithComments) submittedReviewSince(since time.Time, is func(string) bool) bool {
+	for _, r := range d.Reviews {
+		if r.SubmittedAt != nil && r.SubmittedAt.After(since) && is(r.Reviewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// SectionHashes returns a hash of each section's text, subsections
+// included, keyed by section path
+func (d *DocumentWithComments) SectionHashes() map[string]string {
+	structure := d.Structure()
+	lines := strings.Split(d.Content, "\n")
+	hashes := map[string]string{}
+	for _, section := range structure.SectionsByID {
+		start, end := section.StartLine, min(section.EndLine, len(lines))
+		if start < 1 || start > end {
+			continue
+		}
+		hashes[section.GetFullPath(structure.SectionsByID)] = hashLines(strings.Join(lines[start-1:end], "\n"))
+	}
+	return hashes
+}
+
+// SectionChanged reports whether a section's text differs from when the
+// newest review recording section hashes was submitted. With no such review
+// every section counts as changed, as does one the review didn't see.
+func (d *DocumentWithComments) SectionChanged(sectionPath string) bool {
+	return d.changedSections()(sectionPath)
+}
+
+// changedSections returns SectionChanged for d, hashing its sections once
+func (d *DocumentWithComments) changedSections() func(sectionPath string) bool {
+	var baseline *Review
+	for _, r := range d.Reviews {
+		if r.SubmittedAt == nil || r.Sections == nil {
+			continue
+		}
+		if baseline == nil || r.SubmittedAt.After(*baseline.SubmittedAt) {
+			baseline = r
+		}
+	}
+	if baseline == nil {
+		return func(string) bool { return true }
+	}
+
+	current := d.SectionHashes()
+	return func(sectionPath string) bool {
+		hash, ok := baseline.Sections[sectionPath]
+		return !ok || current[sectionPath] != hash
+	}
+}
+
+// AwaitingInChangedSections keeps the suggestions awaiting owners whose
+// section changed since the last submitted review (see SectionChanged)
+func AwaitingInChangedSections(doc *DocumentWithComments, awaiting []AwaitingOwner) []AwaitingOwner {
+	changed := doc.changedSections()
+	var kept []AwaitingOwner
+	for _, a := range awaiting {
+		if changed(a.Suggestion.SectionPath) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
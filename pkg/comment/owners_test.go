@@ -0,0 +1,125 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchSectionPattern(t *testing.T) {
+	tests := []struct {
+		pattern, section string
+		match            bool
+	}{
+		{"API", "API", true},
+		{"API", "API > Auth > Tokens", true}, // Subsections are covered
+		{"API > *", "API > Auth", true},
+		{"API > *", "API", false},
+		{"Guide > Set*", "Guide > Setup", true},
+		{"Guide>Setup", "Guide > Setup", true},
+		{"Guide > Setup", "Guide > Usage", false},
+		{"api", "API", false},
+		{"API", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchSectionPattern(tt.pattern, tt.section); got != tt.match {
+			t.Errorf("MatchSectionPattern(%q, %q) = %v, want %v", tt.pattern, tt.section, got, tt.match)
+		}
+	}
+
+	if err := ValidateSectionPattern("API > [a-"); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+}
+
+func TestAwaitingOwnerReview(t *testing.T) {
+	doc := &DocumentWithComments{}
+	inAPI := NewSuggestion("claude", 10, 10, "Fix", "a", "b")
+	inAPI.SectionPath = "API > Auth"
+	byOwner := NewSuggestion("alice", 12, 12, "Fix", "a", "b")
+	byOwner.SectionPath = "API"
+	repliedTo := NewSuggestion("claude", 4, 4, "Fix", "a", "b")
+	repliedTo.SectionPath = "API"
+	repliedTo.Replies = []*Comment{NewReply("Alice", "Looks right", repliedTo)}
+	unowned := NewSuggestion("claude", 30, 30, "Fix", "a", "b")
+	unowned.SectionPath = "Guide"
+	doc.Threads = []*Comment{inAPI, byOwner, repliedTo, unowned}
+
+	ownersOf := func(section string) []string {
+		if MatchSectionPattern("API", section) {
+			return []string{"alice", "bob"}
+		}
+		return nil
+	}
+
+	// Writing a suggestion isn't reviewing it, so alice's waits on bob
+	awaiting := AwaitingOwnerReview(doc, ownersOf)
+	if len(awaiting) != 2 || awaiting[0].Suggestion != inAPI || len(awaiting[0].Owners) != 2 {
+		t.Fatalf("Awaiting = %+v, want the agent's unreviewed API suggestion first", awaiting)
+	}
+	if awaiting[1].Suggestion != byOwner || len(awaiting[1].Owners) != 1 || awaiting[1].Owners[0] != "bob" {
+		t.Fatalf("Awaiting = %+v, want alice's own suggestion waiting on bob", awaiting[1])
+	}
+
+	stats := NewStats()
+	stats.AddAwaitingOwners(awaiting)
+	if stats.AwaitingOwners["alice"] != 1 || stats.AwaitingOwners["bob"] != 2 {
+		t.Errorf("Owner load = %v, want one for alice and two for bob", stats.AwaitingOwners)
+	}
+
+	// A review bob submitted after the suggestion counts
+	review := NewReview("bob")
+	if err := review.Submit(VerdictRequestChanges, "", inAPI.Timestamp.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	doc.Reviews = []*Review{review}
+	if awaiting := AwaitingOwnerReview(doc, ownersOf); len(awaiting) != 0 {
+		t.Errorf("Expected nothing awaiting after bob's review, got %d", len(awaiting))
+	}
+}
+
+func TestReviewedByIgnoresAuthor(t *testing.T) {
+	s := NewSuggestion("alice", 3, 3, "Fix", "a", "b")
+	s.Replies = []*Comment{NewReply("alice", "Still think so", s)}
+	review := NewReview("alice")
+	if err := review.Submit(VerdictApprove, "", s.Timestamp.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	doc := &DocumentWithComments{Threads: []*Comment{s}, Reviews: []*Review{review}}
+
+	if doc.ReviewedBy(s, []string{"alice"}) {
+		t.Error("The author's own reply and review shouldn't count as reviewing their suggestion")
+	}
+	if awaiting := AwaitingOwnerReview(doc, func(string) []string { return []string{"alice"} }); len(awaiting) != 1 || awaiting[0].Owners[0] != "alice" {
+		t.Errorf("Awaiting = %+v, want the sole owner's suggestion still waiting on them", awaiting)
+	}
+}
+
+func TestSectionChanged(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# API\n\nAuth uses tokens.\n\n# Guide\n\nRun it once."}
+	if !doc.SectionChanged("API") {
+		t.Error("Every section should count as changed before any review")
+	}
+
+	review := NewReview("bob")
+	if err := doc.SubmitReview(review, VerdictApprove, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	doc.Reviews = []*Review{review}
+	if doc.SectionChanged("API") || doc.SectionChanged("Guide") {
+		t.Error("No section should count as changed right after a review")
+	}
+
+	doc.Content = "# API\n\nAuth uses keys.\n\n# Guide\n\nRun it once."
+	if !doc.SectionChanged("API") || doc.SectionChanged("Guide") {
+		t.Error("Only the edited section should count as changed")
+	}
+
+	inAPI := NewSuggestion("claude", 3, 3, "Fix", "Auth uses keys.", "Auth uses API keys.")
+	inAPI.SectionPath = "API"
+	inGuide := NewSuggestion("claude", 7, 7, "Fix", "Run it once.", "Run it.")
+	inGuide.SectionPath = "Guide"
+	awaiting := []AwaitingOwner{{Suggestion: inAPI}, {Suggestion: inGuide}}
+	if kept := AwaitingInChangedSections(doc, awaiting); len(kept) != 1 || kept[0].Suggestion != inAPI {
+		t.Errorf("Kept %+v, want only the suggestion in the changed section", kept)
+	}
+}
@@ -0,0 +1,28 @@
+package comment
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PinThread pins or unpins a thread; replies can't be pinned on their own
+func (d *DocumentWithComments) PinThread(id string, pinned bool) error {
+	thread := d.FindThreadByID(id)
+	if thread == nil {
+		if d.FindCommentByID(id) != nil {
+			return fmt.Errorf("%s is a reply; pin its thread instead", id)
+		}
+		return fmt.Errorf("thread not found: %s", id)
+	}
+	thread.Pinned = pinned
+	log().Debug("set thread pin", "thread", id, "pinned", pinned)
+	return nil
+}
+
+// PinnedFirst moves pinned threads ahead of the others, keeping the order
+// within each group
+func PinnedFirst(threads []*Comment) {
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Pinned && !threads[j].Pinned
+	})
+}
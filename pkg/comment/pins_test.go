@@ -0,0 +1,39 @@
+package comment
+
+import "testing"
+
+func TestPinThread(t *testing.T) {
+	first := NewComment("alice", 5, "First")
+	second := NewComment("bob", 10, "Second")
+	third := NewComment("carol", 20, "Third")
+	reply := NewReply("alice", "Reply", second)
+	second.Replies = []*Comment{reply}
+	doc := &DocumentWithComments{Threads: []*Comment{first, second, third}}
+
+	if err := doc.PinThread(third.ID, true); err != nil {
+		t.Fatalf("PinThread failed: %v", err)
+	}
+	if err := doc.PinThread(second.ID, true); err != nil {
+		t.Fatalf("PinThread failed: %v", err)
+	}
+	if err := doc.PinThread(reply.ID, true); err == nil {
+		t.Error("Expected pinning a reply to fail")
+	}
+	if err := doc.PinThread("missing", true); err == nil {
+		t.Error("Expected pinning a missing thread to fail")
+	}
+
+	threads := []*Comment{first, second, third}
+	PinnedFirst(threads)
+	if threads[0] != second || threads[1] != third || threads[2] != first {
+		t.Errorf("Expected pinned threads first in their order, got %s, %s, %s", threads[0].Text, threads[1].Text, threads[2].Text)
+	}
+
+	if err := doc.PinThread(second.ID, false); err != nil {
+		t.Fatalf("Unpinning failed: %v", err)
+	}
+	PinnedFirst(threads)
+	if threads[0] != third {
+		t.Errorf("Expected only the pinned thread first, got %s", threads[0].Text)
+	}
+}
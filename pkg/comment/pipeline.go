@@ -0,0 +1,115 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// PipelineResult is the outcome of processing one path in a pipeline
+type PipelineResult[T any] struct {
+	Path  string
+	Value T
+	Err   error
+}
+
+// RunPipeline applies fn to every path with a bounded pool of workers
+// Results are delivered in completion order; the channel is closed once every
+// path has been processed. workers <= 0 uses one worker per CPU.
+func RunPipeline[T any](paths []string, workers int, fn func(path string) (T, error)) <-chan PipelineResult[T] {
+	return RunPipelineContext(context.Background(), paths, workers, fn)
+}
+
+// RunPipelineContext is RunPipeline that stops early when ctx is cancelled
+// No new paths are started after cancellation (paths already running
+// finish), so callers should check ctx.Err() to tell an interrupted run from
+// a complete one.
+func RunPipelineContext[T any](ctx context.Context, paths []string, workers int, fn func(path string) (T, error)) <-chan PipelineResult[T] {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(paths) && len(paths) > 0 {
+		workers = len(paths)
+	}
+
+	jobs := make(chan string)
+	results := make(chan PipelineResult[T])
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				value, err := fn(path)
+				results <- PipelineResult[T]{Path: path, Value: value, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			select {
+			case jobs <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// FileError is a failure attributed to a single file
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors aggregates per-file failures from a directory-wide operation
+// so one unreadable sidecar doesn't abort the whole run.
+type FileErrors []FileError
+
+// Add records a failure for path; nil errors are ignored
+func (e *FileErrors) Add(path string, err error) {
+	if err != nil {
+		*e = append(*e, FileError{Path: path, Err: err})
+	}
+}
+
+// Err returns nil when nothing failed, otherwise the aggregated error
+func (e FileErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e FileErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d file(s) failed:", len(e))
+	for _, fe := range e {
+		b.WriteString("\n  ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunPipelineProcessesEveryPath(t *testing.T) {
+	paths := make([]string, 50)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("doc%d.md", i)
+	}
+
+	seen := map[string]bool{}
+	var errs FileErrors
+	for r := range RunPipeline(paths, 4, func(path string) (int, error) {
+		if path == "doc7.md" {
+			return 0, errors.New("boom")
+		}
+		return len(path), nil
+	}) {
+		seen[r.Path] = true
+		errs.Add(r.Path, r.Err)
+	}
+
+	if len(seen) != len(paths) {
+		t.Errorf("Expected %d results, got %d", len(paths), len(seen))
+	}
+	if len(errs) != 1 || errs[0].Path != "doc7.md" {
+		t.Fatalf("Expected one error for doc7.md, got %v", errs)
+	}
+
+	err := errs.Err()
+	if err == nil || !strings.Contains(err.Error(), "1 file(s) failed") {
+		t.Errorf("Unexpected aggregated error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "doc7.md: boom") {
+		t.Errorf("Aggregated error should name the file: %v", err)
+	}
+}
+
+func TestRunPipelineEmpty(t *testing.T) {
+	count := 0
+	for range RunPipeline(nil, 0, func(path string) (bool, error) { return true, nil }) {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("Expected no results, got %d", count)
+	}
+
+	var errs FileErrors
+	if errs.Err() != nil {
+		t.Error("Empty FileErrors should report nil")
+	}
+}
+
+func TestRunPipelineContextStopsWhenCancelled(t *testing.T) {
+	paths := make([]string, 100)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("doc%d.md", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	processed := 0
+	for range RunPipelineContext(ctx, paths, 1, func(path string) (bool, error) {
+		if path == "doc9.md" {
+			cancel()
+		}
+		return true, nil
+	}) {
+		processed++
+	}
+
+	if processed >= len(paths) || processed < 10 {
+		t.Errorf("Expected the run to stop soon after the 10th path, processed %d", processed)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the context to report cancellation")
+	}
+}
+
+func TestComputeStatsAndMerge(t *testing.T) {
+	accepted := true
+	doc := &DocumentWithComments{
+		Threads: []*Comment{
+			{ID: "c1", Author: "alice", Type: "Q", Replies: []*Comment{{ID: "r1", Author: "bob"}}},
+			{ID: "c2", Author: "claude", Resolved: true, IsSuggestion: true, Accepted: &accepted},
+			{ID: "c3", Author: "claude", Status: "orphaned", IsSuggestion: true},
+		},
+	}
+
+	s := ComputeStats(doc)
+	if s.Threads != 3 || s.Replies != 1 || s.Open != 2 || s.Resolved != 1 {
+		t.Errorf("Unexpected counts: %+v", s)
+	}
+	if s.Pending != 1 || s.Accepted != 1 || s.Rejected != 0 {
+		t.Errorf("Unexpected suggestion counts: %+v", s)
+	}
+	if s.Suggestions != (SuggestionOutcomes{Pending: 1, Accepted: 1}) || s.Suggestions.AcceptanceRate() != 1 {
+		t.Errorf("Unexpected suggestion summary: %+v", s.Suggestions)
+	}
+	if s.ByAuthor["claude"] != 2 || s.ByAuthor["bob"] != 1 {
+		t.Errorf("Unexpected author counts: %v", s.ByAuthor)
+	}
+	if s.ByType["Q"] != 1 || s.ByType["none"] != 2 {
+		t.Errorf("Unexpected type counts: %v", s.ByType)
+	}
+
+	total := NewStats()
+	total.Merge(s)
+	total.Merge(s)
+	if total.Files != 2 || total.Threads != 6 || total.ByStatus["orphaned"] != 2 || total.Suggestions.Pending != 2 {
+		t.Errorf("Unexpected merged stats: %+v", total)
+	}
+}
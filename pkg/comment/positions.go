@@ -4,6 +4,8 @@ package comment
 // In v2.0, we only track line numbers (no column/byte offset complexity)
 
 // RecalculateCommentLines updates comment line numbers after a document edit
+// Pending suggestions also have their line ranges shifted so they can still be
+// applied after the edit.
 // editStartLine: first line affected by edit (1-indexed)
 // editEndLine: last line affected by edit (inclusive, 1-indexed)
 // linesAdded: number of lines added by the edit (can be negative for deletions)
@@ -12,6 +14,8 @@ func RecalculateCommentLines(comments []*Comment, editStartLine, editEndLine, li
 	delta := linesAdded - linesDeleted
 
 	for _, comment := range comments {
+		isRange := comment.IsRange()
+
 		if comment.Line > editEndLine {
 			// Comment is after the edit - shift by delta
 			comment.Line += delta
@@ -21,6 +25,13 @@ func RecalculateCommentLines(comments []*Comment, editStartLine, editEndLine, li
 		}
 		// Comments before the edit remain unchanged
 
+		if isRange {
+			recalculateRangeEnd(comment, editStartLine, editEndLine, delta)
+		}
+		if comment.IsPending() {
+			recalculateSuggestionRange(comment, editStartLine, editEndLine, delta)
+		}
+
 		// Recursively update replies
 		if len(comment.Replies) > 0 {
 			RecalculateCommentLines(comment.Replies, editStartLine, editEndLine, linesAdded)
@@ -28,6 +39,87 @@ func RecalculateCommentLines(comments []*Comment, editStartLine, editEndLine, li
 	}
 }
 
+// RecordEdit updates a document's comments after lines editStartLine to
+// editEndLine of Content were replaced with linesAdded lines: positions
+// shift as in RecalculateCommentLines, and sections are recomputed, since the
+// edit may have added, removed, or renamed headings
+func (d *DocumentWithComments) RecordEdit(editStartLine, editEndLine, linesAdded int) {
+	RecalculateCommentLines(d.Threads, editStartLine, editEndLine, linesAdded)
+	RecomputeAllSections(d)
+}
+
+// RecordApplied updates a document's comments after suggestion s was applied
+// to Content, as RecordMove for a move and RecordEdit otherwise. The next
+// save records the change as a revision.
+func (d *DocumentWithComments) RecordApplied(s *Comment) {
+	d.applied = append(d.applied, s.ID)
+	if s.IsMove {
+		d.RecordMove(s.StartLine, s.EndLine, s.MoveAfter)
+		return
+	}
+	d.RecordEdit(s.StartLine, s.EndLine, ProposedLineCount(s))
+}
+
+// recalculateRangeEnd moves the end of a range comment after an edit
+// Ends inside the edit are clamped to the replacement; a range that collapses
+// onto its start line becomes a single-line comment.
+func recalculateRangeEnd(c *Comment, editStartLine, editEndLine, delta int) {
+	if c.EndLine > editEndLine {
+		c.EndLine += delta
+	} else if c.EndLine >= editStartLine {
+		c.EndLine = max(editEndLine+delta, editStartLine)
+	}
+	if c.EndLine <= c.Line {
+		c.EndLine = 0
+	}
+}
+
+// recalculateSuggestionRange shifts a pending suggestion's line range after an edit
+// Ranges after the edit move by delta; ends inside the edit are clamped to the
+// replacement so the range never points past the edited text.
+func recalculateSuggestionRange(s *Comment, editStartLine, editEndLine, delta int) {
+	if s.IsMove {
+		s.MoveAfter = shiftInsertionPoint(s.MoveAfter, editStartLine, editEndLine, delta)
+	}
+	if s.IsInsertion() {
+		after := shiftInsertionPoint(s.EndLine, editStartLine, editEndLine, delta)
+		s.StartLine, s.EndLine = after+1, after
+		return
+	}
+	if s.EndLine < editStartLine {
+		return
+	}
+	if s.StartLine > editEndLine {
+		s.StartLine += delta
+		s.EndLine += delta
+		return
+	}
+
+	// The range overlaps the edit; a start before the edit is unaffected
+	newEditEnd := editEndLine + delta
+	if s.StartLine > newEditEnd {
+		s.StartLine = max(newEditEnd, editStartLine)
+	}
+	if s.EndLine > editEndLine {
+		s.EndLine += delta
+	} else if s.EndLine > newEditEnd {
+		s.EndLine = max(newEditEnd, s.StartLine)
+	}
+}
+
+// shiftInsertionPoint returns where a point after line after (an insertion,
+// or the target of a move) is after an edit. A point inside the edit is
+// clamped to the replacement, like the end of a range.
+func shiftInsertionPoint(after, editStartLine, editEndLine, delta int) int {
+	if after >= editEndLine {
+		return after + delta
+	}
+	if after >= editStartLine {
+		return max(min(after, editEndLine+delta), editStartLine-1)
+	}
+	return after
+}
+
 // SortSuggestionsByLine sorts suggestions by line number in descending order (bottom to top)
 // This order is optimal for applying multiple suggestions without position drift
 func SortSuggestionsByLine(suggestions []*Comment) {
@@ -80,6 +172,7 @@ type Conflict struct {
 }
 
 // DetectConflicts identifies conflicts between pending suggestions
+// Suggestions stacked on each other with DependsOn are not conflicts.
 // Returns a list of conflicts that need user attention
 func DetectConflicts(suggestions []*Comment) []Conflict {
 	conflicts := []Conflict{}
@@ -96,6 +189,9 @@ func DetectConflicts(suggestions []*Comment) []Conflict {
 			if !s2.IsSuggestion || !s2.IsPending() {
 				continue
 			}
+			if dependsOn(s1, s2.ID) || dependsOn(s2, s1.ID) {
+				continue
+			}
 
 			conflict := detectConflictBetween(s1, s2)
 			if conflict.Type != ConflictNone {
@@ -107,6 +203,16 @@ func DetectConflicts(suggestions []*Comment) []Conflict {
 	return conflicts
 }
 
+// dependsOn reports whether s directly depends on the suggestion with id
+func dependsOn(s *Comment, id string) bool {
+	for _, dep := range s.DependsOn {
+		if dep == id {
+			return true
+		}
+	}
+	return false
+}
+
 // detectConflictBetween checks if two suggestions conflict
 func detectConflictBetween(s1, s2 *Comment) Conflict {
 	s1Start := s1.StartLine
@@ -115,6 +221,29 @@ func detectConflictBetween(s1, s2 *Comment) Conflict {
 	s2Start := s2.StartLine
 	s2End := s2.EndLine
 
+	// Two insertions at the same point give different text depending on
+	// which is applied first
+	if s1.IsInsertion() && s2.IsInsertion() && s1End == s2End {
+		return Conflict{
+			Type:        ConflictOverlap,
+			Suggestion1: s1,
+			Suggestion2: s2,
+			Description: "Both insert at the same point",
+		}
+	}
+
+	// Lines moved into the middle of another suggestion's lines, or to the
+	// same point as other new lines, land in an order that depends on which
+	// is applied first
+	if movesInto(s1, s2) || movesInto(s2, s1) {
+		return Conflict{
+			Type:        ConflictOverlap,
+			Suggestion1: s1,
+			Suggestion2: s2,
+			Description: "Lines are moved into text the other suggestion changes",
+		}
+	}
+
 	// Check for overlap
 	if s1Start <= s2End && s1End >= s2Start {
 		// Check if one is nested within the other
@@ -148,6 +277,21 @@ func detectConflictBetween(s1, s2 *Comment) Conflict {
 	return Conflict{Type: ConflictNone}
 }
 
+// movesInto reports whether m moves lines into the lines s changes, or to
+// where s inserts or moves lines
+func movesInto(m, s *Comment) bool {
+	if !m.IsMove {
+		return false
+	}
+	if s.IsInsertion() {
+		return m.MoveAfter == s.EndLine
+	}
+	if s.IsMove && m.MoveAfter == s.MoveAfter {
+		return true
+	}
+	return m.MoveAfter >= s.StartLine && m.MoveAfter < s.EndLine
+}
+
 // HasConflicts returns true if any serious conflicts exist in the list
 func HasConflicts(conflicts []Conflict) bool {
 	for _, c := range conflicts {
@@ -159,29 +303,45 @@ func HasConflicts(conflicts []Conflict) bool {
 }
 
 // FilterNonConflicting removes suggestions that conflict with each other
-// Keeps the first suggestion in each conflicting pair
+// Suggestions are kept greedily in order: each one is kept unless it overlaps
+// a suggestion already kept, so dropping one can't knock out a third.
 func FilterNonConflicting(suggestions []*Comment) []*Comment {
 	conflicts := DetectConflicts(suggestions)
 	if len(conflicts) == 0 {
 		return suggestions
 	}
 
-	// Build set of suggestions to exclude (later suggestions in conflicts)
-	exclude := make(map[string]bool)
+	// Index serious conflicts by suggestion ID
+	conflictsWith := make(map[string]map[string]bool)
 	for _, conflict := range conflicts {
-		if conflict.Type == ConflictOverlap || conflict.Type == ConflictNested {
-			// Keep first, exclude second
-			if conflict.Suggestion2 != nil {
-				exclude[conflict.Suggestion2.ID] = true
-			}
+		if conflict.Type != ConflictOverlap && conflict.Type != ConflictNested {
+			continue
+		}
+		id1, id2 := conflict.Suggestion1.ID, conflict.Suggestion2.ID
+		if conflictsWith[id1] == nil {
+			conflictsWith[id1] = make(map[string]bool)
 		}
+		if conflictsWith[id2] == nil {
+			conflictsWith[id2] = make(map[string]bool)
+		}
+		conflictsWith[id1][id2] = true
+		conflictsWith[id2][id1] = true
 	}
 
 	// Filter suggestions
 	filtered := []*Comment{}
+	kept := make(map[string]bool)
 	for _, s := range suggestions {
-		if !exclude[s.ID] {
+		blocked := false
+		for other := range conflictsWith[s.ID] {
+			if kept[other] {
+				blocked = true
+				break
+			}
+		}
+		if !blocked {
 			filtered = append(filtered, s)
+			kept[s.ID] = true
 		}
 	}
 
@@ -111,6 +111,76 @@ func TestRecalculateCommentLinesNestedReplies(t *testing.T) {
 	}
 }
 
+func TestRecalculateCommentLinesRanges(t *testing.T) {
+	before := &Comment{ID: "before", Line: 2, EndLine: 4, Replies: []*Comment{}}
+	spanning := &Comment{ID: "spanning", Line: 5, EndLine: 20, Replies: []*Comment{}}
+	after := &Comment{ID: "after", Line: 15, EndLine: 18, Replies: []*Comment{}}
+	collapsed := &Comment{ID: "collapsed", Line: 10, EndLine: 12, Replies: []*Comment{}}
+	comments := []*Comment{before, spanning, after, collapsed}
+
+	// Replace lines 10-12 with a single line - net -2 lines
+	RecalculateCommentLines(comments, 10, 12, 1)
+
+	if before.Line != 2 || before.EndLine != 4 {
+		t.Errorf("before = %d-%d, want 2-4", before.Line, before.EndLine)
+	}
+	// The edit is inside the range; only the end moves
+	if spanning.Line != 5 || spanning.EndLine != 18 {
+		t.Errorf("spanning = %d-%d, want 5-18", spanning.Line, spanning.EndLine)
+	}
+	if after.Line != 13 || after.EndLine != 16 {
+		t.Errorf("after = %d-%d, want 13-16", after.Line, after.EndLine)
+	}
+	// A range replaced by one line becomes a plain line comment
+	if collapsed.Line != 10 || collapsed.EndLine != 0 || collapsed.IsRange() {
+		t.Errorf("collapsed = %d-%d, want line 10 with no range", collapsed.Line, collapsed.EndLine)
+	}
+}
+
+func TestRecalculateCommentLinesInsertions(t *testing.T) {
+	before := NewInsertion("alice", 3, "Before", "x")
+	after := NewInsertion("alice", 12, "After", "x")
+	atEnd := NewInsertion("alice", 10, "End of edit", "x")
+
+	// Replace lines 8-10 with a single line - net -2 lines
+	RecalculateCommentLines([]*Comment{before, after, atEnd}, 8, 10, 1)
+
+	for _, tt := range []struct {
+		s    *Comment
+		want int
+	}{
+		{before, 3},
+		{after, 10},
+		{atEnd, 8},
+	} {
+		if !tt.s.IsInsertion() || tt.s.EndLine != tt.want {
+			t.Errorf("%s: insert after %d (start %d), want after %d", tt.s.Text, tt.s.EndLine, tt.s.StartLine, tt.want)
+		}
+	}
+
+	// An insertion point inside an edit is clamped to the replacement
+	mid := NewInsertion("alice", 9, "Mid", "x")
+	RecalculateCommentLines([]*Comment{mid}, 8, 10, 1)
+	if !mid.IsInsertion() || mid.EndLine != 8 {
+		t.Errorf("mid: insert after %d, want after 8", mid.EndLine)
+	}
+}
+
+func TestDetectConflictsSameInsertionPoint(t *testing.T) {
+	s1 := NewInsertion("alice", 4, "One", "a")
+	s2 := NewInsertion("bob", 4, "Two", "b")
+	conflicts := DetectConflicts([]*Comment{s1, s2})
+	if len(conflicts) != 1 || conflicts[0].Type != ConflictOverlap {
+		t.Fatalf("Expected an overlap for insertions at the same point, got %+v", conflicts)
+	}
+
+	// An insertion inside a replaced range conflicts with it
+	replace := NewSuggestion("carol", 3, 6, "Rewrite", "", "c")
+	if !HasConflicts(DetectConflicts([]*Comment{s1, replace})) {
+		t.Error("Expected a conflict for an insertion inside a replaced range")
+	}
+}
+
 func TestSortSuggestionsByLine(t *testing.T) {
 	suggestions := []*Comment{
 		{ID: "s1", StartLine: 10, EndLine: 10},
@@ -345,6 +415,31 @@ func TestDetectConflictsSkipsNonPending(t *testing.T) {
 	}
 }
 
+func TestDetectConflictsSkipsDependentSuggestions(t *testing.T) {
+	suggestions := []*Comment{
+		{
+			ID:           "s1",
+			IsSuggestion: true,
+			StartLine:    10,
+			EndLine:      14,
+		},
+		{
+			ID:           "s2",
+			IsSuggestion: true,
+			StartLine:    11,
+			EndLine:      11,
+			DependsOn:    []string{"s1"},
+		},
+	}
+
+	conflicts := DetectConflicts(suggestions)
+
+	// Stacked suggestions overlap by design
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts (s2 depends on s1), got %d", len(conflicts))
+	}
+}
+
 func TestHasConflicts(t *testing.T) {
 	conflicts := []Conflict{
 		{Type: ConflictAdjacent}, // Not a serious conflict
@@ -419,6 +514,25 @@ func TestFilterNonConflicting(t *testing.T) {
 	}
 }
 
+func TestFilterNonConflictingChain(t *testing.T) {
+	// s2 overlaps both neighbours; once s2 is dropped, s3 no longer conflicts
+	suggestions := []*Comment{
+		{ID: "s1", IsSuggestion: true, StartLine: 7, EndLine: 7},
+		{ID: "s2", IsSuggestion: true, StartLine: 7, EndLine: 8},
+		{ID: "s3", IsSuggestion: true, StartLine: 8, EndLine: 8},
+	}
+
+	filtered := FilterNonConflicting(suggestions)
+
+	if len(filtered) != 2 || filtered[0].ID != "s1" || filtered[1].ID != "s3" {
+		ids := []string{}
+		for _, s := range filtered {
+			ids = append(ids, s.ID)
+		}
+		t.Errorf("Expected [s1 s3], got %v", ids)
+	}
+}
+
 func TestFilterNonConflictingNoConflicts(t *testing.T) {
 	suggestions := []*Comment{
 		{
@@ -444,3 +558,33 @@ func TestFilterNonConflictingNoConflicts(t *testing.T) {
 		t.Errorf("Expected 2 suggestions (no conflicts), got %d", len(filtered))
 	}
 }
+
+func TestRecordEditRecomputesSections(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# Intro\n\nText\n\n# Usage\n\nRun it."}
+	c := NewComment("alice", 7, "How?")
+	c.Replies = append(c.Replies, NewReply("bob", "Like this", c))
+	orphan := NewComment("alice", 3, "Gone")
+	doc.Threads = []*Comment{c, orphan}
+	ComputeSectionsForComments(doc)
+	orphan.Status = "orphaned"
+	orphan.SectionPath = "Removed"
+
+	// Rename the heading and split it into two lines
+	s := NewSuggestion("bob", 5, 5, "Rename", "# Usage", "# How to use\n")
+	newContent, err := ApplySuggestion(doc.Content, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Content = newContent
+	doc.RecordEdit(5, 5, ProposedLineCount(s))
+
+	if c.Line != 8 || c.SectionPath != "How to use" {
+		t.Errorf("Comment at line %d in %q, want line 8 in How to use", c.Line, c.SectionPath)
+	}
+	if c.Replies[0].SectionPath != "How to use" {
+		t.Errorf("Reply section = %q, want How to use", c.Replies[0].SectionPath)
+	}
+	if orphan.SectionPath != "Removed" {
+		t.Errorf("Orphaned comment section = %q, want it kept", orphan.SectionPath)
+	}
+}
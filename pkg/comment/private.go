@@ -0,0 +1,235 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Private notes are personal reminders a reviewer keeps on a document
+// without adding them to the team's record. Each author's notes live in
+// their own overlay in the user's config directory, away from the sidecar
+// and the repository it is committed to; views merge the overlay of the
+// person viewing into the document, and saving such a document writes the
+// notes back to their overlay rather than the shared sidecar.
+
+// Comment visibilities
+const (
+	VisibilityShared  = "shared"  // In the sidecar, seen by everyone (the default)
+	VisibilityPrivate = "private" // In its author's overlay, seen only by them
+)
+
+// ValidateVisibility checks a visibility name
+func ValidateVisibility(name string) error {
+	switch name {
+	case "", VisibilityShared, VisibilityPrivate:
+		return nil
+	}
+	return fmt.Errorf("unknown visibility %q (want %s or %s)", name, VisibilityShared, VisibilityPrivate)
+}
+
+// IsPrivate returns true if the comment is a private note
+func (c *Comment) IsPrivate() bool {
+	return c.Visibility == VisibilityPrivate
+}
+
+// SharedThreads returns the threads that belong in the shared sidecar
+func SharedThreads(threads []*Comment) []*Comment {
+	shared := make([]*Comment, 0, len(threads))
+	for _, t := range threads {
+		if !t.IsPrivate() {
+			shared = append(shared, t)
+		}
+	}
+	return shared
+}
+
+// GetPrivatePath returns the private-notes overlay of author for a markdown
+// file, under the user's config directory (see EnvUserDir)
+func GetPrivatePath(mdPath, author string) (string, error) {
+	return userStatePath("private", mdPath, "."+privateName(author)+".json")
+}
+
+// legacyPrivatePath returns where overlays used to be kept, next to the
+// sidecar; notes found there are moved on the next save
+func legacyPrivatePath(mdPath, author string) string {
+	return strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".private." + privateName(author) + ".json"
+}
+
+// privateName returns author as used in overlay file names. Characters that
+// don't belong in a file name are replaced with "_".
+func privateName(author string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.ToLower(author))
+}
+
+// LoadPrivateNotes reads author's private notes on a markdown file,
+// returning an empty set if there are none
+func LoadPrivateNotes(mdPath, author string) (*StorageFormat, error) {
+	notes := &StorageFormat{
+		Version: StorageVersion,
+		Threads: []*Comment{},
+	}
+
+	path, err := GetPrivatePath(mdPath, author)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		path = legacyPrivatePath(mdPath, author)
+		data, err = os.ReadFile(path)
+	}
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private notes: %w", err)
+	}
+
+	if err := json.Unmarshal(data, notes); err != nil {
+		return nil, fmt.Errorf("failed to parse private notes %s: %w", path, err)
+	}
+	for _, t := range notes.Threads {
+		t.Visibility = VisibilityPrivate
+	}
+	return notes, nil
+}
+
+// SavePrivateNotes writes author's private notes on a markdown file
+// The file is removed once it no longer holds any notes, and an overlay
+// left next to the sidecar by earlier versions is removed either way.
+func SavePrivateNotes(mdPath, author string, notes *StorageFormat) error {
+	path, err := GetPrivatePath(mdPath, author)
+	if err != nil {
+		return err
+	}
+	if len(notes.Threads) == 0 {
+		for _, p := range []string{path, legacyPrivatePath(mdPath, author)} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove private notes: %w", err)
+			}
+		}
+		log().Debug("removed empty private notes", "file", mdPath, "notes", path)
+		return nil
+	}
+
+	notes.Version = StorageVersion
+	notes.Threads = CanonicalOrder(notes.Threads)
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal private notes: %w", err)
+	}
+	if err := writeUserState(path, data); err != nil {
+		return fmt.Errorf("failed to write private notes: %w", err)
+	}
+	if err := os.Remove(legacyPrivatePath(mdPath, author)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove private notes: %w", err)
+	}
+	log().Info("saved private notes", "file", mdPath, "notes", path, "threads", len(notes.Threads))
+	return nil
+}
+
+// AddPrivateNote saves c as a private note of its author on mdPath, written
+// against the document content
+func AddPrivateNote(mdPath string, c *Comment, content string) error {
+	notes, err := LoadPrivateNotes(mdPath, c.Author)
+	if err != nil {
+		return err
+	}
+
+	c.Visibility = VisibilityPrivate
+	notes.Threads = append(notes.Threads, c)
+	notes.DocumentHash = ComputeDocumentHash(content)
+	notes.LastValidated = now()
+
+	return SavePrivateNotes(mdPath, c.Author, notes)
+}
+
+// MergePrivateNotes adds author's private notes on mdPath to the document's
+// threads, for views shown to author. Their section metadata is refreshed
+// against the current content. Saving the document afterwards writes them
+// back to author's overlay, never to the shared sidecar.
+func (d *DocumentWithComments) MergePrivateNotes(mdPath, author string) error {
+	if author == "" || slices.ContainsFunc(d.privateOwners, func(o string) bool { return strings.EqualFold(o, author) }) {
+		return nil
+	}
+	notes, err := LoadPrivateNotes(mdPath, author)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range notes.Threads {
+		UpdateCommentSection(t, d)
+		d.Threads = append(d.Threads, t)
+	}
+	d.privateOwners = append(d.privateOwners, author)
+	log().Debug("merged private notes", "file", mdPath, "author", author, "threads", len(notes.Threads))
+	return nil
+}
+
+// savePrivateNotes writes the private notes in doc's threads back to their
+// authors' overlays. The overlays of authors whose notes were merged are
+// replaced, so deleted notes go; other authors' notes (added since loading)
+// are added to what their overlays already hold.
+func savePrivateNotes(mdPath string, doc *DocumentWithComments) error {
+	merged := func(author string) int {
+		return slices.IndexFunc(doc.privateOwners, func(o string) bool { return strings.EqualFold(o, author) })
+	}
+
+	byOwner := map[string][]*Comment{}
+	owners := slices.Clone(doc.privateOwners)
+	for _, t := range doc.Threads {
+		if !t.IsPrivate() {
+			continue
+		}
+		owner := t.Author
+		if i := merged(owner); i >= 0 {
+			owner = doc.privateOwners[i]
+		} else if _, ok := byOwner[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		byOwner[owner] = append(byOwner[owner], t)
+	}
+
+	for _, owner := range owners {
+		threads := byOwner[owner]
+		if merged(owner) < 0 {
+			existing, err := LoadPrivateNotes(mdPath, owner)
+			if err != nil {
+				return err
+			}
+			threads = mergeByID(existing.Threads, threads)
+		}
+		notes := &StorageFormat{
+			DocumentHash:  ComputeDocumentHash(doc.Content),
+			LastValidated: now(),
+			Threads:       threads,
+		}
+		if err := SavePrivateNotes(mdPath, owner, notes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeByID returns threads with each of updates replacing the thread with
+// its ID, or added if there is none
+func mergeByID(threads, updates []*Comment) []*Comment {
+	merged := slices.Clone(threads)
+	for _, u := range updates {
+		if i := slices.IndexFunc(merged, func(t *Comment) bool { return t.ID == u.ID }); i >= 0 {
+			merged[i] = u
+		} else {
+			merged = append(merged, u)
+		}
+	}
+	return merged
+}
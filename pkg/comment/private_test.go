@@ -0,0 +1,148 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrivateNotesStayOutOfSidecar(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+	content := "# Title\n\nFirst line\nSecond line"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	userDir := t.TempDir()
+	t.Setenv(EnvUserDir, userDir)
+	if got, err := GetPrivatePath(mdPath, "Alice Smith"); err != nil || filepath.Dir(got) != filepath.Join(userDir, "private") || !strings.HasSuffix(got, ".alice_smith.json") {
+		t.Errorf("GetPrivatePath = %s, %v", got, err)
+	}
+
+	note := NewComment("alice", 3, "Remember to check the numbers")
+	if err := AddPrivateNote(mdPath, note, content); err != nil {
+		t.Fatalf("AddPrivateNote failed: %v", err)
+	}
+	if SidecarExists(mdPath) {
+		t.Error("Adding a private note should not create the sidecar")
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatalf("LoadFromSidecar failed: %v", err)
+	}
+	doc.Threads = append(doc.Threads, NewComment("bob", 4, "Shared comment"))
+
+	// Only the note's author sees it
+	if err := doc.MergePrivateNotes(mdPath, "bob"); err != nil {
+		t.Fatalf("MergePrivateNotes failed: %v", err)
+	}
+	if len(doc.Threads) != 1 {
+		t.Fatalf("Bob should not see alice's note, got %d threads", len(doc.Threads))
+	}
+	if err := doc.MergePrivateNotes(mdPath, "alice"); err != nil {
+		t.Fatalf("MergePrivateNotes failed: %v", err)
+	}
+	if len(doc.Threads) != 2 || !doc.Threads[1].IsPrivate() || doc.Threads[1].SectionPath != "Title" {
+		t.Fatalf("Expected alice's note with section metadata, got %+v", doc.Threads)
+	}
+
+	// Replying to the note keeps the reply private; saving splits the threads
+	if err := AddReplyToThread(doc.Threads, note.ID, "alice", "Done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatalf("SaveToSidecar failed: %v", err)
+	}
+	data, err := os.ReadFile(GetSidecarPath(mdPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Remember to check") || strings.Contains(string(data), "Done") {
+		t.Error("The shared sidecar must not contain private notes")
+	}
+	notes, err := LoadPrivateNotes(mdPath, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes.Threads) != 1 || len(notes.Threads[0].Replies) != 1 {
+		t.Errorf("Expected the note and its reply in alice's overlay, got %+v", notes.Threads)
+	}
+
+	// Deleting the last note removes the overlay
+	doc.Threads = SharedThreads(doc.Threads)
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	if path, _ := GetPrivatePath(mdPath, "alice"); fileExists(path) {
+		t.Error("The overlay should be removed once empty")
+	}
+}
+
+func TestSavePrivateNotesKeepsUnmergedNotes(t *testing.T) {
+	t.Setenv(EnvUserDir, t.TempDir())
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+	content := "# Title\n\nLine"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := NewComment("alice", 3, "First note")
+	if err := AddPrivateNote(mdPath, first, content); err != nil {
+		t.Fatal(err)
+	}
+
+	// A note added to a document that never merged alice's overlay
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := NewComment("alice", 3, "Second note")
+	second.Visibility = VisibilityPrivate
+	doc.Threads = append(doc.Threads, second)
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := LoadPrivateNotes(mdPath, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes.Threads) != 2 {
+		t.Errorf("Expected both notes in the overlay, got %d", len(notes.Threads))
+	}
+}
+
+func TestPrivateNotesMoveOutOfSidecarDirectory(t *testing.T) {
+	t.Setenv(EnvUserDir, t.TempDir())
+	tmpDir := t.TempDir()
+	mdPath := filepath.Join(tmpDir, "doc.md")
+	content := "# Title\n\nLine"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An overlay written next to the sidecar by an earlier version
+	legacy := mdPath + ".comments.private.alice.json"
+	if err := os.WriteFile(legacy, []byte(`{"version": "2.0", "threads": [{"id": "c1", "author": "alice", "line": 3, "text": "Old note", "timestamp": "2025-01-15T10:00:00Z"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes, err := LoadPrivateNotes(mdPath, "alice")
+	if err != nil || len(notes.Threads) != 1 {
+		t.Fatalf("Expected the old note, got %+v, %v", notes, err)
+	}
+
+	notes.Threads = append(notes.Threads, NewComment("alice", 3, "New note"))
+	if err := SavePrivateNotes(mdPath, "alice", notes); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(legacy) {
+		t.Error("Expected the overlay next to the sidecar to be removed")
+	}
+	if notes, err := LoadPrivateNotes(mdPath, "alice"); err != nil || len(notes.Threads) != 2 {
+		t.Errorf("Expected both notes in the user's overlay, got %+v, %v", notes, err)
+	}
+}
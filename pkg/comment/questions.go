@@ -0,0 +1,61 @@
+package comment
+
+import "sort"
+
+// A question ([Q] thread) is open until someone who can answer it replies,
+// then answered until it is resolved. A later reply from the asker is a
+// follow-up and opens it again. This tells reviewers which questions still
+// wait on the document's owners and which only need closing.
+
+// Question states
+const (
+	QuestionOpen     = "open"     // Waiting for an answer
+	QuestionAnswered = "answered" // Answered, not yet resolved
+	QuestionResolved = "resolved" // Resolved
+)
+
+// QuestionState returns where a [Q] thread stands, or "" for other threads.
+// isOwner tells who can answer (the document owners); nil lets anyone but
+// the asker answer.
+func (c *Comment) QuestionState(isOwner func(author string) bool) string {
+	if c.Type != "Q" {
+		return ""
+	}
+	if c.Resolved || c.GetStatus() == "resolved" {
+		return QuestionResolved
+	}
+
+	replies := flattenReplies(c.Replies)
+	sort.SliceStable(replies, func(i, j int) bool {
+		return replies[i].Timestamp.Before(replies[j].Timestamp)
+	})
+
+	state := QuestionOpen
+	for _, r := range replies {
+		switch {
+		case r.Author == c.Author:
+			state = QuestionOpen
+		case isOwner == nil || isOwner(r.Author):
+			state = QuestionAnswered
+		}
+	}
+	return state
+}
+
+// ByQuestionState matches [Q] threads in the given state (see QuestionState)
+func ByQuestionState(state string, isOwner func(author string) bool) Filter {
+	return func(c *Comment) bool {
+		return c.QuestionState(isOwner) == state
+	}
+}
+
+// CountQuestions counts the [Q] threads among threads by state
+func CountQuestions(threads []*Comment, isOwner func(author string) bool) map[string]int {
+	counts := map[string]int{}
+	for _, t := range threads {
+		if state := t.QuestionState(isOwner); state != "" {
+			counts[state]++
+		}
+	}
+	return counts
+}
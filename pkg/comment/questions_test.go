@@ -0,0 +1,66 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuestionState(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	isOwner := func(author string) bool { return author == "alice" }
+	reply := func(author string, minutes int) *Comment {
+		return &Comment{ID: author, Author: author, Timestamp: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	question := NewCommentWithType("bob", 3, "Why this way?", "Q")
+	if got := question.QuestionState(isOwner); got != QuestionOpen {
+		t.Errorf("New question: got %q, want open", got)
+	}
+
+	// Replies from others than the owners don't answer it
+	question.Replies = []*Comment{reply("carol", 1)}
+	if got := question.QuestionState(isOwner); got != QuestionOpen {
+		t.Errorf("After carol's reply: got %q, want open", got)
+	}
+	if got := question.QuestionState(nil); got != QuestionAnswered {
+		t.Errorf("Without owners anyone answers: got %q, want answered", got)
+	}
+
+	// An owner's answer, nested under carol's reply
+	question.Replies[0].Replies = []*Comment{reply("alice", 2)}
+	if got := question.QuestionState(isOwner); got != QuestionAnswered {
+		t.Errorf("After alice's answer: got %q, want answered", got)
+	}
+
+	// A follow-up from the asker opens it again
+	question.Replies = append(question.Replies, reply("bob", 3))
+	if got := question.QuestionState(isOwner); got != QuestionOpen {
+		t.Errorf("After bob's follow-up: got %q, want open", got)
+	}
+
+	question.Resolved = true
+	if got := question.QuestionState(isOwner); got != QuestionResolved {
+		t.Errorf("Resolved question: got %q, want resolved", got)
+	}
+
+	if got := NewComment("bob", 3, "Note").QuestionState(isOwner); got != "" {
+		t.Errorf("Other threads have no question state, got %q", got)
+	}
+}
+
+func TestCountQuestions(t *testing.T) {
+	open := NewCommentWithType("bob", 1, "Open", "Q")
+	answered := NewCommentWithType("bob", 2, "Answered", "Q")
+	answered.Replies = []*Comment{{ID: "r1", Author: "alice", Text: "Because"}}
+	resolved := NewCommentWithType("bob", 3, "Resolved", "Q")
+	resolved.Resolved = true
+	threads := []*Comment{open, answered, resolved, NewComment("bob", 4, "Note")}
+
+	counts := CountQuestions(threads, nil)
+	if counts[QuestionOpen] != 1 || counts[QuestionAnswered] != 1 || counts[QuestionResolved] != 1 || len(counts) != 3 {
+		t.Errorf("CountQuestions = %v", counts)
+	}
+	if got := ByQuestionState(QuestionOpen, nil).Apply(threads); len(got) != 1 || got[0] != open {
+		t.Errorf("ByQuestionState(open) = %v", got)
+	}
+}
@@ -0,0 +1,252 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// The queue stages writes (typically an agent's) in a file next to the
+// sidecar instead of saving each one, so they land in the shared sidecar
+// together, in one save, when the queue is flushed. Until then they don't
+// disturb a review in progress or show up as a string of sidecar changes.
+
+// Kinds of queued operation
+const (
+	QueueThread = "thread" // A new comment or suggestion thread
+	QueueReply  = "reply"  // A reply to a thread, which may itself be queued
+)
+
+// QueuedOp is one staged write
+type QueuedOp struct {
+	Kind     string    `json:"kind"`
+	ThreadID string    `json:"threadId,omitempty"` // The thread a reply answers
+	Comment  *Comment  `json:"comment"`            // The new thread or reply
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// Queue is the set of staged writes for a document, oldest first
+type Queue struct {
+	Version      string      `json:"version"`
+	DocumentHash string      `json:"documentHash"` // Content the latest operation was written against
+	Ops          []*QueuedOp `json:"ops"`
+}
+
+// GetQueuePath returns the queue file for a markdown file
+func GetQueuePath(mdPath string) string {
+	return strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".queue.json"
+}
+
+// NewQueuedThread stages c as a new thread
+func NewQueuedThread(c *Comment) *QueuedOp {
+	return &QueuedOp{Kind: QueueThread, Comment: c, QueuedAt: now()}
+}
+
+// NewQueuedReply stages reply as an answer to thread threadID
+func NewQueuedReply(threadID string, reply *Comment) *QueuedOp {
+	return &QueuedOp{Kind: QueueReply, ThreadID: threadID, Comment: reply, QueuedAt: now()}
+}
+
+// LoadQueue reads the queue for a markdown file, returning an empty queue if
+// nothing is staged
+func LoadQueue(mdPath string) (*Queue, error) {
+	queue := &Queue{Version: StorageVersion, Ops: []*QueuedOp{}}
+
+	path := GetQueuePath(mdPath)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return queue, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	if err := json.Unmarshal(data, queue); err != nil {
+		return nil, fmt.Errorf("failed to parse queue %s: %w", path, err)
+	}
+	for i, op := range queue.Ops {
+		if op.Comment == nil || (op.Kind != QueueThread && op.Kind != QueueReply) {
+			return nil, fmt.Errorf("queue %s: operation %d is malformed", path, i+1)
+		}
+	}
+	return queue, nil
+}
+
+// SaveQueue writes the queue for a markdown file
+// The file is removed once nothing is staged.
+func SaveQueue(mdPath string, queue *Queue) error {
+	path := GetQueuePath(mdPath)
+	if len(queue.Ops) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove queue: %w", err)
+		}
+		log().Debug("removed empty queue", "file", mdPath, "queue", path)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write queue: %w", err)
+	}
+	log().Info("saved queue", "file", mdPath, "queue", path, "ops", len(queue.Ops))
+	return nil
+}
+
+// Enqueue stages op for mdPath, written against the document content
+func Enqueue(mdPath string, op *QueuedOp, content string) error {
+	queue, err := LoadQueue(mdPath)
+	if err != nil {
+		return err
+	}
+
+	queue.Ops = append(queue.Ops, op)
+	queue.DocumentHash = ComputeDocumentHash(content)
+	return SaveQueue(mdPath, queue)
+}
+
+// Thread returns the queued thread with the given ID, or nil
+func (q *Queue) Thread(id string) *Comment {
+	for _, op := range q.Ops {
+		if op.Kind == QueueThread && op.Comment.ID == id {
+			return op.Comment
+		}
+	}
+	return nil
+}
+
+// SelectQueued returns the operations written by author (every operation if
+// author is empty)
+func SelectQueued(ops []*QueuedOp, author string) []*QueuedOp {
+	selected := []*QueuedOp{}
+	for _, op := range ops {
+		if author == "" || strings.EqualFold(op.Comment.Author, author) {
+			selected = append(selected, op)
+		}
+	}
+	return selected
+}
+
+// ApplyQueued adds the queued operations to doc, in order, as one change:
+// if any operation can't be applied (a reply to a thread that doesn't
+// exist), doc is left as it was. Returns true if the document has changed
+// since the operations were written, in which case their line numbers may
+// need checking. Neither file is saved; callers save the sidecar and then
+// remove the applied operations with RemoveQueued.
+func ApplyQueued(doc *DocumentWithComments, queue *Queue, ops []*QueuedOp) (bool, error) {
+	threads := make(map[string]bool, len(doc.Threads))
+	for _, t := range doc.Threads {
+		threads[t.ID] = true
+	}
+	for _, op := range ops {
+		if op.Kind == QueueThread {
+			threads[op.Comment.ID] = true
+		} else if !threads[op.ThreadID] {
+			return false, fmt.Errorf("queued reply %s answers thread %s, which doesn't exist", op.Comment.ID, op.ThreadID)
+		}
+	}
+
+	for _, op := range ops {
+		if op.Kind == QueueThread {
+			UpdateCommentSection(op.Comment, doc)
+			doc.Threads = append(doc.Threads, op.Comment)
+			continue
+		}
+		thread := doc.FindThreadByID(op.ThreadID)
+		thread.Replies = append(thread.Replies, op.Comment)
+	}
+	return queue.DocumentHash != "" && queue.DocumentHash != ComputeDocumentHash(doc.Content), nil
+}
+
+// QueuedThreadID returns the thread op writes to: its own comment for a new
+// thread, the thread it answers for a reply
+func QueuedThreadID(op *QueuedOp) string {
+	if op.Kind == QueueThread {
+		return op.Comment.ID
+	}
+	return op.ThreadID
+}
+
+// MergeQueued folds op into into, an earlier operation: op's text is appended
+// to into's, and queued replies (in ops) to op's thread answer into's thread
+// instead. A suggestion can't be merged, since its change would be lost.
+func MergeQueued(into, op *QueuedOp, ops []*QueuedOp) error {
+	if op.Comment.IsSuggestion {
+		return fmt.Errorf("%s is a suggestion; only comments and replies can be merged", op.Comment.ID)
+	}
+	if into == op {
+		return fmt.Errorf("can't merge %s into itself", op.Comment.ID)
+	}
+
+	into.Comment.Text = strings.TrimRight(into.Comment.Text, "\n") + "\n\n" + op.Comment.Text
+	if op.Kind == QueueThread {
+		target := QueuedThreadID(into)
+		for _, other := range ops {
+			if other.Kind == QueueReply && other.ThreadID == op.Comment.ID {
+				other.ThreadID = target
+			}
+		}
+	}
+	return nil
+}
+
+// SaveReviewedQueue writes back the queue for mdPath after a review: the
+// operations with IDs in done (written, dropped, or merged) are removed, and
+// the rest of reviewed replace the queued versions, keeping any edits. The
+// queue is reloaded first so operations queued during the review are kept.
+func SaveReviewedQueue(mdPath string, reviewed []*QueuedOp, done []string) error {
+	queue, err := LoadQueue(mdPath)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool, len(done))
+	for _, id := range done {
+		remove[id] = true
+	}
+	updated := make(map[string]*QueuedOp, len(reviewed))
+	for _, op := range reviewed {
+		updated[op.Comment.ID] = op
+	}
+
+	remaining := []*QueuedOp{}
+	for _, op := range queue.Ops {
+		if remove[op.Comment.ID] {
+			continue
+		}
+		if u, ok := updated[op.Comment.ID]; ok {
+			op = u
+		}
+		remaining = append(remaining, op)
+	}
+	queue.Ops = remaining
+
+	return SaveQueue(mdPath, queue)
+}
+
+// RemoveQueued deletes the queued operations whose comments have the given IDs
+func RemoveQueued(mdPath string, ids []string) error {
+	queue, err := LoadQueue(mdPath)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	remaining := []*QueuedOp{}
+	for _, op := range queue.Ops {
+		if !remove[op.Comment.ID] {
+			remaining = append(remaining, op)
+		}
+	}
+	queue.Ops = remaining
+
+	return SaveQueue(mdPath, queue)
+}
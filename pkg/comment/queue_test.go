@@ -0,0 +1,118 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueueFlushAllOrNothing(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	content := "# Title\n\nFirst line\nSecond line"
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetQueuePath(mdPath); got != mdPath+".comments.queue.json" {
+		t.Errorf("GetQueuePath = %s", got)
+	}
+
+	thread := NewComment("claude", 3, "Queued thread")
+	reply := NewReply("claude", "Queued reply to a queued thread", thread)
+	suggestion := NewSuggestion("claude", 4, 4, "Tighten", "Second line", "Second")
+	for _, op := range []*QueuedOp{NewQueuedThread(thread), NewQueuedReply(thread.ID, reply), NewQueuedThread(suggestion)} {
+		if err := Enqueue(mdPath, op, content); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	if SidecarExists(mdPath) {
+		t.Error("Queueing should not create the sidecar")
+	}
+
+	queue, err := LoadQueue(mdPath)
+	if err != nil {
+		t.Fatalf("LoadQueue failed: %v", err)
+	}
+	if len(queue.Ops) != 3 || queue.Thread(thread.ID) == nil {
+		t.Fatalf("Expected 3 queued operations including the thread, got %d", len(queue.Ops))
+	}
+
+	// A reply whose thread isn't flushed with it fails the whole flush
+	doc := &DocumentWithComments{Content: content, Threads: []*Comment{}}
+	if _, err := ApplyQueued(doc, queue, queue.Ops[1:]); err == nil {
+		t.Error("Expected an error flushing a reply without its thread")
+	}
+	if len(doc.Threads) != 0 {
+		t.Errorf("A failed flush added %d thread(s)", len(doc.Threads))
+	}
+
+	changed, err := ApplyQueued(doc, queue, queue.Ops)
+	if err != nil {
+		t.Fatalf("ApplyQueued failed: %v", err)
+	}
+	if changed {
+		t.Error("Document hasn't changed since the operations were queued")
+	}
+	if len(doc.Threads) != 2 || len(doc.Threads[0].Replies) != 1 || doc.Threads[0].SectionPath != "Title" {
+		t.Errorf("Expected the thread with its reply and the suggestion, got %+v", doc.Threads)
+	}
+
+	if err := RemoveQueued(mdPath, []string{thread.ID, reply.ID, suggestion.ID}); err != nil {
+		t.Fatalf("RemoveQueued failed: %v", err)
+	}
+	if _, err := os.Stat(GetQueuePath(mdPath)); !os.IsNotExist(err) {
+		t.Error("Queue file should be removed once empty")
+	}
+}
+
+func TestQueueReviewMergeAndSave(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	content := "# Title\n\nFirst line\nSecond line"
+
+	first := NewQueuedThread(NewComment("claude", 3, "First"))
+	second := NewQueuedThread(NewComment("claude", 4, "Second"))
+	reply := NewQueuedReply(second.Comment.ID, NewComment("claude", 0, "Reply to second"))
+	suggestion := NewQueuedThread(NewSuggestion("claude", 4, 4, "Tighten", "Second line", "Second"))
+	for _, op := range []*QueuedOp{first, second, reply, suggestion} {
+		if err := Enqueue(mdPath, op, content); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	queue, err := LoadQueue(mdPath)
+	if err != nil {
+		t.Fatalf("LoadQueue failed: %v", err)
+	}
+	ops := queue.Ops
+
+	if err := MergeQueued(ops[0], ops[3], ops); err == nil {
+		t.Error("Expected an error merging a suggestion")
+	}
+	if err := MergeQueued(ops[0], ops[1], ops); err != nil {
+		t.Fatalf("MergeQueued failed: %v", err)
+	}
+	if ops[0].Comment.Text != "First\n\nSecond" {
+		t.Errorf("Merged text = %q", ops[0].Comment.Text)
+	}
+	if ops[2].ThreadID != first.Comment.ID {
+		t.Errorf("Reply to the merged thread should answer %s, got %s", first.Comment.ID, ops[2].ThreadID)
+	}
+
+	// An operation queued during the review is kept
+	late := NewQueuedThread(NewComment("claude", 3, "Late"))
+	if err := Enqueue(mdPath, late, content); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	ops[3].Comment.Text = "Edited while skipped"
+	if err := SaveReviewedQueue(mdPath, ops, []string{ops[0].Comment.ID, ops[1].Comment.ID}); err != nil {
+		t.Fatalf("SaveReviewedQueue failed: %v", err)
+	}
+	queue, err = LoadQueue(mdPath)
+	if err != nil {
+		t.Fatalf("LoadQueue failed: %v", err)
+	}
+	if len(queue.Ops) != 3 || queue.Ops[0].ThreadID != first.Comment.ID ||
+		queue.Ops[1].Comment.Text != "Edited while skipped" || queue.Ops[2].Comment.ID != late.Comment.ID {
+		t.Errorf("Expected the retargeted reply, the edited suggestion, and the late thread, got %d operation(s)", len(queue.Ops))
+	}
+}
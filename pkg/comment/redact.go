@@ -0,0 +1,88 @@
+package comment
+
+import "strings"
+
+// Redaction removes internal review chatter from threads before they are
+// shared outside the team (export, publish)
+type Redaction struct {
+	// Authors whose comments are dropped, with any replies to them
+	Authors []string
+
+	// Labels stripped from comment text wherever they appear, e.g. "#internal"
+	Labels []string
+
+	// RejectedSuggestions drops suggestions that were rejected
+	RejectedSuggestions bool
+}
+
+// IsEmpty reports whether the redaction changes nothing
+func (r Redaction) IsEmpty() bool {
+	return len(r.Authors) == 0 && len(r.Labels) == 0 && !r.RejectedSuggestions
+}
+
+// Apply returns redacted copies of the threads; the originals are not
+// modified. Dropping a thread's root drops the whole thread.
+func (r Redaction) Apply(threads []*Comment) []*Comment {
+	dropped := r.droppedAuthors()
+	var redact func(comments []*Comment) []*Comment
+	redact = func(comments []*Comment) []*Comment {
+		kept := make([]*Comment, 0, len(comments))
+		for _, c := range comments {
+			if dropped[c.Author] || (r.RejectedSuggestions && c.IsRejected()) {
+				continue
+			}
+			clone := c.Clone()
+			clone.Text = r.stripLabels(c.Text)
+			clone.Replies = redact(c.Replies)
+			kept = append(kept, clone)
+		}
+		return kept
+	}
+	return redact(threads)
+}
+
+// ApplyReviews returns redacted copies of the reviews, dropping those by
+// redacted authors
+func (r Redaction) ApplyReviews(reviews []*Review) []*Review {
+	dropped := r.droppedAuthors()
+	kept := make([]*Review, 0, len(reviews))
+	for _, review := range reviews {
+		if dropped[review.Reviewer] {
+			continue
+		}
+		clone := *review
+		clone.Summary = r.stripLabels(review.Summary)
+		kept = append(kept, &clone)
+	}
+	return kept
+}
+
+// droppedAuthors returns the redacted authors as a set
+func (r Redaction) droppedAuthors() map[string]bool {
+	dropped := make(map[string]bool, len(r.Authors))
+	for _, author := range r.Authors {
+		dropped[author] = true
+	}
+	return dropped
+}
+
+// stripLabels removes the labels from text, tidying the spaces left behind
+// on the lines they were on
+func (r Redaction) stripLabels(text string) string {
+	if len(r.Labels) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		stripped := line
+		for _, label := range r.Labels {
+			if label != "" {
+				stripped = strings.ReplaceAll(stripped, label, "")
+			}
+		}
+		if stripped != line {
+			lines[i] = strings.Join(strings.Fields(stripped), " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
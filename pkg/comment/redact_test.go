@@ -0,0 +1,61 @@
+package comment
+
+import "testing"
+
+func TestRedactionApply(t *testing.T) {
+	rejected := false
+	threads := []*Comment{
+		{ID: "c1", Author: "alice", Text: "[Q] Is this final? #internal", Replies: []*Comment{
+			{ID: "r1", Author: "bot", Text: "Auto-triaged", Replies: []*Comment{
+				{ID: "r2", Author: "alice", Text: "Thanks", Replies: []*Comment{}},
+			}},
+			{ID: "r3", Author: "bob", Text: "Yes\n  indented #internal stays tidy", Replies: []*Comment{}},
+		}},
+		{ID: "c2", Author: "bot", Text: "Lint warning", Replies: []*Comment{}},
+		{ID: "c3", Author: "bob", IsSuggestion: true, Accepted: &rejected, Text: "Reword", Replies: []*Comment{}},
+	}
+
+	redacted := Redaction{Authors: []string{"bot"}, Labels: []string{"#internal"}, RejectedSuggestions: true}.Apply(threads)
+
+	if len(redacted) != 1 || redacted[0].ID != "c1" {
+		t.Fatalf("Expected only c1 to remain, got %d thread(s)", len(redacted))
+	}
+	root := redacted[0]
+	if root.Text != "[Q] Is this final?" {
+		t.Errorf("Label not stripped: %q", root.Text)
+	}
+	if len(root.Replies) != 1 || root.Replies[0].ID != "r3" {
+		t.Fatalf("Expected the bot's reply and its replies dropped, got %d replies", len(root.Replies))
+	}
+	if got := root.Replies[0].Text; got != "Yes\nindented stays tidy" {
+		t.Errorf("Reply text = %q", got)
+	}
+
+	// The originals are untouched
+	if threads[0].Text != "[Q] Is this final? #internal" || len(threads[0].Replies) != 2 {
+		t.Error("Apply modified the original threads")
+	}
+}
+
+func TestRedactionApplyReviews(t *testing.T) {
+	reviews := []*Review{
+		{ID: "r1", Reviewer: "bot", Summary: "Automated pass"},
+		{ID: "r2", Reviewer: "alice", Summary: "Looks good #internal"},
+	}
+	redacted := Redaction{Authors: []string{"bot"}, Labels: []string{"#internal"}}.ApplyReviews(reviews)
+	if len(redacted) != 1 || redacted[0].ID != "r2" || redacted[0].Summary != "Looks good" {
+		t.Errorf("Unexpected redacted reviews: %+v", redacted)
+	}
+	if reviews[1].Summary != "Looks good #internal" {
+		t.Error("ApplyReviews modified the original review")
+	}
+}
+
+func TestRedactionIsEmpty(t *testing.T) {
+	if !(Redaction{}).IsEmpty() {
+		t.Error("Zero redaction should be empty")
+	}
+	if (Redaction{RejectedSuggestions: true}).IsEmpty() {
+		t.Error("Dropping rejected suggestions is not empty")
+	}
+}
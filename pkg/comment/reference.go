@@ -0,0 +1,48 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Location returns where a comment points in filename, in the "file#L12" or
+// "file#L12-L20" form code hosts use for line links. Document-level comments
+// return just the filename.
+func Location(c *Comment, filename string) string {
+	if c.IsDocumentLevel() {
+		return filename
+	}
+	first, last := c.LineRange()
+	if last > first {
+		return fmt.Sprintf("%s#L%d-L%d", filename, first, last)
+	}
+	return fmt.Sprintf("%s#L%d", filename, first)
+}
+
+// FormatReference formats a comment for pasting elsewhere (chat, issues):
+// the quoted text it refers to, the comment, and a link back to it.
+// The quote is the text captured when the comment was made, the original
+// text of a suggestion, or else the current text of its lines.
+func FormatReference(c *Comment, filename, docContent string) string {
+	quote := c.Quote
+	if c.IsSuggestion {
+		quote = c.OriginalText
+	}
+	if quote == "" && !c.IsDocumentLevel() {
+		lines := strings.Split(docContent, "\n")
+		if first, last := c.LineRange(); first >= 1 && last <= len(lines) {
+			quote = strings.Join(lines[first-1:last], "\n")
+		}
+	}
+
+	var b strings.Builder
+	if quote != "" {
+		for _, line := range strings.Split(quote, "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "@%s: %s\n", c.Author, c.Text)
+	fmt.Fprintf(&b, "— %s (comment %s)", Location(c, filename), c.ID)
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package comment
+
+import "testing"
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		c        *Comment
+		expected string
+	}{
+		{&Comment{Line: 12}, "doc.md#L12"},
+		{&Comment{Line: 12, EndLine: 20}, "doc.md#L12-L20"},
+		{&Comment{IsSuggestion: true, Line: 3, StartLine: 3, EndLine: 5}, "doc.md#L3-L5"},
+		{&Comment{Line: DocumentLine}, "doc.md"},
+	}
+	for _, tt := range tests {
+		if got := Location(tt.c, "doc.md"); got != tt.expected {
+			t.Errorf("Location(%+v) = %q, want %q", tt.c, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatReference(t *testing.T) {
+	content := "# Title\n\nFirst line\nSecond line"
+
+	// Captured quote wins over the current text
+	c := &Comment{ID: "c1", Author: "alice", Text: "Too vague", Line: 3, EndLine: 4, Quote: "first\nsecond"}
+	expected := "> first\n> second\n\n@alice: Too vague\n— doc.md#L3-L4 (comment c1)"
+	if got := FormatReference(c, "doc.md", content); got != expected {
+		t.Errorf("FormatReference with quote:\n%q\nwant\n%q", got, expected)
+	}
+
+	// Without a quote, the current text of the line is used
+	c = &Comment{ID: "c2", Author: "bob", Text: "Why?", Line: 3}
+	expected = "> First line\n\n@bob: Why?\n— doc.md#L3 (comment c2)"
+	if got := FormatReference(c, "doc.md", content); got != expected {
+		t.Errorf("FormatReference without quote:\n%q\nwant\n%q", got, expected)
+	}
+
+	// Document-level comments have nothing to quote
+	c = &Comment{ID: "c3", Author: "carol", Text: "Overall good", Line: DocumentLine}
+	expected = "@carol: Overall good\n— doc.md (comment c3)"
+	if got := FormatReference(c, "doc.md", content); got != expected {
+		t.Errorf("FormatReference document-level:\n%q\nwant\n%q", got, expected)
+	}
+}
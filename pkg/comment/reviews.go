@@ -0,0 +1,122 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Review verdicts, as in a pull request review
+const (
+	VerdictApprove        = "approve"
+	VerdictRequestChanges = "request-changes"
+
+	// VerdictInProgress stands in for the verdict of a review not submitted yet
+	VerdictInProgress = "in-progress"
+)
+
+// Verdicts lists the valid review verdicts
+var Verdicts = []string{VerdictApprove, VerdictRequestChanges}
+
+// Review groups the comments a reviewer writes during one pass over a
+// document. While a review is open, new comments and replies by the reviewer
+// are tagged with its ID; submitting it records a verdict.
+type Review struct {
+	ID          string     `json:"id"`
+	Reviewer    string     `json:"reviewer"`
+	StartedAt   time.Time  `json:"startedAt"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"` // nil while the review is in progress
+	Verdict     string     `json:"verdict,omitempty"`     // approve or request-changes once submitted
+	Summary     string     `json:"summary,omitempty"`     // Optional overall feedback given on submit
+
+	// Sections hashes each section's text as it was when the review was
+	// submitted, so later changes can be told apart (see SectionChanged)
+	Sections map[string]string `json:"sections,omitempty"`
+}
+
+// NewReview starts a review by reviewer
+func NewReview(reviewer string) *Review {
+	return &Review{
+		ID:        generateID("r"),
+		Reviewer:  reviewer,
+		StartedAt: now(),
+	}
+}
+
+// IsOpen returns true if the review hasn't been submitted yet
+func (r *Review) IsOpen() bool {
+	return r.SubmittedAt == nil
+}
+
+// Submit closes the review with a verdict
+func (r *Review) Submit(verdict, summary string, now time.Time) error {
+	if !r.IsOpen() {
+		return fmt.Errorf("review %s was already submitted", r.ID)
+	}
+	if !IsValidVerdict(verdict) {
+		return fmt.Errorf("invalid verdict %q (valid: %s)", verdict, strings.Join(Verdicts, ", "))
+	}
+	r.SubmittedAt = &now
+	r.Verdict = verdict
+	r.Summary = summary
+	return nil
+}
+
+// SubmitReview submits r with a verdict, recording the document's section
+// hashes so owner checks can tell which sections changed after it
+func (d *DocumentWithComments) SubmitReview(r *Review, verdict, summary string, now time.Time) error {
+	if err := r.Submit(verdict, summary, now); err != nil {
+		return err
+	}
+	r.Sections = d.SectionHashes()
+	return nil
+}
+
+// IsValidVerdict reports whether verdict is one of Verdicts
+func IsValidVerdict(verdict string) bool {
+	for _, v := range Verdicts {
+		if v == verdict {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenReview returns reviewer's review in progress on the document, or nil
+func (d *DocumentWithComments) OpenReview(reviewer string) *Review {
+	for _, r := range d.Reviews {
+		if r.IsOpen() && strings.EqualFold(r.Reviewer, reviewer) {
+			return r
+		}
+	}
+	return nil
+}
+
+// AttachToOpenReview tags c with its author's open review, if there is one
+func (d *DocumentWithComments) AttachToOpenReview(c *Comment) {
+	if r := d.OpenReview(c.Author); r != nil {
+		c.ReviewID = r.ID
+	}
+}
+
+// ReviewComments returns every comment (replies included) written during a review
+func (d *DocumentWithComments) ReviewComments(reviewID string) []*Comment {
+	comments := []*Comment{}
+	for _, c := range d.GetAllComments() {
+		if c.ReviewID == reviewID {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// ApprovedByOther reports whether someone other than author submitted an
+// approving review of the document after since
+func (d *DocumentWithComments) ApprovedByOther(author string, since time.Time) bool {
+	for _, r := range d.Reviews {
+		if r.Verdict == VerdictApprove && !strings.EqualFold(r.Reviewer, author) && r.SubmittedAt != nil && r.SubmittedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReviewLifecycle(t *testing.T) {
+	doc := &DocumentWithComments{Content: "# Title\n\nText", Threads: []*Comment{}}
+
+	review := NewReview("bob")
+	doc.Reviews = append(doc.Reviews, review)
+
+	if doc.OpenReview("Bob") != review {
+		t.Fatal("Expected bob's open review (reviewer match is case-insensitive)")
+	}
+	if doc.OpenReview("alice") != nil {
+		t.Error("alice has no review in progress")
+	}
+
+	inReview := NewComment("bob", 3, "Typo")
+	outside := NewComment("alice", 3, "Looks fine")
+	for _, c := range []*Comment{inReview, outside} {
+		doc.AttachToOpenReview(c)
+		doc.Threads = append(doc.Threads, c)
+	}
+	if err := AddReplyToThread(doc.Threads, outside.ID, "bob", "Agreed"); err != nil {
+		t.Fatal(err)
+	}
+	doc.AttachToOpenReview(outside.Replies[0])
+
+	if got := doc.ReviewComments(review.ID); len(got) != 2 {
+		t.Errorf("Expected bob's comment and reply in the review, got %d", len(got))
+	}
+	if outside.ReviewID != "" {
+		t.Error("alice's comment should not join bob's review")
+	}
+
+	if err := review.Submit("lgtm", "", time.Now()); err == nil {
+		t.Error("Expected an invalid verdict to be rejected")
+	}
+	if err := review.Submit(VerdictRequestChanges, "Fix the typo", time.Now()); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if review.IsOpen() || doc.OpenReview("bob") != nil {
+		t.Error("Submitted review should be closed")
+	}
+	if err := review.Submit(VerdictApprove, "", time.Now()); err == nil {
+		t.Error("Expected a second submit to fail")
+	}
+
+	// Comments after submitting aren't grouped
+	later := NewComment("bob", 1, "One more thing")
+	doc.AttachToOpenReview(later)
+	if later.ReviewID != "" {
+		t.Error("Comment after submit should not join the review")
+	}
+
+	s := ComputeStats(&DocumentWithComments{Reviews: []*Review{review, NewReview("carol")}})
+	if s.Reviews != 2 || s.Verdicts[VerdictRequestChanges] != 1 || s.Verdicts[VerdictInProgress] != 1 {
+		t.Errorf("Unexpected review stats: %d %v", s.Reviews, s.Verdicts)
+	}
+}
+
+func TestReviewsRoundTrip(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("# Title"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	review := NewReview("bob")
+	review.Submit(VerdictApprove, "Ship it", time.Now())
+	doc := &DocumentWithComments{Content: "# Title", Threads: []*Comment{}, Reviews: []*Review{review}}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Reviews) != 1 || loaded.Reviews[0].Verdict != VerdictApprove || loaded.Reviews[0].Summary != "Ship it" {
+		t.Errorf("Reviews not preserved: %+v", loaded.Reviews)
+	}
+}
@@ -0,0 +1,133 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Every save that writes suggestions accepted since the document was loaded
+// records a revision in the sidecar: which suggestions were applied and the
+// patch that undoes them. Only the changed lines are kept, so the history
+// stays small next to the threads. RevertTo undoes revisions newest first,
+// moving comments as the undo edits require and reopening the suggestions
+// whose changes it removes.
+
+// Revision is one change to the document made by accepting suggestions
+type Revision struct {
+	Number      int            `json:"number"` // 1 for the first recorded change; 0 is the document before it
+	Timestamp   time.Time      `json:"timestamp"`
+	Suggestions []string       `json:"suggestions"` // Suggestions applied in this revision
+	Hash        string         `json:"hash"`        // Document hash after the revision
+	Undo        []RevisionHunk `json:"undo"`        // Applied last to first, restores the document before the revision
+}
+
+// RevisionHunk replaces Lines lines of the revised document, starting at
+// StartLine, with Restore
+type RevisionHunk struct {
+	StartLine int      `json:"startLine"`
+	Lines     int      `json:"lines"`
+	Restore   []string `json:"restore,omitempty"`
+}
+
+// recordRevision adds a revision for the suggestions applied since the
+// markdown was last read or written, if they changed Content. Called just
+// before the markdown is written.
+func (d *DocumentWithComments) recordRevision() {
+	applied := d.applied
+	d.applied = nil
+	if len(applied) == 0 || !d.ContentChanged() {
+		return
+	}
+
+	number := 1
+	if len(d.Revisions) > 0 {
+		number = d.Revisions[len(d.Revisions)-1].Number + 1
+	}
+	d.Revisions = append(d.Revisions, &Revision{
+		Number:      number,
+		Timestamp:   now(),
+		Suggestions: applied,
+		Hash:        ComputeDocumentHash(d.Content),
+		Undo:        undoHunks(d.savedContent, d.Content),
+	})
+}
+
+// undoHunks returns the hunks that turn after back into before
+func undoHunks(before, after string) []RevisionHunk {
+	ops := diffLines(strings.Split(before, "\n"), strings.Split(after, "\n"))
+
+	var hunks []RevisionHunk
+	var current *RevisionHunk
+	line := 1 // Line of after the next op is at
+	for _, op := range ops {
+		if op.kind == ' ' {
+			current = nil
+			line++
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, RevisionHunk{StartLine: line})
+			current = &hunks[len(hunks)-1]
+		}
+		if op.kind == '+' {
+			current.Lines++
+			line++
+		} else {
+			current.Restore = append(current.Restore, op.text)
+		}
+	}
+	return hunks
+}
+
+// RevertTo rolls the document back to revision number (0 for before the
+// first recorded revision), undoing later revisions newest first. Comments
+// move as the undo edits require, the suggestions applied in the undone
+// revisions are pending again, and the undone revisions are dropped from the
+// history. Returns the reopened suggestions.
+func (d *DocumentWithComments) RevertTo(number int) ([]*Comment, error) {
+	if len(d.Revisions) == 0 {
+		return nil, fmt.Errorf("no revisions recorded")
+	}
+	latest := d.Revisions[len(d.Revisions)-1]
+	first := d.Revisions[0].Number - 1
+	if number < first || number > latest.Number {
+		return nil, fmt.Errorf("revision %d not found (history has %d-%d)", number, first, latest.Number)
+	}
+	if number == latest.Number {
+		return nil, fmt.Errorf("the document is already at revision %d", number)
+	}
+	if ComputeDocumentHash(d.Content) != latest.Hash {
+		return nil, fmt.Errorf("the document changed since revision %d outside the tool; reverting would discard those changes", latest.Number)
+	}
+
+	var reopened []*Comment
+	for len(d.Revisions) > 0 && d.Revisions[len(d.Revisions)-1].Number > number {
+		revision := d.Revisions[len(d.Revisions)-1]
+
+		lines := strings.Split(d.Content, "\n")
+		for i := len(revision.Undo) - 1; i >= 0; i-- {
+			hunk := revision.Undo[i]
+			start := hunk.StartLine - 1
+			if start < 0 || start+hunk.Lines > len(lines) {
+				return nil, fmt.Errorf("revision %d does not match the document (lines %d-%d)", revision.Number, hunk.StartLine, hunk.StartLine+hunk.Lines-1)
+			}
+			restored := append(append(append([]string{}, lines[:start]...), hunk.Restore...), lines[start+hunk.Lines:]...)
+			lines = restored
+			d.Content = strings.Join(lines, "\n")
+			d.RecordEdit(hunk.StartLine, hunk.StartLine+hunk.Lines-1, len(hunk.Restore))
+		}
+
+		// The revision's suggestions are no longer applied
+		for _, id := range revision.Suggestions {
+			if s := d.FindCommentByID(id); s != nil && s.IsAccepted() {
+				s.Accepted = nil
+				s.DecidedAt = nil
+				reopened = append(reopened, s)
+			}
+		}
+		d.Revisions = d.Revisions[:len(d.Revisions)-1]
+	}
+
+	return reopened, nil
+}
@@ -0,0 +1,135 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// acceptAndSave applies a suggestion the way the accept paths do and saves
+func acceptAndSave(t *testing.T, mdPath string, doc *DocumentWithComments, s *Comment) {
+	t.Helper()
+	content, err := ApplySuggestion(doc.Content, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Content = content
+	if err := AcceptSuggestion(doc.Threads, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	doc.RecordApplied(s)
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRevertToEarlierRevision(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	original := "# Title\n\nOne.\nTwo.\nThree.\nFour.\n"
+	if err := os.WriteFile(mdPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onFour := NewComment("alice", 6, "About four")
+	grow := NewSuggestion("bob", 3, 3, "Expand", "One.", "One.\nAnd a half.")
+	shrink := NewSuggestion("bob", 4, 5, "Merge", "Two.\nThree.", "Two and three.")
+	doc.Threads = []*Comment{onFour, grow, shrink}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Revisions) != 0 {
+		t.Fatalf("Saving without accepting recorded %d revision(s)", len(doc.Revisions))
+	}
+
+	acceptAndSave(t, mdPath, doc, grow)
+	if onFour.Line != 7 {
+		t.Fatalf("Comment at line %d after the first accept, want 7", onFour.Line)
+	}
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptAndSave(t, mdPath, doc, doc.FindCommentByID(shrink.ID))
+
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Revisions) != 2 || doc.Revisions[1].Suggestions[0] != shrink.ID {
+		t.Fatalf("Revisions = %+v, want two, the second applying %s", doc.Revisions, shrink.ID)
+	}
+
+	reopened, err := doc.RevertTo(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Content != original {
+		t.Errorf("Reverted content = %q, want %q", doc.Content, original)
+	}
+	if len(reopened) != 2 || len(doc.Revisions) != 0 {
+		t.Errorf("Reopened %d suggestion(s) with %d revision(s) left, want 2 and 0", len(reopened), len(doc.Revisions))
+	}
+	for _, s := range reopened {
+		if !s.IsPending() {
+			t.Errorf("Suggestion %s should be pending again", s.ID)
+		}
+	}
+	if c := doc.FindCommentByID(onFour.ID); c.Line != 6 {
+		t.Errorf("Comment at line %d after reverting, want 6", c.Line)
+	}
+	if s := doc.FindCommentByID(grow.ID); s.StartLine != 3 {
+		t.Errorf("Reopened suggestion at line %d, want 3", s.StartLine)
+	}
+}
+
+func TestRevertToPartial(t *testing.T) {
+	doc := &DocumentWithComments{Content: "a\nb\nc", savedContent: "a\nb\nc"}
+	first := NewSuggestion("bob", 1, 1, "A", "a", "A")
+	second := NewSuggestion("bob", 3, 3, "C", "c", "C\nD")
+	doc.Threads = []*Comment{first, second}
+
+	for _, s := range []*Comment{first, second} {
+		content, err := ApplySuggestion(doc.Content, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Content = content
+		s.Accepted = new(bool)
+		*s.Accepted = true
+		doc.RecordApplied(s)
+		doc.recordRevision()
+		doc.savedContent = doc.Content
+	}
+
+	if _, err := doc.RevertTo(2); err == nil {
+		t.Error("Expected an error reverting to the current revision")
+	}
+	if _, err := doc.RevertTo(3); err == nil {
+		t.Error("Expected an error reverting to a revision that doesn't exist")
+	}
+
+	reopened, err := doc.RevertTo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Content != "A\nb\nc" || len(reopened) != 1 || reopened[0] != second || !first.IsAccepted() {
+		t.Errorf("After reverting to 1: content %q, reopened %d, want only the second undone", doc.Content, len(reopened))
+	}
+}
+
+func TestRevertRefusesOutsideEdits(t *testing.T) {
+	doc := &DocumentWithComments{Content: "a", savedContent: "a"}
+	s := NewSuggestion("bob", 1, 1, "A", "a", "A")
+	doc.Content = "A"
+	doc.RecordApplied(s)
+	doc.recordRevision()
+
+	doc.Content = "A edited by hand"
+	if _, err := doc.RevertTo(0); err == nil {
+		t.Error("Expected an error when the document changed after the latest revision")
+	}
+}
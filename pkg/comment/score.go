@@ -0,0 +1,74 @@
+package comment
+
+import (
+	"sort"
+	"time"
+)
+
+// A thread's score says how urgently it needs attention, so the most
+// important items can be listed first: blockers ahead of everything else,
+// then by priority, with threads left open for days and busy discussions
+// creeping up. Resolved threads and decided suggestions sink to the bottom.
+
+// Score weights by comment type; untyped pending suggestions count as "S"
+var typeScores = map[string]int{
+	"B": 40,
+	"Q": 15,
+	"T": 10,
+	"S": 5,
+	"E": 5,
+}
+
+// Score weights by priority
+var priorityScores = map[string]int{
+	"high":   30,
+	"medium": 20,
+	"low":    10,
+}
+
+const (
+	maxAgeScore     = 20             // One point per day since the thread was started
+	replyScore      = 3              // Per reply, up to maxReplyScore
+	maxReplyScore   = 15             // Most that replies can add
+	recentScore     = 5              // For activity within recentActivity
+	recentActivity  = 48 * time.Hour // How long since the newest comment counts as recent
+	resolvedPenalty = 100            // For resolved threads and decided suggestions
+)
+
+// Score returns how urgently the thread needs attention at the given time;
+// higher is more urgent
+func (c *Comment) Score(at time.Time) int {
+	commentType := c.Type
+	if commentType == "" && c.IsSuggestion {
+		commentType = "S"
+	}
+	score := typeScores[commentType] + priorityScores[c.GetPriority()]
+
+	if days := int(at.Sub(c.Timestamp).Hours() / 24); days > 0 {
+		score += min(days, maxAgeScore)
+	}
+	score += min(c.CountReplies()*replyScore, maxReplyScore)
+	if at.Sub(c.LatestTimestamp()) < recentActivity {
+		score += recentScore
+	}
+
+	if c.Resolved || (c.IsSuggestion && !c.IsPending()) {
+		score -= resolvedPenalty
+	}
+	return score
+}
+
+// SortByScore sorts threads by score, highest first, then by line
+func SortByScore(threads []*Comment) {
+	at := now()
+	scores := make(map[*Comment]int, len(threads))
+	for _, t := range threads {
+		scores[t] = t.Score(at)
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		if scores[threads[i]] != scores[threads[j]] {
+			return scores[threads[i]] > scores[threads[j]]
+		}
+		return threads[i].Line < threads[j].Line
+	})
+}
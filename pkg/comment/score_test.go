@@ -0,0 +1,39 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreOrdersByUrgency(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	restore := SetClock(FixedClock(start))
+	blocker := NewCommentWithType("alice", 40, "Broken example", "B")
+	blocker.Priority = "low"
+	question := NewCommentWithType("bob", 10, "Why this way?", "Q")
+	question.Priority = "high"
+	note := NewComment("carol", 5, "Nice")
+	old := NewComment("dave", 20, "Still open")
+	resolved := NewCommentWithType("erin", 1, "Fixed", "B")
+	resolved.Resolved = true
+	restore()
+
+	// A week later, old has a reply from today
+	at := start.Add(7 * 24 * time.Hour)
+	old.Timestamp = start.Add(-10 * 24 * time.Hour)
+	old.Replies = []*Comment{{ID: "r1", Author: "alice", Timestamp: at, Text: "Bump"}}
+
+	if got, want := note.Score(at), priorityScores["medium"]+7; got != want {
+		t.Errorf("note.Score = %d, want %d", got, want)
+	}
+
+	threads := []*Comment{note, resolved, old, question, blocker}
+	defer SetClock(FixedClock(at))()
+	SortByScore(threads)
+	want := []*Comment{blocker, question, old, note, resolved}
+	for i := range want {
+		if threads[i] != want[i] {
+			t.Fatalf("Position %d: got %q, want %q", i, threads[i].Text, want[i].Text)
+		}
+	}
+}
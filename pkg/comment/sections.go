@@ -1,10 +1,6 @@
 package comment
 
-import (
-	"fmt"
-
-	"github.com/rcliao/comments/pkg/markdown"
-)
+import "fmt"
 
 // ComputeSectionsForComments updates section metadata for all comments in a document
 // This should be called when:
@@ -17,7 +13,7 @@ func ComputeSectionsForComments(doc *DocumentWithComments) {
 	}
 
 	// Parse document structure
-	docStructure := markdown.ParseDocument(doc.Content)
+	docStructure := doc.Structure()
 
 	// Update each comment with section information (roots and replies)
 	allComments := doc.GetAllComments()
@@ -42,8 +38,8 @@ func ComputeSectionsForComments(doc *DocumentWithComments) {
 // ResolveSectionToLines resolves a section path to a line range
 // Returns (startLine, endLine, error)
 // If includeChildren is true, returns the range including all nested sub-sections
-func ResolveSectionToLines(docContent string, sectionPath string, includeChildren bool) (int, int, error) {
-	docStructure := markdown.ParseDocument(docContent)
+func ResolveSectionToLines(doc *DocumentWithComments, sectionPath string, includeChildren bool) (int, int, error) {
+	docStructure := doc.Structure()
 
 	if includeChildren {
 		// Get section and all its descendants
@@ -79,7 +75,7 @@ func GetCommentsInSection(doc *DocumentWithComments, sectionPath string) []*Comm
 	}
 
 	// Parse document to get section info
-	docStructure := markdown.ParseDocument(doc.Content)
+	docStructure := doc.Structure()
 	section := docStructure.FindSection(sectionPath)
 	if section == nil {
 		return []*Comment{}
@@ -105,14 +101,14 @@ func GetCommentsInSection(doc *DocumentWithComments, sectionPath string) []*Comm
 }
 
 // ListAvailableSections returns all section paths in the document
-func ListAvailableSections(docContent string) []string {
-	docStructure := markdown.ParseDocument(docContent)
+func ListAvailableSections(doc *DocumentWithComments) []string {
+	docStructure := doc.Structure()
 	return docStructure.ListAllPaths()
 }
 
 // ValidateSectionPath checks if a section path exists in the document
-func ValidateSectionPath(docContent string, sectionPath string) error {
-	docStructure := markdown.ParseDocument(docContent)
+func ValidateSectionPath(doc *DocumentWithComments, sectionPath string) error {
+	docStructure := doc.Structure()
 	section := docStructure.FindSection(sectionPath)
 	if section == nil {
 		// Provide helpful error message with available sections
@@ -139,18 +135,18 @@ func joinPaths(paths []string) string {
 }
 
 // GetSectionForLine returns the section path for a specific line number
-func GetSectionForLine(docContent string, line int) string {
-	docStructure := markdown.ParseDocument(docContent)
+func GetSectionForLine(doc *DocumentWithComments, line int) string {
+	docStructure := doc.Structure()
 	return docStructure.GetSectionPath(line)
 }
 
 // UpdateCommentSection updates section metadata for a single comment
-func UpdateCommentSection(comment *Comment, docContent string) {
+func UpdateCommentSection(comment *Comment, doc *DocumentWithComments) {
 	if comment == nil || comment.Line <= 0 {
 		return
 	}
 
-	docStructure := markdown.ParseDocument(docContent)
+	docStructure := doc.Structure()
 	section, exists := docStructure.SectionsByLine[comment.Line]
 	if exists {
 		comment.SectionID = section.ID
@@ -0,0 +1,164 @@
+package comment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Seen state records when each reader last viewed each thread, so threads
+// with activity since then can be flagged as unread. Like private notes, it
+// lives in the user's config directory rather than in or next to the shared
+// sidecar.
+
+// SeenState holds the last-seen times of a document's threads
+type SeenState struct {
+	// Readers maps a reader to the time they last viewed each thread, by
+	// thread ID
+	Readers map[string]map[string]time.Time `json:"readers"`
+}
+
+// GetSeenPath returns the seen-state file for a markdown file, under the
+// user's config directory (see EnvUserDir)
+func GetSeenPath(mdPath string) (string, error) {
+	return userStatePath("seen", mdPath, ".json")
+}
+
+// legacySeenPath returns where seen state used to be kept, next to the
+// sidecar; state found there is moved on the next save
+func legacySeenPath(mdPath string) string {
+	return strings.TrimSuffix(GetSidecarPath(mdPath), ".json") + ".seen.json"
+}
+
+// LoadSeen reads the seen state for a markdown file, returning an empty
+// state if there is none
+func LoadSeen(mdPath string) (*SeenState, error) {
+	seen := &SeenState{Readers: map[string]map[string]time.Time{}}
+
+	path, err := GetSeenPath(mdPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		path = legacySeenPath(mdPath)
+		data, err = os.ReadFile(path)
+	}
+	if os.IsNotExist(err) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seen state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, seen); err != nil {
+		return nil, fmt.Errorf("failed to parse seen state %s: %w", path, err)
+	}
+	if seen.Readers == nil {
+		seen.Readers = map[string]map[string]time.Time{}
+	}
+	return seen, nil
+}
+
+// SaveSeen writes the seen state for a markdown file, removing any left
+// next to the sidecar by earlier versions
+func SaveSeen(mdPath string, seen *SeenState) error {
+	path, err := GetSeenPath(mdPath)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal seen state: %w", err)
+	}
+	if err := writeUserState(path, data); err != nil {
+		return fmt.Errorf("failed to write seen state: %w", err)
+	}
+	if err := os.Remove(legacySeenPath(mdPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove seen state: %w", err)
+	}
+	return nil
+}
+
+// LastSeen returns when reader last viewed the thread (zero if never)
+func (s *SeenState) LastSeen(reader, threadID string) time.Time {
+	return s.Readers[reader][threadID]
+}
+
+// MarkSeen records that reader has viewed the threads now
+func (s *SeenState) MarkSeen(reader string, threads ...*Comment) {
+	if s.Readers == nil {
+		s.Readers = map[string]map[string]time.Time{}
+	}
+	if s.Readers[reader] == nil {
+		s.Readers[reader] = map[string]time.Time{}
+	}
+	at := now()
+	for _, thread := range threads {
+		s.Readers[reader][thread.ID] = at
+	}
+}
+
+// IsUnread reports whether someone other than reader has written in the
+// thread since reader last viewed it. Threads reader has never viewed are
+// unread unless reader wrote everything in them.
+func (s *SeenState) IsUnread(reader string, thread *Comment) bool {
+	latest, ok := latestActivityExcept(thread, reader)
+	return ok && latest.After(s.LastSeen(reader, thread.ID))
+}
+
+// latestActivityExcept returns the time of the latest comment in the thread
+// not written by author, and false if author wrote every comment
+func latestActivityExcept(c *Comment, author string) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	if c.Author != author {
+		latest, found = c.Timestamp, true
+	}
+	for _, reply := range c.Replies {
+		if t, ok := latestActivityExcept(reply, author); ok && (!found || t.After(latest)) {
+			latest, found = t, true
+		}
+	}
+	return latest, found
+}
+
+// Participants returns everyone who has written in the thread: the root's
+// author, then reply authors in thread order
+func Participants(thread *Comment) []string {
+	var participants []string
+	seen := map[string]bool{}
+	var walk func(c *Comment)
+	walk = func(c *Comment) {
+		if !seen[c.Author] {
+			seen[c.Author] = true
+			participants = append(participants, c.Author)
+		}
+		for _, reply := range c.Replies {
+			walk(reply)
+		}
+	}
+	walk(thread)
+	return participants
+}
+
+// ByUnread matches threads with activity reader hasn't seen
+func ByUnread(seen *SeenState, reader string) Filter {
+	return func(c *Comment) bool {
+		return seen.IsUnread(reader, c)
+	}
+}
+
+// ByParticipant matches threads author has written in, root or reply
+func ByParticipant(author string) Filter {
+	return func(c *Comment) bool {
+		for _, p := range Participants(c) {
+			if p == author {
+				return true
+			}
+		}
+		return false
+	}
+}
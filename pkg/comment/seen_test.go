@@ -0,0 +1,115 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSeenStateTracksUnreadActivity(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	restore := SetClock(FixedClock(start))
+	defer restore()
+
+	mine := NewComment("alice", 3, "My question")
+	theirs := NewComment("bob", 5, "A note")
+	threads := []*Comment{mine, theirs}
+
+	seen := &SeenState{}
+	if seen.IsUnread("alice", mine) {
+		t.Error("A thread only alice wrote in should not be unread for her")
+	}
+	if !seen.IsUnread("alice", theirs) {
+		t.Error("A thread alice never viewed should be unread")
+	}
+
+	SetClock(FixedClock(start.Add(time.Minute)))
+	seen.MarkSeen("alice", threads...)
+	seen.MarkSeen("bob", threads...)
+	if got := ByUnread(seen, "alice").Apply(threads); len(got) != 0 {
+		t.Errorf("Expected nothing unread after viewing, got %d", len(got))
+	}
+
+	// bob replies to alice's question after she looked
+	SetClock(FixedClock(start.Add(time.Hour)))
+	if err := AddReplyToThread(threads, mine.ID, "bob", "Because"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ByUnread(seen, "alice").Apply(threads); len(got) != 1 || got[0] != mine {
+		t.Errorf("Expected alice's thread to be unread, got %d", len(got))
+	}
+	if seen.IsUnread("bob", mine) {
+		t.Error("bob's own reply should not make the thread unread for him")
+	}
+
+	if got := Participants(mine); len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Errorf("Participants = %v", got)
+	}
+	if got := ByParticipant("bob").Apply(threads); len(got) != 2 {
+		t.Errorf("Expected bob in both threads, got %d", len(got))
+	}
+}
+
+func TestSeenStateRoundTrip(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("# Title\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	userDir := t.TempDir()
+	t.Setenv(EnvUserDir, userDir)
+	if got, err := GetSeenPath(mdPath); err != nil || filepath.Dir(got) != filepath.Join(userDir, "seen") {
+		t.Errorf("GetSeenPath = %s, %v", got, err)
+	}
+
+	seen, err := LoadSeen(mdPath)
+	if err != nil || len(seen.Readers) != 0 {
+		t.Fatalf("Expected an empty state, got %v (%v)", seen, err)
+	}
+
+	thread := NewComment("bob", 1, "Hello")
+	seen.MarkSeen("alice", thread)
+	if err := SaveSeen(mdPath, seen); err != nil {
+		t.Fatalf("SaveSeen failed: %v", err)
+	}
+
+	loaded, err := LoadSeen(mdPath)
+	if err != nil {
+		t.Fatalf("LoadSeen failed: %v", err)
+	}
+	if !loaded.LastSeen("alice", thread.ID).Equal(seen.LastSeen("alice", thread.ID)) {
+		t.Error("Last-seen time did not survive a round trip")
+	}
+
+	// Seen state stays out of the shared sidecar
+	if SidecarExists(mdPath) {
+		t.Error("Saving seen state should not create the sidecar")
+	}
+}
+
+func TestSeenStateMovesOutOfSidecarDirectory(t *testing.T) {
+	t.Setenv(EnvUserDir, t.TempDir())
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("# Title\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// State written next to the sidecar by an earlier version
+	legacy := mdPath + ".comments.seen.json"
+	if err := os.WriteFile(legacy, []byte(`{"readers": {"alice": {"c1": "2025-01-15T10:00:00Z"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	seen, err := LoadSeen(mdPath)
+	if err != nil || seen.LastSeen("alice", "c1").IsZero() {
+		t.Fatalf("Expected the old state, got %v (%v)", seen, err)
+	}
+	if err := SaveSeen(mdPath, seen); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(legacy) {
+		t.Error("Expected the state next to the sidecar to be removed")
+	}
+	if seen, err := LoadSeen(mdPath); err != nil || seen.LastSeen("alice", "c1").IsZero() {
+		t.Errorf("Expected the state in the user's directory, got %v (%v)", seen, err)
+	}
+}
@@ -0,0 +1,43 @@
+package comment
+
+import "sync/atomic"
+
+// Settings are what a project decides for the documents it holds
+type Settings struct {
+	// Save is how their sidecars are written and loaded
+	Save SaveOptions
+
+	// Location is where their sidecars are kept
+	Location SidecarLocation
+}
+
+// SettingsFunc returns the settings for documents in dir, e.g. from the
+// project config that applies there
+type SettingsFunc func(dir string) Settings
+
+// settingsFunc decides each document's settings; nil gives every document
+// the zero Settings
+var settingsFunc atomic.Pointer[SettingsFunc]
+
+// SetSettingsFunc makes f decide the settings of each document by its
+// directory, so one process can work on documents of several projects at
+// once, and returns a function that restores the previous one. A location
+// set with SetSidecarLocation takes precedence over f's. Documents keep the
+// save options they were loaded with.
+func SetSettingsFunc(f SettingsFunc) (restore func()) {
+	var next *SettingsFunc
+	if f != nil {
+		next = &f
+	}
+	previous := settingsFunc.Swap(next)
+	return func() { settingsFunc.Store(previous) }
+}
+
+// settingsFor returns the settings for documents in dir
+func settingsFor(dir string) Settings {
+	f := settingsFunc.Load()
+	if f == nil {
+		return Settings{}
+	}
+	return (*f)(dir)
+}
@@ -0,0 +1,44 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSettingsFuncSaveOptionsPerDirectory(t *testing.T) {
+	root := t.TempDir()
+	stable := filepath.Join(root, "stable")
+	if err := os.Mkdir(stable, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer SetSettingsFunc(func(dir string) Settings {
+		return Settings{Save: SaveOptions{StableLastValidated: dir == stable}}
+	})()
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	paths := []string{filepath.Join(stable, "a.md"), filepath.Join(root, "b.md")}
+	restore := SetClock(FixedClock(start))
+	for _, path := range paths {
+		if err := SaveToSidecar(path, &DocumentWithComments{Content: "Line one\n"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	restore()
+
+	// Saving again later moves lastValidated only where it isn't kept stable
+	defer SetClock(FixedClock(start.Add(time.Hour)))()
+	for i, path := range paths {
+		doc, err := LoadFromSidecar(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveToSidecar(path, doc); err != nil {
+			t.Fatal(err)
+		}
+		if moved := !doc.LastValidated.Equal(start); moved != (i == 1) {
+			t.Errorf("%s: lastValidated %v", path, doc.LastValidated)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package comment
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SharedDocument holds a document that several goroutines read and change at
+// once, as watch and server modes do. Readers get immutable snapshots without
+// locking; writers change a private copy that then replaces the current
+// snapshot (copy on write), and subscribers are told about each change.
+type SharedDocument struct {
+	file    string
+	current atomic.Pointer[sharedSnapshot]
+
+	mu          sync.Mutex // Serializes writers and guards subscribers
+	subscribers map[int]*subscriber
+	nextID      int
+}
+
+// sharedSnapshot is one published version of a shared document
+type sharedSnapshot struct {
+	doc     *DocumentWithComments
+	version uint64
+}
+
+// subscriber is a listener registered with Subscribe
+type subscriber struct {
+	ch   chan Change
+	last *DocumentWithComments // Snapshot of the last change delivered
+}
+
+// Change is delivered to subscribers after a shared document changes
+type Change struct {
+	Version uint64
+	Doc     *DocumentWithComments // The document after the change; read-only
+	Events  []Event               // Comment changes since this subscriber's previous Change
+}
+
+// NewSharedDocument shares a copy of doc; file is the markdown path used to
+// save and reload it and to label events
+func NewSharedDocument(file string, doc *DocumentWithComments) *SharedDocument {
+	s := &SharedDocument{file: file, subscribers: map[int]*subscriber{}}
+	s.current.Store(&sharedSnapshot{doc: doc.Clone(), version: 1})
+	return s
+}
+
+// LoadSharedDocument loads a markdown file and its sidecar for sharing
+func LoadSharedDocument(file string) (*SharedDocument, error) {
+	doc, err := LoadFromSidecar(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewSharedDocument(file, doc), nil
+}
+
+// Snapshot returns the current document and its version. The snapshot never
+// changes, even as the shared document does; callers must not modify it
+// (Clone it first).
+func (s *SharedDocument) Snapshot() (*DocumentWithComments, uint64) {
+	snap := s.current.Load()
+	return snap.doc, snap.version
+}
+
+// Update applies fn to a copy of the current document and, if fn succeeds,
+// publishes the copy as the new snapshot and notifies subscribers. Updates
+// run one at a time; readers keep seeing the previous snapshot until fn
+// returns. If fn fails nothing changes. Returns the resulting version.
+func (s *SharedDocument) Update(fn func(doc *DocumentWithComments) error) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.current.Load()
+	doc := current.doc.Clone()
+	if err := fn(doc); err != nil {
+		return current.version, err
+	}
+	return s.publish(doc), nil
+}
+
+// Save writes the current document to its markdown file and sidecar
+func (s *SharedDocument) Save() error {
+	_, err := s.Update(func(doc *DocumentWithComments) error {
+		return SaveToSidecar(s.file, doc)
+	})
+	return err
+}
+
+// Reload replaces the document with what is on disk, e.g. after another
+// process changed the sidecar
+func (s *SharedDocument) Reload() (uint64, error) {
+	doc, err := LoadFromSidecar(s.file)
+	if err != nil {
+		_, version := s.Snapshot()
+		return version, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.publish(doc), nil
+}
+
+// publish makes doc the current snapshot and notifies subscribers
+// Callers hold s.mu.
+func (s *SharedDocument) publish(doc *DocumentWithComments) uint64 {
+	version := s.current.Load().version + 1
+	s.current.Store(&sharedSnapshot{doc: doc, version: version})
+
+	for _, sub := range s.subscribers {
+		change := Change{
+			Version: version,
+			Doc:     doc,
+			Events:  DiffThreads(s.file, sub.last.Threads, doc.Threads, now()),
+		}
+		// Never block a writer on a slow subscriber: if its buffer is full the
+		// change is skipped, and its next Change covers both
+		select {
+		case sub.ch <- change:
+			sub.last = doc
+		default:
+		}
+	}
+	return version
+}
+
+// Subscribe registers for a Change after every update. buffer is how many
+// changes may queue before they are coalesced into the next one. Call
+// cancel to stop; it closes the channel.
+func (s *SharedDocument) Subscribe(buffer int) (changes <-chan Change, cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	sub := &subscriber{ch: make(chan Change, max(buffer, 1)), last: s.current.Load().doc}
+	s.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(sub.ch)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package comment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSharedDocumentConcurrentUpdates(t *testing.T) {
+	shared := NewSharedDocument("doc.md", &DocumentWithComments{Content: "one\ntwo\n", Threads: []*Comment{}})
+	before, version := shared.Snapshot()
+
+	changes, cancel := shared.Subscribe(100)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shared.Update(func(doc *DocumentWithComments) error {
+				c := NewComment("bot", 1, fmt.Sprintf("note %d", i))
+				c.ID = fmt.Sprintf("c%d", i)
+				doc.Threads = append(doc.Threads, c)
+				return nil
+			})
+			// Readers run alongside writers
+			doc, _ := shared.Snapshot()
+			_ = len(doc.Threads)
+		}(i)
+	}
+	wg.Wait()
+
+	after, latest := shared.Snapshot()
+	if len(after.Threads) != 20 || latest != version+20 {
+		t.Errorf("Expected 20 threads at version %d, got %d at %d", version+20, len(after.Threads), latest)
+	}
+	if len(before.Threads) != 0 {
+		t.Error("An earlier snapshot changed after updates")
+	}
+
+	events := 0
+	for i := 0; i < 20; i++ {
+		change := <-changes
+		events += len(change.Events)
+	}
+	if events != 20 {
+		t.Errorf("Expected 20 comment_added events, got %d", events)
+	}
+}
+
+func TestSharedDocumentFailedUpdate(t *testing.T) {
+	shared := NewSharedDocument("doc.md", &DocumentWithComments{Content: "one\n", Threads: []*Comment{}})
+	_, version := shared.Snapshot()
+
+	boom := errors.New("boom")
+	got, err := shared.Update(func(doc *DocumentWithComments) error {
+		doc.Threads = append(doc.Threads, NewComment("bot", 1, "half done"))
+		return boom
+	})
+	if !errors.Is(err, boom) || got != version {
+		t.Errorf("Expected the error and version %d, got %v, %d", version, err, got)
+	}
+	if doc, _ := shared.Snapshot(); len(doc.Threads) != 0 {
+		t.Error("A failed update changed the document")
+	}
+}
+
+func TestSharedDocumentCoalescesSlowSubscribers(t *testing.T) {
+	shared := NewSharedDocument("doc.md", &DocumentWithComments{Content: "one\n", Threads: []*Comment{}})
+	changes, cancel := shared.Subscribe(1)
+
+	for i := 0; i < 3; i++ {
+		shared.Update(func(doc *DocumentWithComments) error {
+			c := NewComment("bot", 1, "note")
+			c.ID = fmt.Sprintf("c%d", i)
+			doc.Threads = append(doc.Threads, c)
+			return nil
+		})
+	}
+
+	// The first change filled the buffer; the next one covers the other two
+	first := <-changes
+	if len(first.Events) != 1 {
+		t.Errorf("Expected 1 event in the first change, got %d", len(first.Events))
+	}
+	shared.Update(func(doc *DocumentWithComments) error { return nil })
+	second := <-changes
+	if len(second.Events) != 2 || len(second.Doc.Threads) != 3 {
+		t.Errorf("Expected the skipped changes to be coalesced, got %d events", len(second.Events))
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-changes; ok {
+		t.Error("Expected cancel to close the channel")
+	}
+}
+
+func TestSharedDocumentSaveAndReload(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(mdPath, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shared, err := LoadSharedDocument(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared.Update(func(doc *DocumentWithComments) error {
+		doc.Threads = append(doc.Threads, NewComment("alice", 1, "saved"))
+		return nil
+	})
+	if err := shared.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process adds a comment
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Threads = append(doc.Threads, NewComment("bob", 1, "external"))
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, cancel := shared.Subscribe(1)
+	defer cancel()
+	if _, err := shared.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	change := <-changes
+	if len(change.Doc.Threads) != 2 || len(change.Events) != 1 || change.Events[0].Author != "bob" {
+		t.Errorf("Expected the reload to report bob's comment, got %+v", change.Events)
+	}
+}
@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShiftLines moves every comment below line afterLine by `by` lines, for
+// when lines were added (by > 0) or removed (by < 0) right after afterLine
+// outside the tool, e.g. a block pasted at the top of the document. Ranges
+// spanning afterLine grow or shrink with it. The shift is checked against
+// the current content first: nothing may end up past the last line, and
+// removed lines must not hold comments (reattach those first). Returns the
+// number of threads moved.
+func (d *DocumentWithComments) ShiftLines(afterLine, by int) (int, error) {
+	if by == 0 {
+		return 0, fmt.Errorf("nothing to shift: the offset is 0")
+	}
+	lineCount := len(strings.Split(d.Content, "\n"))
+	if afterLine < 0 || afterLine > lineCount {
+		return 0, fmt.Errorf("line %d out of range (document has %d lines)", afterLine, lineCount)
+	}
+
+	removedEnd := afterLine - by // Last removed line, when by < 0
+	moved := []*Comment{}
+	for _, thread := range d.Threads {
+		if thread.IsDocumentLevel() {
+			continue
+		}
+		first, last := thread.LineRange()
+		if thread.IsSuggestion && !thread.IsPending() {
+			// Decided suggestions keep their range as history; only Line moves
+			first, last = thread.Line, thread.Line
+		}
+		if last <= afterLine {
+			continue
+		}
+		if by < 0 && first <= removedEnd && last > afterLine {
+			return 0, fmt.Errorf("thread %s is on line(s) %d-%d, which would be removed; reattach it first", thread.ID, first, last)
+		}
+		if last+by > lineCount {
+			return 0, fmt.Errorf("thread %s would move to line %d, past the end of the document (%d lines)", thread.ID, last+by, lineCount)
+		}
+		moved = append(moved, thread)
+	}
+
+	if by > 0 {
+		// by lines inserted after afterLine
+		RecalculateCommentLines(d.Threads, afterLine+1, afterLine, by)
+	} else {
+		// Lines afterLine+1 to removedEnd deleted
+		RecalculateCommentLines(d.Threads, afterLine+1, removedEnd, 0)
+	}
+	RecomputeAllSections(d)
+	log().Info("shifted comments", "after", afterLine, "by", by, "threads", len(moved))
+	return len(moved), nil
+}
@@ -0,0 +1,63 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShiftLines(t *testing.T) {
+	content := strings.Repeat("line\n", 39) + "line"
+	top := NewComment("alice", 1, "Above the insertion")
+	below := NewComment("bob", 5, "Below the insertion")
+	reply := NewReply("alice", "Reply", below)
+	below.Replies = []*Comment{reply}
+	span := NewComment("carol", 1, "Spans the insertion")
+	span.EndLine = 3
+	suggestion := NewSuggestion("claude", 4, 6, "Reword", "line\nline\nline", "better")
+	whole := NewComment("dave", DocumentLine, "About the document")
+	doc := &DocumentWithComments{Content: content, Threads: []*Comment{top, below, span, suggestion, whole}}
+
+	moved, err := doc.ShiftLines(2, 25)
+	if err != nil {
+		t.Fatalf("ShiftLines failed: %v", err)
+	}
+	if moved != 3 {
+		t.Errorf("Expected 3 threads moved, got %d", moved)
+	}
+	if top.Line != 1 || below.Line != 30 || reply.Line != 30 || whole.Line != DocumentLine {
+		t.Errorf("Unexpected lines: top %d, below %d, reply %d, document %d", top.Line, below.Line, reply.Line, whole.Line)
+	}
+	if span.Line != 1 || span.EndLine != 28 {
+		t.Errorf("Expected the range to grow to 1-28, got %d-%d", span.Line, span.EndLine)
+	}
+	if suggestion.StartLine != 29 || suggestion.EndLine != 31 {
+		t.Errorf("Expected the suggestion at 29-31, got %d-%d", suggestion.StartLine, suggestion.EndLine)
+	}
+
+	// Past the end of the document
+	if _, err := doc.ShiftLines(0, 20); err == nil {
+		t.Error("Expected a shift past the last line to fail")
+	}
+	// Removing lines that hold comments
+	if _, err := doc.ShiftLines(25, -10); err == nil {
+		t.Error("Expected removing commented lines to fail")
+	}
+	if _, err := doc.ShiftLines(2, 0); err == nil {
+		t.Error("Expected a zero offset to fail")
+	}
+	if below.Line != 30 {
+		t.Errorf("A failed shift must change nothing, got line %d", below.Line)
+	}
+
+	// Shifting back undoes it
+	if _, err := doc.ShiftLines(2, -25); err == nil {
+		t.Error("Expected the range spanning the removed lines to block the shift")
+	}
+	span.EndLine = 0
+	if _, err := doc.ShiftLines(2, -25); err != nil {
+		t.Fatalf("ShiftLines failed: %v", err)
+	}
+	if below.Line != 5 || suggestion.StartLine != 4 || suggestion.EndLine != 6 {
+		t.Errorf("Expected the original lines back, got %d and %d-%d", below.Line, suggestion.StartLine, suggestion.EndLine)
+	}
+}
@@ -0,0 +1,124 @@
+package comment
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Signature verification results
+const (
+	SignatureValid     = "valid"     // Signature matches and the key is trusted (or no trust list applies)
+	SignatureUnsigned  = "unsigned"  // Comment carries no signature
+	SignatureInvalid   = "invalid"   // Signature does not match the content (tampered)
+	SignatureUntrusted = "untrusted" // Signature is intact but the key is not trusted for the author
+)
+
+// SignatureReport is the verification result for a single comment
+type SignatureReport struct {
+	Comment *Comment
+	Status  string // One of the Signature* constants
+	Detail  string // Human-readable explanation for non-valid results
+}
+
+// signedFields are the immutable parts of a comment covered by its signature
+// Positions, status, and resolution are excluded because they change legitimately
+// as the document and review progress.
+type signedFields struct {
+	ID           string `json:"id"`
+	Author       string `json:"author"`
+	Timestamp    string `json:"timestamp"`
+	Text         string `json:"text"`
+	Type         string `json:"type"`
+	IsSuggestion bool   `json:"is_suggestion"`
+	IsMove       bool   `json:"is_move,omitempty"` // Omitted when false, so older signatures still verify
+	OriginalText string `json:"original_text"`
+	ProposedText string `json:"proposed_text"`
+}
+
+// SignaturePayload returns the canonical bytes covered by a comment's signature
+func SignaturePayload(c *Comment) []byte {
+	payload, _ := json.Marshal(signedFields{
+		ID:           c.ID,
+		Author:       c.Author,
+		Timestamp:    c.Timestamp.UTC().Format(time.RFC3339Nano),
+		Text:         c.Text,
+		Type:         c.Type,
+		IsSuggestion: c.IsSuggestion,
+		IsMove:       c.IsMove,
+		OriginalText: c.OriginalText,
+		ProposedText: c.ProposedText,
+	})
+	return payload
+}
+
+// SignComment signs a comment with the given private key
+func SignComment(c *Comment, key ed25519.PrivateKey) {
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, SignaturePayload(c)))
+	c.SignerKey = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// VerifyComment checks a comment's signature against its embedded public key
+// trusted maps author names to the public keys (base64) allowed to sign for them;
+// authors without an entry accept any intact signature.
+func VerifyComment(c *Comment, trusted map[string][]string) SignatureReport {
+	report := SignatureReport{Comment: c, Status: SignatureValid}
+
+	if c.Signature == "" {
+		report.Status = SignatureUnsigned
+		report.Detail = "comment is not signed"
+		return report
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(c.SignerKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		report.Status = SignatureInvalid
+		report.Detail = "signer key is malformed"
+		return report
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(pub), SignaturePayload(c), sig) {
+		report.Status = SignatureInvalid
+		report.Detail = "signature does not match comment content"
+		return report
+	}
+
+	if keys, ok := trusted[c.Author]; ok {
+		for _, key := range keys {
+			if key == c.SignerKey {
+				return report
+			}
+		}
+		report.Status = SignatureUntrusted
+		report.Detail = fmt.Sprintf("signed with a key not trusted for @%s", c.Author)
+	}
+
+	return report
+}
+
+// VerifySignatures verifies every comment (roots and replies) in threads
+func VerifySignatures(threads []*Comment, trusted map[string][]string) []SignatureReport {
+	reports := []SignatureReport{}
+	for _, thread := range threads {
+		reports = append(reports, VerifyComment(thread, trusted))
+		for _, reply := range flattenReplies(thread.Replies) {
+			reports = append(reports, VerifyComment(reply, trusted))
+		}
+	}
+	return reports
+}
+
+// CountInvalidSignatures returns how many signed comments fail verification
+// Unsigned comments are not counted.
+func CountInvalidSignatures(threads []*Comment) int {
+	count := 0
+	for _, report := range VerifySignatures(threads, nil) {
+		if report.Status == SignatureInvalid {
+			count++
+		}
+	}
+	return count
+}
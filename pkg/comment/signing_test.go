@@ -0,0 +1,78 @@
+package comment
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return key
+}
+
+func TestSignAndVerifyComment(t *testing.T) {
+	key := newTestKey(t)
+	c := &Comment{ID: "c1", Author: "alice", Timestamp: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), Text: "Looks good", Line: 4}
+	SignComment(c, key)
+
+	if report := VerifyComment(c, nil); report.Status != SignatureValid {
+		t.Fatalf("Expected valid signature, got %s (%s)", report.Status, report.Detail)
+	}
+
+	// Mutable fields are not covered by the signature
+	c.Line = 10
+	c.Resolved = true
+	if report := VerifyComment(c, nil); report.Status != SignatureValid {
+		t.Errorf("Position/state changes should not invalidate signature, got %s", report.Status)
+	}
+
+	// Editing the text is detected
+	c.Text = "Looks bad"
+	if report := VerifyComment(c, nil); report.Status != SignatureInvalid {
+		t.Errorf("Expected invalid signature after tampering, got %s", report.Status)
+	}
+}
+
+func TestVerifyCommentTrust(t *testing.T) {
+	key := newTestKey(t)
+	c := &Comment{ID: "c1", Author: "alice", Timestamp: time.Now(), Text: "hi"}
+	SignComment(c, key)
+
+	trusted := map[string][]string{"alice": {c.SignerKey}}
+	if report := VerifyComment(c, trusted); report.Status != SignatureValid {
+		t.Errorf("Expected valid for trusted key, got %s", report.Status)
+	}
+
+	other := newTestKey(t)
+	trusted["alice"] = []string{base64.StdEncoding.EncodeToString(other.Public().(ed25519.PublicKey))}
+	if report := VerifyComment(c, trusted); report.Status != SignatureUntrusted {
+		t.Errorf("Expected untrusted for unknown key, got %s", report.Status)
+	}
+}
+
+func TestVerifySignaturesIncludesReplies(t *testing.T) {
+	key := newTestKey(t)
+	reply := &Comment{ID: "c2", Author: "bob", Timestamp: time.Now(), Text: "reply"}
+	thread := &Comment{ID: "c1", Author: "alice", Timestamp: time.Now(), Text: "root", Replies: []*Comment{reply}}
+	SignComment(thread, key)
+
+	reports := VerifySignatures([]*Comment{thread}, nil)
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Status != SignatureValid || reports[1].Status != SignatureUnsigned {
+		t.Errorf("Unexpected statuses: %s, %s", reports[0].Status, reports[1].Status)
+	}
+
+	reply.Signature = "bogus"
+	reply.SignerKey = thread.SignerKey
+	if got := CountInvalidSignatures([]*Comment{thread}); got != 1 {
+		t.Errorf("CountInvalidSignatures = %d, want 1", got)
+	}
+}
@@ -0,0 +1,188 @@
+package comment
+
+// Stats summarizes the comment activity of one or more documents
+type Stats struct {
+	Files    int            `json:"files"`
+	Threads  int            `json:"threads"`
+	Replies  int            `json:"replies"`
+	Open     int            `json:"open"`
+	Resolved int            `json:"resolved"`
+	ByStatus map[string]int `json:"by_status"`
+	ByType   map[string]int `json:"by_type"`
+	ByAuthor map[string]int `json:"by_author"`
+	Pending  int            `json:"pending_suggestions"`
+	Accepted int            `json:"accepted_suggestions"`
+	Rejected int            `json:"rejected_suggestions"`
+
+	// Suggestion outcomes over every author (the same counts as Pending,
+	// Accepted, and Rejected, grouped)
+	Suggestions SuggestionOutcomes `json:"suggestions"`
+
+	Reviews  int            `json:"reviews"`
+	Verdicts map[string]int `json:"by_verdict"` // Reviews by verdict; open reviews count as "in-progress"
+
+	// Suggestion outcomes by the author of the suggestion
+	Outcomes map[string]*SuggestionOutcomes `json:"suggestions_by_author"`
+
+	// Pending suggestions waiting on each section owner; ComputeStats
+	// doesn't know the owners, so callers add these with AddAwaitingOwners
+	AwaitingOwners map[string]int `json:"awaiting_owners"`
+
+	// [Q] threads by state (open, answered, resolved); who can answer
+	// depends on the owners, so callers add these with AddQuestions
+	Questions map[string]int `json:"questions"`
+}
+
+// SuggestionOutcomes counts what became of one author's suggestions
+type SuggestionOutcomes struct {
+	Pending  int `json:"pending"`
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+// AcceptanceRate returns the share of decided suggestions that were accepted
+// (0 if none have been decided)
+func (o *SuggestionOutcomes) AcceptanceRate() float64 {
+	decided := o.Accepted + o.Rejected
+	if decided == 0 {
+		return 0
+	}
+	return float64(o.Accepted) / float64(decided)
+}
+
+// outcomes returns the counts for author, creating them on first use
+func (s *Stats) outcomes(author string) *SuggestionOutcomes {
+	o, ok := s.Outcomes[author]
+	if !ok {
+		o = &SuggestionOutcomes{}
+		s.Outcomes[author] = o
+	}
+	return o
+}
+
+// NewStats returns an empty Stats ready for accumulation
+func NewStats() *Stats {
+	return &Stats{
+		ByStatus: map[string]int{},
+		ByType:   map[string]int{},
+		ByAuthor: map[string]int{},
+		Verdicts: map[string]int{},
+		Outcomes: map[string]*SuggestionOutcomes{},
+
+		AwaitingOwners: map[string]int{},
+		Questions:      map[string]int{},
+	}
+}
+
+// AddQuestions counts the document's [Q] threads by state; isOwner tells
+// who can answer them (see QuestionState)
+func (s *Stats) AddQuestions(doc *DocumentWithComments, isOwner func(author string) bool) {
+	for state, n := range CountQuestions(doc.Threads, isOwner) {
+		s.Questions[state] += n
+	}
+}
+
+// AddAwaitingOwners counts each suggestion waiting on section owners once
+// for every owner it waits on
+func (s *Stats) AddAwaitingOwners(awaiting []AwaitingOwner) {
+	for _, a := range awaiting {
+		for _, owner := range a.Owners {
+			s.AwaitingOwners[owner]++
+		}
+	}
+}
+
+// ComputeStats summarizes the threads of a single document
+// Authors are counted for every comment, replies included.
+func ComputeStats(doc *DocumentWithComments) *Stats {
+	s := NewStats()
+	s.Files = 1
+
+	for _, thread := range doc.Threads {
+		s.Threads++
+		s.Replies += thread.CountReplies()
+		s.ByStatus[thread.GetStatus()]++
+
+		if thread.Resolved || thread.GetStatus() == "resolved" {
+			s.Resolved++
+		} else {
+			s.Open++
+		}
+
+		commentType := thread.Type
+		if commentType == "" {
+			commentType = "none"
+		}
+		s.ByType[commentType]++
+
+		switch {
+		case thread.IsPending():
+			s.Pending++
+			s.Suggestions.Pending++
+			s.outcomes(thread.Author).Pending++
+		case thread.IsAccepted():
+			s.Accepted++
+			s.Suggestions.Accepted++
+			s.outcomes(thread.Author).Accepted++
+		case thread.IsRejected():
+			s.Rejected++
+			s.Suggestions.Rejected++
+			s.outcomes(thread.Author).Rejected++
+		}
+	}
+
+	for _, c := range doc.GetAllComments() {
+		s.ByAuthor[c.Author]++
+	}
+
+	for _, r := range doc.Reviews {
+		s.Reviews++
+		if r.IsOpen() {
+			s.Verdicts[VerdictInProgress]++
+		} else {
+			s.Verdicts[r.Verdict]++
+		}
+	}
+
+	return s
+}
+
+// Merge adds the counts from other into s
+func (s *Stats) Merge(other *Stats) {
+	s.Files += other.Files
+	s.Threads += other.Threads
+	s.Replies += other.Replies
+	s.Open += other.Open
+	s.Resolved += other.Resolved
+	s.Pending += other.Pending
+	s.Accepted += other.Accepted
+	s.Rejected += other.Rejected
+	s.Suggestions.Pending += other.Suggestions.Pending
+	s.Suggestions.Accepted += other.Suggestions.Accepted
+	s.Suggestions.Rejected += other.Suggestions.Rejected
+	for k, v := range other.ByStatus {
+		s.ByStatus[k] += v
+	}
+	for k, v := range other.ByType {
+		s.ByType[k] += v
+	}
+	for k, v := range other.ByAuthor {
+		s.ByAuthor[k] += v
+	}
+	s.Reviews += other.Reviews
+	for k, v := range other.Verdicts {
+		s.Verdicts[k] += v
+	}
+	for author, o := range other.Outcomes {
+		mine := s.outcomes(author)
+		mine.Pending += o.Pending
+		mine.Accepted += o.Accepted
+		mine.Rejected += o.Rejected
+	}
+	for owner, n := range other.AwaitingOwners {
+		s.AwaitingOwners[owner] += n
+	}
+	for state, n := range other.Questions {
+		s.Questions[state] += n
+	}
+}
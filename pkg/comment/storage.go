@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -14,15 +15,60 @@ const StorageVersion = "2.0"
 
 // StorageFormat represents the JSON sidecar file structure (v2.0)
 type StorageFormat struct {
-	Version       string     `json:"version"`        // Format version ("2.0")
-	DocumentHash  string     `json:"documentHash"`   // SHA-256 hash for staleness detection
-	LastValidated time.Time  `json:"lastValidated"`  // Last validation timestamp
-	Threads       []*Comment `json:"threads"`        // Root comment threads with nested replies
+	Version       string            `json:"version"`               // Format version ("2.0")
+	DocumentHash  string            `json:"documentHash"`          // SHA-256 hash for staleness detection
+	LastValidated time.Time         `json:"lastValidated"`         // Last validation timestamp
+	Threads       []*Comment        `json:"threads"`               // Root comment threads with nested replies
+	Reviews       []*Review         `json:"reviews,omitempty"`     // Review sessions grouping comments
+	AppliedKeys   map[string]string `json:"appliedKeys,omitempty"` // Batch idempotency key -> comment ID
+	Revisions     []*Revision       `json:"revisions,omitempty"`   // Changes made by accepting suggestions
 }
 
-// GetSidecarPath returns the sidecar JSON path for a given markdown file
-func GetSidecarPath(mdPath string) string {
-	return mdPath + ".comments.json"
+// SaveOptions controls how sidecars are written and loaded
+type SaveOptions struct {
+	// StableLastValidated moves lastValidated only when the document content
+	// changes, so saves that touch nothing but comments don't churn it in diffs
+	StableLastValidated bool
+
+	// Drift gives comments past the end of a document that got shorter a
+	// grace period before validation orphans them (see drift.go)
+	Drift DriftPolicy
+
+	// AutoRestore lets LoadFromSidecar put back the newest backup written
+	// against the document's current content when its sidecar is missing;
+	// otherwise it only points the backup out
+	AutoRestore bool
+}
+
+// saveOptionsFor returns the options the SettingsFunc gives a markdown file
+func saveOptionsFor(mdPath string) SaveOptions {
+	return settingsFor(filepath.Dir(mdPath)).Save
+}
+
+// saveOptions returns the options doc was loaded with. A document built in
+// memory takes those of the file it is first saved to.
+func (d *DocumentWithComments) saveOptions(mdPath string) SaveOptions {
+	if d.options == nil {
+		opts := saveOptionsFor(mdPath)
+		d.options = &opts
+	}
+	return *d.options
+}
+
+// CanonicalOrder returns the threads sorted by first line, then ID, so the
+// sidecar's order doesn't depend on when each thread was added. Document-level
+// threads (line 0) come first. The input slice is not modified.
+func CanonicalOrder(threads []*Comment) []*Comment {
+	sorted := append([]*Comment{}, threads...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := sorted[i].LineRange()
+		b, _ := sorted[j].LineRange()
+		if a != b {
+			return a < b
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
 }
 
 // ComputeDocumentHash computes SHA-256 hash of markdown content
@@ -35,12 +81,15 @@ func ComputeDocumentHash(content string) string {
 // Returns DocumentWithComments with the markdown content and parsed threads
 func LoadFromSidecar(mdPath string) (*DocumentWithComments, error) {
 	// Read markdown content
-	contentBytes, err := os.ReadFile(mdPath)
+	content, format, model, err := readDocument(mdPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read markdown file: %w", err)
+		return nil, err
+	}
+	var embeddedStore []byte
+	var embedded bool
+	if isPlainText(model) {
+		content, embeddedStore, embedded = splitEmbedded(content)
 	}
-
-	content := string(contentBytes)
 	contentHash := ComputeDocumentHash(content)
 
 	// Initialize empty document
@@ -48,22 +97,40 @@ func LoadFromSidecar(mdPath string) (*DocumentWithComments, error) {
 		Content:       content,
 		Threads:       []*Comment{},
 		DocumentHash:  contentHash,
-		LastValidated: time.Now(),
+		LastValidated: now(),
+		Format:        format,
+		Embedded:      embedded,
+		Model:         model,
+		savedContent:  content,
 	}
+	opts := doc.saveOptions(mdPath)
 
-	// Read sidecar JSON file
+	// Read sidecar JSON file, or the store embedded in the markdown
 	sidecarPath := GetSidecarPath(mdPath)
-
-	// Check if sidecar exists
-	if _, err := os.Stat(sidecarPath); os.IsNotExist(err) {
-		// No sidecar file exists - return empty document
-		return doc, nil
-	}
-
-	// Read sidecar file
-	sidecarBytes, err := os.ReadFile(sidecarPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read sidecar file: %w", err)
+	sidecarBytes := embeddedStore
+	if embedded {
+		sidecarPath = mdPath
+	} else {
+		// Check if sidecar exists
+		if _, err := os.Stat(sidecarPath); os.IsNotExist(err) {
+			// A backup of this very content may still hold its comments
+			restored, err := recoverSidecar(mdPath, contentHash, opts.AutoRestore)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to restore backup: %v\n", err)
+			}
+			if restored == nil {
+				// No sidecar file exists - return empty document
+				log().Debug("loaded document without sidecar", "file", mdPath, "bytes", len(content))
+				return doc, nil
+			}
+			sidecarBytes = restored
+		} else {
+			// Read sidecar file
+			sidecarBytes, err = os.ReadFile(sidecarPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read sidecar file: %w", err)
+			}
+		}
 	}
 
 	// Parse JSON
@@ -79,14 +146,29 @@ func LoadFromSidecar(mdPath string) (*DocumentWithComments, error) {
 
 	// Populate document with loaded data
 	doc.Threads = storage.Threads
+	doc.Reviews = storage.Reviews
+	doc.AppliedKeys = storage.AppliedKeys
+	doc.Revisions = storage.Revisions
 	doc.DocumentHash = storage.DocumentHash
 	doc.LastValidated = storage.LastValidated
 
 	// Migrate old format comments to new format (adds default values for Status, Priority, etc.)
 	doc.MigrateDocument()
+	doc.NormalizeTimestamps()
+	log().Debug("loaded sidecar", "file", mdPath, "sidecar", sidecarPath, "threads", len(doc.Threads),
+		"comments", len(doc.GetAllComments()), "stale", storage.DocumentHash != contentHash)
+
+	// Flag signed comments whose content no longer matches their signature
+	if invalid := CountInvalidSignatures(doc.Threads); invalid > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d comment(s) have invalid signatures; run 'comments verify' for details\n", invalid)
+	}
 
 	// Validate comments and mark orphaned ones (granular validation)
 	orphanedCount, issues := ValidateAndUpdateCommentStatus(doc)
+	log().Debug("validated comments", "file", mdPath, "orphaned", orphanedCount, "issues", len(issues))
+	for _, issue := range issues {
+		log().Debug("validation issue", "file", mdPath, "comment", issue.CommentID, "severity", issue.Severity, "message", issue.Message)
+	}
 
 	// Report validation results to user
 	if orphanedCount > 0 {
@@ -98,17 +180,22 @@ func LoadFromSidecar(mdPath string) (*DocumentWithComments, error) {
 		for _, issue := range issues {
 			if issue.Severity == "info" {
 				fmt.Fprintf(os.Stderr, "Info: %s\n", issue.Message)
+			} else if issue.CommentID != "" {
+				// Drifted comments
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", issue.Message)
 			}
 		}
 	}
 
 	// Update hash and timestamp to current values
+	if doc.DocumentHash != contentHash || !opts.StableLastValidated {
+		doc.LastValidated = now()
+	}
 	doc.DocumentHash = contentHash
-	doc.LastValidated = time.Now()
 
-	// Save the updated sidecar with new statuses
+	// Save the updated sidecar with new statuses (the markdown is unchanged)
 	if orphanedCount > 0 || len(issues) > 0 {
-		if err := SaveToSidecar(mdPath, doc); err != nil {
+		if err := SaveSidecar(mdPath, doc); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to save updated sidecar: %v\n", err)
 		}
 	}
@@ -116,39 +203,233 @@ func LoadFromSidecar(mdPath string) (*DocumentWithComments, error) {
 	return doc, nil
 }
 
+// ReadSidecar loads a document and its threads without validating or rewriting anything
+// Intended for read-only bulk operations (search, reporting) where LoadFromSidecar's
+// status updates and warnings are unwanted.
+func ReadSidecar(mdPath string) (*DocumentWithComments, error) {
+	content, format, model, err := readDocument(mdPath)
+	if err != nil {
+		return nil, err
+	}
+	var sidecarBytes []byte
+	var embedded bool
+	if isPlainText(model) {
+		content, sidecarBytes, embedded = splitEmbedded(content)
+	}
+
+	doc := &DocumentWithComments{
+		Content:      content,
+		Threads:      []*Comment{},
+		Format:       format,
+		Embedded:     embedded,
+		Model:        model,
+		savedContent: content,
+	}
+
+	if !embedded {
+		sidecarBytes, err = os.ReadFile(GetSidecarPath(mdPath))
+		if os.IsNotExist(err) {
+			return doc, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sidecar file: %w", err)
+		}
+	}
+
+	var storage StorageFormat
+	if err := json.Unmarshal(sidecarBytes, &storage); err != nil {
+		return nil, fmt.Errorf("failed to parse sidecar JSON: %w", err)
+	}
+	if storage.Version != StorageVersion {
+		return nil, fmt.Errorf("unsupported storage version: %s (expected %s)", storage.Version, StorageVersion)
+	}
+
+	if storage.Threads != nil {
+		doc.Threads = storage.Threads
+	}
+	doc.Reviews = storage.Reviews
+	doc.AppliedKeys = storage.AppliedKeys
+	doc.Revisions = storage.Revisions
+	doc.DocumentHash = storage.DocumentHash
+	doc.LastValidated = storage.LastValidated
+	doc.MigrateDocument()
+	doc.NormalizeTimestamps()
+	log().Debug("read sidecar", "file", mdPath, "threads", len(doc.Threads))
+
+	return doc, nil
+}
+
 // SaveToSidecar saves comment threads to the sidecar JSON file (v2.0)
-// Also writes the clean markdown content (without comment markup)
+// The markdown file is rewritten only if the content changed since it was
+// loaded (e.g., a suggestion was applied) or the file doesn't exist yet, so
+// comment-only changes never touch it.
 func SaveToSidecar(mdPath string, doc *DocumentWithComments) error {
-	// Write markdown content
-	if err := os.WriteFile(mdPath, []byte(doc.Content), 0644); err != nil {
+	if doc.Embedded {
+		return saveEmbedded(mdPath, doc)
+	}
+	if doc.ContentChanged() || !fileExists(mdPath) {
+		if err := SaveMarkdown(mdPath, doc); err != nil {
+			return err
+		}
+	}
+	return SaveSidecar(mdPath, doc)
+}
+
+// ContentChanged reports whether Content differs from the markdown last read
+// from or written to disk
+func (d *DocumentWithComments) ContentChanged() bool {
+	return d.Content != d.savedContent
+}
+
+// SaveMarkdown writes the document content to the markdown file, in the
+// encoding, line endings, and final-newline style it was read with. For
+// notebooks, the edited cells are merged back into the file.
+func SaveMarkdown(mdPath string, doc *DocumentWithComments) error {
+	if doc.Embedded {
+		return saveEmbedded(mdPath, doc)
+	}
+	doc.recordRevision()
+	data, err := encodeDocument(mdPath, doc)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(mdPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write markdown file: %w", err)
 	}
+	doc.savedContent = doc.Content
+	log().Info("saved markdown", "file", mdPath, "bytes", len(doc.Content))
+	return nil
+}
+
+// SaveSidecar writes the comment threads to the sidecar JSON file without
+// touching the markdown file. The document hash is computed from Content.
+// Embedded documents have no sidecar, so their markdown file is written.
+func SaveSidecar(mdPath string, doc *DocumentWithComments) error {
+	if doc.Embedded {
+		return saveEmbedded(mdPath, doc)
+	}
+
+	if err := savePrivateNotes(mdPath, doc); err != nil {
+		return err
+	}
+	jsonBytes, err := marshalStore(doc, doc.saveOptions(mdPath))
+	if err != nil {
+		return err
+	}
+
+	// Write sidecar file
+	sidecarPath := GetSidecarPath(mdPath)
+	if err := writeFileAtomic(sidecarPath, jsonBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write sidecar file: %w", err)
+	}
+	log().Info("saved sidecar", "file", mdPath, "sidecar", sidecarPath, "threads", len(doc.Threads),
+		"comments", len(doc.GetAllComments()), "hash", doc.DocumentHash, "bytes", len(jsonBytes))
+
+	return nil
+}
+
+// marshalStore updates the document hash and encodes the thread store
+func marshalStore(doc *DocumentWithComments, opts SaveOptions) ([]byte, error) {
+	refreshAnchors(doc)
 
 	// Recompute document hash
-	doc.DocumentHash = ComputeDocumentHash(doc.Content)
-	doc.LastValidated = time.Now()
+	hash := ComputeDocumentHash(doc.Content)
+	if hash != doc.DocumentHash || doc.LastValidated.IsZero() || !opts.StableLastValidated {
+		doc.LastValidated = now()
+	}
+	doc.DocumentHash = hash
+	doc.NormalizeTimestamps()
 
 	// Prepare storage format
 	storage := StorageFormat{
 		Version:       StorageVersion,
 		DocumentHash:  doc.DocumentHash,
 		LastValidated: doc.LastValidated,
-		Threads:       doc.Threads,
+		Threads:       CanonicalOrder(SharedThreads(doc.Threads)),
+		Reviews:       doc.Reviews,
+		AppliedKeys:   doc.AppliedKeys,
+		Revisions:     doc.Revisions,
 	}
 
-	// Marshal to JSON with indentation for readability
+	// Marshal to JSON with indentation for readability; field order follows
+	// the structs and map keys are sorted, so equal documents encode identically
 	jsonBytes, err := json.MarshalIndent(storage, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal comments to JSON: %w", err)
+		return nil, fmt.Errorf("failed to marshal comments to JSON: %w", err)
 	}
+	return jsonBytes, nil
+}
 
-	// Write sidecar file
-	sidecarPath := GetSidecarPath(mdPath)
-	if err := os.WriteFile(sidecarPath, jsonBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write sidecar file: %w", err)
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so an interrupted write never leaves a truncated file.
+// An existing file keeps its permissions; perm applies to new files. When
+// path is a symlink its target is written, and the link is left alone.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	path, err := resolveLinks(path)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
 	}
 
-	return nil
+	// Sidecars kept in a central directory may need their mirrored folder
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// maxLinks bounds how many symlinks resolveLinks follows
+const maxLinks = 40
+
+// resolveLinks returns the file that path refers to after following
+// symlinks, or path itself when it doesn't exist yet. A link to a file that
+// doesn't exist yet resolves to where that file will be.
+func resolveLinks(path string) (string, error) {
+	for range maxLinks {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		// Missing file, or a link to one: follow the link by hand
+		target, err := os.Readlink(path)
+		if err != nil {
+			return path, nil
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
 }
 
 // DeleteSidecar removes the sidecar JSON file if it exists
@@ -167,46 +448,20 @@ func SidecarExists(mdPath string) bool {
 	return err == nil
 }
 
-// ListSidecars finds all sidecar files in a directory
-func ListSidecars(dir string) ([]string, error) {
-	var sidecars []string
-
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if filepath.Ext(name) == ".json" && len(name) > len(".comments.json") {
-			// Check if it ends with .comments.json
-			if name[len(name)-len(".comments.json"):] == ".comments.json" {
-				sidecars = append(sidecars, filepath.Join(dir, name))
-			}
-		}
-	}
-
-	return sidecars, nil
-}
-
 // ArchiveStaleSidecar renames a stale sidecar to .backup with timestamp
+// List, restore, and prune these with ListBackups, RestoreBackup, and PruneBackups.
 func ArchiveStaleSidecar(mdPath string) error {
 	sidecarPath := GetSidecarPath(mdPath)
 	if _, err := os.Stat(sidecarPath); os.IsNotExist(err) {
 		return nil // Nothing to archive
 	}
 
-	// Create backup filename with timestamp
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := fmt.Sprintf("%s.backup.%s", sidecarPath, timestamp)
-
-	// Rename the file
+	// Rename the file to a timestamped backup
+	backupPath := newBackupPath(mdPath)
 	if err := os.Rename(sidecarPath, backupPath); err != nil {
 		return fmt.Errorf("failed to archive sidecar: %w", err)
 	}
+	log().Info("archived stale sidecar", "file", mdPath, "backup", backupPath)
 
 	return nil
 }
@@ -3,6 +3,7 @@ package comment
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -465,3 +466,179 @@ func TestSaveToSidecarWritesMarkdownContent(t *testing.T) {
 		t.Errorf("Markdown content mismatch.\nExpected: %q\nGot: %q", content, string(writtenContent))
 	}
 }
+
+func TestSaveWritesCanonicalOrder(t *testing.T) {
+	defer SetClock(FixedClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)))()
+	mdPath := filepath.Join(t.TempDir(), "test.md")
+	late := NewComment("alice", 5, "Line five")
+	late.ID = "c1"
+	early := NewComment("bob", 2, "Line two")
+	early.ID = "c3"
+	sameLine := NewComment("carol", 2, "Also line two")
+	sameLine.ID = "c2"
+	whole := NewComment("dave", DocumentLine, "Whole document")
+	whole.ID = "c4"
+
+	doc := &DocumentWithComments{
+		Content: "a\nb\nc\nd\ne\n",
+		Threads: []*Comment{late, early, sameLine, whole},
+	}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatalf("SaveToSidecar failed: %v", err)
+	}
+	first, err := os.ReadFile(GetSidecarPath(mdPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, thread := range loaded.Threads {
+		ids = append(ids, thread.ID)
+	}
+	if got := strings.Join(ids, ","); got != "c4,c2,c3,c1" {
+		t.Errorf("Expected threads ordered by line then ID, got %s", got)
+	}
+	if doc.Threads[0] != late {
+		t.Error("Saving should not reorder the document's threads in memory")
+	}
+
+	// Saving the same threads in another order writes identical bytes
+	doc.Threads = []*Comment{whole, sameLine, early, late}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(GetSidecarPath(mdPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Error("Expected identical sidecars regardless of insertion order")
+	}
+}
+
+func TestStableLastValidated(t *testing.T) {
+	defer SetSettingsFunc(func(string) Settings {
+		return Settings{Save: SaveOptions{StableLastValidated: true}}
+	})()
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	mdPath := filepath.Join(t.TempDir(), "test.md")
+	doc := &DocumentWithComments{Content: "Line one\n", Threads: []*Comment{}}
+	restore := SetClock(FixedClock(start))
+	err := SaveToSidecar(mdPath, doc)
+	restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := os.ReadFile(GetSidecarPath(mdPath))
+
+	// A save that changes nothing leaves the sidecar byte-for-byte the same
+	defer SetClock(FixedClock(start.Add(time.Hour)))()
+	doc, err = LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := os.ReadFile(GetSidecarPath(mdPath))
+	if string(first) != string(second) {
+		t.Errorf("Expected an unchanged sidecar, got:\n%s", second)
+	}
+
+	// Changing the content moves lastValidated
+	doc.Content = "Line one, edited\n"
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.LastValidated.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected lastValidated to move on a content change, got %v", doc.LastValidated)
+	}
+}
+
+func TestSaveLeavesMarkdownAloneForCommentChanges(t *testing.T) {
+	mdPath := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(mdPath, []byte("Line one\nLine two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone edits the markdown while the comment is being added
+	edited := "Line one\nLine two, edited elsewhere\n"
+	if err := os.WriteFile(mdPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc.Threads = append(doc.Threads, NewComment("alice", 1, "Comment only"))
+	if doc.ContentChanged() {
+		t.Error("Adding a comment should not count as a content change")
+	}
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	written, _ := os.ReadFile(mdPath)
+	if string(written) != edited {
+		t.Errorf("A comment-only save rewrote the markdown: %q", written)
+	}
+	if !SidecarExists(mdPath) {
+		t.Error("Expected the sidecar to be written")
+	}
+
+	// Changing the content does write the markdown
+	doc.Content = "Replaced\n"
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	written, _ = os.ReadFile(mdPath)
+	if string(written) != "Replaced\n" || doc.ContentChanged() {
+		t.Errorf("Expected the new content to be saved, got %q", written)
+	}
+}
+
+func TestSaveWritesThroughSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	realDoc := filepath.Join(tmpDir, "real.md")
+	if err := os.WriteFile(realDoc, []byte("Line one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mdPath := filepath.Join(tmpDir, "link.md")
+	if err := os.Symlink("real.md", mdPath); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	// The sidecar links to a file that doesn't exist yet
+	realSidecar := filepath.Join(tmpDir, "store", "sidecar.json")
+	if err := os.Symlink(realSidecar, GetSidecarPath(mdPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadFromSidecar(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Content = "Line one, edited\n"
+	doc.Threads = append(doc.Threads, NewComment("alice", 1, "Note"))
+	if err := SaveToSidecar(mdPath, doc); err != nil {
+		t.Fatalf("SaveToSidecar failed: %v", err)
+	}
+
+	for _, link := range []string{mdPath, GetSidecarPath(mdPath)} {
+		if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("Expected %s to still be a symlink", link)
+		}
+	}
+	if data, _ := os.ReadFile(realDoc); string(data) != "Line one, edited\n" {
+		t.Errorf("Expected the linked document to be updated, got %q", data)
+	}
+	if data, err := os.ReadFile(realSidecar); err != nil || !strings.Contains(string(data), "Note") {
+		t.Errorf("Expected the linked sidecar to be written: %v", err)
+	}
+}
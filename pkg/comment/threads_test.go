@@ -25,6 +25,98 @@ func TestGetVisibleComments(t *testing.T) {
 	}
 }
 
+func TestGetVisibleCommentsDocumentLevelFirst(t *testing.T) {
+	threads := []*Comment{
+		{ID: "c1", Line: 5, Replies: []*Comment{}},
+		{ID: "doc", Line: DocumentLine, Replies: []*Comment{}},
+		{ID: "c2", Line: 2, Replies: []*Comment{}},
+	}
+
+	visible := GetVisibleComments(threads, true)
+	ids := []string{}
+	for _, c := range visible {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 3 || ids[0] != "doc" || ids[1] != "c1" || ids[2] != "c2" {
+		t.Errorf("Expected document-level thread first, got %v", ids)
+	}
+}
+
+func TestValidateSkipsDocumentLevelComments(t *testing.T) {
+	reply := &Comment{ID: "r1", Line: DocumentLine, Replies: []*Comment{}}
+	doc := &DocumentWithComments{
+		Content: "# Title\n\nBody",
+		Threads: []*Comment{
+			{ID: "doc", Line: DocumentLine, Replies: []*Comment{reply}},
+			{ID: "far", Line: 99, Replies: []*Comment{}},
+		},
+	}
+
+	orphaned, _ := ValidateAndUpdateCommentStatus(doc)
+	if orphaned != 1 {
+		t.Errorf("Expected only the out-of-bounds comment to be orphaned, got %d", orphaned)
+	}
+	if doc.Threads[0].IsOrphaned() || reply.IsOrphaned() {
+		t.Error("Document-level thread and its reply should not be orphaned")
+	}
+}
+
+func TestValidateRangeComments(t *testing.T) {
+	inBounds := &Comment{ID: "in", Line: 2, EndLine: 3, SectionPath: "Title", Replies: []*Comment{}}
+	pastEnd := &Comment{ID: "past", Line: 2, EndLine: 10, Replies: []*Comment{}}
+	doc := &DocumentWithComments{
+		Content: "# Title\ntwo\nthree",
+		Threads: []*Comment{inBounds, pastEnd},
+	}
+
+	orphaned, _ := ValidateAndUpdateCommentStatus(doc)
+	if orphaned != 1 || !pastEnd.IsOrphaned() || inBounds.IsOrphaned() {
+		t.Errorf("Expected only the range past the end to be orphaned, got %d", orphaned)
+	}
+	// A range inside its section stays where it is
+	if inBounds.Line != 2 || inBounds.EndLine != 3 {
+		t.Errorf("Expected range to stay at 2-3, got %d-%d", inBounds.Line, inBounds.EndLine)
+	}
+}
+
+func TestLineRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          *Comment
+		start, end int
+		isRange    bool
+	}{
+		{"line", &Comment{Line: 4}, 4, 4, false},
+		{"range", &Comment{Line: 4, EndLine: 9}, 4, 9, true},
+		{"suggestion", &Comment{Line: 4, IsSuggestion: true, StartLine: 4, EndLine: 6}, 4, 6, false},
+	}
+	for _, tt := range tests {
+		start, end := tt.c.LineRange()
+		if start != tt.start || end != tt.end || tt.c.IsRange() != tt.isRange {
+			t.Errorf("%s: got %d-%d (range=%v), want %d-%d (range=%v)",
+				tt.name, start, end, tt.c.IsRange(), tt.start, tt.end, tt.isRange)
+		}
+	}
+}
+
+func TestLinesCoveredByRanges(t *testing.T) {
+	resolved := &Comment{ID: "r", Line: 7, EndLine: 8, Resolved: true, Replies: []*Comment{}}
+	threads := []*Comment{
+		{ID: "a", Line: 2, EndLine: 4, Replies: []*Comment{}},
+		{ID: "b", Line: 5, Replies: []*Comment{}},
+		resolved,
+	}
+
+	covered := LinesCoveredByRanges(threads)
+	if !covered[3] || !covered[4] {
+		t.Errorf("Expected lines 3-4 to be covered, got %v", covered)
+	}
+	// The first line of a range carries the comment marker itself
+	if covered[2] || covered[5] || covered[8] {
+		t.Errorf("Expected only the open range to be covered, got %v", covered)
+	}
+}
+
 func TestAddReplyToThread(t *testing.T) {
 	thread := &Comment{
 		ID:      "c1",
@@ -155,6 +247,33 @@ func TestNewCommentWithType(t *testing.T) {
 	}
 }
 
+func TestCaptureQuote(t *testing.T) {
+	content := "# Title\none\ntwo\nthree"
+
+	line := NewComment("alice", 2, "text")
+	CaptureQuote(line, content)
+	if line.Quote != "one" {
+		t.Errorf("Expected quote 'one', got %q", line.Quote)
+	}
+
+	rangeComment := NewComment("alice", 2, "text")
+	rangeComment.EndLine = 4
+	CaptureQuote(rangeComment, content)
+	if rangeComment.Quote != "one\ntwo\nthree" {
+		t.Errorf("Expected the whole range to be quoted, got %q", rangeComment.Quote)
+	}
+
+	for _, c := range []*Comment{
+		NewComment("alice", DocumentLine, "overall"),
+		NewComment("alice", 10, "out of bounds"),
+	} {
+		CaptureQuote(c, content)
+		if c.Quote != "" {
+			t.Errorf("Expected no quote for line %d, got %q", c.Line, c.Quote)
+		}
+	}
+}
+
 func TestNewReply(t *testing.T) {
 	parent := &Comment{
 		ID:   "c1",
@@ -362,6 +481,24 @@ func TestGetSuggestionsByAuthor(t *testing.T) {
 	}
 }
 
+func TestFilterSuggestionsInRange(t *testing.T) {
+	suggestions := []*Comment{
+		{ID: "inside", IsSuggestion: true, StartLine: 12, EndLine: 14},
+		{ID: "edge", IsSuggestion: true, StartLine: 10, EndLine: 20},
+		{ID: "straddles", IsSuggestion: true, StartLine: 18, EndLine: 22},
+		{ID: "before", IsSuggestion: true, StartLine: 2, EndLine: 3},
+	}
+
+	filtered := FilterSuggestionsInRange(suggestions, 10, 20)
+
+	if len(filtered) != 2 {
+		t.Fatalf("FilterSuggestionsInRange = %d, want 2", len(filtered))
+	}
+	if filtered[0].ID != "inside" || filtered[1].ID != "edge" {
+		t.Errorf("Unexpected suggestions: %s, %s", filtered[0].ID, filtered[1].ID)
+	}
+}
+
 func TestAcceptSuggestion(t *testing.T) {
 	threads := []*Comment{
 		{
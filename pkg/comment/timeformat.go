@@ -0,0 +1,86 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time formats for showing timestamps to people. Machine-readable output
+// (JSON, templates) always uses RFC 3339.
+const (
+	TimeLocal    = "local"    // "2025-01-15 10:30" in the local time zone
+	TimeRelative = "relative" // "2h ago", falling back to the local date after a month
+	TimeISO      = "iso"      // RFC 3339, e.g. "2025-01-15T10:30:00Z"
+)
+
+// TimeFormats lists the valid time formats
+var TimeFormats = []string{TimeLocal, TimeRelative, TimeISO}
+
+// ValidateTimeFormat checks a time format name
+func ValidateTimeFormat(name string) error {
+	for _, f := range TimeFormats {
+		if name == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown time format %q (want %s)", name, strings.Join(TimeFormats, ", "))
+}
+
+// FormatTimestamp formats t for display in the given time format (TimeLocal
+// if it is empty or unknown)
+func FormatTimestamp(t time.Time, format string) string {
+	switch format {
+	case TimeISO:
+		return t.Format(time.RFC3339)
+	case TimeRelative:
+		return relativeTime(t)
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// relativeTime describes how long ago t was, e.g. "5m ago" or "3d ago".
+// Anything older than 30 days shows its local date instead.
+func relativeTime(t time.Time) string {
+	elapsed := now().Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed/time.Minute))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed/time.Hour))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed/(24*time.Hour)))
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// NormalizeTimestamps converts every timestamp in the document to UTC, so
+// sidecars written on machines in different time zones store (and diff) the
+// same instants the same way. Loading and saving a sidecar both do this.
+func (d *DocumentWithComments) NormalizeTimestamps() {
+	d.LastValidated = d.LastValidated.UTC()
+	for _, c := range d.GetAllComments() {
+		c.Timestamp = c.Timestamp.UTC()
+		c.ResolvedAt = utcPointer(c.ResolvedAt)
+		c.OrphanedAt = utcPointer(c.OrphanedAt)
+		c.DecidedAt = utcPointer(c.DecidedAt)
+	}
+	for _, r := range d.Reviews {
+		r.StartedAt = r.StartedAt.UTC()
+		r.SubmittedAt = utcPointer(r.SubmittedAt)
+	}
+	for _, r := range d.Revisions {
+		r.Timestamp = r.Timestamp.UTC()
+	}
+}
+
+// utcPointer returns a pointer to t in UTC, or nil if t is nil
+func utcPointer(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
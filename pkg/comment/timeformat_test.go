@@ -0,0 +1,80 @@
+package comment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	current := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	restore := SetClock(FixedClock(current))
+	defer restore()
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{2*time.Hour + 30*time.Minute, "2h ago"},
+		{3 * 24 * time.Hour, "3d ago"},
+		{45 * 24 * time.Hour, current.Add(-45 * 24 * time.Hour).Local().Format("2006-01-02")},
+	}
+	for _, tt := range tests {
+		if got := FormatTimestamp(current.Add(-tt.ago), TimeRelative); got != tt.want {
+			t.Errorf("relative %v ago = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+
+	if got := FormatTimestamp(current, TimeISO); got != "2025-01-15T12:00:00Z" {
+		t.Errorf("iso = %q", got)
+	}
+	if got, want := FormatTimestamp(current, ""), current.Local().Format("2006-01-02 15:04"); got != want {
+		t.Errorf("default = %q, want %q", got, want)
+	}
+	if err := ValidateTimeFormat("relative"); err != nil {
+		t.Errorf("relative should be valid: %v", err)
+	}
+	if err := ValidateTimeFormat("fuzzy"); err == nil {
+		t.Error("Expected an error for an unknown time format")
+	}
+}
+
+func TestNormalizeTimestamps(t *testing.T) {
+	pacific := time.FixedZone("PST", -8*60*60)
+	created := time.Date(2025, 1, 15, 2, 30, 0, 0, pacific)
+	decided := created.Add(time.Hour)
+
+	s := NewSuggestion("alice", 1, 1, "Fix", "a", "b")
+	s.Timestamp = created
+	s.DecidedAt = &decided
+	reply := NewReply("bob", "Agreed", s)
+	reply.Timestamp = created.Add(time.Minute)
+	s.Replies = []*Comment{reply}
+	doc := &DocumentWithComments{
+		Threads:       []*Comment{s},
+		Reviews:       []*Review{{StartedAt: created, SubmittedAt: &decided}},
+		LastValidated: created,
+	}
+
+	doc.NormalizeTimestamps()
+
+	for name, got := range map[string]time.Time{
+		"thread":         s.Timestamp,
+		"reply":          reply.Timestamp,
+		"decided":        *s.DecidedAt,
+		"review started": doc.Reviews[0].StartedAt,
+		"review done":    *doc.Reviews[0].SubmittedAt,
+		"validated":      doc.LastValidated,
+	} {
+		if got.Location() != time.UTC {
+			t.Errorf("%s timestamp is in %s, want UTC", name, got.Location())
+		}
+	}
+	if !s.Timestamp.Equal(created) || s.Timestamp.Format(time.RFC3339) != "2025-01-15T10:30:00Z" {
+		t.Errorf("Normalizing changed the instant: %s", s.Timestamp.Format(time.RFC3339))
+	}
+	if decided.Location() != pacific {
+		t.Error("Normalizing should not modify the original *time.Time values")
+	}
+}
@@ -0,0 +1,72 @@
+package comment
+
+import "strings"
+
+// TrainingPair is one decided suggestion with its document context, for
+// evaluating or fine-tuning reviewers on what was actually accepted
+type TrainingPair struct {
+	File          string `json:"file,omitempty"`
+	SuggestionID  string `json:"suggestion_id"`
+	Author        string `json:"author"`
+	Section       string `json:"section,omitempty"`
+	StartLine     int    `json:"start_line"`
+	EndLine       int    `json:"end_line"`
+	ContextBefore string `json:"context_before"`
+	ContextAfter  string `json:"context_after"`
+	Rationale     string `json:"rationale"`
+	OriginalText  string `json:"original_text"`
+	ProposedText  string `json:"proposed_text"`
+	Outcome       string `json:"outcome"`          // DecisionAccepted or DecisionRejected
+	Reason        string `json:"reason,omitempty"` // Why it was accepted or rejected, if recorded
+	FinalText     string `json:"final_text"`       // What the lines say after the decision
+}
+
+// TrainingPairs returns a pair for every accepted or rejected suggestion in
+// the document, with up to contextLines lines of the current content on each
+// side of the suggestion's lines. Pending suggestions have no outcome yet and
+// are left out.
+func TrainingPairs(doc *DocumentWithComments, contextLines int) []TrainingPair {
+	lines := strings.Split(doc.Content, "\n")
+	pairs := []TrainingPair{}
+
+	for _, s := range doc.Threads {
+		// Moves propose no new text to learn from
+		if !s.IsSuggestion || s.IsPending() || s.IsMove {
+			continue
+		}
+
+		pair := TrainingPair{
+			SuggestionID: s.ID,
+			Author:       s.Author,
+			Section:      s.SectionPath,
+			StartLine:    s.StartLine,
+			EndLine:      s.EndLine,
+			Rationale:    s.Text,
+			OriginalText: s.OriginalText,
+			ProposedText: s.ProposedText,
+			Outcome:      DecisionRejected,
+			FinalText:    s.OriginalText,
+		}
+		// An accepted suggestion's lines now hold the proposed text
+		last := s.EndLine
+		if s.IsAccepted() {
+			pair.Outcome = DecisionAccepted
+			pair.FinalText = s.ProposedText
+			last = s.StartLine + ProposedLineCount(s) - 1
+		}
+		if reply := DecisionReply(s); reply != nil {
+			pair.Reason = reply.Text
+		}
+
+		if s.StartLine >= 1 && s.StartLine <= len(lines)+1 {
+			before := max(s.StartLine-1-contextLines, 0)
+			pair.ContextBefore = strings.Join(lines[before:min(s.StartLine-1, len(lines))], "\n")
+			after := min(last, len(lines))
+			pair.ContextAfter = strings.Join(lines[after:min(after+contextLines, len(lines))], "\n")
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs
+}
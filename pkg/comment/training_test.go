@@ -0,0 +1,45 @@
+package comment
+
+import "testing"
+
+func TestTrainingPairs(t *testing.T) {
+	doc := &DocumentWithComments{Content: "One\nTwo\nThree\nFour\nFive"}
+	accepted := NewSuggestion("claude", 2, 2, "Shout", "Two", "TWO")
+	rejected := NewSuggestion("claude", 4, 4, "Spell out", "Four", "4")
+	pending := NewSuggestion("bob", 5, 5, "Later", "Five", "5")
+	doc.Threads = []*Comment{accepted, rejected, pending}
+
+	content, err := ApplySuggestion(doc.Content, accepted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Content = content
+	if err := AcceptSuggestion(doc.Threads, accepted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RejectSuggestion(doc.Threads, rejected.ID); err != nil {
+		t.Fatal(err)
+	}
+	AddDecisionReply(rejected, "alice", "Digits look odd in prose")
+
+	pairs := TrainingPairs(doc, 1)
+	if len(pairs) != 2 {
+		t.Fatalf("Expected pairs for the two decided suggestions, got %d", len(pairs))
+	}
+
+	got := pairs[0]
+	if got.Outcome != DecisionAccepted || got.FinalText != "TWO" {
+		t.Errorf("Unexpected accepted pair: %+v", got)
+	}
+	if got.ContextBefore != "One" || got.ContextAfter != "Three" {
+		t.Errorf("Expected one line of context on each side, got %q / %q", got.ContextBefore, got.ContextAfter)
+	}
+
+	got = pairs[1]
+	if got.Outcome != DecisionRejected || got.FinalText != "Four" || got.Reason != "Digits look odd in prose" {
+		t.Errorf("Unexpected rejected pair: %+v", got)
+	}
+	if got.ContextBefore != "Three" || got.ContextAfter != "Five" {
+		t.Errorf("Unexpected context for the rejected pair: %q / %q", got.ContextBefore, got.ContextAfter)
+	}
+}
@@ -1,6 +1,10 @@
 package comment
 
-import "time"
+import (
+	"time"
+
+	"github.com/rcliao/comments/pkg/markdown"
+)
 
 // Comment represents a single comment or suggestion in a document (v2.0)
 // Simplified structure with nested thread support
@@ -17,12 +21,27 @@ type Comment struct {
 	// Position
 	Line int // Line number where comment is attached
 
+	// Quote is the exact text of the target line(s) when the comment was made,
+	// kept so the feedback still makes sense after edits or orphaning
+	Quote string
+
+	// LineHash is a hash of the target line(s) when the comment was made or
+	// last reattached, to tell when the text under it changed (see linehash.go)
+	LineHash string
+
+	// Anchor is the slug of the heading a comment is anchored to instead of
+	// its line, with AnchorOffset the lines from the heading to Line; when
+	// the document changes, the comment follows its heading (see anchors.go)
+	Anchor       string
+	AnchorOffset int
+
 	// Section metadata (computed from document structure)
 	SectionID   string // ID of the section this comment belongs to (e.g., "s1", "s2")
 	SectionPath string // Full hierarchical path (e.g., "Intro > Overview > Key Points")
 
 	// State
-	Resolved bool // Whether the comment/thread has been resolved
+	Resolved   bool       // Whether the comment/thread has been resolved
+	ResolvedAt *time.Time // When the thread was resolved (nil if unresolved, or resolved before this was recorded)
 
 	// Status tracking (for TODO/task management)
 	Status         string     // Comment status: "active", "orphaned", "resolved", "completed"
@@ -31,16 +50,90 @@ type Comment struct {
 	OrphanedReason string     // Explanation of why comment was orphaned (empty if active)
 	OrphanedAt     *time.Time // Timestamp when comment was marked as orphaned (nil if never orphaned)
 
+	// DriftedFrom is the line a comment was on before validation moved it
+	// to the end of a document that got shorter (0 unless drifted), and
+	// DriftChecks how many validations have found it drifted (see drift.go)
+	DriftedFrom int
+	DriftChecks int
+
 	// Thread structure (nested replies)
 	Replies []*Comment // Nested replies to this comment (empty for leaf comments)
 
 	// Suggestion fields (for edit suggestions)
-	IsSuggestion bool   // True if this is an edit suggestion
-	StartLine    int    // Start line for suggestion (0 if not a suggestion)
-	EndLine      int    // End line for suggestion (0 if not a suggestion)
-	OriginalText string // Original text being replaced (empty if not a suggestion)
-	ProposedText string // Proposed replacement text (empty if not a suggestion)
-	Accepted     *bool  // nil=pending, true=accepted, false=rejected (nil if not a suggestion)
+	IsSuggestion bool       // True if this is an edit suggestion
+	StartLine    int        // Start line for suggestion (0 if not a suggestion)
+	EndLine      int        // End line for suggestion or range comment (0 for single-line comments)
+	OriginalText string     // Original text being replaced (empty if not a suggestion)
+	ProposedText string     // Proposed replacement text (empty if not a suggestion)
+	Accepted     *bool      // nil=pending, true=accepted, false=rejected (nil if not a suggestion)
+	DecidedAt    *time.Time // When the suggestion was accepted or rejected (nil if pending, or decided before this was recorded)
+	DependsOn    []string   // IDs of suggestions that must be applied before this one (empty if independent)
+
+	// IsMove marks a suggestion that moves lines StartLine-EndLine to after
+	// line MoveAfter (0 for the top) instead of replacing them; MoveAfter is
+	// a line of the document before the move (see moves.go)
+	IsMove    bool
+	MoveAfter int
+
+	// Decision is "accepted" or "rejected" on a reply giving the reason for
+	// its suggestion's decision (empty for other comments, see decisions.go)
+	Decision string
+
+	// Review the comment was written in (empty if none, see reviews.go)
+	ReviewID string
+
+	// Pinned threads are listed first in list and the TUI, whatever their
+	// line, so must-address items stay at the top (see pins.go)
+	Pinned bool
+
+	// Visibility is VisibilityShared (or empty) for the team's record, or
+	// VisibilityPrivate for a note only its author sees, kept in the author's
+	// overlay next to the sidecar (see private.go). Set on threads; replies
+	// follow their thread.
+	Visibility string
+
+	// Authorship integrity (optional, see signing.go)
+	Signature string // Base64 ed25519 signature over the comment's immutable fields (empty if unsigned)
+	SignerKey string // Base64 ed25519 public key that produced Signature
+}
+
+// DocumentLine is the Line of comments about the document as a whole rather
+// than a specific line (e.g. "the overall structure is confusing")
+const DocumentLine = 0
+
+// IsDocumentLevel returns true if the comment applies to the whole document
+// Replies inherit Line from their thread, so they count as well.
+func (c *Comment) IsDocumentLevel() bool {
+	return !c.IsSuggestion && c.Line == DocumentLine
+}
+
+// IsRange returns true if this is a regular comment spanning several lines
+// Range comments start at Line and end at EndLine.
+func (c *Comment) IsRange() bool {
+	return !c.IsSuggestion && c.EndLine > c.Line
+}
+
+// IsInsertion returns true if this is a suggestion that only inserts text
+// An insertion spans no lines: it inserts after EndLine, and StartLine is
+// EndLine+1 (so EndLine is 0 for an insertion at the top of the document).
+func (c *Comment) IsInsertion() bool {
+	return c.IsSuggestion && c.EndLine == c.StartLine-1
+}
+
+// LineRange returns the first and last line the comment refers to
+// An insertion refers to the line it is inserted after (or the first line).
+func (c *Comment) LineRange() (int, int) {
+	if c.IsInsertion() {
+		line := max(c.EndLine, 1)
+		return line, line
+	}
+	if c.IsSuggestion {
+		return c.StartLine, c.EndLine
+	}
+	if c.IsRange() {
+		return c.Line, c.EndLine
+	}
+	return c.Line, c.Line
 }
 
 // IsRoot returns true if this is a root comment (has no parent)
@@ -70,6 +163,27 @@ func (c *Comment) IsRejected() bool {
 	return c.IsSuggestion && c.Accepted != nil && !*c.Accepted
 }
 
+// Suggestion states, as reported by SuggestionState
+const (
+	SuggestionPending  = "pending"
+	SuggestionAccepted = "accepted"
+	SuggestionRejected = "rejected"
+)
+
+// SuggestionState returns whether a suggestion is pending, accepted, or
+// rejected ("" if this is not a suggestion)
+func (c *Comment) SuggestionState() string {
+	switch {
+	case !c.IsSuggestion:
+		return ""
+	case c.Accepted == nil:
+		return SuggestionPending
+	case *c.Accepted:
+		return SuggestionAccepted
+	}
+	return SuggestionRejected
+}
+
 // CountReplies returns the total number of replies (direct + nested)
 func (c *Comment) CountReplies() int {
 	count := len(c.Replies)
@@ -132,10 +246,43 @@ type Position struct {
 
 // DocumentWithComments represents a parsed document with comment threads (v2.0)
 type DocumentWithComments struct {
-	Content      string     // Raw markdown content without comment markup
-	Threads      []*Comment // Root comment threads (each may contain nested replies)
-	DocumentHash string     // SHA-256 hash of content for staleness detection
-	LastValidated time.Time  // Last time sidecar was validated against document
+	Content       string      // Raw markdown content without comment markup
+	Threads       []*Comment  // Root comment threads (each may contain nested replies)
+	DocumentHash  string      // SHA-256 hash of content for staleness detection
+	LastValidated time.Time   // Last time sidecar was validated against document
+	Reviews       []*Review   // Review sessions on the document, in start order
+	Revisions     []*Revision // Changes made by accepting suggestions, oldest first (see revisions.go)
+
+	// AppliedKeys maps batch idempotency keys to the comment each produced
+	AppliedKeys map[string]string
+
+	// Format is how the markdown file is stored on disk (encoding, line
+	// endings); Content is always UTF-8 with "\n" line endings
+	Format TextFormat
+
+	// Embedded is set when the threads are stored in the markdown file
+	// itself rather than a sidecar (see embed.go)
+	Embedded bool
+
+	// Model is the document's format (see markdown.ModelFor); Content is its
+	// text view. Nil means markdown.
+	Model markdown.Model
+
+	// savedContent is Content as last read from or written to the markdown
+	// file, so saves can tell whether the markdown needs rewriting
+	savedContent string
+
+	// options are the save options in effect for the file it was loaded
+	// from, which validation and saves follow (see saveOptions)
+	options *SaveOptions
+
+	// applied lists the suggestions applied to Content since savedContent,
+	// recorded as a revision when the markdown is written
+	applied []string
+
+	// privateOwners lists the authors whose private notes were merged into
+	// Threads, so saves write their overlays back (see private.go)
+	privateOwners []string
 }
 
 // GetAllComments returns a flat list of all comments (roots + replies)
@@ -159,6 +306,73 @@ func flattenReplies(replies []*Comment) []*Comment {
 	return flat
 }
 
+// Clone returns a deep copy of the comment and its replies
+func (c *Comment) Clone() *Comment {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	if c.OrphanedAt != nil {
+		t := *c.OrphanedAt
+		clone.OrphanedAt = &t
+	}
+	if c.ResolvedAt != nil {
+		t := *c.ResolvedAt
+		clone.ResolvedAt = &t
+	}
+	if c.Accepted != nil {
+		accepted := *c.Accepted
+		clone.Accepted = &accepted
+	}
+	if c.DecidedAt != nil {
+		t := *c.DecidedAt
+		clone.DecidedAt = &t
+	}
+	if c.DependsOn != nil {
+		clone.DependsOn = append([]string{}, c.DependsOn...)
+	}
+	if c.Replies != nil {
+		clone.Replies = make([]*Comment, len(c.Replies))
+		for i, reply := range c.Replies {
+			clone.Replies[i] = reply.Clone()
+		}
+	}
+	return &clone
+}
+
+// Clone returns a deep copy of the document, so one copy can be changed
+// without affecting the other
+func (d *DocumentWithComments) Clone() *DocumentWithComments {
+	clone := *d
+	if d.Threads != nil {
+		clone.Threads = make([]*Comment, len(d.Threads))
+		for i, thread := range d.Threads {
+			clone.Threads[i] = thread.Clone()
+		}
+	}
+	if d.Reviews != nil {
+		clone.Reviews = make([]*Review, len(d.Reviews))
+		for i, review := range d.Reviews {
+			r := *review
+			if review.SubmittedAt != nil {
+				t := *review.SubmittedAt
+				r.SubmittedAt = &t
+			}
+			clone.Reviews[i] = &r
+		}
+	}
+	if d.AppliedKeys != nil {
+		clone.AppliedKeys = make(map[string]string, len(d.AppliedKeys))
+		for key, id := range d.AppliedKeys {
+			clone.AppliedKeys[key] = id
+		}
+	}
+	// Revisions are never changed once recorded, only added and removed
+	clone.Revisions = append([]*Revision(nil), d.Revisions...)
+	clone.applied = append([]string(nil), d.applied...)
+	return &clone
+}
+
 // FindThreadByID finds a thread by its ID (root comment ID)
 func (d *DocumentWithComments) FindThreadByID(id string) *Comment {
 	for _, thread := range d.Threads {
@@ -0,0 +1,43 @@
+package comment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EnvUserDir overrides the per-user directory holding personal state about
+// documents (private notes, seen state)
+const EnvUserDir = "COMMENTS_USER_DIR"
+
+// userStatePath returns where personal state of a kind (e.g. "private")
+// about a markdown file is kept: in the user's config directory rather than
+// the document's tree, so it is never committed along with the sidecar.
+// Files are named by a hash of the document's absolute path, then suffix.
+func userStatePath(kind, mdPath, suffix string) (string, error) {
+	dir := os.Getenv(EnvUserDir)
+	if dir == "" {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+		dir = filepath.Join(config, "comments")
+	}
+
+	abs, err := filepath.Abs(mdPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", mdPath, err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, kind, hex.EncodeToString(sum[:8])+suffix), nil
+}
+
+// writeUserState writes a personal state file, creating its directory
+func writeUserState(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
@@ -3,9 +3,6 @@ package comment
 import (
 	"fmt"
 	"strings"
-	"time"
-
-	"github.com/rcliao/comments/pkg/markdown"
 )
 
 // ValidationIssue represents a single validation problem
@@ -37,7 +34,11 @@ func ValidateAndUpdateCommentStatus(doc *DocumentWithComments) (int, []Validatio
 	lineCount := len(lines)
 
 	// Parse document structure for section validation
-	docStructure := markdown.ParseDocument(doc.Content)
+	docStructure := doc.Structure()
+	var driftPolicy DriftPolicy
+	if doc.options != nil {
+		driftPolicy = doc.options.Drift
+	}
 
 	// Validate each comment individually
 	allComments := doc.GetAllComments()
@@ -47,12 +48,34 @@ func ValidateAndUpdateCommentStatus(doc *DocumentWithComments) (int, []Validatio
 			continue
 		}
 
-		orphanReason := ""
+		// Document-level comments have no position to validate
+		if comment.IsDocumentLevel() {
+			continue
+		}
+
+		// Heading-anchored threads follow their heading; if it is gone, the
+		// checks below decide whether the thread survives
+		if hashMismatch && comment.Anchor != "" {
+			from := comment.Line
+			if moved, found := resolveAnchor(comment, docStructure); moved && found {
+				issues = append(issues, ValidationIssue{
+					Severity:  "info",
+					Message:   fmt.Sprintf("Heading '#%s' moved; comment followed it from line %d to %d", comment.Anchor, from, comment.Line),
+					CommentID: comment.ID,
+				})
+			}
+		}
+
+		// Comments past the end drift to the last line while the policy allows
+		issue, orphanReason := driftComment(comment, lineCount, hashMismatch, driftPolicy)
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
 
 		// Check line bounds
-		if comment.Line < 1 {
+		if orphanReason == "" && comment.Line < 1 {
 			orphanReason = fmt.Sprintf("Invalid line number: %d", comment.Line)
-		} else if comment.Line > lineCount {
+		} else if orphanReason == "" && comment.Line > lineCount {
 			orphanReason = fmt.Sprintf("Line %d out of bounds (document has %d lines)", comment.Line, lineCount)
 		}
 
@@ -61,9 +84,12 @@ func ValidateAndUpdateCommentStatus(doc *DocumentWithComments) (int, []Validatio
 			section := docStructure.FindSection(comment.SectionPath)
 			if section == nil {
 				orphanReason = fmt.Sprintf("Section '%s' no longer exists", comment.SectionPath)
-			} else if section.StartLine != comment.Line {
-				// Section exists but moved - update line position
+			} else if comment.Line < section.StartLine || comment.Line > section.EndLine {
+				// Section moved away from the comment - follow it, keeping a range's length
 				comment.OriginalLine = comment.Line
+				if comment.IsRange() {
+					comment.EndLine += section.StartLine - comment.Line
+				}
 				comment.Line = section.StartLine
 				issues = append(issues, ValidationIssue{
 					Severity:  "info",
@@ -73,19 +99,30 @@ func ValidateAndUpdateCommentStatus(doc *DocumentWithComments) (int, []Validatio
 			}
 		}
 
+		// Check range comments
+		if orphanReason == "" && comment.IsRange() && comment.EndLine > lineCount {
+			orphanReason = fmt.Sprintf("Range %d-%d out of bounds (document has %d lines)", comment.Line, comment.EndLine, lineCount)
+		}
+
 		// Check suggestion line ranges
 		if orphanReason == "" && comment.IsSuggestion {
-			if comment.StartLine > lineCount || comment.EndLine > lineCount {
+			if comment.IsInsertion() {
+				if comment.EndLine > lineCount {
+					orphanReason = fmt.Sprintf("Insertion after line %d out of bounds (document has %d lines)", comment.EndLine, lineCount)
+				}
+			} else if comment.StartLine > lineCount || comment.EndLine > lineCount {
 				orphanReason = fmt.Sprintf("Suggestion line range %d-%d out of bounds (document has %d lines)", comment.StartLine, comment.EndLine, lineCount)
+			} else if comment.IsMove && comment.MoveAfter > lineCount {
+				orphanReason = fmt.Sprintf("Move target line %d out of bounds (document has %d lines)", comment.MoveAfter, lineCount)
 			}
 		}
 
 		// Mark comment as orphaned if validation failed
 		if orphanReason != "" {
-			now := time.Now()
+			orphanedAt := now()
 			comment.Status = "orphaned"
 			comment.OrphanedReason = orphanReason
-			comment.OrphanedAt = &now
+			comment.OrphanedAt = &orphanedAt
 			if comment.OriginalLine == 0 {
 				comment.OriginalLine = comment.Line
 			}
@@ -96,6 +133,19 @@ func ValidateAndUpdateCommentStatus(doc *DocumentWithComments) (int, []Validatio
 				Message:   fmt.Sprintf("Comment orphaned: %s", orphanReason),
 				CommentID: comment.ID,
 			})
+		} else if hashMismatch && comment.AppliedByHand(lines) {
+			issues = append(issues, ValidationIssue{
+				Severity:  "info",
+				Message:   fmt.Sprintf("Suggestion %s is already in the document; run 'comments sync' to mark it accepted", comment.ID),
+				CommentID: comment.ID,
+			})
+		} else if hashMismatch && comment.TargetChanged(lines) {
+			// Still attached, but the text it is about was edited
+			issues = append(issues, ValidationIssue{
+				Severity:  "info",
+				Message:   fmt.Sprintf("Text under comment %s (line %d) changed since it was made", comment.ID, comment.Line),
+				CommentID: comment.ID,
+			})
 		}
 	}
 
@@ -123,6 +173,9 @@ func ValidateSidecar(doc *DocumentWithComments) (bool, []ValidationIssue, error)
 
 	allComments := doc.GetAllComments()
 	for _, comment := range allComments {
+		if comment.IsDocumentLevel() {
+			continue
+		}
 		if comment.Line > lineCount {
 			issues = append(issues, ValidationIssue{
 				Severity:  "error",
@@ -140,7 +193,7 @@ func ValidateSidecar(doc *DocumentWithComments) (bool, []ValidationIssue, error)
 	}
 
 	// Check 3: Section paths still exist
-	docStructure := markdown.ParseDocument(doc.Content)
+	docStructure := doc.Structure()
 	for _, comment := range allComments {
 		if comment.SectionPath != "" {
 			// Check if section still exists
@@ -158,7 +211,7 @@ func ValidateSidecar(doc *DocumentWithComments) (bool, []ValidationIssue, error)
 	// Check 4: Suggestion line ranges valid
 	for _, comment := range allComments {
 		if comment.IsSuggestion {
-			if comment.StartLine > lineCount || comment.EndLine > lineCount {
+			if comment.EndLine > lineCount || (comment.StartLine > lineCount && !comment.IsInsertion()) {
 				issues = append(issues, ValidationIssue{
 					Severity:  "error",
 					Message:   fmt.Sprintf("Suggestion line range %d-%d out of bounds (document has %d lines)", comment.StartLine, comment.EndLine, lineCount),
@@ -195,7 +248,7 @@ func ValidateAndArchiveIfStale(mdPath string, doc *DocumentWithComments) (bool,
 		}
 
 		// Get backup path for reporting
-		backupPath := GetSidecarPath(mdPath) + ".backup.*"
+		backupPath := GetBackupPattern(mdPath)
 
 		return false, backupPath, nil
 	}
@@ -204,19 +257,20 @@ func ValidateAndArchiveIfStale(mdPath string, doc *DocumentWithComments) (bool,
 }
 
 // RecomputeAllSections recomputes section metadata for all comments in the document
-// This should be called when the markdown structure changes
+// This should be called when the markdown structure changes. Orphaned
+// comments keep the section they were orphaned from.
 func RecomputeAllSections(doc *DocumentWithComments) {
 	if doc == nil || len(doc.Threads) == 0 {
 		return
 	}
 
 	// Parse document structure
-	docStructure := markdown.ParseDocument(doc.Content)
+	docStructure := doc.Structure()
 
 	// Update all comments (roots and replies)
 	allComments := doc.GetAllComments()
 	for _, comment := range allComments {
-		if comment.Line <= 0 {
+		if comment.Line <= 0 || comment.IsOrphaned() {
 			continue
 		}
 
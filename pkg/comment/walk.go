@@ -0,0 +1,147 @@
+package comment
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/markdown"
+)
+
+// WalkDocuments returns every markdown file under root that has a sidecar
+// Hidden directories (e.g. .git) are skipped. Results are sorted by path.
+func WalkDocuments(root string) ([]string, error) {
+	return WalkDocumentsContext(context.Background(), root)
+}
+
+// WalkDocumentsContext is WalkDocuments that stops with ctx.Err() when ctx
+// is cancelled
+func WalkDocumentsContext(ctx context.Context, root string) ([]string, error) {
+	sidecarName := func(name string) (string, bool) { return markdownName(root, name) }
+	files, err := walkFiles(ctx, root, sidecarName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A document with sidecars under both the configured and the default
+	// name is listed once
+	files = slices.Compact(files)
+
+	dir := sidecarDir(root)
+	if dir == root {
+		return files, nil
+	}
+
+	// Sidecars live in a central directory: walk its mirror of root and map
+	// each sidecar back to the markdown file under root. Sidecars found above
+	// inside the central directory (if it isn't hidden) aren't documents'.
+	seen := map[string]bool{}
+	docs := []string{}
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil && isWithin(dir, abs) {
+			continue
+		}
+		seen[file] = true
+		docs = append(docs, file)
+	}
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return docs, nil
+	}
+	mirrored, err := walkFiles(ctx, dir, sidecarName)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range mirrored {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		if doc := filepath.Join(root, rel); !seen[doc] {
+			seen[doc] = true
+			docs = append(docs, doc)
+		}
+	}
+	sort.Strings(docs)
+	return docs, nil
+}
+
+// WalkMarkdownFiles returns every markdown file under root, with or without a sidecar
+// Hidden directories are skipped. Results are sorted by path.
+func WalkMarkdownFiles(root string) ([]string, error) {
+	return walkFiles(context.Background(), root, func(name string) (string, bool) {
+		return name, isMarkdownName(name)
+	})
+}
+
+// isMarkdownName reports whether a file name has the extension of a
+// document format comments can anchor in (markdown, MDX, notebooks)
+func isMarkdownName(name string) bool {
+	return markdown.IsDocument(name)
+}
+
+// walkFiles collects files under root accepted by match
+// match maps a file name to the name reported (relative to the same directory).
+func walkFiles(ctx context.Context, root string, match func(name string) (string, bool)) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if name, ok := match(d.Name()); ok {
+			files = append(files, filepath.Join(filepath.Dir(path), name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// DocumentResult is the outcome of reading one document in a parallel scan
+type DocumentResult struct {
+	Path string
+	Doc  *DocumentWithComments
+	Err  error
+}
+
+// ReadDocumentsParallel reads documents with a bounded pool of workers
+// Results are delivered in completion order; the channel is closed once every
+// path has been processed. workers <= 0 uses one worker per CPU.
+func ReadDocumentsParallel(paths []string, workers int) <-chan DocumentResult {
+	return ReadDocumentsParallelContext(context.Background(), paths, workers)
+}
+
+// ReadDocumentsParallelContext is ReadDocumentsParallel that stops starting
+// new reads once ctx is cancelled
+func ReadDocumentsParallelContext(ctx context.Context, paths []string, workers int) <-chan DocumentResult {
+	results := make(chan DocumentResult)
+
+	go func() {
+		for r := range RunPipelineContext(ctx, paths, workers, ReadSidecar) {
+			results <- DocumentResult{Path: r.Path, Doc: r.Value, Err: r.Err}
+		}
+		close(results)
+	}()
+
+	return results
+}
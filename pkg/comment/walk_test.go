@@ -0,0 +1,106 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkDocumentsAndReadParallel(t *testing.T) {
+	root := t.TempDir()
+	dirs := []string{"specs", filepath.Join("specs", "api"), ".hidden"}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []string{
+		filepath.Join(root, "README.md"),
+		filepath.Join(root, "specs", "a.md"),
+		filepath.Join(root, "specs", "api", "b.md"),
+		filepath.Join(root, ".hidden", "skip.md"),
+	}
+	for i, path := range files {
+		doc := &DocumentWithComments{
+			Content: "line one\nline two\n",
+			Threads: []*Comment{{ID: "c" + string(rune('1'+i)), Line: 1, Text: "note", Replies: []*Comment{}}},
+		}
+		if err := SaveToSidecar(path, doc); err != nil {
+			t.Fatalf("SaveToSidecar failed: %v", err)
+		}
+	}
+
+	// A markdown file without a sidecar is ignored
+	if err := os.WriteFile(filepath.Join(root, "specs", "plain.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := WalkDocuments(root)
+	if err != nil {
+		t.Fatalf("WalkDocuments failed: %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("Expected 3 documents, got %d: %v", len(docs), docs)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WalkDocumentsContext(cancelled, root); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled walk to fail with context.Canceled, got %v", err)
+	}
+
+	seen := 0
+	for result := range ReadDocumentsParallel(docs, 2) {
+		if result.Err != nil {
+			t.Errorf("Read %s failed: %v", result.Path, result.Err)
+			continue
+		}
+		if len(result.Doc.Threads) != 1 {
+			t.Errorf("Expected 1 thread in %s, got %d", result.Path, len(result.Doc.Threads))
+		}
+		seen++
+	}
+	if seen != 3 {
+		t.Errorf("Expected 3 results, got %d", seen)
+	}
+
+	// Markdown without a sidecar is included when walking all markdown files
+	all, err := WalkMarkdownFiles(root)
+	if err != nil {
+		t.Fatalf("WalkMarkdownFiles failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("Expected 4 markdown files, got %d: %v", len(all), all)
+	}
+	if all[3] != filepath.Join(root, "specs", "plain.md") {
+		t.Errorf("Expected plain.md in sorted results, got %v", all)
+	}
+}
+
+func TestReadSidecarDoesNotRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.md")
+	doc := &DocumentWithComments{
+		Content: "one\n",
+		Threads: []*Comment{{ID: "c1", Line: 5, Text: "past end", Replies: []*Comment{}}},
+	}
+	if err := SaveToSidecar(path, doc); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(GetSidecarPath(path))
+
+	loaded, err := ReadSidecar(path)
+	if err != nil {
+		t.Fatalf("ReadSidecar failed: %v", err)
+	}
+	if loaded.Threads[0].GetStatus() != "active" {
+		t.Errorf("ReadSidecar should not validate, got status %s", loaded.Threads[0].GetStatus())
+	}
+
+	after, _ := os.ReadFile(GetSidecarPath(path))
+	if string(before) != string(after) {
+		t.Error("ReadSidecar modified the sidecar file")
+	}
+}
@@ -0,0 +1,525 @@
+// Package comments is the API for embedding comments in other Go programs
+// (bots, servers, editor plugins). A Service performs the same operations as
+// the comments CLI, with the same project policy, signing, and review rules,
+// but returns typed results and errors instead of printing and exiting.
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// Errors returned by Service operations
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrInvalid        = errors.New("invalid request")
+	ErrReasonRequired = errors.New("a reason is required (the project sets require_reason)")
+)
+
+// Service reads and changes the comments on markdown documents
+// Every mutating call loads the document's sidecar, checks the project
+// policy for the acting author, and saves before returning. A call whose
+// context is cancelled before the save returns ctx.Err() and writes nothing.
+type Service struct {
+	// Sign signs every new comment and reply with the local signing key,
+	// whether or not the project config sets sign_comments
+	Sign bool
+}
+
+// NewService returns a Service with default settings
+func NewService() *Service {
+	return &Service{}
+}
+
+// AddOptions describes a new comment
+// Give Line (with EndLine for a range) or Section; neither makes a
+// document-level comment.
+type AddOptions struct {
+	Author   string
+	Text     string
+	Type     string // Q, S, B, T, E, or empty; prefixes Text like the CLI
+	Priority string // low, medium (default), high
+	Line     int
+	EndLine  int
+	Section  string
+	Draft    bool   // Save as a private draft instead of sharing it
+	Anchor   string // comment.AnchorLine or comment.AnchorHeading (default: the project config's)
+}
+
+// ReplyOptions describes a reply to a thread
+type ReplyOptions struct {
+	ThreadID string
+	Author   string
+	Text     string
+}
+
+// SuggestOptions describes a suggested edit
+// Give StartLine/EndLine or Section.
+type SuggestOptions struct {
+	Author       string
+	Text         string // Rationale
+	StartLine    int
+	EndLine      int
+	Section      string
+	OriginalText string
+	ProposedText string
+	Insert       bool     // Insert ProposedText after StartLine (0 for the top) instead of replacing lines
+	Move         bool     // Move the lines (or Section) to after MoveAfter instead of replacing them
+	MoveAfter    int      // Line a move goes after (0 for the top)
+	DependsOn    []string // Suggestions that must be accepted first
+	Anchor       string   // comment.AnchorLine or comment.AnchorHeading (default: the project config's)
+}
+
+// DecisionOptions identifies a suggestion to accept or reject and who is
+// deciding; Reason is recorded as a reply when given
+type DecisionOptions struct {
+	SuggestionID string
+	Actor        string
+	Reason       string
+
+	// AllowSelfAccept lets an owner accept their own suggestion when the
+	// project's self_accept rule forbids it
+	AllowSelfAccept bool
+}
+
+// AcceptResult is an accepted suggestion and the document it produced
+type AcceptResult struct {
+	Suggestion *comment.Comment
+	Content    string
+}
+
+// ListFilter narrows List to matching threads; zero values match everything
+type ListFilter struct {
+	Author          string
+	Type            string
+	Search          string // Case-insensitive substring of the text
+	Section         string // Includes nested sections
+	Status          string // active, orphaned, resolved, completed
+	Priority        string
+	StartLine       int // With EndLine, threads overlapping the range
+	EndLine         int
+	IncludeResolved bool
+
+	// Match is any further condition, e.g. built with comment.Or and comment.Not
+	Match comment.Filter
+}
+
+// AddComment adds a root comment to filename and returns it
+func (s *Service) AddComment(ctx context.Context, filename string, opts AddOptions) (*comment.Comment, error) {
+	if strings.TrimSpace(opts.Text) == "" || opts.Author == "" {
+		return nil, fmt.Errorf("%w: a comment needs an author and text", ErrInvalid)
+	}
+	if opts.Line != 0 && opts.Section != "" {
+		return nil, fmt.Errorf("%w: give a line or a section, not both", ErrInvalid)
+	}
+	if opts.EndLine != 0 && opts.EndLine < opts.Line {
+		return nil, fmt.Errorf("%w: range %d-%d ends before it starts", ErrInvalid, opts.Line, opts.EndLine)
+	}
+
+	policy, err := s.check(filename, config.ActionAdd, opts.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	line := opts.Line
+	if opts.Section != "" {
+		if err := comment.ValidateSectionPath(doc, opts.Section); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		if line, _, err = comment.ResolveSectionToLines(doc, opts.Section, false); err != nil {
+			return nil, err
+		}
+	}
+
+	text := opts.Text
+	if opts.Type != "" {
+		text = "[" + opts.Type + "] " + opts.Text
+	}
+	c := comment.NewCommentWithType(opts.Author, line, text, opts.Type)
+	c.Priority = opts.Priority
+	if c.Priority == "" {
+		c.Priority = "medium"
+	}
+	c.Status = "active"
+
+	if opts.EndLine > line {
+		if lineCount := len(strings.Split(doc.Content, "\n")); opts.EndLine > lineCount {
+			return nil, fmt.Errorf("%w: end line %d out of range (document has %d lines)", ErrInvalid, opts.EndLine, lineCount)
+		}
+		c.EndLine = opts.EndLine
+	}
+
+	if err := screen(policy, c, true); err != nil {
+		return nil, err
+	}
+	comment.UpdateCommentSection(c, doc)
+	comment.CaptureQuote(c, doc.Content)
+	if err := anchor(policy, c, doc, opts.Anchor); err != nil {
+		return nil, err
+	}
+	doc.AttachToOpenReview(c)
+	if err := s.sign(policy, c); err != nil {
+		return nil, err
+	}
+
+	if opts.Draft {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return c, comment.AddDraft(filename, c, doc.Content)
+	}
+
+	doc.Threads = append(doc.Threads, c)
+	return c, save(ctx, filename, doc)
+}
+
+// Reply adds a reply to a thread and returns it
+func (s *Service) Reply(ctx context.Context, filename string, opts ReplyOptions) (*comment.Comment, error) {
+	if strings.TrimSpace(opts.Text) == "" || opts.Author == "" {
+		return nil, fmt.Errorf("%w: a reply needs an author and text", ErrInvalid)
+	}
+
+	policy, err := s.check(filename, config.ActionReply, opts.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := doc.FindThreadByID(opts.ThreadID)
+	if thread == nil {
+		return nil, fmt.Errorf("%w: thread %s", ErrNotFound, opts.ThreadID)
+	}
+	if err := comment.AddReplyToThread(doc.Threads, opts.ThreadID, opts.Author, opts.Text); err != nil {
+		return nil, err
+	}
+
+	reply := thread.Replies[len(thread.Replies)-1]
+	if err := screen(policy, reply, false); err != nil {
+		return nil, err
+	}
+	doc.AttachToOpenReview(reply)
+	if err := s.sign(policy, reply); err != nil {
+		return nil, err
+	}
+	return reply, save(ctx, filename, doc)
+}
+
+// Resolve marks a thread resolved
+func (s *Service) Resolve(ctx context.Context, filename, threadID, actor string) error {
+	if _, err := s.check(filename, config.ActionResolve, actor); err != nil {
+		return err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return err
+	}
+	if doc.FindThreadByID(threadID) == nil {
+		return fmt.Errorf("%w: thread %s", ErrNotFound, threadID)
+	}
+	if err := comment.ResolveThread(doc.Threads, threadID); err != nil {
+		return err
+	}
+	return save(ctx, filename, doc)
+}
+
+// Suggest adds a suggested edit and returns it
+func (s *Service) Suggest(ctx context.Context, filename string, opts SuggestOptions) (*comment.Comment, error) {
+	if opts.Author == "" || strings.TrimSpace(opts.Text) == "" || (opts.ProposedText == "") != opts.Move {
+		return nil, fmt.Errorf("%w: a suggestion needs an author, text, and proposed text (and a move no proposed text)", ErrInvalid)
+	}
+	if !opts.Insert && (opts.StartLine == 0) == (opts.Section == "") {
+		return nil, fmt.Errorf("%w: give a line range or a section", ErrInvalid)
+	}
+
+	policy, err := s.check(filename, config.ActionSuggest, opts.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Insert {
+		if opts.Section != "" || opts.EndLine != 0 || opts.OriginalText != "" {
+			return nil, fmt.Errorf("%w: an insertion takes only a line to insert after", ErrInvalid)
+		}
+		if lineCount := len(strings.Split(doc.Content, "\n")); opts.StartLine < 0 || opts.StartLine > lineCount {
+			return nil, fmt.Errorf("%w: cannot insert after line %d (document has %d lines)", ErrInvalid, opts.StartLine, lineCount)
+		}
+	}
+
+	start, end := opts.StartLine, opts.EndLine
+	if opts.Section != "" {
+		if err := comment.ValidateSectionPath(doc, opts.Section); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		if start, end, err = comment.ResolveSectionToLines(doc, opts.Section, false); err != nil {
+			return nil, err
+		}
+	}
+	if end == 0 {
+		end = start
+	}
+	if start > end {
+		return nil, fmt.Errorf("%w: start line (%d) must be <= end line (%d)", ErrInvalid, start, end)
+	}
+
+	var suggestion *comment.Comment
+	if opts.Move {
+		if opts.Insert {
+			return nil, fmt.Errorf("%w: a suggestion can't both insert and move lines", ErrInvalid)
+		}
+		lines := strings.Split(doc.Content, "\n")
+		if err := comment.CheckMove(start, end, opts.MoveAfter, len(lines)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		original := opts.OriginalText
+		if original == "" {
+			original = strings.Join(lines[start-1:end], "\n")
+		}
+		suggestion = comment.NewMove(opts.Author, start, end, opts.MoveAfter, opts.Text, original)
+	} else if opts.Insert {
+		suggestion = comment.NewInsertion(opts.Author, opts.StartLine, opts.Text, opts.ProposedText)
+	} else {
+		suggestion = comment.NewSuggestion(opts.Author, start, end, opts.Text, opts.OriginalText, opts.ProposedText)
+	}
+	suggestion.DependsOn = opts.DependsOn
+	if err := comment.ValidateDependencies(suggestion, doc.Threads); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	if err := screen(policy, suggestion, true); err != nil {
+		return nil, err
+	}
+	comment.UpdateCommentSection(suggestion, doc)
+	if err := anchor(policy, suggestion, doc, opts.Anchor); err != nil {
+		return nil, err
+	}
+	doc.AttachToOpenReview(suggestion)
+	if err := s.sign(policy, suggestion); err != nil {
+		return nil, err
+	}
+
+	doc.Threads = append(doc.Threads, suggestion)
+	return suggestion, save(ctx, filename, doc)
+}
+
+// Accept applies a suggestion to the document and marks it accepted
+func (s *Service) Accept(ctx context.Context, filename string, opts DecisionOptions) (*AcceptResult, error) {
+	policy, err := s.decide(filename, config.ActionAccept, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := doc.FindCommentByID(opts.SuggestionID)
+	if suggestion == nil || !suggestion.IsSuggestion {
+		return nil, fmt.Errorf("%w: suggestion %s", ErrNotFound, opts.SuggestionID)
+	}
+	if err := policy.CheckAccept(doc, suggestion, opts.Actor, opts.AllowSelfAccept); err != nil {
+		return nil, err
+	}
+	if unmet := comment.UnmetDependencies(suggestion, doc.Threads); len(unmet) > 0 {
+		return nil, fmt.Errorf("%w: suggestion %s depends on %s, which has not been accepted", ErrInvalid, opts.SuggestionID, strings.Join(unmet, ", "))
+	}
+
+	content, err := comment.ApplySuggestion(doc.Content, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	doc.Content = content
+	if err := comment.AcceptSuggestion(doc.Threads, opts.SuggestionID); err != nil {
+		return nil, err
+	}
+	if err := s.recordReason(policy, doc, suggestion, opts); err != nil {
+		return nil, err
+	}
+	doc.RecordApplied(suggestion)
+
+	if _, err := comment.BackupSidecar(filename, policy.Backups.Keep); err != nil {
+		return nil, err
+	}
+	if err := save(ctx, filename, doc); err != nil {
+		return nil, err
+	}
+	return &AcceptResult{Suggestion: suggestion, Content: content}, nil
+}
+
+// Reject marks a suggestion rejected and returns it
+func (s *Service) Reject(ctx context.Context, filename string, opts DecisionOptions) (*comment.Comment, error) {
+	policy, err := s.decide(filename, config.ActionReject, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := comment.LoadFromSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := doc.FindCommentByID(opts.SuggestionID)
+	if suggestion == nil || !suggestion.IsSuggestion {
+		return nil, fmt.Errorf("%w: suggestion %s", ErrNotFound, opts.SuggestionID)
+	}
+	if err := comment.RejectSuggestion(doc.Threads, opts.SuggestionID); err != nil {
+		return nil, err
+	}
+	if err := s.recordReason(policy, doc, suggestion, opts); err != nil {
+		return nil, err
+	}
+	return suggestion, save(ctx, filename, doc)
+}
+
+// List returns the threads on filename that match filter, in line order
+func (s *Service) List(ctx context.Context, filename string, filter ListFilter) ([]*comment.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	doc, err := comment.ReadSidecar(filename)
+	if err != nil {
+		return nil, err
+	}
+	comment.ComputeSectionsForComments(doc)
+
+	match, err := filter.compile(doc)
+	if err != nil {
+		return nil, err
+	}
+	return match.Apply(comment.GetVisibleComments(doc.Threads, filter.IncludeResolved)), nil
+}
+
+// compile turns the set fields into one comment.Filter for doc
+func (f ListFilter) compile(doc *comment.DocumentWithComments) (comment.Filter, error) {
+	filters := []comment.Filter{f.Match}
+	if f.Author != "" {
+		filters = append(filters, comment.ByAuthor(f.Author))
+	}
+	if f.Type != "" {
+		filters = append(filters, comment.ByType(f.Type))
+	}
+	if f.Search != "" {
+		filters = append(filters, comment.BySearch(f.Search))
+	}
+	if f.Section != "" {
+		inSection, err := comment.InSection(doc, f.Section)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		filters = append(filters, inSection)
+	}
+	if f.Status != "" {
+		filters = append(filters, comment.ByStatus(f.Status))
+	}
+	if f.Priority != "" {
+		filters = append(filters, comment.ByPriority(f.Priority))
+	}
+	if f.StartLine > 0 || f.EndLine > 0 {
+		filters = append(filters, comment.ByLineRange(f.StartLine, f.EndLine))
+	}
+	return comment.And(filters...), nil
+}
+
+// save writes doc unless ctx has been cancelled, so an abandoned request
+// changes nothing
+func save(ctx context.Context, filename string, doc *comment.DocumentWithComments) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return comment.SaveToSidecar(filename, doc)
+}
+
+// check loads the project policy for filename and checks that actor may
+// perform action
+func (s *Service) check(filename, action, actor string) (*config.Config, error) {
+	policy, err := config.LoadForDocument(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Check(action, actor); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// screen runs c through the project's content filter and lint rules,
+// before it is signed since the filter may change its text
+func screen(policy *config.Config, c *comment.Comment, thread bool) error {
+	if err := policy.Screen(c, thread); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return nil
+}
+
+// anchor anchors a new thread as mode asks, or else as the project config
+// does; threads the config can't anchor to a heading stay on their line
+func anchor(policy *config.Config, c *comment.Comment, doc *comment.DocumentWithComments, mode string) error {
+	if mode == "" {
+		_ = comment.AnchorComment(c, doc, policy.Anchor)
+		return nil
+	}
+	if err := comment.AnchorComment(c, doc, mode); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+// decide checks the policy for accepting or rejecting a suggestion,
+// including whether a reason is required
+func (s *Service) decide(filename, action string, opts DecisionOptions) (*config.Config, error) {
+	policy, err := s.check(filename, action, opts.Actor)
+	if err != nil {
+		return nil, err
+	}
+	if policy.RequireReason && strings.TrimSpace(opts.Reason) == "" {
+		return nil, ErrReasonRequired
+	}
+	return policy, nil
+}
+
+// recordReason adds the decision's reason as a reply by the actor
+func (s *Service) recordReason(policy *config.Config, doc *comment.DocumentWithComments, suggestion *comment.Comment, opts DecisionOptions) error {
+	if strings.TrimSpace(opts.Reason) == "" {
+		return nil
+	}
+	reply := comment.AddDecisionReply(suggestion, opts.Actor, opts.Reason)
+	doc.AttachToOpenReview(reply)
+	return s.sign(policy, reply)
+}
+
+// sign signs c when the Service or the project config asks for signatures
+func (s *Service) sign(policy *config.Config, c *comment.Comment) error {
+	if !s.Sign && !policy.SignComments {
+		return nil
+	}
+	path, err := config.DefaultKeyPath()
+	if err != nil {
+		return err
+	}
+	key, err := config.LoadSigningKey(path)
+	if err != nil {
+		return err
+	}
+	comment.SignComment(c, key)
+	return nil
+}
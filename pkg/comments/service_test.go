@@ -0,0 +1,229 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+const testDocument = "# Guide\n\n## Setup\n\nInstall the tool.\nRun it once.\n\n## Usage\n\nCall it daily."
+
+func setupDocument(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "guide.md")
+	if err := os.WriteFile(path, []byte(testDocument), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestServiceCommentLifecycle(t *testing.T) {
+	path := setupDocument(t)
+	svc := NewService()
+	ctx := context.Background()
+
+	c, err := svc.AddComment(ctx, path, AddOptions{Author: "alice", Section: "Guide > Usage", Text: "How often?", Type: "Q"})
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if c.Line != 8 || c.Text != "[Q] How often?" || c.SectionPath != "Guide > Usage" {
+		t.Errorf("Unexpected comment: line %d, %q, %q", c.Line, c.Text, c.SectionPath)
+	}
+
+	if _, err := svc.Reply(ctx, path, ReplyOptions{ThreadID: c.ID, Author: "bob", Text: "Daily"}); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+	if _, err := svc.Reply(ctx, path, ReplyOptions{ThreadID: "missing", Author: "bob", Text: "?"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing thread, got %v", err)
+	}
+
+	open, err := svc.List(ctx, path, ListFilter{Type: "Q"})
+	if err != nil || len(open) != 1 || len(open[0].Replies) != 1 {
+		t.Fatalf("Expected the question with its reply, got %v (%v)", open, err)
+	}
+
+	if err := svc.Resolve(ctx, path, c.ID, "alice"); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if open, _ := svc.List(ctx, path, ListFilter{}); len(open) != 0 {
+		t.Errorf("Resolved threads should be hidden by default, got %d", len(open))
+	}
+	if all, _ := svc.List(ctx, path, ListFilter{IncludeResolved: true, Author: "alice"}); len(all) != 1 {
+		t.Errorf("Expected the resolved thread with IncludeResolved, got %d", len(all))
+	}
+	if others, _ := svc.List(ctx, path, ListFilter{IncludeResolved: true, Match: comment.Not(comment.ByAuthor("alice"))}); len(others) != 0 {
+		t.Errorf("Expected Match to exclude alice's thread, got %d", len(others))
+	}
+}
+
+func TestServiceSuggestions(t *testing.T) {
+	path := setupDocument(t)
+	svc := NewService()
+	ctx := context.Background()
+
+	s, err := svc.Suggest(ctx, path, SuggestOptions{Author: "claude", StartLine: 5, Text: "Be specific", OriginalText: "Install the tool.", ProposedText: "Install the tool with go install."})
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+
+	result, err := svc.Accept(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "alice", Reason: "Clearer"})
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if !result.Suggestion.IsAccepted() || comment.DecisionReply(result.Suggestion) == nil {
+		t.Error("Expected the suggestion accepted with its reason recorded")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != result.Content || result.Content == testDocument {
+		t.Error("Expected the accepted text written to the document")
+	}
+
+	if _, err := svc.Reject(ctx, path, DecisionOptions{SuggestionID: "nope", Actor: "alice"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestServiceMoveSuggestion(t *testing.T) {
+	path := setupDocument(t)
+	svc := NewService()
+	ctx := context.Background()
+
+	c, err := svc.AddComment(ctx, path, AddOptions{Author: "alice", Line: 6, Text: "Once per machine?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Suggest(ctx, path, SuggestOptions{Author: "claude", Section: "Guide > Setup", Move: true, MoveAfter: 10, Text: "Usage first", ProposedText: "x"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a move with proposed text, got %v", err)
+	}
+	s, err := svc.Suggest(ctx, path, SuggestOptions{Author: "claude", Section: "Guide > Setup", Move: true, MoveAfter: 10, Text: "Usage first"})
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+
+	result, err := svc.Accept(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "alice"})
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	want := "# Guide\n\n## Usage\n\nCall it daily.\n## Setup\n\nInstall the tool.\nRun it once.\n"
+	if result.Content != want {
+		t.Errorf("Content after the move:\n%q\nwant:\n%q", result.Content, want)
+	}
+
+	threads, err := svc.List(ctx, path, ListFilter{Author: "alice"})
+	if err != nil || len(threads) != 1 {
+		t.Fatalf("Expected alice's comment, got %v (%v)", threads, err)
+	}
+	if threads[0].ID != c.ID || threads[0].Line != 9 || threads[0].SectionPath != "Guide > Setup" {
+		t.Errorf("Comment at line %d in %q, want it to move with its line to 9 in Guide > Setup", threads[0].Line, threads[0].SectionPath)
+	}
+}
+
+func TestServiceEnforcesPolicy(t *testing.T) {
+	path := setupDocument(t)
+	cfg := `{"require_reason": true, "agents": ["claude"], "permissions": {"accept": ["human"]}}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), config.FileName), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService()
+	ctx := context.Background()
+
+	s, err := svc.Suggest(ctx, path, SuggestOptions{Author: "claude", StartLine: 6, Text: "Shorter", OriginalText: "Run it once.", ProposedText: "Run it."})
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if _, err := svc.Reject(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "alice"}); !errors.Is(err, ErrReasonRequired) {
+		t.Errorf("Expected ErrReasonRequired, got %v", err)
+	}
+	var denied *config.PermissionError
+	if _, err := svc.Accept(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "claude", Reason: "Mine"}); !errors.As(err, &denied) {
+		t.Errorf("Expected a permission error for the agent, got %v", err)
+	}
+}
+
+func TestServiceScreensText(t *testing.T) {
+	path := setupDocument(t)
+	cfg := `{"lint": {"max_length": 30}, "content_filter": {"rules": [{"pattern": "hunter2", "replacement": "[redacted]"}]}}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), config.FileName), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService()
+	ctx := context.Background()
+
+	c, err := svc.AddComment(ctx, path, AddOptions{Author: "alice", Line: 5, Text: "Password is hunter2"})
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if c.Text != "Password is [redacted]" {
+		t.Errorf("Expected the filtered text, got %q", c.Text)
+	}
+
+	var lint *config.LintError
+	if _, err := svc.Reply(ctx, path, ReplyOptions{ThreadID: c.ID, Author: "bob", Text: "This reply runs well past the limit"}); !errors.Is(err, ErrInvalid) || !errors.As(err, &lint) {
+		t.Errorf("Expected a lint error, got %v", err)
+	}
+	doc, err := comment.LoadFromSidecar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replies := doc.FindThreadByID(c.ID).Replies; len(replies) != 0 {
+		t.Errorf("A refused reply should not be saved, got %d", len(replies))
+	}
+}
+
+func TestServiceUsesProjectSidecarLocation(t *testing.T) {
+	path := setupDocument(t)
+	dir := filepath.Dir(path)
+	if err := os.WriteFile(filepath.Join(dir, config.FileName), []byte(`{"sidecars": {"layout": "mirrored"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewService().AddComment(context.Background(), path, AddOptions{Author: "alice", Line: 5, Text: "Which tool?"}); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, config.DefaultSidecarDir, "guide.md.comments.json")); err != nil {
+		t.Errorf("Expected the sidecar in the mirrored directory: %v", err)
+	}
+	if _, err := os.Stat(path + ".comments.json"); !os.IsNotExist(err) {
+		t.Error("Expected no sidecar next to the document")
+	}
+}
+
+func TestServiceSelfAccept(t *testing.T) {
+	path := setupDocument(t)
+	cfg := `{"owners": ["alice"], "self_accept": "deny"}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), config.FileName), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService()
+	ctx := context.Background()
+
+	s, err := svc.Suggest(ctx, path, SuggestOptions{Author: "alice", StartLine: 6, Text: "Shorter", OriginalText: "Run it once.", ProposedText: "Run it."})
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	var denied *config.SelfAcceptError
+	if _, err := svc.Accept(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "alice"}); !errors.As(err, &denied) {
+		t.Errorf("Expected a self-acceptance error, got %v", err)
+	}
+	if _, err := svc.Accept(ctx, path, DecisionOptions{SuggestionID: s.ID, Actor: "alice", AllowSelfAccept: true}); err != nil {
+		t.Errorf("Owner override failed: %v", err)
+	}
+}
+
+func TestServiceCancelledContextWritesNothing(t *testing.T) {
+	path := setupDocument(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewService().AddComment(ctx, path, AddOptions{Author: "alice", Line: 5, Text: "Too late"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if comment.SidecarExists(path) {
+		t.Error("A cancelled call should not write a sidecar")
+	}
+}
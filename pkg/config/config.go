@@ -0,0 +1,344 @@
+// Package config loads per-project settings for the comments tool.
+//
+// Settings live in a JSON file named .comments.config.json. The file is looked up
+// starting from a document's directory and walking towards the filesystem root,
+// so one config at the repository root covers every document below it.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// FileName is the name of the project config file
+const FileName = ".comments.config.json"
+
+// EnvReadOnly forces read-only mode when set to "1" or "true"
+const EnvReadOnly = "COMMENTS_READ_ONLY"
+
+// Config holds project-level settings
+type Config struct {
+	// ReadOnly disables every change to comments and documents
+	ReadOnly bool `json:"read_only"`
+
+	// Owners are the document owners (role "owner")
+	Owners []string `json:"owners"`
+
+	// Agents are authors that represent automated agents (role "agent")
+	// Any author that is neither an owner nor an agent has role "human".
+	Agents []string `json:"agents"`
+
+	// Permissions maps an action to the roles or author names allowed to perform it
+	// Actions without an entry are allowed for everyone.
+	Permissions map[string][]string `json:"permissions"`
+
+	// SignComments signs every new comment with the local signing key
+	SignComments bool `json:"sign_comments"`
+
+	// TrustedKeys maps author names to the public keys (base64) allowed to sign for them
+	TrustedKeys map[string][]string `json:"trusted_keys"`
+
+	// Retention lists the rules applied by `comments cleanup --apply-policy`
+	Retention []RetentionRule `json:"retention"`
+
+	// Dedupe controls how batch-add handles near-duplicates of open comments
+	Dedupe DedupeConfig `json:"dedupe"`
+
+	// RequireReason makes a reason mandatory when accepting or rejecting suggestions
+	RequireReason bool `json:"require_reason"`
+
+	// SectionOwners are the required reviewers of sections, checked by
+	// `comments check`; the last rule matching a section applies
+	SectionOwners []SectionOwnerRule `json:"section_owners"`
+
+	// SelfAccept controls whether a suggestion's author may accept it:
+	// SelfAcceptAllow (default), SelfAcceptDeny, or SelfAcceptApproved
+	SelfAccept string `json:"self_accept"`
+
+	// Backups controls the sidecar copies kept before destructive saves
+	Backups BackupConfig `json:"backups"`
+
+	// Orphans gives comments past the end of a document that got shorter a
+	// grace period before they are orphaned
+	Orphans OrphanConfig `json:"orphans"`
+
+	// StableLastValidated updates a sidecar's lastValidated only when the
+	// document content changes, for quieter git diffs
+	StableLastValidated bool `json:"stable_last_validated"`
+
+	// Sidecars controls where sidecar files are kept and how they are named
+	Sidecars SidecarConfig `json:"sidecars"`
+
+	// Redaction removes internal chatter from `export` and `publish` output
+	Redaction RedactionConfig `json:"redaction"`
+
+	// Lint limits comment text and requires types from some authors
+	Lint LintConfig `json:"lint"`
+
+	// ContentFilter redacts or rejects what new comments may not store
+	ContentFilter ContentFilterConfig `json:"content_filter"`
+
+	// Anchor is how new comments are attached when no --anchor is given:
+	// comment.AnchorLine (default) or comment.AnchorHeading
+	Anchor string `json:"anchor"`
+
+	// Path is the file the config was loaded from (empty when using defaults)
+	Path string `json:"-"`
+}
+
+// SaveOptions returns the sidecar write and load options the config asks for
+func (c *Config) SaveOptions() comment.SaveOptions {
+	if c == nil {
+		return comment.SaveOptions{}
+	}
+	return comment.SaveOptions{
+		StableLastValidated: c.StableLastValidated,
+		AutoRestore:         c.Backups.AutoRestore,
+		Drift: comment.DriftPolicy{
+			Grace:    c.Orphans.Grace,
+			MaxDrift: c.Orphans.MaxDrift,
+		},
+	}
+}
+
+// Sidecar layouts
+const (
+	LayoutAdjacent = "adjacent" // Next to each markdown file (default)
+	LayoutMirrored = "mirrored" // In one directory mirroring the document tree
+)
+
+// DefaultSidecarDir is where the mirrored layout keeps sidecars unless the
+// config names another directory
+const DefaultSidecarDir = ".comments"
+
+// SidecarConfig controls sidecar placement, for repositories that don't
+// allow extra files next to their docs
+type SidecarConfig struct {
+	// Layout is LayoutAdjacent or LayoutMirrored
+	Layout string `json:"layout,omitempty"`
+
+	// Dir is the mirrored layout's directory, relative to the config file
+	// (default: DefaultSidecarDir)
+	Dir string `json:"dir,omitempty"`
+
+	// Suffix replaces ".comments.json" in sidecar file names
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// SidecarLocation returns where the config asks for sidecars to be kept
+// Paths in the mirrored layout are relative to the config file's directory.
+func (c *Config) SidecarLocation() comment.SidecarLocation {
+	if c == nil {
+		return comment.SidecarLocation{}
+	}
+	loc := comment.SidecarLocation{Suffix: c.Sidecars.Suffix}
+	if c.Sidecars.Layout == LayoutMirrored {
+		loc.Root = filepath.Dir(c.Path)
+		loc.Dir = c.Sidecars.Dir
+		if loc.Dir == "" {
+			loc.Dir = DefaultSidecarDir
+		}
+	}
+	return loc
+}
+
+// Settings returns what the config decides for the documents it covers
+func (c *Config) Settings() comment.Settings {
+	return comment.Settings{Save: c.SaveOptions(), Location: c.SidecarLocation()}
+}
+
+// SettingsFor is the comment.SettingsFunc that takes each document's
+// settings from the project config covering its directory. Packages that
+// import config get it by default; a config that doesn't load gives the
+// defaults, and commands that check the policy report it. Settings are
+// cached per directory for settingsTTL, so a long-running editor session
+// picks up config edits without reloading the config on every operation.
+func SettingsFor(dir string) comment.Settings {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	settingsCacheMu.Lock()
+	defer settingsCacheMu.Unlock()
+	if cached, ok := settingsCache[dir]; ok && time.Since(cached.loaded) < settingsTTL {
+		return cached.settings
+	}
+
+	var settings comment.Settings
+	if cfg, err := Load(dir); err == nil {
+		settings = cfg.Settings()
+	}
+	settingsCache[dir] = cachedSettings{settings: settings, loaded: time.Now()}
+	return settings
+}
+
+// settingsTTL is how long SettingsFor reuses the settings it loaded
+const settingsTTL = 2 * time.Second
+
+// cachedSettings are the settings SettingsFor loaded for a directory
+type cachedSettings struct {
+	settings comment.Settings
+	loaded   time.Time
+}
+
+var (
+	settingsCacheMu sync.Mutex
+	settingsCache   = map[string]cachedSettings{}
+)
+
+func init() {
+	comment.SetSettingsFunc(SettingsFor)
+}
+
+// BackupConfig controls sidecar backups
+type BackupConfig struct {
+	// Keep is how many backups to keep per document; before accepting
+	// suggestions, cleanup, or a restore the sidecar is copied and older
+	// backups beyond Keep are pruned. Zero disables automatic backups.
+	Keep int `json:"keep,omitempty"`
+
+	// AutoRestore puts back the newest backup written against a document's
+	// current content when its sidecar is missing, instead of only pointing
+	// the backup out (the --auto-restore flag does the same for one command)
+	AutoRestore bool `json:"auto_restore,omitempty"`
+}
+
+// OrphanConfig controls what validation does with comments whose line is
+// past the end of the document
+type OrphanConfig struct {
+	// Grace keeps such comments on the last line, marked drifted, until the
+	// document has changed this many times; zero orphans them at once
+	Grace int `json:"grace,omitempty"`
+
+	// MaxDrift orphans at once comments more than this many lines past the
+	// end, whatever the grace; zero means no limit
+	MaxDrift int `json:"max_drift,omitempty"`
+}
+
+// Load finds and parses the project config, starting at dir and walking up
+// Returns a default (allow everything) config when no file exists.
+func Load(dir string) (*Config, error) {
+	cfg := &Config{}
+
+	path, err := find(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+		cfg.Path = path
+
+		if err := cfg.validate(); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	if v := strings.ToLower(os.Getenv(EnvReadOnly)); v == "1" || v == "true" {
+		cfg.ReadOnly = true
+	}
+
+	return cfg, nil
+}
+
+// LoadForDocument loads the project config that applies to a markdown file
+func LoadForDocument(mdPath string) (*Config, error) {
+	return Load(filepath.Dir(mdPath))
+}
+
+// find returns the path of the nearest config file, or "" if there is none
+func find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// validate checks permission, retention, dedupe, backup, orphan, sidecar, section owner, self-acceptance, lint, and redaction rules
+func (c *Config) validate() error {
+	for action := range c.Permissions {
+		if !isKnownAction(action) {
+			return fmt.Errorf("unknown action %q in permissions (valid: %s)", action, strings.Join(Actions, ", "))
+		}
+	}
+	for i, rule := range c.Retention {
+		if _, err := rule.Criteria(); err != nil {
+			return fmt.Errorf("retention rule %d: %w", i+1, err)
+		}
+	}
+	if err := c.Dedupe.Policy().Validate(); err != nil {
+		return fmt.Errorf("dedupe: %w", err)
+	}
+	if c.Backups.Keep < 0 {
+		return fmt.Errorf("backups: keep must be zero or greater, got %d", c.Backups.Keep)
+	}
+	if c.Orphans.Grace < 0 || c.Orphans.MaxDrift < 0 {
+		return fmt.Errorf("orphans: grace and max_drift must be zero or greater")
+	}
+	switch c.Sidecars.Layout {
+	case "", LayoutAdjacent, LayoutMirrored:
+	default:
+		return fmt.Errorf("sidecars: unknown layout %q (valid: %s, %s)", c.Sidecars.Layout, LayoutAdjacent, LayoutMirrored)
+	}
+	if c.Sidecars.Dir != "" && c.Sidecars.Layout != LayoutMirrored {
+		return fmt.Errorf("sidecars: dir is only used with layout %q", LayoutMirrored)
+	}
+	if c.Sidecars.Suffix != "" {
+		if err := comment.ValidateSidecarSuffix(c.Sidecars.Suffix); err != nil {
+			return fmt.Errorf("sidecars: %w", err)
+		}
+	}
+	for i, rule := range c.SectionOwners {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("section_owners rule %d: %w", i+1, err)
+		}
+	}
+	switch c.SelfAccept {
+	case "", SelfAcceptAllow, SelfAcceptDeny, SelfAcceptApproved:
+	default:
+		return fmt.Errorf("unknown self_accept %q (valid: %s, %s, %s)", c.SelfAccept, SelfAcceptAllow, SelfAcceptDeny, SelfAcceptApproved)
+	}
+	if err := c.Lint.validate(); err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+	if err := c.ContentFilter.validate(); err != nil {
+		return fmt.Errorf("content_filter: %w", err)
+	}
+	switch c.Anchor {
+	case "", comment.AnchorLine, comment.AnchorHeading:
+	default:
+		return fmt.Errorf("unknown anchor %q (valid: %s, %s)", c.Anchor, comment.AnchorLine, comment.AnchorHeading)
+	}
+	for _, label := range c.Redaction.Labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("redaction: labels must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,517 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestLoadDefaultsWhenMissing(t *testing.T) {
+	t.Setenv(EnvReadOnly, "")
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Path != "" || cfg.ReadOnly {
+		t.Errorf("Expected default config, got %+v", cfg)
+	}
+	if err := cfg.Check(ActionAccept, "anyone"); err != nil {
+		t.Errorf("Default config should allow everything: %v", err)
+	}
+}
+
+func TestLoadSearchesParentDirectories(t *testing.T) {
+	t.Setenv(EnvReadOnly, "")
+	root := t.TempDir()
+	nested := filepath.Join(root, "docs", "guides")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, root, `{"owners": ["alice"]}`)
+
+	cfg, err := LoadForDocument(filepath.Join(nested, "doc.md"))
+	if err != nil {
+		t.Fatalf("LoadForDocument failed: %v", err)
+	}
+	if cfg.Path != filepath.Join(root, FileName) {
+		t.Errorf("Path = %q, want config from root", cfg.Path)
+	}
+	if cfg.Role("Alice") != RoleOwner {
+		t.Errorf("Expected alice to be owner")
+	}
+}
+
+func TestLoadRejectsUnknownAction(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"permissions": {"delete": ["owner"]}}`)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	cfg := &Config{
+		Owners: []string{"alice"},
+		Agents: []string{"claude"},
+		Permissions: map[string][]string{
+			ActionAccept:  {RoleOwner},
+			ActionResolve: {RoleOwner, RoleHuman},
+			ActionReply:   {RoleAnyone},
+			ActionReject:  {"bob"},
+		},
+	}
+
+	tests := []struct {
+		action string
+		author string
+		allow  bool
+	}{
+		{ActionAccept, "alice", true},
+		{ActionAccept, "bob", false},
+		{ActionAccept, "claude", false},
+		{ActionResolve, "bob", true},
+		{ActionResolve, "claude", false},
+		{ActionReply, "claude", true},
+		{ActionReject, "bob", true},
+		{ActionReject, "alice", false},
+		{ActionAdd, "claude", true}, // Unrestricted action
+	}
+
+	for _, tt := range tests {
+		err := cfg.Check(tt.action, tt.author)
+		if tt.allow && err != nil {
+			t.Errorf("Check(%s, %s) denied: %v", tt.action, tt.author, err)
+		}
+		if !tt.allow {
+			var permErr *PermissionError
+			if !errors.As(err, &permErr) {
+				t.Errorf("Check(%s, %s) = %v, want PermissionError", tt.action, tt.author, err)
+			}
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvReadOnly, "1")
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Check(ActionAdd, "alice"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestSigningKeyRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "signing_key")
+	t.Setenv(EnvSigningKey, path)
+
+	generated, err := GenerateSigningKey(path)
+	if err != nil {
+		t.Fatalf("GenerateSigningKey failed: %v", err)
+	}
+
+	cfg := &Config{SignComments: true}
+	loaded, err := cfg.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey failed: %v", err)
+	}
+	if PublicKeyString(loaded) != PublicKeyString(generated) {
+		t.Error("Loaded key does not match generated key")
+	}
+
+	// Signing disabled yields no key
+	if key, err := (&Config{}).SigningKey(); key != nil || err != nil {
+		t.Errorf("Expected no key when signing is disabled, got %v, %v", key, err)
+	}
+}
+
+func TestRetentionRules(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"retention": [{"status": "resolved", "author": "bot", "older_than": "30d"}]}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	criteria, err := cfg.Retention[0].Criteria()
+	if err != nil {
+		t.Fatalf("Criteria failed: %v", err)
+	}
+	if criteria.Author != "bot" || criteria.OlderThan.Hours() != 30*24 {
+		t.Errorf("Unexpected criteria: %+v", criteria)
+	}
+
+	writeConfig(t, dir, `{"retention": [{"status": "active"}]}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for invalid retention status")
+	}
+}
+
+func TestDedupeConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if p := cfg.Dedupe.Policy(); p.Mode != comment.DedupeSkip || p.Threshold != comment.DefaultDuplicateThreshold {
+		t.Errorf("Expected default dedupe policy, got %+v", p)
+	}
+
+	writeConfig(t, dir, `{"dedupe": {"mode": "merge", "threshold": 0.8}}`)
+	cfg, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if p := cfg.Dedupe.Policy(); p.Mode != comment.DedupeMerge || p.Threshold != 0.8 {
+		t.Errorf("Unexpected dedupe policy: %+v", p)
+	}
+
+	writeConfig(t, dir, `{"dedupe": {"mode": "drop"}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for invalid dedupe mode")
+	}
+}
+
+func TestBackupConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"backups": {"keep": 3}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Backups.Keep != 3 {
+		t.Errorf("Expected keep 3, got %d", cfg.Backups.Keep)
+	}
+
+	writeConfig(t, dir, `{"backups": {"keep": -1}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for negative keep")
+	}
+}
+
+func TestOrphanConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"orphans": {"grace": 2, "max_drift": 10}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if drift := cfg.SaveOptions().Drift; drift.Grace != 2 || drift.MaxDrift != 10 {
+		t.Errorf("Expected grace 2 and max drift 10, got %+v", drift)
+	}
+
+	writeConfig(t, dir, `{"orphans": {"grace": -1}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for negative grace")
+	}
+}
+
+func TestSidecarConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"sidecars": {"layout": "mirrored", "suffix": ".review.json"}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	loc := cfg.SidecarLocation()
+	if loc.Dir != DefaultSidecarDir || loc.Root != dir || loc.Suffix != ".review.json" {
+		t.Errorf("Unexpected location %+v", loc)
+	}
+
+	// The default config keeps sidecars next to each file
+	if loc := (&Config{}).SidecarLocation(); loc.Dir != "" || loc.Suffix != "" {
+		t.Errorf("Expected the adjacent layout by default, got %+v", loc)
+	}
+
+	for _, bad := range []string{
+		`{"sidecars": {"layout": "flat"}}`,
+		`{"sidecars": {"dir": "reviews"}}`,
+		`{"sidecars": {"suffix": "comments.json"}}`,
+		`{"sidecars": {"suffix": ".x/y.json"}}`,
+	} {
+		writeConfig(t, dir, bad)
+		if _, err := Load(dir); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+}
+
+func TestRedactionConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"redaction": {"authors": ["bot"], "labels": ["#internal"], "rejected_suggestions": true}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	rules := cfg.Redaction.Rules()
+	if len(rules.Authors) != 1 || rules.Authors[0] != "bot" || len(rules.Labels) != 1 || !rules.RejectedSuggestions {
+		t.Errorf("Unexpected redaction rules: %+v", rules)
+	}
+
+	writeConfig(t, dir, `{"redaction": {"labels": [" "]}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for an empty label")
+	}
+}
+
+func TestAnchorConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"anchor": "heading"}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Anchor != comment.AnchorHeading {
+		t.Errorf("Anchor = %q, want heading", cfg.Anchor)
+	}
+
+	writeConfig(t, dir, `{"anchor": "paragraph"}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for an unknown anchor mode")
+	}
+}
+
+func TestCheckAcceptSelfAccept(t *testing.T) {
+	doc := &comment.DocumentWithComments{}
+	suggestion := comment.NewSuggestion("claude", 1, 1, "Fix", "a", "b")
+
+	var nilConfig *Config
+	if err := nilConfig.CheckAccept(doc, suggestion, "claude", false); err != nil {
+		t.Errorf("A nil config denied self-acceptance: %v", err)
+	}
+
+	cfg := &Config{Owners: []string{"alice"}, SelfAccept: SelfAcceptDeny}
+	if err := cfg.CheckAccept(doc, suggestion, "bob", false); err != nil {
+		t.Errorf("Another author was denied: %v", err)
+	}
+	var selfErr *SelfAcceptError
+	if err := cfg.CheckAccept(doc, suggestion, "Claude", false); !errors.As(err, &selfErr) {
+		t.Errorf("CheckAccept by the author = %v, want SelfAcceptError", err)
+	}
+	if err := cfg.CheckAccept(doc, suggestion, "claude", true); err == nil {
+		t.Error("A non-owner overrode the rule")
+	}
+	own := comment.NewSuggestion("alice", 1, 1, "Fix", "a", "b")
+	if err := cfg.CheckAccept(doc, own, "alice", true); err != nil {
+		t.Errorf("An owner could not override the rule: %v", err)
+	}
+
+	cfg.SelfAccept = SelfAcceptApproved
+	if err := cfg.CheckAccept(doc, suggestion, "claude", false); err == nil {
+		t.Error("Expected the author to need someone else's approval")
+	}
+	review := comment.NewReview("bob")
+	if err := review.Submit(comment.VerdictApprove, "", suggestion.Timestamp.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	doc.Reviews = append(doc.Reviews, review)
+	if err := cfg.CheckAccept(doc, suggestion, "claude", false); err != nil {
+		t.Errorf("Denied after bob approved: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"self_accept": "sometimes"}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for an unknown self_accept rule")
+	}
+}
+
+func TestSectionOwners(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"section_owners": [
+		{"section": "API", "owners": ["alice"]},
+		{"section": "API > Billing*", "owners": ["bob"]}
+	]}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.SectionOwnersOf("API > Auth"); len(got) != 1 || got[0] != "alice" {
+		t.Errorf("Owners of API > Auth = %v, want alice", got)
+	}
+	if got := cfg.SectionOwnersOf("API > Billing > Refunds"); len(got) != 1 || got[0] != "bob" {
+		t.Errorf("Owners of API > Billing > Refunds = %v, want bob (the last matching rule)", got)
+	}
+	if got := cfg.SectionOwnersOf("Guide"); len(got) != 0 {
+		t.Errorf("Owners of Guide = %v, want none", got)
+	}
+
+	writeConfig(t, dir, `{"section_owners": [{"section": "API"}]}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for a rule without owners")
+	}
+}
+
+func TestAnswerer(t *testing.T) {
+	var none *Config
+	if none.Answerer() != nil || (&Config{}).Answerer() != nil {
+		t.Error("Without owners anyone may answer (nil)")
+	}
+
+	isOwner := (&Config{Owners: []string{"alice"}, Agents: []string{"claude"}}).Answerer()
+	if !isOwner("Alice") || isOwner("claude") || isOwner("bob") {
+		t.Error("Only owners should answer questions")
+	}
+}
+
+func TestLintComment(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"agents": ["claude"], "lint": {"max_length": 10, "forbidden_chars": "<>", "require_type": ["agent"]}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	rules := func(issues []LintIssue) []string {
+		var names []string
+		for _, issue := range issues {
+			names = append(names, issue.Rule)
+		}
+		return names
+	}
+
+	if got := cfg.LintComment(comment.NewComment("alice", 1, "Fine"), true); len(got) != 0 {
+		t.Errorf("Expected no issues, got %v", rules(got))
+	}
+	agent := comment.NewComment("claude", 1, "Way too long <b>")
+	if got := rules(cfg.LintComment(agent, true)); len(got) != 3 || got[0] != LintMaxLength || got[1] != LintForbiddenChars || got[2] != LintRequireType {
+		t.Errorf("Expected max_length, forbidden_chars, require_type, got %v", got)
+	}
+	agent.Text, agent.Type = "Typed", "Q"
+	if got := cfg.LintComment(agent, true); len(got) != 0 {
+		t.Errorf("Expected no issues for a typed agent comment, got %v", rules(got))
+	}
+
+	// Replies don't need a type
+	reply := comment.NewReply("claude", "Ok", agent)
+	agent.Replies = []*comment.Comment{reply}
+	doc := &comment.DocumentWithComments{Threads: []*comment.Comment{agent}}
+	if got := cfg.LintDocument(doc); len(got) != 0 {
+		t.Errorf("Expected no issues for an untyped reply, got %v", rules(got))
+	}
+
+	var none *Config
+	if got := none.LintComment(comment.NewComment("claude", 1, "<<<<<<<<<<<<<<"), true); len(got) != 0 {
+		t.Errorf("A nil config should have no rules, got %v", rules(got))
+	}
+
+	writeConfig(t, dir, `{"lint": {"max_length": -1}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for a negative max_length")
+	}
+}
+
+func TestFilterContent(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"content_filter": {"rules": [
+		{"pattern": "\\b\\d{3}-\\d{2}-\\d{4}\\b"},
+		{"name": "profanity", "pattern": "(?i)\\bdarn\\b", "action": "reject"},
+		{"pattern": "[\\w.]+@example\\.com", "replacement": "<email>"}
+	]}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	got, err := cfg.FilterContent("alice", "SSN 123-45-6789, ask bob@example.com")
+	if err != nil {
+		t.Fatalf("FilterContent failed: %v", err)
+	}
+	if want := "SSN [redacted], ask <email>"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	_, err = cfg.FilterContent("alice", "Darn typo")
+	var rejection *comment.FilterRejection
+	if !errors.As(err, &rejection) || rejection.Rule != "profanity" {
+		t.Errorf("Expected a rejection by profanity, got %v", err)
+	}
+
+	var none *Config
+	if got, err := none.FilterContent("alice", "darn"); err != nil || got != "darn" {
+		t.Errorf("A nil config should filter nothing, got %q, %v", got, err)
+	}
+
+	writeConfig(t, dir, `{"content_filter": {"rules": [{"pattern": "(", "action": "reject"}]}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	writeConfig(t, dir, `{"content_filter": {"rules": [{"pattern": "x", "action": "drop"}]}}`)
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an unknown action to be rejected")
+	}
+}
+
+func TestFilterContentCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "filter.sh")
+	body := "#!/bin/sh\ntext=$(cat)\ncase \"$text\" in\n*secret*) echo \"no secrets, $COMMENTS_FILTER_AUTHOR\" >&2; exit 1;;\nesac\necho \"$text\" | tr a-z A-Z\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, dir, `{"content_filter": {"command": "./filter.sh"}}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if got, err := cfg.FilterContent("alice", "looks good"); err != nil || got != "LOOKS GOOD" {
+		t.Errorf("Expected the command's output, got %q, %v", got, err)
+	}
+	_, err = cfg.FilterContent("alice", "the secret is out")
+	var rejection *comment.FilterRejection
+	if !errors.As(err, &rejection) || rejection.Reason != "no secrets, alice" {
+		t.Errorf("Expected a rejection with the command's reason, got %v", err)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"lint": {"max_length": 20}, "content_filter": {"rules": [{"pattern": "\\b\\d{3}-\\d{2}-\\d{4}\\b", "replacement": "#"}]}}`)
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Lint sees the filtered text, short enough once redacted
+	c := comment.NewComment("alice", 1, "SSN 123-45-6789 here")
+	if err := cfg.Screen(c, true); err != nil || c.Text != "SSN # here" {
+		t.Errorf("Screen = %v, text %q", err, c.Text)
+	}
+
+	long := comment.NewReply("alice", "This reply is far too long", c)
+	var lint *LintError
+	if err := cfg.Screen(long, false); !errors.As(err, &lint) || lint.Issues[0].Rule != LintMaxLength {
+		t.Errorf("Expected a max_length lint error, got %v", err)
+	}
+
+	var none *Config
+	if err := none.Screen(long, false); err != nil {
+		t.Errorf("A nil config should screen nothing, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// ContentFilterConfig screens the text of comments as add and batch-add
+// store them, redacting or rejecting what compliance rules forbid
+type ContentFilterConfig struct {
+	// Rules are regular expressions to redact or reject, applied in order
+	Rules []ContentRule `json:"rules,omitempty"`
+
+	// Command is an external filter run after the rules (see
+	// comment.ContentFilter), from the config file's directory
+	Command string `json:"command,omitempty"`
+}
+
+// ContentRule is one pattern to look for in comment text
+type ContentRule struct {
+	Name        string `json:"name,omitempty"`        // Shown when the rule rejects a comment (default: the pattern)
+	Pattern     string `json:"pattern"`               // Go regular expression, e.g. "(?i)\\bpassword\\b"
+	Action      string `json:"action,omitempty"`      // "redact" (default) or "reject"
+	Replacement string `json:"replacement,omitempty"` // What redact leaves in place (default "[redacted]")
+}
+
+// validate checks that every rule compiles and has a known action
+func (f ContentFilterConfig) validate() error {
+	for i, rule := range f.Rules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("rule %d: pattern is required", i+1)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		switch rule.Action {
+		case "", comment.FilterRedact, comment.FilterReject:
+		default:
+			return fmt.Errorf("rule %d: unknown action %q (valid: %s, %s)", i+1, rule.Action, comment.FilterRedact, comment.FilterReject)
+		}
+	}
+	return nil
+}
+
+// Filter returns the comment.ContentFilter the config describes; dir is
+// where its command runs
+func (f ContentFilterConfig) Filter(dir string) (comment.ContentFilter, error) {
+	filter := comment.ContentFilter{Command: f.Command, Dir: dir}
+	for i, rule := range f.Rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return comment.ContentFilter{}, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("%q", rule.Pattern)
+		}
+		action := rule.Action
+		if action == "" {
+			action = comment.FilterRedact
+		}
+		filter.Rules = append(filter.Rules, comment.FilterRule{
+			Name:        name,
+			Pattern:     pattern,
+			Action:      action,
+			Replacement: rule.Replacement,
+		})
+	}
+	return filter, nil
+}
+
+// FilterContent runs text by author through the project's content filter,
+// returning it as it should be stored or a *comment.FilterRejection. A nil
+// config filters nothing.
+func (c *Config) FilterContent(author, text string) (string, error) {
+	if c == nil {
+		return text, nil
+	}
+	dir := ""
+	if c.Path != "" {
+		dir = filepath.Dir(c.Path)
+	}
+	filter, err := c.ContentFilter.Filter(dir)
+	if err != nil {
+		return "", fmt.Errorf("content_filter: %w", err)
+	}
+	if filter.IsEmpty() {
+		return text, nil
+	}
+	return filter.Apply(author, text)
+}
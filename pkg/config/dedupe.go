@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// DedupeConfig controls how batch-add handles comments that nearly repeat an
+// open comment at the same place
+type DedupeConfig struct {
+	Mode      string  `json:"mode,omitempty"`      // "skip" (default), "merge", or "off"
+	Threshold float64 `json:"threshold,omitempty"` // Text similarity (0-1) that counts as a duplicate (default 0.9)
+}
+
+// Policy returns the dedupe policy, filling in defaults for unset fields
+func (d DedupeConfig) Policy() comment.DedupePolicy {
+	policy := comment.DefaultDedupePolicy()
+	if d.Mode != "" {
+		policy.Mode = d.Mode
+	}
+	if d.Threshold != 0 {
+		policy.Threshold = d.Threshold
+	}
+	return policy
+}
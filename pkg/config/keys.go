@@ -0,0 +1,84 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvSigningKey overrides the location of the local signing key
+const EnvSigningKey = "COMMENTS_SIGNING_KEY"
+
+// DefaultKeyPath returns where the local ed25519 signing key is stored
+func DefaultKeyPath() (string, error) {
+	if path := os.Getenv(EnvSigningKey); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "comments", "signing_key"), nil
+}
+
+// GenerateSigningKey creates a new signing key at path
+// The private key seed is stored base64-encoded with owner-only permissions.
+func GenerateSigningKey(path string) (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(key.Seed()) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write key: %w", err)
+	}
+
+	return key, nil
+}
+
+// LoadSigningKey reads a signing key written by GenerateSigningKey
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no signing key at %s (run 'comments keygen')", path)
+		}
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key in %s", path)
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// PublicKeyString returns the base64 public key for a signing key
+func PublicKeyString(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// SigningKey returns the local signing key when the project enables signing
+// Returns nil (and no error) when signing is disabled.
+func (c *Config) SigningKey() (ed25519.PrivateKey, error) {
+	if c == nil || !c.SignComments {
+		return nil, nil
+	}
+
+	path, err := DefaultKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadSigningKey(path)
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// Lint rules
+const (
+	LintMaxLength      = "max_length"
+	LintForbiddenChars = "forbidden_chars"
+	LintRequireType    = "require_type"
+)
+
+// LintConfig limits what comments may contain. New comments are checked
+// when they are added (see Screen); `comments lint-comments` checks existing
+// sidecars.
+type LintConfig struct {
+	// MaxLength is the most characters a comment's text may have (0: no limit)
+	MaxLength int `json:"max_length,omitempty"`
+
+	// ForbiddenChars are characters comment text must not contain
+	ForbiddenChars string `json:"forbidden_chars,omitempty"`
+
+	// RequireType lists roles or author names whose threads must set a type
+	// (Q, S, B, T, E); suggestions and replies are exempt
+	RequireType []string `json:"require_type,omitempty"`
+}
+
+// validate checks the lint limits
+func (l LintConfig) validate() error {
+	if l.MaxLength < 0 {
+		return fmt.Errorf("max_length must be zero or greater, got %d", l.MaxLength)
+	}
+	for _, entry := range l.RequireType {
+		if strings.TrimSpace(entry) == "" {
+			return fmt.Errorf("require_type entries must not be empty")
+		}
+	}
+	return nil
+}
+
+// LintIssue is a comment that breaks one of the lint rules
+type LintIssue struct {
+	Comment *comment.Comment
+	Rule    string
+	Message string
+}
+
+// LintComment returns the lint rules c breaks; thread is true when c starts
+// a thread rather than replying to one. A nil config has no rules.
+func (c *Config) LintComment(cm *comment.Comment, thread bool) []LintIssue {
+	if c == nil {
+		return nil
+	}
+	var issues []LintIssue
+	add := func(rule, format string, args ...any) {
+		issues = append(issues, LintIssue{Comment: cm, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n := utf8.RuneCountInString(cm.Text); c.Lint.MaxLength > 0 && n > c.Lint.MaxLength {
+		add(LintMaxLength, "text is %d characters, over the limit of %d", n, c.Lint.MaxLength)
+	}
+	if c.Lint.ForbiddenChars != "" {
+		var found []string
+		for _, r := range c.Lint.ForbiddenChars {
+			if strings.ContainsRune(cm.Text, r) {
+				found = append(found, fmt.Sprintf("%q", r))
+			}
+		}
+		if len(found) > 0 {
+			add(LintForbiddenChars, "text contains forbidden character(s) %s", strings.Join(found, ", "))
+		}
+	}
+	if thread && !cm.IsSuggestion && cm.Type == "" && c.requiresType(cm.Author) {
+		role := c.Role(cm.Author)
+		add(LintRequireType, "%s (%s) must set a type (Q, S, B, T, E)", cm.Author, role)
+	}
+	return issues
+}
+
+// LintDocument returns the lint rules broken by every comment in doc, thread
+// by thread, replies after the comment they answer
+func (c *Config) LintDocument(doc *comment.DocumentWithComments) []LintIssue {
+	var issues []LintIssue
+	var walk func(cm *comment.Comment, thread bool)
+	walk = func(cm *comment.Comment, thread bool) {
+		issues = append(issues, c.LintComment(cm, thread)...)
+		for _, reply := range cm.Replies {
+			walk(reply, false)
+		}
+	}
+	for _, thread := range doc.Threads {
+		walk(thread, true)
+	}
+	return issues
+}
+
+// requiresType reports whether author's threads must set a type
+func (c *Config) requiresType(author string) bool {
+	role := c.Role(author)
+	for _, entry := range c.Lint.RequireType {
+		if strings.EqualFold(entry, RoleAnyone) || strings.EqualFold(entry, role) || strings.EqualFold(entry, author) {
+			return true
+		}
+	}
+	return false
+}
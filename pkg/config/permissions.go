@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// Actions that can be restricted in the permissions section of the config
+const (
+	ActionAdd      = "add"
+	ActionReply    = "reply"
+	ActionResolve  = "resolve"
+	ActionSuggest  = "suggest"
+	ActionAccept   = "accept"
+	ActionReject   = "reject"
+	ActionStatus   = "status"
+	ActionReattach = "reattach"
+	ActionCleanup  = "cleanup"
+	ActionRestore  = "restore"
+	ActionReview   = "review"
+)
+
+// Actions lists every action that can appear in the permissions section
+var Actions = []string{
+	ActionAdd, ActionReply, ActionResolve, ActionSuggest, ActionAccept,
+	ActionReject, ActionStatus, ActionReattach, ActionCleanup, ActionRestore,
+	ActionReview,
+}
+
+// Roles that can be used in permission rules
+const (
+	RoleOwner  = "owner"
+	RoleAgent  = "agent"
+	RoleHuman  = "human"
+	RoleAnyone = "anyone"
+)
+
+// Self-acceptance rules: whether a suggestion's author may accept it
+const (
+	SelfAcceptAllow    = "allow"    // Anyone allowed to accept may (default)
+	SelfAcceptDeny     = "deny"     // Someone else has to accept it
+	SelfAcceptApproved = "approved" // Only after someone else submits an approving review
+)
+
+// ErrReadOnly is returned for any change attempted in read-only mode
+var ErrReadOnly = errors.New("read-only mode: changes are disabled")
+
+// PermissionError reports an action denied by the project policy
+type PermissionError struct {
+	Action  string
+	Author  string
+	Role    string
+	Allowed []string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied: %s (%s) cannot %s; allowed: %s",
+		e.Author, e.Role, e.Action, strings.Join(e.Allowed, ", "))
+}
+
+// SelfAcceptError reports an author accepting their own suggestion against
+// the self_accept rule
+type SelfAcceptError struct {
+	SuggestionID string
+	Author       string
+	Rule         string
+}
+
+func (e *SelfAcceptError) Error() string {
+	if e.Rule == SelfAcceptApproved {
+		return fmt.Sprintf("%s cannot accept their own suggestion %s until someone else approves the document in a review (self_accept is %q)",
+			e.Author, e.SuggestionID, e.Rule)
+	}
+	return fmt.Sprintf("%s cannot accept their own suggestion %s; someone else has to (self_accept is %q)",
+		e.Author, e.SuggestionID, e.Rule)
+}
+
+// Role returns the role of an author: owner, agent, or human
+func (c *Config) Role(author string) string {
+	if containsFold(c.Owners, author) {
+		return RoleOwner
+	}
+	if containsFold(c.Agents, author) {
+		return RoleAgent
+	}
+	return RoleHuman
+}
+
+// Answerer returns who can answer a [Q] thread: the document owners, or
+// nil (anyone but the asker) when none are configured
+func (c *Config) Answerer() func(author string) bool {
+	if c == nil || len(c.Owners) == 0 {
+		return nil
+	}
+	return func(author string) bool { return containsFold(c.Owners, author) }
+}
+
+// Check returns an error if author may not perform action
+// A nil config allows everything.
+func (c *Config) Check(action, author string) error {
+	if c == nil {
+		return nil
+	}
+
+	if c.ReadOnly {
+		return ErrReadOnly
+	}
+
+	allowed, restricted := c.Permissions[action]
+	if !restricted {
+		return nil
+	}
+
+	role := c.Role(author)
+	for _, entry := range allowed {
+		if strings.EqualFold(entry, RoleAnyone) || strings.EqualFold(entry, role) || strings.EqualFold(entry, author) {
+			return nil
+		}
+	}
+
+	return &PermissionError{Action: action, Author: author, Role: role, Allowed: allowed}
+}
+
+// CheckAccept returns an error if actor may not accept suggestion s, which
+// belongs to doc, under the self_accept rule. Owners may override the rule.
+// A nil config allows everything.
+func (c *Config) CheckAccept(doc *comment.DocumentWithComments, s *comment.Comment, actor string, override bool) error {
+	if c == nil || !strings.EqualFold(s.Author, actor) {
+		return nil
+	}
+	if override && c.Role(actor) == RoleOwner {
+		return nil
+	}
+
+	switch c.SelfAccept {
+	case SelfAcceptDeny:
+	case SelfAcceptApproved:
+		if doc.ApprovedByOther(actor, s.Timestamp) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &SelfAcceptError{SuggestionID: s.ID, Author: actor, Rule: c.SelfAccept}
+}
+
+// isKnownAction reports whether action is a valid permissions key
+func isKnownAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
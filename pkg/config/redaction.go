@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// RedactionConfig lists what to remove from comments shared outside the
+// team by `export` and `publish`
+type RedactionConfig struct {
+	Authors             []string `json:"authors,omitempty"`              // Drop comments by these authors, with replies to them
+	Labels              []string `json:"labels,omitempty"`               // Strip these labels from comment text, e.g. "#internal"
+	RejectedSuggestions bool     `json:"rejected_suggestions,omitempty"` // Drop rejected suggestions
+}
+
+// Rules returns the redaction to apply
+func (r RedactionConfig) Rules() comment.Redaction {
+	return comment.Redaction{
+		Authors:             r.Authors,
+		Labels:              r.Labels,
+		RejectedSuggestions: r.RejectedSuggestions,
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// RetentionRule archives threads matching a status, author, and age
+// Rules are applied by `comments cleanup --apply-policy`.
+type RetentionRule struct {
+	Status    string `json:"status"`               // "completed" or "resolved"
+	Author    string `json:"author,omitempty"`     // Only threads started by this author
+	OlderThan string `json:"older_than,omitempty"` // Minimum time since last activity (e.g., "90d")
+}
+
+// Criteria converts the rule into archive selection criteria
+func (r RetentionRule) Criteria() (comment.ArchiveCriteria, error) {
+	criteria := comment.ArchiveCriteria{Status: r.Status, Author: r.Author}
+
+	if r.Status != "completed" && r.Status != "resolved" {
+		return criteria, fmt.Errorf("retention status must be 'completed' or 'resolved', got %q", r.Status)
+	}
+
+	if r.OlderThan != "" {
+		age, err := comment.ParseAge(r.OlderThan)
+		if err != nil {
+			return criteria, err
+		}
+		criteria.OlderThan = age
+	}
+
+	return criteria, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// LintError is a new comment refused for breaking the project's lint rules
+type LintError struct {
+	Issues []LintIssue
+}
+
+func (e *LintError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.Rule + ": " + issue.Message
+	}
+	return fmt.Sprintf("lint: %s", strings.Join(messages, "; "))
+}
+
+// Screen runs a new comment through the project's content filter, replacing
+// its text with what may be stored, then checks it against the lint rules,
+// so lint sees the filtered text. thread is true when the comment starts a
+// thread. Every way of adding comments calls it before saving. It returns a
+// *comment.FilterRejection or a *LintError for a comment that must not be
+// stored. A nil config screens nothing.
+func (c *Config) Screen(cm *comment.Comment, thread bool) error {
+	text, err := c.FilterContent(cm.Author, cm.Text)
+	if err != nil {
+		return err
+	}
+	cm.Text = text
+	if issues := c.LintComment(cm, thread); len(issues) > 0 {
+		return &LintError{Issues: issues}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// SectionOwnerRule names the required reviewers of the sections matching a
+// pattern, like a CODEOWNERS line. `comments check` fails while a pending
+// suggestion in a matching section has not been reviewed by one of them.
+type SectionOwnerRule struct {
+	Section string   `json:"section"` // Section path pattern, e.g. "API" or "Guide > Setup*"; covers subsections
+	Owners  []string `json:"owners"`  // Author names
+}
+
+// validate checks that the rule has a well-formed pattern and owners
+func (r SectionOwnerRule) validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if err := comment.ValidateSectionPattern(r.Section); err != nil {
+		return fmt.Errorf("section %q: %w", r.Section, err)
+	}
+	if len(r.Owners) == 0 {
+		return fmt.Errorf("section %q has no owners", r.Section)
+	}
+	return nil
+}
+
+// SectionOwnersOf returns the required reviewers of a section: the owners of
+// the last rule matching it, as in CODEOWNERS. A nil config owns nothing.
+func (c *Config) SectionOwnersOf(sectionPath string) []string {
+	if c == nil {
+		return nil
+	}
+	for i := len(c.SectionOwners) - 1; i >= 0; i-- {
+		if comment.MatchSectionPattern(c.SectionOwners[i].Section, sectionPath) {
+			return c.SectionOwners[i].Owners
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Parts of a .docx (Office Open XML) package the importer reads
+const (
+	docxDocument         = "word/document.xml"
+	docxComments         = "word/comments.xml"
+	docxCommentsExtended = "word/commentsExtended.xml" // Replies and resolved state (Word 2013 and later)
+)
+
+// ReadDocx reads the comments and tracked changes from a Word document.
+// Comments are anchored to the text their range covers; tracked changes
+// are grouped by paragraph, anchored to the paragraph's original text.
+func ReadDocx(path string) ([]Comment, []Change, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	parts := map[string]*zip.File{}
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+	read := func(name string, parse func(io.Reader) error) error {
+		f := parts[name]
+		if f == nil {
+			return nil
+		}
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		defer r.Close()
+		if err := parse(r); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		return nil
+	}
+	if parts[docxDocument] == nil {
+		return nil, nil, fmt.Errorf("%s is not a Word document (no %s)", path, docxDocument)
+	}
+
+	var comments []docxComment
+	var extended map[string]docxCommentEx
+	var doc *docxBody
+	if err := read(docxComments, func(r io.Reader) (err error) { comments, err = parseDocxComments(r); return err }); err != nil {
+		return nil, nil, err
+	}
+	if err := read(docxCommentsExtended, func(r io.Reader) (err error) { extended, err = parseDocxCommentsExtended(r); return err }); err != nil {
+		return nil, nil, err
+	}
+	if err := read(docxDocument, func(r io.Reader) (err error) { doc, err = parseDocxDocument(r); return err }); err != nil {
+		return nil, nil, err
+	}
+	return threadDocxComments(comments, extended, doc), doc.changes, nil
+}
+
+// docxComment is a w:comment in comments.xml
+type docxComment struct {
+	id, author string
+	date       time.Time
+	text       string
+	paraID     string // Of its last paragraph, which commentsExtended refers to
+}
+
+// docxCommentEx is a w15:commentEx in commentsExtended.xml
+type docxCommentEx struct {
+	parent string // paraID of the comment it replies to
+	done   bool
+}
+
+// docxBody is what the importer takes from document.xml
+type docxBody struct {
+	anchors  map[string]string // Comment ID to the text its range covers
+	contexts map[string]string // Comment ID to the paragraph its range starts in
+	changes  []Change
+}
+
+// threadDocxComments nests replies under the comments they answer and
+// anchors each thread
+func threadDocxComments(comments []docxComment, extended map[string]docxCommentEx, doc *docxBody) []Comment {
+	byPara := map[string]int{}
+	for i, c := range comments {
+		byPara[c.paraID] = i
+	}
+
+	roots := []Comment{}
+	rootOf := map[int]int{} // Index in comments to index in roots
+	for i, c := range comments {
+		ex := extended[c.paraID]
+		imported := Comment{Author: c.author, Date: c.date, Text: c.text}
+		if parent, ok := byPara[ex.parent]; ok && ex.parent != "" {
+			if root, ok := rootOf[parent]; ok {
+				roots[root].Replies = append(roots[root].Replies, imported)
+				rootOf[i] = root
+				continue
+			}
+		}
+		imported.Anchor = doc.anchors[c.id]
+		imported.Context = doc.contexts[c.id]
+		imported.Resolved = ex.done
+		rootOf[i] = len(roots)
+		roots = append(roots, imported)
+	}
+	return roots
+}
+
+// parseDocxComments reads the comments in comments.xml
+func parseDocxComments(r io.Reader) ([]docxComment, error) {
+	decoder := xml.NewDecoder(r)
+	comments := []docxComment{}
+	var current *docxComment
+	paragraphs := []string{}
+	var paragraph strings.Builder
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return comments, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "comment":
+				current = &docxComment{id: attr(t, "id"), author: attr(t, "author"), date: docxDate(attr(t, "date"))}
+				paragraphs = paragraphs[:0]
+			case "p":
+				if current != nil {
+					current.paraID = attr(t, "paraId")
+				}
+				paragraph.Reset()
+			case "t":
+				inText = true
+			case "tab", "br":
+				paragraph.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				paragraphs = append(paragraphs, strings.TrimSpace(paragraph.String()))
+			case "comment":
+				if current != nil {
+					current.text = strings.TrimSpace(strings.Join(paragraphs, "\n"))
+					comments = append(comments, *current)
+					current = nil
+				}
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+}
+
+// parseDocxCommentsExtended reads commentsExtended.xml, keyed by paraId
+func parseDocxCommentsExtended(r io.Reader) (map[string]docxCommentEx, error) {
+	decoder := xml.NewDecoder(r)
+	extended := map[string]docxCommentEx{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return extended, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := token.(xml.StartElement); ok && t.Name.Local == "commentEx" {
+			extended[attr(t, "paraId")] = docxCommentEx{parent: attr(t, "paraIdParent"), done: attr(t, "done") == "1"}
+		}
+	}
+}
+
+// parseDocxDocument reads the comment ranges and tracked changes in
+// document.xml. Text is taken as it was before the tracked changes: kept
+// and deleted runs, not inserted ones.
+func parseDocxDocument(r io.Reader) (*docxBody, error) {
+	decoder := xml.NewDecoder(r)
+	body := &docxBody{anchors: map[string]string{}, contexts: map[string]string{}}
+
+	var original strings.Builder // The paragraph's text before the changes
+	var change *Change           // The paragraph's tracked changes
+	var edit *Edit               // The edit being read
+	open := map[string]*strings.Builder{}
+	started := []string{} // Comments whose range starts in the paragraph
+	inIns, inDel, inText := false, false, false
+
+	// endEdit ends the edit being read. Word also marks inserted or deleted
+	// paragraph marks, which change no text.
+	endEdit := func() {
+		if edit != nil && (edit.Deleted != "" || edit.Inserted != "") {
+			change.Edits = append(change.Edits, *edit)
+		}
+		edit = nil
+	}
+	// text adds a run of text to the paragraph and the ranges it is in
+	text := func(s string) {
+		switch {
+		case inIns:
+			edit.Inserted += s
+			return
+		case inDel:
+			edit.Deleted += s
+		default:
+			endEdit()
+		}
+		original.WriteString(s)
+		for _, b := range open {
+			b.WriteString(s)
+		}
+	}
+	// startEdit begins an edit (or continues the one being read, so a
+	// deletion followed by an insertion is one replacement)
+	startEdit := func(t xml.StartElement) {
+		if change == nil {
+			change = &Change{Author: attr(t, "author"), Date: docxDate(attr(t, "date"))}
+		}
+		if edit == nil {
+			edit = &Edit{Before: original.String()}
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return body, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				original.Reset()
+				change, edit, started = nil, nil, started[:0]
+			case "ins":
+				startEdit(t)
+				inIns = true
+			case "del":
+				startEdit(t)
+				inDel = true
+			case "t", "delText":
+				inText = true
+			case "tab", "br":
+				text(" ")
+			case "commentRangeStart":
+				id := attr(t, "id")
+				open[id] = &strings.Builder{}
+				started = append(started, id)
+			case "commentRangeEnd":
+				id := attr(t, "id")
+				if b := open[id]; b != nil {
+					body.anchors[id] = strings.TrimSpace(b.String())
+					delete(open, id)
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "ins":
+				inIns = false
+			case "del":
+				inDel = false
+			case "t", "delText":
+				inText = false
+			case "p":
+				endEdit()
+				paragraph := strings.TrimSpace(original.String())
+				if change != nil && len(change.Edits) > 0 {
+					change.Original = paragraph
+					body.changes = append(body.changes, *change)
+				}
+				for _, id := range started {
+					body.contexts[id] = paragraph
+				}
+				change = nil
+				for _, b := range open {
+					b.WriteByte(' ')
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text(string(t))
+			}
+		}
+	}
+}
+
+// docxDate parses a w:date attribute, zero if absent or malformed
+func docxDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
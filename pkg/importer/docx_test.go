@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testDocxDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Setup Guide</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Install the tool with </w:t></w:r><w:commentRangeStart w:id="0"/><w:commentRangeStart w:id="1"/><w:r><w:t>brew</w:t></w:r><w:commentRangeEnd w:id="0"/><w:commentRangeEnd w:id="1"/><w:r><w:commentReference w:id="0"/></w:r><w:r><w:t xml:space="preserve"> and run it once to create the cache.</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Call it </w:t></w:r><w:del w:id="5" w:author="Bob" w:date="2025-01-16T09:00:00Z"><w:r><w:delText>daily</w:delText></w:r></w:del><w:ins w:id="6" w:author="Bob" w:date="2025-01-16T09:00:00Z"><w:r><w:t>weekly</w:t></w:r></w:ins><w:r><w:t>.</w:t></w:r><w:pPr><w:rPr><w:ins w:id="7" w:author="Bob" w:date="2025-01-16T09:00:00Z"/></w:rPr></w:pPr></w:p>
+</w:body>
+</w:document>`
+
+	testDocxComments = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml">
+<w:comment w:id="0" w:author="Alice" w:date="2025-01-15T10:00:00Z" w:initials="A"><w:p w14:paraId="1A000001"><w:r><w:annotationRef/></w:r><w:r><w:t>Which version?</w:t></w:r></w:p></w:comment>
+<w:comment w:id="1" w:author="Bob" w:date="2025-01-15T11:00:00Z" w:initials="B"><w:p w14:paraId="1A000002"><w:r><w:t>Homebrew 4 or later</w:t></w:r></w:p></w:comment>
+</w:comments>`
+
+	testDocxCommentsExtended = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w15:commentsEx xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml">
+<w15:commentEx w15:paraId="1A000001" w15:done="1"/>
+<w15:commentEx w15:paraId="1A000002" w15:paraIdParent="1A000001" w15:done="0"/>
+</w15:commentsEx>`
+)
+
+// writeDocx writes a Word document made of parts to a temporary file
+func writeDocx(t *testing.T, parts map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "draft.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range parts {
+		part, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		part.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestReadDocx(t *testing.T) {
+	path := writeDocx(t, map[string]string{
+		"word/document.xml":         testDocxDocument,
+		"word/comments.xml":         testDocxComments,
+		"word/commentsExtended.xml": testDocxCommentsExtended,
+	})
+
+	comments, changes, err := ReadDocx(path)
+	if err != nil {
+		t.Fatalf("ReadDocx failed: %v", err)
+	}
+
+	if len(comments) != 1 {
+		t.Fatalf("Expected 1 thread (the reply nested), got %d: %+v", len(comments), comments)
+	}
+	c := comments[0]
+	if c.Author != "Alice" || c.Text != "Which version?" || c.Anchor != "brew" || !c.Resolved || c.Date.IsZero() {
+		t.Errorf("Unexpected comment: %+v", c)
+	}
+	if c.Context != "Install the tool with brew and run it once to create the cache." {
+		t.Errorf("Expected the paragraph as context, got %q", c.Context)
+	}
+	if len(c.Replies) != 1 || c.Replies[0].Author != "Bob" || c.Replies[0].Text != "Homebrew 4 or later" {
+		t.Errorf("Expected Bob's reply: %+v", c.Replies)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 paragraph of changes, got %d: %+v", len(changes), changes)
+	}
+	change := changes[0]
+	if change.Author != "Bob" || change.Original != "Call it daily." || len(change.Edits) != 1 {
+		t.Fatalf("Unexpected change: %+v", change)
+	}
+	if e := change.Edits[0]; e.Before != "Call it " || e.Deleted != "daily" || e.Inserted != "weekly" {
+		t.Errorf("Expected daily replaced with weekly: %+v", e)
+	}
+}
+
+func TestReadDocxWithoutComments(t *testing.T) {
+	path := writeDocx(t, map[string]string{"word/document.xml": testDocxDocument})
+	comments, changes, err := ReadDocx(path)
+	if err != nil {
+		t.Fatalf("ReadDocx failed: %v", err)
+	}
+	if len(comments) != 0 || len(changes) != 1 {
+		t.Errorf("Expected only the tracked change, got %d comments, %d changes", len(comments), len(changes))
+	}
+
+	if _, _, err := ReadDocx(writeDocx(t, map[string]string{"content.xml": ""})); err == nil {
+		t.Error("Expected an error for a package without word/document.xml")
+	}
+}
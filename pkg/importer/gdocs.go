@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Google Docs links each comment marker in the text (id "cmnt_ref1") to the
+// comment's body at the end of the page (id "cmnt1"), and back
+const (
+	googleRefPrefix     = "cmnt_ref"
+	googleCommentPrefix = "cmnt"
+)
+
+// googleBlocks are the elements whose text makes a paragraph
+var googleBlocks = map[string]bool{
+	"p": true, "li": true, "td": true, "th": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// ReadGoogleDocs reads the comments from a Google Docs web page export: the
+// .html file, or the .zip that File > Download > Web page saves
+func ReadGoogleDocs(path string) ([]Comment, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseGoogleDocsHTML(f)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if strings.EqualFold(filepath.Ext(file.Name), ".html") {
+			f, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s in %s: %w", file.Name, path, err)
+			}
+			defer f.Close()
+			return ParseGoogleDocsHTML(f)
+		}
+	}
+	return nil, fmt.Errorf("no .html page in %s", path)
+}
+
+// ParseGoogleDocsHTML reads the comments from the HTML of a Google Docs web
+// page export. The export marks only where each comment's text ends and
+// doesn't name authors, so a comment's anchor is the text of its paragraph
+// up to the marker, and its author is left empty.
+func ParseGoogleDocsHTML(r io.Reader) ([]Comment, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	type anchor struct{ before, paragraph string }
+	anchors := map[string]*anchor{}
+	pending := []*anchor{} // Markers in the paragraph being read
+	bodies := map[string][]string{}
+	order := []string{}
+
+	var paragraph strings.Builder
+	inBody := false
+	skip := 0        // Depth inside a marker link, whose "[a]" isn't text
+	divs := 0        // Open divs
+	current := ""    // Comment whose body is being read
+	currentDivs := 0 // Open divs when it started; it ends with its div
+
+	flush := func() {
+		text := strings.Join(strings.Fields(paragraph.String()), " ")
+		paragraph.Reset()
+		if current != "" {
+			if text != "" {
+				bodies[current] = append(bodies[current], text)
+			}
+			return
+		}
+		for _, a := range pending {
+			a.paragraph = text
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the Google Docs export: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "body":
+				inBody = true
+			case name == "div":
+				divs++
+			case name == "br":
+				paragraph.WriteByte(' ')
+			case googleBlocks[name]:
+				flush()
+			case name == "a":
+				id := attr(t, "id")
+				if ref, ok := strings.CutPrefix(id, googleRefPrefix); ok {
+					a := &anchor{before: strings.Join(strings.Fields(paragraph.String()), " ")}
+					anchors[ref] = a
+					pending = append(pending, a)
+					skip++
+				} else if c, ok := strings.CutPrefix(id, googleCommentPrefix); ok && strings.HasPrefix(attr(t, "href"), "#"+googleRefPrefix) {
+					flush()
+					current, currentDivs = c, divs
+					order = append(order, c)
+					skip++
+				} else if skip > 0 {
+					skip++
+				}
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "a" && skip > 0:
+				skip--
+			case name == "div":
+				if current != "" && divs == currentDivs {
+					flush()
+					current = ""
+				}
+				divs--
+			case googleBlocks[name]:
+				flush()
+			}
+		case xml.CharData:
+			if inBody && skip == 0 {
+				paragraph.Write(t)
+			}
+		}
+	}
+	flush()
+
+	comments := []Comment{}
+	for _, id := range order {
+		c := Comment{Text: strings.Join(bodies[id], "\n")}
+		if a := anchors[id]; a != nil {
+			c.Anchor, c.Context = a.before, a.paragraph
+		}
+		if c.Text != "" {
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+// attr returns the value of an element's attribute, or ""
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
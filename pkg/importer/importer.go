@@ -0,0 +1,242 @@
+// Package importer brings review comments made in other tools (Google Docs,
+// Word) into a markdown document's sidecar, for documents migrated into the
+// repository with their review history. Each tool's export is read into
+// Comments (and Changes, for tracked changes), which are then placed on the
+// markdown lines whose text matches what they were anchored to.
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// Comment is a comment read from another tool's export
+type Comment struct {
+	Author   string    // Empty if the export doesn't say
+	Date     time.Time // Zero if the export doesn't say
+	Text     string
+	Anchor   string // Text the comment was on
+	Context  string // Text around Anchor (its paragraph), tried when Anchor isn't found
+	Resolved bool
+	Replies  []Comment
+}
+
+// Change is a paragraph's tracked changes, read from another tool's export
+type Change struct {
+	Author   string    // Who made the first edit
+	Date     time.Time // When, zero if the export doesn't say
+	Original string    // The paragraph's text before the changes
+	Edits    []Edit    // In paragraph order
+}
+
+// Edit is one tracked change within a paragraph: Deleted replaced with
+// Inserted (either may be empty)
+type Edit struct {
+	Before   string // The unchanged text just before it in the paragraph
+	Deleted  string
+	Inserted string
+}
+
+// Threads turns imported comments into threads on content, each on the
+// lines its anchor (or failing that, its context) matches. Comments whose
+// text can't be found become document-level threads and are counted in
+// unplaced. Comments and replies without an author are attributed to author.
+func Threads(content string, imported []Comment, author string) (threads []*comment.Comment, unplaced int) {
+	index := newTextIndex(content)
+	for _, c := range imported {
+		first, last, ok := index.locate(c.Anchor)
+		if !ok {
+			first, last, ok = index.locate(c.Context)
+		}
+		if !ok {
+			first, last = comment.DocumentLine, comment.DocumentLine
+			unplaced++
+		}
+
+		thread := comment.NewComment(authorOr(c.Author, author), first, c.Text)
+		if last > first {
+			thread.EndLine = last
+		}
+		setDate(thread, c.Date)
+		comment.CaptureQuote(thread, content)
+		thread.Resolved = c.Resolved
+		for _, r := range c.Replies {
+			reply := comment.NewReply(authorOr(r.Author, author), r.Text, thread)
+			setDate(reply, r.Date)
+			thread.Replies = append(thread.Replies, reply)
+		}
+		threads = append(threads, thread)
+	}
+	return threads, unplaced
+}
+
+// Suggestions turns imported tracked changes into pending suggestions on
+// content, one per paragraph, replacing the lines its original text matches.
+// The edits are made to the markdown itself so its markup survives; a
+// paragraph whose edits can't be (its text isn't found, or an edit crosses
+// markup) becomes a comment describing them instead, counted in unapplied.
+func Suggestions(content string, changes []Change, author string) (suggestions []*comment.Comment, unapplied int) {
+	index := newTextIndex(content)
+	lines := strings.Split(content, "\n")
+	for _, change := range changes {
+		text := describeEdits(change.Edits)
+		first, last, ok := index.locate(change.Original)
+		var original, proposed string
+		if ok {
+			original = strings.Join(lines[first-1:last], "\n")
+			proposed, ok = applyEdits(original, change.Edits)
+		}
+		if ok && proposed == original {
+			continue
+		}
+
+		var s *comment.Comment
+		switch {
+		case ok:
+			s = comment.NewSuggestion(authorOr(change.Author, author), first, last, text, original, proposed)
+		default:
+			unapplied++
+			if first == 0 {
+				first, last = comment.DocumentLine, comment.DocumentLine
+			}
+			s = comment.NewComment(authorOr(change.Author, author), first, UnappliedChange+text)
+			if last > first {
+				s.EndLine = last
+			}
+			comment.CaptureQuote(s, content)
+		}
+		setDate(s, change.Date)
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, unapplied
+}
+
+// UnappliedChange starts the text of a comment describing a tracked change
+// that couldn't be made to the markdown
+const UnappliedChange = "Tracked change that couldn't be made to the markdown: "
+
+// leadingMarkup matches the markdown that starts a line: heading, quote,
+// and list markers
+var leadingMarkup = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s*|[-*+]\s+|\d+[.)]\s+)*`)
+
+// applyEdits makes edits to text, finding each by the text it deletes or
+// the word it follows; ok is false if one can't be found
+func applyEdits(text string, edits []Edit) (string, bool) {
+	var b strings.Builder
+	pos := 0 // Edits are in order, so each is after the last
+	for _, e := range edits {
+		// The word before the edit tells repeated text apart
+		lead := e.Before[strings.LastIndexFunc(strings.TrimRight(e.Before, " "), unicode.IsSpace)+1:]
+		at := -1
+		switch {
+		case e.Deleted == "" && strings.TrimSpace(e.Before) == "":
+			if pos == 0 {
+				at = len(leadingMarkup.FindString(text))
+			}
+		case lead != "":
+			// The word may end where the last edit did
+			from := max(pos-len(lead), 0)
+			if i := strings.Index(text[from:], lead+e.Deleted); i >= 0 {
+				at = from + i + len(lead)
+			}
+		}
+		if at < 0 && e.Deleted != "" {
+			if i := strings.Index(text[pos:], e.Deleted); i >= 0 {
+				at = pos + i
+			}
+		}
+		if at < 0 {
+			return "", false
+		}
+		b.WriteString(text[pos:at])
+		b.WriteString(e.Inserted)
+		pos = at + len(e.Deleted)
+	}
+	b.WriteString(text[pos:])
+	return b.String(), true
+}
+
+// describeEdits says what edits do, for a suggestion's text
+func describeEdits(edits []Edit) string {
+	parts := []string{}
+	for _, e := range edits {
+		switch {
+		case e.Deleted == "":
+			parts = append(parts, fmt.Sprintf("Insert %q", e.Inserted))
+		case e.Inserted == "":
+			parts = append(parts, fmt.Sprintf("Delete %q", e.Deleted))
+		default:
+			parts = append(parts, fmt.Sprintf("Replace %q with %q", e.Deleted, e.Inserted))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// authorOr returns name, or fallback if it is empty
+func authorOr(name, fallback string) string {
+	if strings.TrimSpace(name) == "" {
+		return fallback
+	}
+	return name
+}
+
+// setDate stamps c with the date from the export, if it has one
+func setDate(c *comment.Comment, date time.Time) {
+	if !date.IsZero() {
+		c.Timestamp = date.UTC()
+	}
+}
+
+// textIndex finds text in a markdown document by its words, so markup,
+// punctuation, case, and line wrapping don't get in the way
+type textIndex struct {
+	text   string // The document's words, lowercase, separated by single spaces
+	starts []int  // Offset in text where each line's words start
+}
+
+func newTextIndex(content string) *textIndex {
+	index := &textIndex{}
+	var b strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		index.starts = append(index.starts, b.Len())
+		if w := words(line); w != "" {
+			b.WriteString(w)
+			b.WriteByte(' ')
+		}
+	}
+	index.text = b.String()
+	return index
+}
+
+// locate returns the first and last line of the first place text appears;
+// ok is false for text that is empty or not found
+func (x *textIndex) locate(text string) (first, last int, ok bool) {
+	w := words(text)
+	if w == "" {
+		return 0, 0, false
+	}
+	at := strings.Index(" "+x.text, " "+w+" ")
+	if at < 0 {
+		return 0, 0, false
+	}
+	return x.line(at), x.line(at + len(w) - 1), true
+}
+
+// line returns the (1-based) line the word at offset is on
+func (x *textIndex) line(offset int) int {
+	return sort.Search(len(x.starts), func(i int) bool { return x.starts[i] > offset })
+}
+
+// words lowercases s and keeps only its words, separated by single spaces
+func words(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMarkdown = `# Setup Guide
+
+Install the tool with **brew** and run it
+once to create the cache.
+
+## Usage
+
+Call it daily.`
+
+func TestThreadsPlacesComments(t *testing.T) {
+	date := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	imported := []Comment{
+		// Wrapped across two lines, markup and case differ
+		{Author: "Alice", Date: date, Text: "Which version?", Anchor: "install the tool with brew and run it once", Resolved: true,
+			Replies: []Comment{{Text: "Any"}}},
+		{Text: "Why daily?", Anchor: "not in the markdown", Context: "Call it daily."},
+		{Text: "Nice doc", Anchor: "gone"},
+	}
+
+	threads, unplaced := Threads(testMarkdown, imported, "gdocs")
+	if len(threads) != 3 || unplaced != 1 {
+		t.Fatalf("Expected 3 threads, 1 unplaced; got %d, %d", len(threads), unplaced)
+	}
+
+	first := threads[0]
+	if first.Line != 3 || first.EndLine != 4 || first.Author != "Alice" || !first.Resolved || !first.Timestamp.Equal(date) {
+		t.Errorf("Unexpected first thread: %+v", first)
+	}
+	if first.Quote == "" || len(first.Replies) != 1 || first.Replies[0].Author != "gdocs" {
+		t.Errorf("Expected a quote and a reply by the fallback author: %+v", first)
+	}
+	if threads[1].Line != 8 || threads[1].Author != "gdocs" {
+		t.Errorf("Second thread should be placed by its context on line 8: %+v", threads[1])
+	}
+	if !threads[2].IsDocumentLevel() {
+		t.Errorf("Unmatched comments should be document-level: line %d", threads[2].Line)
+	}
+}
+
+func TestParseGoogleDocsHTML(t *testing.T) {
+	page := `<html><head><meta content="text/html; charset=UTF-8" http-equiv="content-type"><style type="text/css">.c1{color:#000}</style></head>
+<body class="c2"><h1 class="c3"><span>Setup Guide</span></h1>
+<p class="c1"><span>Install the tool with brew and run it once</span><sup><a href="#cmnt1" id="cmnt_ref1">[a]</a></sup><span>&nbsp;to create the cache.</span></p>
+<ul><li><span>Call it daily.</span><sup><a href="#cmnt2" id="cmnt_ref2">[b]</a></sup></li></ul>
+<div class="c5"><p class="c4"><a href="#cmnt_ref1" id="cmnt1">[a]</a><span class="c0">Which version?</span></p><p><span>Homebrew 4 or later</span></p></div>
+<div class="c5"><p class="c4"><a href="#cmnt_ref2" id="cmnt2">[b]</a><span class="c0">Why daily?</span></p></div>
+</body></html>`
+
+	comments, err := ParseGoogleDocsHTML(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("ParseGoogleDocsHTML failed: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("Expected 2 comments, got %+v", comments)
+	}
+	want := Comment{Text: "Which version?\nHomebrew 4 or later", Anchor: "Install the tool with brew and run it once",
+		Context: "Install the tool with brew and run it once to create the cache."}
+	if comments[0].Text != want.Text || comments[0].Anchor != want.Anchor || comments[0].Context != want.Context {
+		t.Errorf("First comment = %+v, want %+v", comments[0], want)
+	}
+	if comments[1].Text != "Why daily?" || comments[1].Anchor != "Call it daily." {
+		t.Errorf("Second comment = %+v", comments[1])
+	}
+
+	threads, unplaced := Threads(testMarkdown, comments, "gdocs")
+	if unplaced != 0 || threads[0].Line != 3 || threads[1].Line != 8 {
+		t.Errorf("Expected both comments placed (lines 3 and 8), got unplaced %d", unplaced)
+	}
+}
+
+func TestSuggestionsEditTheMarkdown(t *testing.T) {
+	changes := []Change{
+		// Markup around the edit is kept
+		{Author: "Bob", Original: "Install the tool with brew and run it once to create the cache.",
+			Edits: []Edit{{Before: "Install the tool with ", Deleted: "brew", Inserted: "Homebrew"}}},
+		{Original: "Setup Guide", Edits: []Edit{{Inserted: "Quick "}}},
+		{Original: "Call it daily.", Edits: []Edit{{Before: "Call it daily", Inserted: " at noon"}, {Before: "Call it daily.", Inserted: " Or not."}}},
+		// The deleted text crosses markup
+		{Original: "Install the tool with brew and run it", Edits: []Edit{{Before: "Install the tool with ", Deleted: "brew and"}}},
+		{Original: "Not in the markdown", Edits: []Edit{{Deleted: "Not"}}},
+	}
+
+	suggestions, unapplied := Suggestions(testMarkdown, changes, "word")
+	if len(suggestions) != 5 || unapplied != 2 {
+		t.Fatalf("Expected 5 threads, 2 unapplied; got %d, %d", len(suggestions), unapplied)
+	}
+
+	first := suggestions[0]
+	if !first.IsPending() || first.StartLine != 3 || first.EndLine != 4 || first.Author != "Bob" {
+		t.Errorf("Unexpected first suggestion: %+v", first)
+	}
+	if first.ProposedText != "Install the tool with **Homebrew** and run it\nonce to create the cache." {
+		t.Errorf("Expected the edit inside the markup, got %q", first.ProposedText)
+	}
+	if first.Text != `Replace "brew" with "Homebrew"` {
+		t.Errorf("Unexpected text: %q", first.Text)
+	}
+	if got := suggestions[1].ProposedText; got != "# Quick Setup Guide" || suggestions[1].Author != "word" {
+		t.Errorf("Expected an insertion after the heading marker by the fallback author, got %q by %s", got, suggestions[1].Author)
+	}
+	if got := suggestions[2].ProposedText; got != "Call it daily at noon. Or not." {
+		t.Errorf("Expected both insertions, got %q", got)
+	}
+
+	if crossed := suggestions[3]; crossed.IsSuggestion || crossed.Line != 3 || !strings.Contains(crossed.Text, `Delete "brew and"`) {
+		t.Errorf("Expected a comment on the paragraph describing the edit: %+v", crossed)
+	}
+	if !suggestions[4].IsDocumentLevel() {
+		t.Errorf("Expected a document-level comment for a paragraph that isn't found: %+v", suggestions[4])
+	}
+}
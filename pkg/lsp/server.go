@@ -0,0 +1,556 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/comments"
+)
+
+// Server is a minimal LSP-style server that exposes comment threads as
+// diagnostics and thread operations as code actions over a JSON-RPC stream.
+// It is intended to be run over stdio by editor plugins via `comments lsp`.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	log io.Writer
+
+	writeMu sync.Mutex
+
+	author   string            // Author used for replies and state changes
+	service  *comments.Service // Makes every change to comments and documents
+	docs     map[string]string // Open document buffers keyed by URI
+	nextID   int               // Next ID for server-to-client requests
+	shutdown bool              // Set after a shutdown request
+}
+
+// NewServer creates a server reading requests from in and writing responses to out
+// author is used for replies; clients may override it via initializationOptions.
+func NewServer(in io.Reader, out io.Writer, author string) *Server {
+	return &Server{
+		in:      bufio.NewReader(in),
+		out:     out,
+		log:     os.Stderr,
+		author:  author,
+		service: comments.NewService(),
+		docs:    make(map[string]string),
+	}
+}
+
+// Run processes messages until the client sends "exit" or closes the stream
+func (s *Server) Run() error {
+	for {
+		msg, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		s.handle(msg)
+	}
+}
+
+// readMessage reads one Content-Length framed JSON-RPC message
+func (s *Server) readMessage() (*message, error) {
+	contentLength := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		// Report parse errors without an ID, as required by JSON-RPC
+		s.write(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      nil,
+			"error":   responseError{Code: codeParseError, Message: err.Error()},
+		})
+		return &message{}, nil
+	}
+
+	return &msg, nil
+}
+
+// write sends a framed JSON-RPC payload
+func (s *Server) write(payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(s.log, "lsp: failed to encode message: %v\n", err)
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// reply sends a successful response
+func (s *Server) reply(id json.RawMessage, result interface{}) {
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	})
+}
+
+// replyError sends an error response
+func (s *Server) replyError(id json.RawMessage, code int, msg string) {
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   responseError{Code: code, Message: msg},
+	})
+}
+
+// notify sends a notification to the client
+func (s *Server) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+}
+
+// request sends a server-to-client request; responses are not awaited
+func (s *Server) request(method string, params interface{}) {
+	s.nextID++
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      s.nextID,
+		"method":  method,
+		"params":  params,
+	})
+}
+
+// handle dispatches a single message
+func (s *Server) handle(msg *message) {
+	isRequest := len(msg.ID) > 0
+
+	// Responses to our own requests (e.g. workspace/applyEdit) carry no method
+	if msg.Method == "" {
+		return
+	}
+
+	if s.shutdown && isRequest {
+		s.replyError(msg.ID, codeInvalidRequest, "server is shutting down")
+		return
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, &params)
+		}
+		if params.InitializationOptions.Author != "" {
+			s.author = params.InitializationOptions.Author
+		}
+		s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // Full document sync
+					"save":      map[string]bool{"includeText": true},
+				},
+				"codeActionProvider": true,
+				"executeCommandProvider": map[string]interface{}{
+					"commands": []string{CommandResolve, CommandReply, CommandAccept, CommandReject},
+				},
+			},
+			"serverInfo": map[string]string{"name": "comments"},
+		})
+
+	case "initialized":
+		// Nothing to do
+
+	case "shutdown":
+		s.shutdown = true
+		s.reply(msg.ID, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		if params.Text != nil {
+			s.docs[params.TextDocument.URI] = *params.Text
+		}
+		s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.replyError(msg.ID, codeInvalidParams, err.Error())
+			return
+		}
+		actions, err := s.codeActions(params)
+		if err != nil {
+			s.replyError(msg.ID, codeInternalError, err.Error())
+			return
+		}
+		s.reply(msg.ID, actions)
+
+	case "workspace/executeCommand":
+		var params executeCommandParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.replyError(msg.ID, codeInvalidParams, err.Error())
+			return
+		}
+		if err := s.executeCommand(params); err != nil {
+			s.replyError(msg.ID, codeInvalidParams, err.Error())
+			return
+		}
+		s.reply(msg.ID, nil)
+
+	default:
+		if isRequest {
+			s.replyError(msg.ID, codeMethodNotFound, fmt.Sprintf("method not supported: %s", msg.Method))
+		}
+	}
+}
+
+// loadDocument reads the sidecar for a document URI without validating or
+// rewriting it, so diagnostics and code actions never change files
+func (s *Server) loadDocument(uri string) (*comment.DocumentWithComments, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, err
+	}
+	return comment.ReadSidecar(path)
+}
+
+// publishDiagnostics sends the unresolved threads of a document as diagnostics
+func (s *Server) publishDiagnostics(uri string) {
+	diagnostics := []Diagnostic{}
+
+	doc, err := s.loadDocument(uri)
+	if err != nil {
+		fmt.Fprintf(s.log, "lsp: %v\n", err)
+	} else {
+		diagnostics = buildDiagnostics(doc.Threads, s.bufferLines(uri, doc.Content))
+	}
+
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// bufferLines returns the open buffer's lines, falling back to the file on disk
+func (s *Server) bufferLines(uri, diskContent string) []string {
+	if text, ok := s.docs[uri]; ok {
+		return strings.Split(text, "\n")
+	}
+	return strings.Split(diskContent, "\n")
+}
+
+// buildDiagnostics converts unresolved threads into diagnostics
+func buildDiagnostics(threads []*comment.Comment, lines []string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, thread := range comment.GetVisibleComments(threads, false) {
+		if thread.IsSuggestion && !thread.IsPending() {
+			continue
+		}
+		diagnostics = append(diagnostics, threadDiagnostic(thread, lines))
+	}
+
+	return diagnostics
+}
+
+// threadDiagnostic builds the diagnostic for a single thread
+func threadDiagnostic(thread *comment.Comment, lines []string) Diagnostic {
+	startLine, endLine := thread.LineRange()
+
+	severity := SeverityHint
+	if thread.IsSuggestion {
+		severity = SeverityInformation
+	}
+	if thread.Type == "B" {
+		severity = SeverityWarning
+	}
+
+	message := fmt.Sprintf("@%s: %s", thread.Author, thread.Text)
+	if thread.IsSuggestion {
+		message = fmt.Sprintf("Suggestion by @%s: %s\nProposed:\n%s", thread.Author, thread.Text, thread.ProposedText)
+	}
+	if replies := thread.CountReplies(); replies > 0 {
+		message += fmt.Sprintf(" (%d replies)", replies)
+	}
+
+	// Document-level threads are about the whole file; show them on the first line
+	if thread.IsDocumentLevel() && !thread.IsOrphaned() {
+		startLine, endLine = 1, 1
+		message = fmt.Sprintf("Document comment: %s", message)
+	}
+
+	// Orphaned threads have no reliable position; surface them at the top of the file
+	if thread.IsOrphaned() || startLine < 1 || startLine > len(lines) {
+		startLine, endLine = 1, 1
+		severity = SeverityWarning
+		message = fmt.Sprintf("Orphaned comment: %s", message)
+		if thread.OrphanedReason != "" {
+			message += fmt.Sprintf(" [%s]", thread.OrphanedReason)
+		}
+	}
+	if endLine < startLine || endLine > len(lines) {
+		endLine = startLine
+	}
+
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: startLine - 1, Character: 0},
+			End:   Position{Line: endLine - 1, Character: utf16Len(lines[endLine-1])},
+		},
+		Severity: severity,
+		Code:     thread.ID,
+		Source:   "comments",
+		Message:  message,
+		Data:     map[string]string{"threadId": thread.ID},
+	}
+}
+
+// codeActions returns the actions available for threads overlapping a range
+func (s *Server) codeActions(params codeActionParams) ([]CodeAction, error) {
+	uri := params.TextDocument.URI
+	doc, err := s.loadDocument(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := s.bufferLines(uri, doc.Content)
+	actions := []CodeAction{}
+
+	for _, diag := range buildDiagnostics(doc.Threads, lines) {
+		if diag.Range.End.Line < params.Range.Start.Line || diag.Range.Start.Line > params.Range.End.Line {
+			continue
+		}
+
+		thread := doc.FindThreadByID(diag.Code)
+		if thread == nil {
+			continue
+		}
+
+		if thread.IsSuggestion && thread.IsPending() {
+			actions = append(actions,
+				codeAction("Accept suggestion", CommandAccept, uri, thread.ID, diag),
+				codeAction("Reject suggestion", CommandReject, uri, thread.ID, diag),
+			)
+			continue
+		}
+
+		actions = append(actions, codeAction("Resolve comment thread", CommandResolve, uri, thread.ID, diag))
+	}
+
+	return actions, nil
+}
+
+// codeAction builds a quickfix action bound to a thread command
+func codeAction(title, command, uri, threadID string, diag Diagnostic) CodeAction {
+	return CodeAction{
+		Title:       title,
+		Kind:        "quickfix",
+		Diagnostics: []Diagnostic{diag},
+		Command: &Command{
+			Title:     title,
+			Command:   command,
+			Arguments: []interface{}{uri, threadID},
+		},
+	}
+}
+
+// executeCommand runs a thread operation and republishes diagnostics
+// Arguments are [uri, threadID] plus the reply text for comments.reply, or
+// an optional reason for comments.accept and comments.reject. Changes go
+// through the comments Service, so the project policy, content screening,
+// signing, and backups apply just as they do for the CLI.
+func (s *Server) executeCommand(params executeCommandParams) error {
+	args := make([]string, len(params.Arguments))
+	for i, raw := range params.Arguments {
+		if err := json.Unmarshal(raw, &args[i]); err != nil {
+			return fmt.Errorf("argument %d must be a string", i+1)
+		}
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("%s requires document URI and thread ID arguments", params.Command)
+	}
+	uri, threadID := args[0], args[1]
+
+	path, err := uriToPath(uri)
+	if err != nil {
+		return err
+	}
+
+	reason := ""
+	if len(args) > 2 {
+		reason = strings.TrimSpace(args[2])
+	}
+	decision := comments.DecisionOptions{SuggestionID: threadID, Actor: s.author, Reason: reason}
+	ctx := context.Background()
+
+	switch params.Command {
+	case CommandResolve:
+		err = s.service.Resolve(ctx, path, threadID, s.author)
+
+	case CommandReply:
+		if reason == "" {
+			return fmt.Errorf("%s requires reply text", CommandReply)
+		}
+		_, err = s.service.Reply(ctx, path, comments.ReplyOptions{ThreadID: threadID, Author: s.author, Text: args[2]})
+
+	case CommandReject:
+		_, err = s.service.Reject(ctx, path, decision)
+
+	case CommandAccept:
+		err = s.acceptSuggestion(ctx, uri, path, decision)
+
+	default:
+		return fmt.Errorf("unknown command: %s", params.Command)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishDiagnostics(uri)
+	return nil
+}
+
+// acceptSuggestion applies a suggestion to the document and pushes the new
+// content to the client's buffer
+func (s *Server) acceptSuggestion(ctx context.Context, uri, path string, decision comments.DecisionOptions) error {
+	// Refuse to apply against a buffer that differs from disk, to avoid clobbering edits
+	doc, err := comment.ReadSidecar(path)
+	if err != nil {
+		return err
+	}
+	if text, ok := s.docs[uri]; ok && text != doc.Content {
+		return fmt.Errorf("document has unsaved changes; save it before accepting suggestions")
+	}
+
+	result, err := s.service.Accept(ctx, path, decision)
+	if err != nil {
+		return err
+	}
+
+	// Replace the whole buffer so the editor matches what is written to disk
+	oldLines := strings.Split(doc.Content, "\n")
+	lastLine := len(oldLines) - 1
+	s.request("workspace/applyEdit", applyEditParams{
+		Label: "Accept suggestion " + decision.SuggestionID,
+		Edit: WorkspaceEdit{
+			Changes: map[string][]TextEdit{
+				uri: {{
+					Range: Range{
+						Start: Position{Line: 0, Character: 0},
+						End:   Position{Line: lastLine, Character: utf16Len(oldLines[lastLine])},
+					},
+					NewText: result.Content,
+				}},
+			},
+		},
+	})
+	s.docs[uri] = result.Content
+
+	return nil
+}
+
+// uriToPath converts a file:// URI (or plain path) to a filesystem path
+func uriToPath(uri string) (string, error) {
+	if !strings.Contains(uri, "://") {
+		return uri, nil
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid document URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+
+	path := u.Path
+	switch {
+	case u.Host != "" && u.Host != "localhost":
+		// file://server/share/doc.md is a UNC path
+		path = "//" + u.Host + path
+	case len(path) >= 3 && path[0] == '/' && path[2] == ':':
+		// file:///C:/docs/doc.md has a drive letter after the slash
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// utf16Len returns the length of s in UTF-16 code units (LSP character offsets)
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
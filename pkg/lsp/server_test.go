@@ -0,0 +1,289 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// frame encodes a JSON-RPC message with a Content-Length header
+func frame(t *testing.T, payload interface{}) string {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// runSession feeds framed messages to a server and returns its decoded output
+func runSession(t *testing.T, messages ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	server := NewServer(strings.NewReader(strings.Join(messages, "")), &out, "tester")
+	server.log = io.Discard
+
+	if err := server.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var results []map[string]interface{}
+	reader := &Server{in: bufio.NewReader(&out)}
+	for {
+		msg, err := reader.readMessage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("readMessage failed: %v", err)
+		}
+		raw, _ := json.Marshal(msg)
+		var decoded map[string]interface{}
+		json.Unmarshal(raw, &decoded)
+		results = append(results, decoded)
+	}
+	return results
+}
+
+func setupDocument(t *testing.T) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.md")
+	content := "Line one\nLine two\nLine three"
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	doc := &comment.DocumentWithComments{
+		Content: content,
+		Threads: []*comment.Comment{
+			{ID: "c1", Author: "alice", Line: 1, Timestamp: ts, Text: "Question here", Replies: []*comment.Comment{}},
+			{
+				ID: "s1", Author: "bob", Line: 2, Timestamp: ts, Text: "Tighten wording",
+				IsSuggestion: true, StartLine: 2, EndLine: 2,
+				OriginalText: "Line two", ProposedText: "Line 2",
+				Replies: []*comment.Comment{},
+			},
+		},
+	}
+	if err := comment.SaveToSidecar(path, doc); err != nil {
+		t.Fatalf("SaveToSidecar failed: %v", err)
+	}
+
+	return path, "file://" + path
+}
+
+func TestServerInitializeAndDiagnostics(t *testing.T) {
+	path, uri := setupDocument(t)
+	content, _ := os.ReadFile(path)
+
+	out := runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "markdown", "version": 1, "text": string(content)},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "textDocument/codeAction", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"range":        map[string]interface{}{"start": map[string]int{"line": 1, "character": 0}, "end": map[string]int{"line": 1, "character": 0}},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	if len(out) != 3 {
+		t.Fatalf("Expected 3 messages, got %d: %v", len(out), out)
+	}
+
+	caps, ok := out[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if !ok || caps["codeActionProvider"] != true {
+		t.Errorf("Expected codeActionProvider capability, got %v", out[0]["result"])
+	}
+
+	if out[1]["method"] != "textDocument/publishDiagnostics" {
+		t.Fatalf("Expected publishDiagnostics, got %v", out[1]["method"])
+	}
+	diags := out[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %d", len(diags))
+	}
+	first := diags[0].(map[string]interface{})
+	if first["code"] != "c1" || first["source"] != "comments" {
+		t.Errorf("Unexpected diagnostic: %v", first)
+	}
+
+	actions := out[2]["result"].([]interface{})
+	if len(actions) != 2 {
+		t.Fatalf("Expected accept/reject actions for line 2, got %d", len(actions))
+	}
+	if title := actions[0].(map[string]interface{})["title"]; title != "Accept suggestion" {
+		t.Errorf("Expected accept action first, got %v", title)
+	}
+}
+
+func TestServerAcceptSuggestion(t *testing.T) {
+	path, uri := setupDocument(t)
+
+	out := runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "workspace/executeCommand", "params": map[string]interface{}{
+			"command": CommandAccept, "arguments": []string{uri, "s1"},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	// applyEdit request, diagnostics, then the command response
+	if len(out) != 3 {
+		t.Fatalf("Expected 3 messages, got %d: %v", len(out), out)
+	}
+	if out[0]["method"] != "workspace/applyEdit" {
+		t.Errorf("Expected applyEdit request, got %v", out[0]["method"])
+	}
+	if out[2]["error"] != nil {
+		t.Fatalf("Command failed: %v", out[2]["error"])
+	}
+
+	doc, err := comment.LoadFromSidecar(path)
+	if err != nil {
+		t.Fatalf("LoadFromSidecar failed: %v", err)
+	}
+	if !strings.Contains(doc.Content, "Line 2") {
+		t.Errorf("Suggestion was not applied: %q", doc.Content)
+	}
+	if s := doc.FindCommentByID("s1"); s == nil || s.Accepted == nil || !*s.Accepted {
+		t.Error("Suggestion should be marked accepted")
+	}
+}
+
+func TestServerAcceptRefusesUnsavedBuffer(t *testing.T) {
+	_, uri := setupDocument(t)
+
+	out := runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "text": "edited in editor"},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "workspace/executeCommand", "params": map[string]interface{}{
+			"command": CommandAccept, "arguments": []string{uri, "s1"},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	last := out[len(out)-1]
+	if last["error"] == nil {
+		t.Error("Expected accept to fail with unsaved changes")
+	}
+}
+
+func TestServerAcceptBacksUpSidecar(t *testing.T) {
+	path, uri := setupDocument(t)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), config.FileName), []byte(`{"backups": {"keep": 3}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "workspace/executeCommand", "params": map[string]interface{}{
+			"command": CommandAccept, "arguments": []string{uri, "s1"},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	if last := out[len(out)-1]; last["error"] != nil {
+		t.Fatalf("Command failed: %v", last["error"])
+	}
+	backups, err := comment.ListBackups(path)
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Errorf("Expected the sidecar backed up before accepting, got %d backups", len(backups))
+	}
+}
+
+func TestServerDiagnosticsLeaveSidecarAlone(t *testing.T) {
+	path, uri := setupDocument(t)
+
+	// The document changed since the sidecar was saved; loading it for
+	// editing would reattach the threads and rewrite the sidecar
+	content := "Intro\nLine one\nLine two\nLine three"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sidecar := comment.GetSidecarPath(path)
+	before, err := os.ReadFile(sidecar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "text": content},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didChange", "params": map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri},
+			"contentChanges": []map[string]interface{}{{"text": content + "\nLine four"}},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	after, err := os.ReadFile(sidecar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("Publishing diagnostics rewrote the sidecar")
+	}
+}
+
+func TestServerRejectRecordsReason(t *testing.T) {
+	path, uri := setupDocument(t)
+
+	out := runSession(t,
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "workspace/executeCommand", "params": map[string]interface{}{
+			"command": CommandReject, "arguments": []string{uri, "s1", "Numbers read worse here"},
+		}}),
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}),
+	)
+
+	if last := out[len(out)-1]; last["error"] != nil {
+		t.Fatalf("Command failed: %v", last["error"])
+	}
+
+	doc, err := comment.LoadFromSidecar(path)
+	if err != nil {
+		t.Fatalf("LoadFromSidecar failed: %v", err)
+	}
+	reply := comment.DecisionReply(doc.FindCommentByID("s1"))
+	if reply == nil || reply.Decision != comment.DecisionRejected || reply.Text != "Numbers read worse here" {
+		t.Errorf("Expected the reason recorded as a rejected decision reply, got %+v", reply)
+	}
+}
+
+func TestURIToPath(t *testing.T) {
+	tests := map[string]string{
+		"file:///home/me/doc.md":          "/home/me/doc.md",
+		"file:///home/me/my%20notes.md":   "/home/me/my notes.md",
+		"file:///C:/Users/me/doc.md":      "C:/Users/me/doc.md",
+		"file:///c%3A/Users/me/doc.md":    "c:/Users/me/doc.md",
+		"file://server/share/docs/a.md":   "//server/share/docs/a.md",
+		"file://localhost/home/me/doc.md": "/home/me/doc.md",
+		"docs/plain.md":                   "docs/plain.md",
+	}
+	for uri, want := range tests {
+		got, err := uriToPath(uri)
+		if err != nil {
+			t.Errorf("uriToPath(%q) failed: %v", uri, err)
+			continue
+		}
+		if got != filepath.FromSlash(want) {
+			t.Errorf("uriToPath(%q) = %q, want %q", uri, got, filepath.FromSlash(want))
+		}
+	}
+
+	if _, err := uriToPath("https://example.com/doc.md"); err == nil {
+		t.Error("Expected a non-file URI to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+// Package mailin turns email replies into comment replies, so stakeholders
+// can answer review threads from their mail client. A message names the
+// thread it answers with a reference such as [comments:api.md#c123] in its
+// subject (or in the quoted message it replies to); its text, without the
+// quoted part and signature, becomes the reply.
+package mailin
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Errors returned by Parse
+var (
+	ErrNoReference = errors.New("no [comments:<file>#<thread>] reference in the subject or body")
+	ErrNoText      = errors.New("no reply text (only quoted text or a signature)")
+)
+
+// reference matches a thread reference, e.g. [comments:docs/api.md#c123]
+var reference = regexp.MustCompile(`\[comments:([^\]#]+)#([^\]\s]+)\]`)
+
+// Lines that start what a mail client adds below the reply, matched without
+// trailing spaces (quoted-printable drops the one after the "-- " signature
+// separator)
+var quoteHeader = regexp.MustCompile(`^(On .+ wrote:|-+ ?Original Message ?-+|--|_{10,})$`)
+
+// Reply is a comment reply read from an email
+type Reply struct {
+	Document string // As named in the reference
+	ThreadID string
+	Author   string // The sender's address
+	Text     string
+}
+
+// Reference returns the reference to put in the subject of mail about a
+// thread, so replies to it find their way back
+func Reference(document, threadID string) string {
+	return fmt.Sprintf("[comments:%s#%s]", document, threadID)
+}
+
+// Parse reads an email (RFC 5322, as received) and returns the reply it
+// makes. The first text/plain part is used; quoted lines and everything
+// from the "On ... wrote:" line or signature separator down are dropped.
+func Parse(r io.Reader) (*Reply, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email: %w", err)
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, fmt.Errorf("email has no valid From address")
+	}
+
+	body, err := plainText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	ref := reference.FindStringSubmatch(subject)
+	if ref == nil {
+		ref = reference.FindStringSubmatch(body)
+	}
+	if ref == nil {
+		return nil, ErrNoReference
+	}
+
+	text := replyText(body)
+	if text == "" {
+		return nil, ErrNoText
+	}
+	return &Reply{
+		Document: strings.TrimSpace(ref[1]),
+		ThreadID: ref[2],
+		Author:   strings.ToLower(from[0].Address),
+		Text:     text,
+	}, nil
+}
+
+// plainText returns the first text/plain part of a body, decoded
+func plainText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// No or malformed Content-Type: plain text per RFC 2045
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return "", ErrNoText
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to read email part: %w", err)
+			}
+			text, err := plainText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err == nil {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", ErrNoText
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode email text: %w", err)
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+// replyText returns what the sender wrote: the lines above the quoted
+// message or signature, without quoted lines
+func replyText(body string) string {
+	kept := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if quoteHeader.MatchString(line) {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,73 @@
+package mailin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePlainReply(t *testing.T) {
+	message := strings.Join([]string{
+		"From: Carol Jones <Carol@Example.com>",
+		"Subject: Re: " + Reference("docs/api.md", "c123") + " Why once?",
+		"",
+		"Because the cache is warm after that.",
+		"",
+		"Thanks",
+		"",
+		"On Tue, Jan 14, 2025 at 10:00 AM Bob <bob@example.com> wrote:",
+		"> [Q] Why once?",
+	}, "\r\n")
+
+	reply, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := Reply{Document: "docs/api.md", ThreadID: "c123", Author: "carol@example.com", Text: "Because the cache is warm after that.\n\nThanks"}
+	if *reply != want {
+		t.Errorf("Parse = %+v, want %+v", *reply, want)
+	}
+}
+
+func TestParseMultipartReply(t *testing.T) {
+	// Reference only in the quoted message, text quoted-printable
+	message := strings.Join([]string{
+		"From: dana@example.com",
+		"Subject: Re: your question",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/alternative; boundary="b1"`,
+		"",
+		"--b1",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Yes, ship it =E2=80=94 looks good.",
+		"> From the thread " + Reference("guide.md", "c9"),
+		"-- ",
+		"Dana",
+		"--b1",
+		"Content-Type: text/html",
+		"",
+		"<p>Yes, ship it</p>",
+		"--b1--",
+	}, "\r\n")
+
+	reply, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if reply.Document != "guide.md" || reply.ThreadID != "c9" || reply.Text != "Yes, ship it — looks good." {
+		t.Errorf("Unexpected reply: %+v", reply)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	noRef := "From: a@example.com\r\nSubject: Hello\r\n\r\nHi"
+	if _, err := Parse(strings.NewReader(noRef)); !errors.Is(err, ErrNoReference) {
+		t.Errorf("Expected ErrNoReference, got %v", err)
+	}
+	onlyQuote := "From: a@example.com\r\nSubject: Re: [comments:a.md#c1]\r\n\r\n> Quoted\r\n"
+	if _, err := Parse(strings.NewReader(onlyQuote)); !errors.Is(err, ErrNoText) {
+		t.Errorf("Expected ErrNoText, got %v", err)
+	}
+}
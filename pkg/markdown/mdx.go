@@ -0,0 +1,77 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MDX is the model for MDX documents: markdown with embedded JSX. The file
+// is its own text view, but lines inside JSX blocks and fenced code don't
+// start sections, so a "# " in a component's children or an example doesn't
+// split the document.
+var MDX Model = mdxModel{}
+
+type mdxModel struct{}
+
+var (
+	// Matches the start of a JSX element or fragment: <Tabs, <>, <div
+	jsxOpenRegex = regexp.MustCompile(`^<([A-Za-z][\w.:-]*|>)`)
+
+	// Matches a fenced code block delimiter
+	fenceRegex = regexp.MustCompile("^(```|~~~)")
+)
+
+func (mdxModel) Name() string                               { return "mdx" }
+func (mdxModel) View(source string) (string, error)         { return source, nil }
+func (mdxModel) Update(source, view string) (string, error) { return view, nil }
+
+// Parse finds headings outside JSX blocks and fenced code
+func (mdxModel) Parse(view string) *DocumentStructure {
+	lines := strings.Split(view, "\n")
+
+	headings := []headingInfo{}
+	depth := 0 // JSX elements open at this line
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRegex.FindString(trimmed); m != "" {
+			fence = m
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "</"), strings.HasPrefix(trimmed, "/>"):
+			// Closes the innermost element
+			if depth > 0 {
+				depth--
+			}
+			continue
+		case jsxOpenRegex.MatchString(trimmed):
+			// Elements closed on the same line leave the depth unchanged
+			if !strings.HasSuffix(trimmed, "/>") && !strings.Contains(trimmed, "</") {
+				depth++
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
+			headings = append(headings, headingInfo{
+				level:      len(matches[1]),
+				title:      strings.TrimSpace(matches[2]),
+				lineNumber: i + 1,
+			})
+		}
+	}
+
+	return newStructure(headings, len(lines))
+}
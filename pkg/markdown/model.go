@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Model is a document format comments can be anchored in. Comments point at
+// lines of the document's text view, which the model derives from the file
+// and merges edits of (applied suggestions) back into; the model also finds
+// the sections among those lines.
+type Model interface {
+	// Name identifies the model, e.g. "markdown"
+	Name() string
+
+	// View returns the text view of a file's decoded contents
+	View(source string) (string, error)
+
+	// Update returns the file's contents with its text view replaced by view
+	Update(source, view string) (string, error)
+
+	// Parse finds the sections of a text view
+	Parse(view string) *DocumentStructure
+}
+
+// Markdown is the default model: the file is its own text view, sectioned
+// by ATX headings
+var Markdown Model = markdownModel{}
+
+type markdownModel struct{}
+
+func (markdownModel) Name() string                               { return "markdown" }
+func (markdownModel) View(source string) (string, error)         { return source, nil }
+func (markdownModel) Update(source, view string) (string, error) { return view, nil }
+func (markdownModel) Parse(view string) *DocumentStructure       { return ParseDocument(view) }
+
+// models maps lower-case file extensions to their model
+var models = map[string]Model{
+	".md":       Markdown,
+	".markdown": Markdown,
+	".mdx":      MDX,
+	".ipynb":    Notebook,
+}
+
+// Register makes files with the extension (e.g. ".rst") use the model
+func Register(ext string, m Model) {
+	models[strings.ToLower(ext)] = m
+}
+
+// ModelFor returns the model for a file, by extension; files with unknown
+// extensions are treated as markdown
+func ModelFor(path string) Model {
+	if m, ok := models[strings.ToLower(filepath.Ext(path))]; ok {
+		return m
+	}
+	return Markdown
+}
+
+// IsDocument reports whether a file name has the extension of a registered model
+func IsDocument(name string) bool {
+	_, ok := models[strings.ToLower(filepath.Ext(name))]
+	return ok
+}
+
+// Extensions returns the extensions with a registered model, sorted
+func Extensions() []string {
+	exts := make([]string, 0, len(models))
+	for ext := range models {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
@@ -0,0 +1,136 @@
+package markdown
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestModelFor(t *testing.T) {
+	tests := map[string]Model{
+		"doc.md":          Markdown,
+		"README.MARKDOWN": Markdown,
+		"page.mdx":        MDX,
+		"analysis.ipynb":  Notebook,
+		"notes.txt":       Markdown,
+	}
+	for path, want := range tests {
+		if got := ModelFor(path); got != want {
+			t.Errorf("ModelFor(%q) = %s, want %s", path, got.Name(), want.Name())
+		}
+	}
+	if IsDocument("notes.txt") || !IsDocument("Analysis.IPYNB") {
+		t.Error("IsDocument should accept registered extensions only, ignoring case")
+	}
+}
+
+func TestMDXIgnoresJSXAndCode(t *testing.T) {
+	content := `import { Tabs } from './tabs'
+
+# Guide
+
+<Tabs>
+# Not a section
+</Tabs>
+
+<Callout
+  type="warning"
+/>
+
+## Install
+
+` + "```md\n# Example heading\n```" + `
+
+<Note># Inline</Note>
+
+## Usage`
+
+	doc := MDX.Parse(content)
+	paths := doc.ListAllPaths()
+	want := []string{"Guide", "Guide > Install", "Guide > Usage"}
+	if strings.Join(paths, "|") != strings.Join(want, "|") {
+		t.Errorf("Sections = %v, want %v", paths, want)
+	}
+}
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Analysis\n",
+    "Load the data <first>."
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {},
+   "outputs": [],
+   "source": "# a comment, not a heading\nimport pandas as pd\n"
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestNotebookView(t *testing.T) {
+	view, err := Notebook.View(testNotebook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "%% cell 1 [markdown]\n# Analysis\nLoad the data <first>.\n%% cell 2 [code]\n# a comment, not a heading\nimport pandas as pd\n"
+	if view != want {
+		t.Fatalf("View = %q, want %q", view, want)
+	}
+
+	doc := Notebook.Parse(view)
+	paths := doc.ListAllPaths()
+	if strings.Join(paths, "|") != "Cell 1|Cell 1 > Analysis|Cell 2" {
+		t.Errorf("Sections = %v", paths)
+	}
+	if s := doc.SectionsByLine[6]; s == nil || s.Title != "Cell 2" {
+		t.Errorf("Line 6 should be in Cell 2, got %+v", s)
+	}
+}
+
+func TestNotebookUpdate(t *testing.T) {
+	view, _ := Notebook.View(testNotebook)
+	edited := strings.Replace(view, "Load the data", "Load the cleaned data", 1)
+
+	updated, err := Notebook.Update(testNotebook, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Notebook.View(updated); got != edited {
+		t.Errorf("View after update = %q, want %q", got, edited)
+	}
+
+	var nb struct {
+		Cells []struct {
+			Source         []string `json:"source"`
+			ExecutionCount *int     `json:"execution_count"`
+		} `json:"cells"`
+		NbformatMinor int `json:"nbformat_minor"`
+	}
+	if err := json.Unmarshal([]byte(updated), &nb); err != nil {
+		t.Fatal(err)
+	}
+	if nb.NbformatMinor != 5 || nb.Cells[1].ExecutionCount == nil || *nb.Cells[1].ExecutionCount != 1 {
+		t.Error("Fields outside the cell sources should be kept")
+	}
+	if got := strings.Join(nb.Cells[1].Source, "|"); got != "# a comment, not a heading\n|import pandas as pd\n" {
+		t.Errorf("Code cell source = %q", got)
+	}
+	if !strings.Contains(updated, "<first>") {
+		t.Error("HTML in cells should not be escaped")
+	}
+
+	// Cells can't be added or removed through the view
+	if _, err := Notebook.Update(testNotebook, edited+"%% cell 3 [code]\nx = 1"); err == nil {
+		t.Error("Expected an error when the view adds a cell")
+	}
+}
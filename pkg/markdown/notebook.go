@@ -0,0 +1,207 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Notebook is the model for Jupyter notebooks (.ipynb). The text view lists
+// the cells in order, each after a marker line giving its number and type:
+//
+//	%% cell 1 [markdown]
+//	# Analysis
+//	%% cell 2 [code]
+//	import pandas as pd
+//
+// Each cell is a top-level section titled "Cell N", so comments anchor to
+// cells; headings in markdown cells are sections within their cell. Outputs
+// and metadata are not part of the view and are kept as they are on update.
+var Notebook Model = notebookModel{}
+
+type notebookModel struct{}
+
+// Matches a cell marker line in a notebook's text view
+var cellMarkerRegex = regexp.MustCompile(`^%% cell (\d+) \[([\w-]+)\]$`)
+
+// notebookCell is a cell's marker and source lines, as in the text view
+type notebookCell struct {
+	kind  string
+	lines []string
+}
+
+func (notebookModel) Name() string { return "notebook" }
+
+// View renders the notebook's cells as text
+func (notebookModel) View(source string) (string, error) {
+	_, cells, err := decodeNotebook(source)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for i, cell := range cells {
+		var kind string
+		_ = json.Unmarshal(cell["cell_type"], &kind)
+		text, err := cellSource(cell["source"])
+		if err != nil {
+			return "", fmt.Errorf("cell %d: %w", i+1, err)
+		}
+		lines = append(lines, fmt.Sprintf("%%%% cell %d [%s]", i+1, kind))
+		if text != "" {
+			lines = append(lines, strings.Split(text, "\n")...)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Update writes the cell sources of an edited view back into the notebook.
+// Cells can be edited but not added, removed, or reordered.
+func (notebookModel) Update(source, view string) (string, error) {
+	nb, cells, err := decodeNotebook(source)
+	if err != nil {
+		return "", err
+	}
+	edited, err := parseCells(view)
+	if err != nil {
+		return "", err
+	}
+	if len(edited) != len(cells) {
+		return "", fmt.Errorf("notebook has %d cells but the edited view has %d; cells can't be added or removed", len(cells), len(edited))
+	}
+
+	for i, cell := range cells {
+		var kind string
+		_ = json.Unmarshal(cell["cell_type"], &kind)
+		if edited[i].kind != kind {
+			return "", fmt.Errorf("cell %d is %s but the edited view has %s", i+1, kind, edited[i].kind)
+		}
+		src, err := marshalUnescaped(sourceLines(edited[i].lines))
+		if err != nil {
+			return "", err
+		}
+		cell["source"] = src
+	}
+	if nb["cells"], err = marshalUnescaped(cells); err != nil {
+		return "", err
+	}
+
+	// Jupyter writes sorted keys, one-space indents, and a final newline;
+	// match it so saves give small diffs
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(nb); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// marshalUnescaped is json.Marshal without escaping <, >, and &, which
+// Jupyter leaves as they are
+func marshalUnescaped(v any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Parse makes each cell a section, with the headings of markdown cells
+// nested one level below it
+func (notebookModel) Parse(view string) *DocumentStructure {
+	lines := strings.Split(view, "\n")
+
+	headings := []headingInfo{}
+	kind := ""
+	for i, line := range lines {
+		if m := cellMarkerRegex.FindStringSubmatch(line); m != nil {
+			kind = m[2]
+			headings = append(headings, headingInfo{level: 1, title: "Cell " + m[1], lineNumber: i + 1})
+			continue
+		}
+		if kind != "markdown" {
+			continue // "# " in code cells is a comment, not a heading
+		}
+		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
+			headings = append(headings, headingInfo{
+				level:      len(matches[1]) + 1,
+				title:      strings.TrimSpace(matches[2]),
+				lineNumber: i + 1,
+			})
+		}
+	}
+
+	return newStructure(headings, len(lines))
+}
+
+// decodeNotebook returns a notebook's top-level fields and its cells, keeping
+// every field as raw JSON so what the model doesn't touch round-trips
+func decodeNotebook(source string) (map[string]json.RawMessage, []map[string]json.RawMessage, error) {
+	var nb map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(source), &nb); err != nil {
+		return nil, nil, fmt.Errorf("not a Jupyter notebook: %w", err)
+	}
+	var cells []map[string]json.RawMessage
+	if raw, ok := nb["cells"]; ok {
+		if err := json.Unmarshal(raw, &cells); err != nil {
+			return nil, nil, fmt.Errorf("not a Jupyter notebook: cells: %w", err)
+		}
+	}
+	return nb, cells, nil
+}
+
+// cellSource returns a cell's source, which notebooks store as either a
+// string or a list of lines
+func cellSource(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return "", fmt.Errorf("source is neither a string nor a list of lines")
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// sourceLines converts lines back to a notebook source list, where each
+// line keeps its newline
+func sourceLines(lines []string) []string {
+	source := strings.SplitAfter(strings.Join(lines, "\n"), "\n")
+	if source[len(source)-1] == "" {
+		source = source[:len(source)-1]
+	}
+	return source
+}
+
+// parseCells splits a text view back into cells
+func parseCells(view string) ([]notebookCell, error) {
+	var cells []notebookCell
+	for i, line := range strings.Split(view, "\n") {
+		if m := cellMarkerRegex.FindStringSubmatch(line); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n != len(cells)+1 {
+				return nil, fmt.Errorf("line %d: expected cell %d, found cell %d", i+1, len(cells)+1, n)
+			}
+			cells = append(cells, notebookCell{kind: m[2]})
+			continue
+		}
+		if len(cells) == 0 {
+			if line == "" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: text before the first cell marker", i+1)
+		}
+		cells[len(cells)-1].lines = append(cells[len(cells)-1].lines, line)
+	}
+	return cells, nil
+}
@@ -30,19 +30,31 @@ func ParseDocument(content string) *DocumentStructure {
 		}
 	}
 
-	// Second pass: build sections with hierarchy
-	sections := buildSectionHierarchy(headings, len(lines))
+	return newStructure(headings, len(lines))
+}
+
+// newStructure builds the section hierarchy and lookup maps from headings
+// found by any document model
+func newStructure(headings []headingInfo, totalLines int) *DocumentStructure {
+	// Build sections with hierarchy
+	sections := buildSectionHierarchy(headings, totalLines)
 
-	// Third pass: build lookup maps
+	// Build lookup maps
 	sectionsByID := make(map[string]*Section)
 	sectionsByLine := make(map[int]*Section)
 
 	populateLookupMaps(sections, sectionsByID, sectionsByLine)
 
+	sectionsBySlug := make(map[string]*Section, len(sectionsByID))
+	for _, section := range sectionsByID {
+		sectionsBySlug[section.Slug] = section
+	}
+
 	return &DocumentStructure{
 		Sections:       sections,
 		SectionsByID:   sectionsByID,
 		SectionsByLine: sectionsByLine,
+		SectionsBySlug: sectionsBySlug,
 	}
 }
 
@@ -72,6 +84,8 @@ func buildSectionHierarchy(headings []headingInfo, totalLines int) []*Section {
 		}
 	}
 
+	assignSlugs(sections)
+
 	// Calculate end lines: each section ends at the line before the next same-or-higher level heading
 	for i := 0; i < len(sections); i++ {
 		for j := i + 1; j < len(sections); j++ {
@@ -381,3 +381,32 @@ Line 8`
 		t.Errorf("Section 2 end line: expected 8, got %d", doc.Sections[1].EndLine)
 	}
 }
+
+func TestSlugs(t *testing.T) {
+	tests := map[string]string{
+		"Key Points":            "key-points",
+		"What's new in v2.0?":   "whats-new-in-v20",
+		"snake_case & dashes-1": "snake_case--dashes-1",
+		"Ünïcode Heading":       "ünïcode-heading",
+	}
+	for title, want := range tests {
+		if got := Slugify(title); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+
+	doc := ParseDocument("# Notes\n## Notes\n# Notes 1\n# Notes\n# !!!")
+	var slugs []string
+	for _, id := range []string{"s1", "s2", "s3", "s4", "s5"} {
+		slugs = append(slugs, doc.SectionsByID[id].Slug)
+	}
+	want := []string{"notes", "notes-1", "notes-1-1", "notes-2", "section"}
+	for i := range want {
+		if slugs[i] != want[i] {
+			t.Fatalf("Slugs = %v, want %v", slugs, want)
+		}
+	}
+	if s := doc.FindSlug("#notes-2"); s == nil || s.StartLine != 4 {
+		t.Errorf("FindSlug(#notes-2) = %+v, want the heading on line 4", s)
+	}
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Slugify returns the anchor GitHub generates for a heading: lower case,
+// punctuation removed, and spaces turned into hyphens ("Key Points!" ->
+// "key-points")
+func Slugify(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// assignSlugs gives each section (in document order) its heading's slug.
+// Repeated slugs get "-1", "-2", ... suffixes, as on GitHub, and headings
+// with no letters or digits are "section".
+func assignSlugs(sections []*Section) {
+	used := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		base := Slugify(section.Title)
+		if base == "" {
+			base = "section"
+		}
+		slug := base
+		for n := 1; used[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[slug] = true
+		section.Slug = slug
+	}
+}
+
+// FindSlug returns the section with the given slug (a leading "#" is
+// ignored), or nil
+func (d *DocumentStructure) FindSlug(slug string) *Section {
+	return d.SectionsBySlug[strings.TrimPrefix(slug, "#")]
+}
@@ -5,6 +5,7 @@ type Section struct {
 	ID        string     // Unique identifier (e.g., "s1", "s2")
 	Level     int        // Heading level (1 for #, 2 for ##, etc.)
 	Title     string     // Heading text without the # markers
+	Slug      string     // Anchor of the heading (e.g., "key-points"), unique within the document
 	StartLine int        // Line number where this section starts (the heading line)
 	EndLine   int        // Line number where this section ends (before next same/higher level heading)
 	ParentID  string     // ID of parent section (empty for top-level sections)
@@ -16,6 +17,7 @@ type DocumentStructure struct {
 	Sections       []*Section       // Top-level sections
 	SectionsByID   map[string]*Section // Quick lookup by section ID
 	SectionsByLine map[int]*Section    // Quick lookup by line number (maps to closest section above)
+	SectionsBySlug map[string]*Section // Quick lookup by heading slug
 }
 
 // GetPath returns the hierarchical path of a section (e.g., "Introduction > Overview > Key Points")
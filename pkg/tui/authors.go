@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// authorPalette holds readable foreground colors on dark and light terminals.
+// Type colors (red blockers, yellow questions) are left out so an author
+// color isn't mistaken for a comment type.
+var authorPalette = []lipgloss.Color{
+	"39",  // Sky blue
+	"42",  // Green
+	"135", // Purple
+	"208", // Orange
+	"44",  // Teal
+	"170", // Pink
+	"112", // Lime
+	"75",  // Steel blue
+	"178", // Gold
+	"141", // Lavender
+}
+
+// authorColor returns a stable color for an author
+// The same name always hashes to the same color across sessions.
+func authorColor(author string) lipgloss.Color {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(author)))
+	return authorPalette[h.Sum32()%uint32(len(authorPalette))]
+}
+
+// authorStyle returns the bold, colored style used for an author's name
+func authorStyle(author string) lipgloss.Style {
+	return lipgloss.NewStyle().Bold(true).Foreground(authorColor(author))
+}
+
+// authorAvatar renders a one-letter block in the author's color
+func authorAvatar(author string) string {
+	initial := "?"
+	for _, r := range author {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			initial = string(unicode.ToUpper(r))
+			break
+		}
+	}
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("0")).
+		Background(authorColor(author)).
+		Render(" " + initial + " ")
+}
+
+// renderAuthor renders "@name" in the author's color, prefixed with an
+// avatar block when avatars are on. base supplies the surrounding style
+// (e.g. the selected comment's background).
+func (m *Model) renderAuthor(author string, base lipgloss.Style) string {
+	name := authorStyle(author).Inherit(base).Render("@" + author)
+	if m.showAvatars {
+		return authorAvatar(author) + base.Render(" ") + name
+	}
+	return name
+}
+
+// lineAuthor returns the author shared by every thread on a line, or ""
+// when several people commented there
+func lineAuthor(threads []*comment.Comment) string {
+	if len(threads) == 0 {
+		return ""
+	}
+	for _, thread := range threads[1:] {
+		if thread.Author != threads[0].Author {
+			return ""
+		}
+	}
+	return threads[0].Author
+}
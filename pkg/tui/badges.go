@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// fileBadge summarizes a document's comments in the file picker
+type fileBadge struct {
+	Open        int // Unresolved threads
+	Blockers    int // Unresolved threads of type B
+	Suggestions int // Pending suggestions
+}
+
+// String renders the badge, e.g. "12 open, 2 blockers"
+func (b fileBadge) String() string {
+	parts := []string{fmt.Sprintf("%d open", b.Open)}
+	if b.Blockers > 0 {
+		parts = append(parts, plural(b.Blockers, "blocker"))
+	}
+	if b.Suggestions > 0 {
+		parts = append(parts, plural(b.Suggestions, "suggestion"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural formats a count with a noun, made plural unless the count is one
+// ("2 blockers", "3 replies")
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	if stem, ok := strings.CutSuffix(noun, "y"); ok {
+		return fmt.Sprintf("%d %sies", n, stem)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// badgesMsg delivers the badges of the commented files in a directory
+type badgesMsg struct {
+	dir    string
+	badges map[string]fileBadge // By file name
+}
+
+// loadBadges reads the sidecars of the markdown files in dir in the
+// background. Unreadable sidecars get no badge.
+func loadBadges(dir string) tea.Cmd {
+	return func() tea.Msg {
+		sidecars, err := comment.ListSidecars(dir)
+		if err != nil {
+			return badgesMsg{dir: dir}
+		}
+		docs := make([]string, len(sidecars))
+		for i, sidecar := range sidecars {
+			docs[i] = comment.MarkdownForSidecar(dir, sidecar)
+		}
+
+		badges := make(map[string]fileBadge, len(docs))
+		for result := range comment.ReadDocumentsParallel(docs, 0) {
+			if result.Err != nil {
+				continue
+			}
+			var badge fileBadge
+			for _, thread := range result.Doc.Threads {
+				switch {
+				case thread.IsPending():
+					badge.Suggestions++
+				case thread.IsSuggestion, thread.Resolved:
+				default:
+					badge.Open++
+					if thread.Type == "B" {
+						badge.Blockers++
+					}
+				}
+			}
+			if badge != (fileBadge{}) {
+				badges[filepath.Base(result.Path)] = badge
+			}
+		}
+		return badgesMsg{dir: dir, badges: badges}
+	}
+}
+
+// refreshBadges starts loading badges for the file picker's directory
+func (m *Model) refreshBadges() tea.Cmd {
+	m.badgeDir = m.filePicker.CurrentDirectory
+	return loadBadges(m.badgeDir)
+}
+
+// followPickerDir reloads badges when the file picker has moved to another
+// directory, and returns nil otherwise
+func (m *Model) followPickerDir() tea.Cmd {
+	if m.filePicker.CurrentDirectory == m.badgeDir {
+		return nil
+	}
+	m.badges = nil
+	return m.refreshBadges()
+}
+
+// handleBadges stores badges that arrive for the directory still shown
+func (m Model) handleBadges(msg badgesMsg) (tea.Model, tea.Cmd) {
+	if msg.dir == m.filePicker.CurrentDirectory {
+		m.badges = msg.badges
+	}
+	return m, nil
+}
+
+// badgeStyle renders file picker badges
+var badgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+// decoratePicker appends each commented file's badge to its row in the file
+// picker's output. Rows end with the file name, so the longest name a row
+// ends with identifies it.
+func (m Model) decoratePicker(view string) string {
+	if len(m.badges) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		match := ""
+		for name := range m.badges {
+			if len(name) > len(match) && strings.HasSuffix(plain, " "+name) {
+				match = name
+			}
+		}
+		if match != "" {
+			lines[i] = line + "  " + badgeStyle.Render("● "+m.badges[match].String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,16 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// changedBadge marks threads whose text was edited since they were made in
+// the comment pane, so reviewers can tell feedback that may be addressed
+func (m *Model) changedBadge(thread *comment.Comment) string {
+	if thread.IsOrphaned() || !thread.TargetChanged(strings.Split(m.doc.Content, "\n")) {
+		return ""
+	}
+	return " " + badgeStyle.Render("⚠ changed")
+}
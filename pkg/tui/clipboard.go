@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rcliao/comments/pkg/clipboard"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// copyKinds maps copy keys to what they copy from the selected comment
+var copyKinds = map[string]string{
+	"y": "text",      // Comment text
+	"Y": "id",        // Comment ID
+	"L": "reference", // Quoted text, comment, and a file#L12 link
+}
+
+// copyComment copies part of c to the clipboard and reports the result in
+// the status bar
+func (m *Model) copyComment(c *comment.Comment, kind string) {
+	var text, label string
+	switch kind {
+	case "text":
+		text, label = c.Text, "comment text"
+	case "id":
+		text, label = c.ID, "comment ID "+c.ID
+	case "reference":
+		text, label = comment.FormatReference(c, m.filename, m.doc.Content), "quote and link"
+	}
+
+	// The TUI owns stdout, which is where OSC52 has to go
+	method, err := clipboard.Copy(text, os.Stdout)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Copy failed: %v", err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Copied %s", label)
+	if method == clipboard.MethodTerminal {
+		m.statusMsg += " (via terminal)"
+	}
+}
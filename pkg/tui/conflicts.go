@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
+)
+
+// mergeEditedMsg is sent when the editor opened for a manual merge exits
+type mergeEditedMsg struct {
+	conflict comment.Conflict
+	template string
+	text     string
+	err      error
+}
+
+// openConflicts enters conflict resolution mode if pending suggestions overlap
+func (m Model) openConflicts() Model {
+	m.conflicts = comment.PendingConflicts(m.doc.Threads)
+	if len(m.conflicts) == 0 {
+		m.statusMsg = "No conflicting suggestions"
+		return m
+	}
+	m.conflictIndex = 0
+	m.mode = ModeConflicts
+	return m
+}
+
+// currentConflict returns the conflict being reviewed
+func (m Model) currentConflict() comment.Conflict {
+	return m.conflicts[m.conflictIndex]
+}
+
+// handleConflictsKeys handles keys while resolving overlapping suggestions
+// 1/2 pick a side, e merges both in $EDITOR, x rejects both.
+func (m Model) handleConflictsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeBrowse
+		m.conflicts = nil
+		return m, nil
+
+	case "n", "right", "l":
+		m.conflictIndex = (m.conflictIndex + 1) % len(m.conflicts)
+		return m, nil
+
+	case "p", "left", "h":
+		m.conflictIndex = (m.conflictIndex + len(m.conflicts) - 1) % len(m.conflicts)
+		return m, nil
+
+	case "1", "2":
+		if !m.canPerform(config.ActionAccept) || !m.canPerform(config.ActionReject) {
+			return m, nil
+		}
+		winner := m.currentConflict().Suggestion1
+		if msg.String() == "2" {
+			winner = m.currentConflict().Suggestion2
+		}
+		if !m.canAccept(winner) {
+			return m, nil
+		}
+		rejected, err := comment.PickSuggestion(m.doc, winner)
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("Cannot pick %s: %v", winner.ID, err)
+			return m, nil
+		}
+		ids := []string{}
+		for _, s := range rejected {
+			ids = append(ids, s.ID)
+		}
+		return m.afterConflictResolved(fmt.Sprintf("Accepted %s, rejected %s", winner.ID, strings.Join(ids, ", ")))
+
+	case "x":
+		if !m.canPerform(config.ActionReject) {
+			return m, nil
+		}
+		c := m.currentConflict()
+		if err := comment.RejectConflict(m.doc, c); err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m.afterConflictResolved(fmt.Sprintf("Rejected %s and %s", c.Suggestion1.ID, c.Suggestion2.ID))
+
+	case "e":
+		if !m.canPerform(config.ActionAccept) || !m.canPerform(config.ActionReject) {
+			return m, nil
+		}
+		return m, m.editMerge(m.currentConflict())
+	}
+
+	return m, nil
+}
+
+// editMerge opens the merge template for c in the user's editor
+func (m *Model) editMerge(c comment.Conflict) tea.Cmd {
+	template, err := comment.MergeTemplate(m.doc.Content, c)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Cannot merge: %v", err)
+		return nil
+	}
+
+	cmd, err := editInEditor(template, "merge-*.md", func(text string, err error) tea.Msg {
+		return mergeEditedMsg{conflict: c, template: template, text: text, err: err}
+	})
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Cannot merge: %v", err)
+		return nil
+	}
+	return cmd
+}
+
+// handleMergeEdited applies the merged text once the editor exits
+func (m Model) handleMergeEdited(msg mergeEditedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeConflicts {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.statusMsg = fmt.Sprintf("Editor failed: %v", msg.err)
+		return m, nil
+	}
+	if msg.text == msg.template {
+		m.statusMsg = "Merge cancelled: the text was not edited"
+		return m, nil
+	}
+
+	merged, err := comment.MergeSuggestions(m.doc, msg.conflict, m.author, msg.text)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Cannot merge: %v", err)
+		return m, nil
+	}
+	return m.afterConflictResolved(fmt.Sprintf("Merged %s and %s as %s",
+		msg.conflict.Suggestion1.ID, msg.conflict.Suggestion2.ID, merged.ID))
+}
+
+// afterConflictResolved saves the document and moves on to the next conflict,
+// returning to browse mode once none are left
+func (m Model) afterConflictResolved(status string) (tea.Model, tea.Cmd) {
+	if err := m.saveDocument(); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.documentSections = m.doc.Structure()
+	m.documentViewport.SetContent(m.renderDocument())
+	m.commentViewport.SetContent(m.renderComments())
+
+	m.statusMsg = status
+	m.conflicts = comment.PendingConflicts(m.doc.Threads)
+	if len(m.conflicts) == 0 {
+		m.mode = ModeBrowse
+		m.statusMsg = status + " • all conflicts resolved"
+		return m, nil
+	}
+	m.conflictIndex = min(m.conflictIndex, len(m.conflicts)-1)
+	return m, nil
+}
+
+// viewConflicts renders the current conflict with both proposals side by side
+func (m Model) viewConflicts() string {
+	c := m.currentConflict()
+	start, end := c.Range()
+
+	title := titleStyle.Render(fmt.Sprintf("Conflict %d of %d: %s, lines %d-%d",
+		m.conflictIndex+1, len(m.conflicts), c.Type, start, end))
+
+	columnWidth := max((m.width-6)/2, 20)
+	columns := []string{}
+	for i, s := range []*comment.Comment{c.Suggestion1, c.Suggestion2} {
+		result, err := comment.ConflictSide(m.doc.Content, start, end, s)
+		if err != nil {
+			result = fmt.Sprintf("(cannot apply: %v)", err)
+		}
+		header := lipgloss.NewStyle().Bold(true).Render(
+			fmt.Sprintf("%d. %s @%s (lines %d-%d)", i+1, s.ID, s.Author, s.StartLine, s.EndLine))
+		columns = append(columns, conflictColumnStyle.Width(columnWidth).Render(
+			lipgloss.JoinVertical(lipgloss.Left, header, s.Text, "", result)))
+	}
+
+	help := m.renderHelp("1/2: pick side • e: merge in $EDITOR • x: reject both • n/p: next/prev conflict • Esc: back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+		"",
+		help,
+	)
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// reasonRequired reports whether the policy requires a reason to accept or
+// reject a suggestion
+func (m *Model) reasonRequired() bool {
+	return m.policy != nil && m.policy.RequireReason
+}
+
+// askReason opens the reply box to ask why the selected suggestion is being
+// accepted or rejected; the decision is made once the reason is saved
+func (m Model) askReason(decision string) (tea.Model, tea.Cmd) {
+	m.pendingDecision = decision
+	m.mode = ModeReply
+	m.commentInput.Reset()
+	m.commentInput.Focus()
+	return m, textarea.Blink
+}
+
+// finishDecision accepts or rejects the suggestion that was waiting for a
+// reason
+func (m Model) finishDecision(reason string) (tea.Model, tea.Cmd) {
+	decision := m.pendingDecision
+	m.pendingDecision = ""
+	m.commentInput.Reset()
+	if decision == comment.DecisionAccepted {
+		return m.acceptSelectedSuggestion(reason)
+	}
+	return m.rejectSelectedSuggestion(reason)
+}
+
+// recordReason adds the reason for the selected suggestion's decision as a
+// reply; an empty reason records nothing
+func (m *Model) recordReason(reason string) {
+	if reason == "" {
+		return
+	}
+	reply := comment.AddDecisionReply(m.selectedSuggestion, m.author, reason)
+	m.doc.AttachToOpenReview(reply)
+	if err := m.signComment(reply); err != nil {
+		m.err = err
+	}
+}
+
+// acceptSelectedSuggestion applies the selected suggestion to the document
+// and marks it accepted
+func (m Model) acceptSelectedSuggestion(reason string) (tea.Model, tea.Cmd) {
+	if m.selectedSuggestion == nil {
+		m.mode = ModeThreadView
+		return m, nil
+	}
+
+	// Dependencies must be applied first
+	if unmet := comment.UnmetDependencies(m.selectedSuggestion, m.doc.Threads); len(unmet) > 0 {
+		m.statusMsg = fmt.Sprintf("Depends on %s, which has not been accepted yet", strings.Join(unmet, ", "))
+		m.mode = ModeThreadView
+		m.selectedSuggestion = nil
+		m.suggestionPreview = ""
+		return m, nil
+	}
+
+	// Apply suggestion to document
+	newContent, err := comment.ApplySuggestion(m.doc.Content, m.selectedSuggestion)
+	if err != nil {
+		m.err = fmt.Errorf("failed to apply suggestion: %w", err)
+		m.mode = ModeThreadView
+		m.selectedSuggestion = nil
+		m.suggestionPreview = ""
+		return m, nil
+	}
+
+	// Update document content
+	m.doc.Content = newContent
+
+	// Mark suggestion as accepted using helper
+	if err := comment.AcceptSuggestion(m.doc.Threads, m.selectedSuggestion.ID); err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m.recordReason(reason)
+
+	// Recalculate comment line numbers and sections (the edit may change headings)
+	m.doc.RecordApplied(m.selectedSuggestion)
+
+	// Keep a backup of the sidecar when the project asks for one
+	if m.policy != nil {
+		if _, err := comment.BackupSidecar(m.filename, m.policy.Backups.Keep); err != nil {
+			m.err = err
+			return m, nil
+		}
+	}
+
+	// Save document
+	if err := m.saveDocument(); err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	// Refresh all views
+	m.documentSections = m.doc.Structure()
+	m.documentViewport.SetContent(m.renderDocument())
+	m.commentViewport.SetContent(m.renderComments())
+
+	// Return to thread view
+	m.mode = ModeThreadView
+	m.threadViewport.SetContent(m.renderThread())
+	m.selectedSuggestion = nil
+	m.suggestionPreview = ""
+	return m, nil
+}
+
+// rejectSelectedSuggestion marks the selected suggestion rejected
+func (m Model) rejectSelectedSuggestion(reason string) (tea.Model, tea.Cmd) {
+	suggestion := m.selectedSuggestion
+	m.mode = ModeThreadView
+	if suggestion == nil {
+		return m, nil
+	}
+
+	if err := comment.RejectSuggestion(m.doc.Threads, suggestion.ID); err != nil {
+		m.err = fmt.Errorf("failed to reject suggestion: %w", err)
+		m.selectedSuggestion = nil
+		return m, nil
+	}
+	m.recordReason(reason)
+	m.selectedSuggestion = nil
+
+	// Save document
+	if err := comment.SaveToSidecar(m.filename, m.doc); err != nil {
+		m.err = fmt.Errorf("failed to save: %w", err)
+	}
+	// Refresh thread view
+	m.threadViewport.SetContent(m.renderThread())
+	return m, nil
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// composeEditedMsg is sent when the editor opened with Ctrl+E while writing
+// a comment or reply exits
+type composeEditedMsg struct {
+	text string
+	err  error
+}
+
+// editInEditor writes initial to a temp file named after pattern, suspends
+// the TUI while $VISUAL (or $EDITOR, or vi) edits it, and passes the edited
+// text to done once the editor exits
+func editInEditor(initial, pattern string, done func(text string, err error) tea.Msg) (tea.Cmd, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	_, err = f.WriteString(initial)
+	f.Close()
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return done("", err)
+		}
+		edited, err := os.ReadFile(path)
+		return done(string(edited), err)
+	}), nil
+}
+
+// composeInEditor opens the comment or reply being written in the user's
+// editor
+func (m *Model) composeInEditor() tea.Cmd {
+	cmd, err := editInEditor(m.commentInput.Value(), "comment-*.md", func(text string, err error) tea.Msg {
+		return composeEditedMsg{text: text, err: err}
+	})
+	if err != nil {
+		m.statusMsg = "Cannot open editor: " + err.Error()
+		return nil
+	}
+	return cmd
+}
+
+// handleComposeEdited puts the text written in the editor back into the
+// comment input
+func (m Model) handleComposeEdited(msg composeEditedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeAddComment && m.mode != ModeReply {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.statusMsg = "Editor failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.commentInput.SetValue(strings.TrimRight(msg.text, " \t\r\n"))
+	m.commentInput.Focus()
+	return m, nil
+}
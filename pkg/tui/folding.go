@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// recentReplies is how many of a thread's latest replies the thread view
+// shows before folding older ones away (z shows them)
+const recentReplies = 5
+
+// shownReply is a reply listed in the thread view, with its nesting depth
+type shownReply struct {
+	reply *comment.Comment
+	depth int
+}
+
+// foldedReplies returns the selected thread's replies the thread view shows
+// at the top level, and how many older ones are folded away
+func (m *Model) foldedReplies() (replies []*comment.Comment, hidden int) {
+	replies = m.selectedThread.Replies
+	if m.showOlderReplies || len(replies) <= recentReplies {
+		return replies, 0
+	}
+	hidden = len(replies) - recentReplies
+	return replies[hidden:], hidden
+}
+
+// shownReplies lists the replies the thread view shows, depth first, leaving
+// out folded older replies and the contents of collapsed subtrees
+func (m *Model) shownReplies() []shownReply {
+	if m.selectedThread == nil {
+		return nil
+	}
+
+	var shown []shownReply
+	var walk func(replies []*comment.Comment, depth int)
+	walk = func(replies []*comment.Comment, depth int) {
+		for _, reply := range replies {
+			shown = append(shown, shownReply{reply: reply, depth: depth})
+			if !m.collapsedReplies[reply.ID] {
+				walk(reply.Replies, depth+1)
+			}
+		}
+	}
+	top, _ := m.foldedReplies()
+	walk(top, 0)
+	return shown
+}
+
+// resetFolding folds a newly opened thread back to its latest replies
+func (m *Model) resetFolding() {
+	m.showOlderReplies = false
+	m.collapsedReplies = map[string]bool{}
+	m.replyCursor = ""
+}
+
+// handleFoldKeys handles the thread view's folding keys, reporting whether
+// key was one of them:
+//   - z shows or folds older replies
+//   - [ and ] select the previous or next reply
+//   - Enter collapses or expands the selected reply's nested replies
+//   - Z collapses every subtree, or expands them all if any is collapsed
+func (m Model) handleFoldKeys(key string) (Model, bool) {
+	if m.selectedThread == nil {
+		return m, false
+	}
+	if m.collapsedReplies == nil {
+		m.collapsedReplies = map[string]bool{}
+	}
+
+	switch key {
+	case "z":
+		m.showOlderReplies = !m.showOlderReplies
+
+	case "[", "]":
+		shown := m.shownReplies()
+		if len(shown) == 0 {
+			return m, true
+		}
+		i := shownIndex(shown, m.replyCursor)
+		switch {
+		case i < 0 && key == "]":
+			i = 0
+		case i < 0:
+			i = len(shown) - 1
+		case key == "]":
+			i = min(i+1, len(shown)-1)
+		default:
+			i = max(i-1, 0)
+		}
+		m.replyCursor = shown[i].reply.ID
+
+	case "enter":
+		reply := m.doc.FindCommentByID(m.replyCursor)
+		if reply == nil || len(reply.Replies) == 0 {
+			return m, true
+		}
+		m.collapsedReplies[reply.ID] = !m.collapsedReplies[reply.ID]
+
+	case "Z":
+		if anyTrue(m.collapsedReplies) {
+			m.collapsedReplies = map[string]bool{}
+			break
+		}
+		collapseAll(m.selectedThread.Replies, m.collapsedReplies)
+
+	default:
+		return m, false
+	}
+
+	// Drop a selection that was folded out of view
+	if shownIndex(m.shownReplies(), m.replyCursor) < 0 {
+		m.replyCursor = ""
+	}
+	m.threadViewport.SetContent(m.renderThread())
+	m.scrollToReplyCursor()
+	return m, true
+}
+
+// scrollToReplyCursor scrolls the thread viewport to the selected reply if
+// it is out of view
+func (m *Model) scrollToReplyCursor() {
+	if m.replyCursor == "" {
+		return
+	}
+	top := m.threadViewport.YOffset
+	if m.replyCursorRow < top || m.replyCursorRow >= top+m.threadViewport.Height {
+		m.threadViewport.SetYOffset(max(m.replyCursorRow-1, 0))
+	}
+}
+
+// shownIndex returns the position of the reply with the given ID, or -1
+func shownIndex(shown []shownReply, id string) int {
+	for i, s := range shown {
+		if s.reply.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// collapseAll marks every reply with nested replies as collapsed
+func collapseAll(replies []*comment.Comment, collapsed map[string]bool) {
+	for _, reply := range replies {
+		if len(reply.Replies) > 0 {
+			collapsed[reply.ID] = true
+			collapseAll(reply.Replies, collapsed)
+		}
+	}
+}
+
+// anyTrue reports whether any value in set is true
+func anyTrue(set map[string]bool) bool {
+	for _, v := range set {
+		if v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// visibleThreads returns the threads listed in the comment pane, pinned
+// threads first, then the most urgent (see comment.Score). When following the document scroll, only document-level
+// threads and threads overlapping the region last scrolled to are listed, in
+// line order.
+func (m *Model) visibleThreads() []*comment.Comment {
+	threads := comment.GetVisibleComments(m.doc.Threads, m.showResolved)
+	if !m.followScroll {
+		// Most urgent first (see comment.Score)
+		comment.SortByScore(threads)
+		comment.PinnedFirst(threads)
+		return threads
+	}
+
+	near := comment.Or(
+		(*comment.Comment).IsDocumentLevel,
+		comment.ByLineRange(m.followFirstLine, m.followLastLine),
+	).Apply(threads)
+	// Document-level threads have line 0, so they stay first
+	sort.SliceStable(near, func(i, j int) bool {
+		return near[i].Line < near[j].Line
+	})
+	comment.PinnedFirst(near)
+	return near
+}
+
+// visibleLineRange returns the first and last document lines shown in the
+// document viewport
+func (m *Model) visibleLineRange() (int, int) {
+	// Same wrap width as renderDocument
+	availableWidth := m.documentViewport.Width - 10
+	if availableWidth < 40 {
+		availableWidth = 40
+	}
+	layout := comment.NewDisplayLayout(m.doc.Content, availableWidth)
+
+	lastRow := layout.RowCount() - 1
+	topRow := min(m.documentViewport.YOffset, lastRow)
+	bottomRow := min(m.documentViewport.YOffset+m.documentViewport.Height-1, lastRow)
+
+	first, _, err := layout.RowToPosition(max(topRow, 0), 1)
+	if err != nil {
+		first = 1
+	}
+	last, _, err := layout.RowToPosition(max(bottomRow, 0), 1)
+	if err != nil {
+		last = layout.LineCount()
+	}
+	return first, last
+}
+
+// syncFollow narrows the comment pane to the visible document region, keeping
+// the selected thread selected if it is still listed
+func (m *Model) syncFollow() {
+	if m.doc == nil {
+		return
+	}
+
+	var selected *comment.Comment
+	if threads := m.visibleThreads(); m.selectedComment < len(threads) {
+		selected = threads[m.selectedComment]
+	}
+
+	m.followFirstLine, m.followLastLine = m.visibleLineRange()
+
+	m.selectedComment = 0
+	for i, t := range m.visibleThreads() {
+		if t == selected {
+			m.selectedComment = i
+			break
+		}
+	}
+	m.commentViewport.SetContent(m.renderComments())
+}
+
+// toggleFollow switches between listing every thread and only those near
+// the visible part of the document
+func (m *Model) toggleFollow() {
+	var selected *comment.Comment
+	if threads := m.visibleThreads(); m.selectedComment < len(threads) {
+		selected = threads[m.selectedComment]
+	}
+
+	m.followScroll = !m.followScroll
+	if m.followScroll {
+		m.syncFollow()
+		m.statusMsg = "Comment pane follows the document"
+		return
+	}
+
+	m.selectedComment = 0
+	for i, t := range m.visibleThreads() {
+		if t == selected {
+			m.selectedComment = i
+			break
+		}
+	}
+	m.commentViewport.SetContent(m.renderComments())
+	m.statusMsg = "Comment pane shows all threads"
+}
+
+// scrollDocument scrolls the document pane by half a page (down if down is
+// true) and updates the comment pane when it follows the document
+func (m *Model) scrollDocument(down bool) {
+	if down {
+		m.documentViewport.HalfViewDown()
+	} else {
+		m.documentViewport.HalfViewUp()
+	}
+	if m.followScroll {
+		m.syncFollow()
+	}
+}
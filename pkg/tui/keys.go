@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyBinding is a key (or group of keys) and what it does in a mode
+type keyBinding struct {
+	keys string // As shown to the user, e.g. "j/k" or "Ctrl+S"
+	help string
+
+	// when limits the binding to some states (e.g. a workspace is open);
+	// nil means always
+	when func(m *Model) bool
+}
+
+// motionBindings are the vim-style motions of line and range selection
+// (see motions.go)
+var motionBindings = []keyBinding{
+	{keys: "10j / 5k", help: "Move by a count"},
+	{keys: "{ / }", help: "Previous / next paragraph"},
+	{keys: ":N or NG", help: "Go to line N"},
+	{keys: "/text", help: "Search forward"},
+	{keys: "n / N", help: "Repeat search forward / backward"},
+}
+
+// keymap is the registry of every mode's key bindings, in the order the help
+// overlay lists them. Keep it in step with the mode's key handler.
+var keymap = map[ViewMode][]keyBinding{
+	ModeFilePicker: {
+		{keys: "↑/↓ or j/k", help: "Move"},
+		{keys: "Enter / l / →", help: "Open directory or select file"},
+		{keys: "h / ← / Backspace", help: "Parent directory"},
+		{keys: "g / G", help: "First / last entry"},
+		{keys: "q / Ctrl+C", help: "Quit"},
+	},
+	ModeBrowse: {
+		{keys: "j/k or ↑/↓", help: "Select next / previous comment (scroll, in the document pane)"},
+		{keys: "g / G", help: "Top / bottom of the document (document pane)"},
+		{keys: "Enter", help: "Open the selected thread"},
+		{keys: "c", help: "Add a comment (pick a line first)"},
+		{keys: "Tab / Shift+Tab", help: "Switch focus between document and comments"},
+		{keys: "Ctrl+D/U or PgDn/PgUp", help: "Scroll the focused pane"},
+		{keys: "< / >", help: "Shrink / grow the document pane"},
+		{keys: "F", help: "List only comments near the visible lines"},
+		{keys: "R", help: "Show / hide resolved comments"},
+		{keys: "C", help: "Resolve conflicting suggestions"},
+		{keys: "y / Y / L", help: "Copy text / ID / quote and link"},
+		{keys: "A", help: "Show / hide author avatars"},
+		{keys: "P", help: "Pin / unpin the selected thread (pinned threads are listed first)"},
+		{keys: "[ / ]", help: "Previous / next file", when: func(m *Model) bool { return m.workspace != nil }},
+		{keys: "q", help: "Back to the file picker (quit if a file was given)"},
+		{keys: "Ctrl+C", help: "Quit"},
+	},
+	ModeLineSelect: append([]keyBinding{
+		{keys: "j/k or ↑/↓", help: "Move the cursor"},
+		{keys: "Ctrl+D/U", help: "Half page down / up"},
+		{keys: "g / G", help: "First / last line"},
+		{keys: "c / Enter", help: "Comment on the line (or section, on a heading)"},
+		{keys: "v", help: "Select a range of lines"},
+		{keys: "D", help: "Comment on the whole document"},
+		{keys: "s", help: "Suggest an edit (range, or section on a heading)"},
+		{keys: "o / O", help: "Suggest new lines below / above the line"},
+		{keys: "Esc", help: "Cancel"},
+	}, motionBindings...),
+	ModeChooseTarget: {
+		{keys: "s", help: "Comment on the section"},
+		{keys: "l", help: "Comment on the line"},
+		{keys: "Esc / q", help: "Cancel"},
+	},
+	ModeSelectSuggestionType: {
+		{keys: "r", help: "Suggest an edit to a range of lines"},
+		{keys: "s", help: "Suggest an edit to the section"},
+		{keys: "Esc / q", help: "Cancel"},
+	},
+	ModeSelectRange: append([]keyBinding{
+		{keys: "j/k or ↑/↓", help: "Move the end of the range"},
+		{keys: "Enter", help: "Suggest an edit to the range"},
+		{keys: "c", help: "Comment on the range"},
+		{keys: "Esc / q", help: "Cancel"},
+	}, motionBindings...),
+	ModeAddComment: {
+		{keys: "Ctrl+S", help: "Save the comment"},
+		{keys: "Ctrl+E", help: "Continue writing in $VISUAL / $EDITOR"},
+		{keys: "Ctrl+P", help: "Cycle priority"},
+		{keys: "Ctrl+T", help: "Cycle type"},
+		{keys: "Ctrl+G", help: "Cycle shared / draft / private note"},
+		{keys: "Esc", help: "Cancel"},
+	},
+	ModeThreadView: {
+		{keys: "r", help: "Reply"},
+		{keys: "x", help: "Resolve (reject, on a pending suggestion)"},
+		{keys: "a", help: "Accept a pending suggestion"},
+		{keys: "y / Y / L", help: "Copy text / ID / quote and link"},
+		{keys: "z", help: "Show / fold earlier replies"},
+		{keys: "[ / ]", help: "Select previous / next reply"},
+		{keys: "Enter", help: "Collapse / expand the selected reply's replies"},
+		{keys: "Z", help: "Collapse / expand every subtree"},
+		{keys: "↑/↓ or PgUp/PgDn", help: "Scroll"},
+		{keys: "Esc", help: "Back to browsing"},
+		{keys: "q", help: "Back to the file picker (quit if a file was given)"},
+	},
+	ModeReply: {
+		{keys: "Ctrl+S", help: "Save the reply"},
+		{keys: "Ctrl+E", help: "Continue writing in $VISUAL / $EDITOR"},
+		{keys: "Esc", help: "Cancel"},
+	},
+	ModeResolve: {
+		{keys: "y / Enter", help: "Resolve the thread"},
+		{keys: "n / Esc", help: "Cancel"},
+	},
+	ModeReviewSuggestion: {
+		{keys: "y / Enter", help: "Accept and apply the suggestion"},
+		{keys: "n / Esc", help: "Cancel"},
+	},
+	ModeAddSuggestion: {
+		{keys: "Tab / Shift+Tab", help: "Next / previous field"},
+		{keys: "← / → or Space", help: "Change type or priority"},
+		{keys: "Ctrl+S / Ctrl+D", help: "Submit"},
+		{keys: "Esc", help: "Cancel"},
+	},
+	ModeConflicts: {
+		{keys: "1 / 2", help: "Keep the left / right suggestion"},
+		{keys: "e", help: "Merge both in $EDITOR"},
+		{keys: "x", help: "Reject both"},
+		{keys: "n / p", help: "Next / previous conflict"},
+		{keys: "Esc / q", help: "Back"},
+	},
+	ModeRecover: {
+		{keys: "y / Enter", help: "Restore the unsent text"},
+		{keys: "n", help: "Discard it"},
+		{keys: "Esc", help: "Keep it and ask again next time"},
+		{keys: "Ctrl+C", help: "Quit"},
+	},
+}
+
+// typesText reports whether keys in the current state go into a text field,
+// where ? must be typed rather than open the help overlay (F1 still does)
+func (m *Model) typesText() bool {
+	switch m.mode {
+	case ModeAddComment, ModeReply, ModeAddSuggestion:
+		return true
+	}
+	return m.motionPrompt != ""
+}
+
+// helpKey returns the key that opens the help overlay in the current state
+func (m *Model) helpKey() string {
+	if m.typesText() {
+		return "F1"
+	}
+	return "?"
+}
+
+// withHelpKey prefixes a one-line help text with the help overlay key, so
+// it stays visible when the line is cut off on a narrow terminal
+func (m *Model) withHelpKey(text string) string {
+	return m.helpKey() + ": all keys • " + text
+}
+
+// handleHelpKeys opens and closes the help overlay, reporting whether the
+// key was used. Any key closes the overlay.
+func (m Model) handleHelpKeys(key string) (Model, bool) {
+	if m.showHelp {
+		m.showHelp = false
+		return m, true
+	}
+	if key == "f1" || (key == "?" && !m.typesText()) {
+		m.showHelp = true
+		return m, true
+	}
+	return m, false
+}
+
+// viewHelp renders the help overlay: every key binding of the current mode
+func (m Model) viewHelp() string {
+	var bindings []keyBinding
+	for _, b := range keymap[m.mode] {
+		if b.when == nil || b.when(&m) {
+			bindings = append(bindings, b)
+		}
+	}
+
+	width := 0
+	for _, b := range bindings {
+		width = max(width, lipgloss.Width(b.keys))
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	var body strings.Builder
+	body.WriteString(titleStyle.Render(fmt.Sprintf("Keys: %s", m.mode)))
+	body.WriteString("\n\n")
+	for _, b := range bindings {
+		pad := strings.Repeat(" ", width-lipgloss.Width(b.keys))
+		body.WriteString(keyStyle.Render(b.keys) + pad + "  " + b.help + "\n")
+	}
+	body.WriteString("\n")
+	body.WriteString(helpStyle.Render("Press any key to close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 2).
+		Render(m.glyphs(body.String()))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
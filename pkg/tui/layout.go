@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Pane layouts of the document and comment panes
+const (
+	LayoutAuto    = "auto"    // Split when wide enough, stacked or single pane when not
+	LayoutSplit   = "split"   // Document on the left, comments on the right
+	LayoutStacked = "stacked" // Document above comments
+	LayoutSingle  = "single"  // One pane at a time, switched with Tab
+)
+
+// Layouts lists the valid pane layouts
+var Layouts = []string{LayoutAuto, LayoutSplit, LayoutStacked, LayoutSingle}
+
+// DefaultSplitRatio is the document pane's share of the width (or, stacked,
+// of the height)
+const DefaultSplitRatio = 0.6
+
+// Bounds of the split ratio, so neither pane disappears
+const (
+	MinSplitRatio = 0.2
+	MaxSplitRatio = 0.8
+)
+
+// splitStep is how much < and > change the split ratio
+const splitStep = 0.05
+
+// The auto layout stops splitting side by side below narrowWidth columns,
+// and stops stacking below shortHeight rows
+const (
+	narrowWidth = 100
+	shortHeight = 30
+)
+
+// ValidateLayout checks a pane layout and split ratio
+func ValidateLayout(layout string, ratio float64) error {
+	valid := false
+	for _, l := range Layouts {
+		valid = valid || layout == l
+	}
+	if !valid {
+		return fmt.Errorf("unknown layout %q (want %s)", layout, strings.Join(Layouts, ", "))
+	}
+	if ratio < MinSplitRatio || ratio > MaxSplitRatio {
+		return fmt.Errorf("split ratio %g is out of range (%g to %g)", ratio, MinSplitRatio, MaxSplitRatio)
+	}
+	return nil
+}
+
+// SetLayout sets the pane layout and the document pane's share of the space
+func (m *Model) SetLayout(layout string, ratio float64) error {
+	if err := ValidateLayout(layout, ratio); err != nil {
+		return err
+	}
+	m.layout = layout
+	m.splitRatio = ratio
+	if m.ready {
+		m.handleResize()
+	}
+	return nil
+}
+
+// activeLayout resolves the auto layout for the terminal size
+func (m *Model) activeLayout() string {
+	switch {
+	case m.layout != "" && m.layout != LayoutAuto:
+		return m.layout
+	case m.width-m.fileListWidth() >= narrowWidth:
+		return LayoutSplit
+	case m.height >= shortHeight:
+		return LayoutStacked
+	default:
+		return LayoutSingle
+	}
+}
+
+// ratio returns the split ratio in effect
+func (m *Model) ratio() float64 {
+	if m.splitRatio == 0 {
+		return DefaultSplitRatio
+	}
+	return m.splitRatio
+}
+
+// paneSizes returns the sizes of the document and comment viewports. The
+// space left after the workspace file list, title, and help line is divided
+// by the split ratio; in single-pane layout each pane gets all of it.
+func (m *Model) paneSizes() (docWidth, docHeight, commentWidth, commentHeight int) {
+	width := m.width - m.fileListWidth()
+	height := m.height - 2
+
+	switch m.activeLayout() {
+	case LayoutStacked:
+		// The comment pane's top border takes a row
+		docHeight = max(int(float64(height)*m.ratio()), 1)
+		return width, docHeight, width - 2, max(height-docHeight-1, 1)
+	case LayoutSingle:
+		return width, height, width - 2, height
+	default:
+		// The comment pane's left border and padding take 3 columns
+		docWidth = int(float64(width) * m.ratio())
+		return docWidth, height, width - docWidth - 4, height
+	}
+}
+
+// documentWidth returns the width of the document pane
+func (m *Model) documentWidth() int {
+	width, _, _, _ := m.paneSizes()
+	return width
+}
+
+// documentHeight returns the height of the document pane
+func (m *Model) documentHeight() int {
+	_, height, _, _ := m.paneSizes()
+	return height
+}
+
+// adjustSplit grows the document pane by delta (negative shrinks it) and
+// remembers the ratio in the session for the next run
+func (m *Model) adjustSplit(delta float64) {
+	ratio := math.Round((m.ratio()+delta)*100) / 100
+	ratio = min(max(ratio, MinSplitRatio), MaxSplitRatio)
+	if ratio == m.ratio() {
+		return
+	}
+	m.splitRatio = ratio
+	m.session.SplitRatio = ratio
+	m.handleResize()
+	m.documentViewport.SetContent(m.renderDocument())
+	m.commentViewport.SetContent(m.renderComments())
+	m.statusMsg = fmt.Sprintf("Document pane: %d%%", int(math.Round(ratio*100)))
+}
+
+// handlePaneKeys handles the browse mode keys for focus, split, and
+// scrolling the focused pane, reporting whether key was one of them. With
+// the document focused, j/k scroll it instead of selecting comments.
+func (m Model) handlePaneKeys(key string) (Model, bool) {
+	switch key {
+	case "tab", "shift+tab":
+		// In single-pane layout the focused pane is the one shown
+		m.documentFocused = !m.documentFocused
+	case "<":
+		m.adjustSplit(-splitStep)
+	case ">":
+		m.adjustSplit(splitStep)
+	case "ctrl+d", "pgdown", "ctrl+u", "pgup":
+		down := key == "ctrl+d" || key == "pgdown"
+		if m.documentFocused {
+			m.scrollDocument(down)
+		} else if down {
+			m.commentViewport.HalfViewDown()
+		} else {
+			m.commentViewport.HalfViewUp()
+		}
+	case "j", "down", "k", "up", "g", "G":
+		if !m.documentFocused {
+			return m, false
+		}
+		switch key {
+		case "j", "down":
+			m.documentViewport.LineDown(1)
+		case "k", "up":
+			m.documentViewport.LineUp(1)
+		case "g":
+			m.documentViewport.GotoTop()
+		case "G":
+			m.documentViewport.GotoBottom()
+		}
+		if m.followScroll {
+			m.syncFollow()
+		}
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// focusedViewport returns the browse mode pane with keyboard focus
+func (m *Model) focusedViewport() *viewport.Model {
+	if m.documentFocused {
+		return &m.documentViewport
+	}
+	return &m.commentViewport
+}
+
+// renderPanes lays out the document and comment panes. Line selection
+// always shows the document.
+func (m Model) renderPanes() string {
+	// The comment pane's border dims while the document has focus
+	panelStyle := commentPanelStyle
+	if m.activeLayout() == LayoutStacked {
+		panelStyle = stackedPanelStyle
+	}
+	if m.documentFocused {
+		panelStyle = panelStyle.BorderForeground(lipgloss.Color("240"))
+	}
+
+	switch m.activeLayout() {
+	case LayoutStacked:
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.documentViewport.View(),
+			panelStyle.Render(m.commentViewport.View()),
+		)
+	case LayoutSingle:
+		if !m.documentFocused && m.mode == ModeBrowse {
+			return lipgloss.NewStyle().Padding(0, 1).Render(m.commentViewport.View())
+		}
+		return m.documentViewport.View()
+	default:
+		return lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			m.documentViewport.View(),
+			panelStyle.Render(m.commentViewport.View()),
+		)
+	}
+}
@@ -4,25 +4,45 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/reflow/wordwrap"
 	"github.com/rcliao/comments/pkg/comment"
+	"github.com/rcliao/comments/pkg/config"
 	"github.com/rcliao/comments/pkg/markdown"
 )
 
+// DefaultContextSize is the number of document lines shown before and after
+// the target line in modals and the thread view
+const DefaultContextSize = 2
+
 // Model represents the enhanced TUI application state
 type Model struct {
 	// View mode
 	mode ViewMode
 
 	// File picker
-	filePicker       filepicker.Model
-	startedWithFile  bool // Track if file was provided directly vs picked
+	filePicker      filepicker.Model
+	startedWithFile bool                 // Track if file was provided directly vs picked
+	badgeDir        string               // Directory the file picker badges were requested for
+	badges          map[string]fileBadge // Comment counts by file name in badgeDir (see badges.go)
+
+	// Multi-file workspace (nil unless viewing a directory)
+	workspace *workspace
+
+	// Session persistence
+	session     *Session   // Per-file view state, saved by the caller on exit
+	pendingView *FileState // Saved view state to apply once viewports exist
+
+	// Crash recovery of unsent modal input (see recovery.go)
+	recoveryDir      string    // Where recovery files go ("" disables autosave)
+	recovered        *Recovery // Unsent input from a previous run, offered for restore
+	recoveryWritten  bool      // This run owns the open file's recovery file
+	recoverySnapshot string    // Input as of the last autosave, to skip unchanged writes
 
 	// Document state
 	doc              *comment.DocumentWithComments
@@ -41,25 +61,79 @@ type Model struct {
 	selectedThread     *comment.Comment // Thread root (v2.0)
 	selectedSuggestion *comment.Comment // For suggestion review mode
 	suggestionPreview  string           // Preview of suggested changes
+	pendingDecision    string           // Accept or reject waiting for a reason (require_reason)
 	showResolved       bool
 
+	// Reply folding in thread view (see folding.go)
+	showOlderReplies bool            // Show every reply, not just the latest few
+	collapsedReplies map[string]bool // Replies whose nested replies are hidden, by ID
+	replyCursor      string          // ID of the reply selected with [ and ], or ""
+	replyCursorRow   int             // Row of the selected reply in the rendered thread
+
 	// Input state
 	author      string // User name for comments
 	priority    string // Priority for new comment: low, medium (default), high
 	commentType string // Type for new comment: Q, S, B, T, E, or empty for no type
 
 	// Section input support
-	targetIsSection bool // True if user wants to comment on section, false for line only
+	targetIsSection  bool // True if user wants to comment on section, false for line only
+	targetIsDocument bool // True if the new comment is about the whole document
 
 	// Suggestion creation state
-	suggestionOriginalText string         // Original text for suggestion being created
-	proposedTextInput      textarea.Model // For entering proposed text
+	suggestionOriginalText string          // Original text for suggestion being created
+	rationaleInput         textarea.Model  // For entering why the change is suggested
+	proposedTextInput      textarea.Model  // For entering proposed text
+	suggestionFocus        suggestionField // Focused field in the add-suggestion form
+	suggestionFormErr      string          // Validation error shown in the add-suggestion form
 
 	// Multi-line suggestion support
-	rangeStartLine      int  // Start line for range selection
-	rangeEndLine        int  // End line for range selection
-	rangeActive         bool // True if range selection is active
-	suggestionIsSection bool // True if suggestion is section-based
+	rangeStartLine        int  // Start line for range selection
+	rangeEndLine          int  // End line for range selection
+	rangeActive           bool // True if range selection is active
+	suggestionIsSection   bool // True if suggestion is section-based
+	suggestionIsInsertion bool // True if the suggestion inserts after rangeEndLine, replacing nothing
+
+	// Vim-style motions in line and range selection (see motions.go)
+	motionCount  string // Count typed before a motion (e.g. "10" in 10j)
+	motionPrompt string // Open prompt: ":" (go to line), "/" (search), or ""
+	motionInput  string // Text typed into the prompt
+	lastSearch   string // Last /search, repeated with n/N
+
+	// Conflict resolution
+	conflicts     []comment.Conflict // Overlapping pending suggestions
+	conflictIndex int                // Conflict being reviewed
+
+	// New comments go to the private drafts file, or become private notes
+	// only the author sees (Ctrl+G while adding cycles shared, draft, private)
+	draftMode   bool
+	privateMode bool
+
+	// Display options
+	contextSize int    // Lines of document context around the target line
+	showAvatars bool   // Show an initial-letter block before author names
+	timeFormat  string // How timestamps are shown (see comment.FormatTimestamp); "" is local
+
+	// Pane layout (see layout.go)
+	layout          string  // One of Layouts; "" is LayoutAuto
+	splitRatio      float64 // Document pane's share of the space; 0 is DefaultSplitRatio
+	documentFocused bool    // Browse keys scroll the document rather than select comments
+
+	// Accessibility (see theme.go)
+	theme theme // Text markers and ASCII fallbacks of the --theme in use
+
+	// Unread tracking (see unread.go)
+	seen *comment.SeenState // When the author last opened each thread (nil turns markers off)
+
+	// Comment pane follows document scroll (F in browse mode)
+	followScroll    bool // List only threads near the visible document region
+	followFirstLine int  // First document line of the region last scrolled to
+	followLastLine  int  // Last document line of the region last scrolled to
+
+	// Permissions
+	policy    *config.Config // Project policy for the open document (nil allows everything)
+	readOnly  bool           // Disable all changes (view --read-only)
+	statusMsg string         // One-line notice shown in the help bar until the next key press
+	showHelp  bool           // Show the key binding overlay for the current mode (see keys.go)
 
 	// Dimensions
 	width  int
@@ -73,7 +147,9 @@ type Model struct {
 // NewModel creates a new TUI model with file picker
 func NewModel() Model {
 	fp := filepicker.New()
-	fp.AllowedTypes = []string{".md", ".markdown"}
+	for _, ext := range markdown.Extensions() {
+		fp.AllowedTypes = append(fp.AllowedTypes, ext, strings.ToUpper(ext))
+	}
 	fp.CurrentDirectory, _ = os.Getwd()
 
 	ta := textarea.New()
@@ -83,6 +159,8 @@ func NewModel() Model {
 	proposedTA := textarea.New()
 	proposedTA.Placeholder = "Enter proposed text (edit the pre-filled original)..."
 
+	rationaleTA := newRationaleInput()
+
 	// Get author from environment or use default
 	author := os.Getenv("USER")
 	if author == "" {
@@ -92,13 +170,17 @@ func NewModel() Model {
 	return Model{
 		mode:              ModeFilePicker,
 		filePicker:        fp,
+		badgeDir:          fp.CurrentDirectory,
 		commentInput:      ta,
+		rationaleInput:    rationaleTA,
 		proposedTextInput: proposedTA,
 		author:            author,
 		priority:          "medium",
 		commentType:       "",
 		showResolved:      false,
 		startedWithFile:   false,
+		contextSize:       DefaultContextSize,
+		session:           NewSession(),
 	}
 }
 
@@ -111,6 +193,8 @@ func NewModelWithFile(doc *comment.DocumentWithComments, filename string) Model
 	proposedTA := textarea.New()
 	proposedTA.Placeholder = "Enter proposed text (edit the pre-filled original)..."
 
+	rationaleTA := newRationaleInput()
+
 	// Get author from environment or use default
 	author := os.Getenv("USER")
 	if author == "" {
@@ -122,28 +206,149 @@ func NewModelWithFile(doc *comment.DocumentWithComments, filename string) Model
 		doc:               doc,
 		filename:          filename,
 		commentInput:      ta,
+		rationaleInput:    rationaleTA,
 		proposedTextInput: proposedTA,
 		author:            author,
 		priority:          "medium",
 		commentType:       "",
 		showResolved:      false,
 		startedWithFile:   true,
+		contextSize:       DefaultContextSize,
+		session:           NewSession(),
 	}
 
 	// Parse sections
 	if doc != nil {
-		m.documentSections = markdown.ParseDocument(doc.Content)
+		m.documentSections = doc.Structure()
 	}
 
+	m.loadPolicy()
+	m.loadSeen()
+	m.loadPrivateNotes()
+
 	return m
 }
 
+// SetContextSize sets how many lines of document context are shown around
+// the target line. Negative values are treated as zero.
+func (m *Model) SetContextSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	m.contextSize = n
+}
+
+// SetTimeFormat sets how comment timestamps are shown: comment.TimeLocal,
+// comment.TimeRelative, or comment.TimeISO
+func (m *Model) SetTimeFormat(format string) {
+	m.timeFormat = format
+}
+
+// formatTime formats a comment timestamp in the time format in use
+func (m *Model) formatTime(t time.Time) string {
+	return comment.FormatTimestamp(t, m.timeFormat)
+}
+
+// SetReadOnly disables (or re-enables) all changes made through the TUI
+func (m *Model) SetReadOnly(readOnly bool) {
+	m.readOnly = readOnly
+}
+
+// loadPolicy loads the project config that applies to the open document
+func (m *Model) loadPolicy() {
+	if m.filename == "" {
+		m.policy = nil
+		return
+	}
+
+	policy, err := config.LoadForDocument(m.filename)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.policy = policy
+}
+
+// canPerform reports whether the current author may perform action
+// On denial it sets a status message explaining why.
+func (m *Model) canPerform(action string) bool {
+	if m.readOnly {
+		m.statusMsg = config.ErrReadOnly.Error()
+		return false
+	}
+	if err := m.policy.Check(action, m.author); err != nil {
+		m.statusMsg = err.Error()
+		return false
+	}
+	return true
+}
+
+// canAccept reports whether the current author may accept suggestion s under
+// the project's self_accept rule. On denial it sets a status message
+// explaining why.
+func (m *Model) canAccept(s *comment.Comment) bool {
+	if err := m.policy.CheckAccept(m.doc, s, m.author, false); err != nil {
+		m.statusMsg = err.Error()
+		return false
+	}
+	return true
+}
+
+// screenComment runs a new comment through the project's content filter
+// and lint rules, which may change its text. On refusal it sets a status
+// message explaining why.
+func (m *Model) screenComment(c *comment.Comment, thread bool) bool {
+	if m.policy == nil {
+		return true
+	}
+	if err := m.policy.Screen(c, thread); err != nil {
+		m.statusMsg = err.Error()
+		return false
+	}
+	return true
+}
+
+// signComment signs a new comment when the project config enables signing
+func (m *Model) signComment(c *comment.Comment) error {
+	key, err := m.policy.SigningKey()
+	if err != nil {
+		return fmt.Errorf("signing comment: %w", err)
+	}
+	if key != nil {
+		comment.SignComment(c, key)
+	}
+	return nil
+}
+
+// anchorComment anchors a new thread to its heading when the project config
+// sets anchor to heading
+func (m *Model) anchorComment(c *comment.Comment) {
+	if m.policy != nil {
+		_ = comment.AnchorComment(c, m.doc, m.policy.Anchor)
+	}
+}
+
+// renderHelp renders the help bar, replacing it with the status message if one is set
+func (m Model) renderHelp(text string) string {
+	if m.statusMsg != "" {
+		return statusMessageStyle.Render(m.statusMsg)
+	}
+	if prompt := m.motionHelp(); prompt != "" {
+		return prompt
+	}
+	return helpStyle.Render(m.withHelpKey(text))
+}
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
+	var autosave tea.Cmd
+	if m.recoveryDir != "" {
+		autosave = autosaveTick()
+	}
 	if m.mode == ModeFilePicker {
-		return m.filePicker.Init()
+		return tea.Batch(m.filePicker.Init(), loadBadges(m.badgeDir), autosave)
 	}
-	return nil
+	return autosave
 }
 
 // Update handles messages and updates the model
@@ -156,6 +361,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
+
+	case mergeEditedMsg:
+		return m.handleMergeEdited(msg)
+
+	case composeEditedMsg:
+		return m.handleComposeEdited(msg)
+
+	case autosaveMsg:
+		return m.handleAutosave()
+
+	case badgesMsg:
+		return m.handleBadges(msg)
 	}
 
 	// Delegate to mode-specific updates
@@ -168,9 +385,8 @@ func (m *Model) handleResize() {
 		return
 	}
 
-	// Split screen: 60% for document, 40% for comments/thread
-	docWidth := int(float64(m.width) * 0.6)
-	panelWidth := m.width - docWidth - 4
+	// Split screen by the layout and ratio (see layout.go)
+	docWidth, docHeight, panelWidth, panelHeight := m.paneSizes()
 
 	// Set textarea width to use most of the screen width
 	// Account for modal borders (2), padding (4), and some margin (10)
@@ -179,10 +395,12 @@ func (m *Model) handleResize() {
 		textareaWidth = 40 // Minimum width
 	}
 	m.commentInput.SetWidth(textareaWidth)
+	m.rationaleInput.SetWidth(textareaWidth)
+	m.proposedTextInput.SetWidth(textareaWidth)
 
 	if !m.ready {
-		m.documentViewport = viewport.New(docWidth, m.height-2)
-		m.commentViewport = viewport.New(panelWidth, m.height-2)
+		m.documentViewport = viewport.New(docWidth, docHeight)
+		m.commentViewport = viewport.New(panelWidth, panelHeight)
 		m.threadViewport = viewport.New(m.width-4, m.height-2)
 
 		if m.doc != nil {
@@ -190,13 +408,14 @@ func (m *Model) handleResize() {
 			m.documentViewport.YOffset = 0 // Explicitly start at top
 			m.commentViewport.SetContent(m.renderComments())
 			m.commentViewport.YOffset = 0 // Explicitly start at top
+			m.applyPendingView()
 		}
 		m.ready = true
 	} else {
 		m.documentViewport.Width = docWidth
-		m.documentViewport.Height = m.height - 2
+		m.documentViewport.Height = docHeight
 		m.commentViewport.Width = panelWidth
-		m.commentViewport.Height = m.height - 2
+		m.commentViewport.Height = panelHeight
 		m.threadViewport.Width = m.width - 4
 		m.threadViewport.Height = m.height - 2
 	}
@@ -204,6 +423,13 @@ func (m *Model) handleResize() {
 
 // handleKeyPress handles keyboard input based on current mode
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Status messages last until the next key press
+	m.statusMsg = ""
+
+	if helped, ok := m.handleHelpKeys(msg.String()); ok {
+		return helped, nil
+	}
+
 	switch m.mode {
 	case ModeFilePicker:
 		return m.handleFilePickerKeys(msg)
@@ -229,6 +455,10 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleSelectSuggestionTypeKeys(msg)
 	case ModeSelectRange:
 		return m.handleSelectRangeKeys(msg)
+	case ModeConflicts:
+		return m.handleConflictsKeys(msg)
+	case ModeRecover:
+		return m.handleRecoverKeys(msg)
 	default:
 		return m, nil
 	}
@@ -249,11 +479,16 @@ func (m Model) handleFilePickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.loadFile(path)
 	}
 
-	return m, cmd
+	return m, tea.Batch(cmd, m.followPickerDir())
 }
 
 // handleBrowseKeys handles keys in browse mode
 func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Focus, split, and scrolling keys (see layout.go)
+	if next, ok := m.handlePaneKeys(msg.String()); ok {
+		return next, nil
+	}
+
 	switch msg.String() {
 	case "q":
 		// If file was provided directly, quit the app
@@ -261,11 +496,12 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.startedWithFile {
 			return m, tea.Quit
 		}
+		m.recordViewState()
 		m.mode = ModeFilePicker
 		m.doc = nil
 		m.filename = ""
 		m.ready = false
-		return m, nil
+		return m, m.refreshBadges()
 
 	case "ctrl+c":
 		return m, tea.Quit
@@ -276,8 +512,7 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.selectedLine = 1
 
 		// Completely reset the viewport to fix scroll offset issues
-		docWidth := int(float64(m.width) * 0.6)
-		m.documentViewport = viewport.New(docWidth, m.height-2)
+		m.documentViewport = viewport.New(m.documentWidth(), m.documentHeight())
 		m.documentViewport.YOffset = 0
 		m.documentViewport.SetContent(m.renderDocumentWithCursor())
 		m.documentViewport.YOffset = 0 // Set again after content
@@ -285,7 +520,7 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "j", "down":
 		// Navigate comments
-		visibleComments := comment.GetVisibleComments(m.doc.Threads, m.showResolved)
+		visibleComments := m.visibleThreads()
 		if m.selectedComment < len(visibleComments)-1 {
 			m.selectedComment++
 			m.commentViewport.SetContent(m.renderComments())
@@ -295,7 +530,7 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "k", "up":
-		visibleComments := comment.GetVisibleComments(m.doc.Threads, m.showResolved)
+		visibleComments := m.visibleThreads()
 		if m.selectedComment > 0 {
 			m.selectedComment--
 			m.commentViewport.SetContent(m.renderComments())
@@ -306,10 +541,12 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "enter":
 		// Expand selected comment thread
-		visibleComments := comment.GetVisibleComments(m.doc.Threads, m.showResolved)
+		visibleComments := m.visibleThreads()
 		if len(visibleComments) > 0 && m.selectedComment < len(visibleComments) {
 			selectedThread := visibleComments[m.selectedComment]
 			m.selectedThread = selectedThread
+			m.resetFolding()
+			m.markSeen(selectedThread)
 			m.mode = ModeThreadView
 			m.threadViewport.SetContent(m.renderThread())
 			// Scroll document to center the thread's comment
@@ -323,6 +560,57 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.showResolved = !m.showResolved
 		m.commentViewport.SetContent(m.renderComments())
 		return m, nil
+
+	case "C":
+		// Resolve overlapping suggestions
+		return m.openConflicts(), nil
+
+	case "F":
+		// Toggle listing only the comments near the visible document region
+		m.toggleFollow()
+		return m, nil
+
+	case "y", "Y", "L":
+		// Copy the selected comment's text, ID, or quote and link
+		visibleComments := m.visibleThreads()
+		if m.selectedComment < len(visibleComments) {
+			m.copyComment(visibleComments[m.selectedComment], copyKinds[msg.String()])
+		}
+		return m, nil
+
+	case "A":
+		// Toggle author avatars
+		m.showAvatars = !m.showAvatars
+		m.commentViewport.SetContent(m.renderComments())
+		return m, nil
+
+	case "P":
+		// Pin or unpin the selected thread; pinned threads are listed first
+		visibleComments := m.visibleThreads()
+		if m.selectedComment >= len(visibleComments) || !m.canPerform(config.ActionStatus) {
+			return m, nil
+		}
+		thread := visibleComments[m.selectedComment]
+		thread.Pinned = !thread.Pinned
+		if err := m.saveDocument(); err != nil {
+			m.err = err
+			return m, nil
+		}
+		for i, c := range m.visibleThreads() {
+			if c == thread {
+				m.selectedComment = i
+			}
+		}
+		m.commentViewport.SetContent(m.renderComments())
+		return m, nil
+
+	case "]":
+		// Next file in the workspace
+		return m.switchFile(1), nil
+
+	case "[":
+		// Previous file in the workspace
+		return m.switchFile(-1), nil
 	}
 
 	return m, nil
@@ -330,6 +618,10 @@ func (m Model) handleBrowseKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleLineSelectKeys handles keys in line select mode
 func (m Model) handleLineSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if moved, ok := m.handleMotionKeys(msg); ok {
+		return moved, nil
+	}
+
 	lines := strings.Split(m.doc.Content, "\n")
 	totalLines := len(lines)
 
@@ -339,8 +631,7 @@ func (m Model) handleLineSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeBrowse
 
 		// Reset the viewport to fix any scroll offset issues
-		docWidth := int(float64(m.width) * 0.6)
-		m.documentViewport = viewport.New(docWidth, m.height-2)
+		m.documentViewport = viewport.New(m.documentWidth(), m.documentHeight())
 		m.documentViewport.YOffset = 0
 		m.documentViewport.SetContent(m.renderDocument())
 		m.documentViewport.YOffset = 0
@@ -401,6 +692,9 @@ func (m Model) handleLineSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "c", "enter":
+		if !m.canPerform(config.ActionAdd) {
+			return m, nil
+		}
 		// Check if on a heading line
 		if m.isHeadingLine(m.selectedLine) {
 			// On a heading - let user choose section vs line
@@ -414,7 +708,32 @@ func (m Model) handleLineSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.commentInput.Focus()
 		return m, textarea.Blink
 
+	case "v":
+		// Select a range of lines to comment on or suggest an edit for
+		m.rangeStartLine = m.selectedLine
+		m.rangeEndLine = m.selectedLine
+		m.rangeActive = true
+		m.suggestionIsSection = false
+		m.mode = ModeSelectRange
+		m.documentViewport.SetContent(m.renderDocumentWithCursor())
+		return m, nil
+
+	case "D":
+		// Comment on the document as a whole
+		if !m.canPerform(config.ActionAdd) {
+			return m, nil
+		}
+		m.targetIsSection = false
+		m.targetIsDocument = true
+		m.mode = ModeAddComment
+		m.commentInput.Reset()
+		m.commentInput.Focus()
+		return m, textarea.Blink
+
 	case "s":
+		if !m.canPerform(config.ActionSuggest) {
+			return m, nil
+		}
 		// Check if on a heading line
 		if m.isHeadingLine(m.selectedLine) {
 			// On heading - choose range vs section
@@ -429,6 +748,17 @@ func (m Model) handleLineSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeSelectRange
 		m.documentViewport.SetContent(m.renderDocumentWithCursor())
 		return m, nil
+
+	case "o", "O":
+		// Suggest new lines below (o) or above (O) the cursor
+		if !m.canPerform(config.ActionSuggest) {
+			return m, nil
+		}
+		after := m.selectedLine
+		if msg.String() == "O" {
+			after--
+		}
+		return m.openInsertionForm(after)
 	}
 
 	return m, nil
@@ -495,11 +825,7 @@ func (m Model) handleSelectSuggestionTypeKeys(msg tea.KeyMsg) (tea.Model, tea.Cm
 				m.suggestionOriginalText = strings.Join(originalLines, "\n")
 			}
 
-			m.mode = ModeAddSuggestion
-			m.proposedTextInput.Reset()
-			m.proposedTextInput.SetValue(m.suggestionOriginalText)
-			m.proposedTextInput.Focus()
-			return m, textarea.Blink
+			return m.openSuggestionForm()
 		}
 		return m, nil
 
@@ -513,6 +839,10 @@ func (m Model) handleSelectSuggestionTypeKeys(msg tea.KeyMsg) (tea.Model, tea.Cm
 
 // handleSelectRangeKeys handles keys in select range mode
 func (m Model) handleSelectRangeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if moved, ok := m.handleMotionKeys(msg); ok {
+		return moved, nil
+	}
+
 	lines := strings.Split(m.doc.Content, "\n")
 	totalLines := len(lines)
 
@@ -535,17 +865,24 @@ func (m Model) handleSelectRangeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "c":
+		// Comment on the selected range instead of suggesting an edit
+		if !m.canPerform(config.ActionAdd) {
+			return m, nil
+		}
+		m.targetIsSection = false
+		m.mode = ModeAddComment
+		m.commentInput.Reset()
+		m.commentInput.Focus()
+		return m, textarea.Blink
+
 	case "enter":
 		// Confirm range - capture original text
 		if m.rangeStartLine > 0 && m.rangeEndLine <= totalLines {
 			originalLines := lines[m.rangeStartLine-1 : m.rangeEndLine]
 			m.suggestionOriginalText = strings.Join(originalLines, "\n")
 		}
-		m.mode = ModeAddSuggestion
-		m.proposedTextInput.Reset()
-		m.proposedTextInput.SetValue(m.suggestionOriginalText)
-		m.proposedTextInput.Focus()
-		return m, textarea.Blink
+		return m.openSuggestionForm()
 
 	case "esc", "q":
 		// Cancel range selection
@@ -564,23 +901,15 @@ func (m *Model) calculateDisplayRow(targetLineNum int) int {
 		return 0
 	}
 
-	lines := strings.Split(m.doc.Content, "\n")
-
 	// Calculate available width for text
 	availableWidth := m.documentViewport.Width - 12
 	if availableWidth < 40 {
 		availableWidth = 40
 	}
 
-	displayRow := 0
-	for i := 0; i < len(lines) && i < targetLineNum; i++ {
-		line := lines[i]
-		// Count how many rows this line takes when wrapped
-		wrappedLines := strings.Split(wordwrap.String(line, availableWidth), "\n")
-		displayRow += len(wrappedLines)
-	}
-
-	return displayRow
+	// Rows occupied by lines 1..targetLineNum, i.e. where the next line starts
+	layout := comment.NewDisplayLayout(m.doc.Content, availableWidth)
+	return layout.LineStartRow(targetLineNum + 1)
 }
 
 // scrollToLine adjusts viewport to keep the specified line visible
@@ -620,6 +949,8 @@ func (m Model) handleAddCommentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		// Cancel comment creation
 		m.mode = ModeLineSelect
+		m.targetIsDocument = false
+		m.rangeActive = false
 		m.commentInput.Reset()
 		// Reset priority and type to defaults
 		m.priority = "medium"
@@ -628,44 +959,38 @@ func (m Model) handleAddCommentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "ctrl+p":
 		// Cycle priority: medium -> high -> low -> medium
-		switch m.priority {
-		case "medium":
-			m.priority = "high"
-		case "high":
-			m.priority = "low"
-		case "low":
-			m.priority = "medium"
-		default:
-			m.priority = "medium"
-		}
+		m.priority = nextPriority(m.priority)
 		return m, nil
 
 	case "ctrl+t":
 		// Cycle type: none -> Q -> S -> B -> T -> E -> none
-		switch m.commentType {
-		case "":
-			m.commentType = "Q"
-		case "Q":
-			m.commentType = "S"
-		case "S":
-			m.commentType = "B"
-		case "B":
-			m.commentType = "T"
-		case "T":
-			m.commentType = "E"
-		case "E":
-			m.commentType = ""
+		m.commentType = nextCommentType(m.commentType)
+		return m, nil
+
+	case "ctrl+g":
+		// Cycle shared -> draft -> private note; stays on for the next comments
+		switch {
+		case m.draftMode:
+			m.draftMode, m.privateMode = false, true
+		case m.privateMode:
+			m.privateMode = false
 		default:
-			m.commentType = ""
+			m.draftMode = true
 		}
 		return m, nil
 
+	case "ctrl+e":
+		// Continue writing in $EDITOR
+		return m, m.composeInEditor()
+
 	case "ctrl+s":
 		// Save comment
 		text := strings.TrimSpace(m.commentInput.Value())
 		if text == "" {
 			// Empty comment, just cancel
 			m.mode = ModeLineSelect
+			m.targetIsDocument = false
+			m.rangeActive = false
 			m.commentInput.Reset()
 			// Reset priority and type to defaults
 			m.priority = "medium"
@@ -673,39 +998,76 @@ func (m Model) handleAddCommentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		line := m.selectedLine
+		if m.targetIsDocument {
+			line = comment.DocumentLine
+		} else if m.rangeActive {
+			line = m.rangeStartLine
+		}
+
 		// Create new comment with type if specified
 		var newComment *comment.Comment
 		if m.commentType != "" {
 			// Auto-prefix text with type like CLI does
 			commentText := "[" + m.commentType + "] " + text
-			newComment = comment.NewCommentWithType(m.author, m.selectedLine, commentText, m.commentType)
+			newComment = comment.NewCommentWithType(m.author, line, commentText, m.commentType)
 		} else {
-			newComment = comment.NewComment(m.author, m.selectedLine, text)
+			newComment = comment.NewComment(m.author, line, text)
 		}
 
 		// Set priority and status
 		newComment.Priority = m.priority
 		newComment.Status = "active"
 
+		if m.rangeActive && !m.targetIsDocument && m.rangeEndLine > m.rangeStartLine {
+			newComment.EndLine = m.rangeEndLine
+		}
+		if !m.screenComment(newComment, true) {
+			return m, nil
+		}
+
 		// Add section metadata if targeting section
 		if m.targetIsSection {
-			comment.UpdateCommentSection(newComment, m.doc.Content)
+			comment.UpdateCommentSection(newComment, m.doc)
+		}
+		comment.CaptureQuote(newComment, m.doc.Content)
+		m.anchorComment(newComment)
+		if m.privateMode {
+			newComment.Visibility = comment.VisibilityPrivate
+		} else {
+			m.doc.AttachToOpenReview(newComment)
 		}
 
-		m.doc.Threads = append(m.doc.Threads, newComment)
-
-		// Save to file
-		if err := m.saveDocument(); err != nil {
+		if err := m.signComment(newComment); err != nil {
 			m.err = err
 			return m, nil
 		}
 
+		if m.draftMode {
+			// Drafts stay out of the shared sidecar until published
+			if err := comment.AddDraft(m.filename, newComment, m.doc.Content); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.statusMsg = fmt.Sprintf("Draft saved; share drafts with: comments drafts publish %s", m.filename)
+		} else {
+			m.doc.Threads = append(m.doc.Threads, newComment)
+
+			// Save to file
+			if err := m.saveDocument(); err != nil {
+				m.err = err
+				return m, nil
+			}
+		}
+
 		// Refresh views
 		m.documentViewport.SetContent(m.renderDocumentWithCursor())
 		m.commentViewport.SetContent(m.renderComments())
 
 		// Return to line select mode and reset to defaults
 		m.mode = ModeLineSelect
+		m.targetIsDocument = false
+		m.rangeActive = false
 		m.commentInput.Reset()
 		m.priority = "medium"
 		m.commentType = ""
@@ -733,14 +1095,25 @@ func (m Model) handleThreadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.startedWithFile {
 			return m, tea.Quit
 		}
+		m.recordViewState()
 		m.mode = ModeFilePicker
 		m.selectedThread = nil
 		m.doc = nil
 		m.filename = ""
 		m.ready = false
+		return m, m.refreshBadges()
+
+	case "y", "Y", "L":
+		// Copy the thread's text, ID, or quote and link
+		if m.selectedThread != nil {
+			m.copyComment(m.selectedThread, copyKinds[msg.String()])
+		}
 		return m, nil
 
 	case "r":
+		if !m.canPerform(config.ActionReply) {
+			return m, nil
+		}
 		// Enter reply mode
 		m.mode = ModeReply
 		m.commentInput.Reset()
@@ -750,6 +1123,9 @@ func (m Model) handleThreadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "a":
 		// Accept suggestion (if thread root is a pending suggestion)
 		if m.selectedThread != nil && m.selectedThread.IsSuggestion && m.selectedThread.IsPending() {
+			if !m.canPerform(config.ActionAccept) || !m.canAccept(m.selectedThread) {
+				return m, nil
+			}
 			m.selectedSuggestion = m.selectedThread
 			m.mode = ModeReviewSuggestion
 			// Generate preview
@@ -766,24 +1142,27 @@ func (m Model) handleThreadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "x":
 		// Reject suggestion (if root comment is a pending suggestion), otherwise resolve thread
 		if m.selectedThread != nil && m.selectedThread.IsSuggestion && m.selectedThread.IsPending() {
-			// Reject the suggestion using helper
-			if err := comment.RejectSuggestion(m.doc.Threads, m.selectedThread.ID); err != nil {
-				m.err = fmt.Errorf("failed to reject suggestion: %w", err)
+			if !m.canPerform(config.ActionReject) {
 				return m, nil
 			}
-			// Save document
-			if err := comment.SaveToSidecar(m.filename, m.doc); err != nil {
-				m.err = fmt.Errorf("failed to save: %w", err)
+			m.selectedSuggestion = m.selectedThread
+			if m.reasonRequired() {
+				return m.askReason(comment.DecisionRejected)
 			}
-			// Refresh thread view
-			m.threadViewport.SetContent(m.renderThread())
-			return m, nil
+			return m.rejectSelectedSuggestion("")
 		}
 		// Otherwise, enter resolve mode for regular threads
+		if !m.canPerform(config.ActionResolve) {
+			return m, nil
+		}
 		m.mode = ModeResolve
 		return m, nil
 	}
 
+	if folded, ok := m.handleFoldKeys(msg.String()); ok {
+		return folded, nil
+	}
+
 	// Scroll thread viewport
 	var cmd tea.Cmd
 	m.threadViewport, cmd = m.threadViewport.Update(msg)
@@ -794,14 +1173,28 @@ func (m Model) handleThreadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleReplyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		// Cancel reply
+		// Cancel reply (and the accept/reject waiting for a reason)
 		m.mode = ModeThreadView
 		m.commentInput.Reset()
+		m.pendingDecision = ""
+		m.selectedSuggestion = nil
+		m.suggestionPreview = ""
 		return m, nil
 
+	case "ctrl+e":
+		// Continue writing in $EDITOR
+		return m, m.composeInEditor()
+
 	case "ctrl+s":
 		// Save reply
 		text := strings.TrimSpace(m.commentInput.Value())
+		if m.pendingDecision != "" {
+			if text == "" {
+				// The policy requires a reason; keep the prompt open
+				return m, nil
+			}
+			return m.finishDecision(text)
+		}
 		if text == "" {
 			// Empty reply, just cancel
 			m.mode = ModeThreadView
@@ -809,11 +1202,23 @@ func (m Model) handleReplyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Screen the reply before adding it, since the filter may change it
+		screened := &comment.Comment{Author: m.author, Text: text}
+		if !m.screenComment(screened, false) {
+			return m, nil
+		}
+		text = screened.Text
+
 		// Add reply to thread using helper
 		if err := comment.AddReplyToThread(m.doc.Threads, m.selectedThread.ID, m.author, text); err != nil {
 			m.err = err
 			return m, nil
 		}
+		m.doc.AttachToOpenReview(m.selectedThread.Replies[len(m.selectedThread.Replies)-1])
+		if err := m.signComment(m.selectedThread.Replies[len(m.selectedThread.Replies)-1]); err != nil {
+			m.err = err
+			return m, nil
+		}
 
 		// Save to file
 		if err := m.saveDocument(); err != nil {
@@ -887,74 +1292,40 @@ func (m Model) handleReviewSuggestionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Apply suggestion to document
-		newContent, err := comment.ApplySuggestion(m.doc.Content, m.selectedSuggestion)
-		if err != nil {
-			m.err = fmt.Errorf("failed to apply suggestion: %w", err)
-			m.mode = ModeThreadView
-			m.selectedSuggestion = nil
-			m.suggestionPreview = ""
-			return m, nil
+		if m.reasonRequired() {
+			return m.askReason(comment.DecisionAccepted)
 		}
-
-		// Update document content
-		m.doc.Content = newContent
-
-		// Mark suggestion as accepted using helper
-		if err := comment.AcceptSuggestion(m.doc.Threads, m.selectedSuggestion.ID); err != nil {
-			m.err = err
-			return m, nil
-		}
-
-		// Recalculate comment line numbers
-		linesAdded := len(strings.Split(m.selectedSuggestion.ProposedText, "\n"))
-		comment.RecalculateCommentLines(m.doc.Threads, m.selectedSuggestion.StartLine, m.selectedSuggestion.EndLine, linesAdded)
-
-		// Save document
-		if err := m.saveDocument(); err != nil {
-			m.err = err
-			return m, nil
-		}
-
-		// Refresh all views
-		m.documentViewport.SetContent(m.renderDocument())
-		m.commentViewport.SetContent(m.renderComments())
-
-		// Return to thread view
-		m.mode = ModeThreadView
-		m.threadViewport.SetContent(m.renderThread())
-		m.selectedSuggestion = nil
-		m.suggestionPreview = ""
-		return m, nil
+		return m.acceptSelectedSuggestion("")
 	}
 
 	return m, nil
 }
 
-// handleAddSuggestionKeys handles keys in add suggestion mode
+// handleAddSuggestionKeys handles keys in the add suggestion form
+// Tab/Shift+Tab move between rationale, proposed text, type, and priority;
+// the form is validated before the suggestion is saved.
 func (m Model) handleAddSuggestionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		// Cancel suggestion creation
 		m.mode = ModeLineSelect
-		m.suggestionOriginalText = ""
-		m.rangeActive = false
-		m.suggestionIsSection = false
-		m.proposedTextInput.Reset()
+		m.resetSuggestionForm()
 		m.documentViewport.SetContent(m.renderDocumentWithCursor())
 		return m, nil
 
+	case "tab":
+		return m, m.focusSuggestionField((m.suggestionFocus + 1) % suggestionFieldCount)
+
+	case "shift+tab":
+		return m, m.focusSuggestionField((m.suggestionFocus + suggestionFieldCount - 1) % suggestionFieldCount)
+
 	case "ctrl+s", "ctrl+d":
 		// Submit suggestion
+		rationale := strings.TrimSpace(m.rationaleInput.Value())
 		proposedText := m.proposedTextInput.Value()
-		if proposedText == "" {
-			// Don't create empty suggestion
-			m.mode = ModeLineSelect
-			m.suggestionOriginalText = ""
-			m.rangeActive = false
-			m.suggestionIsSection = false
-			m.documentViewport.SetContent(m.renderDocumentWithCursor())
-			return m, nil
+		if field, errMsg := validateSuggestionForm(rationale, proposedText, m.suggestionOriginalText); errMsg != "" {
+			m.suggestionFormErr = errMsg
+			return m, m.focusSuggestionField(field)
 		}
 
 		// Use range if set, otherwise fall back to selectedLine
@@ -965,48 +1336,94 @@ func (m Model) handleAddSuggestionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			endLine = m.rangeEndLine
 		}
 
-		// Create suggestion using helper (multi-line)
-		suggestion := comment.NewSuggestion(
-			m.author,
-			startLine,
-			endLine,
-			"Suggestion",
-			m.suggestionOriginalText,
-			proposedText,
-		)
+		// Auto-prefix rationale with type like comments do
+		text := rationale
+		if m.commentType != "" {
+			text = "[" + m.commentType + "] " + rationale
+		}
+
+		// Create suggestion using helper (multi-line, or an insertion)
+		var suggestion *comment.Comment
+		if m.suggestionIsInsertion {
+			suggestion = comment.NewInsertion(m.author, m.rangeEndLine, text, proposedText)
+		} else {
+			suggestion = comment.NewSuggestion(
+				m.author,
+				startLine,
+				endLine,
+				text,
+				m.suggestionOriginalText,
+				proposedText,
+			)
+		}
+		suggestion.Type = m.commentType
+		suggestion.Priority = m.priority
+		suggestion.Status = "active"
+		if !m.screenComment(suggestion, true) {
+			return m, nil
+		}
+		m.doc.AttachToOpenReview(suggestion)
 
 		// Add section metadata if section-based
 		if m.suggestionIsSection {
-			comment.UpdateCommentSection(suggestion, m.doc.Content)
+			comment.UpdateCommentSection(suggestion, m.doc)
+		}
+		m.anchorComment(suggestion)
+
+		if err := m.signComment(suggestion); err != nil {
+			m.err = err
+			return m, nil
 		}
 
 		// Add to document
 		m.doc.Threads = append(m.doc.Threads, suggestion)
 
-		// Reset state
-		m.rangeActive = false
-		m.suggestionIsSection = false
-
 		// Save document
 		if err := m.saveDocument(); err != nil {
 			m.err = err
 			return m, nil
 		}
 
+		// Reset state
+		m.resetSuggestionForm()
+
 		// Refresh views
 		m.documentViewport.SetContent(m.renderDocument())
 		m.commentViewport.SetContent(m.renderComments())
 
 		// Return to browse mode
 		m.mode = ModeBrowse
-		m.suggestionOriginalText = ""
-		m.proposedTextInput.Reset()
+		return m, nil
+	}
+
+	// Type and priority are choice fields cycled with arrows or space
+	switch m.suggestionFocus {
+	case fieldType:
+		switch msg.String() {
+		case "right", "l", " ":
+			m.commentType = nextCommentType(m.commentType)
+		case "left", "h":
+			m.commentType = prevCommentType(m.commentType)
+		}
+		return m, nil
+	case fieldPriority:
+		switch msg.String() {
+		case "right", "l", " ":
+			m.priority = nextPriority(m.priority)
+		case "left", "h":
+			m.priority = prevPriority(m.priority)
+		}
 		return m, nil
 	}
 
 	// Handle textarea input
 	var cmd tea.Cmd
-	m.proposedTextInput, cmd = m.proposedTextInput.Update(msg)
+	if m.suggestionFocus == fieldRationale {
+		m.rationaleInput, cmd = m.rationaleInput.Update(msg)
+	} else {
+		m.proposedTextInput, cmd = m.proposedTextInput.Update(msg)
+	}
+	m.suggestionFormErr = ""
 	return m, cmd
 }
 
@@ -1017,9 +1434,12 @@ func (m Model) updateByMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case ModeFilePicker:
 		m.filePicker, cmd = m.filePicker.Update(msg)
+		cmd = tea.Batch(cmd, m.followPickerDir())
 	case ModeBrowse:
-		// Only allow viewport updates in browse mode, not line select
-		m.documentViewport, cmd = m.documentViewport.Update(msg)
+		// Only allow viewport updates in browse mode, not line select; they
+		// go to the focused pane
+		pane := m.focusedViewport()
+		*pane, cmd = pane.Update(msg)
 	case ModeLineSelect:
 		// Don't update viewport in line select mode - we control scrolling manually
 		cmd = nil
@@ -1034,11 +1454,23 @@ func (m Model) updateByMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // loadFile loads a markdown file and transitions to browse mode
 func (m Model) loadFile(path string) (tea.Model, tea.Cmd) {
+	return m.loadFileModel(path), nil
+}
+
+// loadFileModel loads a markdown file, restoring any view state the session
+// remembers for it
+func (m Model) loadFileModel(path string) Model {
+	// Settle the recovery file of the file being left
+	if err := m.autosave(); err != nil {
+		m.err = err
+		return m
+	}
+
 	// Load document from sidecar
 	doc, err := comment.LoadFromSidecar(path)
 	if err != nil {
 		m.err = err
-		return m, nil
+		return m
 	}
 
 	// Update model
@@ -1049,14 +1481,26 @@ func (m Model) loadFile(path string) (tea.Model, tea.Cmd) {
 	m.ready = false
 
 	// Parse sections
-	m.documentSections = markdown.ParseDocument(m.doc.Content)
+	m.documentSections = m.doc.Structure()
+
+	m.loadPolicy()
+	m.loadSeen()
+	m.loadPrivateNotes()
+
+	m.pendingView = nil
+	m.queueSavedView()
+
+	// Unsent input belongs to the file it was written for
+	m.recoveryWritten = false
+	m.recoverySnapshot = ""
+	m.offerRecovery()
 
 	// If we have dimensions, initialize viewports now
 	if m.width > 0 && m.height > 0 {
 		m.handleResize()
 	}
 
-	return m, nil
+	return m
 }
 
 // saveDocument saves the current document back to file
@@ -1068,11 +1512,20 @@ func (m *Model) saveDocument() error {
 	return nil
 }
 
-// View renders the UI based on current mode
+// View renders the UI based on current mode, with the theme's ASCII
+// fallbacks applied to whatever wasn't converted before layout
 func (m Model) View() string {
+	return m.glyphs(m.view())
+}
+
+// view renders the UI for the current mode
+func (m Model) view() string {
 	if m.err != nil {
 		return fmt.Sprintf("Error: %v\n\nPress q to quit", m.err)
 	}
+	if m.showHelp {
+		return m.viewHelp()
+	}
 
 	switch m.mode {
 	case ModeFilePicker:
@@ -1097,6 +1550,10 @@ func (m Model) View() string {
 		return m.viewSelectSuggestionType()
 	case ModeSelectRange:
 		return m.viewSelectRange()
+	case ModeConflicts:
+		return m.viewConflicts()
+	case ModeRecover:
+		return m.viewRecover()
 	default:
 		return "Unknown mode"
 	}
@@ -1105,13 +1562,13 @@ func (m Model) View() string {
 // viewFilePicker renders the file picker view
 func (m Model) viewFilePicker() string {
 	title := titleStyle.Render("comments - Select a markdown file")
-	help := helpStyle.Render("↑/↓: navigate • Enter: select • q: quit")
+	help := helpStyle.Render(m.withHelpKey("↑/↓: navigate • Enter: select • q: quit"))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
 		"",
-		m.filePicker.View(),
+		m.decoratePicker(m.filePicker.View()),
 		"",
 		help,
 	)
@@ -1124,26 +1581,32 @@ func (m Model) viewBrowse() string {
 	}
 
 	modeStr := m.mode.String()
-	title := titleStyle.Render(fmt.Sprintf("📄 %s - %s", m.filename, modeStr))
+	if m.readOnly {
+		modeStr += " (read-only)"
+	}
+	title := titleStyle.Render(fmt.Sprintf("📄 %s - %s%s", m.filename, modeStr, m.focusMarker()))
 
 	var helpText string
 	if m.mode == ModeLineSelect {
-		helpText = "j/k: move • Ctrl+D/U: page • g/G: top/bottom • c: comment (section if heading) • s: suggest (range/section) • Esc: cancel"
+		helpText = "j/k: move • 10j, {/}, :N, /text: jump • g/G: top/bottom • c: comment (section if heading) • v: select range • D: document comment • s: suggest (range/section) • Esc: cancel"
 	} else {
 		quitText := "back"
 		if m.startedWithFile {
 			quitText = "quit"
 		}
-		helpText = fmt.Sprintf("j/k: navigate • c: comment • Enter: expand • R: toggle resolved • q: %s", quitText)
+		navigate := "j/k: navigate"
+		if m.documentFocused {
+			navigate = "j/k: scroll"
+		}
+		helpText = fmt.Sprintf("%s • Tab: switch pane • c: comment • Enter: expand • Ctrl+D/U: scroll • </>: resize • F: follow • R: toggle resolved • C: conflicts • y/Y/L: copy • A: avatars • q: %s", navigate, quitText)
+		if m.workspace != nil {
+			helpText = fmt.Sprintf("%s • Tab: switch pane • [/]: switch file • c: comment • Enter: expand • Ctrl+D/U: scroll • </>: resize • F: follow • R: toggle resolved • C: conflicts • y/Y/L: copy • A: avatars • q: %s", navigate, quitText)
+		}
 	}
-	help := helpStyle.Render(helpText)
+	help := m.renderHelp(helpText)
 
-	// Layout: document on left, comments on right
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.documentViewport.View(),
-		commentPanelStyle.Render(m.commentViewport.View()),
-	)
+	// Layout: document and comment panes (file list first in a workspace)
+	content := m.joinWorkspacePanes(m.renderPanes())
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -1163,12 +1626,8 @@ func (m Model) viewAddComment() string {
 	modeStr := "Adding Comment"
 	title := titleStyle.Render(fmt.Sprintf("📄 %s - Mode: %s", m.filename, modeStr))
 
-	// Layout: document on left, comments on right (background)
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.documentViewport.View(),
-		commentPanelStyle.Render(m.commentViewport.View()),
-	)
+	// Layout: document and comment panes (background)
+	content := m.renderPanes()
 
 	// Get section-aware context
 	var contextText string
@@ -1178,7 +1637,7 @@ func (m Model) viewAddComment() string {
 		contextText = sectionContext
 	} else {
 		// Fall back to line-based context if no section found
-		contextLines := m.getContextLines(m.selectedLine, 2)
+		contextLines := m.getContextLines(m.selectedLine, m.contextSize)
 		var builder strings.Builder
 
 		contextStyle := lipgloss.NewStyle().
@@ -1232,13 +1691,25 @@ func (m Model) viewAddComment() string {
 	}
 	typeDisplay := selectionStyle.Render(fmt.Sprintf("Type: %s", typeLabel))
 
+	visibility := "Shared"
+	if m.draftMode {
+		visibility = "Draft (private)"
+	} else if m.privateMode {
+		visibility = "Private note (only you)"
+	}
+	visibilityDisplay := selectionStyle.Render(visibility)
+
 	selectionInfo := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("242")).
-		Render(fmt.Sprintf("%s  •  %s", priorityLabel, typeDisplay))
+		Render(fmt.Sprintf("%s  •  %s  •  %s", priorityLabel, typeDisplay, visibilityDisplay))
 
 	// Modal overlay for comment input
 	var titleText string
-	if m.targetIsSection {
+	if m.targetIsDocument {
+		titleText = "📄 Add Document Comment"
+	} else if m.rangeActive {
+		titleText = fmt.Sprintf("💬 Add Comment on Lines %d-%d", m.rangeStartLine, m.rangeEndLine)
+	} else if m.targetIsSection {
 		section := m.getSectionAtLine(m.selectedLine)
 		if section != nil {
 			titleText = fmt.Sprintf("📍 Add Section Comment: %s", section.Title)
@@ -1254,9 +1725,9 @@ func (m Model) viewAddComment() string {
 		Foreground(lipgloss.Color("170")).
 		Render(titleText)
 
-	modalHelp := helpStyle.Render("Ctrl+S: save • Ctrl+P: cycle priority • Ctrl+T: cycle type • Esc: cancel")
+	modalHelp := helpStyle.Render(m.withHelpKey("Ctrl+S: save • Ctrl+E: $EDITOR • Ctrl+P: cycle priority • Ctrl+T: cycle type • Ctrl+G: shared/draft/private • Esc: cancel"))
 
-	modal := modalOverlayStyle.Render(
+	modal := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			modalTitle,
@@ -1301,13 +1772,13 @@ func (m Model) viewThread() string {
 		return "No thread selected"
 	}
 
-	title := titleStyle.Render(fmt.Sprintf("Thread at Line %d", m.selectedThread.Line))
+	title := titleStyle.Render("Thread at " + threadLocation(m.selectedThread))
 
 	quitText := "file picker"
 	if m.startedWithFile {
 		quitText = "quit"
 	}
-	help := helpStyle.Render(fmt.Sprintf("r: reply • x: resolve • Esc: back • q: %s", quitText))
+	help := m.renderHelp(fmt.Sprintf("r: reply • x: resolve • y/Y/L: copy text/ID/quote • z: earlier replies • [/]: select reply • Enter/Z: fold one/all • Esc: back • q: %s", quitText))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -1325,7 +1796,7 @@ func (m Model) viewReply() string {
 		return "No thread selected"
 	}
 
-	title := titleStyle.Render(fmt.Sprintf("Thread at Line %d", m.selectedThread.Line))
+	title := titleStyle.Render("Thread at " + threadLocation(m.selectedThread))
 
 	// Thread content as background
 	threadContent := m.threadViewport.View()
@@ -1342,7 +1813,7 @@ func (m Model) viewReply() string {
 	// Root comment (selectedThread IS the root comment in v2.0)
 	threadContext.WriteString(fmt.Sprintf("┌ @%s · %s\n",
 		m.selectedThread.Author,
-		m.selectedThread.Timestamp.Format("2006-01-02 15:04")))
+		m.formatTime(m.selectedThread.Timestamp)))
 
 	// Truncate root comment if too long
 	rootText := m.selectedThread.Text
@@ -1364,7 +1835,7 @@ func (m Model) viewReply() string {
 			reply := m.selectedThread.Replies[i]
 			threadContext.WriteString(fmt.Sprintf("├ @%s · %s\n",
 				reply.Author,
-				reply.Timestamp.Format("2006-01-02 15:04")))
+				m.formatTime(reply.Timestamp)))
 
 			// Truncate reply if too long
 			replyText := reply.Text
@@ -1377,14 +1848,21 @@ func (m Model) viewReply() string {
 	threadContext.WriteString("└──────────────────────\n")
 
 	// Modal overlay for reply input
+	replyTitle := "Reply to Thread"
+	switch m.pendingDecision {
+	case comment.DecisionAccepted:
+		replyTitle = "Why accept this suggestion? (a reason is required)"
+	case comment.DecisionRejected:
+		replyTitle = "Why reject this suggestion? (a reason is required)"
+	}
 	modalTitle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("170")).
-		Render("Reply to Thread")
+		Render(replyTitle)
 
-	modalHelp := helpStyle.Render("Ctrl+S: save • Esc: cancel")
+	modalHelp := helpStyle.Render(m.withHelpKey("Ctrl+S: save • Ctrl+E: $EDITOR • Esc: cancel"))
 
-	modal := modalOverlayStyle.Render(
+	modal := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			modalTitle,
@@ -1427,7 +1905,7 @@ func (m Model) viewResolve() string {
 		return "No thread selected"
 	}
 
-	title := titleStyle.Render(fmt.Sprintf("Thread at Line %d", m.selectedThread.Line))
+	title := titleStyle.Render("Thread at " + threadLocation(m.selectedThread))
 
 	// Thread content as background
 	threadContent := m.threadViewport.View()
@@ -1441,9 +1919,9 @@ func (m Model) viewResolve() string {
 	confirmText := lipgloss.NewStyle().
 		Render("This will mark the entire conversation as resolved.\nResolved comments can be toggled with 'R' in browse mode.")
 
-	confirmHelp := helpStyle.Render("y/Enter: confirm • n/Esc: cancel")
+	confirmHelp := helpStyle.Render(m.withHelpKey("y/Enter: confirm • n/Esc: cancel"))
 
-	dialog := modalOverlayStyle.Render(
+	dialog := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			confirmTitle,
@@ -1519,15 +1997,21 @@ func (m Model) viewReviewSuggestion() string {
 		Foreground(lipgloss.Color("3")).
 		Render("Accept this suggestion?")
 
-	suggestionInfo := fmt.Sprintf("Type: multi-line\nAuthor: @%s\nLines: %d-%d",
+	suggestionType := "multi-line"
+	if m.selectedSuggestion.IsMove {
+		suggestionType = "move"
+	} else if m.selectedSuggestion.IsInsertion() {
+		suggestionType = "insertion"
+	}
+	suggestionInfo := fmt.Sprintf("Type: %s\nAuthor: @%s\n%s",
+		suggestionType,
 		m.selectedSuggestion.Author,
-		m.selectedSuggestion.StartLine,
-		m.selectedSuggestion.EndLine)
+		comment.DescribeSuggestionLines(m.selectedSuggestion))
 
 	confirmText := lipgloss.NewStyle().Render(suggestionInfo)
-	confirmHelp := helpStyle.Render("y/Enter: accept and apply • n/Esc: cancel")
+	confirmHelp := helpStyle.Render(m.withHelpKey("y/Enter: accept and apply • n/Esc: cancel"))
 
-	dialog := modalOverlayStyle.Render(
+	dialog := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			confirmTitle,
@@ -1558,9 +2042,14 @@ func (m Model) viewReviewSuggestion() string {
 	)
 }
 
-// viewAddSuggestion renders the add suggestion modal
+// viewAddSuggestion renders the add suggestion form
 func (m Model) viewAddSuggestion() string {
 	title := titleStyle.Render(fmt.Sprintf("Add Suggestion for Line %d", m.selectedLine))
+	if m.suggestionIsInsertion && m.rangeEndLine == 0 {
+		title = titleStyle.Render("Add Suggestion at Top of Document")
+	} else if m.suggestionIsInsertion {
+		title = titleStyle.Render(fmt.Sprintf("Add Suggestion After Line %d", m.rangeEndLine))
+	}
 
 	// Document context as background
 	docContent := m.documentViewport.View()
@@ -1579,14 +2068,26 @@ func (m Model) viewAddSuggestion() string {
 		Background(lipgloss.Color("235")).
 		Padding(0, 1).
 		Render(m.suggestionOriginalText)
+	if m.suggestionIsInsertion {
+		originalLabel = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Render("Inserts new lines; nothing is replaced")
+		originalText = ""
+	}
 
-	proposedLabel := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Render("Proposed text (edit below):")
+	typeLabel := "None"
+	if m.commentType != "" {
+		typeLabel = fmt.Sprintf("[%s] %s", m.commentType, commentTypeNames[m.commentType])
+	}
+
+	formErr := ""
+	if m.suggestionFormErr != "" {
+		formErr = statusMessageStyle.Render(m.suggestionFormErr)
+	}
 
-	help := helpStyle.Render("Ctrl+S or Ctrl+D: submit • Esc: cancel")
+	help := helpStyle.Render(m.withHelpKey("Tab/Shift+Tab: next/previous field • ←/→: change type/priority • Ctrl+S or Ctrl+D: submit • Esc: cancel"))
 
-	dialog := modalOverlayStyle.Render(
+	dialog := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			formTitle,
@@ -1594,9 +2095,16 @@ func (m Model) viewAddSuggestion() string {
 			originalLabel,
 			originalText,
 			"",
-			proposedLabel,
+			m.suggestionFieldLabel(fieldRationale, "Rationale (why this change?):"),
+			m.rationaleInput.View(),
+			"",
+			m.suggestionFieldLabel(fieldProposed, "Proposed text (edit below):"),
 			m.proposedTextInput.View(),
 			"",
+			m.suggestionFieldLabel(fieldType, fmt.Sprintf("Type: ‹ %s ›", typeLabel)),
+			m.suggestionFieldLabel(fieldPriority, fmt.Sprintf("Priority: ‹ %s ›", strings.ToUpper(m.priority))),
+			"",
+			formErr,
 			help,
 		),
 	)
@@ -1633,26 +2141,23 @@ func (m *Model) scrollToComment(c *comment.Comment) {
 
 	// Get the comment's line position (line-only tracking in v2.0)
 	targetLine := c.Line
+	if c.IsDocumentLevel() {
+		m.documentViewport.GotoTop()
+		return
+	}
 	if targetLine < 1 {
 		return
 	}
 
-	// Calculate the rendered line position accounting for line wrapping
-	// We need to count how many rendered lines come before our target line
-	lines := strings.Split(m.doc.Content, "\n")
-	renderedLinesBefore := 0
-
 	// Calculate available width for text (same as in renderDocument)
 	availableWidth := m.documentViewport.Width - 10
 	if availableWidth < 40 {
 		availableWidth = 40
 	}
 
-	// Count wrapped lines for all lines before the target
-	for i := 0; i < len(lines) && i < targetLine-1; i++ {
-		wrappedCount := len(strings.Split(wordwrap.String(lines[i], availableWidth), "\n"))
-		renderedLinesBefore += wrappedCount
-	}
+	// Calculate the rendered line position accounting for line wrapping
+	layout := comment.NewDisplayLayout(m.doc.Content, availableWidth)
+	renderedLinesBefore := layout.LineStartRow(targetLine)
 
 	// Calculate target Y offset to center the line in the viewport
 	viewportHeight := m.documentViewport.Height
@@ -1705,12 +2210,8 @@ func (m Model) viewChooseTarget() string {
 	modeStr := "Choose Target"
 	title := titleStyle.Render(fmt.Sprintf("📄 %s - Mode: %s", m.filename, modeStr))
 
-	// Layout: document on left, comments on right (background)
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.documentViewport.View(),
-		commentPanelStyle.Render(m.commentViewport.View()),
-	)
+	// Layout: document and comment panes (background)
+	content := m.renderPanes()
 
 	// Get section info
 	section := m.getSectionAtLine(m.selectedLine)
@@ -1727,9 +2228,9 @@ func (m Model) viewChooseTarget() string {
 	choices.WriteString(fmt.Sprintf("      (covers lines %d-%d)\n\n", section.StartLine, section.EndLine))
 	choices.WriteString(fmt.Sprintf("  [l] 💬 Line %d only (heading line)\n\n", m.selectedLine))
 
-	modalHelp := helpStyle.Render("s: section • l: line • Esc: cancel")
+	modalHelp := helpStyle.Render(m.withHelpKey("s: section • l: line • Esc: cancel"))
 
-	modal := modalOverlayStyle.Render(
+	modal := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			modalTitle,
@@ -1773,12 +2274,8 @@ func (m Model) viewSelectSuggestionType() string {
 	modeStr := "Choose Suggestion Type"
 	title := titleStyle.Render(fmt.Sprintf("📄 %s - Mode: %s", m.filename, modeStr))
 
-	// Layout: document on left, comments on right (background)
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.documentViewport.View(),
-		commentPanelStyle.Render(m.commentViewport.View()),
-	)
+	// Layout: document and comment panes (background)
+	content := m.renderPanes()
 
 	// Get section info
 	section := m.getSectionAtLine(m.selectedLine)
@@ -1795,9 +2292,9 @@ func (m Model) viewSelectSuggestionType() string {
 	choices.WriteString(fmt.Sprintf("  [s] 📍 Section: %s\n", sectionPath))
 	choices.WriteString(fmt.Sprintf("      (lines %d-%d)\n\n", section.StartLine, section.EndLine))
 
-	modalHelp := helpStyle.Render("r: range • s: section • Esc: cancel")
+	modalHelp := helpStyle.Render(m.withHelpKey("r: range • s: section • Esc: cancel"))
 
-	modal := modalOverlayStyle.Render(
+	modal := m.renderModal(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			modalTitle,
@@ -1841,14 +2338,10 @@ func (m Model) viewSelectRange() string {
 	modeStr := fmt.Sprintf("Range Selection: Lines %d-%d", m.rangeStartLine, m.rangeEndLine)
 	title := titleStyle.Render(fmt.Sprintf("📄 %s - Mode: %s", m.filename, modeStr))
 
-	// Layout: document on left, comments on right (background)
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.documentViewport.View(),
-		commentPanelStyle.Render(m.commentViewport.View()),
-	)
+	// Layout: document and comment panes (background)
+	content := m.renderPanes()
 
-	helpText := helpStyle.Render("j/k: adjust end line • Enter: confirm • Esc: cancel")
+	helpText := m.renderHelp("j/k: adjust end line • 10j, {/}, :N, /text: jump • Enter: suggest edit • c: comment on range • Esc: cancel")
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
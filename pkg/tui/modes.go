@@ -39,6 +39,12 @@ const (
 
 	// ModeSelectRange shows visual range selection for multi-line suggestions
 	ModeSelectRange
+
+	// ModeConflicts shows overlapping suggestions side by side for resolution
+	ModeConflicts
+
+	// ModeRecover offers to restore unsent text left by a previous run
+	ModeRecover
 )
 
 // String returns the string representation of the view mode
@@ -68,6 +74,10 @@ func (m ViewMode) String() string {
 		return "SELECT_SUGGESTION_TYPE"
 	case ModeSelectRange:
 		return "SELECT_RANGE"
+	case ModeConflicts:
+		return "CONFLICTS"
+	case ModeRecover:
+		return "RECOVER"
 	default:
 		return "UNKNOWN"
 	}
@@ -75,7 +85,7 @@ func (m ViewMode) String() string {
 
 // IsModal returns true if the mode represents a modal dialog
 func (m ViewMode) IsModal() bool {
-	return m == ModeAddComment || m == ModeReply || m == ModeResolve || m == ModeReviewSuggestion || m == ModeAddSuggestion || m == ModeChooseTarget || m == ModeSelectSuggestionType
+	return m == ModeAddComment || m == ModeReply || m == ModeResolve || m == ModeReviewSuggestion || m == ModeAddSuggestion || m == ModeChooseTarget || m == ModeSelectSuggestionType || m == ModeRecover
 }
 
 // IsInteractive returns true if the mode requires user input
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Vim-style navigation while picking a line or range:
+//
+//	10j / 5k   move by a count
+//	42G        go to line 42
+//	{ / }      previous / next paragraph (blank line)
+//	:42        go to line 42
+//	/text      search forward (n / N repeat forward / backward)
+//
+// In range selection the motions move the end of the range.
+
+// handleMotionKeys handles counts, motions, and the : and / prompts in line
+// and range selection. Returns false if the key isn't a motion so the
+// mode's own handler can take it.
+func (m Model) handleMotionKeys(msg tea.KeyMsg) (Model, bool) {
+	if m.motionPrompt != "" {
+		return m.handlePromptKeys(msg), true
+	}
+
+	key := msg.String()
+
+	// Digits build up a count; a leading 0 isn't a count
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.motionCount != "") {
+		m.motionCount += key
+		return m, true
+	}
+
+	count, hasCount := m.takeCount()
+	lines := strings.Split(m.doc.Content, "\n")
+
+	switch key {
+	case "j", "down":
+		if !hasCount {
+			return m, false
+		}
+		m.moveCursorTo(m.cursorLine() + count)
+
+	case "k", "up":
+		if !hasCount {
+			return m, false
+		}
+		m.moveCursorTo(m.cursorLine() - count)
+
+	case "G":
+		if !hasCount {
+			return m, false
+		}
+		m.moveCursorTo(count)
+
+	case "}":
+		line := m.cursorLine()
+		for i := 0; i < count; i++ {
+			line = nextParagraph(lines, line)
+		}
+		m.moveCursorTo(line)
+
+	case "{":
+		line := m.cursorLine()
+		for i := 0; i < count; i++ {
+			line = previousParagraph(lines, line)
+		}
+		m.moveCursorTo(line)
+
+	case ":", "/":
+		m.motionPrompt = key
+		m.motionInput = ""
+
+	case "n", "N":
+		if m.lastSearch == "" {
+			m.statusMsg = "No previous search"
+			return m, true
+		}
+		m.search(m.lastSearch, key == "n")
+
+	default:
+		return m, false
+	}
+
+	return m, true
+}
+
+// handlePromptKeys edits and runs the : (go to line) or / (search) prompt
+func (m Model) handlePromptKeys(msg tea.KeyMsg) Model {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.motionPrompt = ""
+		return m
+
+	case tea.KeyBackspace:
+		if m.motionInput == "" {
+			m.motionPrompt = ""
+			return m
+		}
+		runes := []rune(m.motionInput)
+		m.motionInput = string(runes[:len(runes)-1])
+		return m
+
+	case tea.KeyEnter:
+		prompt, input := m.motionPrompt, m.motionInput
+		m.motionPrompt = ""
+		if input == "" {
+			return m
+		}
+		if prompt == ":" {
+			line, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Not a line number: %s", input)
+				return m
+			}
+			m.moveCursorTo(line)
+			return m
+		}
+		m.lastSearch = input
+		m.search(input, true)
+		return m
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.motionInput += string(msg.Runes)
+	}
+	return m
+}
+
+// takeCount returns and clears the pending count (1 if none was typed)
+func (m *Model) takeCount() (int, bool) {
+	if m.motionCount == "" {
+		return 1, false
+	}
+	count, err := strconv.Atoi(m.motionCount)
+	m.motionCount = ""
+	if err != nil || count < 1 {
+		return 1, false
+	}
+	return count, true
+}
+
+// cursorLine returns the line the motions move: the cursor in line
+// selection, the end of the range in range selection
+func (m *Model) cursorLine() int {
+	if m.mode == ModeSelectRange {
+		return m.rangeEndLine
+	}
+	return m.selectedLine
+}
+
+// moveCursorTo moves the cursor (or range end) to line, clamped to the
+// document, and scrolls it into view
+func (m *Model) moveCursorTo(line int) {
+	totalLines := len(strings.Split(m.doc.Content, "\n"))
+	line = min(max(line, 1), totalLines)
+
+	if m.mode == ModeSelectRange {
+		// The range end can't move above its start
+		line = max(line, m.rangeStartLine)
+		m.rangeEndLine = line
+	} else {
+		m.selectedLine = line
+	}
+
+	m.documentViewport.SetContent(m.renderDocumentWithCursor())
+	m.scrollToLine(line)
+}
+
+// search moves to the next (or previous) line containing text, wrapping
+// around the document. Matching is case-insensitive.
+func (m *Model) search(text string, forward bool) {
+	lines := strings.Split(m.doc.Content, "\n")
+	if line := findLine(lines, m.cursorLine(), text, forward); line > 0 {
+		m.moveCursorTo(line)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Pattern not found: %s", text)
+}
+
+// findLine returns the first line after (or before) from containing text,
+// wrapping around, or 0 if no line matches
+func findLine(lines []string, from int, text string, forward bool) int {
+	needle := strings.ToLower(text)
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for i := 1; i <= len(lines); i++ {
+		line := ((from-1+i*step)%len(lines)+len(lines))%len(lines) + 1
+		if strings.Contains(strings.ToLower(lines[line-1]), needle) {
+			return line
+		}
+	}
+	return 0
+}
+
+// nextParagraph returns the next blank line after a run of text below from
+// (the last line if there is none), like vim's }
+func nextParagraph(lines []string, from int) int {
+	line := from + 1
+	for line <= len(lines) && strings.TrimSpace(lines[line-1]) == "" {
+		line++
+	}
+	for line <= len(lines) && strings.TrimSpace(lines[line-1]) != "" {
+		line++
+	}
+	return min(line, len(lines))
+}
+
+// previousParagraph returns the previous blank line before a run of text
+// above from (the first line if there is none), like vim's {
+func previousParagraph(lines []string, from int) int {
+	line := from - 1
+	for line >= 1 && strings.TrimSpace(lines[line-1]) == "" {
+		line--
+	}
+	for line >= 1 && strings.TrimSpace(lines[line-1]) != "" {
+		line--
+	}
+	return max(line, 1)
+}
+
+// motionHelp returns the : or / prompt being typed or the pending count,
+// or "" if there is neither
+func (m Model) motionHelp() string {
+	if m.motionPrompt == "" {
+		return m.motionCount
+	}
+	return m.motionPrompt + m.motionInput + "█"
+}
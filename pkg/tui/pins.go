@@ -0,0 +1,11 @@
+package tui
+
+import "github.com/rcliao/comments/pkg/comment"
+
+// pinBadge marks pinned threads in the comment pane
+func (m *Model) pinBadge(thread *comment.Comment) string {
+	if !thread.Pinned {
+		return ""
+	}
+	return " " + badgeStyle.Render("pinned")
+}
@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// loadPrivateNotes merges the author's private notes into the open file, so
+// only they see them; saving writes them back to their own overlay
+func (m *Model) loadPrivateNotes() {
+	if m.doc == nil || m.filename == "" {
+		return
+	}
+	if err := m.doc.MergePrivateNotes(m.filename, m.author); err != nil {
+		m.statusMsg = fmt.Sprintf("Private notes not shown: %v", err)
+	}
+}
+
+// privateBadge marks the author's private notes in the comment pane
+func (m *Model) privateBadge(thread *comment.Comment) string {
+	if !thread.IsPrivate() {
+		return ""
+	}
+	return " " + badgeStyle.Render("private")
+}
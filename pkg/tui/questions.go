@@ -0,0 +1,17 @@
+package tui
+
+import (
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// questionBadge tells open questions from answered ones waiting to be
+// resolved in the comment pane; the policy's owners answer them
+func (m *Model) questionBadge(thread *comment.Comment) string {
+	switch thread.QuestionState(m.policy.Answerer()) {
+	case comment.QuestionOpen:
+		return " " + badgeStyle.Render("? unanswered")
+	case comment.QuestionAnswered:
+		return " " + badgeStyle.Render("✓ answered")
+	}
+	return ""
+}
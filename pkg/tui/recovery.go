@@ -0,0 +1,403 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// autosaveInterval is how often unsent modal input is written to the
+// recovery file
+const autosaveInterval = 3 * time.Second
+
+// Kinds of input a recovery file can hold
+const (
+	recoverComment    = "comment"
+	recoverReply      = "reply"
+	recoverSuggestion = "suggestion"
+)
+
+// Recovery is unsent text from an add-comment, reply, or add-suggestion
+// modal, saved so a crash or a dead terminal doesn't lose it
+type Recovery struct {
+	File    string    `json:"file"`
+	Kind    string    `json:"kind"` // comment, reply, or suggestion
+	SavedAt time.Time `json:"saved_at"`
+
+	// Where the comment or suggestion goes
+	Line     int    `json:"line,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"`
+	Section  bool   `json:"section,omitempty"`
+	Insert   bool   `json:"insert,omitempty"` // Suggestion inserts after Line
+	Document bool   `json:"document,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"` // Thread being replied to
+	Decision string `json:"decision,omitempty"`  // Accept/reject waiting for this reason
+	Type     string `json:"type,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Draft    bool   `json:"draft,omitempty"`
+	Private  bool   `json:"private,omitempty"`
+	Text     string `json:"text,omitempty"` // Comment or reply text, or suggestion rationale
+	Original string `json:"original_text,omitempty"`
+	Proposed string `json:"proposed_text,omitempty"`
+}
+
+// autosaveMsg triggers a recovery autosave
+type autosaveMsg struct{}
+
+// autosaveTick schedules the next autosave
+func autosaveTick() tea.Cmd {
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg { return autosaveMsg{} })
+}
+
+// DefaultRecoveryDir returns the per-user directory for recovery files
+func DefaultRecoveryDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "comments", "recovery"), nil
+}
+
+// recoveryPath returns the recovery file for a document inside dir
+func recoveryPath(dir, filename string) string {
+	sum := sha256.Sum256([]byte(sessionKey(filename)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadRecovery reads the recovery file for a document, if there is one
+func loadRecovery(dir, filename string) (*Recovery, error) {
+	data, err := os.ReadFile(recoveryPath(dir, filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recovery file: %w", err)
+	}
+
+	r := &Recovery{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery file: %w", err)
+	}
+	if r.File != sessionKey(filename) {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// SetRecoveryDir enables autosaving unsent modal input to dir and offers to
+// restore anything left there for the open file by a previous run
+func (m *Model) SetRecoveryDir(dir string) {
+	m.recoveryDir = dir
+	m.offerRecovery()
+}
+
+// offerRecovery switches to the restore prompt if the open file has unsent
+// input from a previous run
+func (m *Model) offerRecovery() {
+	m.recovered = nil
+	if m.recoveryDir == "" || m.doc == nil || m.filename == "" {
+		return
+	}
+
+	r, err := loadRecovery(m.recoveryDir, m.filename)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return
+	}
+	if r != nil {
+		m.recovered = r
+		m.mode = ModeRecover
+	}
+}
+
+// unsentInput captures the open modal's text, or nil if there is nothing
+// worth keeping
+func (m *Model) unsentInput() *Recovery {
+	r := &Recovery{
+		File:     sessionKey(m.filename),
+		Type:     m.commentType,
+		Priority: m.priority,
+	}
+
+	switch m.mode {
+	case ModeAddComment:
+		r.Kind = recoverComment
+		r.Text = m.commentInput.Value()
+		r.Line = m.selectedLine
+		if m.rangeActive {
+			r.Line, r.EndLine = m.rangeStartLine, m.rangeEndLine
+		}
+		r.Section = m.targetIsSection
+		r.Document = m.targetIsDocument
+		r.Draft = m.draftMode
+		r.Private = m.privateMode
+
+	case ModeReply:
+		if m.selectedThread == nil {
+			return nil
+		}
+		r.Kind = recoverReply
+		r.Text = m.commentInput.Value()
+		r.ThreadID = m.selectedThread.ID
+		r.Decision = m.pendingDecision
+
+	case ModeAddSuggestion:
+		r.Kind = recoverSuggestion
+		r.Text = m.rationaleInput.Value()
+		r.Line, r.EndLine = m.rangeStartLine, m.rangeEndLine
+		if m.suggestionIsInsertion {
+			r.Line, r.EndLine, r.Insert = m.rangeEndLine, 0, true
+		}
+		r.Section = m.suggestionIsSection
+		r.Original = m.suggestionOriginalText
+		r.Proposed = m.proposedTextInput.Value()
+		if strings.TrimSpace(r.Text) == "" && r.Proposed == r.Original {
+			return nil
+		}
+		return r
+
+	default:
+		return nil
+	}
+
+	if strings.TrimSpace(r.Text) == "" {
+		return nil
+	}
+	return r
+}
+
+// autosave writes the open modal's unsent text to the recovery file, and
+// removes the file once that text has been saved or discarded
+func (m *Model) autosave() error {
+	if m.recoveryDir == "" || m.filename == "" {
+		return nil
+	}
+	path := recoveryPath(m.recoveryDir, m.filename)
+
+	r := m.unsentInput()
+	if r == nil {
+		if !m.recoveryWritten {
+			return nil
+		}
+		m.recoveryWritten = false
+		m.recoverySnapshot = ""
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove recovery file: %w", err)
+		}
+		return nil
+	}
+
+	// Skip the write if nothing was typed since the last one
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode recovery file: %w", err)
+	}
+	if string(data) == m.recoverySnapshot {
+		return nil
+	}
+	snapshot := string(data)
+
+	r.SavedAt = time.Now().UTC()
+	if data, err = json.MarshalIndent(r, "", "  "); err != nil {
+		return fmt.Errorf("failed to encode recovery file: %w", err)
+	}
+	if err := os.MkdirAll(m.recoveryDir, 0700); err != nil {
+		return fmt.Errorf("failed to create recovery directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write recovery file: %w", err)
+	}
+	m.recoveryWritten = true
+	m.recoverySnapshot = snapshot
+	return nil
+}
+
+// FlushRecovery brings the recovery file up to date before exiting: it is
+// kept if a modal still holds unsent text and removed otherwise
+func (m Model) FlushRecovery() error {
+	return m.autosave()
+}
+
+// handleAutosave saves unsent input and schedules the next autosave
+func (m Model) handleAutosave() (tea.Model, tea.Cmd) {
+	if err := m.autosave(); err != nil {
+		m.statusMsg = err.Error()
+	}
+	return m, autosaveTick()
+}
+
+// handleRecoverKeys handles keys in the restore prompt
+func (m Model) handleRecoverKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		return m.restoreRecovery()
+
+	case "n":
+		// Discard the recovered text for good
+		if err := os.Remove(recoveryPath(m.recoveryDir, m.filename)); err != nil && !os.IsNotExist(err) {
+			m.statusMsg = fmt.Sprintf("failed to remove recovery file: %v", err)
+		}
+		m.recovered = nil
+		m.mode = ModeBrowse
+		return m, nil
+
+	case "esc":
+		// Keep the file and ask again next time
+		m.recovered = nil
+		m.mode = ModeBrowse
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// restoreRecovery reopens the modal the recovered text came from, filled in
+// as it was left
+func (m Model) restoreRecovery() (tea.Model, tea.Cmd) {
+	r := m.recovered
+	m.recovered = nil
+	m.mode = ModeBrowse
+	if r == nil {
+		return m, nil
+	}
+	// The file is now this run's to update, and to remove once the text is sent
+	m.recoveryWritten = true
+
+	lineCount := len(strings.Split(m.doc.Content, "\n"))
+	m.commentType = r.Type
+	m.priority = r.Priority
+	if m.priority == "" {
+		m.priority = "medium"
+	}
+
+	switch r.Kind {
+	case recoverComment:
+		m.selectedLine = min(max(r.Line, 1), lineCount)
+		m.rangeActive = r.EndLine > r.Line
+		m.rangeStartLine, m.rangeEndLine = m.selectedLine, min(r.EndLine, lineCount)
+		m.targetIsSection = r.Section
+		m.targetIsDocument = r.Document
+		m.draftMode = r.Draft
+		m.privateMode = r.Private
+		m.mode = ModeAddComment
+		m.commentInput.Reset()
+		m.commentInput.SetValue(r.Text)
+		m.commentInput.Focus()
+		return m, textarea.Blink
+
+	case recoverReply:
+		thread := m.doc.FindCommentByID(r.ThreadID)
+		if thread == nil {
+			m.statusMsg = fmt.Sprintf("Thread %s no longer exists; the unsent reply is still in %s", r.ThreadID, recoveryPath(m.recoveryDir, m.filename))
+			return m, nil
+		}
+		m.selectedThread = thread
+		m.resetFolding()
+		m.markSeen(thread)
+		m.threadViewport.SetContent(m.renderThread())
+		if r.Decision != "" && thread.IsSuggestion && thread.IsPending() {
+			m.selectedSuggestion = thread
+			m.pendingDecision = r.Decision
+		}
+		m.mode = ModeReply
+		m.commentInput.Reset()
+		m.commentInput.SetValue(r.Text)
+		m.commentInput.Focus()
+		return m, textarea.Blink
+
+	case recoverSuggestion:
+		if r.Insert {
+			after := min(max(r.Line, 0), lineCount)
+			m.selectedLine = max(after, 1)
+			model, cmd := m.openInsertionForm(after)
+			restored := model.(Model)
+			restored.rationaleInput.SetValue(r.Text)
+			restored.proposedTextInput.SetValue(r.Proposed)
+			return restored, cmd
+		}
+		m.rangeStartLine = min(max(r.Line, 1), lineCount)
+		m.rangeEndLine = min(max(r.EndLine, m.rangeStartLine), lineCount)
+		m.selectedLine = m.rangeStartLine
+		m.rangeActive = true
+		m.suggestionIsSection = r.Section
+		m.suggestionOriginalText = r.Original
+		m.mode = ModeAddSuggestion
+		m.suggestionFormErr = ""
+		m.rationaleInput.Reset()
+		m.rationaleInput.SetValue(r.Text)
+		m.proposedTextInput.Reset()
+		m.proposedTextInput.SetValue(r.Proposed)
+		if current := strings.Join(strings.Split(m.doc.Content, "\n")[m.rangeStartLine-1:m.rangeEndLine], "\n"); current != r.Original {
+			m.suggestionFormErr = "The document changed since this suggestion was written; check the lines before saving"
+		}
+		return m, m.focusSuggestionField(fieldRationale)
+	}
+
+	return m, nil
+}
+
+// viewRecover renders the restore prompt
+func (m Model) viewRecover() string {
+	r := m.recovered
+	if r == nil {
+		return "Nothing to recover"
+	}
+
+	what := map[string]string{
+		recoverComment:    "comment",
+		recoverReply:      "reply",
+		recoverSuggestion: "suggestion",
+	}[r.Kind]
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("170")).
+		Render(fmt.Sprintf("Restore the unsent %s from %s?", what, r.SavedAt.Local().Format("2006-01-02 15:04")))
+
+	preview := r.Text
+	if lines := strings.Split(preview, "\n"); len(lines) > 5 {
+		preview = strings.Join(lines[:5], "\n") + "\n..."
+	}
+	text := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("242")).
+		Italic(true).
+		Render(preview)
+
+	help := helpStyle.Render(m.withHelpKey("y/Enter: restore • n: discard • Esc: ask again next time"))
+
+	dialog := m.renderModal(
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			"",
+			text,
+			"",
+			help,
+		),
+	)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render("📄 "+m.filename),
+		lipgloss.Place(
+			m.width,
+			m.height-2,
+			lipgloss.Center,
+			lipgloss.Center,
+			dialog,
+			lipgloss.WithWhitespaceChars(" "),
+		),
+	)
+}
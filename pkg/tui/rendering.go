@@ -88,15 +88,18 @@ func (m *Model) renderDocument() string {
 
 	// Group comments by line (only root comments)
 	commentsByLine := comment.GroupCommentsByLine(m.doc.Threads)
+	coveredLines := comment.LinesCoveredByRanges(m.doc.Threads)
 
 	for i, line := range lines {
 		lineNum := i + 1
 		lineNumStr := lineNumberStyle.Render(fmt.Sprintf("%d", lineNum))
 
-		// Add comment marker if this line has comments
+		// Add comment marker if this line has comments, or a bar inside a range
 		marker := "  "
 		if comments := commentsByLine[lineNum]; len(comments) > 0 {
-			marker = commentMarkerStyle.Render(fmt.Sprintf("💬%d", len(comments)))
+			marker = lineMarker(comments)
+		} else if coveredLines[lineNum] {
+			marker = rangeMarkerStyle.Render("│ ")
 		}
 
 		// Apply markdown syntax highlighting
@@ -115,7 +118,7 @@ func (m *Model) renderDocument() string {
 		}
 	}
 
-	return rendered.String()
+	return m.glyphs(rendered.String())
 }
 
 // renderDocumentWithCursor renders the document with a cursor for line selection
@@ -135,15 +138,18 @@ func (m *Model) renderDocumentWithCursor() string {
 
 	// Group comments by line
 	commentsByLine := comment.GroupCommentsByLine(m.doc.Threads)
+	coveredLines := comment.LinesCoveredByRanges(m.doc.Threads)
 
 	for i, line := range lines {
 		lineNum := i + 1
 		lineNumStr := lineNumberStyle.Render(fmt.Sprintf("%d", lineNum))
 
-		// Add comment marker if this line has comments
+		// Add comment marker if this line has comments, or a bar inside a range
 		marker := "  "
 		if comments := commentsByLine[lineNum]; len(comments) > 0 {
-			marker = commentMarkerStyle.Render(fmt.Sprintf("💬%d", len(comments)))
+			marker = lineMarker(comments)
+		} else if coveredLines[lineNum] {
+			marker = rangeMarkerStyle.Render("│ ")
 		}
 
 		// Highlight cursor line
@@ -185,7 +191,17 @@ func (m *Model) renderDocumentWithCursor() string {
 		}
 	}
 
-	return rendered.String()
+	return m.glyphs(rendered.String())
+}
+
+// lineMarker renders the gutter marker for a line's threads, in the author's
+// color when they all come from one person
+func lineMarker(threads []*comment.Comment) string {
+	style := commentMarkerStyle
+	if author := lineAuthor(threads); author != "" {
+		style = style.Foreground(authorColor(author))
+	}
+	return style.Render(fmt.Sprintf("💬%d", len(threads)))
 }
 
 // getCommentTypeColor returns the color for a comment based on its type prefix
@@ -217,8 +233,11 @@ func (m *Model) renderComments() string {
 		return "No comments"
 	}
 
-	visibleComments := comment.GetVisibleComments(m.doc.Threads, m.showResolved)
+	visibleComments := m.visibleThreads()
 	if len(visibleComments) == 0 {
+		if m.followScroll {
+			return fmt.Sprintf("No comments near lines %d-%d\n\nPress F to show all comments", m.followFirstLine, m.followLastLine)
+		}
 		if m.showResolved {
 			return "No comments"
 		}
@@ -230,7 +249,11 @@ func (m *Model) renderComments() string {
 	if m.showResolved {
 		statusText = "all"
 	}
-	rendered.WriteString(fmt.Sprintf("Comments (%d %s)\n\n", len(visibleComments), statusText))
+	if m.followScroll {
+		rendered.WriteString(fmt.Sprintf("Comments near lines %d-%d (%d %s)\n\n", m.followFirstLine, m.followLastLine, len(visibleComments), statusText))
+	} else {
+		rendered.WriteString(fmt.Sprintf("Comments (%d %s)\n\n", len(visibleComments), statusText))
+	}
 
 	for i, c := range visibleComments {
 		// Get reply count directly from thread (v2.0)
@@ -265,38 +288,52 @@ func (m *Model) renderComments() string {
 		// Build location string with section context
 		locationStr := fmt.Sprintf("Line %d", c.Line)
 		icon := "💬"
-		if c.SectionPath != "" {
+		if c.IsDocumentLevel() {
+			locationStr = "Document"
+			icon = "📄"
+		} else if c.IsRange() {
+			locationStr = fmt.Sprintf("Lines %d-%d", c.Line, c.EndLine)
+		} else if c.SectionPath != "" {
 			locationStr = fmt.Sprintf("%s (Line %d)", c.SectionPath, c.Line)
 			icon = "📍"
 		}
 
+		resolvedMark := ""
 		if c.Resolved {
-			commentText = fmt.Sprintf("✓ %s %s • @%s%s\n%s\n%s\n└─ %d replies",
-				icon,
-				locationStr,
-				c.Author,
-				suggestionIndicator,
-				c.Timestamp.Format("2006-01-02 15:04"),
-				c.Text,
-				replyCount,
-			)
-		} else {
-			commentText = fmt.Sprintf("%s %s • @%s%s\n%s\n%s\n└─ %d replies",
-				icon,
-				locationStr,
-				c.Author,
-				suggestionIndicator,
-				c.Timestamp.Format("2006-01-02 15:04"),
-				c.Text,
-				replyCount,
-			)
+			resolvedMark = "✓ "
 		}
-
-		rendered.WriteString(style.Render(commentText))
+		// The author is rendered separately so it keeps its own color
+		header := style.Render(fmt.Sprintf("%s%s%s %s • ", m.selectionMarker(i == m.selectedComment), resolvedMark, icon, locationStr)) +
+			m.renderAuthor(c.Author, style) +
+			style.Render(suggestionIndicator) +
+			m.unreadBadge(c) +
+			m.pinBadge(c) +
+			m.privateBadge(c) +
+			m.changedBadge(c) +
+			m.questionBadge(c)
+		commentText = fmt.Sprintf("%s\n%s%s\n└─ %d replies",
+			m.formatTime(c.Timestamp),
+			m.typeMarker(c),
+			c.Text,
+			replyCount,
+		)
+
+		rendered.WriteString(header + "\n" + style.Render(commentText))
 		rendered.WriteString("\n\n")
 	}
 
-	return rendered.String()
+	return m.glyphs(rendered.String())
+}
+
+// threadLocation returns "Document", "Lines N-M", or "Line N" for a thread
+func threadLocation(c *comment.Comment) string {
+	if c.IsDocumentLevel() {
+		return "Document"
+	}
+	if c.IsRange() {
+		return fmt.Sprintf("Lines %d-%d", c.Line, c.EndLine)
+	}
+	return fmt.Sprintf("Line %d", c.Line)
 }
 
 // renderThread renders an expanded thread view
@@ -310,7 +347,12 @@ func (m *Model) renderThread() string {
 	// Thread header with section context
 	locationStr := fmt.Sprintf("Line %d", m.selectedThread.Line)
 	icon := "💬"
-	if m.selectedThread.SectionPath != "" {
+	if m.selectedThread.IsDocumentLevel() {
+		locationStr = "Document"
+		icon = "📄"
+	} else if m.selectedThread.IsRange() {
+		locationStr = fmt.Sprintf("Lines %d-%d", m.selectedThread.Line, m.selectedThread.EndLine)
+	} else if m.selectedThread.SectionPath != "" {
 		locationStr = fmt.Sprintf("%s (Line %d)", m.selectedThread.SectionPath, m.selectedThread.Line)
 		icon = "📍"
 	}
@@ -320,7 +362,11 @@ func (m *Model) renderThread() string {
 	rendered.WriteString("\n")
 
 	// Document context - show lines around the comment
-	contextLines := m.getContextLines(m.selectedThread.Line, 2) // 2 lines before/after
+	var contextLines []ContextLine
+	if !m.selectedThread.IsDocumentLevel() {
+		first, last := m.selectedThread.LineRange()
+		contextLines = m.getRangeContextLines(first, last, m.contextSize)
+	}
 	if len(contextLines) > 0 {
 		contextStyle := lipgloss.NewStyle().
 			Border(lipgloss.NormalBorder()).
@@ -351,8 +397,9 @@ func (m *Model) renderThread() string {
 			// Wrap the line text
 			wrappedLines := strings.Split(wordwrap.String(styledText, contextWidth), "\n")
 
+			first, last := m.selectedThread.LineRange()
 			for i, wrappedLine := range wrappedLines {
-				if cl.LineNum == m.selectedThread.Line {
+				if cl.LineNum >= first && cl.LineNum <= last {
 					if i == 0 {
 						marker = lipgloss.NewStyle().
 							Foreground(lipgloss.Color("170")).
@@ -409,9 +456,9 @@ func (m *Model) renderThread() string {
 	}
 	wrappedRootText := wordwrap.String(m.selectedThread.Text, rootTextWidth)
 
-	rootText := fmt.Sprintf("@%s · %s\n\n%s",
-		m.selectedThread.Author,
-		m.selectedThread.Timestamp.Format("2006-01-02 15:04"),
+	rootText := fmt.Sprintf("%s · %s\n\n%s",
+		m.renderAuthor(m.selectedThread.Author, lipgloss.NewStyle()),
+		m.formatTime(m.selectedThread.Timestamp),
 		wrappedRootText,
 	)
 
@@ -432,7 +479,7 @@ func (m *Model) renderThread() string {
 		rootText = stateText + "\n\n" + rootText
 	}
 
-	rendered.WriteString(rootStyle.Render(rootText))
+	rendered.WriteString(rootStyle.Render(m.glyphs(rootText)))
 	rendered.WriteString("\n\n")
 
 	// Show suggestion details if this is a suggestion (v2.0 simplified)
@@ -444,7 +491,15 @@ func (m *Model) renderThread() string {
 			Width(m.width - 8)
 
 		suggestionText := fmt.Sprintf("Suggestion Type: multi-line\n")
-		suggestionText += fmt.Sprintf("Lines: %d-%d\n", m.selectedThread.StartLine, m.selectedThread.EndLine)
+		if m.selectedThread.IsMove {
+			suggestionText = "Suggestion Type: move\n"
+		} else if m.selectedThread.IsInsertion() {
+			suggestionText = "Suggestion Type: insertion\n"
+		}
+		suggestionText += comment.DescribeSuggestionLines(m.selectedThread) + "\n"
+		if len(m.selectedThread.DependsOn) > 0 {
+			suggestionText += fmt.Sprintf("Depends on: %s\n", strings.Join(m.selectedThread.DependsOn, ", "))
+		}
 
 		if m.selectedThread.OriginalText != "" {
 			suggestionText += fmt.Sprintf("\nOriginal:\n  %s\n", m.selectedThread.OriginalText)
@@ -459,14 +514,24 @@ func (m *Model) renderThread() string {
 		rendered.WriteString("\n\n")
 	}
 
-	// Replies
+	// Replies, with older ones and collapsed subtrees folded (see folding.go)
+	m.replyCursorRow = 0
 	if len(m.selectedThread.Replies) > 0 {
 		rendered.WriteString(lipgloss.NewStyle().Bold(true).Render(
-			fmt.Sprintf("Replies (%d):", len(m.selectedThread.Replies))))
+			fmt.Sprintf("Replies (%d):", m.selectedThread.CountReplies())))
 		rendered.WriteString("\n\n")
 
 		borderStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-		authorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
+		timestampStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
+		cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+
+		if _, hidden := m.foldedReplies(); hidden > 0 {
+			rendered.WriteString(helpStyle.Render(fmt.Sprintf("⋯ %s folded (z to show)", plural(hidden, "earlier reply"))))
+			rendered.WriteString("\n\n")
+		} else if m.showOlderReplies && len(m.selectedThread.Replies) > recentReplies {
+			rendered.WriteString(helpStyle.Render("(z to fold earlier replies)"))
+			rendered.WriteString("\n\n")
+		}
 
 		// Calculate available width for reply text: width - padding - border characters
 		replyWidth := m.width - 12
@@ -474,32 +539,49 @@ func (m *Model) renderThread() string {
 			replyWidth = 40
 		}
 
-		for _, reply := range m.selectedThread.Replies {
+		for _, shown := range m.shownReplies() {
+			reply := shown.reply
+			indent := strings.Repeat("  ", shown.depth)
+			border := borderStyle.Render(indent + "│ ")
+			width := max(replyWidth-2*shown.depth, 20)
+
 			// Reply header with styled border and author
-			rendered.WriteString(borderStyle.Render("│ "))
-			rendered.WriteString(authorStyle.Render(fmt.Sprintf("@%s · %s",
-				reply.Author,
-				reply.Timestamp.Format("2006-01-02 15:04"))))
+			if reply.ID == m.replyCursor {
+				m.replyCursorRow = strings.Count(rendered.String(), "\n")
+				rendered.WriteString(cursorStyle.Render(indent + "▶ "))
+			} else {
+				rendered.WriteString(border)
+			}
+			rendered.WriteString(m.renderAuthor(reply.Author, lipgloss.NewStyle()))
+			rendered.WriteString(timestampStyle.Render(" · " + m.formatTime(reply.Timestamp)))
+			if n := reply.CountReplies(); n > 0 {
+				rendered.WriteString(timestampStyle.Render(" · " + plural(n, "reply")))
+			}
 			rendered.WriteString("\n")
 
 			// Wrap and render reply text
 			lines := strings.Split(reply.Text, "\n")
 			for _, line := range lines {
 				// Wrap each line if it's too long
-				wrappedLines := strings.Split(wordwrap.String(line, replyWidth), "\n")
+				wrappedLines := strings.Split(wordwrap.String(line, width), "\n")
 				for _, wrappedLine := range wrappedLines {
-					rendered.WriteString(borderStyle.Render("│ "))
+					rendered.WriteString(border)
 					rendered.WriteString(wrappedLine)
 					rendered.WriteString("\n")
 				}
 			}
+			if m.collapsedReplies[reply.ID] {
+				rendered.WriteString(border)
+				rendered.WriteString(helpStyle.Render(fmt.Sprintf("▸ %s collapsed", plural(reply.CountReplies(), "reply"))))
+				rendered.WriteString("\n")
+			}
 			rendered.WriteString("\n")
 		}
 	} else {
 		rendered.WriteString(helpStyle.Render("No replies yet\n\nPress 'r' to add a reply"))
 	}
 
-	return rendered.String()
+	return m.glyphs(rendered.String())
 }
 
 // ContextLine represents a line with its line number for context display
@@ -510,6 +592,12 @@ type ContextLine struct {
 
 // getContextLines extracts lines around a specific line number for context
 func (m *Model) getContextLines(lineNum int, contextSize int) []ContextLine {
+	return m.getRangeContextLines(lineNum, lineNum, contextSize)
+}
+
+// getRangeContextLines extracts the lines firstLine-lastLine plus contextSize
+// lines on either side
+func (m *Model) getRangeContextLines(firstLine, lastLine int, contextSize int) []ContextLine {
 	if m.doc == nil {
 		return nil
 	}
@@ -518,12 +606,12 @@ func (m *Model) getContextLines(lineNum int, contextSize int) []ContextLine {
 	var result []ContextLine
 
 	// Calculate range
-	start := lineNum - contextSize - 1 // -1 for 0-based indexing
+	start := firstLine - contextSize - 1 // -1 for 0-based indexing
 	if start < 0 {
 		start = 0
 	}
 
-	end := lineNum + contextSize // inclusive
+	end := lastLine + contextSize // inclusive
 	if end > len(lines) {
 		end = len(lines)
 	}
@@ -592,7 +680,7 @@ func (m *Model) getSectionContext(lineNum int) string {
 
 	// Show context around target line within section
 	// Show a few lines before and after the target, but stay within section bounds
-	contextSize := 2
+	contextSize := m.contextSize
 	start := lineNum - contextSize
 	if start < section.StartLine {
 		start = section.StartLine
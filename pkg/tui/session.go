@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxSessionFiles caps how many files the session remembers; the least
+// recently viewed files are forgotten first
+const maxSessionFiles = 100
+
+// FileState is the scroll/selection/filter state remembered for one file
+type FileState struct {
+	SelectedComment int       `json:"selected_comment"`
+	ShowResolved    bool      `json:"show_resolved"`
+	DocOffset       int       `json:"doc_offset"`
+	CommentOffset   int       `json:"comment_offset"`
+	ViewedAt        time.Time `json:"viewed_at"`
+}
+
+// Session is the TUI state persisted between runs so reviews can resume
+// where they left off. Files are keyed by absolute path.
+type Session struct {
+	LastFile string               `json:"last_file,omitempty"`
+	Files    map[string]FileState `json:"files"`
+
+	// SplitRatio is the document pane's share last set with < and >
+	// (0 if never changed)
+	SplitRatio float64 `json:"split_ratio,omitempty"`
+}
+
+// NewSession returns an empty session
+func NewSession() *Session {
+	return &Session{Files: map[string]FileState{}}
+}
+
+// DefaultSessionPath returns the per-user session file location
+func DefaultSessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "comments", "session.json"), nil
+}
+
+// LoadSession reads a session file; a missing file yields an empty session
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewSession(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	s := NewSession()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	if s.Files == nil {
+		s.Files = map[string]FileState{}
+	}
+	return s, nil
+}
+
+// Save writes the session file, creating its directory if needed
+func (s *Session) Save(path string) error {
+	s.prune()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the remembered state for a file
+func (s *Session) lookup(path string) (FileState, bool) {
+	st, ok := s.Files[sessionKey(path)]
+	return st, ok
+}
+
+// record remembers the state for a file and marks it as the last one viewed
+func (s *Session) record(path string, st FileState) {
+	key := sessionKey(path)
+	st.ViewedAt = time.Now().UTC()
+	s.Files[key] = st
+	s.LastFile = key
+}
+
+// prune drops the least recently viewed files beyond maxSessionFiles
+func (s *Session) prune() {
+	if len(s.Files) <= maxSessionFiles {
+		return
+	}
+
+	keys := make([]string, 0, len(s.Files))
+	for k := range s.Files {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.Files[keys[i]].ViewedAt.After(s.Files[keys[j]].ViewedAt)
+	})
+	for _, k := range keys[maxSessionFiles:] {
+		delete(s.Files, k)
+	}
+}
+
+// sessionKey normalizes a path so the same file matches from any working directory
+func sessionKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// SetSession attaches a session and resumes from it: the open file's view
+// state is restored, a workspace jumps to the last file viewed, and the file
+// picker opens the last file directly.
+func (m *Model) SetSession(s *Session) {
+	if s == nil {
+		return
+	}
+	m.session = s
+
+	switch {
+	case m.mode == ModeFilePicker && s.LastFile != "":
+		if _, err := os.Stat(s.LastFile); err != nil {
+			return
+		}
+		if next := m.loadFileModel(s.LastFile); next.err == nil {
+			*m = next
+		}
+
+	case m.workspace != nil:
+		for i, f := range m.workspace.files {
+			if sessionKey(f.path) == s.LastFile && i != m.workspace.current {
+				*m = m.openWorkspaceFile(i)
+				return
+			}
+		}
+		m.queueSavedView()
+
+	default:
+		m.queueSavedView()
+	}
+}
+
+// Session records the open file's state and returns the session for saving
+func (m Model) Session() *Session {
+	m.recordViewState()
+	return m.session
+}
+
+// recordViewState saves the open file's scroll/selection state to the session
+func (m *Model) recordViewState() {
+	if m.doc == nil || m.filename == "" {
+		return
+	}
+	m.session.record(m.filename, FileState{
+		SelectedComment: m.selectedComment,
+		ShowResolved:    m.showResolved,
+		DocOffset:       m.documentViewport.YOffset,
+		CommentOffset:   m.commentViewport.YOffset,
+	})
+}
+
+// queueSavedView schedules the open file's remembered state to be applied
+// once the viewports exist (immediately if they already do)
+func (m *Model) queueSavedView() {
+	st, ok := m.session.lookup(m.filename)
+	if !ok {
+		return
+	}
+	m.showResolved = st.ShowResolved
+	m.pendingView = &st
+	if m.ready {
+		m.applyPendingView()
+	}
+}
+
+// applyPendingView restores a queued selection and scroll position
+func (m *Model) applyPendingView() {
+	st := m.pendingView
+	if st == nil || m.doc == nil {
+		return
+	}
+	m.pendingView = nil
+
+	if visible := m.visibleThreads(); st.SelectedComment < len(visible) {
+		m.selectedComment = st.SelectedComment
+	}
+	m.commentViewport.SetContent(m.renderComments())
+	m.documentViewport.SetYOffset(st.DocOffset)
+	m.commentViewport.SetYOffset(st.CommentOffset)
+}
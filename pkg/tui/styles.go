@@ -23,6 +23,11 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241"))
 
+	// Status notices (e.g., permission denied) shown in place of help text
+	statusMessageStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("203")).
+				Bold(true)
+
 	// Comment panel
 	commentPanelStyle = lipgloss.NewStyle().
 				BorderLeft(true).
@@ -30,6 +35,27 @@ var (
 				BorderForeground(lipgloss.Color("63")).
 				Padding(0, 1)
 
+	// Comment panel below the document (stacked layout)
+	stackedPanelStyle = lipgloss.NewStyle().
+				BorderTop(true).
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("63")).
+				Padding(0, 1)
+
+	// Workspace file list (comments view <dir>)
+	fileListStyle = lipgloss.NewStyle().
+			BorderRight(true).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1).
+			Width(workspaceListWidth - 1)
+
+	// Competing proposal in conflict resolution
+	conflictColumnStyle = lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("3")).
+				Padding(0, 1)
+
 	// Selected comment
 	selectedCommentStyle = lipgloss.NewStyle().
 				Background(lipgloss.Color("237"))
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// suggestionField identifies a field in the add-suggestion form
+type suggestionField int
+
+const (
+	fieldRationale suggestionField = iota
+	fieldProposed
+	fieldType
+	fieldPriority
+	suggestionFieldCount
+)
+
+// commentTypes is the cycle order for comment/suggestion types ("" = none)
+var commentTypes = []string{"", "Q", "S", "B", "T", "E"}
+
+// commentTypeNames maps comment type codes to display names
+var commentTypeNames = map[string]string{
+	"Q": "Question",
+	"S": "Suggestion",
+	"B": "Bug",
+	"T": "TODO",
+	"E": "Enhancement",
+}
+
+// priorities is the cycle order for priorities
+var priorities = []string{"medium", "high", "low"}
+
+// newRationaleInput creates the textarea for a suggestion's rationale
+func newRationaleInput() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Explain why this change is suggested (required)..."
+	ta.SetHeight(2)
+	return ta
+}
+
+// openSuggestionForm shows the add-suggestion form for the captured range
+// The proposed text is pre-filled with the original so small edits are easy.
+func (m Model) openSuggestionForm() (tea.Model, tea.Cmd) {
+	m.mode = ModeAddSuggestion
+	m.suggestionFormErr = ""
+	m.rationaleInput.Reset()
+	m.proposedTextInput.Reset()
+	m.proposedTextInput.SetValue(m.suggestionOriginalText)
+	return m, m.focusSuggestionField(fieldRationale)
+}
+
+// openInsertionForm shows the add-suggestion form for new lines inserted
+// after line after (0 for the top of the document)
+func (m Model) openInsertionForm(after int) (tea.Model, tea.Cmd) {
+	m.rangeStartLine = after + 1
+	m.rangeEndLine = after
+	m.rangeActive = false
+	m.suggestionIsSection = false
+	m.suggestionIsInsertion = true
+	m.suggestionOriginalText = ""
+	return m.openSuggestionForm()
+}
+
+// focusSuggestionField moves form focus, focusing the matching textarea
+func (m *Model) focusSuggestionField(field suggestionField) tea.Cmd {
+	m.suggestionFocus = field
+	m.rationaleInput.Blur()
+	m.proposedTextInput.Blur()
+
+	switch field {
+	case fieldRationale:
+		m.rationaleInput.Focus()
+		return textarea.Blink
+	case fieldProposed:
+		m.proposedTextInput.Focus()
+		return textarea.Blink
+	}
+	return nil
+}
+
+// resetSuggestionForm clears all suggestion creation state
+func (m *Model) resetSuggestionForm() {
+	m.suggestionOriginalText = ""
+	m.rangeActive = false
+	m.suggestionIsSection = false
+	m.suggestionIsInsertion = false
+	m.suggestionFormErr = ""
+	m.rationaleInput.Reset()
+	m.proposedTextInput.Reset()
+	m.priority = "medium"
+	m.commentType = ""
+}
+
+// suggestionFieldLabel renders a form label, highlighted when its field has focus
+func (m Model) suggestionFieldLabel(field suggestionField, label string) string {
+	if m.suggestionFocus == field {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("170")).
+			Bold(true).
+			Render("▸ " + label)
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("  " + label)
+}
+
+// validateSuggestionForm checks the form before saving
+// Returns the field to focus and an error message, or "" if the form is valid.
+func validateSuggestionForm(rationale, proposed, original string) (suggestionField, string) {
+	if rationale == "" {
+		return fieldRationale, "Rationale is required"
+	}
+	if proposed == "" {
+		return fieldProposed, "Proposed text is required"
+	}
+	if proposed == original {
+		return fieldProposed, "Proposed text is unchanged from the original"
+	}
+	return 0, ""
+}
+
+// nextCommentType returns the type after t in the cycle none → Q → S → B → T → E
+func nextCommentType(t string) string {
+	return cycle(commentTypes, t, 1)
+}
+
+// prevCommentType returns the type before t in the cycle
+func prevCommentType(t string) string {
+	return cycle(commentTypes, t, -1)
+}
+
+// nextPriority returns the priority after p in the cycle medium → high → low
+func nextPriority(p string) string {
+	return cycle(priorities, p, 1)
+}
+
+// prevPriority returns the priority before p in the cycle
+func prevPriority(p string) string {
+	return cycle(priorities, p, -1)
+}
+
+// cycle steps through values from current; unknown values restart at the first
+func cycle(values []string, current string, step int) string {
+	for i, v := range values {
+		if v == current {
+			return values[(i+step+len(values))%len(values)]
+		}
+	}
+	return values[0]
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// Themes
+const (
+	ThemeDefault      = "default"       // 256 colors and emoji
+	ThemeHighContrast = "high-contrast" // Basic bright colors, reverse video for selection, text markers
+	ThemeMono         = "mono"          // No color, text markers, and ASCII in place of emoji
+)
+
+// Themes lists the valid themes
+var Themes = []string{ThemeDefault, ThemeHighContrast, ThemeMono}
+
+// theme holds the display choices that go beyond colors
+type theme struct {
+	// markers spells out in text what colors alone would signal: the
+	// selected comment, the comment type, the focused pane
+	markers bool
+
+	// ascii replaces emoji and other symbols with ASCII
+	ascii bool
+}
+
+// asciiGlyphs replaces the TUI's emoji and symbols for screen readers and
+// terminals without the fonts for them. Box-drawing characters stay.
+var asciiGlyphs = strings.NewReplacer(
+	"💬", "*",
+	"📄", "=",
+	"📍", "#",
+	"📝", "~",
+	"✓", "+",
+	"✗", "x",
+	"●", "*",
+	"▶", ">",
+	"►", ">",
+	"▸", ">",
+	"⋯", "...",
+	"…", "~",
+	"•", "-",
+	"‹", "<",
+	"›", ">",
+	"↑", "^",
+	"↓", "v",
+	"←", "<",
+	"→", ">",
+	"█", "_",
+	"⚠", "!",
+)
+
+// ValidateTheme checks a theme name
+func ValidateTheme(name string) error {
+	for _, t := range Themes {
+		if name == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(Themes, ", "))
+}
+
+// SetTheme applies a theme. NO_COLOR (https://no-color.org) turns color off
+// whatever the theme, adding text markers in its place. Colors are set for
+// the whole process, as lipgloss keeps them globally.
+func (m *Model) SetTheme(name string) error {
+	if err := ValidateTheme(name); err != nil {
+		return err
+	}
+
+	m.theme = theme{markers: name != ThemeDefault, ascii: name == ThemeMono}
+	switch {
+	case name == ThemeMono || termenv.EnvNoColor():
+		lipgloss.SetColorProfile(termenv.Ascii)
+		m.theme.markers = true
+	case name == ThemeHighContrast:
+		lipgloss.SetColorProfile(termenv.ANSI)
+		useHighContrastStyles()
+	}
+	return nil
+}
+
+// useHighContrastStyles swaps the dim grays and background tints of the
+// shared styles for bright text, bold, and reverse video
+func useHighContrastStyles() {
+	bright := lipgloss.Color("15")
+	titleStyle = titleStyle.Foreground(bright).Underline(true)
+	helpStyle = helpStyle.Foreground(bright)
+	lineNumberStyle = lineNumberStyle.Foreground(bright)
+	commentPanelStyle = commentPanelStyle.BorderForeground(bright)
+	stackedPanelStyle = stackedPanelStyle.BorderForeground(bright)
+	fileListStyle = fileListStyle.BorderForeground(bright)
+	selectedCommentStyle = lipgloss.NewStyle().Reverse(true)
+	cursorStyle = lipgloss.NewStyle().Reverse(true).Bold(true)
+	selectedLineStyle = lipgloss.NewStyle().Underline(true)
+	rangeMarkerStyle = rangeMarkerStyle.Foreground(bright)
+	badgeStyle = badgeStyle.Foreground(bright).Bold(true)
+}
+
+// glyphs applies the theme's ASCII fallbacks to rendered text. Text is
+// converted before it is laid out where possible, as the fallbacks can be
+// narrower than the emoji they replace.
+func (m *Model) glyphs(s string) string {
+	if !m.theme.ascii {
+		return s
+	}
+	return asciiGlyphs.Replace(s)
+}
+
+// renderModal draws a modal dialog box, converting its content to ASCII
+// first so the border stays aligned
+func (m *Model) renderModal(content string) string {
+	return modalOverlayStyle.Render(m.glyphs(content))
+}
+
+// selectionMarker prefixes the selected comment when colors can't show it
+func (m *Model) selectionMarker(selected bool) string {
+	switch {
+	case !m.theme.markers:
+		return ""
+	case selected:
+		return "▶ "
+	default:
+		return "  "
+	}
+}
+
+// typeMarker labels a comment's type, e.g. "[B] ", when colors can't show
+// it and the text doesn't already start with the label
+func (m *Model) typeMarker(c *comment.Comment) string {
+	if !m.theme.markers || c.Type == "" {
+		return ""
+	}
+	label := "[" + c.Type + "]"
+	if strings.HasPrefix(c.Text, label) {
+		return ""
+	}
+	return label + " "
+}
+
+// focusMarker names the focused browse pane for the title when the dimmed
+// border can't show it
+func (m *Model) focusMarker() string {
+	if !m.theme.markers || m.mode != ModeBrowse {
+		return ""
+	}
+	if m.documentFocused {
+		return " [document focused]"
+	}
+	return " [comments focused]"
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// loadSeen reads when the author last viewed each thread of the open file.
+// Unread markers are left off if the seen state can't be read.
+func (m *Model) loadSeen() {
+	m.seen = nil
+	if m.filename == "" {
+		return
+	}
+	seen, err := comment.LoadSeen(m.filename)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Unread markers off: %v", err)
+		return
+	}
+	m.seen = seen
+}
+
+// isUnread reports whether a thread has activity from others since the
+// author last opened it
+func (m *Model) isUnread(thread *comment.Comment) bool {
+	return m.seen != nil && m.seen.IsUnread(m.author, thread)
+}
+
+// markSeen records that the author has opened a thread. The seen state is
+// personal, so it is saved even in read-only mode.
+func (m *Model) markSeen(thread *comment.Comment) {
+	if m.seen == nil || m.filename == "" {
+		return
+	}
+	m.seen.MarkSeen(m.author, thread)
+	m.commentViewport.SetContent(m.renderComments())
+	if err := comment.SaveSeen(m.filename, m.seen); err != nil {
+		m.statusMsg = fmt.Sprintf("Could not save unread state: %v", err)
+	}
+}
+
+// unreadBadge marks a thread with unseen activity in the comment pane
+func (m *Model) unreadBadge(thread *comment.Comment) string {
+	if !m.isUnread(thread) {
+		return ""
+	}
+	return " " + badgeStyle.Render("● new")
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rcliao/comments/pkg/comment"
+)
+
+// workspaceListWidth is the width of the file list pane, border included
+const workspaceListWidth = 30
+
+// workspaceFile is one markdown file in a multi-file workspace
+type workspaceFile struct {
+	path       string
+	unresolved int
+}
+
+// workspace holds the state of a directory review session (`comments view <dir>`)
+type workspace struct {
+	root    string
+	files   []workspaceFile
+	current int
+}
+
+// NewWorkspaceModel creates a model that reviews several files from a directory
+// The first file is opened immediately; [ and ] switch between files.
+func NewWorkspaceModel(root string, paths []string) (Model, error) {
+	if len(paths) == 0 {
+		return Model{}, fmt.Errorf("no markdown files found in %s", root)
+	}
+
+	ws := &workspace{
+		root:  root,
+		files: make([]workspaceFile, len(paths)),
+	}
+
+	index := map[string]int{}
+	for i, path := range paths {
+		ws.files[i].path = path
+		index[path] = i
+	}
+
+	// Unresolved counts come from a read-only scan so untouched files aren't re-saved
+	var errs comment.FileErrors
+	for result := range comment.ReadDocumentsParallel(paths, 0) {
+		if result.Err != nil {
+			errs.Add(result.Path, result.Err)
+			continue
+		}
+		ws.files[index[result.Path]].unresolved = countUnresolved(result.Doc.Threads)
+	}
+	if err := errs.Err(); err != nil {
+		return Model{}, err
+	}
+
+	doc, err := comment.LoadFromSidecar(paths[0])
+	if err != nil {
+		return Model{}, err
+	}
+
+	m := NewModelWithFile(doc, paths[0])
+	m.workspace = ws
+	return m, nil
+}
+
+// countUnresolved returns the number of unresolved threads
+func countUnresolved(threads []*comment.Comment) int {
+	return len(comment.GetVisibleComments(threads, false))
+}
+
+// fileListWidth returns the width taken by the workspace file list (0 outside a workspace)
+func (m *Model) fileListWidth() int {
+	if m.workspace == nil {
+		return 0
+	}
+	return workspaceListWidth
+}
+
+// switchFile opens the file delta positions away in the workspace list,
+// wrapping around at either end
+func (m Model) switchFile(delta int) Model {
+	ws := m.workspace
+	if ws == nil || len(ws.files) < 2 {
+		return m
+	}
+	return m.openWorkspaceFile((ws.current + delta + len(ws.files)) % len(ws.files))
+}
+
+// openWorkspaceFile remembers the open file's view state and opens the
+// workspace file at index, restoring its last selection and scroll position
+func (m Model) openWorkspaceFile(index int) Model {
+	ws := m.workspace
+	if m.doc != nil {
+		ws.files[ws.current].unresolved = countUnresolved(m.doc.Threads)
+	}
+	m.recordViewState()
+
+	m = m.loadFileModel(ws.files[index].path)
+	if m.err != nil {
+		return m
+	}
+	ws.current = index
+	ws.files[index].unresolved = countUnresolved(m.doc.Threads)
+	return m
+}
+
+// renderFileList renders the workspace file list with unresolved counts
+func (m Model) renderFileList() string {
+	ws := m.workspace
+	if m.doc != nil {
+		ws.files[ws.current].unresolved = countUnresolved(m.doc.Threads)
+	}
+
+	nameWidth := workspaceListWidth - 7 // border, padding, and count column
+	var rendered strings.Builder
+	rendered.WriteString(fmt.Sprintf("Files (%d)\n\n", len(ws.files)))
+
+	for i, f := range ws.files {
+		name := f.path
+		if rel, err := filepath.Rel(ws.root, f.path); err == nil {
+			name = rel
+		}
+		if runes := []rune(name); len(runes) > nameWidth {
+			name = "…" + string(runes[len(runes)-nameWidth+1:])
+		}
+
+		count := ""
+		if f.unresolved > 0 {
+			count = fmt.Sprintf("%d", f.unresolved)
+		}
+		line := fmt.Sprintf("%-*s %3s", nameWidth, name, count)
+
+		if i == ws.current {
+			line = selectedCommentStyle.Render(line)
+		} else if f.unresolved == 0 {
+			line = helpStyle.Render(line)
+		}
+		rendered.WriteString(line + "\n")
+	}
+
+	return fileListStyle.Height(m.height - 2).Render(rendered.String())
+}
+
+// joinWorkspacePanes prepends the file list pane when a workspace is open
+func (m Model) joinWorkspacePanes(content string) string {
+	if m.workspace == nil {
+		return content
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.renderFileList(), content)
+}